@@ -648,3 +648,20 @@ func MaxFuncImpl(rows types.TraceSet) types.Trace {
 func MinFuncImpl(rows types.TraceSet) types.Trace {
 	return applyFuncToEachColumn(rows, vec32.Min)
 }
+
+// PercentileFuncImpl puts the p-th percentile, where p is in [0, 100], of the
+// values of all argument traces into a single trace.
+func PercentileFuncImpl(rows types.TraceSet, p float64) types.Trace {
+	return applyFuncToEachColumn(rows, func(column []float32) float32 {
+		return vec32.PercentileE(column, p)
+	})
+}
+
+// TrimmedMeanFuncImpl puts the mean of the values of all argument traces,
+// after discarding the given fraction of the smallest and largest values, into
+// a single trace.
+func TrimmedMeanFuncImpl(rows types.TraceSet, fraction float64) types.Trace {
+	return applyFuncToEachColumn(rows, func(column []float32) float32 {
+		return vec32.TrimmedMeanE(column, fraction)
+	})
+}
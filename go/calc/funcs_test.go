@@ -1,6 +1,7 @@
 package calc
 
 import (
+	"fmt"
 	"math"
 	"testing"
 
@@ -53,3 +54,22 @@ func TestMinFuncImpl(t *testing.T) {
 func TestMinFuncImpl_EmptyTraceSet_ReturnsEmptyTrace(t *testing.T) {
 	assert.Equal(t, types.Trace{}, MinFuncImpl(types.TraceSet{}))
 }
+
+func TestPercentileFuncImpl(t *testing.T) {
+	tr := PercentileFuncImpl(types.TraceSet{
+		"a": []float32{e, 0, 1, 2},
+		"b": []float32{e, e, 3, 4},
+		"c": []float32{e, e, 5, 6},
+	}, 50)
+	assert.Equal(t, types.Trace{e, 0, 3, 4}, tr)
+}
+
+func TestTrimmedMeanFuncImpl(t *testing.T) {
+	rows := types.TraceSet{}
+	for i := 0; i < 10; i++ {
+		rows[fmt.Sprintf("%d", i)] = []float32{float32(i)}
+	}
+	rows["0"] = []float32{-1000}
+	rows["9"] = []float32{1000}
+	assert.Equal(t, types.Trace{4.5}, TrimmedMeanFuncImpl(rows, 0.1))
+}
@@ -67,6 +67,8 @@ var (
 type GitilesRepo interface {
 	// Details returns a vcsinfo.LongCommit for the given commit.
 	Details(ctx context.Context, ref string) (*vcsinfo.LongCommit, error)
+	// GetTreeDiffs returns a slice of TreeDiffs for the given commit.
+	GetTreeDiffs(ctx context.Context, ref string) ([]*TreeDiff, error)
 	// ReadObject reads the given object at the given ref, returning its contents
 	// and FileInfo.
 	ReadObject(ctx context.Context, path, ref string) (os.FileInfo, []byte, error)
@@ -116,6 +116,36 @@ func (_m *GitilesRepo) DownloadFileAtRef(ctx context.Context, srcPath string, re
 	return r0
 }
 
+// GetTreeDiffs provides a mock function with given fields: ctx, ref
+func (_m *GitilesRepo) GetTreeDiffs(ctx context.Context, ref string) ([]*gitiles.TreeDiff, error) {
+	ret := _m.Called(ctx, ref)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTreeDiffs")
+	}
+
+	var r0 []*gitiles.TreeDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*gitiles.TreeDiff, error)); ok {
+		return rf(ctx, ref)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*gitiles.TreeDiff); ok {
+		r0 = rf(ctx, ref)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*gitiles.TreeDiff)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ref)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListDir provides a mock function with given fields: ctx, dir
 func (_m *GitilesRepo) ListDir(ctx context.Context, dir string) ([]fs.FileInfo, error) {
 	ret := _m.Called(ctx, dir)
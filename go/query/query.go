@@ -292,6 +292,23 @@ func (q *Query) String() string {
 	return strings.Join(ret, "")
 }
 
+// KeyValueString returns a string representation of the Query that, unlike
+// String, includes the keys and any negation, so that it can be used as a
+// cache key. The returned string is formatted as a URL query.
+func (q *Query) KeyValueString() string {
+	values := url.Values{}
+	for _, p := range q.params {
+		key := p.Key()
+		for _, v := range p.values {
+			if p.isNegative {
+				v = "!" + v
+			}
+			values.Add(key, v)
+		}
+	}
+	return values.Encode()
+}
+
 // NewFromString creates a Query from the given string, which is formatted as a URL query.
 func NewFromString(s string) (*Query, error) {
 	values, err := url.ParseQuery(s)
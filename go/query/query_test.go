@@ -775,3 +775,27 @@ func TestQueryParamKey_HappyPath(t *testing.T) {
 func TestQueryParamKey_EmptyKey_ReturnsEmptyString(t *testing.T) {
 	require.Empty(t, queryParam{keyMatch: ",="}.Key())
 }
+
+func TestKeyValueString_QueryWithNegativeAndRegexParams_RoundTrips(t *testing.T) {
+	q, err := New(url.Values{
+		"config": []string{"!565", "!8888"},
+		"arch":   []string{"x86", "arm"},
+		"name":   []string{"~^desk"},
+	})
+	require.NoError(t, err)
+	s := q.KeyValueString()
+	require.Equal(t, "arch=x86&arch=arm&config=%21565&config=%218888&name=~%5Edesk", s)
+
+	q2, err := NewFromString(s)
+	require.NoError(t, err)
+	require.Equal(t, s, q2.KeyValueString())
+}
+
+func TestKeyValueString_QueriesWithSameValuesForDifferentKeys_AreDifferent(t *testing.T) {
+	q1, err := New(url.Values{"arch": []string{"x86"}})
+	require.NoError(t, err)
+	q2, err := New(url.Values{"cpu": []string{"x86"}})
+	require.NoError(t, err)
+	require.Equal(t, q1.String(), q2.String())
+	require.NotEqual(t, q1.KeyValueString(), q2.KeyValueString())
+}
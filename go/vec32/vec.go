@@ -562,3 +562,42 @@ func Max(a []float32) float32 {
 	}
 	return ret
 }
+
+// PercentileE returns the p-th percentile, where p is in [0, 100], of the
+// values in the vector, ignoring MissingDataSentinels. Values between two
+// samples are linearly interpolated.
+//
+// Returns MissingDataSentinel if no non-MissingDataSentinel values are found.
+func PercentileE(a []float32, p float64) float32 {
+	sorted := RemoveMissingDataSentinel(a)
+	if len(sorted) == 0 {
+		return MissingDataSentinel
+	}
+	sort.Sort(float32Slice(sorted))
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower < 0 {
+		return sorted[0]
+	}
+	if upper >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := float32(rank - float64(lower))
+	return sorted[lower] + frac*(sorted[upper]-sorted[lower])
+}
+
+// TrimmedMeanE returns the mean of the values in the vector after discarding
+// the given fraction, in [0, 0.5), of the smallest and of the largest values.
+// MissingDataSentinels are ignored.
+//
+// Returns MissingDataSentinel if no non-MissingDataSentinel values are found.
+func TrimmedMeanE(a []float32, fraction float64) float32 {
+	sorted := RemoveMissingDataSentinel(a)
+	if len(sorted) == 0 {
+		return MissingDataSentinel
+	}
+	sort.Sort(float32Slice(sorted))
+	trim := int(fraction * float64(len(sorted)))
+	return MeanE(sorted[trim : len(sorted)-trim])
+}
@@ -730,3 +730,20 @@ func TestMax(t *testing.T) {
 	assert.Equal(t, float32(2), Max([]float32{2}))
 	assert.Equal(t, float32(5), Max([]float32{5, e, 3}))
 }
+
+func TestPercentileE(t *testing.T) {
+	assert.Equal(t, float32(e), PercentileE([]float32{}, 50), "Empty returns MissingDataSentinel.")
+	assert.Equal(t, float32(e), PercentileE([]float32{e}, 50), "MissingDataSentinels are ignored.")
+	assert.Equal(t, float32(3), PercentileE([]float32{5, e, 1, 3}, 50))
+	assert.Equal(t, float32(2.5), PercentileE([]float32{4, 1, 3, 2}, 50), "Interpolates between samples.")
+	assert.Equal(t, float32(1), PercentileE([]float32{4, 1, 3, 2}, 0))
+	assert.Equal(t, float32(4), PercentileE([]float32{4, 1, 3, 2}, 100))
+	assert.InDelta(t, float32(9.91), PercentileE([]float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 99), 0.001)
+}
+
+func TestTrimmedMeanE(t *testing.T) {
+	assert.Equal(t, float32(e), TrimmedMeanE([]float32{}, 0.1), "Empty returns MissingDataSentinel.")
+	assert.Equal(t, float32(e), TrimmedMeanE([]float32{e}, 0.1), "MissingDataSentinels are ignored.")
+	assert.Equal(t, float32(2), TrimmedMeanE([]float32{3, 1, 2}, 0.1), "Too few values to trim.")
+	assert.Equal(t, float32(5.5), TrimmedMeanE([]float32{100, 2, 3, 4, 5, 6, 7, 8, 9, -100}, 0.1), "Outliers are trimmed.")
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"go.skia.org/infra/gold-client/go/goldclient"
+	"go.skia.org/infra/gold-client/go/localgold"
+	"go.skia.org/infra/golden/go/jsonio"
+	"go.skia.org/infra/golden/go/types"
+)
+
+const fstrBaselineDir = "baseline-dir"
+
+// localEnv provides the environment for the local command and its sub-commands.
+type localEnv struct {
+	baselineDir string
+	workDir     string
+
+	// Flags of the export command.
+	bucketOverride   string
+	changelistID     string
+	codeReviewSystem string
+	instanceID       string
+	urlOverride      string
+	tests            []string
+	extraDigests     []string
+
+	// Flags of the add command.
+	testName                string
+	pngFile                 string
+	testOptionalKeysFile    string
+	testOptionalKeysStrings []string
+}
+
+// getLocalCmd returns the definition of the local command.
+func getLocalCmd() *cobra.Command {
+	env := &localEnv{}
+	localCmd := &cobra.Command{
+		Use:   "local",
+		Short: "Compare images against a baseline on disk, without a Gold instance",
+		Long: `
+Compare images against a baseline that was previously exported from a Gold instance, without
+talking to the instance or uploading anything. Useful for offline developer runs.
+
+Export the baseline once with 'goldctl local export', then run 'goldctl local init',
+'goldctl local add' for each image, and 'goldctl local finalize' to produce an HTML report of
+the failures with diffs against the closest passing images.`,
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a baseline from a Gold instance to disk",
+		Long: `
+Downloads the baseline, the known hashes and the images of the passing digests from a Gold
+instance into the baseline directory. Requires goldctl auth to have been run on the work directory.`,
+		Run:  env.runExportCmd,
+		Args: cobra.NoArgs,
+	}
+	exportCmd.Flags().StringVar(&env.baselineDir, fstrBaselineDir, "", "Directory to write the baseline to.")
+	exportCmd.Flags().StringVar(&env.workDir, fstrWorkDir, "", "Work directory for intermediate results")
+	exportCmd.Flags().StringVar(&env.instanceID, "instance", "", "ID of the Gold instance.")
+	exportCmd.Flags().StringVar(&env.bucketOverride, "bucket", "", "GCS Bucket to use. If empty the URL will be derived from the value of 'instance'")
+	exportCmd.Flags().StringVar(&env.urlOverride, "url", "", "URL of the Gold instance. If empty the URL will be derived from the value of 'instance'")
+	exportCmd.Flags().StringVar(&env.changelistID, "changelist", "", "If provided, the expectations of this changelist will be merged onto the baseline.")
+	exportCmd.Flags().StringVar(&env.codeReviewSystem, "crs", "", "CodeReviewSystem of the changelist, if any (e.g. 'gerrit', 'github')")
+	exportCmd.Flags().StringSliceVar(&env.tests, "test", []string{}, "Only export the images of these tests. All images are exported by default.")
+	exportCmd.Flags().StringSliceVar(&env.extraDigests, "digest", []string{}, "Additional digests whose images should be exported, e.g. masks used by the mask_region algorithm.")
+	must(exportCmd.MarkFlagRequired(fstrBaselineDir))
+	must(exportCmd.MarkFlagRequired(fstrWorkDir))
+	must(exportCmd.MarkFlagRequired("instance"))
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Start a local testing session",
+		Long: `
+Start a local testing session against the given baseline directory, discarding the results of any
+previous session in the work directory.`,
+		Run:  env.runInitCmd,
+		Args: cobra.NoArgs,
+	}
+	initCmd.Flags().StringVar(&env.baselineDir, fstrBaselineDir, "", "Directory with a baseline written by 'goldctl local export'.")
+	initCmd.Flags().StringVar(&env.workDir, fstrWorkDir, "", "Work directory for intermediate results")
+	must(initCmd.MarkFlagRequired(fstrBaselineDir))
+	must(initCmd.MarkFlagRequired(fstrWorkDir))
+
+	addCmd := &cobra.Command{
+		Use:   "add",
+		Short: "Compare an image against the local baseline",
+		Long: `
+Compares the image against the local baseline, using the image matching algorithm specified via
+the optional keys, if any. Exits with a non-zero code if the image does not match.`,
+		Run:  env.runAddCmd,
+		Args: cobra.NoArgs,
+	}
+	addCmd.Flags().StringVar(&env.workDir, fstrWorkDir, "", "Work directory for intermediate results")
+	addCmd.Flags().StringVar(&env.testName, "test-name", "", "Unique name of the test, must not contain spaces.")
+	addCmd.Flags().StringVar(&env.pngFile, "png-file", "", "Path to the PNG file that contains the test results.")
+	addCmd.Flags().StringVar(&env.testOptionalKeysFile, "add-test-optional-keys-file", "", "File with a JSON dictionary of test-specific optional keys.")
+	addCmd.Flags().StringSliceVar(&env.testOptionalKeysStrings, "add-test-optional-key", []string{}, "Any number of test-specific optional keys represented as key:value pairs.")
+	must(addCmd.MarkFlagRequired(fstrWorkDir))
+	must(addCmd.MarkFlagRequired("test-name"))
+	must(addCmd.MarkFlagRequired("png-file"))
+
+	finalizeCmd := &cobra.Command{
+		Use:   "finalize",
+		Short: "Write an HTML report of the failures",
+		Long: `
+Writes an HTML report of the images that did not match the local baseline, with diffs against the
+closest passing images. Exits with a non-zero code if there were any failures.`,
+		Run:  env.runFinalizeCmd,
+		Args: cobra.NoArgs,
+	}
+	finalizeCmd.Flags().StringVar(&env.workDir, fstrWorkDir, "", "Work directory for intermediate results")
+	must(finalizeCmd.MarkFlagRequired(fstrWorkDir))
+
+	localCmd.AddCommand(exportCmd, initCmd, addCmd, finalizeCmd)
+	return localCmd
+}
+
+func (l *localEnv) runExportCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	l.Export(ctx)
+}
+
+// Export downloads the baseline of a Gold instance to the baseline directory.
+func (l *localEnv) Export(ctx context.Context) {
+	ctx = loadAuthenticatedClients(ctx, l.workDir)
+
+	config := goldclient.GoldClientConfig{
+		InstanceID:      l.instanceID,
+		OverrideBucket:  l.bucketOverride,
+		OverrideGoldURL: l.urlOverride,
+		WorkDir:         l.workDir,
+	}
+	goldClient, err := goldclient.NewCloudClient(config)
+	ifErrLogExit(ctx, err)
+
+	if l.changelistID != "" {
+		gr := jsonio.GoldResults{
+			GitHash:          "HEAD",
+			ChangelistID:     l.changelistID,
+			CodeReviewSystem: l.codeReviewSystem,
+		}
+		err = goldClient.SetSharedConfig(ctx, gr, true)
+		ifErrLogExit(ctx, err)
+	}
+
+	var tests []types.TestName
+	for _, t := range l.tests {
+		tests = append(tests, types.TestName(t))
+	}
+	var extraDigests []types.Digest
+	for _, d := range l.extraDigests {
+		extraDigests = append(extraDigests, types.Digest(d))
+	}
+	err = goldClient.Export(ctx, l.baselineDir, tests, extraDigests)
+	ifErrLogExit(ctx, err)
+
+	logInfof(ctx, "Exported baseline to %s\n", l.baselineDir)
+	exitProcess(ctx, 0)
+}
+
+func (l *localEnv) runInitCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	l.Init(ctx)
+}
+
+// Init starts a local testing session in the work directory.
+func (l *localEnv) Init(ctx context.Context) {
+	client, err := localgold.NewClient(l.baselineDir, l.workDir)
+	ifErrLogExit(ctx, err)
+
+	b := client.Baseline()
+	logInfof(ctx, "Loaded %d tests from the baseline of %s exported at %s\n", len(b.Expectations), b.GoldURL, b.ExportTime)
+	exitProcess(ctx, 0)
+}
+
+func (l *localEnv) runAddCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	l.Add(ctx)
+}
+
+// Add compares an image against the local baseline.
+func (l *localEnv) Add(ctx context.Context) {
+	client, err := localgold.LoadClient(l.workDir)
+	ifErrLogExit(ctx, err)
+
+	optionalKeys := readKeyValuePairsFromFileOrStringSlice(ctx, l.testOptionalKeysFile, l.testOptionalKeysStrings)
+
+	result, err := client.Add(types.TestName(l.testName), l.pngFile, optionalKeys)
+	ifErrLogExit(ctx, err)
+
+	if !result.Passed {
+		logErrf(ctx, "Test: %s FAIL (digest %s)\n", l.testName, result.Digest)
+		exitProcess(ctx, 1)
+	}
+	logInfof(ctx, "Test: %s PASS\n", l.testName)
+	exitProcess(ctx, 0)
+}
+
+func (l *localEnv) runFinalizeCmd(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	l.Finalize(ctx)
+}
+
+// Finalize writes the HTML report of the local testing session.
+func (l *localEnv) Finalize(ctx context.Context) {
+	client, err := localgold.LoadClient(l.workDir)
+	ifErrLogExit(ctx, err)
+
+	reportPath, numFailures, err := client.WriteReport()
+	ifErrLogExit(ctx, err)
+
+	logInfof(ctx, "%d of %d images did not match the baseline. Report written to %s\n", numFailures, len(client.Results()), reportPath)
+	if numFailures > 0 {
+		exitProcess(ctx, 1)
+	}
+	exitProcess(ctx, 0)
+}
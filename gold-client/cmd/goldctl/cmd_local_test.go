@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.skia.org/infra/go/testutils"
+	"go.skia.org/infra/gold-client/go/imgmatching"
+	"go.skia.org/infra/gold-client/go/localgold"
+	"go.skia.org/infra/golden/go/expectations"
+	"go.skia.org/infra/golden/go/types"
+)
+
+// writeLocalBaseline writes a baseline in which a01Digest is the only positive digest of
+// pixel-tests and returns its directory.
+func writeLocalBaseline(t *testing.T) string {
+	td := testutils.TestDataDir(t)
+	baselineDir := t.TempDir()
+	b := localgold.Baseline{
+		GoldURL:    "https://my-instance-gold.skia.org",
+		ExportTime: time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC),
+		Expectations: expectations.Baseline{
+			"pixel-tests": {a01Digest: expectations.Positive},
+		},
+	}
+	require.NoError(t, localgold.WriteBaseline(baselineDir, b, types.DigestSet{a01Digest: true}))
+	a01Bytes, err := os.ReadFile(filepath.Join(td, a01Digest+".png"))
+	require.NoError(t, err)
+	require.NoError(t, localgold.WriteImage(baselineDir, a01Digest, a01Bytes))
+	return baselineDir
+}
+
+func TestLocal_InitAddFinalize_ImageMatches_ExitCodeZero(t *testing.T) {
+	td := testutils.TestDataDir(t)
+	workDir := t.TempDir()
+
+	ctx, output, exit := testContext(nil, nil, nil, nil)
+	env := localEnv{
+		baselineDir: writeLocalBaseline(t),
+		workDir:     workDir,
+	}
+	runUntilExit(t, func() {
+		env.Init(ctx)
+	})
+	exit.AssertWasCalledWithCode(t, 0, output.String())
+	assert.Contains(t, output.String(), "Loaded 1 tests from the baseline of https://my-instance-gold.skia.org")
+
+	ctx, output, exit = testContext(nil, nil, nil, nil)
+	env = localEnv{
+		workDir:  workDir,
+		testName: "pixel-tests",
+		pngFile:  filepath.Join(td, a05Digest+".png"),
+		testOptionalKeysStrings: []string{
+			imgmatching.AlgorithmNameOptKey + ":" + string(imgmatching.FuzzyMatching),
+			string(imgmatching.MaxDifferentPixels) + ":2",
+			string(imgmatching.PixelDeltaThreshold) + ":10",
+		},
+	}
+	runUntilExit(t, func() {
+		env.Add(ctx)
+	})
+	exit.AssertWasCalledWithCode(t, 0, output.String())
+	assert.Contains(t, output.String(), "Test: pixel-tests PASS")
+
+	ctx, output, exit = testContext(nil, nil, nil, nil)
+	env = localEnv{workDir: workDir}
+	runUntilExit(t, func() {
+		env.Finalize(ctx)
+	})
+	exit.AssertWasCalledWithCode(t, 0, output.String())
+	assert.Contains(t, output.String(), "0 of 1 images did not match the baseline.")
+	assert.FileExists(t, filepath.Join(workDir, "report", "index.html"))
+}
+
+func TestLocal_InitAddFinalize_ImageDoesNotMatch_ExitCodeOne(t *testing.T) {
+	td := testutils.TestDataDir(t)
+	workDir := t.TempDir()
+
+	ctx, output, exit := testContext(nil, nil, nil, nil)
+	env := localEnv{
+		baselineDir: writeLocalBaseline(t),
+		workDir:     workDir,
+	}
+	runUntilExit(t, func() {
+		env.Init(ctx)
+	})
+	exit.AssertWasCalledWithCode(t, 0, output.String())
+
+	ctx, output, exit = testContext(nil, nil, nil, nil)
+	env = localEnv{
+		workDir:  workDir,
+		testName: "pixel-tests",
+		pngFile:  filepath.Join(td, a09Digest+".png"),
+	}
+	runUntilExit(t, func() {
+		env.Add(ctx)
+	})
+	exit.AssertWasCalledWithCode(t, 1, output.String())
+	assert.Contains(t, output.String(), "Test: pixel-tests FAIL")
+
+	ctx, output, exit = testContext(nil, nil, nil, nil)
+	env = localEnv{workDir: workDir}
+	runUntilExit(t, func() {
+		env.Finalize(ctx)
+	})
+	exit.AssertWasCalledWithCode(t, 1, output.String())
+	assert.Contains(t, output.String(), "1 of 1 images did not match the baseline.")
+	report, err := os.ReadFile(filepath.Join(workDir, "report", "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(report), a01Digest)
+}
+
+func TestLocal_AddWithoutInit_ExitCodeOne(t *testing.T) {
+	td := testutils.TestDataDir(t)
+
+	ctx, output, exit := testContext(nil, nil, nil, nil)
+	env := localEnv{
+		workDir:  t.TempDir(),
+		testName: "pixel-tests",
+		pngFile:  filepath.Join(td, a01Digest+".png"),
+	}
+	runUntilExit(t, func() {
+		env.Add(ctx)
+	})
+	exit.AssertWasCalledWithCode(t, 1, output.String())
+	assert.Contains(t, output.String(), "was goldctl local init run?")
+}
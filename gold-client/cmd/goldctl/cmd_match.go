@@ -16,15 +16,18 @@ import (
 	"go.skia.org/infra/gold-client/go/imgmatching"
 	"go.skia.org/infra/gold-client/go/imgmatching/exact"
 	"go.skia.org/infra/gold-client/go/imgmatching/fuzzy"
+	"go.skia.org/infra/gold-client/go/imgmatching/mask_region"
 	"go.skia.org/infra/gold-client/go/imgmatching/positive_if_only_image"
 	"go.skia.org/infra/gold-client/go/imgmatching/sample_area"
 	"go.skia.org/infra/gold-client/go/imgmatching/sobel"
+	"go.skia.org/infra/gold-client/go/imgmatching/ssim"
 )
 
 // matchEnv provides the environment for the match command.
 type matchEnv struct {
 	algorithmName string
 	parameters    []string
+	maskFile      string
 }
 
 // getMatchCmd returns the definition of the match command.
@@ -48,6 +51,7 @@ algorithms and parameters.
 
 	cmd.Flags().StringVar(&env.algorithmName, "algorithm", "", "Image matching algorithm (e.g. exact, fuzzy, sobel).")
 	cmd.Flags().StringArrayVar(&env.parameters, "parameter", []string{}, "Any number of algorithm-specific parameters represented as name:value pairs (e.g. sobel_edge_threshold:10).")
+	cmd.Flags().StringVar(&env.maskFile, "mask", "", "PNG mask image on disk for the mask_region algorithm. Non-black, non-transparent pixels are ignored.")
 	must(cmd.MarkFlagRequired("algorithm"))
 
 	return cmd
@@ -72,6 +76,12 @@ func (m *matchEnv) Match(ctx context.Context, leftFile, rightFile string) {
 	algorithmName, matcher, err := imgmatching.MakeMatcher(optionalKeys)
 	ifErrLogExit(ctx, err)
 
+	// Load the mask from disk, since images cannot be downloaded by digest here.
+	if maskMatcher, ok := matcher.(*mask_region.Matcher); ok && m.maskFile != "" {
+		maskMatcher.Mask, err = loadPng(m.maskFile)
+		ifErrLogExit(ctx, err)
+	}
+
 	// Run the algorithm against the two input images.
 	imagesMatch := matcher.Match(leftImage, rightImage)
 
@@ -88,6 +98,8 @@ func (m *matchEnv) Match(ctx context.Context, leftFile, rightFile string) {
 		printOutExactDebugInfo(ctx, matcher.(*exact.Matcher))
 	case imgmatching.FuzzyMatching:
 		printOutFuzzyDebugInfo(ctx, matcher.(*fuzzy.Matcher))
+	case imgmatching.MaskRegionMatching:
+		printOutMaskRegionDebugInfo(ctx, matcher.(*mask_region.Matcher))
 	case imgmatching.PositiveIfOnlyImageMatching:
 		printOutPositiveIfOnlyImageDebugInfo(ctx, matcher.(*positive_if_only_image.Matcher))
 	case imgmatching.SampleAreaMatching:
@@ -95,6 +107,8 @@ func (m *matchEnv) Match(ctx context.Context, leftFile, rightFile string) {
 	case imgmatching.SobelFuzzyMatching:
 		err := printOutSobelDebugInfo(ctx, matcher.(*sobel.Matcher))
 		ifErrLogExit(ctx, err)
+	case imgmatching.SSIMMatching:
+		printOutSSIMDebugInfo(ctx, matcher.(*ssim.Matcher))
 	}
 
 	exitProcess(ctx, 0)
@@ -144,6 +158,13 @@ func printOutFuzzyDebugInfo(ctx context.Context, matcher *fuzzy.Matcher) {
 	printDebugInfoItem(ctx, "Pixel comparison method", matcher.PixelComparisonMethod())
 }
 
+// printOutMaskRegionDebugInfo prints out stats reported by the given mask_region.Matcher, including
+// those of the embedded fuzzy.Matcher.
+func printOutMaskRegionDebugInfo(ctx context.Context, matcher *mask_region.Matcher) {
+	printDebugInfoItem(ctx, "Number of ignored pixels", matcher.NumIgnoredPixels())
+	printOutFuzzyDebugInfo(ctx, &matcher.Matcher)
+}
+
 // printOutPositiveIfOnlyImageDebugInfo prints out stats reported by the given
 // positive_if_only_image.Matcher.
 func printOutPositiveIfOnlyImageDebugInfo(ctx context.Context, matcher *positive_if_only_image.Matcher) {
@@ -161,6 +182,11 @@ func printOutSampleAreaDebugInfo(ctx context.Context, matcher *sample_area.Match
 	printDebugInfoItem(ctx, "Sample area pixel delta threshold out of range", matcher.SampleAreaChannelDeltaThresholdOutOfRange())
 }
 
+// printOutSSIMDebugInfo prints out stats reported by the given ssim.Matcher.
+func printOutSSIMDebugInfo(ctx context.Context, matcher *ssim.Matcher) {
+	printDebugInfoItem(ctx, "Structural similarity (SSIM)", matcher.SSIM())
+}
+
 // printOutSobelDebugInfo writes intermediate images generated by the given sobel.Matcher to a
 // temporary directory and prints out the resulting paths. It also prints out the stats reported by
 // the embedded fuzzy.Matcher.
@@ -88,3 +88,81 @@ func TestMatch_Sobel_ImagesAreVeryDifferent_ExitCodeZero(t *testing.T) {
 	assert.Contains(t, logs, `Maximum delta: 1020`, logs)
 	assert.Contains(t, logs, `Pixel comparison method: pixel delta threshold`, logs)
 }
+
+func TestMatch_MaskRegion_DifferencesInsideRegion_ImagesMatch(t *testing.T) {
+
+	td := testutils.TestDataDir(t)
+
+	// Call imgtest match using the mask_region match algorithm, ignoring the whole image.
+	ctx, output, exit := testContext(nil, nil, nil, nil)
+	env := matchEnv{
+		algorithmName: "mask_region",
+		parameters: []string{
+			string(imgmatching.MaskRegionRects + ":0,0,100,100"),
+		},
+	}
+	runUntilExit(t, func() {
+		env.Match(ctx, filepath.Join(td, a01Digest+".png"), filepath.Join(td, a05Digest+".png"))
+	})
+	logs := output.String()
+	exit.AssertWasCalledWithCode(t, 0, output.String())
+
+	assert.Equal(t, `Images match.
+            Number of ignored pixels: 64
+          Number of different pixels: 0
+                       Maximum delta: 0
+             Pixel comparison method: pixel per-channel delta threshold
+`, logs)
+}
+
+func TestMatch_MaskRegion_MaskFile_ImagesMatch(t *testing.T) {
+
+	td := testutils.TestDataDir(t)
+
+	// Call imgtest match using the mask_region match algorithm, with a mask on disk instead of
+	// downloading it by digest.
+	ctx, output, exit := testContext(nil, nil, nil, nil)
+	env := matchEnv{
+		algorithmName: "mask_region",
+		parameters: []string{
+			string(imgmatching.MaskRegionImageDigest + ":" + a09Digest),
+		},
+		maskFile: filepath.Join(td, a09Digest+".png"),
+	}
+	runUntilExit(t, func() {
+		env.Match(ctx, filepath.Join(td, a01Digest+".png"), filepath.Join(td, a05Digest+".png"))
+	})
+	logs := output.String()
+	exit.AssertWasCalledWithCode(t, 0, output.String())
+
+	// The mask pixels that are neither black nor transparent cover the differences between the images.
+	assert.Equal(t, `Images match.
+            Number of ignored pixels: 36
+          Number of different pixels: 0
+                       Maximum delta: 0
+             Pixel comparison method: pixel per-channel delta threshold
+`, logs)
+}
+
+func TestMatch_SSIM_ImagesAreSimilar_ImagesMatch(t *testing.T) {
+
+	td := testutils.TestDataDir(t)
+
+	// Call imgtest match using the ssim match algorithm.
+	ctx, output, exit := testContext(nil, nil, nil, nil)
+	env := matchEnv{
+		algorithmName: "ssim",
+		parameters: []string{
+			string(imgmatching.MinSSIM + ":0.9"),
+		},
+	}
+	runUntilExit(t, func() {
+		env.Match(ctx, filepath.Join(td, a01Digest+".png"), filepath.Join(td, a05Digest+".png"))
+	})
+	logs := output.String()
+	exit.AssertWasCalledWithCode(t, 0, output.String())
+
+	assert.Equal(t, `Images match.
+        Structural similarity (SSIM): 0.9998835325241089
+`, logs)
+}
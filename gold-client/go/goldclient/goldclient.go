@@ -21,9 +21,12 @@ import (
 
 	"go.skia.org/infra/go/fileutil"
 	"go.skia.org/infra/go/jsonutils"
+	"go.skia.org/infra/go/now"
 	"go.skia.org/infra/go/paramtools"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/gold-client/go/imgmatching"
+	"go.skia.org/infra/gold-client/go/imgmatching/mask_region"
+	"go.skia.org/infra/gold-client/go/localgold"
 	"go.skia.org/infra/golden/go/diff"
 	"go.skia.org/infra/golden/go/expectations"
 	"go.skia.org/infra/golden/go/jsonio"
@@ -485,19 +488,21 @@ func (c *CloudClient) Check(ctx context.Context, name types.TestName, imgFileNam
 //
 // Returns true if the image matches the baseline, or false otherwise.
 //
-// Returns the algorithm name used to determine the match. If the image's digest was labeled as
-// either positive or negative in the baseline, imgmatching.ExactMatching will be returned
+// Returns the algorithm name used to determine the match. If the image's digest was triaged in the
+// baseline (e.g. labeled as positive or negative), imgmatching.ExactMatching will be returned
 // regardless of whether a non-exact image matching algorithm was specified via the optionalKeys.
 //
 // A non-nil error is returned if there are any problems parsing or instantiating the specified
 // image matching algorithm, for example if there are any missing parameters.
 func (c *CloudClient) matchImageAgainstBaseline(ctx context.Context, testName types.TestName, traceId tiling.TraceIDV2, imageBytes []byte, imageHash types.Digest, optionalKeys map[string]string) (bool, imgmatching.AlgorithmName, error) {
-	// First we check whether the digest is a known positive or negative, regardless of the specified
-	// image matching algorithm.
-	if c.resultState.Expectations[testName][imageHash] == expectations.Positive {
+	// First we check whether the digest has been triaged, regardless of the specified image matching
+	// algorithm. Besides positive and negative, the instance may have custom labels which count as
+	// either passing or failing.
+	label := c.resultState.Expectations[testName][imageHash]
+	if expectations.IsPassing(label, c.resultState.CustomLabels) {
 		return true, imgmatching.ExactMatching, nil
 	}
-	if c.resultState.Expectations[testName][imageHash] == expectations.Negative {
+	if expectations.IsFailing(label, c.resultState.CustomLabels) {
 		return false, imgmatching.ExactMatching, nil
 	}
 
@@ -526,6 +531,15 @@ func (c *CloudClient) matchImageAgainstBaseline(ctx context.Context, testName ty
 		return false, "", skerr.Wrapf(err, "decoding PNG image")
 	}
 
+	// Download the mask image from GCS if the algorithm references one.
+	if maskMatcher, ok := matcher.(*mask_region.Matcher); ok && maskMatcher.MaskDigest != "" {
+		infof(ctx, "Fetching mask image with digest %q.\n", maskMatcher.MaskDigest)
+		maskMatcher.Mask, _, err = c.getDigestFromCacheOrGCS(ctx, maskMatcher.MaskDigest)
+		if err != nil {
+			return false, "", skerr.Wrapf(err, "downloading mask image from GCS")
+		}
+	}
+
 	// Fetch the most recent positive digest.
 	infof(ctx, "Fetching most recent positive digest for trace with ID %q.\n", traceId)
 	mostRecentPositiveDigest, err := c.MostRecentPositiveDigest(ctx, traceId)
@@ -739,6 +753,53 @@ func (c *CloudClient) Diff(ctx context.Context, grouping paramtools.Params, imgF
 	return skerr.Wrap(diffFile.Close())
 }
 
+// Export downloads the baseline and known hashes from Gold and writes them to outDir in the format
+// read by the localgold package, along with the images of the passing digests of the baseline.
+// If tests is not empty, only the images of those tests are exported. The images of extraDigests
+// (e.g. masks used by the mask_region image matching algorithm) are exported as well.
+func (c *CloudClient) Export(ctx context.Context, outDir string, tests []types.TestName, extraDigests []types.Digest) error {
+	if err := c.downloadHashesAndBaselineFromGold(ctx); err != nil {
+		return skerr.Wrapf(err, "fetching baseline")
+	}
+
+	baseline := localgold.Baseline{
+		GoldURL:          c.resultState.GoldURL,
+		ChangelistID:     c.resultState.SharedConfig.ChangelistID,
+		CodeReviewSystem: c.resultState.SharedConfig.CodeReviewSystem,
+		ExportTime:       now.Now(ctx),
+		Expectations:     c.resultState.Expectations,
+		CustomLabels:     c.resultState.CustomLabels,
+	}
+	if err := localgold.WriteBaseline(outDir, baseline, c.resultState.KnownHashes); err != nil {
+		return skerr.Wrap(err)
+	}
+
+	wantedTests := map[types.TestName]bool{}
+	for _, t := range tests {
+		wantedTests[t] = true
+	}
+	digests := types.DigestSet{}
+	digests.AddLists(extraDigests)
+	for testName := range c.resultState.Expectations {
+		if len(wantedTests) > 0 && !wantedTests[testName] {
+			continue
+		}
+		digests.AddLists(baseline.PassingDigests(testName))
+	}
+
+	infof(ctx, "Exporting %d images to %s\n", len(digests), outDir)
+	for _, d := range digests.Keys() {
+		_, b, err := c.getDigestFromCacheOrGCS(ctx, d)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		if err := localgold.WriteImage(outDir, d, b); err != nil {
+			return skerr.Wrap(err)
+		}
+	}
+	return nil
+}
+
 // urlEncode returns the map as a url-encoded string.
 func urlEncode(p map[string]string) string {
 	values := make(url.Values, len(p))
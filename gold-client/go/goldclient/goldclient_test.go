@@ -27,6 +27,7 @@ import (
 	"go.skia.org/infra/go/testutils"
 	"go.skia.org/infra/go/util"
 	"go.skia.org/infra/gold-client/go/imgmatching"
+	"go.skia.org/infra/gold-client/go/localgold"
 	"go.skia.org/infra/gold-client/go/mocks"
 	"go.skia.org/infra/golden/go/diff"
 	"go.skia.org/infra/golden/go/expectations"
@@ -1666,6 +1667,42 @@ func TestCloudClient_MatchImageAgainstBaseline_FuzzyMatching_ImageAlreadyLabeled
 	test("labeled negative, returns false", expectations.Negative, false)
 }
 
+func TestCloudClient_MatchImageAgainstBaseline_FuzzyMatching_ImageHasCustomLabel_Success(t *testing.T) {
+	test := func(name string, label expectations.Label, want bool) {
+		t.Run(name, func(t *testing.T) {
+			goldClient, ctx, _, _ := makeGoldClientForMatchImageAgainstBaselineTests(t)
+
+			const testName = types.TestName("my_test")
+			const digest = types.Digest("11111111111111111111111111111111")
+			optionalKeys := map[string]string{
+				imgmatching.AlgorithmNameOptKey: string(imgmatching.FuzzyMatching),
+				// These optionalKeys do not matter because the algorithm is not exercised by this test.
+				string(imgmatching.MaxDifferentPixels):  "0",
+				string(imgmatching.PixelDeltaThreshold): "0",
+			}
+
+			goldClient.resultState.Expectations = expectations.Baseline{
+				testName: {
+					digest: label,
+				},
+			}
+			goldClient.resultState.CustomLabels = []expectations.LabelDefinition{
+				{Label: "flaky", Code: "f", Passing: true},
+				{Label: "bug_filed", Code: "b", Passing: false},
+			}
+
+			got, algorithmName, err := goldClient.matchImageAgainstBaseline(ctx, testName, "" /* =traceId */, nil /* =imageBytes */, digest, optionalKeys)
+			assert.NoError(t, err)
+			assert.Equal(t, imgmatching.ExactMatching, algorithmName)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	test("labeled with passing custom label, returns true", "flaky", true)
+	test("labeled with failing custom label, returns false", "bug_filed", false)
+	test("labeled with unknown label, returns false", "wontfix", false)
+}
+
 func TestCloudClient_MatchImageAgainstBaseline_FuzzyMatching_UntriagedImage_Success(t *testing.T) {
 	const testName = types.TestName("my_test")
 	const traceId = tiling.TraceIDV2("1234567890abcdef1234567890abcdef")
@@ -2198,6 +2235,115 @@ func TestCloudClient_MatchImageAgainstBaseline_SobelFuzzyMatching_NoRecentPositi
 	assert.Equal(t, imgmatching.SobelFuzzyMatching, algorithmName)
 }
 
+func TestCloudClient_MatchImageAgainstBaseline_MaskRegionMatching_UntriagedImage_Success(t *testing.T) {
+	const testName = types.TestName("my_test")
+	const traceId = tiling.TraceIDV2("1234567890abcdef1234567890abcdef")
+	const digest = types.Digest("11111111111111111111111111111111")
+
+	const latestPositiveDigestRpcUrl = "https://testing-gold.skia.org/json/v2/latestpositivedigest/1234567890abcdef1234567890abcdef"
+	const latestPositiveDigestResponse = `{"digest":"22222222222222222222222222222222"}`
+	const latestPositiveDigest = types.Digest("22222222222222222222222222222222")
+	latestPositiveImageBytes := imageToPngBytes(t, text.MustToNRGBA(`! SKTEXTSIMPLE
+	2 2
+	0x00000000 0x00000000
+	0x00000000 0x00000000`))
+
+	// The mask ignores the top right pixel.
+	const maskDigest = types.Digest("33333333333333333333333333333333")
+	maskImageBytes := imageToPngBytes(t, text.MustToNRGBA(`! SKTEXTSIMPLE
+	2 2
+	0x000000ff 0xffffffff
+	0x000000ff 0x000000ff`))
+
+	test := func(name string, imageBytes []byte, expected bool) {
+		t.Run(name, func(t *testing.T) {
+			goldClient, ctx, httpClient, dlr := makeGoldClientForMatchImageAgainstBaselineTests(t)
+			defer httpClient.AssertExpectations(t)
+			defer dlr.AssertExpectations(t)
+
+			httpClient.On("Get", latestPositiveDigestRpcUrl).Return(httpResponse(latestPositiveDigestResponse, "200 OK", http.StatusOK), nil)
+			dlr.On("DownloadImage", testutils.AnyContext, "https://testing-gold.skia.org", latestPositiveDigest).Return(latestPositiveImageBytes, nil)
+			dlr.On("DownloadImage", testutils.AnyContext, "https://testing-gold.skia.org", maskDigest).Return(maskImageBytes, nil)
+
+			optionalKeys := map[string]string{
+				imgmatching.AlgorithmNameOptKey:           string(imgmatching.MaskRegionMatching),
+				string(imgmatching.MaskRegionRects):       "0,1,1,2",
+				string(imgmatching.MaskRegionImageDigest): string(maskDigest),
+			}
+
+			actual, algorithmName, err := goldClient.matchImageAgainstBaseline(ctx, testName, traceId, imageBytes, digest, optionalKeys)
+			assert.NoError(t, err)
+			assert.Equal(t, imgmatching.MaskRegionMatching, algorithmName)
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	test(
+		"images different only in masked pixels, returns true",
+		imageToPngBytes(t, text.MustToNRGBA(`! SKTEXTSIMPLE
+		2 2
+		0x00000000 0xffffffff
+		0xffffffff 0x00000000`)),
+		true)
+	test(
+		"images different in unmasked pixel, returns false",
+		imageToPngBytes(t, text.MustToNRGBA(`! SKTEXTSIMPLE
+		2 2
+		0x00000000 0xffffffff
+		0xffffffff 0x00000001`)),
+		false)
+}
+
+func TestCloudClient_MatchImageAgainstBaseline_SSIMMatching_UntriagedImage_Success(t *testing.T) {
+	const testName = types.TestName("my_test")
+	const traceId = tiling.TraceIDV2("1234567890abcdef1234567890abcdef")
+	const digest = types.Digest("11111111111111111111111111111111")
+
+	const latestPositiveDigestRpcUrl = "https://testing-gold.skia.org/json/v2/latestpositivedigest/1234567890abcdef1234567890abcdef"
+	const latestPositiveDigestResponse = `{"digest":"22222222222222222222222222222222"}`
+	const latestPositiveDigest = types.Digest("22222222222222222222222222222222")
+	latestPositiveImageBytes := imageToPngBytes(t, text.MustToNRGBA(`! SKTEXTSIMPLE
+	2 2
+	0x808080ff 0x808080ff
+	0x808080ff 0x808080ff`))
+
+	test := func(name string, imageBytes []byte, expected bool) {
+		t.Run(name, func(t *testing.T) {
+			goldClient, ctx, httpClient, dlr := makeGoldClientForMatchImageAgainstBaselineTests(t)
+			defer httpClient.AssertExpectations(t)
+			defer dlr.AssertExpectations(t)
+
+			httpClient.On("Get", latestPositiveDigestRpcUrl).Return(httpResponse(latestPositiveDigestResponse, "200 OK", http.StatusOK), nil)
+			dlr.On("DownloadImage", testutils.AnyContext, "https://testing-gold.skia.org", latestPositiveDigest).Return(latestPositiveImageBytes, nil)
+
+			optionalKeys := map[string]string{
+				imgmatching.AlgorithmNameOptKey: string(imgmatching.SSIMMatching),
+				string(imgmatching.MinSSIM):     "0.95",
+			}
+
+			actual, algorithmName, err := goldClient.matchImageAgainstBaseline(ctx, testName, traceId, imageBytes, digest, optionalKeys)
+			assert.NoError(t, err)
+			assert.Equal(t, imgmatching.SSIMMatching, algorithmName)
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	test(
+		"perceptually similar images, returns true",
+		imageToPngBytes(t, text.MustToNRGBA(`! SKTEXTSIMPLE
+		2 2
+		0x818181ff 0x808080ff
+		0x808080ff 0x808080ff`)),
+		true)
+	test(
+		"structurally different images, returns false",
+		imageToPngBytes(t, text.MustToNRGBA(`! SKTEXTSIMPLE
+		2 2
+		0x000000ff 0xffffffff
+		0xffffffff 0x000000ff`)),
+		false)
+}
+
 func TestCloudClient_MatchImageAgainstBaseline_UnknownAlgorithm_ReturnsError(t *testing.T) {
 	goldClient, ctx, _, _ := makeGoldClientForMatchImageAgainstBaselineTests(t)
 
@@ -2312,6 +2458,92 @@ func TestCloudClient_GetDigestFromCacheOrGCS_InCache_ReadsCorruptedImageFromDisk
 	assert.Contains(t, err.Error(), "decoding PNG file at "+filepath.Join(wd, digestsDirectory, string(digest))+".png")
 }
 
+func TestCloudClient_Export_WritesBaselineAndPassingImages_Success(t *testing.T) {
+	wd := t.TempDir()
+	outDir := t.TempDir()
+
+	ctx, httpClient, _, gcsDownloader := makeMocks()
+	defer httpClient.AssertExpectations(t)
+	defer gcsDownloader.AssertExpectations(t)
+	exportTime := time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC)
+	ctx = context.WithValue(ctx, now.ContextKey, exportTime)
+
+	hashesResp := httpResponse(mockHashesTxt, "200 OK", http.StatusOK)
+	httpClient.On("Get", "https://testing-gold.skia.org/json/v1/hashes").Return(hashesResp, nil)
+	// These are defined in mockBaselineJSON
+	const positiveDigest = types.Digest("beef00d3a1527db19619ec12a4e0df68")
+	const negativeDigest = types.Digest("badbadbad1325855590527db196112e0")
+	exp := httpResponse(mockBaselineJSON, "200 OK", http.StatusOK)
+	httpClient.On("Get", "https://testing-gold.skia.org/json/v2/expectations").Return(exp, nil)
+
+	// Only the images of passing digests and the extra digests are exported.
+	const maskDigest = types.Digest("11111111111111111111111111111111")
+	positiveBytes := imageToPngBytes(t, image1)
+	maskBytes := imageToPngBytes(t, image2)
+	gcsDownloader.On("DownloadImage", testutils.AnyContext, "https://testing-gold.skia.org", positiveDigest).Return(positiveBytes, nil)
+	gcsDownloader.On("DownloadImage", testutils.AnyContext, "https://testing-gold.skia.org", maskDigest).Return(maskBytes, nil)
+
+	goldClient, err := NewCloudClient(GoldClientConfig{
+		WorkDir:    wd,
+		InstanceID: "testing",
+	})
+	require.NoError(t, err)
+
+	err = goldClient.Export(ctx, outDir, nil, []types.Digest{maskDigest})
+	require.NoError(t, err)
+
+	b, knownHashes, err := localgold.LoadBaseline(outDir)
+	require.NoError(t, err)
+	assert.Equal(t, "https://testing-gold.skia.org", b.GoldURL)
+	assert.Equal(t, exportTime, b.ExportTime)
+	assert.Equal(t, expectations.Baseline{
+		"ThisIsTheOnlyTest": {
+			positiveDigest: expectations.Positive,
+			negativeDigest: expectations.Negative,
+		},
+	}, b.Expectations)
+	assert.Len(t, knownHashes, 4)
+	assert.True(t, knownHashes["a9e1481ebc45c1c4f6720d1119644c20"])
+
+	actualBytes, err := os.ReadFile(filepath.Join(outDir, "images", string(positiveDigest)+".png"))
+	require.NoError(t, err)
+	assert.Equal(t, positiveBytes, actualBytes)
+	actualBytes, err = os.ReadFile(filepath.Join(outDir, "images", string(maskDigest)+".png"))
+	require.NoError(t, err)
+	assert.Equal(t, maskBytes, actualBytes)
+	assert.NoFileExists(t, filepath.Join(outDir, "images", string(negativeDigest)+".png"))
+}
+
+func TestCloudClient_Export_OtherTestRequested_NoImagesExported(t *testing.T) {
+	wd := t.TempDir()
+	outDir := t.TempDir()
+
+	ctx, httpClient, _, gcsDownloader := makeMocks()
+	defer httpClient.AssertExpectations(t)
+	defer gcsDownloader.AssertExpectations(t)
+
+	hashesResp := httpResponse(mockHashesTxt, "200 OK", http.StatusOK)
+	httpClient.On("Get", "https://testing-gold.skia.org/json/v1/hashes").Return(hashesResp, nil)
+	exp := httpResponse(mockBaselineJSON, "200 OK", http.StatusOK)
+	httpClient.On("Get", "https://testing-gold.skia.org/json/v2/expectations").Return(exp, nil)
+
+	goldClient, err := NewCloudClient(GoldClientConfig{
+		WorkDir:    wd,
+		InstanceID: "testing",
+	})
+	require.NoError(t, err)
+
+	err = goldClient.Export(ctx, outDir, []types.TestName{"SomeOtherTest"}, nil)
+	require.NoError(t, err)
+
+	b, _, err := localgold.LoadBaseline(outDir)
+	require.NoError(t, err)
+	assert.Len(t, b.Expectations, 1)
+	entries, err := os.ReadDir(filepath.Join(outDir, "images"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
 func TestCloudClient_Whoami_Success(t *testing.T) {
 	// This test reads and writes a small amount of data from/to disk.
 
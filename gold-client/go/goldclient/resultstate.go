@@ -51,6 +51,7 @@ type resultState struct {
 	Bucket          string
 	KnownHashes     types.DigestSet
 	Expectations    expectations.Baseline
+	CustomLabels    []expectations.LabelDefinition
 }
 
 // newResultState creates a new instance of resultState
@@ -147,6 +148,7 @@ func (r *resultState) loadExpectations(ctx context.Context) error {
 	}
 
 	r.Expectations = exp.Expectations
+	r.CustomLabels = exp.CustomLabels
 	return nil
 }
 
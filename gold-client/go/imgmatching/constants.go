@@ -10,9 +10,11 @@ type AlgorithmName string
 const (
 	ExactMatching               = AlgorithmName("exact")
 	FuzzyMatching               = AlgorithmName("fuzzy")
+	MaskRegionMatching          = AlgorithmName("mask_region")
 	PositiveIfOnlyImageMatching = AlgorithmName("positive_if_only_image")
 	SampleAreaMatching          = AlgorithmName("sample_area")
 	SobelFuzzyMatching          = AlgorithmName("sobel")
+	SSIMMatching                = AlgorithmName("ssim")
 )
 
 // AlgorithmParamOptKey is an optional key indicating a parameter for the specified non-exact image
@@ -21,19 +23,21 @@ type AlgorithmParamOptKey string
 
 const (
 	// MaxDifferentPixels is the optional key used to specify the MaxDifferentPixels parameter of
-	// algorithms FuzzyMatching and SobelFuzzyMatching.
+	// algorithms FuzzyMatching, SobelFuzzyMatching and MaskRegionMatching. It is optional for the
+	// latter, and defaults to 0.
 	MaxDifferentPixels = AlgorithmParamOptKey("fuzzy_max_different_pixels")
 
 	// PixelDeltaThreshold is the optional key used to specify the PixelDeltaThreshold parameter of
-	// algorithms FuzzyMatching and SobelFuzzyMatching.
+	// algorithms FuzzyMatching, SobelFuzzyMatching and MaskRegionMatching.
 	PixelDeltaThreshold = AlgorithmParamOptKey("fuzzy_pixel_delta_threshold")
 
 	// PixelPerChannelDeltaThreshold is the optional key used to specify the
-	// PixelPerChannelDeltaThreshold parameter of algorithms FuzzyMatching and SobelFuzzyMatching.
+	// PixelPerChannelDeltaThreshold parameter of algorithms FuzzyMatching, SobelFuzzyMatching and
+	// MaskRegionMatching.
 	PixelPerChannelDeltaThreshold = AlgorithmParamOptKey("fuzzy_pixel_per_channel_delta_threshold")
 
 	// IgnoredBorderThickness is the optional key used to specify the IgnoredBorderThickness
-	// parameter of algorithms FuzzyMatching and SobelFuzzyMatching.
+	// parameter of algorithms FuzzyMatching, SobelFuzzyMatching and MaskRegionMatching.
 	IgnoredBorderThickness = AlgorithmParamOptKey("fuzzy_ignored_border_thickness")
 
 	// EdgeThreshold is the optional key used to specify the EdgeThreshold parameter of the
@@ -51,4 +55,19 @@ const (
 	// SampleAreaChannelDeltaThreshold is the optional key used to specify the
 	// SampleAreaChannelDeltaThreshold parameter of the SampleAreaMatching algorithm.
 	SampleAreaChannelDeltaThreshold = AlgorithmParamOptKey("sample_area_channel_delta_threshold")
+
+	// MaskRegionRects is the optional key used to specify the Regions parameter of the
+	// MaskRegionMatching algorithm, as a semicolon-separated list of "left,top,right,bottom"
+	// rectangles in pixels, where right and bottom are exclusive, e.g. "0,0,100,20;50,50,60,70".
+	MaskRegionRects = AlgorithmParamOptKey("mask_region_rects")
+
+	// MaskRegionImageDigest is the optional key used to specify the MaskDigest parameter of the
+	// MaskRegionMatching algorithm, i.e. the digest of a previously uploaded image in which
+	// pixels that are neither black nor transparent are ignored. At least one of MaskRegionRects
+	// and MaskRegionImageDigest must be set.
+	MaskRegionImageDigest = AlgorithmParamOptKey("mask_region_image_digest")
+
+	// MinSSIM is the optional key used to specify the MinSSIM parameter of the SSIMMatching
+	// algorithm, a number between 0 and 1, e.g. "0.98".
+	MinSSIM = AlgorithmParamOptKey("ssim_min_similarity")
 )
@@ -2,6 +2,7 @@ package imgmatching
 
 import (
 	"fmt"
+	"image"
 	"math"
 	"strconv"
 	"strings"
@@ -9,9 +10,13 @@ import (
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/gold-client/go/imgmatching/exact"
 	"go.skia.org/infra/gold-client/go/imgmatching/fuzzy"
+	"go.skia.org/infra/gold-client/go/imgmatching/mask_region"
 	"go.skia.org/infra/gold-client/go/imgmatching/positive_if_only_image"
 	"go.skia.org/infra/gold-client/go/imgmatching/sample_area"
 	"go.skia.org/infra/gold-client/go/imgmatching/sobel"
+	"go.skia.org/infra/gold-client/go/imgmatching/ssim"
+	"go.skia.org/infra/golden/go/types"
+	"go.skia.org/infra/golden/go/validation"
 )
 
 // MakeMatcher takes a map of optional keys and returns the specified image matching algorithm
@@ -39,6 +44,13 @@ func MakeMatcher(optionalKeys map[string]string) (AlgorithmName, Matcher, error)
 		}
 		return FuzzyMatching, matcher, nil
 
+	case MaskRegionMatching:
+		matcher, err := makeMaskRegionMatcher(optionalKeys)
+		if err != nil {
+			return "", nil, skerr.Wrap(err)
+		}
+		return MaskRegionMatching, matcher, nil
+
 	case PositiveIfOnlyImageMatching:
 		return PositiveIfOnlyImageMatching, &positive_if_only_image.Matcher{}, nil
 
@@ -56,6 +68,13 @@ func MakeMatcher(optionalKeys map[string]string) (AlgorithmName, Matcher, error)
 		}
 		return SobelFuzzyMatching, matcher, nil
 
+	case SSIMMatching:
+		matcher, err := makeSSIMMatcher(optionalKeys)
+		if err != nil {
+			return "", nil, skerr.Wrap(err)
+		}
+		return SSIMMatching, matcher, nil
+
 	default:
 		return "", nil, skerr.Fmt("unrecognized image matching algorithm: %q", algorithmName)
 	}
@@ -64,7 +83,13 @@ func MakeMatcher(optionalKeys map[string]string) (AlgorithmName, Matcher, error)
 // makeFuzzyMatcher returns a fuzzy.Matcher instance set up with the parameter values in the
 // given optional keys map.
 func makeFuzzyMatcher(optionalKeys map[string]string) (*fuzzy.Matcher, error) {
-	maxDifferentPixels, err := getAndValidateIntParameter(MaxDifferentPixels, 0, math.MaxInt32, true /* =required */, optionalKeys)
+	return makeFuzzyMatcherWithRequiredMaxDifferentPixels(optionalKeys, true /* =required */)
+}
+
+// makeFuzzyMatcherWithRequiredMaxDifferentPixels is like makeFuzzyMatcher, but the
+// MaxDifferentPixels parameter only needs to be present if required is true.
+func makeFuzzyMatcherWithRequiredMaxDifferentPixels(optionalKeys map[string]string, required bool) (*fuzzy.Matcher, error) {
+	maxDifferentPixels, err := getAndValidateIntParameter(MaxDifferentPixels, 0, math.MaxInt32, required, optionalKeys)
 	if err != nil {
 		return nil, skerr.Wrap(err)
 	}
@@ -163,6 +188,116 @@ func makeSobelFuzzyMatcher(optionalKeys map[string]string) (*sobel.Matcher, erro
 	}, nil
 }
 
+// makeMaskRegionMatcher returns a mask_region.Matcher instance set up with the parameter values
+// in the given optional keys map. The mask image, if any, is not loaded.
+func makeMaskRegionMatcher(optionalKeys map[string]string) (*mask_region.Matcher, error) {
+	// Instantiate the fuzzy.Matcher that will be embedded in the mask_region.Matcher. The masked
+	// images are compared exactly unless the fuzzy parameters are set.
+	fuzzyMatcher, err := makeFuzzyMatcherWithRequiredMaxDifferentPixels(optionalKeys, false /* =required */)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+
+	regions, err := getAndValidateRectsParameter(MaskRegionRects, optionalKeys)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+
+	maskDigest, ok := optionalKeys[string(MaskRegionImageDigest)]
+	if ok && !validation.IsValidDigest(maskDigest) {
+		return nil, skerr.Fmt("image matching parameter %q must be a valid digest, was: %q", MaskRegionImageDigest, maskDigest)
+	}
+
+	if len(regions) == 0 && maskDigest == "" {
+		return nil, skerr.Fmt("at least one of %s and %s must be set", MaskRegionRects, MaskRegionImageDigest)
+	}
+
+	return &mask_region.Matcher{
+		Matcher:    *fuzzyMatcher,
+		Regions:    regions,
+		MaskDigest: types.Digest(maskDigest),
+	}, nil
+}
+
+// makeSSIMMatcher returns a ssim.Matcher instance set up with the parameter values in the given
+// optional keys map.
+func makeSSIMMatcher(optionalKeys map[string]string) (*ssim.Matcher, error) {
+	minSSIM, err := getAndValidateFloatParameter(MinSSIM, 0, 1, optionalKeys)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+
+	return &ssim.Matcher{
+		MinSSIM: minSSIM,
+	}, nil
+}
+
+// getAndValidateRectsParameter extracts and validates the given optional list of rectangles from
+// the given map of optional keys. See MaskRegionRects for the format.
+//
+// If the parameter is not present in the map of optional keys, nil will be returned.
+func getAndValidateRectsParameter(name AlgorithmParamOptKey, optionalKeys map[string]string) ([]image.Rectangle, error) {
+	stringVal, ok := optionalKeys[string(name)]
+	if !ok {
+		return nil, nil
+	}
+
+	// Value cannot be empty.
+	if strings.TrimSpace(stringVal) == "" {
+		return nil, skerr.Fmt("image matching parameter %q cannot be empty", name)
+	}
+
+	var rects []image.Rectangle
+	for _, rectStr := range strings.Split(stringVal, ";") {
+		parts := strings.Split(rectStr, ",")
+		if len(parts) != 4 {
+			return nil, skerr.Fmt("image matching parameter %q must be a list of left,top,right,bottom rectangles, was: %q", name, stringVal)
+		}
+		var coords [4]int
+		for i, part := range parts {
+			c, err := strconv.ParseInt(strings.TrimSpace(part), 0, 32)
+			if err != nil {
+				return nil, skerr.Fmt("parsing rectangle %q for image matching parameter %q: %q", rectStr, name, err.Error())
+			}
+			if c < 0 {
+				return nil, skerr.Fmt("image matching parameter %q cannot have negative coordinates, was: %q", name, rectStr)
+			}
+			coords[i] = int(c)
+		}
+		if coords[2] <= coords[0] || coords[3] <= coords[1] {
+			return nil, skerr.Fmt("image matching parameter %q cannot have empty rectangles, was: %q", name, rectStr)
+		}
+		rects = append(rects, image.Rect(coords[0], coords[1], coords[2], coords[3]))
+	}
+	return rects, nil
+}
+
+// getAndValidateFloatParameter extracts and validates the given required floating point parameter
+// from the given map of optional keys. The value must be between min and max inclusive.
+func getAndValidateFloatParameter(name AlgorithmParamOptKey, min, max float64, optionalKeys map[string]string) (float64, error) {
+	stringVal, ok := optionalKeys[string(name)]
+	if !ok {
+		return 0, skerr.Fmt("required image matching parameter not found: %q", name)
+	}
+
+	// Value cannot be empty.
+	if strings.TrimSpace(stringVal) == "" {
+		return 0, skerr.Fmt("image matching parameter %q cannot be empty", name)
+	}
+
+	floatVal, err := strconv.ParseFloat(stringVal, 64)
+	if err != nil {
+		return 0, skerr.Fmt("parsing float value for image matching parameter %q: %q", name, err.Error())
+	}
+
+	// Value must be between bounds. The negated comparison also rejects NaN.
+	if !(floatVal >= min && floatVal <= max) {
+		return 0, skerr.Fmt("image matching parameter %q must be between %g and %g, was: %s", name, min, max, stringVal)
+	}
+
+	return floatVal, nil
+}
+
 // getAndValidateIntParameter extracts and validates the given required integer parameter from the
 // given map of optional keys.
 //
@@ -2,15 +2,18 @@ package imgmatching
 
 import (
 	"fmt"
+	"image"
 	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"go.skia.org/infra/gold-client/go/imgmatching/exact"
 	"go.skia.org/infra/gold-client/go/imgmatching/fuzzy"
+	"go.skia.org/infra/gold-client/go/imgmatching/mask_region"
 	"go.skia.org/infra/gold-client/go/imgmatching/positive_if_only_image"
 	"go.skia.org/infra/gold-client/go/imgmatching/sample_area"
 	"go.skia.org/infra/gold-client/go/imgmatching/sobel"
+	"go.skia.org/infra/gold-client/go/imgmatching/ssim"
 )
 
 func TestMakeMatcher_UnknownAlgorithm_ReturnsError(t *testing.T) {
@@ -981,3 +984,203 @@ func TestMakeMatcher_SampleAreaMatching_Success(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeMatcher_MaskRegionMatching_Success(t *testing.T) {
+	const maskDigest = "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name         string
+		optionalKeys map[string]string
+		want         mask_region.Matcher
+	}{
+		{
+			name: "single rectangle, fuzzy parameters default to exact matching",
+			optionalKeys: map[string]string{
+				string(MaskRegionRects): "1,2,3,4",
+			},
+			want: mask_region.Matcher{
+				Regions: []image.Rectangle{image.Rect(1, 2, 3, 4)},
+			},
+		},
+		{
+			name: "multiple rectangles with spaces and mask digest",
+			optionalKeys: map[string]string{
+				string(MaskRegionRects):       "0,0,100,20; 50, 50, 60, 70",
+				string(MaskRegionImageDigest): maskDigest,
+			},
+			want: mask_region.Matcher{
+				Regions:    []image.Rectangle{image.Rect(0, 0, 100, 20), image.Rect(50, 50, 60, 70)},
+				MaskDigest: maskDigest,
+			},
+		},
+		{
+			name: "mask digest only, with fuzzy parameters",
+			optionalKeys: map[string]string{
+				string(MaskRegionImageDigest):  maskDigest,
+				string(MaxDifferentPixels):     "10",
+				string(PixelDeltaThreshold):    "20",
+				string(IgnoredBorderThickness): "1",
+			},
+			want: mask_region.Matcher{
+				Matcher: fuzzy.Matcher{
+					MaxDifferentPixels:     10,
+					PixelDeltaThreshold:    20,
+					IgnoredBorderThickness: 1,
+				},
+				MaskDigest: maskDigest,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.optionalKeys[AlgorithmNameOptKey] = string(MaskRegionMatching)
+
+			algorithmName, matcher, err := MakeMatcher(tc.optionalKeys)
+
+			assert.NoError(t, err)
+			assert.Equal(t, MaskRegionMatching, algorithmName)
+			assert.Equal(t, &tc.want, matcher)
+		})
+	}
+}
+
+func TestMakeMatcher_MaskRegionMatching_Error(t *testing.T) {
+	tests := []struct {
+		name         string
+		optionalKeys map[string]string
+		error        string
+	}{
+		{
+			name:         "no rectangles or mask, returns error",
+			optionalKeys: map[string]string{},
+			error:        "at least one of mask_region_rects and mask_region_image_digest must be set",
+		},
+		{
+			name:         "empty rectangles, returns error",
+			optionalKeys: map[string]string{string(MaskRegionRects): " "},
+			error:        `image matching parameter "mask_region_rects" cannot be empty`,
+		},
+		{
+			name:         "too few coordinates, returns error",
+			optionalKeys: map[string]string{string(MaskRegionRects): "0,0,10,10;1,2,3"},
+			error:        `must be a list of left,top,right,bottom rectangles, was: "0,0,10,10;1,2,3"`,
+		},
+		{
+			name:         "non-integer coordinate, returns error",
+			optionalKeys: map[string]string{string(MaskRegionRects): "0,0,10,ten"},
+			error:        `parsing rectangle "0,0,10,ten" for image matching parameter "mask_region_rects"`,
+		},
+		{
+			name:         "negative coordinate, returns error",
+			optionalKeys: map[string]string{string(MaskRegionRects): "-1,0,10,10"},
+			error:        `cannot have negative coordinates, was: "-1,0,10,10"`,
+		},
+		{
+			name:         "empty rectangle, returns error",
+			optionalKeys: map[string]string{string(MaskRegionRects): "5,0,5,10"},
+			error:        `cannot have empty rectangles, was: "5,0,5,10"`,
+		},
+		{
+			name:         "invalid mask digest, returns error",
+			optionalKeys: map[string]string{string(MaskRegionImageDigest): "not a digest"},
+			error:        `image matching parameter "mask_region_image_digest" must be a valid digest, was: "not a digest"`,
+		},
+		{
+			name: "invalid fuzzy parameter, returns error",
+			optionalKeys: map[string]string{
+				string(MaskRegionRects):    "0,0,10,10",
+				string(MaxDifferentPixels): "-1",
+			},
+			error: `image matching parameter "fuzzy_max_different_pixels" must be at least 0, was: -1`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.optionalKeys[AlgorithmNameOptKey] = string(MaskRegionMatching)
+
+			_, _, err := MakeMatcher(tc.optionalKeys)
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.error)
+		})
+	}
+}
+
+func TestMakeMatcher_SSIMMatching(t *testing.T) {
+	tests := []struct {
+		name    string
+		minSSIM string
+		want    ssim.Matcher
+		error   string
+	}{
+		{
+			name:    "value = lower limit, success",
+			minSSIM: "0",
+			want:    ssim.Matcher{MinSSIM: 0},
+		},
+		{
+			name:    "value within bounds, success",
+			minSSIM: "0.98",
+			want:    ssim.Matcher{MinSSIM: 0.98},
+		},
+		{
+			name:    "value = upper limit, success",
+			minSSIM: "1",
+			want:    ssim.Matcher{MinSSIM: 1},
+		},
+		{
+			name:    "missing, returns error",
+			minSSIM: missing,
+			error:   `required image matching parameter not found: "ssim_min_similarity"`,
+		},
+		{
+			name:    "empty, returns error",
+			minSSIM: "",
+			error:   `image matching parameter "ssim_min_similarity" cannot be empty`,
+		},
+		{
+			name:    "not a number, returns error",
+			minSSIM: "very similar",
+			error:   `parsing float value for image matching parameter "ssim_min_similarity"`,
+		},
+		{
+			name:    "NaN, returns error",
+			minSSIM: "NaN",
+			error:   `image matching parameter "ssim_min_similarity" must be between 0 and 1, was: NaN`,
+		},
+		{
+			name:    "value < lower limit, returns error",
+			minSSIM: "-0.5",
+			error:   `image matching parameter "ssim_min_similarity" must be between 0 and 1, was: -0.5`,
+		},
+		{
+			name:    "value > upper limit, returns error",
+			minSSIM: "1.01",
+			error:   `image matching parameter "ssim_min_similarity" must be between 0 and 1, was: 1.01`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			optionalKeys := map[string]string{
+				AlgorithmNameOptKey: string(SSIMMatching),
+			}
+			if tc.minSSIM != missing {
+				optionalKeys[string(MinSSIM)] = tc.minSSIM
+			}
+
+			algorithmName, matcher, err := MakeMatcher(optionalKeys)
+
+			if tc.error != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.error)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, SSIMMatching, algorithmName)
+				assert.Equal(t, &tc.want, matcher)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package mask_region
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"go.skia.org/infra/gold-client/go/imgmatching/fuzzy"
+	"go.skia.org/infra/golden/go/types"
+)
+
+// Matcher is an image matching algorithm.
+//
+// It extends the fuzzy.Matcher algorithm by ignoring known dynamic areas of the images, such as a
+// clock or a blinking cursor. The ignored areas are the union of:
+//
+//   - The rectangles in Regions, in image coordinates.
+//   - The pixels of the Mask image that are neither black nor fully transparent. The mask is
+//     aligned with the top left corner of the images, and any pixels outside the mask are not
+//     ignored. Masks are referenced by digest via the optional keys, and the image must be set in
+//     Mask (e.g. downloaded by goldctl) before calling Match.
+//
+// The algorithm sets the ignored pixels of *both* images to transparent black, and passes the
+// resulting images to the fuzzy.Matcher algorithm (using parameters MaxDifferentPixels,
+// PixelDeltaThreshold, PixelPerChannelDeltaThreshold and IgnoredBorderThickness) and returns its
+// return value. If all the fuzzy parameters are zero, the pixels that are not ignored must match
+// exactly.
+type Matcher struct {
+	fuzzy.Matcher
+	Regions    []image.Rectangle
+	MaskDigest types.Digest
+	Mask       image.Image
+
+	// Debug information about the last pair of matched images.
+	numIgnoredPixels int
+}
+
+// Match implements the imgmatching.Matcher interface.
+func (m *Matcher) Match(expected, actual image.Image) bool {
+	m.numIgnoredPixels = 0
+
+	// Expected image will be nil if no recent positive image is found.
+	if expected == nil {
+		return false
+	}
+
+	// Images must be the same size.
+	if !expected.Bounds().Eq(actual.Bounds()) {
+		return false
+	}
+
+	// A mask was requested but not provided, so we cannot know which pixels to ignore.
+	if m.MaskDigest != "" && m.Mask == nil {
+		return false
+	}
+
+	ignored := m.ignoredPixels(expected.Bounds())
+	return m.Matcher.Match(applyMask(expected, ignored), applyMask(actual, ignored))
+}
+
+// NumIgnoredPixels returns the number of pixels that were ignored in the last Match method call.
+func (m *Matcher) NumIgnoredPixels() int { return m.numIgnoredPixels }
+
+// ignoredPixels returns an image of the given bounds in which the ignored pixels are opaque.
+func (m *Matcher) ignoredPixels(bounds image.Rectangle) *image.Alpha {
+	ignored := image.NewAlpha(bounds)
+	for _, r := range m.Regions {
+		draw.Draw(ignored, r.Intersect(bounds), image.Opaque, image.Point{}, draw.Src)
+	}
+	if m.Mask != nil {
+		maskBounds := m.Mask.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				mx, my := maskBounds.Min.X+x-bounds.Min.X, maskBounds.Min.Y+y-bounds.Min.Y
+				if !image.Pt(mx, my).In(maskBounds) {
+					continue
+				}
+				c := color.NRGBAModel.Convert(m.Mask.At(mx, my)).(color.NRGBA)
+				if c.A != 0 && (c.R != 0 || c.G != 0 || c.B != 0) {
+					ignored.SetAlpha(x, y, color.Alpha{A: 0xff})
+				}
+			}
+		}
+	}
+	for _, a := range ignored.Pix {
+		if a != 0 {
+			m.numIgnoredPixels++
+		}
+	}
+	return ignored
+}
+
+// applyMask returns a copy of the input image in which all ignored pixels are replaced with
+// transparent black pixels. Input and ignored images must have the same bounds.
+func applyMask(img image.Image, ignored *image.Alpha) image.Image {
+	outputImg := image.NewNRGBA(img.Bounds())
+	draw.Draw(outputImg, img.Bounds(), img, img.Bounds().Min, draw.Src)
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			if ignored.AlphaAt(x, y).A != 0 {
+				outputImg.SetNRGBA(x, y, color.NRGBA{})
+			}
+		}
+	}
+	return outputImg
+}
@@ -0,0 +1,111 @@
+package mask_region
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.skia.org/infra/gold-client/go/imgmatching/fuzzy"
+	"go.skia.org/infra/golden/go/image/text"
+)
+
+var (
+	expectedImage = text.MustToNRGBA(`! SKTEXTSIMPLE
+	4 3
+	0x112233ff 0x112233ff 0x112233ff 0x112233ff
+	0x112233ff 0x000000ff 0x000000ff 0x112233ff
+	0x112233ff 0x112233ff 0x112233ff 0x112233ff`)
+
+	// clockImage differs from expectedImage only in the two pixels in the middle.
+	clockImage = text.MustToNRGBA(`! SKTEXTSIMPLE
+	4 3
+	0x112233ff 0x112233ff 0x112233ff 0x112233ff
+	0x112233ff 0xffffffff 0x00ff00ff 0x112233ff
+	0x112233ff 0x112233ff 0x112233ff 0x112233ff`)
+
+	// clockAndCornerImage also differs from expectedImage in the top left corner.
+	clockAndCornerImage = text.MustToNRGBA(`! SKTEXTSIMPLE
+	4 3
+	0x112233ff 0x112233ff 0x112233ff 0x112233ff
+	0x112233ff 0xffffffff 0x00ff00ff 0x112233ff
+	0x112233ff 0x112233ff 0x112233ff 0x112234ff`)
+
+	// clockMask ignores the two pixels in the middle.
+	clockMask = text.MustToNRGBA(`! SKTEXTSIMPLE
+	4 3
+	0x000000ff 0x000000ff 0x000000ff 0x000000ff
+	0x000000ff 0xffffffff 0xffffffff 0x000000ff
+	0x00000000 0xffffff00 0x000000ff 0x000000ff`)
+)
+
+func TestMatcher_Match_NoRegions_ComparesExactly(t *testing.T) {
+	m := Matcher{}
+	assert.True(t, m.Match(expectedImage, expectedImage))
+	assert.False(t, m.Match(expectedImage, clockImage))
+	assert.Equal(t, 0, m.NumIgnoredPixels())
+}
+
+func TestMatcher_Match_RegionsCoverDifferences_ReturnsTrue(t *testing.T) {
+	m := Matcher{Regions: []image.Rectangle{image.Rect(1, 1, 3, 2)}}
+	assert.True(t, m.Match(expectedImage, clockImage))
+	assert.True(t, m.Match(clockImage, expectedImage))
+	assert.Equal(t, 2, m.NumIgnoredPixels())
+}
+
+func TestMatcher_Match_RegionsPartiallyCoverDifferences_ReturnsFalse(t *testing.T) {
+	m := Matcher{Regions: []image.Rectangle{image.Rect(1, 1, 2, 2)}}
+	assert.False(t, m.Match(expectedImage, clockImage))
+	assert.Equal(t, 1, m.NumIgnoredPixels())
+}
+
+func TestMatcher_Match_RegionsOutsideImage_AreClipped(t *testing.T) {
+	m := Matcher{Regions: []image.Rectangle{image.Rect(1, 1, 100, 2), image.Rect(-5, -5, -1, -1)}}
+	assert.True(t, m.Match(expectedImage, clockImage))
+	assert.Equal(t, 3, m.NumIgnoredPixels())
+}
+
+func TestMatcher_Match_MaskCoversDifferences_ReturnsTrue(t *testing.T) {
+	m := Matcher{MaskDigest: "00000000000000000000000000000000", Mask: clockMask}
+	assert.True(t, m.Match(expectedImage, clockImage))
+	// Black and transparent mask pixels are not ignored.
+	assert.Equal(t, 2, m.NumIgnoredPixels())
+}
+
+func TestMatcher_Match_SmallerMask_PixelsOutsideMaskNotIgnored(t *testing.T) {
+	m := Matcher{Mask: clockMask.SubImage(image.Rect(0, 0, 2, 2))}
+	assert.False(t, m.Match(expectedImage, clockImage))
+	assert.Equal(t, 1, m.NumIgnoredPixels())
+
+	// The mask is aligned with the top left corner of the images even if its bounds do not start
+	// at the origin.
+	m = Matcher{Mask: clockMask.SubImage(image.Rect(1, 1, 3, 2))}
+	assert.False(t, m.Match(expectedImage, clockImage))
+	assert.Equal(t, 2, m.NumIgnoredPixels())
+}
+
+func TestMatcher_Match_MaskDigestWithoutMask_ReturnsFalse(t *testing.T) {
+	m := Matcher{MaskDigest: "00000000000000000000000000000000"}
+	assert.False(t, m.Match(expectedImage, expectedImage))
+}
+
+func TestMatcher_Match_FuzzyParameters_AppliedToPixelsNotIgnored(t *testing.T) {
+	m := Matcher{
+		Matcher: fuzzy.Matcher{MaxDifferentPixels: 1, PixelDeltaThreshold: 1},
+		Regions: []image.Rectangle{image.Rect(1, 1, 3, 2)},
+	}
+	assert.True(t, m.Match(expectedImage, clockAndCornerImage))
+	assert.Equal(t, 1, m.NumDifferentPixels())
+
+	m.PixelDeltaThreshold = 0
+	assert.False(t, m.Match(expectedImage, clockAndCornerImage))
+}
+
+func TestMatcher_Match_DifferentSizeImages_ReturnsFalse(t *testing.T) {
+	m := Matcher{Regions: []image.Rectangle{image.Rect(0, 0, 100, 100)}}
+	assert.False(t, m.Match(expectedImage, image.NewNRGBA(image.Rect(0, 0, 3, 3))))
+}
+
+func TestMatcher_Match_NoExpectedImage_ReturnsFalse(t *testing.T) {
+	m := Matcher{}
+	assert.False(t, m.Match(nil, expectedImage))
+}
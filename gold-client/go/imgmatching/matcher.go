@@ -5,9 +5,11 @@ import (
 
 	"go.skia.org/infra/gold-client/go/imgmatching/exact"
 	"go.skia.org/infra/gold-client/go/imgmatching/fuzzy"
+	"go.skia.org/infra/gold-client/go/imgmatching/mask_region"
 	"go.skia.org/infra/gold-client/go/imgmatching/positive_if_only_image"
 	"go.skia.org/infra/gold-client/go/imgmatching/sample_area"
 	"go.skia.org/infra/gold-client/go/imgmatching/sobel"
+	"go.skia.org/infra/gold-client/go/imgmatching/ssim"
 )
 
 // Matcher represents a generic image matching algorithm.
@@ -20,6 +22,8 @@ type Matcher interface {
 // Note: this is done here instead of in their respective packages to prevent import cycles.
 var _ Matcher = (*exact.Matcher)(nil)
 var _ Matcher = (*fuzzy.Matcher)(nil)
+var _ Matcher = (*mask_region.Matcher)(nil)
 var _ Matcher = (*positive_if_only_image.Matcher)(nil)
 var _ Matcher = (*sample_area.Matcher)(nil)
 var _ Matcher = (*sobel.Matcher)(nil)
+var _ Matcher = (*ssim.Matcher)(nil)
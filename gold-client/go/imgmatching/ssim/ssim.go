@@ -0,0 +1,47 @@
+package ssim
+
+import (
+	"image"
+
+	"go.skia.org/infra/golden/go/diff"
+)
+
+// Matcher is a perceptual image matching algorithm.
+//
+// It considers two images to be equal if they are of equal size and the mean structural
+// similarity (SSIM)[1] of their luma is at least MinSSIM. SSIM is 1 for identical images and
+// barely changes for antialiasing or slight color differences, but drops quickly if shapes move,
+// appear or disappear. See diff.SSIM for the details of the computation.
+//
+// Valid MinSSIM values are 0 to 1 inclusive. A MinSSIM of 1 is equivalent to exact matching of
+// the luma of both images.
+//
+// [1] https://en.wikipedia.org/wiki/Structural_similarity
+type Matcher struct {
+	MinSSIM float64
+
+	// Debug information about the last pair of matched images.
+	actualSSIM float64
+}
+
+// Match implements the imgmatching.Matcher interface.
+func (m *Matcher) Match(expected, actual image.Image) bool {
+	m.actualSSIM = 0
+
+	// Expected image will be nil if no recent positive image is found.
+	if expected == nil {
+		return false
+	}
+
+	// Images must be the same size.
+	if !expected.Bounds().Eq(actual.Bounds()) {
+		return false
+	}
+
+	m.actualSSIM = float64(diff.SSIM(diff.GetNRGBA(expected), diff.GetNRGBA(actual)))
+	return m.actualSSIM >= m.MinSSIM
+}
+
+// SSIM returns the structural similarity of the images in the last Match method call, or 0 if
+// they could not be compared.
+func (m *Matcher) SSIM() float64 { return m.actualSSIM }
@@ -0,0 +1,69 @@
+package ssim
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.skia.org/infra/golden/go/image/text"
+)
+
+var (
+	grayImage = text.MustToNRGBA(`! SKTEXTSIMPLE
+	4 4
+	0x808080ff 0x808080ff 0x808080ff 0x808080ff
+	0x808080ff 0x808080ff 0x808080ff 0x808080ff
+	0x808080ff 0x808080ff 0x808080ff 0x808080ff
+	0x808080ff 0x808080ff 0x808080ff 0x808080ff`)
+
+	// slightlyDifferentImage has a single pixel that is barely lighter than grayImage.
+	slightlyDifferentImage = text.MustToNRGBA(`! SKTEXTSIMPLE
+	4 4
+	0x808080ff 0x808080ff 0x808080ff 0x808080ff
+	0x808080ff 0x828282ff 0x808080ff 0x808080ff
+	0x808080ff 0x808080ff 0x808080ff 0x808080ff
+	0x808080ff 0x808080ff 0x808080ff 0x808080ff`)
+
+	// stripedImage has the same mean color as grayImage, but a completely different structure.
+	stripedImage = text.MustToNRGBA(`! SKTEXTSIMPLE
+	4 4
+	0x000000ff 0xffffffff 0x000000ff 0xffffffff
+	0x000000ff 0xffffffff 0x000000ff 0xffffffff
+	0x000000ff 0xffffffff 0x000000ff 0xffffffff
+	0x000000ff 0xffffffff 0x000000ff 0xffffffff`)
+)
+
+func TestMatcher_Match_IdenticalImages_ReturnsTrue(t *testing.T) {
+	m := Matcher{MinSSIM: 1}
+	assert.True(t, m.Match(grayImage, grayImage))
+	assert.Equal(t, 1.0, m.SSIM())
+}
+
+func TestMatcher_Match_SlightlyDifferentImages_ReturnsTrueIfAboveThreshold(t *testing.T) {
+	m := Matcher{MinSSIM: 0.95}
+	assert.True(t, m.Match(grayImage, slightlyDifferentImage))
+	assert.Less(t, m.SSIM(), 1.0)
+	assert.Greater(t, m.SSIM(), 0.95)
+
+	m = Matcher{MinSSIM: 1}
+	assert.False(t, m.Match(grayImage, slightlyDifferentImage))
+}
+
+func TestMatcher_Match_DifferentStructure_ReturnsFalse(t *testing.T) {
+	m := Matcher{MinSSIM: 0.5}
+	assert.False(t, m.Match(grayImage, stripedImage))
+	assert.Less(t, m.SSIM(), 0.5)
+	assert.False(t, m.Match(stripedImage, grayImage))
+	assert.Less(t, m.SSIM(), 0.5)
+}
+
+func TestMatcher_Match_DifferentSizeImages_ReturnsFalse(t *testing.T) {
+	m := Matcher{MinSSIM: 0}
+	assert.False(t, m.Match(grayImage, image.NewNRGBA(image.Rect(0, 0, 3, 4))))
+	assert.Equal(t, 0.0, m.SSIM())
+}
+
+func TestMatcher_Match_NoExpectedImage_ReturnsFalse(t *testing.T) {
+	m := Matcher{MinSSIM: 0}
+	assert.False(t, m.Match(nil, grayImage))
+}
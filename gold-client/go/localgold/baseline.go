@@ -0,0 +1,154 @@
+package localgold
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/golden/go/expectations"
+	"go.skia.org/infra/golden/go/types"
+)
+
+const (
+	// baselineFile is the name of the file in a baseline directory that holds the Baseline.
+	baselineFile = "baseline.json"
+
+	// knownHashesFile is the name of the file in a baseline directory that holds the digests known
+	// to the Gold instance, one per line.
+	knownHashesFile = "hashes.txt"
+
+	// imagesDirectory is the directory inside a baseline directory that holds the PNG images of
+	// the exported digests, named <digest>.png.
+	imagesDirectory = "images"
+)
+
+// Baseline is a baseline exported from a Gold instance, which allows images to be compared
+// against it without access to the instance.
+type Baseline struct {
+	// GoldURL is the URL of the instance the baseline was exported from.
+	GoldURL string `json:"gold_url"`
+
+	// ChangelistID and CodeReviewSystem identify the changelist whose expectations were merged
+	// onto the baseline of the primary branch, if any.
+	ChangelistID     string `json:"cl_id,omitempty"`
+	CodeReviewSystem string `json:"crs,omitempty"`
+
+	// ExportTime is when the baseline was exported.
+	ExportTime time.Time `json:"export_time"`
+
+	// Expectations are the triaged digests of each test.
+	Expectations expectations.Baseline `json:"expectations"`
+
+	// CustomLabels are the labels configured for the instance in addition to positive and negative.
+	CustomLabels []expectations.LabelDefinition `json:"custom_labels,omitempty"`
+}
+
+// PassingDigests returns the digests of the given test that count as passing, sorted so that
+// comparisons against them are deterministic.
+func (b *Baseline) PassingDigests(test types.TestName) []types.Digest {
+	var rv []types.Digest
+	for digest, label := range b.Expectations[test] {
+		if expectations.IsPassing(label, b.CustomLabels) {
+			rv = append(rv, digest)
+		}
+	}
+	sort.Slice(rv, func(i, j int) bool { return rv[i] < rv[j] })
+	return rv
+}
+
+// WriteBaseline writes the given baseline and known hashes to the given directory, creating it
+// if necessary. Images need to be added separately via WriteImage.
+func WriteBaseline(dir string, b Baseline, knownHashes types.DigestSet) error {
+	if err := os.MkdirAll(filepath.Join(dir, imagesDirectory), os.ModePerm); err != nil {
+		return skerr.Wrapf(err, "creating baseline directory %s", dir)
+	}
+
+	jsonBytes, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return skerr.Wrapf(err, "encoding baseline")
+	}
+	baselinePath := filepath.Join(dir, baselineFile)
+	if err := os.WriteFile(baselinePath, jsonBytes, 0644); err != nil {
+		return skerr.Wrapf(err, "writing %s", baselinePath)
+	}
+
+	hashes := knownHashes.Keys()
+	sort.Sort(hashes)
+	var buf bytes.Buffer
+	for _, h := range hashes {
+		buf.WriteString(string(h))
+		buf.WriteString("\n")
+	}
+	hashesPath := filepath.Join(dir, knownHashesFile)
+	if err := os.WriteFile(hashesPath, buf.Bytes(), 0644); err != nil {
+		return skerr.Wrapf(err, "writing %s", hashesPath)
+	}
+	return nil
+}
+
+// WriteImage writes the given PNG file for the given digest to the baseline directory.
+func WriteImage(dir string, digest types.Digest, pngBytes []byte) error {
+	imagePath := imagePath(dir, digest)
+	if err := os.WriteFile(imagePath, pngBytes, 0644); err != nil {
+		return skerr.Wrapf(err, "writing %s", imagePath)
+	}
+	return nil
+}
+
+// LoadBaseline reads a baseline and its known hashes from a directory written by WriteBaseline.
+func LoadBaseline(dir string) (*Baseline, types.DigestSet, error) {
+	baselinePath := filepath.Join(dir, baselineFile)
+	jsonBytes, err := os.ReadFile(baselinePath)
+	if err != nil {
+		return nil, nil, skerr.Wrapf(err, "reading %s; was the baseline exported with goldctl local export?", baselinePath)
+	}
+	b := &Baseline{}
+	if err := json.Unmarshal(jsonBytes, b); err != nil {
+		return nil, nil, skerr.Wrapf(err, "parsing %s", baselinePath)
+	}
+
+	hashesPath := filepath.Join(dir, knownHashesFile)
+	hashesBytes, err := os.ReadFile(hashesPath)
+	if err != nil {
+		return nil, nil, skerr.Wrapf(err, "reading %s", hashesPath)
+	}
+	knownHashes := types.DigestSet{}
+	scanner := bufio.NewScanner(bytes.NewReader(hashesBytes))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			knownHashes[types.Digest(line)] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, skerr.Wrapf(err, "scanning %s", hashesPath)
+	}
+	return b, knownHashes, nil
+}
+
+// loadImage returns the decoded image and the raw PNG file of the given digest from the baseline
+// directory.
+func loadImage(dir string, digest types.Digest) (image.Image, []byte, error) {
+	imagePath := imagePath(dir, digest)
+	pngBytes, err := os.ReadFile(imagePath)
+	if err != nil {
+		return nil, nil, skerr.Wrapf(err, "reading image of digest %s; it might need to be exported with goldctl local export --digest", digest)
+	}
+	img, err := png.Decode(bytes.NewReader(pngBytes))
+	if err != nil {
+		return nil, nil, skerr.Wrapf(err, "decoding PNG file at %s", imagePath)
+	}
+	return img, pngBytes, nil
+}
+
+// imagePath returns the path of the PNG file of the given digest in the baseline directory.
+func imagePath(dir string, digest types.Digest) string {
+	return filepath.Join(dir, imagesDirectory, string(digest)+".png")
+}
@@ -0,0 +1,69 @@
+package localgold
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.skia.org/infra/golden/go/expectations"
+	"go.skia.org/infra/golden/go/types"
+)
+
+func TestWriteBaseline_LoadBaseline_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	b := Baseline{
+		GoldURL:          "https://testing-gold.skia.org",
+		ChangelistID:     "12345",
+		CodeReviewSystem: "gerrit",
+		ExportTime:       time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC),
+		Expectations: expectations.Baseline{
+			"some_test": {
+				"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa": expectations.Positive,
+				"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb": expectations.Negative,
+			},
+		},
+		CustomLabels: []expectations.LabelDefinition{{Label: "flaky", Code: "f", Passing: true}},
+	}
+	known := types.DigestSet{
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa": true,
+		"cccccccccccccccccccccccccccccccc": true,
+	}
+	require.NoError(t, WriteBaseline(dir, b, known))
+
+	actual, actualKnown, err := LoadBaseline(dir)
+	require.NoError(t, err)
+	assert.Equal(t, b, *actual)
+	assert.Equal(t, known, actualKnown)
+}
+
+func TestLoadBaseline_MissingDirectory_ReturnsError(t *testing.T) {
+	_, _, err := LoadBaseline(t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "goldctl local export")
+}
+
+func TestPassingDigests_CustomLabels_Sorted(t *testing.T) {
+	b := Baseline{
+		Expectations: expectations.Baseline{
+			"some_test": {
+				"dddddddddddddddddddddddddddddddd": expectations.Positive,
+				"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb": expectations.Negative,
+				"cccccccccccccccccccccccccccccccc": "flaky",
+				"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa": "bug_filed",
+				"eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee": expectations.Positive,
+			},
+		},
+		CustomLabels: []expectations.LabelDefinition{
+			{Label: "flaky", Code: "f", Passing: true},
+			{Label: "bug_filed", Code: "b"},
+		},
+	}
+	assert.Equal(t, []types.Digest{
+		"cccccccccccccccccccccccccccccccc",
+		"dddddddddddddddddddddddddddddddd",
+		"eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee",
+	}, b.PassingDigests("some_test"))
+	assert.Empty(t, b.PassingDigests("other_test"))
+}
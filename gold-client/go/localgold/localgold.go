@@ -0,0 +1,313 @@
+// Package localgold compares images against a baseline exported from a Gold instance, without
+// talking to the instance, and produces a static HTML report of the failures.
+package localgold
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+
+	"go.skia.org/infra/go/fileutil"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/gold-client/go/imgmatching"
+	"go.skia.org/infra/gold-client/go/imgmatching/mask_region"
+	"go.skia.org/infra/golden/go/diff"
+	"go.skia.org/infra/golden/go/expectations"
+	"go.skia.org/infra/golden/go/types"
+)
+
+const (
+	// stateFile is the name of the file in the work directory that holds the state between calls.
+	stateFile = "local-state.json"
+
+	// reportDirectory is the directory inside the work directory that holds the HTML report and
+	// the images it references.
+	reportDirectory = "report"
+
+	// reportImagesDirectory is the directory inside the report directory that holds the images.
+	reportImagesDirectory = "images"
+)
+
+// Result is the outcome of comparing an image against the local baseline.
+type Result struct {
+	TestName types.TestName `json:"test_name"`
+	Digest   types.Digest   `json:"digest"`
+	Passed   bool           `json:"passed"`
+
+	// Label is the label of Digest in the baseline, or empty if it has not been triaged.
+	Label expectations.Label `json:"label,omitempty"`
+
+	// Known is true if the Gold instance had seen Digest when the baseline was exported.
+	Known bool `json:"known"`
+
+	// Algorithm is the image matching algorithm that determined the outcome. Exact matching is
+	// reported if Digest was triaged in the baseline, regardless of the requested algorithm.
+	Algorithm imgmatching.AlgorithmName `json:"algorithm"`
+
+	// MatchedDigest is the passing digest that the image matched with a non-exact algorithm.
+	MatchedDigest types.Digest `json:"matched_digest,omitempty"`
+
+	// ClosestDigest is the passing digest closest to the image, for failed comparisons. It is
+	// empty if the test has no passing digests.
+	ClosestDigest types.Digest `json:"closest_digest,omitempty"`
+
+	// ImageFile, ClosestImageFile and DiffImageFile are the paths of the image, the closest passing
+	// image and the diff between them, relative to the report directory. Only set for failures.
+	ImageFile        string `json:"image_file,omitempty"`
+	ClosestImageFile string `json:"closest_image_file,omitempty"`
+	DiffImageFile    string `json:"diff_image_file,omitempty"`
+}
+
+// state is persisted in the work directory between calls.
+type state struct {
+	BaselineDir string   `json:"baseline_dir"`
+	Results     []Result `json:"results"`
+}
+
+// Client compares images against a local baseline and keeps track of the results in a work
+// directory, much like goldclient.CloudClient does for a Gold instance.
+type Client struct {
+	workDir     string
+	state       state
+	baseline    *Baseline
+	knownHashes types.DigestSet
+}
+
+// NewClient returns a Client that compares images against the baseline in baselineDir (see
+// WriteBaseline). Any results and report previously stored in workDir are discarded.
+func NewClient(baselineDir, workDir string) (*Client, error) {
+	if baselineDir == "" || workDir == "" {
+		return nil, skerr.Fmt("both a baseline directory and a work directory are required")
+	}
+	workDir, err := fileutil.EnsureDirExists(workDir)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "setting up workdir %q", workDir)
+	}
+	baselineDir, err = filepath.Abs(baselineDir)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	c := &Client{
+		workDir: workDir,
+		state:   state{BaselineDir: baselineDir},
+	}
+	if err := c.loadBaseline(); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	if err := os.RemoveAll(c.reportDir()); err != nil {
+		return nil, skerr.Wrapf(err, "removing previous report")
+	}
+	if err := c.saveState(); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return c, nil
+}
+
+// LoadClient returns a Client that has previously been stored to disk in workDir by NewClient.
+func LoadClient(workDir string) (*Client, error) {
+	if workDir == "" {
+		return nil, skerr.Fmt("no work directory provided")
+	}
+	c := &Client{workDir: workDir}
+	statePath := c.statePath()
+	jsonBytes, err := os.ReadFile(statePath)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "reading %s; was goldctl local init run?", statePath)
+	}
+	if err := json.Unmarshal(jsonBytes, &c.state); err != nil {
+		return nil, skerr.Wrapf(err, "parsing %s", statePath)
+	}
+	if err := c.loadBaseline(); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return c, nil
+}
+
+// Baseline returns the baseline that images are compared against.
+func (c *Client) Baseline() *Baseline {
+	return c.baseline
+}
+
+// Results returns the results of all the images added so far.
+func (c *Client) Results() []Result {
+	return c.state.Results
+}
+
+// Add compares the given image against the baseline of the given test and records the result.
+//
+// If the digest of the image has been triaged, it passes or fails according to its label.
+// Otherwise, the non-exact image matching algorithm specified via optionalKeys (if any) compares
+// the image against every passing digest of the test, and the image passes if any of them match.
+// Unlike goldctl imgtest, which only compares against the most recent positive digest of the
+// trace, all passing digests are used because traces are not part of the exported baseline.
+//
+// For failed comparisons, the image, the closest passing image and the diff between them are
+// written to the report directory.
+func (c *Client) Add(name types.TestName, imgFileName string, optionalKeys map[string]string) (Result, error) {
+	imgBytes, err := os.ReadFile(imgFileName)
+	if err != nil {
+		return Result{}, skerr.Wrapf(err, "reading %s", imgFileName)
+	}
+	img, err := png.Decode(bytes.NewReader(imgBytes))
+	if err != nil {
+		return Result{}, skerr.Wrapf(err, "decoding PNG in file %s", imgFileName)
+	}
+	digest := hashImage(img)
+
+	result := Result{
+		TestName: name,
+		Digest:   digest,
+		Label:    c.baseline.Expectations[name][digest],
+		Known:    c.knownHashes[digest],
+	}
+	result.Passed, result.Algorithm, result.MatchedDigest, err = c.matchImageAgainstBaseline(name, img, digest, optionalKeys)
+	if err != nil {
+		return Result{}, skerr.Wrap(err)
+	}
+
+	if !result.Passed {
+		if err := c.writeFailureImages(&result, img, imgBytes); err != nil {
+			return Result{}, skerr.Wrap(err)
+		}
+	}
+
+	c.state.Results = append(c.state.Results, result)
+	if err := c.saveState(); err != nil {
+		return Result{}, skerr.Wrap(err)
+	}
+	return result, nil
+}
+
+// matchImageAgainstBaseline returns whether the given image matches the baseline, the algorithm
+// that was used to determine it and the passing digest it matched, if a non-exact algorithm was
+// used.
+func (c *Client) matchImageAgainstBaseline(name types.TestName, img image.Image, digest types.Digest, optionalKeys map[string]string) (bool, imgmatching.AlgorithmName, types.Digest, error) {
+	label := c.baseline.Expectations[name][digest]
+	if expectations.IsPassing(label, c.baseline.CustomLabels) {
+		return true, imgmatching.ExactMatching, "", nil
+	}
+	if expectations.IsFailing(label, c.baseline.CustomLabels) {
+		return false, imgmatching.ExactMatching, "", nil
+	}
+
+	algorithmName, matcher, err := imgmatching.MakeMatcher(optionalKeys)
+	if err != nil {
+		return false, "", "", skerr.Wrapf(err, "parsing image matching algorithm from optional keys")
+	}
+	if algorithmName == imgmatching.ExactMatching {
+		return false, algorithmName, "", nil
+	}
+
+	if maskMatcher, ok := matcher.(*mask_region.Matcher); ok && maskMatcher.MaskDigest != "" {
+		maskMatcher.Mask, _, err = loadImage(c.state.BaselineDir, maskMatcher.MaskDigest)
+		if err != nil {
+			return false, "", "", skerr.Wrapf(err, "loading mask image")
+		}
+	}
+
+	passing := c.baseline.PassingDigests(name)
+	if len(passing) == 0 {
+		// Matchers treat a nil expected image as "no recent positive digest".
+		return matcher.Match(nil, img), algorithmName, "", nil
+	}
+	for _, d := range passing {
+		expected, _, err := loadImage(c.state.BaselineDir, d)
+		if err != nil {
+			return false, "", "", skerr.Wrap(err)
+		}
+		if matcher.Match(expected, img) {
+			return true, algorithmName, d, nil
+		}
+	}
+	return false, algorithmName, "", nil
+}
+
+// writeFailureImages writes the image, the closest passing image and the diff between them to
+// the report directory, and records their paths in the given result.
+func (c *Client) writeFailureImages(result *Result, img image.Image, imgBytes []byte) error {
+	imagesDir := filepath.Join(c.reportDir(), reportImagesDirectory)
+	if err := os.MkdirAll(imagesDir, os.ModePerm); err != nil {
+		return skerr.Wrapf(err, "creating report directory %s", imagesDir)
+	}
+
+	result.ImageFile = filepath.Join(reportImagesDirectory, string(result.Digest)+".png")
+	if err := os.WriteFile(filepath.Join(c.reportDir(), result.ImageFile), imgBytes, 0644); err != nil {
+		return skerr.Wrapf(err, "writing image %s", result.ImageFile)
+	}
+
+	// Find the passing digest with the smallest combined diff metric, as goldctl diff does.
+	smallestCombined := float32(math.MaxFloat32)
+	var closestBytes []byte
+	var closestDiffImg image.Image
+	for _, d := range c.baseline.PassingDigests(result.TestName) {
+		expected, expectedBytes, err := loadImage(c.state.BaselineDir, d)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		dm, diffImg := diff.PixelDiff(expected, img)
+		if combined := diff.CombinedDiffMetric(dm.MaxRGBADiffs, dm.PixelDiffPercent); combined < smallestCombined {
+			smallestCombined = combined
+			result.ClosestDigest = d
+			closestBytes = expectedBytes
+			closestDiffImg = diffImg
+		}
+	}
+	if result.ClosestDigest == "" {
+		return nil
+	}
+
+	result.ClosestImageFile = filepath.Join(reportImagesDirectory, string(result.ClosestDigest)+".png")
+	if err := os.WriteFile(filepath.Join(c.reportDir(), result.ClosestImageFile), closestBytes, 0644); err != nil {
+		return skerr.Wrapf(err, "writing image %s", result.ClosestImageFile)
+	}
+
+	result.DiffImageFile = filepath.Join(reportImagesDirectory, fmt.Sprintf("diff-%s-%s.png", result.Digest, result.ClosestDigest))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, closestDiffImg); err != nil {
+		return skerr.Wrapf(err, "encoding diff image")
+	}
+	if err := os.WriteFile(filepath.Join(c.reportDir(), result.DiffImageFile), buf.Bytes(), 0644); err != nil {
+		return skerr.Wrapf(err, "writing image %s", result.DiffImageFile)
+	}
+	return nil
+}
+
+// loadBaseline loads the baseline referenced by the state.
+func (c *Client) loadBaseline() error {
+	var err error
+	c.baseline, c.knownHashes, err = LoadBaseline(c.state.BaselineDir)
+	return skerr.Wrap(err)
+}
+
+// saveState writes the state to the work directory.
+func (c *Client) saveState() error {
+	jsonBytes, err := json.Marshal(c.state)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if err := os.WriteFile(c.statePath(), jsonBytes, 0644); err != nil {
+		return skerr.Wrapf(err, "writing %s", c.statePath())
+	}
+	return nil
+}
+
+func (c *Client) statePath() string {
+	return filepath.Join(c.workDir, stateFile)
+}
+
+func (c *Client) reportDir() string {
+	return filepath.Join(c.workDir, reportDirectory)
+}
+
+// hashImage returns the MD5 hash of the pixels of the given image, as goldctl computes digests.
+func hashImage(img image.Image) types.Digest {
+	s := md5.Sum(diff.GetNRGBA(img).Pix)
+	return types.Digest(hex.EncodeToString(s[:]))
+}
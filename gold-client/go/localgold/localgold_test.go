@@ -0,0 +1,160 @@
+package localgold
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.skia.org/infra/gold-client/go/imgmatching"
+	"go.skia.org/infra/golden/go/expectations"
+	"go.skia.org/infra/golden/go/types"
+)
+
+const testName = types.TestName("my_test")
+
+// makeImage returns a 4x4 white image with the given number of pixels in its first row set to
+// black.
+func makeImage(numBlackPixels int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	for x := 0; x < numBlackPixels; x++ {
+		img.Set(x, 0, color.Black)
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// writePNGFile writes the given image to a PNG file in a temporary directory and returns its path.
+func writePNGFile(t *testing.T, img image.Image) string {
+	p := filepath.Join(t.TempDir(), "image.png")
+	require.NoError(t, os.WriteFile(p, encodePNG(t, img), 0644))
+	return p
+}
+
+// setupBaseline writes a baseline with a positive image (no black pixels) and a negative image
+// (four black pixels) for testName, and returns the baseline directory.
+func setupBaseline(t *testing.T) string {
+	dir := t.TempDir()
+	positive, negative := makeImage(0), makeImage(4)
+	b := Baseline{
+		GoldURL:    "https://testing-gold.skia.org",
+		ExportTime: time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC),
+		Expectations: expectations.Baseline{
+			testName: {
+				hashImage(positive): expectations.Positive,
+				hashImage(negative): expectations.Negative,
+			},
+		},
+	}
+	known := types.DigestSet{hashImage(positive): true, hashImage(negative): true}
+	require.NoError(t, WriteBaseline(dir, b, known))
+	require.NoError(t, WriteImage(dir, hashImage(positive), encodePNG(t, positive)))
+	return dir
+}
+
+func TestAdd_PositiveDigest_Passes(t *testing.T) {
+	c, err := NewClient(setupBaseline(t), t.TempDir())
+	require.NoError(t, err)
+
+	result, err := c.Add(testName, writePNGFile(t, makeImage(0)), nil)
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.True(t, result.Known)
+	assert.Equal(t, expectations.Positive, result.Label)
+	assert.Equal(t, imgmatching.ExactMatching, result.Algorithm)
+	assert.Empty(t, result.ImageFile)
+}
+
+func TestAdd_NegativeDigest_FailsAndWritesImages(t *testing.T) {
+	workDir := t.TempDir()
+	c, err := NewClient(setupBaseline(t), workDir)
+	require.NoError(t, err)
+
+	result, err := c.Add(testName, writePNGFile(t, makeImage(4)), nil)
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Equal(t, expectations.Negative, result.Label)
+	assert.Equal(t, hashImage(makeImage(0)), result.ClosestDigest)
+	for _, f := range []string{result.ImageFile, result.ClosestImageFile, result.DiffImageFile} {
+		assert.FileExists(t, filepath.Join(workDir, reportDirectory, f))
+	}
+}
+
+func TestAdd_UntriagedDigest_ExactMatching_Fails(t *testing.T) {
+	c, err := NewClient(setupBaseline(t), t.TempDir())
+	require.NoError(t, err)
+
+	result, err := c.Add(testName, writePNGFile(t, makeImage(1)), nil)
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.False(t, result.Known)
+	assert.Equal(t, expectations.Label(""), result.Label)
+	assert.Equal(t, imgmatching.ExactMatching, result.Algorithm)
+}
+
+func TestAdd_UntriagedDigest_FuzzyMatching_MatchesPositiveDigest(t *testing.T) {
+	c, err := NewClient(setupBaseline(t), t.TempDir())
+	require.NoError(t, err)
+
+	optionalKeys := map[string]string{
+		string(imgmatching.AlgorithmNameOptKey):    string(imgmatching.FuzzyMatching),
+		string(imgmatching.MaxDifferentPixels):     "2",
+		string(imgmatching.PixelDeltaThreshold):    "1020",
+		string(imgmatching.IgnoredBorderThickness): "0",
+	}
+	result, err := c.Add(testName, writePNGFile(t, makeImage(2)), optionalKeys)
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Equal(t, imgmatching.FuzzyMatching, result.Algorithm)
+	assert.Equal(t, hashImage(makeImage(0)), result.MatchedDigest)
+
+	result, err = c.Add(testName, writePNGFile(t, makeImage(3)), optionalKeys)
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Equal(t, hashImage(makeImage(0)), result.ClosestDigest)
+}
+
+func TestLoadClient_ResultsPersisted_WriteReport(t *testing.T) {
+	workDir := t.TempDir()
+	c, err := NewClient(setupBaseline(t), workDir)
+	require.NoError(t, err)
+	_, err = c.Add(testName, writePNGFile(t, makeImage(0)), nil)
+	require.NoError(t, err)
+
+	c, err = LoadClient(workDir)
+	require.NoError(t, err)
+	_, err = c.Add(testName, writePNGFile(t, makeImage(4)), nil)
+	require.NoError(t, err)
+	assert.Len(t, c.Results(), 2)
+
+	reportPath, numFailures, err := c.WriteReport()
+	require.NoError(t, err)
+	assert.Equal(t, 1, numFailures)
+	report, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(report), "1 passed, 1 failed.")
+	assert.Contains(t, string(report), string(hashImage(makeImage(4))))
+}
+
+func TestLoadClient_NotInitialized_ReturnsError(t *testing.T) {
+	_, err := LoadClient(t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "goldctl local init")
+}
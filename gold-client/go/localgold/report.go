@@ -0,0 +1,100 @@
+package localgold
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	"go.skia.org/infra/go/skerr"
+)
+
+// reportFile is the name of the HTML report inside the report directory.
+const reportFile = "index.html"
+
+// reportTemplate renders the results of a local run as a self-contained HTML page. Images are
+// referenced relative to the report directory, so it can be copied around or archived by CI.
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Gold local report</title>
+  <style>
+    body { font-family: sans-serif; }
+    table { border-collapse: collapse; }
+    td, th { border: 1px solid #ccc; padding: 4px; vertical-align: top; }
+    img { max-width: 256px; image-rendering: pixelated; }
+  </style>
+</head>
+<body>
+  <h1>Gold local report</h1>
+  <p>
+    Compared against the baseline of <a href="{{.Baseline.GoldURL}}">{{.Baseline.GoldURL}}</a>
+    {{- if .Baseline.ChangelistID}} for changelist {{.Baseline.CodeReviewSystem}}/{{.Baseline.ChangelistID}}{{end}}
+    exported at {{.Baseline.ExportTime}}.
+  </p>
+  <p>{{.NumPassed}} passed, {{len .Failures}} failed.</p>
+  {{- if .Failures}}
+  <table>
+    <tr>
+      <th>Test</th>
+      <th>Image</th>
+      <th>Closest passing image</th>
+      <th>Diff</th>
+    </tr>
+    {{- range .Failures}}
+    <tr>
+      <td>
+        <b>{{.TestName}}</b><br>
+        digest: {{.Digest}}<br>
+        label: {{if .Label}}{{.Label}}{{else}}untriaged{{end}}<br>
+        algorithm: {{.Algorithm}}<br>
+        {{if not .Known}}new to Gold{{end}}
+      </td>
+      <td><a href="{{.ImageFile}}"><img src="{{.ImageFile}}"></a></td>
+      {{- if .ClosestDigest}}
+      <td><a href="{{.ClosestImageFile}}"><img src="{{.ClosestImageFile}}"></a><br>{{.ClosestDigest}}</td>
+      <td><a href="{{.DiffImageFile}}"><img src="{{.DiffImageFile}}"></a></td>
+      {{- else}}
+      <td colspan="2">No passing images for this test.</td>
+      {{- end}}
+    </tr>
+    {{- end}}
+  </table>
+  {{- end}}
+</body>
+</html>
+`))
+
+// reportData is the input to reportTemplate.
+type reportData struct {
+	Baseline  *Baseline
+	NumPassed int
+	Failures  []Result
+}
+
+// WriteReport writes an HTML report of the failed comparisons to the report directory inside the
+// work directory. It returns the path of the report and the number of failures.
+func (c *Client) WriteReport() (string, int, error) {
+	data := reportData{Baseline: c.baseline}
+	for _, r := range c.state.Results {
+		if r.Passed {
+			data.NumPassed++
+		} else {
+			data.Failures = append(data.Failures, r)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, data); err != nil {
+		return "", 0, skerr.Wrapf(err, "rendering report")
+	}
+	if err := os.MkdirAll(c.reportDir(), os.ModePerm); err != nil {
+		return "", 0, skerr.Wrapf(err, "creating report directory %s", c.reportDir())
+	}
+	reportPath := filepath.Join(c.reportDir(), reportFile)
+	if err := os.WriteFile(reportPath, buf.Bytes(), 0644); err != nil {
+		return "", 0, skerr.Wrapf(err, "writing %s", reportPath)
+	}
+	return reportPath, len(data.Failures), nil
+}
@@ -24,6 +24,7 @@ import (
 	"go.skia.org/infra/go/sklog"
 	"go.skia.org/infra/golden/go/clstore"
 	"go.skia.org/infra/golden/go/config"
+	"go.skia.org/infra/golden/go/expectations"
 	"go.skia.org/infra/golden/go/sql"
 	"go.skia.org/infra/golden/go/storage"
 	"go.skia.org/infra/golden/go/tracing"
@@ -61,6 +62,10 @@ func main() {
 		sklog.Fatalf("Reading config: %s", err)
 	}
 	sklog.Infof("Loaded config %#v", bsc)
+	labels, err := expectations.NewLabels(bsc.CustomLabels)
+	if err != nil {
+		sklog.Fatalf("Invalid custom labels: %s", err)
+	}
 
 	if err := tracing.Initialize(0.1, bsc.SQLDatabaseName); err != nil {
 		sklog.Fatalf("Could not initialize tracing: %s", err)
@@ -105,6 +110,7 @@ func main() {
 		GCSClient:                 gsClient,
 		ReviewSystems:             reviewSystems,
 		GroupingParamKeysByCorpus: bsc.GroupingParamKeysByCorpus,
+		Labels:                    labels,
 	}, web.BaselineSubset, proxylogin.NewWithDefaults())
 	if err != nil {
 		sklog.Fatalf("Failed to initialize web handlers: %s", err)
@@ -50,6 +50,10 @@ const (
 	diffCalculationTimeout = 10 * time.Minute
 
 	groupingCacheSize = 100_000
+
+	// The number of pairs of digests to backfill perceptual metrics for at a time, when there
+	// are no new diffs to calculate.
+	backfillBatchSize = 100
 )
 
 type diffCalculatorConfig struct {
@@ -104,8 +108,10 @@ func main() {
 		sklog.Fatalf("Could not initialize cache: %s", err)
 	}
 
+	w := worker.New(db, gis, dcc.WindowSize)
 	sqlProcessor := &processor{
-		calculator:         worker.New(db, gis, dcc.WindowSize),
+		calculator:         w,
+		backfiller:         w,
 		db:                 db,
 		groupingCache:      gc,
 		primaryCounter:     metrics2.GetCounter("diffcalculator_primarybranch_processed"),
@@ -139,11 +145,18 @@ func beginPolling(ctx context.Context, sqlProcessor *processor) error {
 		}
 		n := now.Now(ctx)
 		if secondaryShouldSleepUntil.After(n) && primaryShouldSleepUntil.After(n) {
-			// Neither has data, so sleep. This prevents us from slamming the SQL DB during periods
-			// we are not busy
-			sklog.Infof("No diffs to calculate, sleeping")
-			sqlProcessor.setBusy(false)
-			time.Sleep(sleepDuration)
+			// Neither has data, so use the time to backfill the metrics of older diffs. If there
+			// is nothing to backfill either, sleep. This prevents us from slamming the SQL DB
+			// during periods we are not busy
+			n, err := sqlProcessor.backfillPerceptualMetrics(ctx)
+			if err != nil {
+				sklog.Errorf("Error backfilling perceptual metrics: %s", err)
+			}
+			if n == 0 {
+				sklog.Infof("No diffs to calculate, sleeping")
+				sqlProcessor.setBusy(false)
+				time.Sleep(sleepDuration)
+			}
 		} else if secondaryShouldSleepUntil.Before(n) && primaryShouldSleepUntil.Before(n) {
 			// Both primary and secondary have data, so randomly choose one. We randomly choose
 			// to avoid starving one of our "queues" if both are full.
@@ -240,9 +253,16 @@ func (g *gcsImageDownloader) GetImage(ctx context.Context, digest types.Digest)
 	return b, skerr.Wrap(err)
 }
 
+// perceptualMetricsBackfiller computes the perceptual metrics (e.g. SSIM) of diffs that were
+// calculated before those metrics existed.
+type perceptualMetricsBackfiller interface {
+	BackfillPerceptualMetrics(ctx context.Context, batchSize int) (int, error)
+}
+
 type processor struct {
 	db             *pgxpool.Pool
 	calculator     diff.Calculator
+	backfiller     perceptualMetricsBackfiller
 	groupingCache  *lru.Cache
 	primaryCounter metrics2.Counter
 	clsCounter     metrics2.Counter
@@ -321,6 +341,18 @@ SET last_calculated_ts = $2 WHERE grouping_id = $1`
 	return false, nil
 }
 
+// backfillPerceptualMetrics backfills the perceptual metrics of a batch of older diffs, if there
+// is a backfiller. It returns the number of diffs that were backfilled.
+func (p *processor) backfillPerceptualMetrics(ctx context.Context) (int, error) {
+	if p.backfiller == nil {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, diffCalculationTimeout)
+	defer cancel()
+	n, err := p.backfiller.BackfillPerceptualMetrics(ctx, backfillBatchSize)
+	return n, skerr.Wrap(err)
+}
+
 // expandGrouping returns the params associated with the grouping id. It will use the cache - if
 // there is a cache miss, it will look it up, add it to the cache and return it.
 func (p *processor) expandGrouping(ctx context.Context, groupingID schema.GroupingID) (paramtools.Params, error) {
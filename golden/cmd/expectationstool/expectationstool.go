@@ -0,0 +1,172 @@
+// The expectationstool executable exports the expectations of a Gold instance, along with their
+// triage history, to a portable JSON file and imports such a file into the same or another
+// instance. On import, the keys of the groupings can be rewritten, e.g. to follow a test rename or
+// a move to a new corpus.
+//
+// Examples:
+//
+//	expectationstool -sql_db=skia -filter="source_type=gm" -file=gm.json export
+//	expectationstool -sql_db=skia_v2 -file=gm.json -rewrites_file=rewrites.json import
+//
+// where rewrites.json contains something like
+//
+//	[{"match": {"source_type": "gm"}, "set": {"source_type": "gm_v2"}}]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flynn/json5"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/go/sklog/sklogimpl"
+	"go.skia.org/infra/go/sklog/stdlogging"
+	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/golden/go/expectations"
+	"go.skia.org/infra/golden/go/expectations/migration"
+	"go.skia.org/infra/golden/go/sql"
+)
+
+func main() {
+	var (
+		sqlDB                = flag.String("sql_db", "", "Something like the instance id (no dashes)")
+		sqlConnection        = flag.String("sql_connection", "root@localhost:26234", "Where to connect to the SQL database.")
+		commonInstanceConfig = flag.String("common_instance_config", "", "Path to the json5 file containing the configuration of the instance. Required if the instance has custom labels.")
+		file                 = flag.String("file", "", "The JSON file to export to or import from.")
+		filter               = flag.String("filter", "", "Only export the groupings with these keys, as a URL query, e.g. source_type=gm&name=my_test. Exports everything if empty.")
+		rewritesFile         = flag.String("rewrites_file", "", "A JSON file with a list of key rewrites to apply to the groupings on import.")
+		overwrite            = flag.Bool("overwrite", false, "If true, imported labels replace the labels of digests that are already triaged in the target instance.")
+	)
+	sklogimpl.SetLogger(stdlogging.New(os.Stderr))
+	flag.Parse()
+	task := strings.ToLower(flag.Arg(0))
+	if *sqlDB == "" || *file == "" {
+		exitWithError("Both --sql_db and --file are required.")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var labels *expectations.Labels
+	if *commonInstanceConfig != "" {
+		var err error
+		if labels, err = loadLabels(*commonInstanceConfig); err != nil {
+			exitWithError("Loading custom labels from %s: %s", *commonInstanceConfig, err)
+		}
+	}
+	u := sql.GetConnectionURL(*sqlConnection, *sqlDB)
+	conf, err := pgxpool.ParseConfig(u)
+	if err != nil {
+		exitWithError("Getting postgres config %s: %s", u, err)
+	}
+	conf.MaxConns = 4
+	db, err := pgxpool.ConnectConfig(ctx, conf)
+	if err != nil {
+		sklog.Info("You might need to run\nkubectl port-forward gold-cockroachdb-0 26234:26234")
+		exitWithError("Connecting to the database: %s", err)
+	}
+	defer db.Close()
+
+	if task == "export" {
+		if err := exportExpectations(ctx, db, labels, *filter, *file); err != nil {
+			exitWithError("Exporting expectations to %s: %s", *file, err)
+		}
+	} else if task == "import" {
+		if err := importExpectations(ctx, db, labels, *file, *rewritesFile, *overwrite); err != nil {
+			exitWithError("Importing expectations from %s: %s", *file, err)
+		}
+	} else {
+		exitWithError(`Invalid command: %q. Try "export" or "import".`, task)
+	}
+}
+
+func exitWithError(msg string, args ...interface{}) {
+	msg = strings.TrimSuffix(msg, "\n") + "\n"
+	fmt.Printf(msg, args...)
+	os.Exit(1)
+}
+
+// loadLabels returns the labels of the instance, including the custom labels defined in the given
+// instance config, so that their codes can be translated to and from their names.
+func loadLabels(configFile string) (*expectations.Labels, error) {
+	var cfg struct {
+		CustomLabels []expectations.LabelDefinition `json:"custom_labels"`
+	}
+	err := util.WithReadFile(configFile, func(r io.Reader) error {
+		return json5.NewDecoder(r).Decode(&cfg)
+	})
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	labels, err := expectations.NewLabels(cfg.CustomLabels)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return labels, nil
+}
+
+func exportExpectations(ctx context.Context, db *pgxpool.Pool, labels *expectations.Labels, filter, file string) error {
+	values, err := url.ParseQuery(filter)
+	if err != nil {
+		return skerr.Wrapf(err, "parsing filter %q", filter)
+	}
+	params := paramtools.Params{}
+	for key, vals := range values {
+		if len(vals) != 1 {
+			return skerr.Fmt("filter must have exactly one value for key %q", key)
+		}
+		params[key] = vals[0]
+	}
+
+	e, err := migration.ExportExpectations(ctx, db, labels, params, time.Now())
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	err = util.WithWriteFile(file, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	})
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	sklog.Infof("Exported %d triage records and %d expectations to %s", len(e.Records), len(e.Expectations), file)
+	return nil
+}
+
+func importExpectations(ctx context.Context, db *pgxpool.Pool, labels *expectations.Labels, file, rewritesFile string, overwrite bool) error {
+	var e migration.Export
+	err := util.WithReadFile(file, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&e)
+	})
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	opts := migration.ImportOptions{Overwrite: overwrite}
+	if rewritesFile != "" {
+		err := util.WithReadFile(rewritesFile, func(r io.Reader) error {
+			return json.NewDecoder(r).Decode(&opts.Rewrites)
+		})
+		if err != nil {
+			return skerr.Wrapf(err, "reading key rewrites from %s", rewritesFile)
+		}
+	}
+
+	stats, err := migration.ImportExpectations(ctx, db, labels, e, opts)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	sklog.Infof("Imported %d triage records with %d deltas and %d expectations; skipped %d already triaged expectations and %d of their deltas",
+		stats.Records, stats.Deltas, stats.Expectations, stats.SkippedExpectations, stats.SkippedDeltas)
+	return nil
+}
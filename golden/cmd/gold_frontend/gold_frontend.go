@@ -38,6 +38,7 @@ import (
 	"go.skia.org/infra/golden/go/code_review/gerrit_crs"
 	"go.skia.org/infra/golden/go/code_review/github_crs"
 	"go.skia.org/infra/golden/go/config"
+	"go.skia.org/infra/golden/go/expectations"
 	"go.skia.org/infra/golden/go/ignore"
 	"go.skia.org/infra/golden/go/ignore/sqlignorestore"
 	"go.skia.org/infra/golden/go/publicparams"
@@ -112,6 +113,8 @@ func main() {
 	// Load configuration from common and instance-specific JSON files.
 	fsc := mustLoadFrontendServerConfig(commonInstanceConfig, thisConfig)
 
+	labels := mustMakeLabels(fsc)
+
 	// Speculative memory usage fix? https://github.com/googleapis/google-cloud-go/issues/375
 	grpc.EnableTracing = false
 
@@ -149,11 +152,11 @@ func main() {
 
 	reviewSystems := mustInitializeReviewSystems(fsc, client)
 
-	s2a := mustLoadSearchAPI(ctx, fsc, sqlDB, publiclyViewableParams, reviewSystems)
+	s2a := mustLoadSearchAPI(ctx, fsc, sqlDB, publiclyViewableParams, reviewSystems, labels)
 
 	plogin := proxylogin.NewWithDefaults()
 
-	handlers := mustMakeWebHandlers(ctx, fsc, sqlDB, gsClient, ignoreStore, reviewSystems, s2a, plogin)
+	handlers := mustMakeWebHandlers(ctx, fsc, sqlDB, gsClient, ignoreStore, reviewSystems, s2a, labels, plogin)
 
 	rootRouter := mustMakeRootRouter(fsc, handlers, plogin)
 
@@ -162,13 +165,13 @@ func main() {
 	sklog.Fatal(http.ListenAndServe(fsc.ReadyPort, rootRouter))
 }
 
-func mustLoadSearchAPI(ctx context.Context, fsc *frontendServerConfig, sqlDB *pgxpool.Pool, publiclyViewableParams publicparams.Matcher, systems []clstore.ReviewSystem) *search.Impl {
+func mustLoadSearchAPI(ctx context.Context, fsc *frontendServerConfig, sqlDB *pgxpool.Pool, publiclyViewableParams publicparams.Matcher, systems []clstore.ReviewSystem, labels *expectations.Labels) *search.Impl {
 	templates := map[string]string{}
 	for _, crs := range systems {
 		templates[crs.ID] = crs.URLTemplate
 	}
 
-	s2a := search.New(sqlDB, fsc.WindowSize)
+	s2a := search.New(sqlDB, fsc.WindowSize, labels)
 	s2a.SetReviewSystemTemplates(templates)
 	sklog.Infof("SQL Search loaded with CRS templates %s", templates)
 	err := s2a.StartCacheProcess(ctx, 5*time.Minute, fsc.WindowSize)
@@ -198,6 +201,16 @@ func mustLoadFrontendServerConfig(commonInstanceConfig *string, thisConfig *stri
 	return &fsc
 }
 
+// mustMakeLabels returns the labels of the instance, i.e. the built-in labels and the custom
+// labels configured for it.
+func mustMakeLabels(fsc *frontendServerConfig) *expectations.Labels {
+	labels, err := expectations.NewLabels(fsc.CustomLabels)
+	if err != nil {
+		sklog.Fatalf("Invalid custom labels: %s", err)
+	}
+	return labels
+}
+
 // mustStartDebugServer starts an internal HTTP server for debugging purposes if requested.
 func mustStartDebugServer(fsc *frontendServerConfig) {
 	if fsc.DebugPort != "" {
@@ -347,7 +360,7 @@ func mustInitializeReviewSystems(fsc *frontendServerConfig, hc *http.Client) []c
 }
 
 // mustMakeWebHandlers returns a new web.Handlers.
-func mustMakeWebHandlers(ctx context.Context, fsc *frontendServerConfig, db *pgxpool.Pool, gsClient storage.GCSClient, ignoreStore ignore.Store, reviewSystems []clstore.ReviewSystem, s2a search.API, alogin alogin.Login) *web.Handlers {
+func mustMakeWebHandlers(ctx context.Context, fsc *frontendServerConfig, db *pgxpool.Pool, gsClient storage.GCSClient, ignoreStore ignore.Store, reviewSystems []clstore.ReviewSystem, s2a search.API, labels *expectations.Labels, alogin alogin.Login) *web.Handlers {
 	handlers, err := web.NewHandlers(web.HandlersConfig{
 		DB:                        db,
 		GCSClient:                 gsClient,
@@ -356,6 +369,7 @@ func mustMakeWebHandlers(ctx context.Context, fsc *frontendServerConfig, db *pgx
 		Search2API:                s2a,
 		WindowSize:                fsc.WindowSize,
 		GroupingParamKeysByCorpus: fsc.GroupingParamKeysByCorpus,
+		Labels:                    labels,
 	}, web.FullFrontEnd, alogin)
 	if err != nil {
 		sklog.Fatalf("Failed to initialize web handlers: %s", err)
@@ -547,6 +561,7 @@ func addUnauthenticatedJSONRoutes(router chi.Router, _ *frontendServerConfig, ha
 	// These routes can be served with baseline_server for higher availability.
 	add(frontend.ExpectationsRouteV2, handlers.BaselineHandlerV2)
 	add(frontend.GroupingsRouteV1, handlers.GroupingsHandler)
+	add(frontend.LabelsRouteV1, handlers.LabelsHandler)
 }
 
 var (
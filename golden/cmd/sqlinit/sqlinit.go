@@ -1,5 +1,6 @@
 // The sqlinit executable creates a database on the production SQL cluster with the appropriate
-// schema. It will not modify any tables (e.g. add missing indexes or change columns).
+// schema. It will not modify existing tables (e.g. add missing indexes or change columns), except
+// as listed in schema.Migrations.
 // This executable will schedule new automatic backups, so if there are existing ones, one may have
 // to drop the old schedules.
 // https://www.cockroachlabs.com/docs/v20.2/show-schedules
@@ -71,6 +72,19 @@ func main() {
 		sklog.Fatalf("Error while creating tables: %s %s", err, out)
 	}
 
+	sklog.Infof("Migrating existing tables")
+	out, err = exec.Command("kubectl", "run",
+		"gold-cockroachdb-init-"+normalizedDB,
+		"--restart=Never", cockroachDBVersion,
+		"--rm", "-it", // -it forces this command to wait until it completes.
+		"--", "sql",
+		"--insecure", "--host="+*dbCluster, "--database="+normalizedDB,
+		"--execute="+schema.Migrations,
+	).CombinedOutput()
+	if err != nil {
+		sklog.Fatalf("Error while migrating tables: %s %s", err, out)
+	}
+
 	sklog.Infof("Deleting existing schedules, if any")
 	out, err = exec.Command("kubectl", "run",
 		"gold-cockroachdb-init-"+normalizedDB,
@@ -8,6 +8,7 @@ import (
 	"go.skia.org/infra/go/config"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/golden/go/expectations"
 )
 
 // The Common struct is a set of configuration values that are the same across all instances.
@@ -68,6 +69,11 @@ type Common struct {
 	// GroupingParamKeysByCorpus is a map from corpus name to the list of keys that comprise the
 	// corpus' grouping.
 	GroupingParamKeysByCorpus map[string][]string `json:"grouping_param_keys_by_corpus"`
+
+	// CustomLabels are the labels this instance supports in addition to untriaged, positive and
+	// negative, e.g. to distinguish flaky but acceptable digests from known bad ones with a bug
+	// filed. Each label counts as either passing or failing, e.g. for goldctl.
+	CustomLabels []expectations.LabelDefinition `json:"custom_labels" optional:"true"`
 }
 
 // CodeReviewSystem represents the details needed to interact with a CodeReviewSystem (e.g.
@@ -76,6 +76,14 @@ type DiffMetrics struct {
 
 	// DimDiffer is true if the dimensions between the two images are different.
 	DimDiffer bool
+
+	// SSIM is the structural similarity of the two images, in [-1, 1] where 1
+	// means identical. See SSIM().
+	SSIM float32
+
+	// DeltaE is the mean CIEDE2000 color difference of the two images, in
+	// [0, MaxDeltaE] where 0 means identical. See DeltaE().
+	DeltaE float32
 }
 
 // ComputeDiffMetrics computes and returns the diff metrics between two given images.
@@ -83,6 +91,12 @@ func ComputeDiffMetrics(leftImg *image.NRGBA, rightImg *image.NRGBA) *DiffMetric
 	defer metrics2.FuncTimer().Stop()
 	ret, _ := PixelDiff(leftImg, rightImg)
 	ret.CombinedMetric = CombinedDiffMetric(ret.MaxRGBADiffs, ret.PixelDiffPercent)
+	if ret.NumDiffPixels == 0 {
+		ret.SSIM = 1
+		return ret
+	}
+	ret.SSIM = SSIM(leftImg, rightImg)
+	ret.DeltaE = DeltaE(leftImg, rightImg)
 	return ret
 }
 
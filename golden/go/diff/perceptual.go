@@ -0,0 +1,238 @@
+package diff
+
+import (
+	"image"
+	"math"
+
+	"go.skia.org/infra/go/util"
+)
+
+const (
+	// ssimWindowSize is the width and height of the windows that SSIM is
+	// computed over, they are smaller if the images are smaller.
+	ssimWindowSize = 8
+
+	// ssimWindowStride is how far apart the SSIM windows are.
+	ssimWindowStride = 4
+
+	// MaxDeltaE is the value of DiffMetrics.DeltaE for pixels that only exist
+	// in one of the images. It is the largest CIEDE2000 difference between two
+	// sRGB colors, e.g. black and white.
+	MaxDeltaE = 100
+)
+
+var (
+	// The stabilizing constants from the SSIM paper for 8 bit channels.
+	ssimC1 = math.Pow(0.01*255, 2)
+	ssimC2 = math.Pow(0.03*255, 2)
+
+	// pow25To7 is 25^7, used in CIEDE2000.
+	pow25To7 = math.Pow(25, 7)
+
+	// srgbToLinear maps an 8 bit sRGB channel value to linear light in [0, 1].
+	srgbToLinear = func() [256]float64 {
+		var ret [256]float64
+		for i := range ret {
+			v := float64(i) / 255
+			if v <= 0.04045 {
+				ret[i] = v / 12.92
+			} else {
+				ret[i] = math.Pow((v+0.055)/1.055, 2.4)
+			}
+		}
+		return ret
+	}()
+)
+
+// overWhite returns the non-premultiplied RGBA pixel at offset i of pix
+// composited over a white background, since that is how the images are viewed.
+func overWhite(pix []uint8, i int) (uint8, uint8, uint8) {
+	a := uint32(pix[i+3])
+	if a == 0xff {
+		return pix[i], pix[i+1], pix[i+2]
+	}
+	blend := func(c uint8) uint8 {
+		return uint8((uint32(c)*a + 0xff*(0xff-a) + 0x7f) / 0xff)
+	}
+	return blend(pix[i]), blend(pix[i+1]), blend(pix[i+2])
+}
+
+// luma returns the Rec. 601 luma, in [0, 255], of the pixel at offset i of pix.
+func luma(pix []uint8, i int) float64 {
+	r, g, b := overWhite(pix, i)
+	return 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+}
+
+// lumaPlane returns the luma of the w x h pixels at the top left of img.
+func lumaPlane(img *image.NRGBA, w, h int) []float64 {
+	ret := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		row := img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y)
+		for x := 0; x < w; x++ {
+			ret[y*w+x] = luma(img.Pix, row+x*4)
+		}
+	}
+	return ret
+}
+
+// SSIM returns the mean structural similarity of the luma of the two images, a
+// value in [-1, 1] where 1 means the images are identical. Unlike the pixel
+// counts it barely changes for antialiasing differences, but drops quickly if
+// shapes move or disappear.
+//
+// Only the area common to both images is compared, and the result is scaled by
+// the fraction of the larger bounds that the common area covers, so images of
+// different sizes are never considered similar.
+func SSIM(left, right *image.NRGBA) float32 {
+	w := util.MinInt(left.Rect.Dx(), right.Rect.Dx())
+	h := util.MinInt(left.Rect.Dy(), right.Rect.Dy())
+	totalPixels := util.MaxInt(left.Rect.Dx(), right.Rect.Dx()) * util.MaxInt(left.Rect.Dy(), right.Rect.Dy())
+	if w == 0 || h == 0 {
+		return 0
+	}
+	l := lumaPlane(left, w, h)
+	r := lumaPlane(right, w, h)
+
+	winW := util.MinInt(ssimWindowSize, w)
+	winH := util.MinInt(ssimWindowSize, h)
+	n := float64(winW * winH)
+	sum := 0.0
+	windows := 0
+	for y0 := 0; y0+winH <= h; y0 += ssimWindowStride {
+		for x0 := 0; x0+winW <= w; x0 += ssimWindowStride {
+			var sumL, sumR, sumLL, sumRR, sumLR float64
+			for y := y0; y < y0+winH; y++ {
+				for x := x0; x < x0+winW; x++ {
+					a, b := l[y*w+x], r[y*w+x]
+					sumL += a
+					sumR += b
+					sumLL += a * a
+					sumRR += b * b
+					sumLR += a * b
+				}
+			}
+			meanL, meanR := sumL/n, sumR/n
+			varL := sumLL/n - meanL*meanL
+			varR := sumRR/n - meanR*meanR
+			covar := sumLR/n - meanL*meanR
+			sum += ((2*meanL*meanR + ssimC1) * (2*covar + ssimC2)) /
+				((meanL*meanL + meanR*meanR + ssimC1) * (varL + varR + ssimC2))
+			windows++
+		}
+	}
+	return float32(sum / float64(windows) * float64(w*h) / float64(totalPixels))
+}
+
+// DeltaE returns the mean CIEDE2000 color difference between the pixels of the
+// two images, composited over white. A difference of about 2.3 is just
+// noticeable, so antialiasing noise results in a small value even if many
+// pixels differ slightly. Pixels that only exist in one of the images count as
+// MaxDeltaE.
+func DeltaE(left, right *image.NRGBA) float32 {
+	w := util.MinInt(left.Rect.Dx(), right.Rect.Dx())
+	h := util.MinInt(left.Rect.Dy(), right.Rect.Dy())
+	totalPixels := util.MaxInt(left.Rect.Dx(), right.Rect.Dx()) * util.MaxInt(left.Rect.Dy(), right.Rect.Dy())
+	if totalPixels == 0 {
+		return 0
+	}
+	sum := float64(totalPixels-w*h) * MaxDeltaE
+	for y := 0; y < h; y++ {
+		lRow := left.PixOffset(left.Rect.Min.X, left.Rect.Min.Y+y)
+		rRow := right.PixOffset(right.Rect.Min.X, right.Rect.Min.Y+y)
+		for x := 0; x < w; x++ {
+			lr, lg, lb := overWhite(left.Pix, lRow+x*4)
+			rr, rg, rb := overWhite(right.Pix, rRow+x*4)
+			if lr == rr && lg == rg && lb == rb {
+				continue
+			}
+			l1, a1, b1 := srgbToLab(lr, lg, lb)
+			l2, a2, b2 := srgbToLab(rr, rg, rb)
+			sum += ciede2000(l1, a1, b1, l2, a2, b2)
+		}
+	}
+	return float32(sum / float64(totalPixels))
+}
+
+// srgbToLab converts an 8 bit sRGB color to CIELAB using the D65 white point.
+func srgbToLab(r, g, b uint8) (float64, float64, float64) {
+	lr, lg, lb := srgbToLinear[r], srgbToLinear[g], srgbToLinear[b]
+	x := (0.4124564*lr + 0.3575761*lg + 0.1804375*lb) / 0.95047
+	y := 0.2126729*lr + 0.7151522*lg + 0.0721750*lb
+	z := (0.0193339*lr + 0.1191920*lg + 0.9503041*lb) / 1.08883
+	f := func(t float64) float64 {
+		if t > 216.0/24389.0 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27.0*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+// hueAngle returns the hue angle in degrees, in [0, 360), of the given a and b
+// components of a CIELAB color.
+func hueAngle(a, b float64) float64 {
+	if a == 0 && b == 0 {
+		return 0
+	}
+	h := math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+// ciede2000 returns the CIEDE2000 difference between two CIELAB colors, see
+// "The CIEDE2000 Color-Difference Formula: Implementation Notes, Supplementary
+// Test Data, and Mathematical Observations" by Sharma, Wu, and Dalal.
+func ciede2000(l1, a1, b1, l2, a2, b2 float64) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	cBar := (math.Hypot(a1, b1) + math.Hypot(a2, b2)) / 2
+	cBar7 := math.Pow(cBar, 7)
+	g := 0.5 * (1 - math.Sqrt(cBar7/(cBar7+pow25To7)))
+	a1p, a2p := (1+g)*a1, (1+g)*a2
+	c1p, c2p := math.Hypot(a1p, b1), math.Hypot(a2p, b2)
+	h1p, h2p := hueAngle(a1p, b1), hueAngle(a2p, b2)
+
+	dLp := l2 - l1
+	dCp := c2p - c1p
+	dhp := 0.0
+	if c1p*c2p != 0 {
+		dhp = h2p - h1p
+		if dhp > 180 {
+			dhp -= 360
+		} else if dhp < -180 {
+			dhp += 360
+		}
+	}
+	dHp := 2 * math.Sqrt(c1p*c2p) * math.Sin(rad(dhp/2))
+
+	lBarp := (l1 + l2) / 2
+	cBarp := (c1p + c2p) / 2
+	var hBarp float64
+	switch {
+	case c1p*c2p == 0:
+		hBarp = h1p + h2p
+	case math.Abs(h1p-h2p) <= 180:
+		hBarp = (h1p + h2p) / 2
+	case h1p+h2p < 360:
+		hBarp = (h1p + h2p + 360) / 2
+	default:
+		hBarp = (h1p + h2p - 360) / 2
+	}
+
+	t := 1 - 0.17*math.Cos(rad(hBarp-30)) + 0.24*math.Cos(rad(2*hBarp)) +
+		0.32*math.Cos(rad(3*hBarp+6)) - 0.20*math.Cos(rad(4*hBarp-63))
+	dTheta := 30 * math.Exp(-math.Pow((hBarp-275)/25, 2))
+	cBarp7 := math.Pow(cBarp, 7)
+	rC := 2 * math.Sqrt(cBarp7/(cBarp7+pow25To7))
+	lBarpMinus50Sq := (lBarp - 50) * (lBarp - 50)
+	sL := 1 + 0.015*lBarpMinus50Sq/math.Sqrt(20+lBarpMinus50Sq)
+	sC := 1 + 0.045*cBarp
+	sH := 1 + 0.015*cBarp*t
+	rT := -math.Sin(rad(2*dTheta)) * rC
+
+	dL, dC, dH := dLp/sL, dCp/sC, dHp/sH
+	return math.Sqrt(dL*dL + dC*dC + dH*dH + rT*dC*dH)
+}
@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCIEDE2000_SharmaTestData_MatchesReference(t *testing.T) {
+	// A selection of the pairs from Table 1 of the Sharma, Wu, and Dalal paper.
+	testCases := []struct {
+		l1, a1, b1, l2, a2, b2 float64
+		expected               float64
+	}{
+		{50.0000, 2.6772, -79.7751, 50.0000, 0.0000, -82.7485, 2.0425},
+		{50.0000, 0.0000, 0.0000, 50.0000, -1.0000, 2.0000, 2.3669},
+		{50.0000, 2.4900, -0.0010, 50.0000, -2.4900, 0.0009, 7.1792},
+		{50.0000, 2.5000, 0.0000, 73.0000, 25.0000, -18.0000, 27.1492},
+		{50.0000, 2.5000, 0.0000, 56.0000, -27.0000, -3.0000, 31.9030},
+		{60.2574, -34.0099, 36.2677, 60.4626, -34.1751, 39.4387, 1.2644},
+		{22.7233, 20.0904, -46.6940, 23.0331, 14.9730, -42.5619, 2.0373},
+		{90.8027, -2.0831, 1.4410, 91.1528, -1.6435, 0.0447, 1.4441},
+	}
+	for _, tc := range testCases {
+		assert.InDelta(t, tc.expected, ciede2000(tc.l1, tc.a1, tc.b1, tc.l2, tc.a2, tc.b2), 0.0001)
+		// The difference is symmetric.
+		assert.InDelta(t, tc.expected, ciede2000(tc.l2, tc.a2, tc.b2, tc.l1, tc.a1, tc.b1), 0.0001)
+	}
+}
+
+func TestSRGBToLab_BlackAndWhite(t *testing.T) {
+	l, a, b := srgbToLab(0, 0, 0)
+	assert.InDelta(t, 0, l, 0.001)
+	assert.InDelta(t, 0, a, 0.001)
+	assert.InDelta(t, 0, b, 0.001)
+
+	l, a, b = srgbToLab(0xff, 0xff, 0xff)
+	assert.InDelta(t, 100, l, 0.001)
+	assert.InDelta(t, 0, a, 0.001)
+	assert.InDelta(t, 0, b, 0.001)
+}
+
+// square returns a w x h white image with a black square of the given size at
+// the given offset.
+func square(w, h, x0, y0, size int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			if x >= x0 && x < x0+size && y >= y0 && y < y0+size {
+				c = color.NRGBA{A: 0xff}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestSSIM_IdenticalImages_ReturnsOne(t *testing.T) {
+	img := square(32, 32, 8, 8, 16)
+	assert.InDelta(t, 1, SSIM(img, img), 0.00001)
+}
+
+func TestSSIMAndDeltaE_AntialiasingVersusMovedShape_AntialiasingIsCloser(t *testing.T) {
+	orig := square(32, 32, 8, 8, 16)
+
+	// Soften the edge pixels of the square slightly, like antialiasing would.
+	antialiased := square(32, 32, 8, 8, 16)
+	for i := 8; i < 24; i++ {
+		antialiased.SetNRGBA(i, 8, color.NRGBA{R: 0x10, G: 0x10, B: 0x10, A: 0xff})
+		antialiased.SetNRGBA(8, i, color.NRGBA{R: 0x10, G: 0x10, B: 0x10, A: 0xff})
+	}
+	moved := square(32, 32, 12, 12, 16)
+
+	assert.Greater(t, SSIM(orig, antialiased), SSIM(orig, moved))
+	assert.Less(t, DeltaE(orig, antialiased), DeltaE(orig, moved))
+}
+
+func TestSSIM_DifferentDimensions_ScaledByCommonArea(t *testing.T) {
+	small := square(16, 16, 0, 0, 0)
+	large := square(32, 16, 0, 0, 0)
+	assert.InDelta(t, 0.5, SSIM(small, large), 0.00001)
+	assert.InDelta(t, 0.5, SSIM(large, small), 0.00001)
+}
+
+func TestDeltaE_DifferentDimensions_MissingPixelsAreMaxDeltaE(t *testing.T) {
+	small := square(16, 16, 0, 0, 0)
+	large := square(32, 16, 0, 0, 0)
+	assert.InDelta(t, MaxDeltaE/2, DeltaE(small, large), 0.00001)
+}
+
+func TestDeltaE_TransparentPixels_ComparedOverWhite(t *testing.T) {
+	white := square(4, 4, 0, 0, 0)
+	transparent := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	assert.Equal(t, float32(0), DeltaE(white, transparent))
+}
@@ -70,6 +70,11 @@ type WorkerImpl struct {
 	inputDigestsSummary      metrics2.Float64SummaryMetric
 	digestsOfInterestSummary metrics2.Float64SummaryMetric
 	metricsCalculatedCounter metrics2.Counter
+	metricsBackfilledCounter metrics2.Counter
+
+	// backfillCursor is the last pair of digests visited by BackfillPerceptualMetrics. The next
+	// call continues after it, so pairs whose images cannot be read do not block the backfill.
+	backfillCursor digestPair
 }
 
 // New returns a diff worker which uses the provided ImageSource.
@@ -80,6 +85,7 @@ func New(db *pgxpool.Pool, src ImageSource, windowSize int) *WorkerImpl {
 		windowSize:               windowSize,
 		badDigestsCache:          ttlcache.New(badImageCooldown, 2*badImageCooldown),
 		metricsCalculatedCounter: metrics2.GetCounter("diffcalculator_metricscalculated"),
+		metricsBackfilledCounter: metrics2.GetCounter("diffcalculator_metricsbackfilled"),
 		inputDigestsSummary:      metrics2.GetFloat64SummaryMetric("diffcalculator_inputdigests"),
 		digestsOfInterestSummary: metrics2.GetFloat64SummaryMetric("diffcalculator_digestsofinterest"),
 	}
@@ -435,6 +441,8 @@ func (w *WorkerImpl) diff(ctx context.Context, left, right types.Digest) (schema
 		MaxRGBADiffs:      m.MaxRGBADiffs,
 		MaxChannelDiff:    max(m.MaxRGBADiffs),
 		CombinedMetric:    m.CombinedMetric,
+		SSIM:              &m.SSIM,
+		DeltaE:            &m.DeltaE,
 		DimensionsDiffer:  m.DimDiffer,
 		Timestamp:         now.Now(ctx),
 	}, nil
@@ -490,8 +498,8 @@ func (w *WorkerImpl) writeMetrics(ctx context.Context, metrics []schema.DiffMetr
 	defer span.End()
 	const baseStatement = `UPSERT INTO DiffMetrics
 (left_digest, right_digest, num_pixels_diff, percent_pixels_diff, max_rgba_diffs,
-max_channel_diff, combined_metric, dimensions_differ, ts) VALUES `
-	const valuesPerRow = 9
+max_channel_diff, combined_metric, ssim, delta_e, dimensions_differ, ts) VALUES `
+	const valuesPerRow = 11
 
 	arguments := make([]interface{}, 0, len(metrics)*valuesPerRow*2)
 	count := 0
@@ -500,9 +508,9 @@ max_channel_diff, combined_metric, dimensions_differ, ts) VALUES `
 		rgba := make([]int, 4)
 		copy(rgba, r.MaxRGBADiffs[:])
 		arguments = append(arguments, r.LeftDigest, r.RightDigest, r.NumPixelsDiff, r.PercentPixelsDiff, rgba,
-			r.MaxChannelDiff, r.CombinedMetric, r.DimensionsDiffer, r.Timestamp)
+			r.MaxChannelDiff, r.CombinedMetric, r.SSIM, r.DeltaE, r.DimensionsDiffer, r.Timestamp)
 		arguments = append(arguments, r.RightDigest, r.LeftDigest, r.NumPixelsDiff, r.PercentPixelsDiff, rgba,
-			r.MaxChannelDiff, r.CombinedMetric, r.DimensionsDiffer, r.Timestamp)
+			r.MaxChannelDiff, r.CombinedMetric, r.SSIM, r.DeltaE, r.DimensionsDiffer, r.Timestamp)
 	}
 	vp := sqlutil.ValuesPlaceholders(valuesPerRow, count)
 	_, err := w.db.Exec(ctx, baseStatement+vp, arguments...)
@@ -512,6 +520,104 @@ max_channel_diff, combined_metric, dimensions_differ, ts) VALUES `
 	return nil
 }
 
+// BackfillPerceptualMetrics computes the SSIM and DeltaE metrics of up to batchSize pairs of
+// digests whose DiffMetrics rows were written before those metrics existed. Each call continues
+// where the previous one stopped and starts over once it reaches the end of the table. It returns
+// the number of pairs it backfilled; zero means a complete pass found nothing to do.
+func (w *WorkerImpl) BackfillPerceptualMetrics(ctx context.Context, batchSize int) (int, error) {
+	ctx, span := trace.StartSpan(ctx, "worker2_BackfillPerceptualMetrics")
+	defer span.End()
+	work, err := w.getPairsToBackfill(ctx, batchSize)
+	if err != nil {
+		return 0, skerr.Wrap(err)
+	}
+	if len(work) < batchSize {
+		// We reached the end of the table, so start over from the beginning next time.
+		w.backfillCursor = digestPair{}
+	} else {
+		w.backfillCursor = work[len(work)-1]
+	}
+	if len(work) == 0 {
+		return 0, nil
+	}
+
+	imgCache, err := lru.New(decodedImageCacheSize)
+	if err != nil {
+		return 0, skerr.Wrap(err)
+	}
+	ctx = addImgCache(ctx, imgCache)
+	defer imgCache.Purge()
+
+	const statement = `UPDATE DiffMetrics SET ssim = $3, delta_e = $4
+WHERE (left_digest = $1 AND right_digest = $2) OR (left_digest = $2 AND right_digest = $1)`
+	backfilled := 0
+	for _, pair := range work {
+		if err := ctx.Err(); err != nil {
+			return backfilled, skerr.Wrap(err)
+		}
+		_, bad1 := w.badDigestsCache.Get(string(pair.left))
+		_, bad2 := w.badDigestsCache.Get(string(pair.right))
+		if bad1 || bad2 {
+			continue
+		}
+		m, iErr := w.diff(ctx, pair.left, pair.right)
+		if iErr != nil {
+			if err := ctx.Err(); err != nil {
+				return backfilled, skerr.Wrap(err)
+			}
+			w.badDigestsCache.Set(string(iErr.digest), true, ttlcache.DefaultExpiration)
+			if err := w.reportProblemImage(ctx, iErr); err != nil {
+				return backfilled, skerr.Wrap(err)
+			}
+			continue
+		}
+		if _, err := w.db.Exec(ctx, statement, m.LeftDigest, m.RightDigest, m.SSIM, m.DeltaE); err != nil {
+			return backfilled, skerr.Wrapf(err, "backfilling metrics of %s and %s", pair.left, pair.right)
+		}
+		backfilled++
+	}
+	w.metricsBackfilledCounter.Inc(int64(backfilled))
+	return backfilled, nil
+}
+
+// getPairsToBackfill returns up to limit pairs of digests after the backfill cursor that are
+// missing the SSIM or DeltaE metrics. Because both directions of a pair are stored, only the
+// canonical (left < right) one is returned.
+func (w *WorkerImpl) getPairsToBackfill(ctx context.Context, limit int) ([]digestPair, error) {
+	ctx, span := trace.StartSpan(ctx, "getPairsToBackfill")
+	defer span.End()
+	// The cursor must not be NULL, or the comparison below would not match any rows.
+	cursorLeft, cursorRight := schema.DigestBytes{}, schema.DigestBytes{}
+	if w.backfillCursor.left != "" {
+		var err error
+		if cursorLeft, err = sql.DigestToBytes(w.backfillCursor.left); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		if cursorRight, err = sql.DigestToBytes(w.backfillCursor.right); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+	}
+	const statement = `SELECT encode(left_digest, 'hex'), encode(right_digest, 'hex') FROM DiffMetrics
+WHERE (ssim IS NULL OR delta_e IS NULL) AND left_digest < right_digest
+  AND (left_digest, right_digest) > ($1, $2)
+ORDER BY left_digest, right_digest
+LIMIT $3`
+	rows, err := w.db.Query(ctx, statement, cursorLeft, cursorRight, limit)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	defer rows.Close()
+	var rv []digestPair
+	for rows.Next() {
+		var left, right types.Digest
+		if err := rows.Scan(&left, &right); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		rv = append(rv, digestPair{left: left, right: right})
+	}
+	return rv, nil
+}
+
 // reportProblemImage creates or updates a row in the ProblemImages table for the given digest.
 func (w *WorkerImpl) reportProblemImage(ctx context.Context, imgErr *imgError) error {
 	ctx, span := trace.StartSpan(ctx, "reportProblemImage")
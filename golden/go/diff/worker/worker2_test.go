@@ -349,6 +349,41 @@ func TestWorkerImpl_GetCommonAndRecentDigests_SmokeTest(t *testing.T) {
 	}, actualSquare)
 }
 
+func TestWorkerImpl_BackfillPerceptualMetrics_RowsWithoutMetrics_Backfilled(t *testing.T) {
+
+	fakeNow := time.Date(2021, time.February, 1, 1, 1, 1, 0, time.UTC)
+	ctx := context.WithValue(context.Background(), now.ContextKey, fakeNow)
+	db := sqltest.NewCockroachDBForTestsWithProductionSchema(ctx, t)
+	// Simulate rows that were computed before the perceptual metrics were added.
+	var existing []schema.DiffMetricRow
+	for _, pair := range [][2]types.Digest{{dks.DigestA01Pos, dks.DigestA02Pos}, {dks.DigestA01Pos, dks.DigestA04Unt}} {
+		for _, row := range []schema.DiffMetricRow{expectedFromKS(t, pair[0], pair[1], fakeNow), expectedFromKS(t, pair[1], pair[0], fakeNow)} {
+			row.SSIM, row.DeltaE = nil, nil
+			existing = append(existing, row)
+		}
+	}
+	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, schema.Tables{DiffMetrics: existing}))
+	waitForSystemTime()
+	w := newWorker2UsingImagesFromKitchenSink(t, db)
+
+	n, err := w.BackfillPerceptualMetrics(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = w.BackfillPerceptualMetrics(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = w.BackfillPerceptualMetrics(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	assert.Equal(t, []schema.DiffMetricRow{
+		expectedFromKS(t, dks.DigestA01Pos, dks.DigestA02Pos, fakeNow),
+		expectedFromKS(t, dks.DigestA01Pos, dks.DigestA04Unt, fakeNow),
+		expectedFromKS(t, dks.DigestA02Pos, dks.DigestA01Pos, fakeNow),
+		expectedFromKS(t, dks.DigestA04Unt, dks.DigestA01Pos, fakeNow),
+	}, getAllDiffMetricRows(t, db))
+}
+
 func newWorker2UsingImagesFromKitchenSink(t *testing.T, db *pgxpool.Pool) *WorkerImpl {
 	return New(db, &fsImageSource{root: kitchenSinkRoot(t)}, 200)
 }
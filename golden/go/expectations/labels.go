@@ -1,5 +1,9 @@
 package expectations
 
+import (
+	"go.skia.org/infra/go/skerr"
+)
+
 // Label represents a digest classification.
 type Label string
 
@@ -18,12 +22,127 @@ const (
 // must match its LabelInt value (Untriaged = 0, etc.).
 var AllLabel = []Label{Untriaged, Positive, Negative}
 
-// ValidLabel returns true if the given Label is valid.
-func ValidLabel(s Label) bool {
-	for _, label := range AllLabel {
-		if label == s {
-			return true
+// LabelDefinition describes a Label that an instance uses in addition to Untriaged, Positive and
+// Negative, e.g. "flaky" for digests that are acceptable but should not be used as references, or
+// "bug_filed" for known bad digests which are being worked on.
+type LabelDefinition struct {
+	// Label is the name of the label, as used in the triage API and in baselines.
+	Label Label `json:"label"`
+
+	// Code is the single character used to store the label in the SQL database. It must not be
+	// one of the codes used by Untriaged ("u"), Positive ("p") or Negative ("n").
+	Code string `json:"code"`
+
+	// Passing is true if digests with this label count as passing (like Positive digests) or
+	// false if they count as failing (like Negative digests), e.g. when goldctl checks an image
+	// against the baseline.
+	Passing bool `json:"passing"`
+
+	// Description is a human-readable explanation of when to use the label.
+	Description string `json:"description,omitempty"`
+}
+
+// builtinLabels are the labels that every instance supports. Untriaged is neither passing nor
+// failing; it is listed here so its code is reserved.
+var builtinLabels = []LabelDefinition{
+	{Label: Untriaged, Code: "u", Description: "A previously unseen digest."},
+	{Label: Positive, Code: "p", Passing: true, Description: "A known good digest."},
+	{Label: Negative, Code: "n", Description: "A known bad digest."},
+}
+
+// Labels are the labels an instance supports, i.e. the built-in labels and the custom labels
+// configured for the instance. A nil *Labels supports only the built-in labels.
+type Labels struct {
+	custom []LabelDefinition
+}
+
+// NewLabels validates the given custom labels and returns the Labels of an instance which
+// supports them in addition to the built-in labels.
+func NewLabels(custom []LabelDefinition) (*Labels, error) {
+	seenLabels := map[Label]bool{}
+	seenCodes := map[string]bool{}
+	for _, def := range builtinLabels {
+		seenLabels[def.Label] = true
+		seenCodes[def.Code] = true
+	}
+	for _, def := range custom {
+		if def.Label == "" {
+			return nil, skerr.Fmt("custom label with code %q must have a name", def.Code)
+		}
+		if len(def.Code) != 1 {
+			return nil, skerr.Fmt("custom label %q must have a single character code, got %q", def.Label, def.Code)
+		}
+		if seenLabels[def.Label] {
+			return nil, skerr.Fmt("duplicate label %q", def.Label)
+		}
+		if seenCodes[def.Code] {
+			return nil, skerr.Fmt("custom label %q uses code %q, which is already in use", def.Label, def.Code)
+		}
+		seenLabels[def.Label] = true
+		seenCodes[def.Code] = true
+	}
+	return &Labels{
+		custom: append([]LabelDefinition(nil), custom...),
+	}, nil
+}
+
+// Custom returns the custom labels.
+func (l *Labels) Custom() []LabelDefinition {
+	if l == nil {
+		return nil
+	}
+	return append([]LabelDefinition(nil), l.custom...)
+}
+
+// All returns the definitions of the built-in labels followed by the custom labels.
+func (l *Labels) All() []LabelDefinition {
+	return append(append([]LabelDefinition(nil), builtinLabels...), l.Custom()...)
+}
+
+// Lookup returns the definition of the given built-in or custom Label.
+func (l *Labels) Lookup(label Label) (LabelDefinition, bool) {
+	for _, def := range l.All() {
+		if def.Label == label {
+			return def, true
+		}
+	}
+	return LabelDefinition{}, false
+}
+
+// LookupCode returns the definition of the built-in or custom Label stored in the SQL database
+// with the given code.
+func (l *Labels) LookupCode(code string) (LabelDefinition, bool) {
+	for _, def := range l.All() {
+		if def.Code == code {
+			return def, true
+		}
+	}
+	return LabelDefinition{}, false
+}
+
+// Valid returns true if the given Label is either one of the built-in labels or a custom label.
+func (l *Labels) Valid(label Label) bool {
+	_, ok := l.Lookup(label)
+	return ok
+}
+
+// IsPassing returns true if digests with the given Label count as passing, i.e. if the label is
+// Positive or one of the given custom labels with Passing set.
+func IsPassing(l Label, custom []LabelDefinition) bool {
+	if l == Positive {
+		return true
+	}
+	for _, def := range custom {
+		if def.Label == l {
+			return def.Passing
 		}
 	}
 	return false
 }
+
+// IsFailing returns true if digests with the given Label count as failing, i.e. if the label is
+// Negative or one of the given custom labels without Passing set. Labels that are not defined
+// in custom are considered failing as well, since they have been triaged by a human.
+func IsFailing(l Label, custom []LabelDefinition) bool {
+	return l != "" && l != Untriaged && !IsPassing(l, custom)
+}
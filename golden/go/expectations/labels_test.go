@@ -0,0 +1,116 @@
+package expectations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	flakyLabel = LabelDefinition{
+		Label:       "flaky",
+		Code:        "f",
+		Passing:     true,
+		Description: "Acceptable, but should not be used as a reference.",
+	}
+	bugFiledLabel = LabelDefinition{
+		Label: "bug_filed",
+		Code:  "b",
+	}
+)
+
+func newLabelsForTest(t *testing.T, defs ...LabelDefinition) *Labels {
+	labels, err := NewLabels(defs)
+	require.NoError(t, err)
+	return labels
+}
+
+func TestValid_NoCustomLabels_OnlyBuiltinLabelsAreValid(t *testing.T) {
+	labels := newLabelsForTest(t)
+
+	assert.True(t, labels.Valid(Untriaged))
+	assert.True(t, labels.Valid(Positive))
+	assert.True(t, labels.Valid(Negative))
+	assert.False(t, labels.Valid("flaky"))
+	assert.False(t, labels.Valid(""))
+}
+
+func TestValid_NilLabels_OnlyBuiltinLabelsAreValid(t *testing.T) {
+	var labels *Labels
+
+	assert.True(t, labels.Valid(Positive))
+	assert.False(t, labels.Valid("flaky"))
+	assert.Empty(t, labels.Custom())
+}
+
+func TestValid_CustomLabels_CustomLabelsAreValid(t *testing.T) {
+	labels := newLabelsForTest(t, flakyLabel, bugFiledLabel)
+
+	assert.True(t, labels.Valid(Positive))
+	assert.True(t, labels.Valid("flaky"))
+	assert.True(t, labels.Valid("bug_filed"))
+	assert.False(t, labels.Valid("wontfix"))
+}
+
+func TestLookupCode_BuiltinAndCustomLabels_Success(t *testing.T) {
+	labels := newLabelsForTest(t, flakyLabel)
+
+	def, ok := labels.LookupCode("p")
+	assert.True(t, ok)
+	assert.Equal(t, Positive, def.Label)
+
+	def, ok = labels.LookupCode("f")
+	assert.True(t, ok)
+	assert.Equal(t, flakyLabel, def)
+
+	_, ok = labels.LookupCode("b")
+	assert.False(t, ok)
+}
+
+func TestAll_BuiltinLabelsFirst(t *testing.T) {
+	labels := newLabelsForTest(t, flakyLabel, bugFiledLabel)
+
+	var names []Label
+	for _, def := range labels.All() {
+		names = append(names, def.Label)
+	}
+	assert.Equal(t, []Label{Untriaged, Positive, Negative, "flaky", "bug_filed"}, names)
+	assert.Equal(t, []LabelDefinition{flakyLabel, bugFiledLabel}, labels.Custom())
+}
+
+func TestNewLabels_InvalidDefinitions_ReturnsError(t *testing.T) {
+	test := func(name string, defs []LabelDefinition, expectedError string) {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewLabels(defs)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), expectedError)
+		})
+	}
+
+	test("missing name", []LabelDefinition{{Code: "f"}}, "must have a name")
+	test("missing code", []LabelDefinition{{Label: "flaky"}}, `"flaky" must have a single character code`)
+	test("long code", []LabelDefinition{{Label: "flaky", Code: "fl"}}, `"flaky" must have a single character code, got "fl"`)
+	test("builtin name", []LabelDefinition{{Label: Positive, Code: "f"}}, `duplicate label "positive"`)
+	test("duplicate name", []LabelDefinition{flakyLabel, {Label: "flaky", Code: "g"}}, `duplicate label "flaky"`)
+	test("builtin code", []LabelDefinition{{Label: "flaky", Code: "p"}}, `code "p", which is already in use`)
+	test("duplicate code", []LabelDefinition{flakyLabel, {Label: "fragile", Code: "f"}}, `code "f", which is already in use`)
+}
+
+func TestIsPassingAndIsFailing_Success(t *testing.T) {
+	custom := []LabelDefinition{flakyLabel, bugFiledLabel}
+
+	test := func(label Label, passing, failing bool) {
+		assert.Equal(t, passing, IsPassing(label, custom), "IsPassing(%q)", label)
+		assert.Equal(t, failing, IsFailing(label, custom), "IsFailing(%q)", label)
+	}
+
+	test(Positive, true, false)
+	test(Negative, false, true)
+	test(Untriaged, false, false)
+	test("", false, false)
+	test("flaky", true, false)
+	test("bug_filed", false, true)
+	// Labels which are not defined are considered failing.
+	test("wontfix", false, true)
+}
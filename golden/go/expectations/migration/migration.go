@@ -0,0 +1,564 @@
+// Package migration exports the expectations of a Gold instance, along with their triage history,
+// to a portable JSON format, and imports them into the same or another instance. On import, the
+// keys of the groupings can be rewritten, e.g. to follow a test rename or a move to a new corpus.
+//
+// Only the primary branch is exported; expectations of CLs are tied to the code review system of
+// the originating instance and are not portable.
+package migration
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/cockroach-go/v2/crdb/crdbpgx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sql/sqlutil"
+	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/golden/go/expectations"
+	"go.skia.org/infra/golden/go/sql"
+	"go.skia.org/infra/golden/go/sql/schema"
+	"go.skia.org/infra/golden/go/types"
+)
+
+// FormatVersion is the version of the Export format written by this package. It should be
+// incremented whenever a backwards incompatible change is made to the format.
+const FormatVersion = 1
+
+// batchSize is the maximum number of rows written in a single statement.
+const batchSize = 1000
+
+// recordNamespace is the UUID namespace of the IDs of imported triage records. See
+// importedRecordID.
+var recordNamespace = uuid.MustParse("6f1c7f9e-3c57-4a47-9a0f-8f2d7e1b4c21")
+
+// Export is the portable representation of the expectations of an instance. Labels are stored by
+// name rather than by their single-character SQL code, because custom label codes are specific
+// to each instance.
+type Export struct {
+	Version    int       `json:"version"`
+	ExportTime time.Time `json:"export_time"`
+	// Filter contains the key/value pairs that all of the exported groupings have in common. If
+	// empty, all groupings were exported.
+	Filter paramtools.Params `json:"filter,omitempty"`
+	// Records are the triage events that touched the exported groupings, oldest first. Deltas
+	// affecting other groupings are omitted.
+	Records []Record `json:"records"`
+	// Expectations are the current labels of the exported groupings.
+	Expectations []Expectation `json:"expectations"`
+}
+
+// Record is a triage event.
+type Record struct {
+	// ID is the ID of the record in the exporting instance. Records get new IDs when imported,
+	// which are derived from this one.
+	ID         string    `json:"id"`
+	UserName   string    `json:"user_name"`
+	TriageTime time.Time `json:"triage_time"`
+	Deltas     []Delta   `json:"deltas"`
+}
+
+// Delta is the change of the label of a single digest in a single grouping.
+type Delta struct {
+	Grouping    paramtools.Params  `json:"grouping"`
+	Digest      types.Digest       `json:"digest"`
+	LabelBefore expectations.Label `json:"label_before"`
+	LabelAfter  expectations.Label `json:"label_after"`
+}
+
+// Expectation is the current label of a digest in a grouping.
+type Expectation struct {
+	Grouping paramtools.Params  `json:"grouping"`
+	Digest   types.Digest       `json:"digest"`
+	Label    expectations.Label `json:"label"`
+	// RecordID is the ID of the Record that most recently set Label, if any.
+	RecordID string `json:"record_id,omitempty"`
+}
+
+// ExportExpectations returns the expectations and the triage history of all groupings on the
+// primary branch that contain the key/value pairs of filter, e.g. {"source_type": "my-corpus"}
+// to export a corpus or the full keys of a grouping to export just that grouping. The given labels
+// must include any custom labels the instance has triaged digests as.
+func ExportExpectations(ctx context.Context, db *pgxpool.Pool, labels *expectations.Labels, filter paramtools.Params, exportTime time.Time) (Export, error) {
+	if filter == nil {
+		filter = paramtools.Params{}
+	}
+	rv := Export{
+		Version:    FormatVersion,
+		ExportTime: exportTime,
+		Filter:     filter,
+	}
+	var err error
+	rv.Records, err = exportRecords(ctx, db, labels, filter)
+	if err != nil {
+		return Export{}, skerr.Wrapf(err, "exporting triage records")
+	}
+	rv.Expectations, err = exportCurrentExpectations(ctx, db, labels, filter)
+	if err != nil {
+		return Export{}, skerr.Wrapf(err, "exporting current expectations")
+	}
+	return rv, nil
+}
+
+// exportRecords returns the primary branch triage records with their deltas for the groupings
+// matching filter, oldest first.
+func exportRecords(ctx context.Context, db *pgxpool.Pool, labels *expectations.Labels, filter paramtools.Params) ([]Record, error) {
+	const statement = `SELECT ExpectationRecords.expectation_record_id, user_name, triage_time,
+  Groupings.keys, digest, label_before, label_after
+FROM ExpectationRecords
+JOIN ExpectationDeltas ON ExpectationRecords.expectation_record_id = ExpectationDeltas.expectation_record_id
+JOIN Groupings ON ExpectationDeltas.grouping_id = Groupings.grouping_id
+WHERE branch_name IS NULL AND Groupings.keys @> $1
+ORDER BY triage_time ASC, ExpectationRecords.expectation_record_id ASC, digest ASC`
+	rows, err := db.Query(ctx, statement, filter)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	defer rows.Close()
+	var rv []Record
+	for rows.Next() {
+		var recordID uuid.UUID
+		var userName string
+		var triageTime time.Time
+		var grouping paramtools.Params
+		var digest schema.DigestBytes
+		var before, after schema.ExpectationLabel
+		if err := rows.Scan(&recordID, &userName, &triageTime, &grouping, &digest, &before, &after); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		delta := Delta{
+			Grouping: grouping,
+			Digest:   types.Digest(hex.EncodeToString(digest)),
+		}
+		if delta.LabelBefore, err = before.ToExpectation(labels); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		if delta.LabelAfter, err = after.ToExpectation(labels); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		// Deltas of the same record are adjacent because of the ORDER BY.
+		if len(rv) == 0 || rv[len(rv)-1].ID != recordID.String() {
+			rv = append(rv, Record{
+				ID:         recordID.String(),
+				UserName:   userName,
+				TriageTime: triageTime.UTC(),
+			})
+		}
+		rv[len(rv)-1].Deltas = append(rv[len(rv)-1].Deltas, delta)
+	}
+	return rv, nil
+}
+
+// exportCurrentExpectations returns the current labels of the digests of the groupings matching
+// filter. Untriaged digests are skipped unless they were explicitly triaged as such.
+func exportCurrentExpectations(ctx context.Context, db *pgxpool.Pool, labels *expectations.Labels, filter paramtools.Params) ([]Expectation, error) {
+	const statement = `SELECT Groupings.keys, digest, label, expectation_record_id
+FROM Expectations
+JOIN Groupings ON Expectations.grouping_id = Groupings.grouping_id
+WHERE (label <> 'u' OR expectation_record_id IS NOT NULL) AND Groupings.keys @> $1
+ORDER BY Groupings.keys->>'name' ASC, digest ASC`
+	rows, err := db.Query(ctx, statement, filter)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	defer rows.Close()
+	var rv []Expectation
+	for rows.Next() {
+		var grouping paramtools.Params
+		var digest schema.DigestBytes
+		var label schema.ExpectationLabel
+		var recordID *uuid.UUID
+		if err := rows.Scan(&grouping, &digest, &label, &recordID); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		e := Expectation{
+			Grouping: grouping,
+			Digest:   types.Digest(hex.EncodeToString(digest)),
+		}
+		if e.Label, err = label.ToExpectation(labels); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		if recordID != nil {
+			e.RecordID = recordID.String()
+		}
+		rv = append(rv, e)
+	}
+	return rv, nil
+}
+
+// KeyRewrite changes the keys of the groupings that contain all key/value pairs of Match by
+// setting the key/value pairs of Set. For example, {"match": {"name": "old"}, "set": {"name":
+// "new"}} follows a test rename and {"match": {"source_type": "a"}, "set": {"source_type": "b"}}
+// moves a corpus.
+type KeyRewrite struct {
+	Match paramtools.Params `json:"match"`
+	Set   paramtools.Params `json:"set"`
+}
+
+// RewriteGrouping applies the given rewrites in order, each to the result of the previous ones,
+// and returns the resulting grouping. The given grouping is not modified.
+func RewriteGrouping(grouping paramtools.Params, rewrites []KeyRewrite) paramtools.Params {
+	rv := grouping.Copy()
+	for _, r := range rewrites {
+		if !containsAll(rv, r.Match) {
+			continue
+		}
+		for k, v := range r.Set {
+			rv[k] = v
+		}
+	}
+	return rv
+}
+
+// containsAll returns true if p has all key/value pairs of subset.
+func containsAll(p, subset paramtools.Params) bool {
+	for k, v := range subset {
+		if p[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ImportOptions control how an Export is imported.
+type ImportOptions struct {
+	// Rewrites are applied to every grouping in the Export before it is imported.
+	Rewrites []KeyRewrite
+	// Overwrite, if true, replaces labels that are already triaged in the target instance.
+	// Otherwise, those labels are left alone, and the imported history of those digests is not
+	// recorded either, so the history of every digest stays consistent with its label.
+	Overwrite bool
+}
+
+// ImportStats summarizes what ImportExpectations wrote.
+type ImportStats struct {
+	Records      int
+	Deltas       int
+	Expectations int
+	// SkippedExpectations are the expectations that were not written because the target instance
+	// had already triaged the digest and ImportOptions.Overwrite was false.
+	SkippedExpectations int
+	// SkippedDeltas are the deltas that were not written for the same reason.
+	SkippedDeltas int
+}
+
+// ImportExpectations writes the given Export to the primary branch of the instance. The triage
+// records keep their original user names and triage times, so triage attribution is preserved,
+// but get new IDs. The labels of the Export must be among the given labels of the target
+// instance.
+//
+// Importing is not atomic, but it is idempotent: the new record IDs are derived from the exported
+// ones and the rewrites, so importing the same Export again, e.g. after a failure, completes the
+// import without recording its history twice.
+func ImportExpectations(ctx context.Context, db *pgxpool.Pool, labels *expectations.Labels, e Export, opts ImportOptions) (ImportStats, error) {
+	if e.Version != FormatVersion {
+		return ImportStats{}, skerr.Fmt("unsupported export version %d; expected %d", e.Version, FormatVersion)
+	}
+	for _, r := range opts.Rewrites {
+		if len(r.Set) == 0 {
+			return ImportStats{}, skerr.Fmt("key rewrite matching %v does not set any keys", r.Match)
+		}
+	}
+	namespace, err := importNamespace(opts.Rewrites)
+	if err != nil {
+		return ImportStats{}, skerr.Wrap(err)
+	}
+
+	groupings := map[schema.MD5Hash]schema.GroupingRow{}
+	convertGrouping := func(g paramtools.Params) schema.GroupingID {
+		rewritten := RewriteGrouping(g, opts.Rewrites)
+		_, groupingID := sql.SerializeMap(rewritten)
+		groupings[sql.AsMD5Hash(groupingID)] = schema.GroupingRow{GroupingID: groupingID, Keys: rewritten}
+		return groupingID
+	}
+
+	records, deltas, newRecordIDs, err := convertRecords(labels, e.Records, namespace, convertGrouping)
+	if err != nil {
+		return ImportStats{}, skerr.Wrap(err)
+	}
+	exps, err := convertExpectations(labels, e.Expectations, newRecordIDs, convertGrouping)
+	if err != nil {
+		return ImportStats{}, skerr.Wrap(err)
+	}
+
+	var stats ImportStats
+	if !opts.Overwrite {
+		// This has to happen before anything is written, otherwise a retried import would see
+		// its own expectations as already triaged.
+		triaged, err := alreadyTriaged(ctx, db, groupings)
+		if err != nil {
+			return ImportStats{}, skerr.Wrapf(err, "looking up existing expectations")
+		}
+		var skippedExps, skippedDeltas int
+		records, deltas, exps, skippedDeltas, skippedExps = withoutTriaged(records, deltas, exps, triaged)
+		stats.SkippedDeltas = skippedDeltas
+		stats.SkippedExpectations = skippedExps
+	}
+
+	if err := writeGroupings(ctx, db, groupings); err != nil {
+		return ImportStats{}, skerr.Wrapf(err, "writing %d groupings", len(groupings))
+	}
+	if err := writeRecords(ctx, db, records); err != nil {
+		return ImportStats{}, skerr.Wrapf(err, "writing %d triage records", len(records))
+	}
+	if err := writeDeltas(ctx, db, deltas); err != nil {
+		return ImportStats{}, skerr.Wrapf(err, "writing %d triage deltas", len(deltas))
+	}
+	if err := writeExpectations(ctx, db, exps); err != nil {
+		return ImportStats{}, skerr.Wrapf(err, "writing %d expectations", len(exps))
+	}
+	stats.Records = len(records)
+	stats.Deltas = len(deltas)
+	stats.Expectations = len(exps)
+	return stats, nil
+}
+
+// importNamespace returns the UUID namespace of the records imported with the given rewrites.
+// The rewrites are part of it so that importing the same Export with different rewrites, e.g. to
+// copy a test under two new names, records the history for each of them.
+func importNamespace(rewrites []KeyRewrite) (uuid.UUID, error) {
+	b, err := json.Marshal(rewrites)
+	if err != nil {
+		return uuid.UUID{}, skerr.Wrap(err)
+	}
+	return uuid.NewSHA1(recordNamespace, b), nil
+}
+
+// importedRecordID returns the ID of the record imported for the exported record with the given
+// ID.
+func importedRecordID(namespace uuid.UUID, exportedID string) uuid.UUID {
+	return uuid.NewSHA1(namespace, []byte(exportedID))
+}
+
+type groupingDigest struct {
+	groupingID schema.MD5Hash
+	digest     schema.MD5Hash
+}
+
+// convertRecords returns the records and deltas to write for the given exported records, along
+// with a mapping from the exported record IDs to the new ones, which are derived in the given
+// namespace. If rewriting groupings causes several deltas of a record to affect the same digest
+// in the same grouping, they are merged.
+func convertRecords(labels *expectations.Labels, exported []Record, namespace uuid.UUID, convertGrouping func(paramtools.Params) schema.GroupingID) ([]schema.ExpectationRecordRow, []schema.ExpectationDeltaRow, map[string]uuid.UUID, error) {
+	var records []schema.ExpectationRecordRow
+	var deltas []schema.ExpectationDeltaRow
+	newRecordIDs := map[string]uuid.UUID{}
+	for _, r := range exported {
+		if len(r.Deltas) == 0 {
+			continue
+		}
+		newID := importedRecordID(namespace, r.ID)
+		newRecordIDs[r.ID] = newID
+		seen := map[groupingDigest]int{}
+		start := len(deltas)
+		for _, d := range r.Deltas {
+			digest, err := sql.DigestToBytes(d.Digest)
+			if err != nil {
+				return nil, nil, nil, skerr.Wrapf(err, "record %s", r.ID)
+			}
+			before, err := schema.FromExpectationLabel(labels, d.LabelBefore)
+			if err != nil {
+				return nil, nil, nil, skerr.Wrapf(err, "record %s", r.ID)
+			}
+			after, err := schema.FromExpectationLabel(labels, d.LabelAfter)
+			if err != nil {
+				return nil, nil, nil, skerr.Wrapf(err, "record %s", r.ID)
+			}
+			groupingID := convertGrouping(d.Grouping)
+			key := groupingDigest{groupingID: sql.AsMD5Hash(groupingID), digest: sql.AsMD5Hash(digest)}
+			if idx, ok := seen[key]; ok {
+				deltas[idx].LabelAfter = after
+				continue
+			}
+			seen[key] = len(deltas)
+			deltas = append(deltas, schema.ExpectationDeltaRow{
+				ExpectationRecordID: newID,
+				GroupingID:          groupingID,
+				Digest:              digest,
+				LabelBefore:         before,
+				LabelAfter:          after,
+			})
+		}
+		records = append(records, schema.ExpectationRecordRow{
+			ExpectationRecordID: newID,
+			UserName:            r.UserName,
+			TriageTime:          r.TriageTime,
+			NumChanges:          len(deltas) - start,
+		})
+	}
+	return records, deltas, newRecordIDs, nil
+}
+
+// convertExpectations returns the expectation rows to write for the given exported expectations.
+// If rewriting groupings causes several expectations to apply to the same digest in the same
+// grouping, the last one wins.
+func convertExpectations(labels *expectations.Labels, exported []Expectation, newRecordIDs map[string]uuid.UUID, convertGrouping func(paramtools.Params) schema.GroupingID) ([]schema.ExpectationRow, error) {
+	var rv []schema.ExpectationRow
+	seen := map[groupingDigest]int{}
+	for _, e := range exported {
+		digest, err := sql.DigestToBytes(e.Digest)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		label, err := schema.FromExpectationLabel(labels, e.Label)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		row := schema.ExpectationRow{
+			GroupingID: convertGrouping(e.Grouping),
+			Digest:     digest,
+			Label:      label,
+		}
+		if newID, ok := newRecordIDs[e.RecordID]; ok {
+			row.ExpectationRecordID = &newID
+		}
+		key := groupingDigest{groupingID: sql.AsMD5Hash(row.GroupingID), digest: sql.AsMD5Hash(digest)}
+		if idx, ok := seen[key]; ok {
+			rv[idx] = row
+			continue
+		}
+		seen[key] = len(rv)
+		rv = append(rv, row)
+	}
+	return rv, nil
+}
+
+// writeGroupings writes the given groupings, leaving existing ones alone.
+func writeGroupings(ctx context.Context, db *pgxpool.Pool, groupings map[schema.MD5Hash]schema.GroupingRow) error {
+	rows := make([]schema.GroupingRow, 0, len(groupings))
+	for _, g := range groupings {
+		rows = append(rows, g)
+	}
+	return util.ChunkIter(len(rows), batchSize, func(startIdx int, endIdx int) error {
+		batch := rows[startIdx:endIdx]
+		const valuesPerRow = 2
+		statement := `INSERT INTO Groupings (grouping_id, keys) VALUES ` +
+			sqlutil.ValuesPlaceholders(valuesPerRow, len(batch)) + ` ON CONFLICT DO NOTHING`
+		arguments := make([]interface{}, 0, valuesPerRow*len(batch))
+		for _, g := range batch {
+			arguments = append(arguments, g.GroupingID, g.Keys)
+		}
+		return execInTx(ctx, db, statement, arguments)
+	})
+}
+
+// writeRecords writes the given primary branch triage records, leaving existing ones alone.
+func writeRecords(ctx context.Context, db *pgxpool.Pool, records []schema.ExpectationRecordRow) error {
+	return util.ChunkIter(len(records), batchSize, func(startIdx int, endIdx int) error {
+		batch := records[startIdx:endIdx]
+		const valuesPerRow = 4
+		statement := `INSERT INTO ExpectationRecords (expectation_record_id, user_name, triage_time, num_changes) VALUES ` +
+			sqlutil.ValuesPlaceholders(valuesPerRow, len(batch)) + ` ON CONFLICT DO NOTHING`
+		arguments := make([]interface{}, 0, valuesPerRow*len(batch))
+		for _, r := range batch {
+			arguments = append(arguments, r.ExpectationRecordID, r.UserName, r.TriageTime, r.NumChanges)
+		}
+		return execInTx(ctx, db, statement, arguments)
+	})
+}
+
+// writeDeltas writes the given triage deltas, leaving existing ones alone.
+func writeDeltas(ctx context.Context, db *pgxpool.Pool, deltas []schema.ExpectationDeltaRow) error {
+	return util.ChunkIter(len(deltas), batchSize, func(startIdx int, endIdx int) error {
+		batch := deltas[startIdx:endIdx]
+		const valuesPerRow = 5
+		statement := `INSERT INTO ExpectationDeltas (expectation_record_id, grouping_id, digest, label_before, label_after) VALUES ` +
+			sqlutil.ValuesPlaceholders(valuesPerRow, len(batch)) + ` ON CONFLICT DO NOTHING`
+		arguments := make([]interface{}, 0, valuesPerRow*len(batch))
+		for _, d := range batch {
+			arguments = append(arguments, d.ExpectationRecordID, d.GroupingID, d.Digest, d.LabelBefore, d.LabelAfter)
+		}
+		return execInTx(ctx, db, statement, arguments)
+	})
+}
+
+// alreadyTriaged returns the digests of the given groupings that have been triaged in the target
+// instance.
+func alreadyTriaged(ctx context.Context, db *pgxpool.Pool, groupings map[schema.MD5Hash]schema.GroupingRow) (map[groupingDigest]bool, error) {
+	groupingIDs := make([]schema.GroupingID, 0, len(groupings))
+	for _, g := range groupings {
+		groupingIDs = append(groupingIDs, g.GroupingID)
+	}
+	rv := map[groupingDigest]bool{}
+	err := util.ChunkIter(len(groupingIDs), batchSize, func(startIdx int, endIdx int) error {
+		const statement = `SELECT grouping_id, digest FROM Expectations
+WHERE label <> 'u' AND grouping_id = ANY($1)`
+		rows, err := db.Query(ctx, statement, groupingIDs[startIdx:endIdx])
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var groupingID schema.GroupingID
+			var digest schema.DigestBytes
+			if err := rows.Scan(&groupingID, &digest); err != nil {
+				return skerr.Wrap(err)
+			}
+			rv[groupingDigest{groupingID: sql.AsMD5Hash(groupingID), digest: sql.AsMD5Hash(digest)}] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return rv, nil
+}
+
+// withoutTriaged returns the given records, deltas and expectations without the deltas and
+// expectations of the triaged digests, along with how many deltas and expectations were removed.
+// Records left without deltas are removed too, and the number of changes of the others is
+// updated.
+func withoutTriaged(records []schema.ExpectationRecordRow, deltas []schema.ExpectationDeltaRow, exps []schema.ExpectationRow, triaged map[groupingDigest]bool) ([]schema.ExpectationRecordRow, []schema.ExpectationDeltaRow, []schema.ExpectationRow, int, int) {
+	numChanges := map[uuid.UUID]int{}
+	keptDeltas := make([]schema.ExpectationDeltaRow, 0, len(deltas))
+	for _, d := range deltas {
+		if triaged[groupingDigest{groupingID: sql.AsMD5Hash(d.GroupingID), digest: sql.AsMD5Hash(d.Digest)}] {
+			continue
+		}
+		keptDeltas = append(keptDeltas, d)
+		numChanges[d.ExpectationRecordID]++
+	}
+	keptRecords := make([]schema.ExpectationRecordRow, 0, len(records))
+	for _, r := range records {
+		if n := numChanges[r.ExpectationRecordID]; n > 0 {
+			r.NumChanges = n
+			keptRecords = append(keptRecords, r)
+		}
+	}
+	keptExps := make([]schema.ExpectationRow, 0, len(exps))
+	for _, e := range exps {
+		if !triaged[groupingDigest{groupingID: sql.AsMD5Hash(e.GroupingID), digest: sql.AsMD5Hash(e.Digest)}] {
+			keptExps = append(keptExps, e)
+		}
+	}
+	return keptRecords, keptDeltas, keptExps, len(deltas) - len(keptDeltas), len(exps) - len(keptExps)
+}
+
+// writeExpectations writes the given primary branch expectations, replacing existing ones.
+func writeExpectations(ctx context.Context, db *pgxpool.Pool, exps []schema.ExpectationRow) error {
+	return util.ChunkIter(len(exps), batchSize, func(startIdx int, endIdx int) error {
+		batch := exps[startIdx:endIdx]
+		const valuesPerRow = 4
+		statement := `UPSERT INTO Expectations (grouping_id, digest, label, expectation_record_id) VALUES ` +
+			sqlutil.ValuesPlaceholders(valuesPerRow, len(batch))
+		arguments := make([]interface{}, 0, valuesPerRow*len(batch))
+		for _, e := range batch {
+			arguments = append(arguments, e.GroupingID, e.Digest, e.Label, e.ExpectationRecordID)
+		}
+		return execInTx(ctx, db, statement, arguments)
+	})
+}
+
+func execInTx(ctx context.Context, db *pgxpool.Pool, statement string, arguments []interface{}) error {
+	err := crdbpgx.ExecuteTx(ctx, db, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, statement, arguments...)
+		return err // don't wrap, could be retryable
+	})
+	return skerr.Wrap(err)
+}
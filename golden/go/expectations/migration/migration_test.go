@@ -0,0 +1,382 @@
+package migration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/golden/go/expectations"
+	"go.skia.org/infra/golden/go/sql"
+	dks "go.skia.org/infra/golden/go/sql/datakitchensink"
+	"go.skia.org/infra/golden/go/sql/schema"
+	"go.skia.org/infra/golden/go/sql/sqltest"
+	"go.skia.org/infra/golden/go/types"
+)
+
+var (
+	exportTime = time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	triangleGrouping = paramtools.Params{types.CorpusField: dks.CornersCorpus, types.PrimaryKeyField: dks.TriangleTest}
+)
+
+func TestExportExpectations_Grouping_ExportsPrimaryBranchHistoryAndLabels(t *testing.T) {
+	ctx := context.Background()
+	db := sqltest.NewCockroachDBForTestsWithProductionSchema(ctx, t)
+	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, dks.Build()))
+
+	e, err := ExportExpectations(ctx, db, nil, triangleGrouping, exportTime)
+	require.NoError(t, err)
+	assert.Equal(t, FormatVersion, e.Version)
+	assert.Equal(t, exportTime, e.ExportTime)
+
+	// The triage event on the CL is not exported.
+	require.Len(t, e.Records, 4)
+	assert.Equal(t, dks.UserOne, e.Records[0].UserName)
+	assert.Equal(t, time.Date(2020, time.June, 7, 8, 9, 43, 0, time.UTC), e.Records[0].TriageTime)
+	assert.Equal(t, []Delta{
+		{Grouping: triangleGrouping, Digest: dks.DigestB01Pos, LabelBefore: expectations.Untriaged, LabelAfter: expectations.Positive},
+		{Grouping: triangleGrouping, Digest: dks.DigestB02Pos, LabelBefore: expectations.Untriaged, LabelAfter: expectations.Positive},
+	}, e.Records[0].Deltas)
+	assert.Equal(t, dks.UserTwo, e.Records[3].UserName)
+	assert.Equal(t, []Delta{
+		{Grouping: triangleGrouping, Digest: dks.DigestB04Neg, LabelBefore: expectations.Positive, LabelAfter: expectations.Negative},
+	}, e.Records[3].Deltas)
+
+	labels := map[types.Digest]expectations.Label{}
+	for _, exp := range e.Expectations {
+		assert.Equal(t, triangleGrouping, exp.Grouping)
+		assert.NotEmpty(t, exp.RecordID)
+		labels[exp.Digest] = exp.Label
+	}
+	assert.Equal(t, map[types.Digest]expectations.Label{
+		dks.DigestB01Pos: expectations.Positive,
+		dks.DigestB02Pos: expectations.Positive,
+		dks.DigestB03Neg: expectations.Negative,
+		dks.DigestB04Neg: expectations.Negative,
+	}, labels)
+	assert.Equal(t, e.Records[3].ID, recordIDOf(e, dks.DigestB04Neg))
+}
+
+func TestExportImport_RenameTest_HistoryAndAttributionPreserved(t *testing.T) {
+	ctx := context.Background()
+	db := sqltest.NewCockroachDBForTestsWithProductionSchema(ctx, t)
+	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, dks.Build()))
+
+	e, err := ExportExpectations(ctx, db, nil, triangleGrouping, exportTime)
+	require.NoError(t, err)
+
+	target := sqltest.NewCockroachDBForTestsWithProductionSchema(ctx, t)
+	stats, err := ImportExpectations(ctx, target, nil, e, ImportOptions{
+		Rewrites: []KeyRewrite{{
+			Match: paramtools.Params{types.PrimaryKeyField: dks.TriangleTest},
+			Set:   paramtools.Params{types.PrimaryKeyField: "triangle_v2"},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ImportStats{Records: 4, Deltas: 5, Expectations: 4}, stats)
+
+	renamed := paramtools.Params{types.CorpusField: dks.CornersCorpus, types.PrimaryKeyField: "triangle_v2"}
+	imported, err := ExportExpectations(ctx, target, nil, renamed, exportTime)
+	require.NoError(t, err)
+	require.Len(t, imported.Records, len(e.Records))
+	for i := range e.Records {
+		assert.Equal(t, e.Records[i].UserName, imported.Records[i].UserName)
+		assert.Equal(t, e.Records[i].TriageTime, imported.Records[i].TriageTime)
+		require.Len(t, imported.Records[i].Deltas, len(e.Records[i].Deltas))
+		for j, d := range imported.Records[i].Deltas {
+			assert.Equal(t, renamed, d.Grouping)
+			assert.Equal(t, e.Records[i].Deltas[j].Digest, d.Digest)
+			assert.Equal(t, e.Records[i].Deltas[j].LabelAfter, d.LabelAfter)
+		}
+	}
+	require.Len(t, imported.Expectations, 4)
+	assert.Equal(t, imported.Records[3].ID, recordIDOf(imported, dks.DigestB04Neg))
+
+	groupings := sqltest.GetAllRows(ctx, t, target, "Groupings", &schema.GroupingRow{}).([]schema.GroupingRow)
+	require.Len(t, groupings, 1)
+	assert.Equal(t, renamed, groupings[0].Keys)
+}
+
+func TestImportExpectations_AlreadyTriaged_NotOverwrittenByDefault(t *testing.T) {
+	ctx := context.Background()
+	db := sqltest.NewCockroachDBForTestsWithProductionSchema(ctx, t)
+	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, dks.Build()))
+
+	e := Export{
+		Version: FormatVersion,
+		Records: []Record{{
+			ID:         "not-a-real-id",
+			UserName:   "migrator@example.com",
+			TriageTime: exportTime,
+			Deltas: []Delta{
+				{Grouping: triangleGrouping, Digest: dks.DigestB01Pos, LabelBefore: expectations.Untriaged, LabelAfter: expectations.Negative},
+			},
+		}},
+		Expectations: []Expectation{
+			{Grouping: triangleGrouping, Digest: dks.DigestB01Pos, Label: expectations.Negative, RecordID: "not-a-real-id"},
+		},
+	}
+	before, err := ExportExpectations(ctx, db, nil, triangleGrouping, exportTime)
+	require.NoError(t, err)
+	stats, err := ImportExpectations(ctx, db, nil, e, ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ImportStats{SkippedDeltas: 1, SkippedExpectations: 1}, stats)
+	assert.Equal(t, expectations.Positive, currentLabel(ctx, t, db, dks.DigestB01Pos))
+	// The history of the skipped expectation isn't recorded either.
+	after, err := ExportExpectations(ctx, db, nil, triangleGrouping, exportTime)
+	require.NoError(t, err)
+	assert.Equal(t, before.Records, after.Records)
+
+	stats, err = ImportExpectations(ctx, db, nil, e, ImportOptions{Overwrite: true})
+	require.NoError(t, err)
+	assert.Equal(t, ImportStats{Records: 1, Deltas: 1, Expectations: 1}, stats)
+	assert.Equal(t, expectations.Negative, currentLabel(ctx, t, db, dks.DigestB01Pos))
+}
+
+func TestImportExpectations_ImportedTwice_HistoryRecordedOnce(t *testing.T) {
+	ctx := context.Background()
+	db := sqltest.NewCockroachDBForTestsWithProductionSchema(ctx, t)
+	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, dks.Build()))
+
+	e, err := ExportExpectations(ctx, db, nil, triangleGrouping, exportTime)
+	require.NoError(t, err)
+
+	target := sqltest.NewCockroachDBForTestsWithProductionSchema(ctx, t)
+	_, err = ImportExpectations(ctx, target, nil, e, ImportOptions{})
+	require.NoError(t, err)
+	_, err = ImportExpectations(ctx, target, nil, e, ImportOptions{Overwrite: true})
+	require.NoError(t, err)
+
+	imported, err := ExportExpectations(ctx, target, nil, triangleGrouping, exportTime)
+	require.NoError(t, err)
+	assert.Len(t, imported.Records, len(e.Records))
+}
+
+func TestImportExpectations_UnknownLabel_ReturnsError(t *testing.T) {
+	e := Export{
+		Version: FormatVersion,
+		Expectations: []Expectation{
+			{Grouping: triangleGrouping, Digest: dks.DigestB01Pos, Label: "flaky"},
+		},
+	}
+	_, err := ImportExpectations(context.Background(), nil, nil, e, ImportOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `label "flaky" is not configured for this instance`)
+}
+
+func TestImportExpectations_WrongVersion_ReturnsError(t *testing.T) {
+	_, err := ImportExpectations(context.Background(), nil, nil, Export{Version: 99}, ImportOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported export version 99")
+}
+
+func TestImportExpectations_RewriteWithoutSet_ReturnsError(t *testing.T) {
+	_, err := ImportExpectations(context.Background(), nil, nil, Export{Version: FormatVersion}, ImportOptions{
+		Rewrites: []KeyRewrite{{Match: triangleGrouping}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not set any keys")
+}
+
+func TestRewriteGrouping_RulesAppliedInOrder(t *testing.T) {
+	rewrites := []KeyRewrite{
+		{
+			Match: paramtools.Params{types.CorpusField: "old_corpus"},
+			Set:   paramtools.Params{types.CorpusField: "new_corpus"},
+		},
+		{
+			Match: paramtools.Params{types.CorpusField: "new_corpus", types.PrimaryKeyField: "old_name"},
+			Set:   paramtools.Params{types.PrimaryKeyField: "new_name"},
+		},
+	}
+	original := paramtools.Params{types.CorpusField: "old_corpus", types.PrimaryKeyField: "old_name"}
+
+	assert.Equal(t, paramtools.Params{types.CorpusField: "new_corpus", types.PrimaryKeyField: "new_name"}, RewriteGrouping(original, rewrites))
+	assert.Equal(t, paramtools.Params{types.CorpusField: "new_corpus", types.PrimaryKeyField: "other_name"},
+		RewriteGrouping(paramtools.Params{types.CorpusField: "old_corpus", types.PrimaryKeyField: "other_name"}, rewrites))
+	assert.Equal(t, paramtools.Params{types.CorpusField: "unrelated", types.PrimaryKeyField: "old_name"},
+		RewriteGrouping(paramtools.Params{types.CorpusField: "unrelated", types.PrimaryKeyField: "old_name"}, rewrites))
+	// The original grouping is not modified.
+	assert.Equal(t, paramtools.Params{types.CorpusField: "old_corpus", types.PrimaryKeyField: "old_name"}, original)
+}
+
+func TestConvertRecords_RewriteMergesGroupings_DeltasMerged(t *testing.T) {
+	a := paramtools.Params{types.CorpusField: "corpus", types.PrimaryKeyField: "a"}
+	b := paramtools.Params{types.CorpusField: "corpus", types.PrimaryKeyField: "b"}
+	records := []Record{{
+		ID:         "record",
+		UserName:   dks.UserOne,
+		TriageTime: exportTime,
+		Deltas: []Delta{
+			{Grouping: a, Digest: dks.DigestA01Pos, LabelBefore: expectations.Untriaged, LabelAfter: expectations.Negative},
+			{Grouping: b, Digest: dks.DigestA01Pos, LabelBefore: expectations.Negative, LabelAfter: expectations.Positive},
+			{Grouping: b, Digest: dks.DigestA02Pos, LabelBefore: expectations.Untriaged, LabelAfter: expectations.Positive},
+		},
+	}}
+	rewrites := []KeyRewrite{{Match: b, Set: paramtools.Params{types.PrimaryKeyField: "a"}}}
+	convertGrouping := func(g paramtools.Params) schema.GroupingID {
+		return schema.GroupingID(RewriteGrouping(g, rewrites)[types.PrimaryKeyField])
+	}
+
+	rows, deltas, newIDs, err := convertRecords(nil, records, recordNamespace, convertGrouping)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, newIDs["record"], rows[0].ExpectationRecordID)
+	assert.Equal(t, dks.UserOne, rows[0].UserName)
+	assert.Equal(t, exportTime, rows[0].TriageTime)
+	assert.Equal(t, 2, rows[0].NumChanges)
+	require.Len(t, deltas, 2)
+	assert.Equal(t, schema.GroupingID("a"), deltas[0].GroupingID)
+	assert.Equal(t, schema.LabelUntriaged, deltas[0].LabelBefore)
+	assert.Equal(t, schema.LabelPositive, deltas[0].LabelAfter)
+	assert.Equal(t, schema.GroupingID("a"), deltas[1].GroupingID)
+	assert.Equal(t, schema.LabelPositive, deltas[1].LabelAfter)
+}
+
+func TestConvertRecords_SameExportedID_SameNewID(t *testing.T) {
+	records := []Record{{
+		ID:         "record",
+		UserName:   dks.UserOne,
+		TriageTime: exportTime,
+		Deltas: []Delta{
+			{Grouping: triangleGrouping, Digest: dks.DigestB01Pos, LabelBefore: expectations.Untriaged, LabelAfter: expectations.Positive},
+		},
+	}}
+	convertGrouping := func(g paramtools.Params) schema.GroupingID {
+		return schema.GroupingID(g[types.PrimaryKeyField])
+	}
+
+	first, _, _, err := convertRecords(nil, records, recordNamespace, convertGrouping)
+	require.NoError(t, err)
+	second, deltas, _, err := convertRecords(nil, records, recordNamespace, convertGrouping)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	require.Len(t, second, 1)
+	assert.Equal(t, first[0].ExpectationRecordID, second[0].ExpectationRecordID)
+	assert.Equal(t, first[0].ExpectationRecordID, deltas[0].ExpectationRecordID)
+
+	other, err := importNamespace([]KeyRewrite{{Match: triangleGrouping, Set: paramtools.Params{types.PrimaryKeyField: "triangle_v2"}}})
+	require.NoError(t, err)
+	third, _, _, err := convertRecords(nil, records, other, convertGrouping)
+	require.NoError(t, err)
+	assert.NotEqual(t, first[0].ExpectationRecordID, third[0].ExpectationRecordID)
+}
+
+func TestImportNamespace_SameRewrites_SameNamespace(t *testing.T) {
+	rewrites := []KeyRewrite{{Match: triangleGrouping, Set: paramtools.Params{types.PrimaryKeyField: "triangle_v2"}}}
+	a, err := importNamespace(rewrites)
+	require.NoError(t, err)
+	b, err := importNamespace(rewrites)
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	none, err := importNamespace(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, a, none)
+}
+
+func TestWithoutTriaged_TriagedDigests_DeltasAndExpectationsDropped(t *testing.T) {
+	recordOne := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	recordTwo := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	a := schema.GroupingID("a")
+	b := schema.GroupingID("b")
+	digestOne := schema.DigestBytes{0x01}
+	digestTwo := schema.DigestBytes{0x02}
+	records := []schema.ExpectationRecordRow{
+		{ExpectationRecordID: recordOne, UserName: dks.UserOne, NumChanges: 2},
+		{ExpectationRecordID: recordTwo, UserName: dks.UserTwo, NumChanges: 1},
+	}
+	deltas := []schema.ExpectationDeltaRow{
+		{ExpectationRecordID: recordOne, GroupingID: a, Digest: digestOne, LabelAfter: schema.LabelPositive},
+		{ExpectationRecordID: recordOne, GroupingID: b, Digest: digestOne, LabelAfter: schema.LabelPositive},
+		{ExpectationRecordID: recordTwo, GroupingID: a, Digest: digestTwo, LabelAfter: schema.LabelNegative},
+	}
+	exps := []schema.ExpectationRow{
+		{GroupingID: a, Digest: digestOne, Label: schema.LabelPositive, ExpectationRecordID: &recordOne},
+		{GroupingID: b, Digest: digestOne, Label: schema.LabelPositive, ExpectationRecordID: &recordOne},
+		{GroupingID: a, Digest: digestTwo, Label: schema.LabelNegative, ExpectationRecordID: &recordTwo},
+	}
+	triaged := map[groupingDigest]bool{
+		{groupingID: sql.AsMD5Hash(b), digest: sql.AsMD5Hash(digestOne)}: true,
+		{groupingID: sql.AsMD5Hash(a), digest: sql.AsMD5Hash(digestTwo)}: true,
+	}
+
+	records, deltas, exps, skippedDeltas, skippedExps := withoutTriaged(records, deltas, exps, triaged)
+	assert.Equal(t, []schema.ExpectationRecordRow{
+		{ExpectationRecordID: recordOne, UserName: dks.UserOne, NumChanges: 1},
+	}, records)
+	assert.Equal(t, []schema.ExpectationDeltaRow{
+		{ExpectationRecordID: recordOne, GroupingID: a, Digest: digestOne, LabelAfter: schema.LabelPositive},
+	}, deltas)
+	assert.Equal(t, []schema.ExpectationRow{
+		{GroupingID: a, Digest: digestOne, Label: schema.LabelPositive, ExpectationRecordID: &recordOne},
+	}, exps)
+	assert.Equal(t, 2, skippedDeltas)
+	assert.Equal(t, 2, skippedExps)
+}
+
+func TestConvertExpectations_RecordIDsMapped(t *testing.T) {
+	newID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	convertGrouping := func(g paramtools.Params) schema.GroupingID {
+		return schema.GroupingID(g[types.PrimaryKeyField])
+	}
+	rows, err := convertExpectations(nil, []Expectation{
+		{Grouping: triangleGrouping, Digest: dks.DigestB01Pos, Label: expectations.Positive, RecordID: "record"},
+		{Grouping: triangleGrouping, Digest: dks.DigestB03Neg, Label: expectations.Negative, RecordID: "not-exported"},
+	}, map[string]uuid.UUID{"record": newID}, convertGrouping)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, schema.GroupingID(dks.TriangleTest), rows[0].GroupingID)
+	assert.Equal(t, schema.LabelPositive, rows[0].Label)
+	require.NotNil(t, rows[0].ExpectationRecordID)
+	assert.Equal(t, newID, *rows[0].ExpectationRecordID)
+	assert.Equal(t, schema.LabelNegative, rows[1].Label)
+	assert.Nil(t, rows[1].ExpectationRecordID)
+}
+
+func recordIDOf(e Export, digest types.Digest) string {
+	for _, exp := range e.Expectations {
+		if exp.Digest == digest {
+			return exp.RecordID
+		}
+	}
+	return ""
+}
+
+// currentLabel returns the current label of the given digest in the triangle grouping.
+func currentLabel(ctx context.Context, t *testing.T, db *pgxpool.Pool, digest types.Digest) expectations.Label {
+	e, err := ExportExpectations(ctx, db, nil, triangleGrouping, exportTime)
+	require.NoError(t, err)
+	for _, exp := range e.Expectations {
+		if exp.Digest == digest {
+			return exp.Label
+		}
+	}
+	return expectations.Untriaged
+}
+
+func TestConvertExpectations_CustomLabel_StoredWithCode(t *testing.T) {
+	labels, err := expectations.NewLabels([]expectations.LabelDefinition{{Label: "flaky", Code: "f"}})
+	require.NoError(t, err)
+	convertGrouping := func(g paramtools.Params) schema.GroupingID {
+		return schema.GroupingID(g[types.PrimaryKeyField])
+	}
+	exported := []Expectation{
+		{Grouping: triangleGrouping, Digest: dks.DigestB01Pos, Label: "flaky"},
+	}
+
+	rows, err := convertExpectations(labels, exported, nil, convertGrouping)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, schema.ExpectationLabel("f"), rows[0].Label)
+
+	// Without the custom label configured, the import fails.
+	_, err = convertExpectations(nil, exported, nil, convertGrouping)
+	require.Error(t, err)
+}
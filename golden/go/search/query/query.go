@@ -4,9 +4,11 @@ package query
 
 import (
 	"net/http"
+	"strings"
 
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/golden/go/expectations"
 	"go.skia.org/infra/golden/go/types"
 	"go.skia.org/infra/golden/go/validation"
 )
@@ -23,11 +25,19 @@ const (
 	PercentMetric = "percent"
 	// PixelMetric corresponds to diff.DiffMetric.NumDiffPixels
 	PixelMetric = "pixel"
+	// SSIMMetric corresponds to diff.DiffMetric.SSIM
+	SSIMMetric = "ssim"
+	// DeltaEMetric corresponds to diff.DiffMetric.DeltaE
+	DeltaEMetric = "deltae"
 )
 
+// AllMetrics are the metrics that search results can be sorted by.
+var AllMetrics = []string{CombinedMetric, PercentMetric, PixelMetric, SSIMMetric, DeltaEMetric}
+
 // ParseSearch parses the request parameters from the URL query string or from the
-// form parameters and stores the parsed and validated values in query.
-func ParseSearch(r *http.Request, q *Search) error {
+// form parameters and stores the parsed and validated values in query. The given labels are the
+// ones the instance supports.
+func ParseSearch(r *http.Request, q *Search, labels *expectations.Labels) error {
 	if err := r.ParseForm(); err != nil {
 		return skerr.Wrapf(err, "parsing form")
 	}
@@ -53,7 +63,7 @@ func ParseSearch(r *http.Request, q *Search) error {
 	q.Offset = int(validate.Int64FormValue(r, "offset", 0))
 	q.Offset = util.MaxInt(q.Offset, 0)
 
-	validate.StrFormValue(r, "metric", &q.Metric, []string{CombinedMetric, PercentMetric, PixelMetric}, CombinedMetric)
+	validate.StrFormValue(r, "metric", &q.Metric, AllMetrics, CombinedMetric)
 	validate.StrFormValue(r, "sort", &q.Sort, []string{SortDescending, SortAscending}, SortDescending)
 
 	// Parse and validate the filter values.
@@ -74,6 +84,11 @@ func ParseSearch(r *http.Request, q *Search) error {
 	q.IncludePositiveDigests = r.FormValue("pos") == "true"
 	q.IncludeNegativeDigests = r.FormValue("neg") == "true"
 	q.IncludeUntriagedDigests = r.FormValue("unt") == "true"
+	customLabels, err := ParseCustomLabels(r, labels)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	q.IncludeCustomLabels = customLabels
 	q.OnlyIncludeDigestsProducedAtHead = r.FormValue("head") == "true"
 	q.IncludeIgnoredTraces = r.FormValue("include") == "true"
 	// TODO(kjlubick) rename this
@@ -84,3 +99,26 @@ func ParseSearch(r *http.Request, q *Search) error {
 
 	return nil
 }
+
+// ParseCustomLabels parses the comma-separated "labels" form value, which lists the custom labels
+// whose digests should be included in the results. They must be among the given labels. The
+// built-in labels are selected via the "pos", "neg" and "unt" form values instead.
+func ParseCustomLabels(r *http.Request, labels *expectations.Labels) ([]expectations.Label, error) {
+	formValue := r.FormValue("labels")
+	if formValue == "" {
+		return nil, nil
+	}
+	var rv []expectations.Label
+	for _, l := range strings.Split(formValue, ",") {
+		label := expectations.Label(strings.TrimSpace(l))
+		switch label {
+		case expectations.Untriaged, expectations.Positive, expectations.Negative:
+			return nil, skerr.Fmt("built-in label %q cannot be used as a custom label", label)
+		}
+		if !labels.Valid(label) {
+			return nil, skerr.Fmt("invalid custom label %q", label)
+		}
+		rv = append(rv, label)
+	}
+	return rv, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"go.skia.org/infra/go/paramtools"
 	"go.skia.org/infra/go/testutils"
+	"go.skia.org/infra/golden/go/expectations"
 )
 
 // TestParseQuery spot checks the parsing of a string and makes sure the object produced
@@ -64,10 +65,51 @@ func TestParseSearchValidList(t *testing.T) {
 }
 
 func clearParseQuery(q *Search, qStr string) error {
+	return clearParseQueryWithLabels(q, qStr, nil)
+}
+
+func clearParseQueryWithLabels(q *Search, qStr string, labels *expectations.Labels) error {
 	*q = Search{}
 	r, err := http.NewRequest("GET", "/?"+qStr, nil)
 	if err != nil {
 		return err
 	}
-	return ParseSearch(r, q)
+	return ParseSearch(r, q, labels)
+}
+
+func TestParseQuery_PerceptualMetrics_Accepted(t *testing.T) {
+	q := &Search{}
+	require.NoError(t, clearParseQuery(q, "metric=ssim"))
+	require.Equal(t, SSIMMetric, q.Metric)
+
+	require.NoError(t, clearParseQuery(q, "metric=deltae"))
+	require.Equal(t, DeltaEMetric, q.Metric)
+
+	require.Error(t, clearParseQuery(q, "metric=psnr"))
+}
+
+func TestParseQuery_CustomLabels_Success(t *testing.T) {
+	labels, err := expectations.NewLabels([]expectations.LabelDefinition{
+		{Label: "flaky", Code: "f", Passing: true},
+		{Label: "bug_filed", Code: "b"},
+	})
+	require.NoError(t, err)
+
+	q := &Search{}
+	require.NoError(t, clearParseQueryWithLabels(q, "pos=true&labels=flaky,bug_filed", labels))
+	require.Equal(t, []expectations.Label{"flaky", "bug_filed"}, q.IncludeCustomLabels)
+	require.False(t, q.ExcludesClassification(expectations.Positive))
+	require.True(t, q.ExcludesClassification(expectations.Negative))
+	require.False(t, q.ExcludesClassification("flaky"))
+
+	require.NoError(t, clearParseQueryWithLabels(q, "labels=flaky", labels))
+	require.True(t, q.ExcludesClassification("bug_filed"))
+
+	require.NoError(t, clearParseQueryWithLabels(q, "unt=true", labels))
+	require.Nil(t, q.IncludeCustomLabels)
+
+	require.Error(t, clearParseQueryWithLabels(q, "labels=wontfix", labels))
+	require.Error(t, clearParseQueryWithLabels(q, "labels=flaky,positive", labels))
+	// Custom labels must be configured for the instance.
+	require.Error(t, clearParseQuery(q, "labels=flaky"))
 }
@@ -22,6 +22,9 @@ type Search struct {
 	IncludeUntriagedDigests          bool
 	OnlyIncludeDigestsProducedAtHead bool
 	IncludeIgnoredTraces             bool
+	// IncludeCustomLabels are the custom labels configured for the instance whose digests should
+	// be included.
+	IncludeCustomLabels []expectations.Label
 
 	// URL encoded query string
 	QueryStr    string
@@ -67,7 +70,18 @@ func (q *Search) IgnoreState() types.IgnoreState {
 // ExcludesClassification returns true if the given label/status for a digest
 // should be excluded based on the values in the query.
 func (q *Search) ExcludesClassification(cl expectations.Label) bool {
-	return ((cl == expectations.Negative) && !q.IncludeNegativeDigests) ||
-		((cl == expectations.Positive) && !q.IncludePositiveDigests) ||
-		((cl == expectations.Untriaged) && !q.IncludeUntriagedDigests)
+	switch cl {
+	case expectations.Negative:
+		return !q.IncludeNegativeDigests
+	case expectations.Positive:
+		return !q.IncludePositiveDigests
+	case expectations.Untriaged:
+		return !q.IncludeUntriagedDigests
+	}
+	for _, l := range q.IncludeCustomLabels {
+		if l == cl {
+			return false
+		}
+	}
+	return true
 }
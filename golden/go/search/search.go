@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -171,9 +172,13 @@ type ClusterOptions struct {
 	IncludePositiveDigests  bool
 	IncludeNegativeDigests  bool
 	IncludeUntriagedDigests bool
+	IncludeCustomLabels     []expectations.Label
 	CodeReviewSystem        string
 	ChangelistID            string
 	PatchsetID              string
+	// Metric is the query metric (see query.go) used as the distance between digests. If empty,
+	// the percentage of pixels that differ is used.
+	Metric string
 }
 
 const (
@@ -204,10 +209,14 @@ type Impl struct {
 	paramsetCache        *ttlcache.Cache
 
 	materializedViews map[string]bool
+
+	// labels are the labels the instance supports, used to convert them to and from the codes
+	// stored in the database.
+	labels *expectations.Labels
 }
 
-// New returns an implementation of API.
-func New(sqlDB *pgxpool.Pool, windowLength int) *Impl {
+// New returns an implementation of API. A nil labels supports only the built-in labels.
+func New(sqlDB *pgxpool.Pool, windowLength int, labels *expectations.Labels) *Impl {
 	cc, err := lru.New(commitCacheSize)
 	if err != nil {
 		panic(err) // should only happen if commitCacheSize is negative.
@@ -230,6 +239,7 @@ func New(sqlDB *pgxpool.Pool, windowLength int) *Impl {
 		traceCache:           tc,
 		paramsetCache:        pc,
 		reviewSystemMapping:  map[string]string{},
+		labels:               labels,
 	}
 }
 
@@ -813,6 +823,50 @@ func getQualifiedPS(ctx context.Context) string {
 	return ctx.Value(qualifiedPSIDKey).(string)
 }
 
+// labelsToInclude returns the SQL codes of the labels whose digests should be included in the
+// search results.
+func (s *Impl) labelsToInclude(untriaged, negative, positive bool, custom []expectations.Label) ([]schema.ExpectationLabel, error) {
+	var rv []schema.ExpectationLabel
+	if untriaged {
+		rv = append(rv, schema.LabelUntriaged)
+	}
+	if negative {
+		rv = append(rv, schema.LabelNegative)
+	}
+	if positive {
+		rv = append(rv, schema.LabelPositive)
+	}
+	for _, label := range custom {
+		code, err := schema.FromExpectationLabel(s.labels, label)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		rv = append(rv, code)
+	}
+	return rv, nil
+}
+
+// triagedLabelCodes returns the SQL codes of all the labels other than untriaged, and of the ones
+// among them that count as passing.
+func (s *Impl) triagedLabelCodes() (triaged, passing []schema.ExpectationLabel) {
+	for _, def := range s.labels.All() {
+		if def.Label == expectations.Untriaged {
+			continue
+		}
+		triaged = append(triaged, schema.ExpectationLabel(def.Code))
+		if def.Passing {
+			passing = append(passing, schema.ExpectationLabel(def.Code))
+		}
+	}
+	return triaged, passing
+}
+
+// isPassing returns true if digests with the given label count as passing, i.e. if it is
+// Positive or a custom label with Passing set.
+func (s *Impl) isPassing(label expectations.Label) bool {
+	return expectations.IsPassing(label, s.labels.Custom())
+}
+
 // addCommitsData finds the current sliding window of data (The last N commits) and adds the
 // derived data to the given context and returns it.
 func (s *Impl) addCommitsData(ctx context.Context) (context.Context, error) {
@@ -897,15 +951,10 @@ JOIN
 MatchingTraces ON MatchingDigests.grouping_id = MatchingTraces.grouping_id AND
   MatchingDigests.digest = MatchingTraces.digest`
 
-	var triageStatuses []schema.ExpectationLabel
-	if q.IncludeUntriagedDigests {
-		triageStatuses = append(triageStatuses, schema.LabelUntriaged)
-	}
-	if q.IncludeNegativeDigests {
-		triageStatuses = append(triageStatuses, schema.LabelNegative)
-	}
-	if q.IncludePositiveDigests {
-		triageStatuses = append(triageStatuses, schema.LabelPositive)
+	triageStatuses, err := s.labelsToInclude(q.IncludeUntriagedDigests, q.IncludeNegativeDigests,
+		q.IncludePositiveDigests, q.IncludeCustomLabels)
+	if err != nil {
+		return nil, skerr.Wrap(err)
 	}
 	arguments := append([]interface{}{triageStatuses}, args...)
 
@@ -1229,13 +1278,13 @@ func (s *Impl) getClosestDiffs(ctx context.Context, inputs []digestWithTraceAndG
 						return skerr.Wrap(err)
 					}
 					digestAndClosestDiffs.rightDigests = append(digestAndClosestDiffs.rightDigests, digestBytes)
-					if srdd.Status == expectations.Positive {
+					if s.isPassing(srdd.Status) {
 						digestAndClosestDiffs.closestPositive = srdd
 					} else {
 						digestAndClosestDiffs.closestNegative = srdd
 					}
 					if digestAndClosestDiffs.closestNegative != nil && digestAndClosestDiffs.closestPositive != nil {
-						if digestAndClosestDiffs.closestPositive.CombinedMetric < digestAndClosestDiffs.closestNegative.CombinedMetric {
+						if digestAndClosestDiffs.closestPositive.QueryMetric < digestAndClosestDiffs.closestNegative.QueryMetric {
 							digestAndClosestDiffs.closestDigest = digestAndClosestDiffs.closestPositive
 						} else {
 							digestAndClosestDiffs.closestDigest = digestAndClosestDiffs.closestNegative
@@ -1308,8 +1357,15 @@ func (s *Impl) getClosestDiffs(ctx context.Context, inputs []digestWithTraceAndG
 		if results[i].closestDigest != nil && results[j].closestDigest == nil {
 			return false
 		}
+		if results[i].closestDigest != nil && results[j].closestDigest != nil {
+			iKnown := results[i].closestDigest.QueryMetric != unknownQueryMetric
+			jKnown := results[j].closestDigest.QueryMetric != unknownQueryMetric
+			if iKnown != jKnown {
+				return iKnown // sort results whose metric was not backfilled yet to the bottom
+			}
+		}
 		if (results[i].closestDigest == nil && results[j].closestDigest == nil) ||
-			results[i].closestDigest.CombinedMetric == results[j].closestDigest.CombinedMetric {
+			results[i].closestDigest.QueryMetric == results[j].closestDigest.QueryMetric {
 			// Tiebreak using digest in ascending order, followed by groupingID.
 			c := bytes.Compare(results[i].leftDigest, results[j].leftDigest)
 			if c != 0 {
@@ -1318,9 +1374,9 @@ func (s *Impl) getClosestDiffs(ctx context.Context, inputs []digestWithTraceAndG
 			return bytes.Compare(results[i].groupingID, results[j].groupingID) < 0
 		}
 		if sortAsc {
-			return results[i].closestDigest.CombinedMetric < results[j].closestDigest.CombinedMetric
+			return results[i].closestDigest.QueryMetric < results[j].closestDigest.QueryMetric
 		}
-		return results[i].closestDigest.CombinedMetric > results[j].closestDigest.CombinedMetric
+		return results[i].closestDigest.QueryMetric > results[j].closestDigest.QueryMetric
 	})
 
 	if q.Limit <= 0 {
@@ -1336,8 +1392,32 @@ func (s *Impl) getClosestDiffs(ctx context.Context, inputs []digestWithTraceAndG
 	return results[q.Offset:end], extendedBulkTriageDeltaInfos, nil
 }
 
-// getDiffsForGrouping returns the closest positive and negative diffs for the provided digests
-// in the given grouping.
+// queryMetricExpression returns the DiffMetrics SQL expression, as a FLOAT4, for the given
+// query metric (see query.go). Smaller values always mean the digests are more similar, so SSIM
+// is inverted. The combined metric is used if the metric is unknown. SSIM and DeltaE are NULL for
+// diffs that have not been backfilled yet.
+func queryMetricExpression(metric string) string {
+	switch metric {
+	case query.PercentMetric:
+		return "percent_pixels_diff"
+	case query.PixelMetric:
+		return "num_pixels_diff::FLOAT4"
+	case query.SSIMMetric:
+		return "(1 - ssim)"
+	case query.DeltaEMetric:
+		return "delta_e"
+	default:
+		return "combined_metric"
+	}
+}
+
+// unknownQueryMetric is the QueryMetric of diffs whose requested metric has not been backfilled
+// yet. It makes them the least similar when choosing the closest reference digest.
+var unknownQueryMetric = float32(math.Inf(1))
+
+// getDiffsForGrouping returns the closest passing and failing diffs for the provided digests
+// in the given grouping. Digests with a custom label count as passing or failing according to
+// the label's definition.
 func (s *Impl) getDiffsForGrouping(ctx context.Context, groupingID schema.MD5Hash, leftDigests []schema.DigestBytes) (map[groupingDigestKey][]*frontend.SRDiffDigest, error) {
 	ctx, span := trace.StartSpan(ctx, "getDiffsForGrouping")
 	defer span.End()
@@ -1346,28 +1426,31 @@ func (s *Impl) getDiffsForGrouping(ctx context.Context, groupingID schema.MD5Has
 	if err != nil {
 		return nil, skerr.Wrap(err)
 	}
+	metric := queryMetricExpression(getQuery(ctx).Metric)
+	triaged, passing := s.triagedLabelCodes()
 	statement := `
 WITH
-PositiveOrNegativeDigests AS (
-	SELECT digest, label FROM Expectations
-	WHERE grouping_id = $1 AND (label = 'n' OR label = 'p')
+TriagedDigests AS (
+	SELECT digest, label, label = ANY($5) AS passing FROM Expectations
+	WHERE grouping_id = $1 AND label = ANY($4)
 ),
 ComparisonBetweenUntriagedAndObserved AS (
 	SELECT DiffMetrics.* FROM DiffMetrics
 	WHERE left_digest = ANY($2) AND right_digest = ANY($3)
 )
--- This will return the right_digest with the smallest combined_metric for each left_digest + label
-SELECT DISTINCT ON (left_digest, label)
+-- This will return the right_digest with the smallest query metric for each left_digest, among
+-- the passing and among the failing digests.
+SELECT DISTINCT ON (left_digest, passing)
   label, left_digest, right_digest, num_pixels_diff, percent_pixels_diff, max_rgba_diffs,
-  combined_metric, dimensions_differ
+  combined_metric, dimensions_differ, ` + metric + `
 FROM
   ComparisonBetweenUntriagedAndObserved
-JOIN PositiveOrNegativeDigests
-  ON ComparisonBetweenUntriagedAndObserved.right_digest = PositiveOrNegativeDigests.digest
-ORDER BY left_digest, label, combined_metric ASC, max_channel_diff ASC, right_digest ASC
+JOIN TriagedDigests
+  ON ComparisonBetweenUntriagedAndObserved.right_digest = TriagedDigests.digest
+ORDER BY left_digest, passing, ` + metric + ` ASC NULLS LAST, max_channel_diff ASC, right_digest ASC
 `
 
-	rows, err := s.db.Query(ctx, statement, groupingID[:], leftDigests, digestsInGrouping)
+	rows, err := s.db.Query(ctx, statement, groupingID[:], leftDigests, digestsInGrouping, triaged, passing)
 	if err != nil {
 		return nil, skerr.Wrap(err)
 	}
@@ -1375,22 +1458,31 @@ ORDER BY left_digest, label, combined_metric ASC, max_channel_diff ASC, right_di
 	results := map[groupingDigestKey][]*frontend.SRDiffDigest{}
 	var label schema.ExpectationLabel
 	var row schema.DiffMetricRow
+	var queryMetric *float32
 	for rows.Next() {
 		if err := rows.Scan(&label, &row.LeftDigest, &row.RightDigest, &row.NumPixelsDiff,
 			&row.PercentPixelsDiff, &row.MaxRGBADiffs, &row.CombinedMetric,
-			&row.DimensionsDiffer); err != nil {
+			&row.DimensionsDiffer, &queryMetric); err != nil {
+			rows.Close()
+			return nil, skerr.Wrap(err)
+		}
+		status, err := label.ToExpectation(s.labels)
+		if err != nil {
 			rows.Close()
 			return nil, skerr.Wrap(err)
 		}
 		srdd := &frontend.SRDiffDigest{
 			Digest:           types.Digest(hex.EncodeToString(row.RightDigest)),
-			Status:           label.ToExpectation(),
+			Status:           status,
 			CombinedMetric:   row.CombinedMetric,
 			DimDiffer:        row.DimensionsDiffer,
 			MaxRGBADiffs:     row.MaxRGBADiffs,
 			NumDiffPixels:    row.NumPixelsDiff,
 			PixelDiffPercent: row.PercentPixelsDiff,
-			QueryMetric:      row.CombinedMetric,
+			QueryMetric:      unknownQueryMetric,
+		}
+		if queryMetric != nil {
+			srdd.QueryMetric = *queryMetric
 		}
 		key := groupingDigestKey{
 			digest:     sql.AsMD5Hash(row.LeftDigest),
@@ -1750,9 +1842,9 @@ func (s *Impl) fillOutTraceHistory(ctx context.Context, inputs []digestAndCloses
 					frontend.NegativeRef: input.closestNegative,
 				},
 			}
-			if input.closestDigest != nil && input.closestDigest.Status == expectations.Positive {
+			if input.closestDigest != nil && s.isPassing(input.closestDigest.Status) {
 				sr.ClosestRef = frontend.PositiveRef
-			} else if input.closestDigest != nil && input.closestDigest.Status == expectations.Negative {
+			} else if input.closestDigest != nil {
 				sr.ClosestRef = frontend.NegativeRef
 			}
 			tg, err := s.traceGroupForTraces(eCtx, input.traceIDs, input.optionsIDs, sr.Digest)
@@ -1893,9 +1985,13 @@ CLExpectations FULL OUTER JOIN PrimaryExpectations ON
 		if err := rows.Scan(&digest, &label); err != nil {
 			return skerr.Wrap(err)
 		}
+		status, err := label.ToExpectation(s.labels)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
 		for i, ds := range tg.Digests {
 			if ds.Digest == digest {
-				tg.Digests[i].Status = label.ToExpectation()
+				tg.Digests[i].Status = status
 			}
 		}
 	}
@@ -2072,7 +2168,11 @@ func (s *Impl) populateLabelBefore(ctx context.Context, triageDeltaInfos []exten
 		if !ok {
 			label = schema.LabelUntriaged
 		}
-		triageDeltaInfo.LabelBefore = label.ToExpectation()
+		labelBefore, err := label.ToExpectation(s.labels)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		triageDeltaInfo.LabelBefore = labelBefore
 	}
 
 	return nil
@@ -2113,7 +2213,11 @@ func (s *Impl) populateLabelBeforeForCL(ctx context.Context, triageDeltaInfos []
 		if !ok {
 			label = schema.LabelUntriaged
 		}
-		triageDeltaInfo.LabelBefore = label.ToExpectation()
+		labelBefore, err := label.ToExpectation(s.labels)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		triageDeltaInfo.LabelBefore = labelBefore
 	}
 
 	return nil
@@ -2420,15 +2524,10 @@ LEFT JOIN CLExpectations
 WHERE COALESCE(CLExpectations.label, COALESCE(Expectations.label, 'u')) = ANY($3)
 `
 
-	var triageStatuses []schema.ExpectationLabel
-	if q.IncludeUntriagedDigests {
-		triageStatuses = append(triageStatuses, schema.LabelUntriaged)
-	}
-	if q.IncludeNegativeDigests {
-		triageStatuses = append(triageStatuses, schema.LabelNegative)
-	}
-	if q.IncludePositiveDigests {
-		triageStatuses = append(triageStatuses, schema.LabelPositive)
+	triageStatuses, err := s.labelsToInclude(q.IncludeUntriagedDigests, q.IncludeNegativeDigests,
+		q.IncludePositiveDigests, q.IncludeCustomLabels)
+	if err != nil {
+		return nil, skerr.Wrap(err)
 	}
 
 	rows, err := s.db.Query(ctx, statement, getQualifiedCL(ctx), getQualifiedPS(ctx), triageStatuses)
@@ -3018,10 +3117,14 @@ WHERE grouping_id = $1 and digest = ANY($2)`
 				if err := rows.Scan(&digest, &label); err != nil {
 					return skerr.Wrap(err)
 				}
+				l, err := label.ToExpectation(s.labels)
+				if err != nil {
+					return skerr.Wrap(err)
+				}
 				exp[expectationKey{
 					groupingID: groupingKey,
 					digest:     digest,
-				}] = label.ToExpectation()
+				}] = l
 			}
 			return nil
 		})
@@ -3252,7 +3355,7 @@ func (s *Impl) GetCluster(ctx context.Context, opts ClusterOptions) (frontend.Cl
 	if err != nil {
 		return frontend.ClusterDiffResult{}, skerr.Wrap(err)
 	}
-	nodes, links, err := s.getLinks(ctx, digestsAndTraces)
+	nodes, links, err := s.getLinks(ctx, digestsAndTraces, opts.Metric)
 	if err != nil {
 		return frontend.ClusterDiffResult{}, skerr.Wrap(err)
 	}
@@ -3292,15 +3395,10 @@ FROM DataOfInterest JOIN Expectations
 	ON Expectations.grouping_id = $1 and DataOfInterest.digest = Expectations.digest
 WHERE label = ANY($3)
 `
-	var triageStatuses []schema.ExpectationLabel
-	if opts.IncludeUntriagedDigests {
-		triageStatuses = append(triageStatuses, schema.LabelUntriaged)
-	}
-	if opts.IncludeNegativeDigests {
-		triageStatuses = append(triageStatuses, schema.LabelNegative)
-	}
-	if opts.IncludePositiveDigests {
-		triageStatuses = append(triageStatuses, schema.LabelPositive)
+	triageStatuses, err := s.labelsToInclude(opts.IncludeUntriagedDigests, opts.IncludeNegativeDigests,
+		opts.IncludePositiveDigests, opts.IncludeCustomLabels)
+	if err != nil {
+		return nil, skerr.Wrap(err)
 	}
 	if len(triageStatuses) == 0 {
 		return nil, nil // If no triage status is set, there can be no results.
@@ -3408,8 +3506,8 @@ DataOfInterest AS (
 }
 
 // getLinks returns the nodes and links that correspond to the digests and how each compares to
-// the other digests.
-func (s *Impl) getLinks(ctx context.Context, digests map[schema.MD5Hash]*digestClusterInfo) ([]frontend.Node, []frontend.Link, error) {
+// the other digests, using the given query metric as the distance.
+func (s *Impl) getLinks(ctx context.Context, digests map[schema.MD5Hash]*digestClusterInfo, metric string) ([]frontend.Node, []frontend.Link, error) {
 	ctx, span := trace.StartSpan(ctx, "getDigestsAndTracesForCluster")
 	defer span.End()
 
@@ -3417,10 +3515,14 @@ func (s *Impl) getLinks(ctx context.Context, digests map[schema.MD5Hash]*digestC
 	nodes := make([]frontend.Node, 0, len(digestsToLookup))
 	for digest, info := range digests {
 		if len(info.traceIDs) > 0 {
+			status, err := info.label.ToExpectation(s.labels)
+			if err != nil {
+				return nil, nil, skerr.Wrap(err)
+			}
 			digestsToLookup = append(digestsToLookup, sql.FromMD5Hash(digest))
 			nodes = append(nodes, frontend.Node{
 				Digest: types.Digest(hex.EncodeToString(digest[:])),
-				Status: info.label.ToExpectation(),
+				Status: status,
 			})
 		}
 	}
@@ -3438,9 +3540,13 @@ func (s *Impl) getLinks(ctx context.Context, digests map[schema.MD5Hash]*digestC
 	}
 
 	span.AddAttributes(trace.Int64Attribute("num_digests", int64(len(digestsToLookup))))
-	const statement = `SELECT encode(left_digest, 'hex'), encode(right_digest, 'hex'), percent_pixels_diff
+	if metric == "" {
+		metric = query.PercentMetric
+	}
+	statement := `SELECT encode(left_digest, 'hex'), encode(right_digest, 'hex'), ` + queryMetricExpression(metric) + `
 FROM DiffMetrics AS OF SYSTEM TIME '-0.1s'
 WHERE left_digest = ANY($1) AND right_digest = ANY($1) AND left_digest < right_digest
+  AND ` + queryMetricExpression(metric) + ` IS NOT NULL
 ORDER BY 1, 2`
 	rows, err := s.db.Query(ctx, statement, digestsToLookup)
 	if err != nil {
@@ -3877,7 +3983,11 @@ FULL OUTER JOIN ExpectationsFromPrimary USING (digest)
 		}
 		return "", skerr.Wrap(err)
 	}
-	return label.ToExpectation(), nil
+	l, err := label.ToExpectation(s.labels)
+	if err != nil {
+		return "", skerr.Wrap(err)
+	}
+	return l, nil
 }
 
 // getParamsetsForTracesProducing returns the paramset of the traces on the primary branch which
@@ -3987,7 +4097,7 @@ OldestCommitInWindow AS (
 	JOIN Groupings ON DigestsOfInterest.grouping_id = Groupings.grouping_id
 )
 SELECT encode(grouping_id, 'hex'), grouping, label, COUNT(digest) FROM DigestsWithLabels
-GROUP BY grouping_id, grouping, label ORDER BY grouping->>'name'`
+GROUP BY grouping_id, grouping, label ORDER BY grouping->>'name', grouping_id`
 
 	arguments := []interface{}{s.windowLength}
 	arguments = append(arguments, digestsArgs...)
@@ -3999,8 +4109,7 @@ GROUP BY grouping_id, grouping, label ORDER BY grouping->>'name'`
 		return frontend.ListTestsResponse{}, skerr.Wrap(err)
 	}
 	defer rows.Close()
-	var currentSummary *frontend.TestSummary
-	var currentSummaryGroupingID string
+	summariesByGroupingID := map[string]*frontend.TestSummary{}
 	var summaries []*frontend.TestSummary
 	for rows.Next() {
 		var groupingID string
@@ -4010,17 +4119,24 @@ GROUP BY grouping_id, grouping, label ORDER BY grouping->>'name'`
 		if err := rows.Scan(&groupingID, &grouping, &label, &count); err != nil {
 			return frontend.ListTestsResponse{}, skerr.Wrap(err)
 		}
-		if currentSummary == nil || currentSummaryGroupingID != groupingID {
-			currentSummary = &frontend.TestSummary{Grouping: grouping}
-			currentSummaryGroupingID = groupingID
-			summaries = append(summaries, currentSummary)
+		summary, ok := summariesByGroupingID[groupingID]
+		if !ok {
+			summary = &frontend.TestSummary{Grouping: grouping}
+			summariesByGroupingID[groupingID] = summary
+			summaries = append(summaries, summary)
+		}
+		// Custom labels are counted with the positive or negative digests, depending on
+		// whether they count as passing or failing.
+		def, ok := s.labels.LookupCode(string(label))
+		if !ok {
+			return frontend.ListTestsResponse{}, skerr.Fmt("unknown label code %q", label)
 		}
-		if label == schema.LabelNegative {
-			currentSummary.NegativeDigests = count
-		} else if label == schema.LabelPositive {
-			currentSummary.PositiveDigests = count
+		if def.Label == expectations.Untriaged {
+			summary.UntriagedDigests += count
+		} else if def.Passing {
+			summary.PositiveDigests += count
 		} else {
-			currentSummary.UntriagedDigests = count
+			summary.NegativeDigests += count
 		}
 	}
 
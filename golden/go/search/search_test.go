@@ -40,7 +40,7 @@ func TestNewAndUntriagedSummaryForCL_OnePatchset_Success(t *testing.T) {
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartCacheProcess(ctx, time.Minute, 100))
 	rv, err := s.NewAndUntriagedSummaryForCL(ctx, sql.Qualify(dks.GerritCRS, dks.ChangelistIDThatAttemptsToFixIOS))
 	require.NoError(t, err)
@@ -67,7 +67,7 @@ func TestNewAndUntriagedSummaryForCL_OnePatchsetWithMultipleDatapointsOnSameTrac
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartCacheProcess(ctx, time.Minute, 100))
 	rv, err := s.NewAndUntriagedSummaryForCL(ctx, sql.Qualify(dks.GerritCRS, dks.ChangelistIDWithMultipleDatapointsPerTrace))
 	require.NoError(t, err)
@@ -92,7 +92,7 @@ func TestNewAndUntriagedSummaryForCL_TwoPatchsets_Success(t *testing.T) {
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartCacheProcess(ctx, time.Minute, 100))
 	rv, err := s.NewAndUntriagedSummaryForCL(ctx, sql.Qualify(dks.GerritInternalCRS, dks.ChangelistIDThatAddsNewTests))
 	require.NoError(t, err)
@@ -161,7 +161,7 @@ func TestNewAndUntriagedSummaryForCL_NoNewDataForPS_Success(t *testing.T) {
 	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, b.Build()))
 	waitForSystemTime()
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartCacheProcess(ctx, time.Minute, 100))
 	rv, err := s.NewAndUntriagedSummaryForCL(ctx, sql.Qualify(dks.GerritCRS, clID))
 	require.NoError(t, err)
@@ -192,7 +192,7 @@ func TestNewAndUntriagedSummaryForCL_CLDoesNotExist_ReturnsError(t *testing.T) {
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartCacheProcess(ctx, time.Minute, 100))
 	_, err := s.NewAndUntriagedSummaryForCL(ctx, sql.Qualify(dks.GerritInternalCRS, "does not exist"))
 	require.Error(t, err)
@@ -262,7 +262,7 @@ func TestNewAndUntriagedSummaryForCL_NewDeviceAdded_DigestsOnPrimaryBranchNotCou
 	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, b.Build()))
 	waitForSystemTime()
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartCacheProcess(ctx, time.Minute, 100))
 	rv, err := s.NewAndUntriagedSummaryForCL(ctx, sql.Qualify(dks.GerritCRS, clID))
 	require.NoError(t, err)
@@ -350,7 +350,7 @@ func TestNewAndUntriagedSummaryForCL_IgnoreRulesRespected(t *testing.T) {
 	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, b.Build()))
 	waitForSystemTime()
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartCacheProcess(ctx, time.Minute, 100))
 	rv, err := s.NewAndUntriagedSummaryForCL(ctx, sql.Qualify(dks.GerritCRS, clID))
 	require.NoError(t, err)
@@ -433,7 +433,7 @@ func TestNewAndUntriagedSummaryForCL_TriageStatusAffectsAllPS(t *testing.T) {
 	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, b.Build()))
 	waitForSystemTime()
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartCacheProcess(ctx, time.Minute, 100))
 	rv, err := s.NewAndUntriagedSummaryForCL(ctx, sql.Qualify(dks.GerritCRS, clID))
 	require.NoError(t, err)
@@ -470,7 +470,7 @@ func TestNewAndUntriagedSummaryForCL_MultipleThreadsAtOnce_NoRaces(t *testing.T)
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	// Update the caches aggressively to be writing to the shared cache while reading from it.
 	require.NoError(t, s.StartCacheProcess(ctx, 100*time.Millisecond, 100))
 
@@ -506,7 +506,7 @@ func TestChangelistLastUpdated_ValidCL_ReturnsLatestTS(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	ts, err := s.ChangelistLastUpdated(ctx, sql.Qualify(dks.GerritInternalCRS, dks.ChangelistIDThatAddsNewTests))
 	require.NoError(t, err)
 	assert.Equal(t, changelistTSForNewTests, ts)
@@ -517,7 +517,7 @@ func TestChangelistLastUpdated_NonExistentCL_ReturnsZeroTime(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	ts, err := s.ChangelistLastUpdated(ctx, sql.Qualify(dks.GerritInternalCRS, "does not exist"))
 	require.NoError(t, err)
 	assert.True(t, ts.IsZero())
@@ -528,7 +528,7 @@ func TestSearch_UntriagedDigestsAtHead_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: true,
 		IncludePositiveDigests:           false,
@@ -552,7 +552,7 @@ func TestSearch_UntriagedDigestsAtHead_WithMaterializedViews(t *testing.T) {
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 10) // Otherwise there's no commit for the materialized views
+	s := New(db, 10, nil) // Otherwise there's no commit for the materialized views
 	require.NoError(t, s.StartMaterializedViews(ctx, []string{dks.CornersCorpus, dks.RoundCorpus}, time.Minute))
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: true,
@@ -798,7 +798,7 @@ func TestStartMaterializedViews_ViewsAreNonEmpty(t *testing.T) {
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 10)
+	s := New(db, 10, nil)
 	require.NoError(t, s.StartMaterializedViews(ctx, []string{dks.CornersCorpus, dks.RoundCorpus}, time.Minute))
 
 	assertNumRows(t, db, "mv_corners_traces", 21)
@@ -810,7 +810,7 @@ func TestStartMaterializedViews_ViewsGetUpdated(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	db := sqltest.NewCockroachDBForTestsWithProductionSchema(ctx, t)
-	s := New(db, 10)
+	s := New(db, 10, nil)
 	require.NoError(t, s.StartMaterializedViews(ctx, []string{dks.CornersCorpus, dks.RoundCorpus}, time.Second))
 	// no data yet
 	assertNumRows(t, db, "mv_corners_traces", 0)
@@ -835,7 +835,7 @@ func TestSearch_IncludeIgnoredAtHead_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: true,
 		IncludePositiveDigests:           false,
@@ -950,7 +950,7 @@ func TestSearch_RespectMinMaxRGBAFilter_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: true,
 		IncludePositiveDigests:           false,
@@ -1040,7 +1040,7 @@ func TestSearch_RespectLimitOffsetOrder_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: true,
 		IncludePositiveDigests:           true,
@@ -1485,7 +1485,7 @@ func TestSearch_FilterLeftSideByKeys_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: true,
 		IncludePositiveDigests:           true,
@@ -1511,7 +1511,7 @@ func TestSearch_FilterLeftSideByKeys_WithMaterializedViews(t *testing.T) {
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 10) // Otherwise there's no commit for the materialized views
+	s := New(db, 10, nil) // Otherwise there's no commit for the materialized views
 	require.NoError(t, s.StartMaterializedViews(ctx, []string{dks.CornersCorpus, dks.RoundCorpus}, time.Minute))
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: true,
@@ -1753,7 +1753,7 @@ func TestSearch_FilterLeftSideByKeysAndOptions_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: true,
 		IncludePositiveDigests:           true,
@@ -1880,7 +1880,7 @@ func TestSearch_FilteredAcrossAllHistory_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: false,
 		IncludePositiveDigests:           false,
@@ -1904,7 +1904,7 @@ func TestSearch_FilteredAcrossAllHistory_WithMaterializedView(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 10) // Otherwise there's no commit for the materialized views
+	s := New(db, 10, nil) // Otherwise there's no commit for the materialized views
 	require.NoError(t, s.StartMaterializedViews(ctx, []string{dks.CornersCorpus, dks.RoundCorpus}, time.Minute))
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: false,
@@ -2306,7 +2306,7 @@ func TestSearch_AcrossAllHistory_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: false,
 		IncludePositiveDigests:           false,
@@ -2333,7 +2333,7 @@ func TestSearch_AcrossAllHistory_WithMaterializedViews(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 10) // Otherwise there's no commit for the materialized views
+	s := New(db, 10, nil) // Otherwise there's no commit for the materialized views
 	require.NoError(t, s.StartMaterializedViews(ctx, []string{dks.CornersCorpus, dks.RoundCorpus}, time.Minute))
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: false,
@@ -2473,7 +2473,7 @@ func TestSearch_DifferentTestsDrawTheSame_SearchResultsAreSeparate(t *testing.T)
 	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, b.Build()))
 	waitForSystemTime()
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: true,
 		IncludePositiveDigests:           false,
@@ -2636,7 +2636,7 @@ func TestSearch_RespectsPublicParams_Success(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: true,
@@ -2668,7 +2668,7 @@ func TestSearch_RespectsPublicParams_WithMaterializedViews(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	s := New(db, 10) // Otherwise there's no commit for the materialized views
+	s := New(db, 10, nil) // Otherwise there's no commit for the materialized views
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 	require.NoError(t, s.StartMaterializedViews(ctx, []string{dks.CornersCorpus, dks.RoundCorpus}, time.Minute))
 	res, err := s.Search(ctx, &query.Search{
@@ -2822,7 +2822,7 @@ func TestSearch_RespectsRightSideFilter_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.Search(ctx, &query.Search{
 		OnlyIncludeDigestsProducedAtHead: true,
 		IncludePositiveDigests:           false,
@@ -3031,7 +3031,7 @@ func TestSearch_ReturnsCLData_ShowsOnlyDataNewToPrimaryBranch(t *testing.T) {
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	s.SetReviewSystemTemplates(map[string]string{
 		dks.GerritCRS:         "http://example.com/public/%s",
 		dks.GerritInternalCRS: "http://example.com/internal/%s",
@@ -3204,7 +3204,7 @@ func TestSearch_DisallowTriagingOnPrimaryBranch_DigestExcludedFromBulkTriageInfo
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartCacheProcess(ctx, time.Minute, 100))
 	res, err := s.Search(ctx, &query.Search{
 		IncludePositiveDigests:  true,
@@ -3461,7 +3461,7 @@ func TestSearch_DisallowTriagingOnCL_DigestExcludedFromBulkTriageInfos(t *testin
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	s.SetReviewSystemTemplates(map[string]string{
 		dks.GerritCRS:         "http://example.com/public/%s",
 		dks.GerritInternalCRS: "http://example.com/internal/%s",
@@ -3583,7 +3583,7 @@ func TestSearch_CLAndPatchsetWithMultipleDatapointsOnSameTrace_ReturnsAllDatapoi
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	s.SetReviewSystemTemplates(map[string]string{
 		dks.GerritCRS:         "http://example.com/public/%s",
 		dks.GerritInternalCRS: "http://example.com/internal/%s",
@@ -3935,7 +3935,7 @@ func TestSearch_ReturnsFilteredCLData_Success(t *testing.T) {
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	s.SetReviewSystemTemplates(map[string]string{
 		dks.GerritCRS:         "http://example.com/public/%s",
 		dks.GerritInternalCRS: "http://example.com/internal/%s",
@@ -4174,7 +4174,7 @@ func TestSearch_ResultHasNoReferenceDiffsNorExistingTraces_Success(t *testing.T)
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	s.SetReviewSystemTemplates(map[string]string{
 		dks.GerritCRS:         "http://example.com/public/%s",
 		dks.GerritInternalCRS: "http://example.com/internal/%s",
@@ -4304,7 +4304,7 @@ func TestGetPrimaryBranchParamset_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	ps, err := s.GetPrimaryBranchParamset(ctx)
 	require.NoError(t, err)
 	assert.Equal(t, paramtools.ReadOnlyParamSet{
@@ -4332,7 +4332,7 @@ func TestGetPrimaryBranchParamset_RespectsPublicParams_Success(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 
 	ps, err := s.GetPrimaryBranchParamset(ctx)
@@ -4351,7 +4351,7 @@ func TestGetChangelistParamset_ValidCLs_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	ps, err := s.GetChangelistParamset(ctx, dks.GerritCRS, dks.ChangelistIDThatAttemptsToFixIOS)
 	require.NoError(t, err)
 	assert.Equal(t, paramtools.ReadOnlyParamSet{
@@ -4388,7 +4388,7 @@ func TestGetChangelistParamset_InvalidCL_ReturnsError(t *testing.T) {
 	ctx := context.Background()
 	db := sqltest.NewCockroachDBForTestsWithProductionSchema(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	_, err := s.GetChangelistParamset(ctx, "does not", "exist")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "Could not find")
@@ -4413,7 +4413,7 @@ func TestGetChangelistParamset_RespectsPublicView_Success(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 	ps, err := s.GetChangelistParamset(ctx, dks.GerritCRS, dks.ChangelistIDThatAttemptsToFixIOS)
 	require.NoError(t, err)
@@ -4440,7 +4440,7 @@ func TestGetBlamesForUntriagedDigests_UntriagedDigestsAtHeadInCorpus_Success(t *
 
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
-	s := New(db, 100)
+	s := New(db, 100, nil)
 
 	blames, err := s.GetBlamesForUntriagedDigests(ctx, dks.RoundCorpus)
 	require.NoError(t, err)
@@ -4452,7 +4452,7 @@ func TestGetBlamesForUntriagedDigests_UntriagedDigestsAtHeadInCorpus_WithMateria
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
-	s := New(db, 9)
+	s := New(db, 9, nil)
 	require.NoError(t, s.StartMaterializedViews(ctx, []string{dks.CornersCorpus, dks.RoundCorpus}, time.Minute))
 
 	blames, err := s.GetBlamesForUntriagedDigests(ctx, dks.RoundCorpus)
@@ -4509,7 +4509,7 @@ func TestSearch_IncludesBlameCommit_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 
 	res, err := s.Search(ctx, &query.Search{
 		BlameGroupID: string(dks.WindowsDriverUpdateCommitID),
@@ -4538,7 +4538,7 @@ func TestSearchByBlameThenTriageAndSearchAgain_Success(t *testing.T) {
 			ctx := context.Background()
 			db := useKitchenSinkData(ctx, t)
 
-			s := New(db, 10)
+			s := New(db, 10, nil)
 
 			if withMaterializedView {
 				// Ensure the materialized view does not get updated for the duration of this test.
@@ -4683,7 +4683,7 @@ func TestSearch_IncludesBlameCommit_WithMaterializedViews(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	db := useKitchenSinkData(ctx, t)
-	s := New(db, 10)
+	s := New(db, 10, nil)
 	require.NoError(t, s.StartMaterializedViews(ctx, []string{dks.CornersCorpus, dks.RoundCorpus}, time.Minute))
 
 	res, err := s.Search(ctx, &query.Search{
@@ -4838,7 +4838,7 @@ func TestSearch_IncludesBlameCommit_MultipleNewTraces_Success(t *testing.T) {
 	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, existingData))
 	waitForSystemTime()
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 
 	// We want to assert that searching with the given blameID, we return exactly one result
 	// corresponding to the given digest. This makes sure we can take the results of
@@ -4877,7 +4877,7 @@ func TestGetBlamesForUntriagedDigests_MultipleNewTraces_Success(t *testing.T) {
 	betaGrouping := paramtools.Params{types.PrimaryKeyField: "beta", types.CorpusField: "test_corpus"}
 	gammaGrouping := paramtools.Params{types.PrimaryKeyField: "gamma", types.CorpusField: "test_corpus"}
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 
 	res, err := s.GetBlamesForUntriagedDigests(ctx, "test_corpus")
 	require.NoError(t, err)
@@ -4969,7 +4969,7 @@ func TestSearch_IncludesBlameRange_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 
 	res, err := s.Search(ctx, &query.Search{
 		BlameGroupID: "0000000106:0000000108",
@@ -5098,7 +5098,7 @@ func TestSearch_BlameRespectsPublicParams_Success(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 	res, err := s.Search(ctx, &query.Search{
 		BlameGroupID: "0000000106:0000000109",
@@ -5197,7 +5197,7 @@ func TestSearch_BlameCommitInCorpusWithNoUntriaged_ReturnsEmptyResult(t *testing
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 
 	res, err := s.Search(ctx, &query.Search{
 		BlameGroupID: string(dks.WindowsDriverUpdateCommitID),
@@ -5225,7 +5225,7 @@ func TestSearch_PrimaryBranch_NoReferenceImages_ReturnsEmptyResult(t *testing.T)
 	_, err := db.Exec(ctx, "DELETE FROM DiffMetrics")
 	require.NoError(t, err)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.Search(ctx, &query.Search{
 		IncludePositiveDigests:     true,
 		MustIncludeReferenceFilter: true,
@@ -5265,7 +5265,7 @@ func TestSearch_SecondaryBranch_NoReferenceImages_ReturnsEmptyResult(t *testing.
 		ChangelistURL: "http://example.com/public/CL_fix_ios",
 	})
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	s.SetReviewSystemTemplates(map[string]string{
 		dks.GerritCRS:         "http://example.com/public/%s",
 		dks.GerritInternalCRS: "http://example.com/internal/%s",
@@ -5306,7 +5306,7 @@ func TestGetBlamesForUntriagedDigests_RespectsPublicParams_Success(t *testing.T)
 	})
 	require.NoError(t, err)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 
 	blames, err := s.GetBlamesForUntriagedDigests(ctx, dks.RoundCorpus)
@@ -5345,7 +5345,7 @@ func TestGetBlamesForUntriagedDigests_NoUntriagedDigestsAtHead_Success(t *testin
 
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
-	s := New(db, 100)
+	s := New(db, 100, nil)
 
 	// None of the traces for the corner tests have unignored, untriaged digests at head.
 	// As a result, the blame returned should be empty.
@@ -6217,7 +6217,7 @@ func TestGetCluster_ShowAllDataFromPrimaryBranch_Success(t *testing.T) {
 
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.GetCluster(ctx, ClusterOptions{
 		Grouping: paramtools.Params{
 			types.CorpusField:     dks.CornersCorpus,
@@ -6297,7 +6297,7 @@ func TestGetCluster_RespectsTriageStatuses_Success(t *testing.T) {
 
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.GetCluster(ctx, ClusterOptions{
 		Grouping: paramtools.Params{
 			types.CorpusField:     dks.CornersCorpus,
@@ -6318,7 +6318,7 @@ func TestGetCluster_RespectsFilters_Success(t *testing.T) {
 
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	res, err := s.GetCluster(ctx, ClusterOptions{
 		Grouping: paramtools.Params{
 			types.CorpusField:     dks.RoundCorpus,
@@ -6383,7 +6383,7 @@ func TestGetCluster_RespectsPublicParams_Success(t *testing.T) {
 		},
 	})
 	require.NoError(t, err)
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 	res, err := s.GetCluster(ctx, ClusterOptions{
 		Grouping: paramtools.Params{
@@ -6523,7 +6523,7 @@ func TestGetCommitsInWindow_GitCommits_ReturnsAllCommitsWithData(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	commits, err := s.GetCommitsInWindow(ctx)
 	require.NoError(t, err)
 	assert.Equal(t, makeKitchenSinkCommits(), commits)
@@ -6537,7 +6537,7 @@ func TestGetCommitsInWindow_RespectsWindow_ReturnsMostRecentCommitsWithData(t *t
 	allCommits := makeKitchenSinkCommits()
 	mostRecentCommits := allCommits[len(allCommits)-3:]
 
-	s := New(db, 3)
+	s := New(db, 3, nil)
 	commits, err := s.GetCommitsInWindow(ctx)
 	require.NoError(t, err)
 	assert.Equal(t, mostRecentCommits, commits)
@@ -6548,7 +6548,7 @@ func TestGetDigestsForGrouping_ValidGrouping_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	digests, err := s.GetDigestsForGrouping(ctx, paramtools.Params{
 		types.PrimaryKeyField: dks.CircleTest,
 		types.CorpusField:     dks.RoundCorpus,
@@ -6566,7 +6566,7 @@ func TestGetDigestsForGrouping_InvalidGrouping_EmptyResponseReturned(t *testing.
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	digests, err := s.GetDigestsForGrouping(ctx, paramtools.Params{
 		types.PrimaryKeyField: "not a real test",
 		types.CorpusField:     "not a real corpus",
@@ -6584,7 +6584,7 @@ func TestGetDigestDetails_ValidDigestAndGroupingOnPrimary_Success(t *testing.T)
 		types.CorpusField:     dks.RoundCorpus,
 	}
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	details, err := s.GetDigestDetails(ctx, inputGrouping, dks.DigestC02Pos, "", "")
 	require.NoError(t, err)
 	assert.Equal(t, frontend.DigestDetails{
@@ -6702,7 +6702,7 @@ func TestGetDigestDetails_ValidDigestAndGroupingOnPrimary_PublicView_SomeTracesM
 	})
 	require.NoError(t, err)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 	details, err := s.GetDigestDetails(ctx, inputGrouping, dks.DigestC02Pos, "", "")
 	require.NoError(t, err)
@@ -6804,7 +6804,7 @@ func TestGetDigestDetails_ValidDigestAndGroupingOnPrimary_PublicView_NoTracesMat
 	})
 	require.NoError(t, err)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 	_, err = s.GetDigestDetails(ctx, inputGrouping, dks.DigestC02Pos, "", "")
 	require.Error(t, err)
@@ -6821,7 +6821,7 @@ func TestGetDigestDetails_InvalidDigestAndGroupingOnPrimary_ReturnsPartialResult
 		types.CorpusField:     dks.RoundCorpus,
 	}
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	// This digest is not seen in that grouping on the primary branch.
 	details, err := s.GetDigestDetails(ctx, inputGrouping, dks.DigestE03Unt_CL, "", "")
 	require.NoError(t, err)
@@ -6870,7 +6870,7 @@ func TestGetDigestDetails_ValidDigestAndGroupingOnCL_Success(t *testing.T) {
 		Subject:       "Fix iOS",
 		ChangelistURL: "http://example.com/public/CL_fix_ios",
 	})
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	s.SetReviewSystemTemplates(map[string]string{
 		dks.GerritCRS:         "http://example.com/public/%s",
 		dks.GerritInternalCRS: "http://example.com/internal/%s",
@@ -6974,7 +6974,7 @@ func TestGetDigestDetails_ValidDigestAndGroupingOnCL_PublicView_SomeTracesMatchP
 	})
 	require.NoError(t, err)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 	s.SetReviewSystemTemplates(map[string]string{
 		dks.GerritCRS:         "http://example.com/public/%s",
@@ -7063,7 +7063,7 @@ func TestGetDigestDetails_ValidDigestAndGroupingOnCL_PublicView_NoTracesMatchPub
 	})
 	require.NoError(t, err)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 	s.SetReviewSystemTemplates(map[string]string{
 		dks.GerritCRS:         "http://example.com/public/%s",
@@ -7094,7 +7094,7 @@ func TestGetDigestDetails_ValidDigestAndGroupingOnCL_OnePatchsetWithMultipleData
 		Subject:       "multiple datapoints",
 		ChangelistURL: "http://example.com/public/CLmultipledatapoints",
 	})
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	s.SetReviewSystemTemplates(map[string]string{
 		dks.GerritCRS:         "http://example.com/public/%s",
 		dks.GerritInternalCRS: "http://example.com/internal/%s",
@@ -7189,7 +7189,7 @@ func TestGetDigestDetails_InvalidDigestAndGroupingOnCL_ReturnsError(t *testing.T
 		types.CorpusField:     dks.RoundCorpus,
 	}
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	s.SetReviewSystemTemplates(map[string]string{
 		dks.GerritCRS:         "http://example.com/public/%s",
 		dks.GerritInternalCRS: "http://example.com/internal/%s",
@@ -7210,7 +7210,7 @@ func TestGetDigestsDiff_TwoKnownDigests_Success(t *testing.T) {
 		types.CorpusField:     dks.RoundCorpus,
 	}
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	rv, err := s.GetDigestsDiff(ctx, inputGrouping, dks.DigestC01Pos, dks.DigestC03Unt, "", "")
 	require.NoError(t, err)
 	assert.Equal(t, frontend.DigestComparison{
@@ -7256,7 +7256,7 @@ func TestGetDigestsDiff_UnknownDigests_ReturnsError(t *testing.T) {
 	}
 	const notARealDigest = `ffffffffffffffffffffffffffffffff`
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	_, err := s.GetDigestsDiff(ctx, inputGrouping, dks.DigestC01Pos, notARealDigest, "", "")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "missing")
@@ -7280,7 +7280,7 @@ func TestGetDigestsDiff_TwoKnownDigestsOnACL_Success(t *testing.T) {
 		types.CorpusField:     dks.RoundCorpus,
 	}
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	rv, err := s.GetDigestsDiff(ctx, inputGrouping, dks.DigestC01Pos, dks.DigestC06Pos_CL, dks.ChangelistIDThatAttemptsToFixIOS, dks.GerritCRS)
 	require.NoError(t, err)
 	assert.Equal(t, frontend.DigestComparison{
@@ -7324,7 +7324,7 @@ func TestGetDigestsDiff_UntriagedDigestOnKnownCL_ReturnsDiffAndParamset(t *testi
 		types.CorpusField:     dks.RoundCorpus,
 	}
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	rv, err := s.GetDigestsDiff(ctx, inputGrouping, dks.DigestC01Pos, dks.DigestC07Unt_CL, dks.ChangelistIDThatAttemptsToFixIOS, dks.GerritCRS)
 	require.NoError(t, err)
 	assert.Equal(t, frontend.DigestComparison{
@@ -7368,7 +7368,7 @@ func TestGetDigestsDiff_TwoKnownDigestsOnACL_OnePatchsetWithMultipleDatapointsOn
 		types.CorpusField:     dks.CornersCorpus,
 	}
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	// In this CL a tryjob was executed multiple times at the last patchset, generating multiple
 	// datapoints for the same trace at the last patchset. DigestC01Pos was drawn on the last two
 	// tryjob runs.
@@ -7418,7 +7418,7 @@ func TestGetDigestsDiff_DigestsExistOnPrimaryInvalidCL_ReturnDiffAndPrimaryParam
 		types.CorpusField:     dks.RoundCorpus,
 	}
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	rv, err := s.GetDigestsDiff(ctx, inputGrouping, dks.DigestC01Pos, dks.DigestC03Unt, "not a real CL", dks.GerritCRS)
 	require.NoError(t, err)
 	assert.Equal(t, frontend.DigestComparison{
@@ -7463,7 +7463,7 @@ func TestGetDigestsDiff_UntriagedDigestOnUnknownCL_ReturnsDiffButNoRightParamset
 		types.CorpusField:     dks.RoundCorpus,
 	}
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	rv, err := s.GetDigestsDiff(ctx, inputGrouping, dks.DigestC01Pos, dks.DigestC06Pos_CL, "not a real CL", dks.GerritCRS)
 	require.NoError(t, err)
 	assert.Equal(t, frontend.DigestComparison{
@@ -7505,7 +7505,7 @@ func TestCountDigestsByTest_AllAtHead_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	resp, err := s.CountDigestsByTest(ctx, frontend.ListTestsQuery{
 		Corpus:      dks.CornersCorpus,
 		IgnoreState: types.ExcludeIgnoredTraces,
@@ -7556,12 +7556,55 @@ func TestCountDigestsByTest_AllAtHead_Success(t *testing.T) {
 	}, resp)
 }
 
+func TestCountDigestsByTest_CustomLabels_CountedAsPassingOrFailing(t *testing.T) {
+
+	ctx := context.Background()
+	db := useKitchenSinkData(ctx, t)
+
+	labels, err := expectations.NewLabels([]expectations.LabelDefinition{
+		{Label: "flaky", Code: "f", Passing: true},
+		{Label: "bug_filed", Code: "b"},
+	})
+	require.NoError(t, err)
+	_, groupingID := sql.SerializeMap(paramtools.Params{
+		types.CorpusField:     dks.RoundCorpus,
+		types.PrimaryKeyField: dks.CircleTest,
+	})
+	for digest, code := range map[types.Digest]string{dks.DigestC03Unt: "f", dks.DigestC04Unt: "b"} {
+		digestBytes, err := sql.DigestToBytes(digest)
+		require.NoError(t, err)
+		_, err = db.Exec(ctx, `UPDATE Expectations SET label = $1 WHERE grouping_id = $2 AND digest = $3`,
+			code, groupingID, digestBytes)
+		require.NoError(t, err)
+	}
+
+	s := New(db, 100, labels)
+	resp, err := s.CountDigestsByTest(ctx, frontend.ListTestsQuery{
+		Corpus: dks.RoundCorpus,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, frontend.ListTestsResponse{
+		Tests: []frontend.TestSummary{
+			{
+				Grouping: paramtools.Params{
+					types.CorpusField:     dks.RoundCorpus,
+					types.PrimaryKeyField: dks.CircleTest,
+				},
+				PositiveDigests:  3,
+				NegativeDigests:  1,
+				UntriagedDigests: 1,
+				TotalDigests:     5,
+			},
+		},
+	}, resp)
+}
+
 func TestCountDigestsByTest_WithIgnored_Success(t *testing.T) {
 
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	resp, err := s.CountDigestsByTest(ctx, frontend.ListTestsQuery{
 		Corpus:      dks.CornersCorpus,
 		IgnoreState: types.IncludeIgnoredTraces,
@@ -7620,7 +7663,7 @@ func TestCountDigestsByTest_FilteredByParams_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	resp, err := s.CountDigestsByTest(ctx, frontend.ListTestsQuery{
 		Corpus:      dks.CornersCorpus,
 		IgnoreState: types.ExcludeIgnoredTraces,
@@ -7660,7 +7703,7 @@ func TestCountDigestsByTest_FilteredByParamset_NotImplemented(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	_, err := s.CountDigestsByTest(ctx, frontend.ListTestsQuery{
 		Corpus:      dks.CornersCorpus,
 		IgnoreState: types.ExcludeIgnoredTraces,
@@ -7679,7 +7722,7 @@ func TestComputeGUIStatus_Success(t *testing.T) {
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 
 	res, err := s.ComputeGUIStatus(ctx)
 	require.NoError(t, err)
@@ -7716,7 +7759,7 @@ func TestComputeGUIStatus_GitCommitIDsDoNotMatch_Success(t *testing.T) {
 	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, data))
 	waitForSystemTime()
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 
 	res, err := s.ComputeGUIStatus(ctx)
 	require.NoError(t, err)
@@ -7752,7 +7795,7 @@ func TestComputeGUIStatus_RespectsPublicParams_Success(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	require.NoError(t, s.StartApplyingPublicParams(ctx, matcher, time.Minute))
 
 	res, err := s.ComputeGUIStatus(ctx)
@@ -7826,7 +7869,7 @@ func TestGetCommits_StandardGitIDs_Success(t *testing.T) {
 	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, existingData))
 	waitForSystemTime()
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	ctx, err := s.addCommitsData(ctx)
 	require.NoError(t, err)
 	fec, err := s.getCommits(ctx)
@@ -7879,7 +7922,7 @@ func TestGetCommits_NonStandardGitIDs_Success(t *testing.T) {
 	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, existingData))
 	waitForSystemTime()
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	ctx, err := s.addCommitsData(ctx)
 	require.NoError(t, err)
 	fec, err := s.getCommits(ctx)
@@ -7901,7 +7944,7 @@ func TestGetDigestsForGrouping_NoRightTraceKeys_ReturnsDigestsFromTracesOnPrimar
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	ctx, err := s.addCommitsData(ctx)
 	require.NoError(t, err)
 
@@ -7931,7 +7974,7 @@ func TestGetDigestsForGrouping_WithRightTraceKeys_ReturnsDigestsFromThoseTracesO
 	ctx := context.Background()
 	db := useKitchenSinkData(ctx, t)
 
-	s := New(db, 100)
+	s := New(db, 100, nil)
 	ctx, err := s.addCommitsData(ctx)
 	require.NoError(t, err)
 
@@ -311,6 +311,8 @@ func (b *TablesBuilder) ComputeDiffMetricsFromImages(imgDir string, nowStr strin
 					MaxRGBADiffs:      dm.MaxRGBADiffs,
 					MaxChannelDiff:    max(dm.MaxRGBADiffs),
 					CombinedMetric:    dm.CombinedMetric,
+					SSIM:              &dm.SSIM,
+					DeltaE:            &dm.DeltaE,
 					DimensionsDiffer:  dm.DimDiffer,
 					Timestamp:         now,
 				})
@@ -323,6 +325,8 @@ func (b *TablesBuilder) ComputeDiffMetricsFromImages(imgDir string, nowStr strin
 					MaxRGBADiffs:      dm.MaxRGBADiffs,
 					MaxChannelDiff:    max(dm.MaxRGBADiffs),
 					CombinedMetric:    dm.CombinedMetric,
+					SSIM:              &dm.SSIM,
+					DeltaE:            &dm.DeltaE,
 					DimensionsDiffer:  dm.DimDiffer,
 					Timestamp:         now,
 				})
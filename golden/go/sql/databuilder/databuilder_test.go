@@ -343,6 +343,8 @@ func TestBuild_CalledWithValidInput_ProducesCorrectData(t *testing.T) {
 		MaxRGBADiffs:      [4]int{250, 244, 197, 51},
 		MaxChannelDiff:    250,
 		CombinedMetric:    2.9445405,
+		SSIM:              float32Ptr(0.68344045),
+		DeltaE:            float32Ptr(10.295635),
 		DimensionsDiffer:  false,
 		Timestamp:         ts,
 	}, {
@@ -353,6 +355,8 @@ func TestBuild_CalledWithValidInput_ProducesCorrectData(t *testing.T) {
 		MaxRGBADiffs:      [4]int{250, 244, 197, 51},
 		MaxChannelDiff:    250,
 		CombinedMetric:    2.9445405,
+		SSIM:              float32Ptr(0.68344045),
+		DeltaE:            float32Ptr(10.295635),
 		DimensionsDiffer:  false,
 		Timestamp:         ts,
 	}, {
@@ -363,6 +367,8 @@ func TestBuild_CalledWithValidInput_ProducesCorrectData(t *testing.T) {
 		MaxRGBADiffs:      [4]int{106, 21, 21, 0},
 		MaxChannelDiff:    106,
 		CombinedMetric:    3.4844475,
+		SSIM:              float32Ptr(0.86827904),
+		DeltaE:            float32Ptr(11.153552),
 		DimensionsDiffer:  false,
 		Timestamp:         ts,
 	}, {
@@ -373,6 +379,8 @@ func TestBuild_CalledWithValidInput_ProducesCorrectData(t *testing.T) {
 		MaxRGBADiffs:      [4]int{106, 21, 21, 0},
 		MaxChannelDiff:    106,
 		CombinedMetric:    3.4844475,
+		SSIM:              float32Ptr(0.86827904),
+		DeltaE:            float32Ptr(11.153552),
 		DimensionsDiffer:  false,
 		Timestamp:         ts,
 	}}, tables.DiffMetrics)
@@ -812,6 +820,8 @@ func TestBuild_CalledWithChangelistData_ProducesCorrectData(t *testing.T) {
 		MaxRGBADiffs:      [4]int{250, 244, 197, 51},
 		MaxChannelDiff:    250,
 		CombinedMetric:    2.9445405,
+		SSIM:              float32Ptr(0.68344045),
+		DeltaE:            float32Ptr(10.295635),
 		DimensionsDiffer:  false,
 		Timestamp:         ts,
 	}, {
@@ -822,6 +832,8 @@ func TestBuild_CalledWithChangelistData_ProducesCorrectData(t *testing.T) {
 		MaxRGBADiffs:      [4]int{250, 244, 197, 51},
 		MaxChannelDiff:    250,
 		CombinedMetric:    2.9445405,
+		SSIM:              float32Ptr(0.68344045),
+		DeltaE:            float32Ptr(10.295635),
 		DimensionsDiffer:  false,
 		Timestamp:         ts,
 	}, {
@@ -832,6 +844,8 @@ func TestBuild_CalledWithChangelistData_ProducesCorrectData(t *testing.T) {
 		MaxRGBADiffs:      [4]int{106, 21, 21, 0},
 		MaxChannelDiff:    106,
 		CombinedMetric:    3.4844475,
+		SSIM:              float32Ptr(0.86827904),
+		DeltaE:            float32Ptr(11.153552),
 		DimensionsDiffer:  false,
 		Timestamp:         ts,
 	}, {
@@ -842,6 +856,8 @@ func TestBuild_CalledWithChangelistData_ProducesCorrectData(t *testing.T) {
 		MaxRGBADiffs:      [4]int{106, 21, 21, 0},
 		MaxChannelDiff:    106,
 		CombinedMetric:    3.4844475,
+		SSIM:              float32Ptr(0.86827904),
+		DeltaE:            float32Ptr(11.153552),
 		DimensionsDiffer:  false,
 		Timestamp:         ts,
 	}, { // The following 2 were calculated on the new test introduced by this CL
@@ -852,6 +868,8 @@ func TestBuild_CalledWithChangelistData_ProducesCorrectData(t *testing.T) {
 		MaxRGBADiffs:      [4]int{250, 244, 197, 255},
 		MaxChannelDiff:    255,
 		CombinedMetric:    9.653383,
+		SSIM:              float32Ptr(0.03890474),
+		DeltaE:            float32Ptr(28.084368),
 		DimensionsDiffer:  false,
 		Timestamp:         ts,
 	}, {
@@ -862,6 +880,8 @@ func TestBuild_CalledWithChangelistData_ProducesCorrectData(t *testing.T) {
 		MaxRGBADiffs:      [4]int{250, 244, 197, 255},
 		MaxChannelDiff:    255,
 		CombinedMetric:    9.653383,
+		SSIM:              float32Ptr(0.03890474),
+		DeltaE:            float32Ptr(28.084368),
 		DimensionsDiffer:  false,
 		Timestamp:         ts,
 	}}, tables.DiffMetrics)
@@ -1691,3 +1711,8 @@ func gitHash(cID schema.CommitID) string {
 	h := sha1.Sum([]byte(cID))
 	return hex.EncodeToString(h[:])
 }
+
+// float32Ptr returns a pointer to the provided value, for the nullable DiffMetrics columns.
+func float32Ptr(f float32) *float32 {
+	return &f
+}
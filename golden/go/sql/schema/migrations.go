@@ -0,0 +1,11 @@
+package schema
+
+// Migrations brings tables that were created by an older version of Schema up to date. Schema
+// only creates missing tables, so any column or index added to an existing table must also be
+// added here. Every statement must be idempotent, because Migrations is applied after Schema
+// every time a database is initialized.
+const Migrations = `ALTER TABLE DiffMetrics ADD COLUMN IF NOT EXISTS ssim FLOAT4;
+ALTER TABLE DiffMetrics ADD COLUMN IF NOT EXISTS delta_e FLOAT4;
+CREATE INDEX IF NOT EXISTS perceptual_backfill_idx ON DiffMetrics (left_digest, right_digest)
+  WHERE ssim IS NULL OR delta_e IS NULL;
+`
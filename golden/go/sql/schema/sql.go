@@ -25,9 +25,12 @@ CREATE TABLE IF NOT EXISTS DiffMetrics (
   max_rgba_diffs INT2[] NOT NULL,
   max_channel_diff INT2 NOT NULL,
   combined_metric FLOAT4 NOT NULL,
+  ssim FLOAT4,
+  delta_e FLOAT4,
   dimensions_differ BOOL NOT NULL,
   ts TIMESTAMP WITH TIME ZONE NOT NULL,
-  PRIMARY KEY (left_digest, right_digest)
+  PRIMARY KEY (left_digest, right_digest),
+  INDEX perceptual_backfill_idx (left_digest, right_digest) WHERE ssim IS NULL OR delta_e IS NULL
 );
 CREATE TABLE IF NOT EXISTS ExpectationDeltas (
   expectation_record_id UUID,
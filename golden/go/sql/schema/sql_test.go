@@ -20,3 +20,18 @@ func TestSchema_LoadIntoCockroachDB_Success(t *testing.T) {
 	_, err := db.Exec(ctx, schema.Schema)
 	require.NoError(t, err)
 }
+
+// This test makes sure the migrations can be applied to both a freshly created database and one
+// that was already migrated.
+func TestMigrations_AppliedTwiceAfterSchema_Success(t *testing.T) {
+
+	ctx := context.Background()
+	db := sqltest.NewCockroachDBForTests(ctx, t)
+
+	_, err := db.Exec(ctx, schema.Schema)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, schema.Migrations)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, schema.Migrations)
+	require.NoError(t, err)
+}
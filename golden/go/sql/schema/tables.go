@@ -75,6 +75,9 @@ const (
 	NBTrue  NullableBool = 2
 )
 
+// ExpectationLabel is the single character code used to store an expectations.Label. Besides
+// the built-in labels below, instances may configure custom labels with their own codes (see
+// expectations.LabelDefinition).
 type ExpectationLabel string
 
 const (
@@ -83,28 +86,24 @@ const (
 	LabelNegative  ExpectationLabel = "n"
 )
 
-func (e ExpectationLabel) ToExpectation() expectations.Label {
-	switch e {
-	case LabelPositive:
-		return expectations.Positive
-	case LabelNegative:
-		return expectations.Negative
-	case LabelUntriaged:
-		return expectations.Untriaged
+// ToExpectation returns the expectations.Label stored with this code. It returns an error if the
+// code is neither one of the built-in labels nor one of the given custom labels.
+func (e ExpectationLabel) ToExpectation(labels *expectations.Labels) (expectations.Label, error) {
+	def, ok := labels.LookupCode(string(e))
+	if !ok {
+		return "", skerr.Fmt("unknown label code %q; are the custom labels of the instance configured?", e)
 	}
-	return expectations.Untriaged
+	return def.Label, nil
 }
 
-func FromExpectationLabel(label expectations.Label) ExpectationLabel {
-	switch label {
-	case expectations.Positive:
-		return LabelPositive
-	case expectations.Negative:
-		return LabelNegative
-	case expectations.Untriaged:
-		return LabelUntriaged
+// FromExpectationLabel returns the code used to store the given expectations.Label. It returns an
+// error if the label is neither one of the built-in labels nor one of the given custom labels.
+func FromExpectationLabel(labels *expectations.Labels, label expectations.Label) (ExpectationLabel, error) {
+	def, ok := labels.Lookup(label)
+	if !ok {
+		return "", skerr.Fmt("label %q is not configured for this instance", label)
 	}
-	return LabelUntriaged
+	return ExpectationLabel(def.Code), nil
 }
 
 type ChangelistStatus string
@@ -489,7 +488,8 @@ type ExpectationRow struct {
 	GroupingID GroupingID `sql:"grouping_id BYTES"`
 	// Digest is the MD5 hash of the pixel data. It identifies the image that is currently triaged.
 	Digest DigestBytes `sql:"digest BYTES"`
-	// Label is the current label associated with the given digest in the given grouping.
+	// Label is the current label associated with the given digest in the given grouping. This is
+	// either one of the built-in labels or the code of a custom label configured for the instance.
 	Label ExpectationLabel `sql:"label CHAR NOT NULL"`
 	// ExpectationRecordID corresponds to most recent ExpectationRecordRow that set the given label.
 	ExpectationRecordID *uuid.UUID `sql:"expectation_record_id UUID"`
@@ -540,26 +540,36 @@ type DiffMetricRow struct {
 	// CombinedMetric is a value in [0, 10] that represents how large the diff is between two
 	// images. It is based off the MaxRGBADiffs and PixelDiffPercent.
 	CombinedMetric float32 `sql:"combined_metric FLOAT4 NOT NULL"`
+	// SSIM is the structural similarity of the two images, in [-1, 1] where 1 means identical.
+	// Unlike the other metrics it is barely affected by antialiasing differences. It is NULL for
+	// rows computed before this column was added until they have been backfilled.
+	SSIM *float32 `sql:"ssim FLOAT4"`
+	// DeltaE is the mean CIEDE2000 color difference of the two images, in [0, 100] where 0 means
+	// identical. Like SSIM, it is NULL until backfilled.
+	DeltaE *float32 `sql:"delta_e FLOAT4"`
 	// DimensionsDiffer is true if the dimensions between the two images are different.
 	DimensionsDiffer bool `sql:"dimensions_differ BOOL NOT NULL"`
 	// Timestamp represents when this metric was computed or verified (i.e. still in use). This
 	// allows for us to periodically clean up this large table.
 	Timestamp  time.Time `sql:"ts TIMESTAMP WITH TIME ZONE NOT NULL"`
 	primaryKey struct{}  `sql:"PRIMARY KEY (left_digest, right_digest)"`
+	// This partial index lets the backfill find the rows without perceptual metrics quickly.
+	perceptualBackfillIndex struct{} `sql:"INDEX perceptual_backfill_idx (left_digest, right_digest) WHERE ssim IS NULL OR delta_e IS NULL"`
 }
 
 // ToSQLRow implements the sqltest.SQLExporter interface.
 func (r DiffMetricRow) ToSQLRow() (colNames []string, colData []interface{}) {
 	return []string{"left_digest", "right_digest", "num_pixels_diff", "percent_pixels_diff", "max_rgba_diffs",
-			"max_channel_diff", "combined_metric", "dimensions_differ", "ts"},
+			"max_channel_diff", "combined_metric", "ssim", "delta_e", "dimensions_differ", "ts"},
 		[]interface{}{r.LeftDigest, r.RightDigest, r.NumPixelsDiff, r.PercentPixelsDiff, r.MaxRGBADiffs,
-			r.MaxChannelDiff, r.CombinedMetric, r.DimensionsDiffer, r.Timestamp}
+			r.MaxChannelDiff, r.CombinedMetric, r.SSIM, r.DeltaE, r.DimensionsDiffer, r.Timestamp}
 }
 
 // ScanFrom implements the sqltest.SQLScanner interface.
 func (r *DiffMetricRow) ScanFrom(scan func(...interface{}) error) error {
 	err := scan(&r.LeftDigest, &r.RightDigest, &r.NumPixelsDiff, &r.PercentPixelsDiff,
-		&r.MaxRGBADiffs, &r.MaxChannelDiff, &r.CombinedMetric, &r.DimensionsDiffer, &r.Timestamp)
+		&r.MaxRGBADiffs, &r.MaxChannelDiff, &r.CombinedMetric, &r.SSIM, &r.DeltaE, &r.DimensionsDiffer,
+		&r.Timestamp)
 	if err != nil {
 		return skerr.Wrap(err)
 	}
@@ -0,0 +1,40 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.skia.org/infra/golden/go/expectations"
+	"go.skia.org/infra/golden/go/sql/schema"
+)
+
+func TestToExpectation_BuiltinAndCustomCodes_Success(t *testing.T) {
+	labels, err := expectations.NewLabels([]expectations.LabelDefinition{{Label: "flaky", Code: "f"}})
+	require.NoError(t, err)
+
+	label, err := schema.LabelPositive.ToExpectation(labels)
+	require.NoError(t, err)
+	assert.Equal(t, expectations.Positive, label)
+
+	label, err = schema.ExpectationLabel("f").ToExpectation(labels)
+	require.NoError(t, err)
+	assert.Equal(t, expectations.Label("flaky"), label)
+
+	code, err := schema.FromExpectationLabel(labels, "flaky")
+	require.NoError(t, err)
+	assert.Equal(t, schema.ExpectationLabel("f"), code)
+}
+
+func TestToExpectation_UnknownCode_ReturnsError(t *testing.T) {
+	_, err := schema.ExpectationLabel("f").ToExpectation(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown label code "f"`)
+}
+
+func TestFromExpectationLabel_UnknownLabel_ReturnsError(t *testing.T) {
+	_, err := schema.FromExpectationLabel(nil, "flaky")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `label "flaky" is not configured for this instance`)
+}
@@ -68,6 +68,9 @@ func addTypes(generator *go2ts.Go2TS) {
 	// Response for the /json/v1/groupings RPC endpoint.
 	generator.Add(frontend.GroupingsResponse{})
 
+	// Response for the /json/v1/labels RPC endpoint.
+	generator.Add(frontend.LabelsResponse{})
+
 	// Response for the /json/v1/byblame RPC endpoint.
 	generator.Add(frontend.ByBlameResponse{})
 
@@ -45,6 +45,10 @@ const (
 	KnownHashesRouteV1 = "/json/v1/hashes"
 
 	GroupingsRouteV1 = "/json/v1/groupings"
+
+	// LabelsRouteV1 serves the labels that can be used when triaging, including any custom labels
+	// configured for the instance.
+	LabelsRouteV1 = "/json/v1/labels"
 )
 
 // Changelist encapsulates how the frontend expects to get information
@@ -425,8 +429,8 @@ type SRDiffDigest struct {
 	// MaxRGBADiffs contains the maximum difference of each channel.
 	MaxRGBADiffs [4]int `json:"maxRGBADiffs"`
 
-	// One of CombinedMetric, PixelDiffPercent, or NumDiffPixels depending on the requested
-	// metric name (see query.go). Used internally in search.
+	// One of CombinedMetric, PixelDiffPercent, NumDiffPixels, 1 - SSIM, or DeltaE depending on
+	// the requested metric name (see query.go). Used internally in search.
 	QueryMetric float32 `json:"-"`
 
 	// DimDiffer is true if the dimensions between the two images are different.
@@ -630,19 +634,23 @@ type Link struct {
 	LeftIndex int `json:"source"`
 	// RightIndex is the index in the sibling Nodes slice corresponding to the "right" digest.
 	RightIndex int `json:"target"`
-	// Distance is how far apart the two digests are. This distance is currently the percentage
-	// of pixels different between the two images.
+	// Distance is how far apart the two digests are. By default this is the percentage of pixels
+	// different between the two images, but any of the search metrics can be requested.
 	Distance float32 `json:"value"`
 }
 
 // BaselineV2Response captures the data necessary to verify test results on the
-// commit queue. A baseline is essentially just the positive and negative expectations
-// for a branch.
+// commit queue. A baseline is essentially just the triaged expectations for a branch.
 type BaselineV2Response struct {
 	// Expectations captures the "baseline expectations", that is, the expectations with only the
-	// positive and negative digests (i.e. no untriaged digest) of the current commit.
+	// triaged digests (i.e. no untriaged digest) of the current commit.
 	Expectations expectations.Baseline `json:"primary,omitempty"`
 
+	// CustomLabels are the labels configured for this instance in addition to positive and
+	// negative, which may appear in Expectations. Clients use them to decide whether a digest
+	// counts as passing or failing.
+	CustomLabels []expectations.LabelDefinition `json:"custom_labels,omitempty"`
+
 	// ChangelistID indicates the Gerrit or GitHub issue id of this baseline.
 	// "" indicates the master branch.
 	ChangelistID string `json:"cl_id,omitempty"`
@@ -689,6 +697,12 @@ type GroupingsResponse struct {
 	GroupingParamKeysByCorpus map[string][]string `json:"grouping_param_keys_by_corpus"`
 }
 
+// LabelsResponse is the response for the /json/v1/labels RPC.
+type LabelsResponse struct {
+	// Labels are the built-in labels followed by the custom labels configured for the instance.
+	Labels []expectations.LabelDefinition `json:"labels"`
+}
+
 // DiffRequest is the request for the /json/v2/diff RPC.
 type DiffRequest struct {
 	Grouping         paramtools.Params `json:"grouping"`
@@ -102,6 +102,9 @@ type HandlersConfig struct {
 	Search2API                search.API
 	WindowSize                int
 	GroupingParamKeysByCorpus map[string][]string
+	// Labels are the labels the instance supports. A nil Labels supports only the built-in
+	// labels.
+	Labels *expectations.Labels
 }
 
 // Handlers represents all the handlers (e.g. JSON endpoints) of Gold.
@@ -436,7 +439,7 @@ func (wh *Handlers) SearchHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	q, ok := parseSearchQuery(w, r)
+	q, ok := parseSearchQuery(w, r, wh.Labels)
 	if !ok {
 		return
 	}
@@ -454,9 +457,9 @@ func (wh *Handlers) SearchHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // parseSearchQuery extracts the search query from request.
-func parseSearchQuery(w http.ResponseWriter, r *http.Request) (*search_query.Search, bool) {
+func parseSearchQuery(w http.ResponseWriter, r *http.Request, labels *expectations.Labels) (*search_query.Search, bool) {
 	q := search_query.Search{Limit: 50}
-	if err := search_query.ParseSearch(r, &q); err != nil {
+	if err := search_query.ParseSearch(r, &q, labels); err != nil {
 		httputils.ReportError(w, err, "Search for digests failed.", http.StatusInternalServerError)
 		return nil, false
 	}
@@ -918,10 +921,10 @@ func (wh *Handlers) convertToDeltas(ctx context.Context, req frontend.TriageRequ
 				// server side than make the JS check for empty string and mutate the POST body.
 				continue
 			}
-			if !expectations.ValidLabel(label) {
-				return nil, skerr.Fmt("invalid label %q in triage request", label)
+			labelAfter, err := schema.FromExpectationLabel(wh.Labels, label)
+			if err != nil {
+				return nil, skerr.Wrapf(err, "invalid label %q in triage request", label)
 			}
-			labelAfter := schema.FromExpectationLabel(label)
 			grouping, err := wh.getGroupingForTest(ctx, string(test))
 			if err != nil {
 				return nil, skerr.Wrap(err)
@@ -1052,7 +1055,7 @@ func (wh *Handlers) triage3(ctx context.Context, userID string, req frontend.Tri
 		userID = req.ImageMatchingAlgorithm
 	}
 
-	allDeltas, err := convertTriageDeltasToExpectationDeltaRows(req.Deltas)
+	allDeltas, err := convertTriageDeltasToExpectationDeltaRows(req.Deltas, wh.Labels)
 	if err != nil {
 		return frontend.TriageResponse{}, skerr.Wrapf(err, "converting TriageDeltas to ExpectationDeltaRows")
 	}
@@ -1100,13 +1103,21 @@ func (wh *Handlers) triage3(ctx context.Context, userID string, req frontend.Tri
 			if err != nil {
 				return frontend.TriageResponse{}, skerr.Wrap(err)
 			}
+			expectedLabelBefore, err := tce.ExpectedLabelBefore.ToExpectation(wh.Labels)
+			if err != nil {
+				return frontend.TriageResponse{}, skerr.Wrap(err)
+			}
+			actualLabelBefore, err := tce.ActualLabelBefore.ToExpectation(wh.Labels)
+			if err != nil {
+				return frontend.TriageResponse{}, skerr.Wrap(err)
+			}
 			return frontend.TriageResponse{
 				Status: frontend.TriageResponseStatusConflict,
 				Conflict: frontend.TriageConflict{
 					Grouping:            grouping,
 					Digest:              types.Digest(hex.EncodeToString(tce.Digest)),
-					ExpectedLabelBefore: tce.ExpectedLabelBefore.ToExpectation(),
-					ActualLabelBefore:   tce.ActualLabelBefore.ToExpectation(),
+					ExpectedLabelBefore: expectedLabelBefore,
+					ActualLabelBefore:   actualLabelBefore,
 				},
 			}, nil
 		}
@@ -1116,18 +1127,18 @@ func (wh *Handlers) triage3(ctx context.Context, userID string, req frontend.Tri
 }
 
 // convertTriageDeltasToExpectationDeltaRows converts frontend.TriageDelta structs to
-// schema.ExpectationDeltaRow structs.
-func convertTriageDeltasToExpectationDeltaRows(deltas []frontend.TriageDelta) ([]schema.ExpectationDeltaRow, error) {
+// schema.ExpectationDeltaRow structs. The labels of the deltas must be among the given labels.
+func convertTriageDeltasToExpectationDeltaRows(deltas []frontend.TriageDelta, labels *expectations.Labels) ([]schema.ExpectationDeltaRow, error) {
 	rv := make([]schema.ExpectationDeltaRow, 0, len(deltas))
 	for _, delta := range deltas {
-		if !expectations.ValidLabel(delta.LabelBefore) {
-			return nil, skerr.Fmt("invalid LabelBefore %q in triage request", delta.LabelBefore)
+		labelBefore, err := schema.FromExpectationLabel(labels, delta.LabelBefore)
+		if err != nil {
+			return nil, skerr.Wrapf(err, "invalid LabelBefore %q in triage request", delta.LabelBefore)
 		}
-		if !expectations.ValidLabel(delta.LabelAfter) {
-			return nil, skerr.Fmt("invalid LabelAfter %q in triage request", delta.LabelAfter)
+		labelAfter, err := schema.FromExpectationLabel(labels, delta.LabelAfter)
+		if err != nil {
+			return nil, skerr.Wrapf(err, "invalid LabelAfter %q in triage request", delta.LabelAfter)
 		}
-		labelBefore := schema.FromExpectationLabel(delta.LabelBefore)
-		labelAfter := schema.FromExpectationLabel(delta.LabelAfter)
 		_, groupingID := sql.SerializeMap(delta.Grouping)
 		digestBytes, err := sql.DigestToBytes(delta.Digest)
 		if err != nil {
@@ -1445,6 +1456,17 @@ func (wh *Handlers) GroupingsHandler(w http.ResponseWriter, r *http.Request) {
 	sendJSONResponse(w, res)
 }
 
+// LabelsHandler returns the labels that digests can be triaged as, including the custom labels
+// configured for this instance and whether they count as passing or failing.
+func (wh *Handlers) LabelsHandler(w http.ResponseWriter, r *http.Request) {
+	_, span := trace.StartSpan(r.Context(), "web_LabelsHandler")
+	defer span.End()
+
+	sendJSONResponse(w, frontend.LabelsResponse{
+		Labels: wh.Labels.All(),
+	})
+}
+
 // ClusterDiffRequest contains the options that the frontend provides to the clusterdiff RPC.
 type ClusterDiffRequest struct {
 	Corpus                  string
@@ -1452,13 +1474,16 @@ type ClusterDiffRequest struct {
 	IncludePositiveDigests  bool
 	IncludeNegativeDigests  bool
 	IncludeUntriagedDigests bool
+	IncludeCustomLabels     []expectations.Label
+	// Metric is one of search_query.AllMetrics, or empty to use the default distance.
+	Metric string
 	// TODO(kjlubick) the frontend does not yet support these yet.
 	ChangelistID       string
 	CodeReviewSystemID string
 	PatchsetID         string
 }
 
-func parseClusterDiffQuery(r *http.Request) (ClusterDiffRequest, error) {
+func parseClusterDiffQuery(r *http.Request, labels *expectations.Labels) (ClusterDiffRequest, error) {
 	if err := r.ParseForm(); err != nil {
 		return ClusterDiffRequest{}, skerr.Wrap(err)
 	}
@@ -1481,6 +1506,17 @@ func parseClusterDiffQuery(r *http.Request) (ClusterDiffRequest, error) {
 	rv.IncludePositiveDigests = r.FormValue("pos") == "true"
 	rv.IncludeNegativeDigests = r.FormValue("neg") == "true"
 	rv.IncludeUntriagedDigests = r.FormValue("unt") == "true"
+	customLabels, err := search_query.ParseCustomLabels(r, labels)
+	if err != nil {
+		return ClusterDiffRequest{}, skerr.Wrap(err)
+	}
+	rv.IncludeCustomLabels = customLabels
+	if metric := r.FormValue("metric"); metric != "" {
+		if !util.In(metric, search_query.AllMetrics) {
+			return ClusterDiffRequest{}, skerr.Fmt("Invalid metric %q", metric)
+		}
+		rv.Metric = metric
+	}
 
 	rv.CodeReviewSystemID = r.FormValue("crs")
 	rv.ChangelistID = r.FormValue("cl_id")
@@ -1498,7 +1534,7 @@ func (wh *Handlers) ClusterDiffHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	q, err := parseClusterDiffQuery(r)
+	q, err := parseClusterDiffQuery(r, wh.Labels)
 	if err != nil {
 		httputils.ReportError(w, err, "Invalid requrest", http.StatusBadRequest)
 		return
@@ -1520,6 +1556,8 @@ func (wh *Handlers) ClusterDiffHandler(w http.ResponseWriter, r *http.Request) {
 		IncludePositiveDigests:  q.IncludePositiveDigests,
 		IncludeNegativeDigests:  q.IncludeNegativeDigests,
 		IncludeUntriagedDigests: q.IncludeUntriagedDigests,
+		IncludeCustomLabels:     q.IncludeCustomLabels,
+		Metric:                  q.Metric,
 
 		CodeReviewSystem: q.CodeReviewSystemID,
 		ChangelistID:     q.ChangelistID,
@@ -1663,11 +1701,19 @@ ORDER BY triage_time DESC, expectation_record_id, digest
 			})
 			currentEntry = &rv[len(rv)-1]
 		}
+		labelBefore, err := delta.LabelBefore.ToExpectation(wh.Labels)
+		if err != nil {
+			return nil, 0, skerr.Wrap(err)
+		}
+		labelAfter, err := delta.LabelAfter.ToExpectation(wh.Labels)
+		if err != nil {
+			return nil, 0, skerr.Wrap(err)
+		}
 		currentEntry.Details = append(currentEntry.Details, frontend.TriageDelta{
 			Grouping:    grouping,
 			Digest:      types.Digest(hex.EncodeToString(delta.Digest)),
-			LabelBefore: delta.LabelBefore.ToExpectation(),
-			LabelAfter:  delta.LabelAfter.ToExpectation(),
+			LabelBefore: labelBefore,
+			LabelAfter:  labelAfter,
 		})
 	}
 	return rv, total, nil
@@ -2017,7 +2063,7 @@ func (wh *Handlers) fetchBaseline(ctx context.Context, crs, clID string) (fronte
 PrimaryBranchExps AS (
 	SELECT grouping_id, digest, label FROM Expectations
 	AS OF SYSTEM TIME '-0.1s'
-	WHERE label = 'n' OR label = 'p'
+	WHERE label <> 'u'
 )`
 	var args []interface{}
 	if crs == "" {
@@ -2050,7 +2096,7 @@ JoinedExps AS (
 SELECT Groupings.keys ->> 'name', encode(digest, 'hex'), label FROM JoinedExps
 JOIN Groupings ON JoinedExps.grouping_id = Groupings.grouping_id
 AS OF SYSTEM TIME '-0.1s'
-WHERE label = 'n' OR label = 'p'`
+WHERE label <> 'u'`
 		args = append(args, qCLID)
 	}
 	rows, err := wh.DB.Query(ctx, statement, args...)
@@ -2071,13 +2117,18 @@ WHERE label = 'n' OR label = 'p'`
 			byDigest = map[types.Digest]expectations.Label{}
 			baseline[testName] = byDigest
 		}
-		byDigest[digest] = label.ToExpectation()
+		l, err := label.ToExpectation(wh.Labels)
+		if err != nil {
+			return frontend.BaselineV2Response{}, skerr.Wrap(err)
+		}
+		byDigest[digest] = l
 	}
 
 	response := frontend.BaselineV2Response{
 		CodeReviewSystem: crs,
 		ChangelistID:     clID,
 		Expectations:     baseline,
+		CustomLabels:     wh.Labels.Custom(),
 	}
 	span.AddAttributes(trace.Int64Attribute("numExpectationsReturned", int64(len(response.Expectations))))
 
@@ -2769,9 +2820,13 @@ JOIN Expectations ON ValuesAtHead.grouping_id = Expectations.grouping_id
 		if err := rows.Scan(&ps, &label); err != nil {
 			return skerr.Wrap(err)
 		}
+		l, err := label.ToExpectation(wh.Labels)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
 		ignoredTraces = append(ignoredTraces, ignoredTrace{
 			Keys:  ps,
-			Label: label.ToExpectation(),
+			Label: l,
 		})
 	}
 
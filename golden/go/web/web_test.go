@@ -1212,7 +1212,7 @@ func TestStartCLCacheProcess_Success(t *testing.T) {
 
 	wh := initCaches(&Handlers{
 		HandlersConfig: HandlersConfig{
-			Search2API: search.New(db, 10),
+			Search2API: search.New(db, 10, nil),
 			DB:         db,
 		},
 	})
@@ -1882,6 +1882,81 @@ func TestTriage2_SingleDigestOnPrimaryBranch_Success(t *testing.T) {
 	})
 }
 
+func TestTriage2_CustomLabel_StoredWithCodeAndIncludedInBaseline(t *testing.T) {
+	ctx := context.Background()
+	db := sqltest.NewCockroachDBForTestsWithProductionSchema(ctx, t)
+	require.NoError(t, sqltest.BulkInsertDataTables(ctx, db, dks.Build()))
+	flaky := expectations.LabelDefinition{Label: "flaky", Code: "f", Passing: true}
+	labels, err := expectations.NewLabels([]expectations.LabelDefinition{flaky})
+	require.NoError(t, err)
+
+	wh := Handlers{
+		HandlersConfig: HandlersConfig{
+			DB:     db,
+			Labels: labels,
+		},
+		baselineCache: ttlcache.New(time.Minute, 10*time.Minute),
+	}
+
+	tr := frontend.TriageRequestV2{
+		TestDigestStatus: map[types.TestName]map[types.Digest]expectations.Label{
+			dks.CircleTest: {
+				dks.DigestC03Unt: "flaky",
+			},
+		},
+	}
+	require.NoError(t, wh.triage2(ctx, "custom_triage@example.com", tr))
+
+	exps := sqltest.GetAllRows(ctx, t, db, "Expectations", &schema.ExpectationRow{}).([]schema.ExpectationRow)
+	var found bool
+	for _, row := range exps {
+		if bytes.Equal(row.GroupingID, dks.CircleGroupingID) && bytes.Equal(row.Digest, d(dks.DigestC03Unt)) {
+			assert.Equal(t, schema.ExpectationLabel("f"), row.Label)
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	waitForSystemTime()
+	baseline, err := wh.fetchBaseline(ctx, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, expectations.Label("flaky"), baseline.Expectations[dks.CircleTest][dks.DigestC03Unt])
+	assert.Equal(t, []expectations.LabelDefinition{flaky}, baseline.CustomLabels)
+}
+
+func TestTriage2_UnknownLabel_ReturnsError(t *testing.T) {
+	wh := Handlers{}
+
+	tr := frontend.TriageRequestV2{
+		TestDigestStatus: map[types.TestName]map[types.Digest]expectations.Label{
+			dks.CircleTest: {
+				dks.DigestC03Unt: "flaky",
+			},
+		},
+	}
+	err := wh.triage2(context.Background(), "custom_triage@example.com", tr)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid label "flaky"`)
+}
+
+func TestLabelsHandler_CustomLabels_ReturnsBuiltinAndCustomLabels(t *testing.T) {
+	labels, err := expectations.NewLabels([]expectations.LabelDefinition{
+		{Label: "flaky", Code: "f", Passing: true, Description: "Acceptable, but flaky."},
+	})
+	require.NoError(t, err)
+
+	wh := Handlers{
+		HandlersConfig: HandlersConfig{
+			Labels: labels,
+		},
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, frontend.LabelsRouteV1, nil)
+	wh.LabelsHandler(w, r)
+	const expectedJSON = `{"labels":[{"label":"untriaged","code":"u","passing":false,"description":"A previously unseen digest."},{"label":"positive","code":"p","passing":true,"description":"A known good digest."},{"label":"negative","code":"n","passing":false,"description":"A known bad digest."},{"label":"flaky","code":"f","passing":true,"description":"Acceptable, but flaky."}]}`
+	assertJSONResponseWas(t, http.StatusOK, expectedJSON, w)
+}
+
 func TestTriage2_ImageMatchingAlgorithmSet_UsesAlgorithmNameAsAuthor(t *testing.T) {
 	ctx := context.Background()
 	db := sqltest.NewCockroachDBForTestsWithProductionSchema(ctx, t)
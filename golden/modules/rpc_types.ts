@@ -147,6 +147,17 @@ export interface GroupingsResponse {
 	grouping_param_keys_by_corpus: { [key: string]: string[] | null } | null;
 }
 
+export interface LabelDefinition {
+	label: Label;
+	code: string;
+	passing: boolean;
+	description?: string;
+}
+
+export interface LabelsResponse {
+	labels: LabelDefinition[] | null;
+}
+
 export interface TestRollup {
 	grouping: Params;
 	num: number;
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"path"
 	"sort"
 	"strconv"
 	"strings"
@@ -149,6 +150,21 @@ type Alert struct {
 	MinimumNum        int       `json:"minimum_num"` // How many traces need to be found interesting before an alert is fired.
 	Category          string    `json:"category"   ` // Which category this alert falls into.
 
+	// WebhookChannel is the chat channel notifications for this alert are
+	// routed to when using a webhook notifier, e.g. "#perf-alerts". If empty
+	// then config.NotifyConfig.WebhookDefaultChannel is used.
+	WebhookChannel string `json:"webhook_channel,omitempty"`
+
+	// CulpritPaths is a comma separated list of path globs, e.g.
+	// "src/core/**", for the files that are likely to cause regressions found
+	// by this alert. The commits in the range of a regression that touched
+	// them are listed in notifications.
+	//
+	// Each path element of a glob is matched as in path.Match, except for
+	// "**" which matches any number of path elements, and a glob that matches
+	// a directory matches all the files below it.
+	CulpritPaths string `json:"culprit_paths,omitempty"`
+
 	// Action to take for this alert. It could be none, report or bisect.
 	Action types.AlertAction `json:"action,omitempty"` // What action should be taken by the detected anomalies.
 
@@ -214,6 +230,19 @@ func (c *Alert) GroupedBy() []string {
 	return ret
 }
 
+// CulpritPathGlobs returns the parsed CulpritPaths value as a slice of strings.
+func (c *Alert) CulpritPathGlobs() []string {
+	ret := []string{}
+	for _, s := range strings.Split(c.CulpritPaths, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		ret = append(ret, s)
+	}
+	return ret
+}
+
 // KeyValue holds a single Params key and value, used in 'Combination'.
 type KeyValue struct {
 	Key   string
@@ -306,6 +335,11 @@ func (c *Alert) Validate() error {
 			}
 		}
 	}
+	for _, glob := range c.CulpritPathGlobs() {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("Invalid Config: Invalid culprit path %q: %s", glob, err)
+		}
+	}
 	if c.StepUpOnly {
 		c.StepUpOnly = false
 		c.DirectionAsString = UP
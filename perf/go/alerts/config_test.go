@@ -88,6 +88,23 @@ func TestValidate(t *testing.T) {
 	assert.Error(t, a.Validate())
 }
 
+func TestValidate_CulpritPaths(t *testing.T) {
+	a := NewConfig()
+	a.CulpritPaths = "src/core/**, include/*.h"
+	assert.NoError(t, a.Validate())
+
+	a.CulpritPaths = "src/[core"
+	assert.Error(t, a.Validate())
+}
+
+func TestCulpritPathGlobs(t *testing.T) {
+	a := NewConfig()
+	assert.Empty(t, a.CulpritPathGlobs())
+
+	a.CulpritPaths = " src/core/**, ,include/*.h"
+	assert.Equal(t, []string{"src/core/**", "include/*.h"}, a.CulpritPathGlobs())
+}
+
 func TestGroupedBy(t *testing.T) {
 	testCases := []struct {
 		value    string
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -31,6 +32,10 @@ func (store *MockStore) List(ctx context.Context, includeDeleted bool) ([]*Alert
 	return store.alerts, nil
 }
 
+func (store *MockStore) ReplaceAll(ctx context.Context, reqs []*SaveRequest, tx pgx.Tx) error {
+	return nil
+}
+
 func (store *MockStore) GetListCount() int {
 	store.mutex.Lock()
 	defer store.mutex.Unlock()
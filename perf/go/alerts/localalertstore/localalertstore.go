@@ -0,0 +1,217 @@
+// Package localalertstore implements alerts.Store using a localstore.DB.
+package localalertstore
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/localstore"
+)
+
+// alertsTable is the name of the table in the localstore.DB.
+const alertsTable = "alerts"
+
+// alertRow is a single stored Alert.
+type alertRow struct {
+	// Alert is the JSON serialized alerts.Alert.
+	Alert        string
+	ConfigState  int
+	LastModified int64
+	SubName      string
+	SubRevision  string
+}
+
+// table is the data stored in the alerts table.
+type table struct {
+	NextID int64
+	Rows   map[int64]alertRow
+}
+
+// LocalAlertStore implements the alerts.Store interface.
+type LocalAlertStore struct {
+	db *localstore.DB
+
+	// mutex protects data.
+	mutex sync.Mutex
+	data  table
+}
+
+// New returns a new *LocalAlertStore.
+func New(db *localstore.DB) (*LocalAlertStore, error) {
+	ret := &LocalAlertStore{
+		db: db,
+		data: table{
+			NextID: 1,
+			Rows:   map[int64]alertRow{},
+		},
+	}
+	if err := db.Read(alertsTable, &ret.data); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return ret, nil
+}
+
+// Save implements the alerts.Store interface.
+func (s *LocalAlertStore) Save(ctx context.Context, req *alerts.SaveRequest) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cfg := req.Cfg
+	now := time.Now().Unix()
+	if cfg.IDAsString == alerts.BadAlertIDAsAsString {
+		// Not a valid ID, so this should be an insert, not an update.
+		id := s.data.NextID
+		s.data.NextID++
+		cfg.SetIDFromInt64(id)
+		b, err := json.Marshal(cfg)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to serialize Alert for saving with ID=%s", cfg.IDAsString)
+		}
+		s.data.Rows[id] = alertRow{
+			Alert:        string(b),
+			ConfigState:  alerts.ConfigStateToInt(alerts.ACTIVE),
+			LastModified: now,
+		}
+	} else {
+		b, err := json.Marshal(cfg)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to serialize Alert for saving with ID=%s", cfg.IDAsString)
+		}
+		row := alertRow{
+			Alert:        string(b),
+			ConfigState:  cfg.StateToInt(),
+			LastModified: now,
+		}
+		if req.SubKey != nil {
+			row.SubName = req.SubKey.SubName
+			row.SubRevision = req.SubKey.SubRevision
+		}
+		id := cfg.IDAsStringToInt()
+		s.data.Rows[id] = row
+		if id >= s.data.NextID {
+			s.data.NextID = id + 1
+		}
+	}
+	return skerr.Wrap(s.db.Write(alertsTable, s.data))
+}
+
+// Delete implements the alerts.Store interface.
+func (s *LocalAlertStore) Delete(ctx context.Context, id int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	row, ok := s.data.Rows[int64(id)]
+	if !ok {
+		return nil
+	}
+	row.ConfigState = alerts.ConfigStateToInt(alerts.DELETED)
+	row.LastModified = time.Now().Unix()
+	s.data.Rows[int64(id)] = row
+	if err := s.db.Write(alertsTable, s.data); err != nil {
+		return skerr.Wrapf(err, "Failed to mark Alert as deleted with ID=%d", id)
+	}
+	return nil
+}
+
+// List implements the alerts.Store interface.
+func (s *LocalAlertStore) List(ctx context.Context, includeDeleted bool) ([]*alerts.Alert, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ret := []*alerts.Alert{}
+	for id, row := range s.data.Rows {
+		if !includeDeleted && row.ConfigState != alerts.ConfigStateToInt(alerts.ACTIVE) {
+			continue
+		}
+		a := &alerts.Alert{}
+		if err := json.Unmarshal([]byte(row.Alert), a); err != nil {
+			return nil, skerr.Wrapf(err, "Failed to deserialize JSON Alert.")
+		}
+		a.SetIDFromInt64(id)
+		ret = append(ret, a)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].DisplayName == ret[j].DisplayName {
+			return ret[i].IDAsString < ret[j].IDAsString
+		}
+		return ret[i].DisplayName < ret[j].DisplayName
+	})
+	return ret, nil
+}
+
+// ReplaceAll implements the alerts.Store interface.
+//
+// The local store has no SQL transactions, so tx is ignored. The new table is
+// only swapped in once it has been written, so the replacement is all or
+// nothing.
+func (s *LocalAlertStore) ReplaceAll(ctx context.Context, reqs []*alerts.SaveRequest, _ pgx.Tx) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now().Unix()
+	newData := table{
+		NextID: s.data.NextID,
+		Rows:   make(map[int64]alertRow, len(s.data.Rows)+len(reqs)),
+	}
+	existing := map[alerts.SubscriptionAlertKey]int64{}
+	for id, row := range s.data.Rows {
+		newData.Rows[id] = row
+		if row.SubName == "" || row.ConfigState != alerts.ConfigStateToInt(alerts.ACTIVE) {
+			continue
+		}
+		a := &alerts.Alert{}
+		if err := json.Unmarshal([]byte(row.Alert), a); err != nil {
+			return skerr.Wrapf(err, "Failed to deserialize JSON Alert with ID=%d", id)
+		}
+		existing[alerts.SubscriptionAlertKey{SubName: row.SubName, Query: a.Query}] = id
+	}
+	ids := make([]int64, len(reqs))
+	for i, req := range reqs {
+		if req.SubKey == nil {
+			return skerr.Fmt("Alert %q is not part of a subscription", req.Cfg.DisplayName)
+		}
+		key := alerts.SubscriptionAlertKey{SubName: req.SubKey.SubName, Query: req.Cfg.Query}
+		if id, ok := existing[key]; ok {
+			// Only the first request for a key reuses the existing Alert.
+			delete(existing, key)
+			ids[i] = id
+		} else {
+			ids[i] = newData.NextID
+			newData.NextID++
+		}
+		b, err := json.Marshal(req.Cfg)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to serialize Alert %q", req.Cfg.DisplayName)
+		}
+		newData.Rows[ids[i]] = alertRow{
+			Alert:        string(b),
+			ConfigState:  alerts.ConfigStateToInt(alerts.ACTIVE),
+			LastModified: now,
+			SubName:      req.SubKey.SubName,
+			SubRevision:  req.SubKey.SubRevision,
+		}
+	}
+	for _, id := range existing {
+		row := newData.Rows[id]
+		row.ConfigState = alerts.ConfigStateToInt(alerts.DELETED)
+		row.LastModified = now
+		newData.Rows[id] = row
+	}
+	if err := s.db.Write(alertsTable, newData); err != nil {
+		return skerr.Wrap(err)
+	}
+	s.data = newData
+	for i, req := range reqs {
+		req.Cfg.SetIDFromInt64(ids[i])
+	}
+	return nil
+}
+
+// Confirm that *LocalAlertStore implements alerts.Store.
+var _ alerts.Store = (*LocalAlertStore)(nil)
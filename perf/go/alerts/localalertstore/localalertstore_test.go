@@ -0,0 +1,145 @@
+package localalertstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/localstore"
+)
+
+func setUp(t *testing.T) (*LocalAlertStore, *localstore.DB) {
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	store, err := New(db)
+	require.NoError(t, err)
+	return store, db
+}
+
+func TestStore_SaveListDelete(t *testing.T) {
+	ctx := context.Background()
+	store, db := setUp(t)
+
+	cfg := alerts.NewConfig()
+	cfg.Query = "source_type=svg"
+	cfg.DisplayName = "foo"
+	require.NoError(t, store.Save(ctx, &alerts.SaveRequest{Cfg: cfg}))
+	require.NotEqual(t, alerts.BadAlertIDAsAsString, cfg.IDAsString)
+
+	cfg = alerts.NewConfig()
+	cfg.Query = "source_type=skp"
+	cfg.DisplayName = "bar"
+	require.NoError(t, store.Save(ctx, &alerts.SaveRequest{Cfg: cfg}))
+
+	// Confirm they are both listed, ordered by DisplayName.
+	cfgs, err := store.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, cfgs, 2)
+	assert.Equal(t, "bar", cfgs[0].DisplayName)
+	assert.Equal(t, "foo", cfgs[1].DisplayName)
+
+	// Update an existing alert.
+	cfgs[1].Query = "source_type=png"
+	require.NoError(t, store.Save(ctx, &alerts.SaveRequest{Cfg: cfgs[1], SubKey: &alerts.SubKey{SubName: "sub", SubRevision: "abc"}}))
+
+	// Delete one.
+	require.NoError(t, store.Delete(ctx, int(cfgs[0].IDAsStringToInt())))
+
+	// Reopen the store and confirm the changes were persisted.
+	store, err = New(db)
+	require.NoError(t, err)
+	cfgs, err = store.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, cfgs, 1)
+	assert.Equal(t, "foo", cfgs[0].DisplayName)
+	assert.Equal(t, "source_type=png", cfgs[0].Query)
+
+	cfgs, err = store.List(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, cfgs, 2)
+
+	// New alerts get a new unique id.
+	cfg = alerts.NewConfig()
+	require.NoError(t, store.Save(ctx, &alerts.SaveRequest{Cfg: cfg}))
+	assert.NotEqual(t, cfgs[0].IDAsString, cfg.IDAsString)
+	assert.NotEqual(t, cfgs[1].IDAsString, cfg.IDAsString)
+}
+
+func TestReplaceAll_ReplacesOnlySubscriptionAlerts(t *testing.T) {
+	ctx := context.Background()
+	store, db := setUp(t)
+
+	manual := alerts.NewConfig()
+	manual.DisplayName = "manual"
+	require.NoError(t, store.Save(ctx, &alerts.SaveRequest{Cfg: manual}))
+	old := alerts.NewConfig()
+	old.DisplayName = "old"
+	old.Query = "bot=linux"
+	require.NoError(t, store.ReplaceAll(ctx, []*alerts.SaveRequest{{Cfg: old, SubKey: &alerts.SubKey{SubName: "sub", SubRevision: "abc"}}}, nil))
+
+	newCfg := alerts.NewConfig()
+	newCfg.DisplayName = "new"
+	newCfg.Query = "bot=mac"
+	require.NoError(t, store.ReplaceAll(ctx, []*alerts.SaveRequest{{Cfg: newCfg, SubKey: &alerts.SubKey{SubName: "sub", SubRevision: "def"}}}, nil))
+	assert.NotEqual(t, old.IDAsString, newCfg.IDAsString)
+
+	// Reopen the store and confirm the changes were persisted.
+	store, err := New(db)
+	require.NoError(t, err)
+	cfgs, err := store.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, cfgs, 2)
+	assert.Equal(t, "manual", cfgs[0].DisplayName)
+	assert.Equal(t, "new", cfgs[1].DisplayName)
+
+	cfgs, err = store.List(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, cfgs, 3)
+}
+
+func TestReplaceAll_SameSubscriptionAndQuery_AlertKeepsItsID(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setUp(t)
+
+	old := alerts.NewConfig()
+	old.Query = "bot=linux"
+	require.NoError(t, store.ReplaceAll(ctx, []*alerts.SaveRequest{{Cfg: old, SubKey: &alerts.SubKey{SubName: "sub", SubRevision: "abc"}}}, nil))
+
+	updated := alerts.NewConfig()
+	updated.Query = "bot=linux"
+	updated.Radius = 12
+	otherSub := alerts.NewConfig()
+	otherSub.Query = "bot=linux"
+	require.NoError(t, store.ReplaceAll(ctx, []*alerts.SaveRequest{
+		{Cfg: updated, SubKey: &alerts.SubKey{SubName: "sub", SubRevision: "def"}},
+		{Cfg: otherSub, SubKey: &alerts.SubKey{SubName: "other", SubRevision: "def"}},
+	}, nil))
+	assert.Equal(t, old.IDAsString, updated.IDAsString)
+	assert.NotEqual(t, old.IDAsString, otherSub.IDAsString)
+
+	cfgs, err := store.List(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, cfgs, 2)
+	for _, cfg := range cfgs {
+		if cfg.IDAsString == old.IDAsString {
+			assert.Equal(t, 12, cfg.Radius)
+		}
+	}
+}
+
+func TestReplaceAll_AlertWithoutSubscription_ReturnsErrorAndChangesNothing(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setUp(t)
+
+	old := alerts.NewConfig()
+	require.NoError(t, store.ReplaceAll(ctx, []*alerts.SaveRequest{{Cfg: old, SubKey: &alerts.SubKey{SubName: "sub", SubRevision: "abc"}}}, nil))
+
+	require.Error(t, store.ReplaceAll(ctx, []*alerts.SaveRequest{{Cfg: alerts.NewConfig()}}, nil))
+
+	cfgs, err := store.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, cfgs, 1)
+	assert.Equal(t, old.IDAsString, cfgs[0].IDAsString)
+}
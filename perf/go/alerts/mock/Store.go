@@ -8,6 +8,8 @@ import (
 	alerts "go.skia.org/infra/perf/go/alerts"
 
 	mock "github.com/stretchr/testify/mock"
+
+	pgx "github.com/jackc/pgx/v4"
 )
 
 // Store is an autogenerated mock type for the Store type
@@ -63,6 +65,24 @@ func (_m *Store) List(ctx context.Context, includeDeleted bool) ([]*alerts.Alert
 	return r0, r1
 }
 
+// ReplaceAll provides a mock function with given fields: ctx, reqs, tx
+func (_m *Store) ReplaceAll(ctx context.Context, reqs []*alerts.SaveRequest, tx pgx.Tx) error {
+	ret := _m.Called(ctx, reqs, tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceAll")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*alerts.SaveRequest, pgx.Tx) error); ok {
+		r0 = rf(ctx, reqs, tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Save provides a mock function with given fields: ctx, req
 func (_m *Store) Save(ctx context.Context, req *alerts.SaveRequest) error {
 	ret := _m.Called(ctx, req)
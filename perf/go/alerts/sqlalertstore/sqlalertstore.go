@@ -10,6 +10,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/jackc/pgx/v4"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/sql/pool"
 	"go.skia.org/infra/perf/go/alerts"
@@ -25,6 +26,10 @@ const (
 	deleteAlert
 	listActiveAlerts
 	listAllAlerts
+	listSubscriptionAlerts
+	insertSubscriptionAlert
+	updateSubscriptionAlert
+	deleteAlerts
 )
 
 // statements holds all the raw SQL statements used.
@@ -66,6 +71,42 @@ var statements = map[statement]string{
 		FROM
 			ALERTS
 		`,
+	listSubscriptionAlerts: `
+		SELECT
+			id, alert, sub_name
+		FROM
+			Alerts
+		WHERE
+			config_state=0 -- alerts.ACTIVE
+			AND sub_name IS NOT NULL
+		`,
+	insertSubscriptionAlert: `
+		INSERT INTO
+			Alerts (alert, last_modified, sub_name, sub_revision)
+		VALUES
+			($1, $2, $3, $4)
+		RETURNING
+			id
+		`,
+	updateSubscriptionAlert: `
+		UPDATE
+			Alerts
+		SET
+			alert=$1,
+			last_modified=$2,
+			sub_revision=$3
+		WHERE
+			id=$4
+		`,
+	deleteAlerts: `
+		UPDATE
+			Alerts
+		SET
+			config_state=1, -- alerts.DELETED
+			last_modified=$1
+		WHERE
+			id = ANY($2)
+		`,
 }
 
 // SQLAlertStore implements the alerts.Store interface.
@@ -128,6 +169,92 @@ func (s *SQLAlertStore) Delete(ctx context.Context, id int) error {
 	return nil
 }
 
+// ReplaceAll implements the alerts.Store interface.
+func (s *SQLAlertStore) ReplaceAll(ctx context.Context, reqs []*alerts.SaveRequest, tx pgx.Tx) error {
+	for _, req := range reqs {
+		if req.SubKey == nil {
+			return skerr.Fmt("Alert %q is not part of a subscription", req.Cfg.DisplayName)
+		}
+	}
+	ids := make([]int64, len(reqs))
+	var err error
+	if tx != nil {
+		err = replaceAllInTx(ctx, reqs, ids, tx)
+	} else {
+		err = s.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+			return replaceAllInTx(ctx, reqs, ids, tx)
+		})
+	}
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	for i, req := range reqs {
+		req.Cfg.SetIDFromInt64(ids[i])
+	}
+	return nil
+}
+
+// replaceAllInTx writes reqs in tx and fills in ids with the ID of the Alert
+// each request was stored as.
+func replaceAllInTx(ctx context.Context, reqs []*alerts.SaveRequest, ids []int64, tx pgx.Tx) error {
+	rows, err := tx.Query(ctx, statements[listSubscriptionAlerts])
+	if err != nil {
+		return skerr.Wrapf(err, "Failed to list subscription Alerts")
+	}
+	existing := map[alerts.SubscriptionAlertKey]int64{}
+	for rows.Next() {
+		var id int64
+		var serializedAlert string
+		var subName string
+		if err := rows.Scan(&id, &serializedAlert, &subName); err != nil {
+			rows.Close()
+			return skerr.Wrap(err)
+		}
+		a := &alerts.Alert{}
+		if err := json.Unmarshal([]byte(serializedAlert), a); err != nil {
+			rows.Close()
+			return skerr.Wrapf(err, "Failed to deserialize JSON Alert with ID=%d", id)
+		}
+		existing[alerts.SubscriptionAlertKey{SubName: subName, Query: a.Query}] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return skerr.Wrap(err)
+	}
+
+	now := time.Now().Unix()
+	for i, req := range reqs {
+		key := alerts.SubscriptionAlertKey{SubName: req.SubKey.SubName, Query: req.Cfg.Query}
+		id, ok := existing[key]
+		if ok {
+			// Only the first request for a key reuses the existing Alert.
+			delete(existing, key)
+			req.Cfg.SetIDFromInt64(id)
+		}
+		b, err := json.Marshal(req.Cfg)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to serialize Alert %q", req.Cfg.DisplayName)
+		}
+		if ok {
+			if _, err := tx.Exec(ctx, statements[updateSubscriptionAlert], string(b), now, req.SubKey.SubRevision, id); err != nil {
+				return skerr.Wrapf(err, "Failed to update Alert %q with ID=%d", req.Cfg.DisplayName, id)
+			}
+		} else if err := tx.QueryRow(ctx, statements[insertSubscriptionAlert], string(b), now, req.SubKey.SubName, req.SubKey.SubRevision).Scan(&id); err != nil {
+			return skerr.Wrapf(err, "Failed to insert Alert %q", req.Cfg.DisplayName)
+		}
+		ids[i] = id
+	}
+
+	removed := make([]int64, 0, len(existing))
+	for _, id := range existing {
+		removed = append(removed, id)
+	}
+	if _, err := tx.Exec(ctx, statements[deleteAlerts], now, removed); err != nil {
+		return skerr.Wrapf(err, "Failed to mark removed subscription Alerts as deleted")
+	}
+	return nil
+}
+
 type sortableAlertSlice []*alerts.Alert
 
 func (p sortableAlertSlice) Len() int { return len(p) }
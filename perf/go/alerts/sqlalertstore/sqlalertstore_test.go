@@ -236,6 +236,90 @@ func TestStoreSave_SaveWithSubscription(t *testing.T) {
 	assert.Equal(t, alerts.ConfigStateToInt(alerts.ACTIVE), configState)
 }
 
+func TestStoreReplaceAll_ReplacesOnlySubscriptionAlerts(t *testing.T) {
+	ctx := context.Background()
+	store, db := setUp(t)
+
+	manual := alerts.NewConfig()
+	manual.SetIDFromInt64(1)
+	manual.DisplayName = "manual"
+	insertAlertToDb(t, ctx, db, manual, nil)
+
+	fromSub := alerts.NewConfig()
+	fromSub.SetIDFromInt64(2)
+	fromSub.DisplayName = "old"
+	fromSub.Query = "bot=linux"
+	insertAlertToDb(t, ctx, db, fromSub, &alerts.SubKey{SubName: "sub", SubRevision: "abcd"})
+
+	cfg := alerts.NewConfig()
+	cfg.DisplayName = "new"
+	cfg.Query = "bot=mac"
+	err := store.ReplaceAll(ctx, []*alerts.SaveRequest{
+		{
+			Cfg:    cfg,
+			SubKey: &alerts.SubKey{SubName: "sub", SubRevision: "efgh"},
+		},
+	}, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, alerts.BadAlertIDAsAsString, cfg.IDAsString)
+
+	cfgs, err := store.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, cfgs, 2)
+	assert.Equal(t, "manual", cfgs[0].DisplayName)
+	assert.Equal(t, "new", cfgs[1].DisplayName)
+
+	_, subKey, configState := getAlertFromDb(t, ctx, db, cfg.IDAsStringToInt())
+	assert.Equal(t, "efgh", subKey.SubRevision)
+	assert.Equal(t, alerts.ConfigStateToInt(alerts.ACTIVE), configState)
+
+	_, _, configState = getAlertFromDb(t, ctx, db, 2)
+	assert.Equal(t, alerts.ConfigStateToInt(alerts.DELETED), configState)
+}
+
+func TestStoreReplaceAll_SameSubscriptionAndQuery_AlertKeepsItsID(t *testing.T) {
+	ctx := context.Background()
+	store, db := setUp(t)
+
+	fromSub := alerts.NewConfig()
+	fromSub.SetIDFromInt64(1)
+	fromSub.Query = "bot=linux"
+	insertAlertToDb(t, ctx, db, fromSub, &alerts.SubKey{SubName: "sub", SubRevision: "abcd"})
+
+	cfg := alerts.NewConfig()
+	cfg.Query = "bot=linux"
+	cfg.Radius = 12
+	err := store.ReplaceAll(ctx, []*alerts.SaveRequest{
+		{
+			Cfg:    cfg,
+			SubKey: &alerts.SubKey{SubName: "sub", SubRevision: "efgh"},
+		},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cfg.IDAsStringToInt())
+
+	alert, subKey, configState := getAlertFromDb(t, ctx, db, 1)
+	assert.Equal(t, 12, alert.Radius)
+	assert.Equal(t, "efgh", subKey.SubRevision)
+	assert.Equal(t, alerts.ConfigStateToInt(alerts.ACTIVE), configState)
+}
+
+func TestStoreReplaceAll_AlertWithoutSubscription_ReturnsErrorAndChangesNothing(t *testing.T) {
+	ctx := context.Background()
+	store, db := setUp(t)
+
+	fromSub := alerts.NewConfig()
+	fromSub.SetIDFromInt64(1)
+	fromSub.DisplayName = "old"
+	insertAlertToDb(t, ctx, db, fromSub, &alerts.SubKey{SubName: "sub", SubRevision: "abcd"})
+
+	err := store.ReplaceAll(ctx, []*alerts.SaveRequest{{Cfg: alerts.NewConfig()}}, nil)
+	require.Error(t, err)
+
+	_, _, configState := getAlertFromDb(t, ctx, db, 1)
+	assert.Equal(t, alerts.ConfigStateToInt(alerts.ACTIVE), configState)
+}
+
 func insertAlertToDb(t *testing.T, ctx context.Context, db pool.Pool, cfg *alerts.Alert, subKey *alerts.SubKey) {
 	b, err := json.Marshal(cfg)
 	require.NoError(t, err)
@@ -1,12 +1,24 @@
 package alerts
 
-import "context"
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+)
 
 type SubKey struct {
 	SubName     string
 	SubRevision string
 }
 
+// SubscriptionAlertKey identifies an Alert created from a subscription across
+// revisions of the subscription, which Store.ReplaceAll uses to keep the IDs
+// of Alerts stable.
+type SubscriptionAlertKey struct {
+	SubName string
+	Query   string
+}
+
 type SaveRequest struct {
 	Cfg    *Alert
 	SubKey *SubKey
@@ -26,4 +38,15 @@ type Store interface {
 	// If includeDeleted is true then deleted Alerts are also included in the
 	// response.
 	List(ctx context.Context, includeDeleted bool) ([]*Alert, error)
+
+	// ReplaceAll replaces every active Alert that was created from a
+	// subscription with the Alerts in reqs, all or nothing. An Alert with the
+	// same subscription name and query as an existing one is updated in
+	// place, so it keeps its ID, the remaining existing Alerts are marked as
+	// deleted, and the rest of reqs are inserted. Alerts that weren't created
+	// from a subscription are left untouched.
+	//
+	// If tx is not nil then the changes are made as part of that transaction,
+	// otherwise the store uses its own transaction.
+	ReplaceAll(ctx context.Context, reqs []*SaveRequest, tx pgx.Tx) error
 }
@@ -138,6 +138,94 @@ func (_m *Store) LoadById(ctx context.Context, group_id string) (*v1.AnomalyGrou
 	return r0, r1
 }
 
+// LoadHistory provides a mock function with given fields: ctx, group_id
+func (_m *Store) LoadHistory(ctx context.Context, group_id string) ([]*v1.AnomalyGroupChange, error) {
+	ret := _m.Called(ctx, group_id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoadHistory")
+	}
+
+	var r0 []*v1.AnomalyGroupChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*v1.AnomalyGroupChange, error)); ok {
+		return rf(ctx, group_id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*v1.AnomalyGroupChange); ok {
+		r0 = rf(ctx, group_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*v1.AnomalyGroupChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, group_id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MergeGroups provides a mock function with given fields: ctx, target_group_id, source_group_ids, reason
+func (_m *Store) MergeGroups(ctx context.Context, target_group_id string, source_group_ids []string, reason string) (*v1.AnomalyGroup, error) {
+	ret := _m.Called(ctx, target_group_id, source_group_ids, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeGroups")
+	}
+
+	var r0 *v1.AnomalyGroup
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, string) (*v1.AnomalyGroup, error)); ok {
+		return rf(ctx, target_group_id, source_group_ids, reason)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, string) *v1.AnomalyGroup); ok {
+		r0 = rf(ctx, target_group_id, source_group_ids, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.AnomalyGroup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, string) error); ok {
+		r1 = rf(ctx, target_group_id, source_group_ids, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SplitGroup provides a mock function with given fields: ctx, group_id, anomaly_ids, reason
+func (_m *Store) SplitGroup(ctx context.Context, group_id string, anomaly_ids []string, reason string) (string, error) {
+	ret := _m.Called(ctx, group_id, anomaly_ids, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SplitGroup")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, string) (string, error)); ok {
+		return rf(ctx, group_id, anomaly_ids, reason)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, string) string); ok {
+		r0 = rf(ctx, group_id, anomaly_ids, reason)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, string) error); ok {
+		r1 = rf(ctx, group_id, anomaly_ids, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateBisectID provides a mock function with given fields: ctx, group_id, bisection_id
 func (_m *Store) UpdateBisectID(ctx context.Context, group_id string, bisection_id string) error {
 	ret := _m.Called(ctx, group_id, bisection_id)
@@ -76,6 +76,59 @@ func (GroupActionType) EnumDescriptor() ([]byte, []int) {
 	return file_anomalygroup_service_proto_rawDescGZIP(), []int{0}
 }
 
+// The kind of change made to anomaly groups after they were created.
+type GroupChangeType int32
+
+const (
+	// Not set.
+	GroupChangeType_UNKNOWN_CHANGE GroupChangeType = 0
+	// Anomaly groups were merged into another group.
+	GroupChangeType_MERGE GroupChangeType = 1
+	// Anomalies were split off an anomaly group into a new group.
+	GroupChangeType_SPLIT GroupChangeType = 2
+)
+
+// Enum value maps for GroupChangeType.
+var (
+	GroupChangeType_name = map[int32]string{
+		0: "UNKNOWN_CHANGE",
+		1: "MERGE",
+		2: "SPLIT",
+	}
+	GroupChangeType_value = map[string]int32{
+		"UNKNOWN_CHANGE": 0,
+		"MERGE":          1,
+		"SPLIT":          2,
+	}
+)
+
+func (x GroupChangeType) Enum() *GroupChangeType {
+	p := new(GroupChangeType)
+	*p = x
+	return p
+}
+
+func (x GroupChangeType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GroupChangeType) Descriptor() protoreflect.EnumDescriptor {
+	return file_anomalygroup_service_proto_enumTypes[1].Descriptor()
+}
+
+func (GroupChangeType) Type() protoreflect.EnumType {
+	return &file_anomalygroup_service_proto_enumTypes[1]
+}
+
+func (x GroupChangeType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GroupChangeType.Descriptor instead.
+func (GroupChangeType) EnumDescriptor() ([]byte, []int) {
+	return file_anomalygroup_service_proto_rawDescGZIP(), []int{1}
+}
+
 // Request object for CreateNewAnomalyGroup
 type CreateNewAnomalyGroupRequest struct {
 	state         protoimpl.MessageState
@@ -702,6 +755,337 @@ func (x *FindTopAnomaliesResponse) GetAnomalies() []*Anomaly {
 	return nil
 }
 
+// Request object for MergeAnomalyGroups
+type MergeAnomalyGroupsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The ID of the anomaly group to merge the other groups into.
+	TargetGroupId string `protobuf:"bytes,1,opt,name=target_group_id,json=targetGroupId,proto3" json:"target_group_id,omitempty"`
+	// The IDs of the anomaly groups to merge into the target group.
+	SourceGroupIds []string `protobuf:"bytes,2,rep,name=source_group_ids,json=sourceGroupIds,proto3" json:"source_group_ids,omitempty"`
+	// Why the groups are merged, e.g. the culprit they share.
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *MergeAnomalyGroupsRequest) Reset() {
+	*x = MergeAnomalyGroupsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_anomalygroup_service_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MergeAnomalyGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeAnomalyGroupsRequest) ProtoMessage() {}
+
+func (x *MergeAnomalyGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_anomalygroup_service_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergeAnomalyGroupsRequest.ProtoReflect.Descriptor instead.
+func (*MergeAnomalyGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_anomalygroup_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *MergeAnomalyGroupsRequest) GetTargetGroupId() string {
+	if x != nil {
+		return x.TargetGroupId
+	}
+	return ""
+}
+
+func (x *MergeAnomalyGroupsRequest) GetSourceGroupIds() []string {
+	if x != nil {
+		return x.SourceGroupIds
+	}
+	return nil
+}
+
+func (x *MergeAnomalyGroupsRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Response object for MergeAnomalyGroups
+type MergeAnomalyGroupsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The target anomaly group after the merge.
+	AnomalyGroup *AnomalyGroup `protobuf:"bytes,1,opt,name=anomaly_group,json=anomalyGroup,proto3" json:"anomaly_group,omitempty"`
+}
+
+func (x *MergeAnomalyGroupsResponse) Reset() {
+	*x = MergeAnomalyGroupsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_anomalygroup_service_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MergeAnomalyGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeAnomalyGroupsResponse) ProtoMessage() {}
+
+func (x *MergeAnomalyGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_anomalygroup_service_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergeAnomalyGroupsResponse.ProtoReflect.Descriptor instead.
+func (*MergeAnomalyGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_anomalygroup_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *MergeAnomalyGroupsResponse) GetAnomalyGroup() *AnomalyGroup {
+	if x != nil {
+		return x.AnomalyGroup
+	}
+	return nil
+}
+
+// Request object for SplitAnomalyGroup
+type SplitAnomalyGroupRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The ID of the anomaly group to split.
+	AnomalyGroupId string `protobuf:"bytes,1,opt,name=anomaly_group_id,json=anomalyGroupId,proto3" json:"anomaly_group_id,omitempty"`
+	// The anomalies to move into the new group. They must all belong to
+	// the group, and can't be all of its anomalies.
+	AnomalyIds []string `protobuf:"bytes,2,rep,name=anomaly_ids,json=anomalyIds,proto3" json:"anomaly_ids,omitempty"`
+	// Why the group is split.
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *SplitAnomalyGroupRequest) Reset() {
+	*x = SplitAnomalyGroupRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_anomalygroup_service_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SplitAnomalyGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SplitAnomalyGroupRequest) ProtoMessage() {}
+
+func (x *SplitAnomalyGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_anomalygroup_service_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SplitAnomalyGroupRequest.ProtoReflect.Descriptor instead.
+func (*SplitAnomalyGroupRequest) Descriptor() ([]byte, []int) {
+	return file_anomalygroup_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SplitAnomalyGroupRequest) GetAnomalyGroupId() string {
+	if x != nil {
+		return x.AnomalyGroupId
+	}
+	return ""
+}
+
+func (x *SplitAnomalyGroupRequest) GetAnomalyIds() []string {
+	if x != nil {
+		return x.AnomalyIds
+	}
+	return nil
+}
+
+func (x *SplitAnomalyGroupRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Response object for SplitAnomalyGroup
+type SplitAnomalyGroupResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The ID of the newly created anomaly group.
+	NewAnomalyGroupId string `protobuf:"bytes,1,opt,name=new_anomaly_group_id,json=newAnomalyGroupId,proto3" json:"new_anomaly_group_id,omitempty"`
+}
+
+func (x *SplitAnomalyGroupResponse) Reset() {
+	*x = SplitAnomalyGroupResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_anomalygroup_service_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SplitAnomalyGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SplitAnomalyGroupResponse) ProtoMessage() {}
+
+func (x *SplitAnomalyGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_anomalygroup_service_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SplitAnomalyGroupResponse.ProtoReflect.Descriptor instead.
+func (*SplitAnomalyGroupResponse) Descriptor() ([]byte, []int) {
+	return file_anomalygroup_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SplitAnomalyGroupResponse) GetNewAnomalyGroupId() string {
+	if x != nil {
+		return x.NewAnomalyGroupId
+	}
+	return ""
+}
+
+// Request object for GetAnomalyGroupHistory
+type GetAnomalyGroupHistoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The ID of the anomaly group to read the history of.
+	AnomalyGroupId string `protobuf:"bytes,1,opt,name=anomaly_group_id,json=anomalyGroupId,proto3" json:"anomaly_group_id,omitempty"`
+}
+
+func (x *GetAnomalyGroupHistoryRequest) Reset() {
+	*x = GetAnomalyGroupHistoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_anomalygroup_service_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAnomalyGroupHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAnomalyGroupHistoryRequest) ProtoMessage() {}
+
+func (x *GetAnomalyGroupHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_anomalygroup_service_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAnomalyGroupHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetAnomalyGroupHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_anomalygroup_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetAnomalyGroupHistoryRequest) GetAnomalyGroupId() string {
+	if x != nil {
+		return x.AnomalyGroupId
+	}
+	return ""
+}
+
+// Response object for GetAnomalyGroupHistory
+type GetAnomalyGroupHistoryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The changes made to the group, oldest first.
+	Changes []*AnomalyGroupChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+}
+
+func (x *GetAnomalyGroupHistoryResponse) Reset() {
+	*x = GetAnomalyGroupHistoryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_anomalygroup_service_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAnomalyGroupHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAnomalyGroupHistoryResponse) ProtoMessage() {}
+
+func (x *GetAnomalyGroupHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_anomalygroup_service_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAnomalyGroupHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetAnomalyGroupHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_anomalygroup_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetAnomalyGroupHistoryResponse) GetChanges() []*AnomalyGroupChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
 // Simplified format for an anomaly group, which should be sufficient
 // in the following use cases:
 //  1. provide a list of anomalies for filing a bug.
@@ -727,12 +1111,16 @@ type AnomalyGroup struct {
 	SubsciptionName string `protobuf:"bytes,6,opt,name=subsciption_name,json=subsciptionName,proto3" json:"subsciption_name,omitempty"`
 	// The subscription revision this anomaly group belongs to
 	SubscriptionRevision string `protobuf:"bytes,7,opt,name=subscription_revision,json=subscriptionRevision,proto3" json:"subscription_revision,omitempty"`
+	// The ID of the group this group was merged into, if any.
+	MergedInto string `protobuf:"bytes,8,opt,name=merged_into,json=mergedInto,proto3" json:"merged_into,omitempty"`
+	// The ID of the bisection job launched for this group, if any.
+	BisectionId string `protobuf:"bytes,9,opt,name=bisection_id,json=bisectionId,proto3" json:"bisection_id,omitempty"`
 }
 
 func (x *AnomalyGroup) Reset() {
 	*x = AnomalyGroup{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_anomalygroup_service_proto_msgTypes[10]
+		mi := &file_anomalygroup_service_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -745,7 +1133,7 @@ func (x *AnomalyGroup) String() string {
 func (*AnomalyGroup) ProtoMessage() {}
 
 func (x *AnomalyGroup) ProtoReflect() protoreflect.Message {
-	mi := &file_anomalygroup_service_proto_msgTypes[10]
+	mi := &file_anomalygroup_service_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -758,7 +1146,7 @@ func (x *AnomalyGroup) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AnomalyGroup.ProtoReflect.Descriptor instead.
 func (*AnomalyGroup) Descriptor() ([]byte, []int) {
-	return file_anomalygroup_service_proto_rawDescGZIP(), []int{10}
+	return file_anomalygroup_service_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *AnomalyGroup) GetGroupId() string {
@@ -810,6 +1198,20 @@ func (x *AnomalyGroup) GetSubscriptionRevision() string {
 	return ""
 }
 
+func (x *AnomalyGroup) GetMergedInto() string {
+	if x != nil {
+		return x.MergedInto
+	}
+	return ""
+}
+
+func (x *AnomalyGroup) GetBisectionId() string {
+	if x != nil {
+		return x.BisectionId
+	}
+	return ""
+}
+
 // Regression object in a format used for anomaly group actions,
 // including filing a new bug and triggering a new bisection job.
 type Anomaly struct {
@@ -847,7 +1249,7 @@ type Anomaly struct {
 func (x *Anomaly) Reset() {
 	*x = Anomaly{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_anomalygroup_service_proto_msgTypes[11]
+		mi := &file_anomalygroup_service_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -860,7 +1262,7 @@ func (x *Anomaly) String() string {
 func (*Anomaly) ProtoMessage() {}
 
 func (x *Anomaly) ProtoReflect() protoreflect.Message {
-	mi := &file_anomalygroup_service_proto_msgTypes[11]
+	mi := &file_anomalygroup_service_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -873,7 +1275,7 @@ func (x *Anomaly) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Anomaly.ProtoReflect.Descriptor instead.
 func (*Anomaly) Descriptor() ([]byte, []int) {
-	return file_anomalygroup_service_proto_rawDescGZIP(), []int{11}
+	return file_anomalygroup_service_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *Anomaly) GetStartCommit() int64 {
@@ -904,6 +1306,101 @@ func (x *Anomaly) GetImprovementDirection() string {
 	return ""
 }
 
+// A merge or split an anomaly group took part in.
+type AnomalyGroupChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The ID of the anomaly group.
+	GroupId string `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	// Whether the group was merged or split.
+	ChangeType GroupChangeType `protobuf:"varint,2,opt,name=change_type,json=changeType,proto3,enum=anomalygroup.v1.GroupChangeType" json:"change_type,omitempty"`
+	// The other groups involved: the groups merged into this one, or the
+	// group this one was merged into, or the group split from or off it.
+	RelatedGroupIds []string `protobuf:"bytes,3,rep,name=related_group_ids,json=relatedGroupIds,proto3" json:"related_group_ids,omitempty"`
+	// The anomalies moved between the groups.
+	AnomalyIds []string `protobuf:"bytes,4,rep,name=anomaly_ids,json=anomalyIds,proto3" json:"anomaly_ids,omitempty"`
+	// Why the change was made.
+	Reason string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	// When the change was made, in seconds since the Unix epoch.
+	CreateTime int64 `protobuf:"varint,6,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+}
+
+func (x *AnomalyGroupChange) Reset() {
+	*x = AnomalyGroupChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_anomalygroup_service_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnomalyGroupChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnomalyGroupChange) ProtoMessage() {}
+
+func (x *AnomalyGroupChange) ProtoReflect() protoreflect.Message {
+	mi := &file_anomalygroup_service_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnomalyGroupChange.ProtoReflect.Descriptor instead.
+func (*AnomalyGroupChange) Descriptor() ([]byte, []int) {
+	return file_anomalygroup_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AnomalyGroupChange) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *AnomalyGroupChange) GetChangeType() GroupChangeType {
+	if x != nil {
+		return x.ChangeType
+	}
+	return GroupChangeType_UNKNOWN_CHANGE
+}
+
+func (x *AnomalyGroupChange) GetRelatedGroupIds() []string {
+	if x != nil {
+		return x.RelatedGroupIds
+	}
+	return nil
+}
+
+func (x *AnomalyGroupChange) GetAnomalyIds() []string {
+	if x != nil {
+		return x.AnomalyIds
+	}
+	return nil
+}
+
+func (x *AnomalyGroupChange) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AnomalyGroupChange) GetCreateTime() int64 {
+	if x != nil {
+		return x.CreateTime
+	}
+	return 0
+}
+
 var File_anomalygroup_service_proto protoreflect.FileDescriptor
 
 var file_anomalygroup_service_proto_rawDesc = []byte{
@@ -993,88 +1490,172 @@ var file_anomalygroup_service_proto_rawDesc = []byte{
 	0x73, 0x65, 0x12, 0x36, 0x0a, 0x09, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x69, 0x65, 0x73, 0x18,
 	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67,
 	0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x52,
-	0x09, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x69, 0x65, 0x73, 0x22, 0xbc, 0x02, 0x0a, 0x0c, 0x41,
-	0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x19, 0x0a, 0x08, 0x67,
+	0x09, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x69, 0x65, 0x73, 0x22, 0x85, 0x01, 0x0a, 0x19, 0x4d,
+	0x65, 0x72, 0x67, 0x65, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x61, 0x72, 0x67,
+	0x65, 0x74, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0d, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x64,
+	0x12, 0x28, 0x0a, 0x10, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x5f, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x22, 0x60, 0x0a, 0x1a, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x41, 0x6e, 0x6f, 0x6d, 0x61,
+	0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x42, 0x0a, 0x0d, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x5f, 0x67, 0x72, 0x6f, 0x75,
+	0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c,
+	0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c,
+	0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x0c, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47,
+	0x72, 0x6f, 0x75, 0x70, 0x22, 0x7d, 0x0a, 0x18, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x41, 0x6e, 0x6f,
+	0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x28, 0x0a, 0x10, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x5f, 0x67, 0x72, 0x6f, 0x75,
+	0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6e, 0x6f, 0x6d,
+	0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x6e,
+	0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0a, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x49, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61,
+	0x73, 0x6f, 0x6e, 0x22, 0x4c, 0x0a, 0x19, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x41, 0x6e, 0x6f, 0x6d,
+	0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x2f, 0x0a, 0x14, 0x6e, 0x65, 0x77, 0x5f, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x5f,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11,
+	0x6e, 0x65, 0x77, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49,
+	0x64, 0x22, 0x49, 0x0a, 0x1d, 0x47, 0x65, 0x74, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47,
+	0x72, 0x6f, 0x75, 0x70, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x28, 0x0a, 0x10, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x5f, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6e,
+	0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x64, 0x22, 0x5f, 0x0a, 0x1e,
+	0x47, 0x65, 0x74, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d,
+	0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x23, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76,
+	0x31, 0x2e, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x22, 0x80, 0x03,
+	0x0a, 0x0c, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x19,
+	0x0a, 0x08, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x64, 0x12, 0x43, 0x0a, 0x0c, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x20, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70,
+	0x65, 0x52, 0x0b, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1f,
+	0x0a, 0x0b, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x49, 0x64, 0x73, 0x12,
+	0x1f, 0x0a, 0x0b, 0x63, 0x75, 0x6c, 0x70, 0x72, 0x69, 0x74, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x75, 0x6c, 0x70, 0x72, 0x69, 0x74, 0x49, 0x64, 0x73,
+	0x12, 0x2a, 0x0a, 0x11, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x5f, 0x69, 0x73, 0x73,
+	0x75, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x72, 0x65, 0x70,
+	0x6f, 0x72, 0x74, 0x65, 0x64, 0x49, 0x73, 0x73, 0x75, 0x65, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x10,
+	0x73, 0x75, 0x62, 0x73, 0x63, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x73, 0x75, 0x62, 0x73, 0x63, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x33, 0x0a, 0x15, 0x73, 0x75, 0x62, 0x73, 0x63,
+	0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b,
+	0x6d, 0x65, 0x72, 0x67, 0x65, 0x64, 0x5f, 0x69, 0x6e, 0x74, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x6d, 0x65, 0x72, 0x67, 0x65, 0x64, 0x49, 0x6e, 0x74, 0x6f, 0x12, 0x21, 0x0a,
+	0x0c, 0x62, 0x69, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x62, 0x69, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64,
+	0x22, 0x81, 0x02, 0x0a, 0x07, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x12, 0x21, 0x0a, 0x0c,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x65, 0x6e, 0x64, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x6e, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x42,
+	0x0a, 0x08, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x26, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e,
+	0x76, 0x31, 0x2e, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d,
+	0x73, 0x65, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x65, 0x74, 0x12, 0x33, 0x0a, 0x15, 0x69, 0x6d, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x5f, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x14, 0x69, 0x6d, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x44, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x3b, 0x0a, 0x0d, 0x50, 0x61, 0x72, 0x61, 0x6d,
+	0x73, 0x65, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0xf8, 0x01, 0x0a, 0x12, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x67,
 	0x72, 0x6f, 0x75, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x67,
-	0x72, 0x6f, 0x75, 0x70, 0x49, 0x64, 0x12, 0x43, 0x0a, 0x0c, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f,
-	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x20, 0x2e, 0x61,
-	0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x47,
-	0x72, 0x6f, 0x75, 0x70, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0b,
-	0x67, 0x72, 0x6f, 0x75, 0x70, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x61,
-	0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09,
-	0x52, 0x0a, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x49, 0x64, 0x73, 0x12, 0x1f, 0x0a, 0x0b,
-	0x63, 0x75, 0x6c, 0x70, 0x72, 0x69, 0x74, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
-	0x09, 0x52, 0x0a, 0x63, 0x75, 0x6c, 0x70, 0x72, 0x69, 0x74, 0x49, 0x64, 0x73, 0x12, 0x2a, 0x0a,
-	0x11, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x5f, 0x69, 0x73, 0x73, 0x75, 0x65, 0x5f,
-	0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74,
-	0x65, 0x64, 0x49, 0x73, 0x73, 0x75, 0x65, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x75, 0x62,
-	0x73, 0x63, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x06, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0f, 0x73, 0x75, 0x62, 0x73, 0x63, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x33, 0x0a, 0x15, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70,
-	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x14, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x81, 0x02, 0x0a, 0x07, 0x41, 0x6e,
-	0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x63,
-	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x73, 0x74, 0x61,
-	0x72, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x6e, 0x64, 0x5f,
-	0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x6e,
-	0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x42, 0x0a, 0x08, 0x70, 0x61, 0x72, 0x61, 0x6d,
-	0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x61, 0x6e, 0x6f, 0x6d,
-	0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x6f, 0x6d,
-	0x61, 0x6c, 0x79, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x65, 0x74, 0x45, 0x6e, 0x74, 0x72,
-	0x79, 0x52, 0x08, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x65, 0x74, 0x12, 0x33, 0x0a, 0x15, 0x69,
-	0x6d, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x64, 0x69, 0x72, 0x65, 0x63,
-	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x69, 0x6d, 0x70, 0x72,
-	0x6f, 0x76, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x1a, 0x3b, 0x0a, 0x0d, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x65, 0x74, 0x45, 0x6e, 0x74, 0x72,
-	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
-	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x2a, 0x37, 0x0a,
-	0x0f, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65,
-	0x12, 0x0c, 0x0a, 0x08, 0x4e, 0x4f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x00, 0x12, 0x0a,
-	0x0a, 0x06, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x42, 0x49,
-	0x53, 0x45, 0x43, 0x54, 0x10, 0x02, 0x32, 0xd3, 0x04, 0x0a, 0x13, 0x41, 0x6e, 0x6f, 0x6d, 0x61,
-	0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x78,
-	0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x65, 0x77, 0x41, 0x6e, 0x6f, 0x6d, 0x61,
-	0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x2d, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c,
-	0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x4e, 0x65, 0x77, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2e, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79,
+	0x72, 0x6f, 0x75, 0x70, 0x49, 0x64, 0x12, 0x41, 0x0a, 0x0b, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x20, 0x2e, 0x61, 0x6e,
+	0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x72, 0x65, 0x6c,
+	0x61, 0x74, 0x65, 0x64, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x47, 0x72, 0x6f,
+	0x75, 0x70, 0x49, 0x64, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79,
+	0x5f, 0x69, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x6e, 0x6f, 0x6d,
+	0x61, 0x6c, 0x79, 0x49, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1f,
+	0x0a, 0x0b, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x2a,
+	0x37, 0x0a, 0x0f, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x4e, 0x4f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x00,
+	0x12, 0x0a, 0x0a, 0x06, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06,
+	0x42, 0x49, 0x53, 0x45, 0x43, 0x54, 0x10, 0x02, 0x2a, 0x3b, 0x0a, 0x0f, 0x47, 0x72, 0x6f, 0x75,
+	0x70, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x0e, 0x55,
+	0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x10, 0x00, 0x12,
+	0x09, 0x0a, 0x05, 0x4d, 0x45, 0x52, 0x47, 0x45, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x53, 0x50,
+	0x4c, 0x49, 0x54, 0x10, 0x02, 0x32, 0xaf, 0x07, 0x0a, 0x13, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c,
+	0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x78, 0x0a,
+	0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x65, 0x77, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c,
+	0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x2d, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79,
 	0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e,
 	0x65, 0x77, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x75, 0x0a, 0x14, 0x4c, 0x6f, 0x61, 0x64,
-	0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x42, 0x79, 0x49, 0x44,
-	0x12, 0x2c, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e,
-	0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x61, 0x64, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72,
-	0x6f, 0x75, 0x70, 0x42, 0x79, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d,
-	0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31,
-	0x2e, 0x4c, 0x6f, 0x61, 0x64, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75,
-	0x70, 0x42, 0x79, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
-	0x6f, 0x0a, 0x12, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79,
-	0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x2a, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67,
-	0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x6e,
-	0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2e, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67,
+	0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x65,
+	0x77, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x75, 0x0a, 0x14, 0x4c, 0x6f, 0x61, 0x64, 0x41,
+	0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x42, 0x79, 0x49, 0x44, 0x12,
+	0x2c, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x6f, 0x61, 0x64, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f,
+	0x75, 0x70, 0x42, 0x79, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d, 0x2e,
+	0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x6f, 0x61, 0x64, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x42, 0x79, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x6f,
+	0x0a, 0x12, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47,
+	0x72, 0x6f, 0x75, 0x70, 0x12, 0x2a, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x6e, 0x6f,
+	0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x2b, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e,
+	0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
+	0x6f, 0x0a, 0x12, 0x46, 0x69, 0x6e, 0x64, 0x45, 0x78, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x47,
+	0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x2a, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67,
+	0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6e, 0x64, 0x45, 0x78, 0x69, 0x73,
+	0x74, 0x69, 0x6e, 0x67, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
 	0x74, 0x1a, 0x2b, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70,
-	0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c,
-	0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
-	0x12, 0x6f, 0x0a, 0x12, 0x46, 0x69, 0x6e, 0x64, 0x45, 0x78, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67,
-	0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x2a, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79,
-	0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6e, 0x64, 0x45, 0x78, 0x69,
-	0x73, 0x74, 0x69, 0x6e, 0x67, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x2b, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75,
-	0x70, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6e, 0x64, 0x45, 0x78, 0x69, 0x73, 0x74, 0x69, 0x6e,
-	0x67, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
-	0x00, 0x12, 0x69, 0x0a, 0x10, 0x46, 0x69, 0x6e, 0x64, 0x54, 0x6f, 0x70, 0x41, 0x6e, 0x6f, 0x6d,
-	0x61, 0x6c, 0x69, 0x65, 0x73, 0x12, 0x28, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67,
-	0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6e, 0x64, 0x54, 0x6f, 0x70, 0x41,
-	0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x29, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76,
-	0x31, 0x2e, 0x46, 0x69, 0x6e, 0x64, 0x54, 0x6f, 0x70, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x69,
-	0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x31, 0x5a, 0x2f,
-	0x67, 0x6f, 0x2e, 0x73, 0x6b, 0x69, 0x61, 0x2e, 0x6f, 0x72, 0x67, 0x2f, 0x69, 0x6e, 0x66, 0x72,
-	0x61, 0x2f, 0x70, 0x65, 0x72, 0x66, 0x2f, 0x67, 0x6f, 0x2f, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c,
-	0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6e, 0x64, 0x45, 0x78, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
+	0x12, 0x69, 0x0a, 0x10, 0x46, 0x69, 0x6e, 0x64, 0x54, 0x6f, 0x70, 0x41, 0x6e, 0x6f, 0x6d, 0x61,
+	0x6c, 0x69, 0x65, 0x73, 0x12, 0x28, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6e, 0x64, 0x54, 0x6f, 0x70, 0x41, 0x6e,
+	0x6f, 0x6d, 0x61, 0x6c, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29,
+	0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x69, 0x6e, 0x64, 0x54, 0x6f, 0x70, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x69, 0x65,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x6f, 0x0a, 0x12, 0x4d,
+	0x65, 0x72, 0x67, 0x65, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x73, 0x12, 0x2a, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2b, 0x2e,
+	0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e,
+	0x4d, 0x65, 0x72, 0x67, 0x65, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75,
+	0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x6c, 0x0a, 0x11,
+	0x53, 0x70, 0x6c, 0x69, 0x74, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75,
+	0x70, 0x12, 0x29, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a, 0x2e, 0x61,
+	0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x70, 0x6c, 0x69, 0x74, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x7b, 0x0a, 0x16, 0x47, 0x65,
+	0x74, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x48, 0x69, 0x73,
+	0x74, 0x6f, 0x72, 0x79, 0x12, 0x2e, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c,
+	0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x2f, 0x2e, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6e, 0x6f, 0x6d, 0x61, 0x6c,
+	0x79, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x6f, 0x2e, 0x73, 0x6b,
+	0x69, 0x61, 0x2e, 0x6f, 0x72, 0x67, 0x2f, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2f, 0x70, 0x65, 0x72,
+	0x66, 0x2f, 0x67, 0x6f, 0x2f, 0x61, 0x6e, 0x6f, 0x6d, 0x61, 0x6c, 0x79, 0x67, 0x72, 0x6f, 0x75,
+	0x70, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
@@ -1089,47 +1670,64 @@ func file_anomalygroup_service_proto_rawDescGZIP() []byte {
 	return file_anomalygroup_service_proto_rawDescData
 }
 
-var file_anomalygroup_service_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_anomalygroup_service_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_anomalygroup_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_anomalygroup_service_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_anomalygroup_service_proto_goTypes = []interface{}{
-	(GroupActionType)(0),                  // 0: anomalygroup.v1.GroupActionType
-	(*CreateNewAnomalyGroupRequest)(nil),  // 1: anomalygroup.v1.CreateNewAnomalyGroupRequest
-	(*CreateNewAnomalyGroupResponse)(nil), // 2: anomalygroup.v1.CreateNewAnomalyGroupResponse
-	(*LoadAnomalyGroupByIDRequest)(nil),   // 3: anomalygroup.v1.LoadAnomalyGroupByIDRequest
-	(*LoadAnomalyGroupByIDResponse)(nil),  // 4: anomalygroup.v1.LoadAnomalyGroupByIDResponse
-	(*UpdateAnomalyGroupRequest)(nil),     // 5: anomalygroup.v1.UpdateAnomalyGroupRequest
-	(*UpdateAnomalyGroupResponse)(nil),    // 6: anomalygroup.v1.UpdateAnomalyGroupResponse
-	(*FindExistingGroupsRequest)(nil),     // 7: anomalygroup.v1.FindExistingGroupsRequest
-	(*FindExistingGroupsResponse)(nil),    // 8: anomalygroup.v1.FindExistingGroupsResponse
-	(*FindTopAnomaliesRequest)(nil),       // 9: anomalygroup.v1.FindTopAnomaliesRequest
-	(*FindTopAnomaliesResponse)(nil),      // 10: anomalygroup.v1.FindTopAnomaliesResponse
-	(*AnomalyGroup)(nil),                  // 11: anomalygroup.v1.AnomalyGroup
-	(*Anomaly)(nil),                       // 12: anomalygroup.v1.Anomaly
-	nil,                                   // 13: anomalygroup.v1.Anomaly.ParamsetEntry
+	(GroupActionType)(0),                   // 0: anomalygroup.v1.GroupActionType
+	(GroupChangeType)(0),                   // 1: anomalygroup.v1.GroupChangeType
+	(*CreateNewAnomalyGroupRequest)(nil),   // 2: anomalygroup.v1.CreateNewAnomalyGroupRequest
+	(*CreateNewAnomalyGroupResponse)(nil),  // 3: anomalygroup.v1.CreateNewAnomalyGroupResponse
+	(*LoadAnomalyGroupByIDRequest)(nil),    // 4: anomalygroup.v1.LoadAnomalyGroupByIDRequest
+	(*LoadAnomalyGroupByIDResponse)(nil),   // 5: anomalygroup.v1.LoadAnomalyGroupByIDResponse
+	(*UpdateAnomalyGroupRequest)(nil),      // 6: anomalygroup.v1.UpdateAnomalyGroupRequest
+	(*UpdateAnomalyGroupResponse)(nil),     // 7: anomalygroup.v1.UpdateAnomalyGroupResponse
+	(*FindExistingGroupsRequest)(nil),      // 8: anomalygroup.v1.FindExistingGroupsRequest
+	(*FindExistingGroupsResponse)(nil),     // 9: anomalygroup.v1.FindExistingGroupsResponse
+	(*FindTopAnomaliesRequest)(nil),        // 10: anomalygroup.v1.FindTopAnomaliesRequest
+	(*FindTopAnomaliesResponse)(nil),       // 11: anomalygroup.v1.FindTopAnomaliesResponse
+	(*MergeAnomalyGroupsRequest)(nil),      // 12: anomalygroup.v1.MergeAnomalyGroupsRequest
+	(*MergeAnomalyGroupsResponse)(nil),     // 13: anomalygroup.v1.MergeAnomalyGroupsResponse
+	(*SplitAnomalyGroupRequest)(nil),       // 14: anomalygroup.v1.SplitAnomalyGroupRequest
+	(*SplitAnomalyGroupResponse)(nil),      // 15: anomalygroup.v1.SplitAnomalyGroupResponse
+	(*GetAnomalyGroupHistoryRequest)(nil),  // 16: anomalygroup.v1.GetAnomalyGroupHistoryRequest
+	(*GetAnomalyGroupHistoryResponse)(nil), // 17: anomalygroup.v1.GetAnomalyGroupHistoryResponse
+	(*AnomalyGroup)(nil),                   // 18: anomalygroup.v1.AnomalyGroup
+	(*Anomaly)(nil),                        // 19: anomalygroup.v1.Anomaly
+	(*AnomalyGroupChange)(nil),             // 20: anomalygroup.v1.AnomalyGroupChange
+	nil,                                    // 21: anomalygroup.v1.Anomaly.ParamsetEntry
 }
 var file_anomalygroup_service_proto_depIdxs = []int32{
 	0,  // 0: anomalygroup.v1.CreateNewAnomalyGroupRequest.action:type_name -> anomalygroup.v1.GroupActionType
-	11, // 1: anomalygroup.v1.LoadAnomalyGroupByIDResponse.anomaly_group:type_name -> anomalygroup.v1.AnomalyGroup
+	18, // 1: anomalygroup.v1.LoadAnomalyGroupByIDResponse.anomaly_group:type_name -> anomalygroup.v1.AnomalyGroup
 	0,  // 2: anomalygroup.v1.FindExistingGroupsRequest.action:type_name -> anomalygroup.v1.GroupActionType
-	11, // 3: anomalygroup.v1.FindExistingGroupsResponse.anomaly_groups:type_name -> anomalygroup.v1.AnomalyGroup
-	12, // 4: anomalygroup.v1.FindTopAnomaliesResponse.anomalies:type_name -> anomalygroup.v1.Anomaly
-	0,  // 5: anomalygroup.v1.AnomalyGroup.group_action:type_name -> anomalygroup.v1.GroupActionType
-	13, // 6: anomalygroup.v1.Anomaly.paramset:type_name -> anomalygroup.v1.Anomaly.ParamsetEntry
-	1,  // 7: anomalygroup.v1.AnomalyGroupService.CreateNewAnomalyGroup:input_type -> anomalygroup.v1.CreateNewAnomalyGroupRequest
-	3,  // 8: anomalygroup.v1.AnomalyGroupService.LoadAnomalyGroupByID:input_type -> anomalygroup.v1.LoadAnomalyGroupByIDRequest
-	5,  // 9: anomalygroup.v1.AnomalyGroupService.UpdateAnomalyGroup:input_type -> anomalygroup.v1.UpdateAnomalyGroupRequest
-	7,  // 10: anomalygroup.v1.AnomalyGroupService.FindExistingGroups:input_type -> anomalygroup.v1.FindExistingGroupsRequest
-	9,  // 11: anomalygroup.v1.AnomalyGroupService.FindTopAnomalies:input_type -> anomalygroup.v1.FindTopAnomaliesRequest
-	2,  // 12: anomalygroup.v1.AnomalyGroupService.CreateNewAnomalyGroup:output_type -> anomalygroup.v1.CreateNewAnomalyGroupResponse
-	4,  // 13: anomalygroup.v1.AnomalyGroupService.LoadAnomalyGroupByID:output_type -> anomalygroup.v1.LoadAnomalyGroupByIDResponse
-	6,  // 14: anomalygroup.v1.AnomalyGroupService.UpdateAnomalyGroup:output_type -> anomalygroup.v1.UpdateAnomalyGroupResponse
-	8,  // 15: anomalygroup.v1.AnomalyGroupService.FindExistingGroups:output_type -> anomalygroup.v1.FindExistingGroupsResponse
-	10, // 16: anomalygroup.v1.AnomalyGroupService.FindTopAnomalies:output_type -> anomalygroup.v1.FindTopAnomaliesResponse
-	12, // [12:17] is the sub-list for method output_type
-	7,  // [7:12] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	18, // 3: anomalygroup.v1.FindExistingGroupsResponse.anomaly_groups:type_name -> anomalygroup.v1.AnomalyGroup
+	19, // 4: anomalygroup.v1.FindTopAnomaliesResponse.anomalies:type_name -> anomalygroup.v1.Anomaly
+	18, // 5: anomalygroup.v1.MergeAnomalyGroupsResponse.anomaly_group:type_name -> anomalygroup.v1.AnomalyGroup
+	20, // 6: anomalygroup.v1.GetAnomalyGroupHistoryResponse.changes:type_name -> anomalygroup.v1.AnomalyGroupChange
+	0,  // 7: anomalygroup.v1.AnomalyGroup.group_action:type_name -> anomalygroup.v1.GroupActionType
+	21, // 8: anomalygroup.v1.Anomaly.paramset:type_name -> anomalygroup.v1.Anomaly.ParamsetEntry
+	1,  // 9: anomalygroup.v1.AnomalyGroupChange.change_type:type_name -> anomalygroup.v1.GroupChangeType
+	2,  // 10: anomalygroup.v1.AnomalyGroupService.CreateNewAnomalyGroup:input_type -> anomalygroup.v1.CreateNewAnomalyGroupRequest
+	4,  // 11: anomalygroup.v1.AnomalyGroupService.LoadAnomalyGroupByID:input_type -> anomalygroup.v1.LoadAnomalyGroupByIDRequest
+	6,  // 12: anomalygroup.v1.AnomalyGroupService.UpdateAnomalyGroup:input_type -> anomalygroup.v1.UpdateAnomalyGroupRequest
+	8,  // 13: anomalygroup.v1.AnomalyGroupService.FindExistingGroups:input_type -> anomalygroup.v1.FindExistingGroupsRequest
+	10, // 14: anomalygroup.v1.AnomalyGroupService.FindTopAnomalies:input_type -> anomalygroup.v1.FindTopAnomaliesRequest
+	12, // 15: anomalygroup.v1.AnomalyGroupService.MergeAnomalyGroups:input_type -> anomalygroup.v1.MergeAnomalyGroupsRequest
+	14, // 16: anomalygroup.v1.AnomalyGroupService.SplitAnomalyGroup:input_type -> anomalygroup.v1.SplitAnomalyGroupRequest
+	16, // 17: anomalygroup.v1.AnomalyGroupService.GetAnomalyGroupHistory:input_type -> anomalygroup.v1.GetAnomalyGroupHistoryRequest
+	3,  // 18: anomalygroup.v1.AnomalyGroupService.CreateNewAnomalyGroup:output_type -> anomalygroup.v1.CreateNewAnomalyGroupResponse
+	5,  // 19: anomalygroup.v1.AnomalyGroupService.LoadAnomalyGroupByID:output_type -> anomalygroup.v1.LoadAnomalyGroupByIDResponse
+	7,  // 20: anomalygroup.v1.AnomalyGroupService.UpdateAnomalyGroup:output_type -> anomalygroup.v1.UpdateAnomalyGroupResponse
+	9,  // 21: anomalygroup.v1.AnomalyGroupService.FindExistingGroups:output_type -> anomalygroup.v1.FindExistingGroupsResponse
+	11, // 22: anomalygroup.v1.AnomalyGroupService.FindTopAnomalies:output_type -> anomalygroup.v1.FindTopAnomaliesResponse
+	13, // 23: anomalygroup.v1.AnomalyGroupService.MergeAnomalyGroups:output_type -> anomalygroup.v1.MergeAnomalyGroupsResponse
+	15, // 24: anomalygroup.v1.AnomalyGroupService.SplitAnomalyGroup:output_type -> anomalygroup.v1.SplitAnomalyGroupResponse
+	17, // 25: anomalygroup.v1.AnomalyGroupService.GetAnomalyGroupHistory:output_type -> anomalygroup.v1.GetAnomalyGroupHistoryResponse
+	18, // [18:26] is the sub-list for method output_type
+	10, // [10:18] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_anomalygroup_service_proto_init() }
@@ -1259,7 +1857,7 @@ func file_anomalygroup_service_proto_init() {
 			}
 		}
 		file_anomalygroup_service_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AnomalyGroup); i {
+			switch v := v.(*MergeAnomalyGroupsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1271,6 +1869,78 @@ func file_anomalygroup_service_proto_init() {
 			}
 		}
 		file_anomalygroup_service_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MergeAnomalyGroupsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_anomalygroup_service_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SplitAnomalyGroupRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_anomalygroup_service_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SplitAnomalyGroupResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_anomalygroup_service_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetAnomalyGroupHistoryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_anomalygroup_service_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetAnomalyGroupHistoryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_anomalygroup_service_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnomalyGroup); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_anomalygroup_service_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Anomaly); i {
 			case 0:
 				return &v.state
@@ -1282,14 +1952,26 @@ func file_anomalygroup_service_proto_init() {
 				return nil
 			}
 		}
+		file_anomalygroup_service_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnomalyGroupChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_anomalygroup_service_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   13,
+			NumEnums:      2,
+			NumMessages:   20,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
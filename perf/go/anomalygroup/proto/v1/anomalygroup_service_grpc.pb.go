@@ -20,11 +20,14 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	AnomalyGroupService_CreateNewAnomalyGroup_FullMethodName = "/anomalygroup.v1.AnomalyGroupService/CreateNewAnomalyGroup"
-	AnomalyGroupService_LoadAnomalyGroupByID_FullMethodName  = "/anomalygroup.v1.AnomalyGroupService/LoadAnomalyGroupByID"
-	AnomalyGroupService_UpdateAnomalyGroup_FullMethodName    = "/anomalygroup.v1.AnomalyGroupService/UpdateAnomalyGroup"
-	AnomalyGroupService_FindExistingGroups_FullMethodName    = "/anomalygroup.v1.AnomalyGroupService/FindExistingGroups"
-	AnomalyGroupService_FindTopAnomalies_FullMethodName      = "/anomalygroup.v1.AnomalyGroupService/FindTopAnomalies"
+	AnomalyGroupService_CreateNewAnomalyGroup_FullMethodName  = "/anomalygroup.v1.AnomalyGroupService/CreateNewAnomalyGroup"
+	AnomalyGroupService_LoadAnomalyGroupByID_FullMethodName   = "/anomalygroup.v1.AnomalyGroupService/LoadAnomalyGroupByID"
+	AnomalyGroupService_UpdateAnomalyGroup_FullMethodName     = "/anomalygroup.v1.AnomalyGroupService/UpdateAnomalyGroup"
+	AnomalyGroupService_FindExistingGroups_FullMethodName     = "/anomalygroup.v1.AnomalyGroupService/FindExistingGroups"
+	AnomalyGroupService_FindTopAnomalies_FullMethodName       = "/anomalygroup.v1.AnomalyGroupService/FindTopAnomalies"
+	AnomalyGroupService_MergeAnomalyGroups_FullMethodName     = "/anomalygroup.v1.AnomalyGroupService/MergeAnomalyGroups"
+	AnomalyGroupService_SplitAnomalyGroup_FullMethodName      = "/anomalygroup.v1.AnomalyGroupService/SplitAnomalyGroup"
+	AnomalyGroupService_GetAnomalyGroupHistory_FullMethodName = "/anomalygroup.v1.AnomalyGroupService/GetAnomalyGroupHistory"
 )
 
 // AnomalyGroupServiceClient is the client API for AnomalyGroupService service.
@@ -42,6 +45,15 @@ type AnomalyGroupServiceClient interface {
 	// (e.g., from a newly found anomaly).
 	FindExistingGroups(ctx context.Context, in *FindExistingGroupsRequest, opts ...grpc.CallOption) (*FindExistingGroupsResponse, error)
 	FindTopAnomalies(ctx context.Context, in *FindTopAnomaliesRequest, opts ...grpc.CallOption) (*FindTopAnomaliesResponse, error)
+	// Merge anomaly groups into a target group, e.g. when they turn out to
+	// share a culprit. The merged groups are kept for their history, but no
+	// longer have any anomalies.
+	MergeAnomalyGroups(ctx context.Context, in *MergeAnomalyGroupsRequest, opts ...grpc.CallOption) (*MergeAnomalyGroupsResponse, error)
+	// Move anomalies out of an anomaly group into a new group, e.g. when
+	// they were wrongly grouped.
+	SplitAnomalyGroup(ctx context.Context, in *SplitAnomalyGroupRequest, opts ...grpc.CallOption) (*SplitAnomalyGroupResponse, error)
+	// Read the merges and splits an anomaly group took part in.
+	GetAnomalyGroupHistory(ctx context.Context, in *GetAnomalyGroupHistoryRequest, opts ...grpc.CallOption) (*GetAnomalyGroupHistoryResponse, error)
 }
 
 type anomalyGroupServiceClient struct {
@@ -97,6 +109,33 @@ func (c *anomalyGroupServiceClient) FindTopAnomalies(ctx context.Context, in *Fi
 	return out, nil
 }
 
+func (c *anomalyGroupServiceClient) MergeAnomalyGroups(ctx context.Context, in *MergeAnomalyGroupsRequest, opts ...grpc.CallOption) (*MergeAnomalyGroupsResponse, error) {
+	out := new(MergeAnomalyGroupsResponse)
+	err := c.cc.Invoke(ctx, AnomalyGroupService_MergeAnomalyGroups_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *anomalyGroupServiceClient) SplitAnomalyGroup(ctx context.Context, in *SplitAnomalyGroupRequest, opts ...grpc.CallOption) (*SplitAnomalyGroupResponse, error) {
+	out := new(SplitAnomalyGroupResponse)
+	err := c.cc.Invoke(ctx, AnomalyGroupService_SplitAnomalyGroup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *anomalyGroupServiceClient) GetAnomalyGroupHistory(ctx context.Context, in *GetAnomalyGroupHistoryRequest, opts ...grpc.CallOption) (*GetAnomalyGroupHistoryResponse, error) {
+	out := new(GetAnomalyGroupHistoryResponse)
+	err := c.cc.Invoke(ctx, AnomalyGroupService_GetAnomalyGroupHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AnomalyGroupServiceServer is the server API for AnomalyGroupService service.
 // All implementations must embed UnimplementedAnomalyGroupServiceServer
 // for forward compatibility
@@ -112,6 +151,15 @@ type AnomalyGroupServiceServer interface {
 	// (e.g., from a newly found anomaly).
 	FindExistingGroups(context.Context, *FindExistingGroupsRequest) (*FindExistingGroupsResponse, error)
 	FindTopAnomalies(context.Context, *FindTopAnomaliesRequest) (*FindTopAnomaliesResponse, error)
+	// Merge anomaly groups into a target group, e.g. when they turn out to
+	// share a culprit. The merged groups are kept for their history, but no
+	// longer have any anomalies.
+	MergeAnomalyGroups(context.Context, *MergeAnomalyGroupsRequest) (*MergeAnomalyGroupsResponse, error)
+	// Move anomalies out of an anomaly group into a new group, e.g. when
+	// they were wrongly grouped.
+	SplitAnomalyGroup(context.Context, *SplitAnomalyGroupRequest) (*SplitAnomalyGroupResponse, error)
+	// Read the merges and splits an anomaly group took part in.
+	GetAnomalyGroupHistory(context.Context, *GetAnomalyGroupHistoryRequest) (*GetAnomalyGroupHistoryResponse, error)
 	mustEmbedUnimplementedAnomalyGroupServiceServer()
 }
 
@@ -134,6 +182,15 @@ func (UnimplementedAnomalyGroupServiceServer) FindExistingGroups(context.Context
 func (UnimplementedAnomalyGroupServiceServer) FindTopAnomalies(context.Context, *FindTopAnomaliesRequest) (*FindTopAnomaliesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FindTopAnomalies not implemented")
 }
+func (UnimplementedAnomalyGroupServiceServer) MergeAnomalyGroups(context.Context, *MergeAnomalyGroupsRequest) (*MergeAnomalyGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergeAnomalyGroups not implemented")
+}
+func (UnimplementedAnomalyGroupServiceServer) SplitAnomalyGroup(context.Context, *SplitAnomalyGroupRequest) (*SplitAnomalyGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SplitAnomalyGroup not implemented")
+}
+func (UnimplementedAnomalyGroupServiceServer) GetAnomalyGroupHistory(context.Context, *GetAnomalyGroupHistoryRequest) (*GetAnomalyGroupHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAnomalyGroupHistory not implemented")
+}
 func (UnimplementedAnomalyGroupServiceServer) mustEmbedUnimplementedAnomalyGroupServiceServer() {}
 
 // UnsafeAnomalyGroupServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -237,6 +294,60 @@ func _AnomalyGroupService_FindTopAnomalies_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AnomalyGroupService_MergeAnomalyGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeAnomalyGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnomalyGroupServiceServer).MergeAnomalyGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnomalyGroupService_MergeAnomalyGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnomalyGroupServiceServer).MergeAnomalyGroups(ctx, req.(*MergeAnomalyGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnomalyGroupService_SplitAnomalyGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SplitAnomalyGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnomalyGroupServiceServer).SplitAnomalyGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnomalyGroupService_SplitAnomalyGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnomalyGroupServiceServer).SplitAnomalyGroup(ctx, req.(*SplitAnomalyGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnomalyGroupService_GetAnomalyGroupHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAnomalyGroupHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnomalyGroupServiceServer).GetAnomalyGroupHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnomalyGroupService_GetAnomalyGroupHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnomalyGroupServiceServer).GetAnomalyGroupHistory(ctx, req.(*GetAnomalyGroupHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AnomalyGroupService_ServiceDesc is the grpc.ServiceDesc for AnomalyGroupService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -264,6 +375,18 @@ var AnomalyGroupService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FindTopAnomalies",
 			Handler:    _AnomalyGroupService_FindTopAnomalies_Handler,
 		},
+		{
+			MethodName: "MergeAnomalyGroups",
+			Handler:    _AnomalyGroupService_MergeAnomalyGroups_Handler,
+		},
+		{
+			MethodName: "SplitAnomalyGroup",
+			Handler:    _AnomalyGroupService_SplitAnomalyGroup_Handler,
+		},
+		{
+			MethodName: "GetAnomalyGroupHistory",
+			Handler:    _AnomalyGroupService_GetAnomalyGroupHistory_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "anomalygroup_service.proto",
@@ -110,6 +110,36 @@ func (_m *AnomalyGroupServiceServer) FindTopAnomalies(_a0 context.Context, _a1 *
 	return r0, r1
 }
 
+// GetAnomalyGroupHistory provides a mock function with given fields: _a0, _a1
+func (_m *AnomalyGroupServiceServer) GetAnomalyGroupHistory(_a0 context.Context, _a1 *v1.GetAnomalyGroupHistoryRequest) (*v1.GetAnomalyGroupHistoryResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAnomalyGroupHistory")
+	}
+
+	var r0 *v1.GetAnomalyGroupHistoryResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *v1.GetAnomalyGroupHistoryRequest) (*v1.GetAnomalyGroupHistoryResponse, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *v1.GetAnomalyGroupHistoryRequest) *v1.GetAnomalyGroupHistoryResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.GetAnomalyGroupHistoryResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *v1.GetAnomalyGroupHistoryRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // LoadAnomalyGroupByID provides a mock function with given fields: _a0, _a1
 func (_m *AnomalyGroupServiceServer) LoadAnomalyGroupByID(_a0 context.Context, _a1 *v1.LoadAnomalyGroupByIDRequest) (*v1.LoadAnomalyGroupByIDResponse, error) {
 	ret := _m.Called(_a0, _a1)
@@ -140,6 +170,66 @@ func (_m *AnomalyGroupServiceServer) LoadAnomalyGroupByID(_a0 context.Context, _
 	return r0, r1
 }
 
+// MergeAnomalyGroups provides a mock function with given fields: _a0, _a1
+func (_m *AnomalyGroupServiceServer) MergeAnomalyGroups(_a0 context.Context, _a1 *v1.MergeAnomalyGroupsRequest) (*v1.MergeAnomalyGroupsResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeAnomalyGroups")
+	}
+
+	var r0 *v1.MergeAnomalyGroupsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *v1.MergeAnomalyGroupsRequest) (*v1.MergeAnomalyGroupsResponse, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *v1.MergeAnomalyGroupsRequest) *v1.MergeAnomalyGroupsResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.MergeAnomalyGroupsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *v1.MergeAnomalyGroupsRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SplitAnomalyGroup provides a mock function with given fields: _a0, _a1
+func (_m *AnomalyGroupServiceServer) SplitAnomalyGroup(_a0 context.Context, _a1 *v1.SplitAnomalyGroupRequest) (*v1.SplitAnomalyGroupResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SplitAnomalyGroup")
+	}
+
+	var r0 *v1.SplitAnomalyGroupResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *v1.SplitAnomalyGroupRequest) (*v1.SplitAnomalyGroupResponse, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *v1.SplitAnomalyGroupRequest) *v1.SplitAnomalyGroupResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.SplitAnomalyGroupResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *v1.SplitAnomalyGroupRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateAnomalyGroup provides a mock function with given fields: _a0, _a1
 func (_m *AnomalyGroupServiceServer) UpdateAnomalyGroup(_a0 context.Context, _a1 *v1.UpdateAnomalyGroupRequest) (*v1.UpdateAnomalyGroupResponse, error) {
 	ret := _m.Called(_a0, _a1)
@@ -217,6 +217,50 @@ func (s *anomalygroupService) FindTopAnomalies(
 	}, nil
 }
 
+// Merge the source groups into the target group, and return the target group.
+func (s *anomalygroupService) MergeAnomalyGroups(
+	ctx context.Context,
+	req *ag.MergeAnomalyGroupsRequest) (*ag.MergeAnomalyGroupsResponse, error) {
+	anomaly_group, err := s.anomalygroupStore.MergeGroups(
+		ctx, req.TargetGroupId, req.SourceGroupIds, req.Reason)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "failed to merge anomaly groups %s into %s",
+			req.SourceGroupIds, req.TargetGroupId)
+	}
+	return &ag.MergeAnomalyGroupsResponse{
+		AnomalyGroup: anomaly_group,
+	}, nil
+}
+
+// Move the given anomalies into a new group, and return the new group id.
+func (s *anomalygroupService) SplitAnomalyGroup(
+	ctx context.Context,
+	req *ag.SplitAnomalyGroupRequest) (*ag.SplitAnomalyGroupResponse, error) {
+	new_group_id, err := s.anomalygroupStore.SplitGroup(
+		ctx, req.AnomalyGroupId, req.AnomalyIds, req.Reason)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "failed to split anomalies %s off anomaly group %s",
+			req.AnomalyIds, req.AnomalyGroupId)
+	}
+	return &ag.SplitAnomalyGroupResponse{
+		NewAnomalyGroupId: new_group_id,
+	}, nil
+}
+
+// Given a group id, return the merges and splits the group took part in.
+func (s *anomalygroupService) GetAnomalyGroupHistory(
+	ctx context.Context,
+	req *ag.GetAnomalyGroupHistoryRequest) (*ag.GetAnomalyGroupHistoryResponse, error) {
+	changes, err := s.anomalygroupStore.LoadHistory(ctx, req.AnomalyGroupId)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "failed to load the history of anomaly group %s",
+			req.AnomalyGroupId)
+	}
+	return &ag.GetAnomalyGroupHistoryResponse{
+		Changes: changes,
+	}, nil
+}
+
 func isParamSetValid(paramset paramtools.ReadOnlyParamSet) bool {
 	requiredKeys := []string{"bot", "benchmark", "test", "stat", "subtest_1"}
 	for _, key := range requiredKeys {
@@ -194,6 +194,89 @@ func TestFindExistingGroups_anomalygroupFail(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed on finding existing groups")
 }
 
+func TestMergeAnomalyGroups(t *testing.T) {
+	service, store, _ := setUp(t)
+	ctx := context.Background()
+	req := &ag.MergeAnomalyGroupsRequest{
+		TargetGroupId:  "ce7107ae-3552-49e9-bd89-120ff97c3cea",
+		SourceGroupIds: []string{"3cb85993-d0a8-452e-86ec-cb5154aada9c"},
+		Reason:         "same culprit",
+	}
+	merged := &ag.AnomalyGroup{
+		GroupId:    "ce7107ae-3552-49e9-bd89-120ff97c3cea",
+		AnomalyIds: []string{"b1fb4036-1883-4d9e-85d4-ed607629017a"},
+	}
+	store.On("MergeGroups", mock.Anything,
+		"ce7107ae-3552-49e9-bd89-120ff97c3cea",
+		[]string{"3cb85993-d0a8-452e-86ec-cb5154aada9c"},
+		"same culprit").Return(merged, nil)
+
+	resp, err := service.MergeAnomalyGroups(ctx, req)
+
+	store.AssertExpectations(t)
+	assert.NoError(t, err)
+	assert.Equal(t, merged, resp.AnomalyGroup)
+}
+
+func TestMergeAnomalyGroups_StoreFails(t *testing.T) {
+	service, store, _ := setUp(t)
+	ctx := context.Background()
+	req := &ag.MergeAnomalyGroupsRequest{
+		TargetGroupId:  "ce7107ae-3552-49e9-bd89-120ff97c3cea",
+		SourceGroupIds: []string{"3cb85993-d0a8-452e-86ec-cb5154aada9c"},
+	}
+	store.On("MergeGroups", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything).Return(nil, errors.New("fail"))
+
+	_, err := service.MergeAnomalyGroups(ctx, req)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to merge anomaly groups")
+}
+
+func TestSplitAnomalyGroup(t *testing.T) {
+	service, store, _ := setUp(t)
+	ctx := context.Background()
+	req := &ag.SplitAnomalyGroupRequest{
+		AnomalyGroupId: "ce7107ae-3552-49e9-bd89-120ff97c3cea",
+		AnomalyIds:     []string{"b1fb4036-1883-4d9e-85d4-ed607629017a"},
+		Reason:         "wrongly grouped",
+	}
+	store.On("SplitGroup", mock.Anything,
+		"ce7107ae-3552-49e9-bd89-120ff97c3cea",
+		[]string{"b1fb4036-1883-4d9e-85d4-ed607629017a"},
+		"wrongly grouped").Return("24fa5591-946b-44e4-bf09-3fd271588ee5", nil)
+
+	resp, err := service.SplitAnomalyGroup(ctx, req)
+
+	store.AssertExpectations(t)
+	assert.NoError(t, err)
+	assert.Equal(t, "24fa5591-946b-44e4-bf09-3fd271588ee5", resp.NewAnomalyGroupId)
+}
+
+func TestGetAnomalyGroupHistory(t *testing.T) {
+	service, store, _ := setUp(t)
+	ctx := context.Background()
+	req := &ag.GetAnomalyGroupHistoryRequest{
+		AnomalyGroupId: "ce7107ae-3552-49e9-bd89-120ff97c3cea",
+	}
+	changes := []*ag.AnomalyGroupChange{
+		{
+			GroupId:         "ce7107ae-3552-49e9-bd89-120ff97c3cea",
+			ChangeType:      ag.GroupChangeType_SPLIT,
+			RelatedGroupIds: []string{"24fa5591-946b-44e4-bf09-3fd271588ee5"},
+		},
+	}
+	store.On("LoadHistory", mock.Anything,
+		"ce7107ae-3552-49e9-bd89-120ff97c3cea").Return(changes, nil)
+
+	resp, err := service.GetAnomalyGroupHistory(ctx, req)
+
+	store.AssertExpectations(t)
+	assert.NoError(t, err)
+	assert.Equal(t, changes, resp.Changes)
+}
+
 func TestFindTopAnomalies_TopOneOfTwo(t *testing.T) {
 	group_id := "ce7107ae-3552-49e9-bd89-120ff97c3cea"
 	anomaly_ids := []string{"b1fb4036-1883-4d9e-85d4-ed607629017a"}
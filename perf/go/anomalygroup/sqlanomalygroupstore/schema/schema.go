@@ -45,4 +45,37 @@ type AnomalyGroupSchema struct {
 
 	// The timestamp of the last update
 	LastModifiedTime time.Time `sql:"last_modified_time TIMESTAMPTZ"`
+
+	// The ID of the group this group was merged into, if any. A merged
+	// group keeps its row for its history, but is no longer matched
+	// when grouping new anomalies.
+	MergedInto string `sql:"merged_into UUID"`
+}
+
+// AnomalyGroupHistorySchema represents the SQL schema of the
+// AnomalyGroupHistory table, which records every merge and split of
+// anomaly groups.
+type AnomalyGroupHistorySchema struct {
+	ID string `sql:"id UUID PRIMARY KEY DEFAULT gen_random_uuid()"`
+
+	// The ID of the anomaly group the change was made to.
+	GroupID string `sql:"group_id UUID"`
+
+	// Either 'MERGE' or 'SPLIT'.
+	ChangeType string `sql:"change_type TEXT"`
+
+	// The other groups involved in the change, i.e. the groups merged
+	// into or out of GroupID, or the group split from or off GroupID.
+	RelatedGroupIDs []string `sql:"related_group_ids UUID ARRAY"`
+
+	// The anomalies that were moved between the groups.
+	AnomalyIDs []string `sql:"anomaly_ids UUID ARRAY"`
+
+	// Why the change was made.
+	Reason string `sql:"reason TEXT"`
+
+	// The timestamp when the change was made.
+	CreateTime time.Time `sql:"create_time TIMESTAMPTZ DEFAULT now()"`
+
+	byGroupIDIndex struct{} `sql:"INDEX by_group_id (group_id)"`
 }
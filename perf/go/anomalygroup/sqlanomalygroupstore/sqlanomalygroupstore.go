@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/sql/pool"
 
@@ -110,7 +112,7 @@ func (s *AnomalyGroupStore) LoadById(
 
 	statement := `
 		SELECT
-			id, action, anomaly_ids, culprit_ids, group_meta_data->>'subscription_name', group_meta_data->>'subscription_revision'
+			id, action, anomaly_ids, culprit_ids, group_meta_data->>'subscription_name', group_meta_data->>'subscription_revision', COALESCE(CAST(merged_into AS STRING), ''), COALESCE(CAST(bisection_id AS STRING), '')
 		FROM
 			AnomalyGroups
 		WHERE
@@ -123,7 +125,9 @@ func (s *AnomalyGroupStore) LoadById(
 	var culprit_ids []string
 	var subscription_name string
 	var subscription_revision string
-	if err := s.db.QueryRow(ctx, statement, group_id).Scan(&loaded_group_id, &action, &anomaly_ids, &culprit_ids, &subscription_name, &subscription_revision); err != nil {
+	var merged_into string
+	var bisection_id string
+	if err := s.db.QueryRow(ctx, statement, group_id).Scan(&loaded_group_id, &action, &anomaly_ids, &culprit_ids, &subscription_name, &subscription_revision, &merged_into, &bisection_id); err != nil {
 		err_msg := fmt.Sprintf("failed to load the anomaly group: %s", group_id)
 		return nil, skerr.Wrapf(err, err_msg)
 	}
@@ -135,6 +139,8 @@ func (s *AnomalyGroupStore) LoadById(
 		CulpritIds:           culprit_ids,
 		SubsciptionName:      subscription_name,
 		SubscriptionRevision: subscription_revision,
+		MergedInto:           merged_into,
+		BisectionId:          bisection_id,
 	}, nil
 }
 
@@ -149,7 +155,8 @@ func (s *AnomalyGroupStore) UpdateBisectID(ctx context.Context, group_id string,
 		UPDATE
 			AnomalyGroups
 		SET
-			bisection_id=$1
+			bisection_id=$1,
+			action_time=CASE WHEN $1='' THEN action_time ELSE COALESCE(action_time, now()) END
 		WHERE
 			id=$2
 	`
@@ -172,7 +179,8 @@ func (s *AnomalyGroupStore) UpdateReportedIssueID(ctx context.Context, group_id
 		UPDATE
 			AnomalyGroups
 		SET
-			reported_issue_id=$1
+			reported_issue_id=$1,
+			action_time=CASE WHEN $1='' THEN action_time ELSE COALESCE(action_time, now()) END
 		WHERE
 			id=$2
 	`
@@ -255,6 +263,7 @@ func (s *AnomalyGroupStore) FindExistingGroup(
 			AND JSON_EXTRACT_PATH_TEXT(group_meta_data, 'benchmark_name')=$5
 			AND common_rev_start<=$6
 			AND common_rev_end>=$7
+			AND merged_into IS NULL
 	`
 
 	rows, err := s.db.Query(ctx, statement,
@@ -289,3 +298,311 @@ func (s *AnomalyGroupStore) FindExistingGroup(
 	}
 	return groups, nil
 }
+
+// groupToChange is the part of an anomaly group read when merging or
+// splitting it.
+type groupToChange struct {
+	action      string
+	anomalyIDs  []string
+	culpritIDs  []string
+	startCommit int64
+	endCommit   int64
+}
+
+// loadGroupToChange reads the group with the given id inside a transaction,
+// and fails if the group has already been merged into another group.
+func loadGroupToChange(ctx context.Context, tx pgx.Tx, group_id string) (*groupToChange, error) {
+	statement := `
+		SELECT
+			action, anomaly_ids, culprit_ids, common_rev_start, common_rev_end, merged_into IS NOT NULL
+		FROM
+			AnomalyGroups
+		WHERE
+			id=$1
+		`
+	var ret groupToChange
+	var merged bool
+	if err := tx.QueryRow(ctx, statement, group_id).Scan(&ret.action, &ret.anomalyIDs, &ret.culpritIDs, &ret.startCommit, &ret.endCommit, &merged); err != nil {
+		return nil, skerr.Wrapf(err, "failed to load the anomaly group: %s", group_id)
+	}
+	if merged {
+		return nil, skerr.Fmt("anomaly group %s has already been merged into another group", group_id)
+	}
+	return &ret, nil
+}
+
+// commitRange returns the commit range shared by the given anomalies, which
+// is the commit range of a group holding just those anomalies. The anomalies
+// are read from the Regressions2 table, and if any of them can't be found
+// there then start and end are returned unchanged, so they must be a range
+// shared by all of the anomalies, such as the range of a group holding them.
+func commitRange(ctx context.Context, tx pgx.Tx, anomaly_ids []string, start, end int64) (int64, int64, error) {
+	distinct := map[string]bool{}
+	for _, anomaly_id := range anomaly_ids {
+		distinct[anomaly_id] = true
+	}
+	statement := `
+		SELECT
+			COUNT(*), COALESCE(MAX(prev_commit_number), 0), COALESCE(MIN(commit_number), 0)
+		FROM
+			Regressions2
+		WHERE
+			id=ANY($1)
+		`
+	var found int
+	var anomaliesStart, anomaliesEnd int64
+	if err := tx.QueryRow(ctx, statement, anomaly_ids).Scan(&found, &anomaliesStart, &anomaliesEnd); err != nil {
+		return 0, 0, skerr.Wrapf(err, "failed to load the commit ranges of anomalies %v", anomaly_ids)
+	}
+	if found != len(distinct) {
+		return start, end, nil
+	}
+	return anomaliesStart, anomaliesEnd, nil
+}
+
+// addHistory records a merge or split in the history of the given group.
+func addHistory(ctx context.Context, tx pgx.Tx, group_id string, change_type pb.GroupChangeType, related_group_ids []string, anomaly_ids []string, reason string) error {
+	statement := `
+		INSERT INTO
+			AnomalyGroupHistory (group_id, change_type, related_group_ids, anomaly_ids, reason)
+		VALUES
+			($1, $2, $3, $4, $5)
+		`
+	if _, err := tx.Exec(ctx, statement, group_id, change_type.String(), related_group_ids, anomaly_ids, reason); err != nil {
+		return skerr.Wrapf(err, "failed to record the history of anomaly group %s", group_id)
+	}
+	return nil
+}
+
+// appendMissing appends the values not already in dst to dst.
+func appendMissing(dst []string, values []string) []string {
+	for _, v := range values {
+		found := false
+		for _, d := range dst {
+			if d == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
+
+func (s *AnomalyGroupStore) MergeGroups(ctx context.Context, target_group_id string, source_group_ids []string, reason string) (*pb.AnomalyGroup, error) {
+	// Sanity checks
+	if len(source_group_ids) == 0 {
+		return nil, skerr.Fmt("no anomaly groups to merge into %s", target_group_id)
+	}
+	for _, group_id := range append([]string{target_group_id}, source_group_ids...) {
+		if _, err := uuid.Parse(group_id); err != nil {
+			return nil, skerr.Wrapf(err, "group id is not a valid uuid: %s.", group_id)
+		}
+	}
+	for i, group_id := range source_group_ids {
+		if group_id == target_group_id {
+			return nil, skerr.Fmt("can't merge anomaly group %s into itself", group_id)
+		}
+		for _, other := range source_group_ids[:i] {
+			if group_id == other {
+				return nil, skerr.Fmt("anomaly group %s is merged more than once", group_id)
+			}
+		}
+	}
+
+	err := s.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		target, err := loadGroupToChange(ctx, tx, target_group_id)
+		if err != nil {
+			return err
+		}
+		anomaly_ids := target.anomalyIDs
+		culprit_ids := target.culpritIDs
+		start, end := target.startCommit, target.endCommit
+		moved := []string{}
+		sources := map[string]*groupToChange{}
+		for _, group_id := range source_group_ids {
+			source, err := loadGroupToChange(ctx, tx, group_id)
+			if err != nil {
+				return err
+			}
+			if source.action != target.action {
+				return skerr.Fmt("can't merge anomaly group %s with action %s into anomaly group %s with action %s", group_id, source.action, target_group_id, target.action)
+			}
+			// The commit range of a group is the range shared by all of its
+			// anomalies.
+			if source.startCommit > start {
+				start = source.startCommit
+			}
+			if source.endCommit < end {
+				end = source.endCommit
+			}
+			anomaly_ids = appendMissing(anomaly_ids, source.anomalyIDs)
+			culprit_ids = appendMissing(culprit_ids, source.culpritIDs)
+			moved = append(moved, source.anomalyIDs...)
+			sources[group_id] = source
+		}
+		if start > end {
+			return skerr.Fmt("the commit ranges of anomaly groups %s and %v don't overlap", target_group_id, source_group_ids)
+		}
+
+		statement := `
+			UPDATE
+				AnomalyGroups
+			SET
+				anomaly_ids=$1, culprit_ids=$2, common_rev_start=$3, common_rev_end=$4, last_modified_time=now()
+			WHERE
+				id=$5
+		`
+		if _, err := tx.Exec(ctx, statement, anomaly_ids, culprit_ids, start, end, target_group_id); err != nil {
+			return skerr.Wrapf(err, "failed to update anomaly group %s", target_group_id)
+		}
+		statement = `
+			UPDATE
+				AnomalyGroups
+			SET
+				anomaly_ids=ARRAY[]::UUID[], merged_into=$1, last_modified_time=now()
+			WHERE
+				id=ANY($2)
+		`
+		if _, err := tx.Exec(ctx, statement, target_group_id, source_group_ids); err != nil {
+			return skerr.Wrapf(err, "failed to mark anomaly groups %v as merged", source_group_ids)
+		}
+
+		if err := addHistory(ctx, tx, target_group_id, pb.GroupChangeType_MERGE, source_group_ids, moved, reason); err != nil {
+			return err
+		}
+		for _, group_id := range source_group_ids {
+			if err := addHistory(ctx, tx, group_id, pb.GroupChangeType_MERGE, []string{target_group_id}, sources[group_id].anomalyIDs, reason); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, skerr.Wrapf(err, "failed to merge anomaly groups %v into %s", source_group_ids, target_group_id)
+	}
+	return s.LoadById(ctx, target_group_id)
+}
+
+func (s *AnomalyGroupStore) SplitGroup(ctx context.Context, group_id string, anomaly_ids []string, reason string) (string, error) {
+	// Sanity checks
+	if _, err := uuid.Parse(group_id); err != nil {
+		return "", skerr.Wrapf(err, "group id is not a valid uuid: %s.", group_id)
+	}
+	if len(anomaly_ids) == 0 {
+		return "", skerr.Fmt("no anomalies to split off anomaly group %s", group_id)
+	}
+
+	new_group_id := ""
+	err := s.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		group, err := loadGroupToChange(ctx, tx, group_id)
+		if err != nil {
+			return err
+		}
+		toMove := map[string]bool{}
+		for _, anomaly_id := range anomaly_ids {
+			toMove[anomaly_id] = true
+		}
+		remaining := []string{}
+		for _, anomaly_id := range group.anomalyIDs {
+			if toMove[anomaly_id] {
+				delete(toMove, anomaly_id)
+			} else {
+				remaining = append(remaining, anomaly_id)
+			}
+		}
+		if len(toMove) > 0 {
+			return skerr.Fmt("anomalies %v are not in anomaly group %s", anomaly_ids, group_id)
+		}
+		if len(remaining) == 0 {
+			return skerr.Fmt("can't split all the anomalies off anomaly group %s", group_id)
+		}
+
+		// Each group gets the range shared by the anomalies it ends up with,
+		// which can be wider than the range of the original group.
+		newStart, newEnd, err := commitRange(ctx, tx, anomaly_ids, group.startCommit, group.endCommit)
+		if err != nil {
+			return err
+		}
+		start, end, err := commitRange(ctx, tx, remaining, group.startCommit, group.endCommit)
+		if err != nil {
+			return err
+		}
+
+		statement := `
+			INSERT INTO
+				AnomalyGroups (anomaly_ids, group_meta_data, common_rev_start, common_rev_end, action)
+			SELECT
+				$1, group_meta_data, $2, $3, action
+			FROM
+				AnomalyGroups
+			WHERE
+				id=$4
+			RETURNING
+				id
+		`
+		if err := tx.QueryRow(ctx, statement, anomaly_ids, newStart, newEnd, group_id).Scan(&new_group_id); err != nil {
+			return skerr.Wrapf(err, "failed to create the anomaly group split off %s", group_id)
+		}
+		statement = `
+			UPDATE
+				AnomalyGroups
+			SET
+				anomaly_ids=$1, common_rev_start=$2, common_rev_end=$3, last_modified_time=now()
+			WHERE
+				id=$4
+		`
+		if _, err := tx.Exec(ctx, statement, remaining, start, end, group_id); err != nil {
+			return skerr.Wrapf(err, "failed to update anomaly group %s", group_id)
+		}
+
+		if err := addHistory(ctx, tx, group_id, pb.GroupChangeType_SPLIT, []string{new_group_id}, anomaly_ids, reason); err != nil {
+			return err
+		}
+		return addHistory(ctx, tx, new_group_id, pb.GroupChangeType_SPLIT, []string{group_id}, anomaly_ids, reason)
+	})
+	if err != nil {
+		return "", skerr.Wrapf(err, "failed to split anomaly group %s", group_id)
+	}
+	return new_group_id, nil
+}
+
+func (s *AnomalyGroupStore) LoadHistory(ctx context.Context, group_id string) ([]*pb.AnomalyGroupChange, error) {
+	// Sanity checks
+	if _, err := uuid.Parse(group_id); err != nil {
+		return nil, skerr.Wrapf(err, "group id is not a valid uuid: %s.", group_id)
+	}
+
+	statement := `
+		SELECT
+			change_type, related_group_ids, anomaly_ids, reason, create_time
+		FROM
+			AnomalyGroupHistory
+		WHERE
+			group_id=$1
+		ORDER BY
+			create_time, id
+	`
+	rows, err := s.db.Query(ctx, statement, group_id)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "failed to load the history of anomaly group %s", group_id)
+	}
+	defer rows.Close()
+	changes := []*pb.AnomalyGroupChange{}
+	for rows.Next() {
+		var change_type string
+		var create_time time.Time
+		change := &pb.AnomalyGroupChange{
+			GroupId: group_id,
+		}
+		if err := rows.Scan(&change_type, &change.RelatedGroupIds, &change.AnomalyIds, &change.Reason, &create_time); err != nil {
+			return nil, skerr.Wrapf(err, "failed to read the history of anomaly group %s", group_id)
+		}
+		change.ChangeType = pb.GroupChangeType(pb.GroupChangeType_value[change_type])
+		change.CreateTime = create_time.Unix()
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
@@ -3,6 +3,7 @@ package sqlanomalygroupstore
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -167,6 +168,31 @@ func TestUpdateReportedIssueID(t *testing.T) {
 	assert.Equal(t, "REPORT", group.GroupAction.String())
 }
 
+func TestUpdateReportedIssueID_ActionTimeIsOnlySetOnce(t *testing.T) {
+	store, db := setUp(t)
+	ctx := context.Background()
+
+	new_group_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-a", 100, 200, "REPORT")
+	require.NoError(t, err)
+
+	readActionTime := func() *time.Time {
+		var actionTime *time.Time
+		err := db.QueryRow(ctx, "SELECT action_time FROM AnomalyGroups WHERE id=$1", new_group_id).Scan(&actionTime)
+		require.NoError(t, err)
+		return actionTime
+	}
+	assert.Nil(t, readActionTime())
+
+	err = store.UpdateReportedIssueID(ctx, new_group_id, "24fa5591-946b-44e4-bf09-3fd271588ee5")
+	require.NoError(t, err)
+	first := readActionTime()
+	require.NotNil(t, first)
+
+	err = store.UpdateReportedIssueID(ctx, new_group_id, "35fa5591-946b-44e4-bf09-3fd271588ee5")
+	require.NoError(t, err)
+	assert.Equal(t, first, readActionTime())
+}
+
 func TestUpdateReportedIssueID_InvalidID(t *testing.T) {
 	store, _ := setUp(t)
 	ctx := context.Background()
@@ -330,3 +356,204 @@ func TestFindGroup_InvalidCommit(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid params")
 }
+
+func TestMergeGroups(t *testing.T) {
+	store, _ := setUp(t)
+	ctx := context.Background()
+
+	target_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-a", 100, 200, "BISECT")
+	require.NoError(t, err)
+	source_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-b", 150, 250, "BISECT")
+	require.NoError(t, err)
+	require.NoError(t, store.AddAnomalyID(ctx, target_id, "b1fb4036-1883-4d9e-85d4-ed607629017a"))
+	require.NoError(t, store.AddAnomalyID(ctx, source_id, "a60414c6-2495-4ef7-834a-829b1a929100"))
+	require.NoError(t, store.AddCulpritIDs(ctx, source_id, []string{"ffd48105-ce5a-425e-982a-fb4221c46f21"}))
+
+	merged, err := store.MergeGroups(ctx, target_id, []string{source_id}, "same culprit")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"b1fb4036-1883-4d9e-85d4-ed607629017a",
+		"a60414c6-2495-4ef7-834a-829b1a929100"}, merged.AnomalyIds)
+	assert.Equal(t, []string{"ffd48105-ce5a-425e-982a-fb4221c46f21"}, merged.CulpritIds)
+
+	source, err := store.LoadById(ctx, source_id)
+	require.NoError(t, err)
+	assert.Empty(t, source.AnomalyIds)
+	assert.Equal(t, target_id, source.MergedInto)
+
+	// The merged group is no longer found for new anomalies, and the target
+	// group now only covers the commits shared by both groups.
+	groups, err := store.FindExistingGroup(ctx, "sub", "rev-abc", "domain-a", "benchmark-b", 150, 250, "BISECT")
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+	groups, err = store.FindExistingGroup(ctx, "sub", "rev-abc", "domain-a", "benchmark-a", 120, 140, "BISECT")
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	history, err := store.LoadHistory(ctx, target_id)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "MERGE", history[0].ChangeType.String())
+	assert.Equal(t, []string{source_id}, history[0].RelatedGroupIds)
+	assert.Equal(t, []string{"a60414c6-2495-4ef7-834a-829b1a929100"}, history[0].AnomalyIds)
+	assert.Equal(t, "same culprit", history[0].Reason)
+
+	history, err = store.LoadHistory(ctx, source_id)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, []string{target_id}, history[0].RelatedGroupIds)
+}
+
+func TestMergeGroups_AlreadyMerged(t *testing.T) {
+	store, _ := setUp(t)
+	ctx := context.Background()
+
+	target_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-a", 100, 200, "BISECT")
+	require.NoError(t, err)
+	source_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-b", 100, 200, "BISECT")
+	require.NoError(t, err)
+	_, err = store.MergeGroups(ctx, target_id, []string{source_id}, "")
+	require.NoError(t, err)
+
+	_, err = store.MergeGroups(ctx, target_id, []string{source_id}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already been merged")
+}
+
+func TestMergeGroups_DisjointRanges(t *testing.T) {
+	store, _ := setUp(t)
+	ctx := context.Background()
+
+	target_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-a", 100, 200, "BISECT")
+	require.NoError(t, err)
+	source_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-b", 300, 400, "BISECT")
+	require.NoError(t, err)
+
+	_, err = store.MergeGroups(ctx, target_id, []string{source_id}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "don't overlap")
+}
+
+func TestMergeGroups_DifferentActions(t *testing.T) {
+	store, _ := setUp(t)
+	ctx := context.Background()
+
+	target_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-a", 100, 200, "BISECT")
+	require.NoError(t, err)
+	source_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-b", 100, 200, "REPORT")
+	require.NoError(t, err)
+
+	_, err = store.MergeGroups(ctx, target_id, []string{source_id}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "with action")
+}
+
+func TestSplitGroup(t *testing.T) {
+	store, _ := setUp(t)
+	ctx := context.Background()
+
+	group_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-a", 100, 200, "REPORT")
+	require.NoError(t, err)
+	require.NoError(t, store.AddAnomalyID(ctx, group_id, "b1fb4036-1883-4d9e-85d4-ed607629017a"))
+	require.NoError(t, store.AddAnomalyID(ctx, group_id, "a60414c6-2495-4ef7-834a-829b1a929100"))
+
+	new_group_id, err := store.SplitGroup(ctx, group_id, []string{"a60414c6-2495-4ef7-834a-829b1a929100"}, "wrong benchmark")
+	require.NoError(t, err)
+
+	group, err := store.LoadById(ctx, group_id)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b1fb4036-1883-4d9e-85d4-ed607629017a"}, group.AnomalyIds)
+	new_group, err := store.LoadById(ctx, new_group_id)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a60414c6-2495-4ef7-834a-829b1a929100"}, new_group.AnomalyIds)
+	assert.Equal(t, "REPORT", new_group.GroupAction.String())
+	assert.Equal(t, "sub", new_group.SubsciptionName)
+
+	history, err := store.LoadHistory(ctx, new_group_id)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "SPLIT", history[0].ChangeType.String())
+	assert.Equal(t, []string{group_id}, history[0].RelatedGroupIds)
+	assert.Equal(t, "wrong benchmark", history[0].Reason)
+}
+
+func TestSplitGroup_RangesRecomputedFromAnomalies(t *testing.T) {
+	store, db := setUp(t)
+	ctx := context.Background()
+
+	const stayingID = "b1fb4036-1883-4d9e-85d4-ed607629017a"
+	const movedID = "a60414c6-2495-4ef7-834a-829b1a929100"
+	_, err := db.Exec(ctx, `
+		INSERT INTO Regressions2 (id, prev_commit_number, commit_number, alert_id) VALUES
+			($1, 100, 200, 1),
+			($2, 150, 250, 1)
+		`, stayingID, movedID)
+	require.NoError(t, err)
+
+	// The group covers the range shared by both anomalies.
+	group_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-a", 150, 200, "REPORT")
+	require.NoError(t, err)
+	require.NoError(t, store.AddAnomalyID(ctx, group_id, stayingID))
+	require.NoError(t, store.AddAnomalyID(ctx, group_id, movedID))
+
+	new_group_id, err := store.SplitGroup(ctx, group_id, []string{movedID}, "wrong benchmark")
+	require.NoError(t, err)
+
+	commitRangeOf := func(id string) (int64, int64) {
+		var start, end int64
+		err := db.QueryRow(ctx, "SELECT common_rev_start, common_rev_end FROM AnomalyGroups WHERE id=$1", id).Scan(&start, &end)
+		require.NoError(t, err)
+		return start, end
+	}
+	start, end := commitRangeOf(group_id)
+	assert.Equal(t, int64(100), start)
+	assert.Equal(t, int64(200), end)
+	start, end = commitRangeOf(new_group_id)
+	assert.Equal(t, int64(150), start)
+	assert.Equal(t, int64(250), end)
+}
+
+func TestSplitGroup_AnomaliesNotFound_RangesUnchanged(t *testing.T) {
+	store, db := setUp(t)
+	ctx := context.Background()
+
+	group_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-a", 100, 200, "REPORT")
+	require.NoError(t, err)
+	require.NoError(t, store.AddAnomalyID(ctx, group_id, "b1fb4036-1883-4d9e-85d4-ed607629017a"))
+	require.NoError(t, store.AddAnomalyID(ctx, group_id, "a60414c6-2495-4ef7-834a-829b1a929100"))
+
+	new_group_id, err := store.SplitGroup(ctx, group_id, []string{"a60414c6-2495-4ef7-834a-829b1a929100"}, "")
+	require.NoError(t, err)
+
+	var start, end int64
+	err = db.QueryRow(ctx, "SELECT common_rev_start, common_rev_end FROM AnomalyGroups WHERE id=$1", new_group_id).Scan(&start, &end)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), start)
+	assert.Equal(t, int64(200), end)
+}
+
+func TestSplitGroup_AnomalyNotInGroup(t *testing.T) {
+	store, _ := setUp(t)
+	ctx := context.Background()
+
+	group_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-a", 100, 200, "REPORT")
+	require.NoError(t, err)
+	require.NoError(t, store.AddAnomalyID(ctx, group_id, "b1fb4036-1883-4d9e-85d4-ed607629017a"))
+
+	_, err = store.SplitGroup(ctx, group_id, []string{"a60414c6-2495-4ef7-834a-829b1a929100"}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "are not in anomaly group")
+}
+
+func TestSplitGroup_AllAnomalies(t *testing.T) {
+	store, _ := setUp(t)
+	ctx := context.Background()
+
+	group_id, err := store.Create(ctx, "sub", "rev-abc", "domain-a", "benchmark-a", 100, 200, "REPORT")
+	require.NoError(t, err)
+	require.NoError(t, store.AddAnomalyID(ctx, group_id, "b1fb4036-1883-4d9e-85d4-ed607629017a"))
+
+	_, err = store.SplitGroup(ctx, group_id, []string{"b1fb4036-1883-4d9e-85d4-ed607629017a"}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "all the anomalies")
+}
@@ -54,4 +54,20 @@ type Store interface {
 	// Example use case: when an auto bisection job finished with
 	// culprit(s) detected.
 	AddCulpritIDs(ctx context.Context, group_id string, culprit_ids []string) error
+
+	// Merge the anomalies and culprits of the source groups into the target
+	// group, and mark the source groups as merged into it. Returns the
+	// target group after the merge.
+	// Example use case: when groups turn out to share a culprit.
+	MergeGroups(ctx context.Context, target_group_id string, source_group_ids []string, reason string) (*pb.AnomalyGroup, error)
+
+	// Move anomalies out of an anomaly group into a new group with the same
+	// properties, except that the commit range of each group is recomputed
+	// from the anomalies it ends up with. Returns the ID of the new group.
+	// Example use case: when anomalies were wrongly grouped together.
+	SplitGroup(ctx context.Context, group_id string, anomaly_ids []string, reason string) (string, error)
+
+	// Load the merges and splits an anomaly group took part in, oldest
+	// first.
+	LoadHistory(ctx context.Context, group_id string) ([]*pb.AnomalyGroupChange, error)
 }
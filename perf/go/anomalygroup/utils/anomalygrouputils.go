@@ -6,11 +6,14 @@ import (
 	"sync"
 	"time"
 
+	"go.skia.org/infra/go/query"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/sklog"
 	"go.skia.org/infra/perf/go/alerts"
 	ag "go.skia.org/infra/perf/go/anomalygroup/proto/v1"
 	backend "go.skia.org/infra/perf/go/backend/client"
+	"go.skia.org/infra/perf/go/tracestore"
+	"go.skia.org/infra/perf/go/types"
 )
 
 var groupingMutex sync.Mutex
@@ -19,12 +22,75 @@ type AnomalyGrouper interface {
 	ProcessRegressionInGroup(ctx context.Context, alert *alerts.Alert, anomalyID string, startCommit int64, endCommit int64, testPath string, paramSet map[string]string) (string, error) //
 }
 
-type AnomalyGrouperImpl struct{}
+type AnomalyGrouperImpl struct {
+	// BisectBatcher, if set, queues the groups of BISECT alerts to be
+	// bisected in batches.
+	BisectBatcher *BisectBatcher
+
+	// TraceStore is used to look up the improvement direction of the traces
+	// whose anomalies are bisected. Must be set if BisectBatcher is set.
+	TraceStore tracestore.TraceStore
+}
 
 // implementation of ProcessRegressionInGroup for the AnomalyGrouper interface.
 func (a *AnomalyGrouperImpl) ProcessRegressionInGroup(
 	ctx context.Context, alert *alerts.Alert, anomalyID string, startCommit int64, endCommit int64, testPath string, paramSet map[string]string) (string, error) {
-	return ProcessRegression(ctx, alert, anomalyID, startCommit, endCommit, testPath, paramSet)
+	groupIDs, err := ProcessRegression(ctx, alert, anomalyID, startCommit, endCommit, testPath, paramSet)
+	if err != nil {
+		return "", err
+	}
+	if a.BisectBatcher != nil && alert.Action == types.Bisection && groupIDs != "" {
+		improvementDirection, err := a.improvementDirection(ctx, paramSet)
+		if err != nil {
+			return "", err
+		}
+		for _, groupID := range strings.Split(groupIDs, ",") {
+			a.BisectBatcher.Add(BisectCandidate{
+				GroupID: groupID,
+				Anomaly: bisectAnomaly(startCommit, endCommit, paramSet, improvementDirection),
+			})
+		}
+	}
+	return groupIDs, nil
+}
+
+// improvementDirection returns the improvement direction stored with the
+// trace with the given params, or "" if it isn't known.
+func (a *AnomalyGrouperImpl) improvementDirection(ctx context.Context, paramSet map[string]string) (string, error) {
+	traceName, err := query.MakeKey(paramSet)
+	if err != nil {
+		return "", skerr.Wrapf(err, "invalid trace params %v", paramSet)
+	}
+	metadata, err := a.TraceStore.ReadTraceMetadata(ctx, []string{traceName})
+	if err != nil {
+		return "", skerr.Wrapf(err, "reading metadata of trace %q", traceName)
+	}
+	return metadata[traceName].ImprovementDirection, nil
+}
+
+// bisectAnomaly returns the anomaly to bisect for a regression found in the
+// trace with the given params and improvement direction.
+func bisectAnomaly(startCommit int64, endCommit int64, paramSet map[string]string, improvementDirection string) *ag.Anomaly {
+	// The story is the last of the subtest_x params.
+	story := ""
+	for _, key := range []string{"subtest_3", "subtest_2", "subtest_1"} {
+		if value, ok := paramSet[key]; ok {
+			story = value
+			break
+		}
+	}
+	return &ag.Anomaly{
+		StartCommit: startCommit,
+		EndCommit:   endCommit,
+		Paramset: map[string]string{
+			"bot":         paramSet["bot"],
+			"benchmark":   paramSet["benchmark"],
+			"story":       story,
+			"measurement": paramSet["test"],
+			"stat":        paramSet["stat"],
+		},
+		ImprovementDirection: improvementDirection,
+	}
 }
 
 // Process the regression with the following steps:
@@ -81,6 +147,7 @@ func ProcessRegression(
 			return "", skerr.Wrapf(err, "error finding existing group for new anomaly")
 		}
 		sklog.Info("Created new anomaly group: %s", newGroupID)
+		resp.AnomalyGroups = []*ag.AnomalyGroup{{GroupId: newGroupID.AnomalyGroupId}}
 		// TODO(wenbinzhang): Update on create in one step.
 		_, err = ag_client.UpdateAnomalyGroup(
 			ctx,
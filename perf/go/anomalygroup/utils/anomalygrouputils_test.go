@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/tracestore"
+	traceStoreMocks "go.skia.org/infra/perf/go/tracestore/mocks"
+)
+
+var testParams = map[string]string{
+	"master":    "ChromiumPerf",
+	"bot":       "linux-perf",
+	"benchmark": "speedometer2",
+	"test":      "RunsPerMinute",
+	"subtest_1": "Speedometer2",
+}
+
+const testTraceName = ",benchmark=speedometer2,bot=linux-perf,master=ChromiumPerf,subtest_1=Speedometer2,test=RunsPerMinute,"
+
+func TestImprovementDirection_TraceHasMetadata_ReturnsStoredDirection(t *testing.T) {
+	ctx := context.Background()
+	store := &traceStoreMocks.TraceStore{}
+	store.On("ReadTraceMetadata", ctx, []string{testTraceName}).Return(map[string]tracestore.TraceMetadata{
+		testTraceName: {Unit: "score", ImprovementDirection: "up"},
+	}, nil)
+
+	direction, err := (&AnomalyGrouperImpl{TraceStore: store}).improvementDirection(ctx, testParams)
+	require.NoError(t, err)
+	assert.Equal(t, "up", direction)
+}
+
+func TestImprovementDirection_TraceHasNoMetadata_ReturnsEmptyDirection(t *testing.T) {
+	ctx := context.Background()
+	store := &traceStoreMocks.TraceStore{}
+	store.On("ReadTraceMetadata", ctx, []string{testTraceName}).Return(map[string]tracestore.TraceMetadata{}, nil)
+
+	direction, err := (&AnomalyGrouperImpl{TraceStore: store}).improvementDirection(ctx, testParams)
+	require.NoError(t, err)
+	assert.Equal(t, "", direction)
+}
+
+func TestImprovementDirection_ReadFails_ReturnsError(t *testing.T) {
+	ctx := context.Background()
+	store := &traceStoreMocks.TraceStore{}
+	store.On("ReadTraceMetadata", ctx, []string{testTraceName}).Return(nil, errors.New("my error"))
+
+	_, err := (&AnomalyGrouperImpl{TraceStore: store}).improvementDirection(ctx, testParams)
+	require.Error(t, err)
+}
+
+func TestBisectAnomaly_UsesGivenImprovementDirection(t *testing.T) {
+	anomaly := bisectAnomaly(10, 20, testParams, "down")
+	assert.Equal(t, "down", anomaly.ImprovementDirection)
+	assert.Equal(t, "Speedometer2", anomaly.Paramset["story"])
+	assert.Equal(t, "RunsPerMinute", anomaly.Paramset["measurement"])
+}
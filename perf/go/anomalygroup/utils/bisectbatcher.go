@@ -0,0 +1,316 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/go/util"
+	ag "go.skia.org/infra/perf/go/anomalygroup/proto/v1"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/pinpoint"
+	"go.skia.org/infra/perf/go/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// BisectBatchPolicy controls how anomaly groups waiting to be bisected are
+// coalesced into bisect requests.
+type BisectBatchPolicy struct {
+	// MaxCommitRange is the widest commit range, end minus start, a single
+	// bisect request may cover. Groups that would widen a batch past it start
+	// a new batch. Zero means no limit.
+	MaxCommitRange int64
+}
+
+// BisectCandidate is an anomaly group waiting to be bisected.
+type BisectCandidate struct {
+	// GroupID is the ID of the anomaly group.
+	GroupID string
+
+	// Anomaly is the anomaly to bisect for the group. Its commit range is
+	// the range the group is bisected over.
+	Anomaly *ag.Anomaly
+}
+
+// BisectBatch is a set of anomaly groups covered by a single bisect request.
+type BisectBatch struct {
+	// GroupIDs are the IDs of the anomaly groups in the batch.
+	GroupIDs []string
+
+	// StartCommit and EndCommit are the commit range to bisect, which covers
+	// the commit ranges of all of the groups in the batch.
+	StartCommit int64
+	EndCommit   int64
+
+	// Anomaly is the anomaly that is bisected, which is the anomaly of the
+	// first group added to the batch.
+	Anomaly *ag.Anomaly
+}
+
+// bisectConfig returns a key that is the same for two anomalies only if they
+// are bisected with the same configuration, i.e. the same bot, benchmark,
+// story, measurement and statistic.
+func bisectConfig(anomaly *ag.Anomaly) string {
+	keys := make([]string, 0, len(anomaly.Paramset))
+	for key := range anomaly.Paramset {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s,", key, anomaly.Paramset[key])
+	}
+	return b.String()
+}
+
+// Coalesce groups the candidates with the same bisect configuration and
+// overlapping commit ranges into batches, so that each batch can be bisected
+// with a single request. Candidates are expected in the order they were
+// found, and each batch bisects the anomaly of its earliest candidate.
+func (p BisectBatchPolicy) Coalesce(candidates []BisectCandidate) []BisectBatch {
+	configs := make([]string, len(candidates))
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+		configs[i] = bisectConfig(candidates[i].Anomaly)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if configs[order[i]] != configs[order[j]] {
+			return configs[order[i]] < configs[order[j]]
+		}
+		return candidates[order[i]].Anomaly.StartCommit < candidates[order[j]].Anomaly.StartCommit
+	})
+
+	ret := []BisectBatch{}
+	// first is the index of the earliest candidate in the current batch.
+	first := -1
+	for _, i := range order {
+		c := candidates[i]
+		if len(ret) > 0 {
+			last := &ret[len(ret)-1]
+			end := last.EndCommit
+			if c.Anomaly.EndCommit > end {
+				end = c.Anomaly.EndCommit
+			}
+			sameConfig := configs[i] == configs[first]
+			overlaps := c.Anomaly.StartCommit <= last.EndCommit
+			fits := p.MaxCommitRange <= 0 || end-last.StartCommit <= p.MaxCommitRange
+			if sameConfig && overlaps && fits {
+				last.GroupIDs = append(last.GroupIDs, c.GroupID)
+				last.EndCommit = end
+				if i < first {
+					first = i
+					last.Anomaly = c.Anomaly
+				}
+				continue
+			}
+		}
+		first = i
+		ret = append(ret, BisectBatch{
+			GroupIDs:    []string{c.GroupID},
+			StartCommit: c.Anomaly.StartCommit,
+			EndCommit:   c.Anomaly.EndCommit,
+			Anomaly:     c.Anomaly,
+		})
+	}
+	return ret
+}
+
+// Bisector launches a bisect job for a batch of anomaly groups.
+type Bisector interface {
+	// Bisect launches a bisect job and returns its ID.
+	Bisect(ctx context.Context, batch BisectBatch) (string, error)
+}
+
+// BisectBatcher collects the anomaly groups that need to be bisected, and
+// periodically launches a single bisect job for each batch of groups with the
+// same bisect configuration and overlapping commit ranges, instead of one job
+// per group.
+//
+// The queue is only held in memory, so groups that are queued when the
+// process exits are never bisected, and are left without a bisection
+// recorded on them.
+type BisectBatcher struct {
+	policy   BisectBatchPolicy
+	bisector Bisector
+	client   ag.AnomalyGroupServiceClient
+
+	// mutex protects pending.
+	mutex   sync.Mutex
+	pending []BisectCandidate
+
+	batchesBisected metrics2.Counter
+	groupsBisected  metrics2.Counter
+	failures        metrics2.Counter
+}
+
+// NewBisectBatcher returns a new *BisectBatcher that records the bisect job
+// IDs on the anomaly groups through the given client.
+func NewBisectBatcher(policy BisectBatchPolicy, bisector Bisector, client ag.AnomalyGroupServiceClient) *BisectBatcher {
+	return &BisectBatcher{
+		policy:          policy,
+		bisector:        bisector,
+		client:          client,
+		batchesBisected: metrics2.GetCounter("perf_anomalygroup_bisect_batches"),
+		groupsBisected:  metrics2.GetCounter("perf_anomalygroup_bisect_groups"),
+		failures:        metrics2.GetCounter("perf_anomalygroup_bisect_failures"),
+	}
+}
+
+// Add queues an anomaly group to be bisected on the next Flush. If the group
+// is already queued then its commit range is narrowed to the range shared
+// with the new anomaly, the same way the group's own range is.
+func (b *BisectBatcher) Add(candidate BisectCandidate) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, c := range b.pending {
+		if c.GroupID != candidate.GroupID {
+			continue
+		}
+		if candidate.Anomaly.StartCommit > c.Anomaly.StartCommit && candidate.Anomaly.StartCommit <= c.Anomaly.EndCommit {
+			c.Anomaly.StartCommit = candidate.Anomaly.StartCommit
+		}
+		if candidate.Anomaly.EndCommit < c.Anomaly.EndCommit && candidate.Anomaly.EndCommit >= c.Anomaly.StartCommit {
+			c.Anomaly.EndCommit = candidate.Anomaly.EndCommit
+		}
+		return
+	}
+	// The queued anomaly is narrowed by later calls to Add, so don't share it
+	// with the caller.
+	candidate.Anomaly = proto.Clone(candidate.Anomaly).(*ag.Anomaly)
+	b.pending = append(b.pending, candidate)
+}
+
+// Flush launches a bisect job for each batch of the queued anomaly groups,
+// and records the job ID on every group in the batch. Groups that already
+// have a bisection are dropped, and groups whose batch fails are queued again
+// for the next Flush. Returns the batches that were bisected.
+func (b *BisectBatcher) Flush(ctx context.Context) ([]BisectBatch, error) {
+	b.mutex.Lock()
+	queued := b.pending
+	b.pending = nil
+	b.mutex.Unlock()
+
+	candidates := make([]BisectCandidate, 0, len(queued))
+	for _, c := range queued {
+		resp, err := b.client.LoadAnomalyGroupByID(ctx, &ag.LoadAnomalyGroupByIDRequest{
+			AnomalyGroupId: c.GroupID,
+		})
+		if err != nil {
+			// Bisecting the group again is better than not bisecting it.
+			sklog.Warningf("Failed to load anomaly group %s: %s", c.GroupID, err)
+		} else if resp.AnomalyGroup.GetBisectionId() != "" {
+			sklog.Infof("Anomaly group %s already has bisection %s", c.GroupID, resp.AnomalyGroup.GetBisectionId())
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+
+	byGroupID := map[string]BisectCandidate{}
+	for _, c := range candidates {
+		byGroupID[c.GroupID] = c
+	}
+
+	bisected := []BisectBatch{}
+	var firstErr error
+	for _, batch := range b.policy.Coalesce(candidates) {
+		if err := b.bisectBatch(ctx, batch); err != nil {
+			sklog.Errorf("Failed to bisect anomaly groups %v: %s", batch.GroupIDs, err)
+			b.failures.Inc(1)
+			if firstErr == nil {
+				firstErr = err
+			}
+			for _, groupID := range batch.GroupIDs {
+				b.Add(byGroupID[groupID])
+			}
+			continue
+		}
+		bisected = append(bisected, batch)
+	}
+	return bisected, firstErr
+}
+
+func (b *BisectBatcher) bisectBatch(ctx context.Context, batch BisectBatch) error {
+	jobID, err := b.bisector.Bisect(ctx, batch)
+	if err != nil {
+		return skerr.Wrapf(err, "Failed to launch bisection over commits [%d, %d]", batch.StartCommit, batch.EndCommit)
+	}
+	b.batchesBisected.Inc(1)
+	for _, groupID := range batch.GroupIDs {
+		_, err := b.client.UpdateAnomalyGroup(ctx, &ag.UpdateAnomalyGroupRequest{
+			AnomalyGroupId: groupID,
+			BisectionId:    jobID,
+		})
+		if err != nil {
+			// The job has been launched, so the group isn't queued again.
+			sklog.Errorf("Failed to record bisection %s on anomaly group %s: %s", jobID, groupID, err)
+			continue
+		}
+		b.groupsBisected.Inc(1)
+	}
+	sklog.Infof("Launched bisection %s for anomaly groups %v", jobID, batch.GroupIDs)
+	return nil
+}
+
+// Start flushes the queued anomaly groups every period, giving more
+// anomalies time to be grouped before bisecting, until the context is
+// cancelled.
+func (b *BisectBatcher) Start(ctx context.Context, period time.Duration) {
+	util.RepeatCtx(ctx, period, func(ctx context.Context) {
+		if _, err := b.Flush(ctx); err != nil {
+			sklog.Errorf("Failed to bisect anomaly groups: %s", err)
+		}
+	})
+}
+
+// CommitLookup finds the details of a commit. It is implemented by
+// perfgit.Git.
+type CommitLookup interface {
+	CommitFromCommitNumber(ctx context.Context, commitNumber types.CommitNumber) (provider.Commit, error)
+}
+
+// PinpointBisector is a Bisector that creates Pinpoint bisect jobs.
+type PinpointBisector struct {
+	client  *pinpoint.Client
+	commits CommitLookup
+}
+
+// NewPinpointBisector returns a new *PinpointBisector.
+func NewPinpointBisector(client *pinpoint.Client, commits CommitLookup) *PinpointBisector {
+	return &PinpointBisector{
+		client:  client,
+		commits: commits,
+	}
+}
+
+// Bisect implements Bisector.
+func (p *PinpointBisector) Bisect(ctx context.Context, batch BisectBatch) (string, error) {
+	start, err := p.commits.CommitFromCommitNumber(ctx, types.CommitNumber(batch.StartCommit))
+	if err != nil {
+		return "", skerr.Wrapf(err, "Failed to look up start commit %d", batch.StartCommit)
+	}
+	end, err := p.commits.CommitFromCommitNumber(ctx, types.CommitNumber(batch.EndCommit))
+	if err != nil {
+		return "", skerr.Wrapf(err, "Failed to look up end commit %d", batch.EndCommit)
+	}
+	resp, err := p.client.CreateBisect(ctx, pinpoint.CreateBisectRequest{
+		ComparisonMode: "performance",
+		StartGitHash:   start.GitHash,
+		EndGitHash:     end.GitHash,
+		Configuration:  batch.Anomaly.Paramset["bot"],
+		Benchmark:      batch.Anomaly.Paramset["benchmark"],
+		Story:          batch.Anomaly.Paramset["story"],
+		Chart:          batch.Anomaly.Paramset["measurement"],
+		Statistic:      batch.Anomaly.Paramset["stat"],
+	})
+	if err != nil {
+		return "", skerr.Wrap(err)
+	}
+	return resp.JobID, nil
+}
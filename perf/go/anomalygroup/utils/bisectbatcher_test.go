@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ag "go.skia.org/infra/perf/go/anomalygroup/proto/v1"
+	"google.golang.org/grpc"
+)
+
+func candidate(groupID string, start, end int64) BisectCandidate {
+	return candidateForBot(groupID, "linux-perf", start, end)
+}
+
+func candidateForBot(groupID, bot string, start, end int64) BisectCandidate {
+	return BisectCandidate{
+		GroupID: groupID,
+		Anomaly: &ag.Anomaly{
+			StartCommit: start,
+			EndCommit:   end,
+			Paramset: map[string]string{
+				"bot":       bot,
+				"benchmark": "speedometer2",
+				"story":     "Speedometer2",
+			},
+		},
+	}
+}
+
+func TestCoalesce_OverlappingRanges_OneBatch(t *testing.T) {
+	batches := BisectBatchPolicy{}.Coalesce([]BisectCandidate{
+		candidate("b", 150, 250),
+		candidate("a", 100, 200),
+		candidate("c", 240, 300),
+	})
+	require.Len(t, batches, 1)
+	assert.Equal(t, []string{"a", "b", "c"}, batches[0].GroupIDs)
+	assert.Equal(t, int64(100), batches[0].StartCommit)
+	assert.Equal(t, int64(300), batches[0].EndCommit)
+	// The anomaly of the first candidate found is bisected.
+	assert.Equal(t, int64(150), batches[0].Anomaly.StartCommit)
+}
+
+func TestCoalesce_OverlappingRangesOnDifferentBots_SeparateBatches(t *testing.T) {
+	batches := BisectBatchPolicy{}.Coalesce([]BisectCandidate{
+		candidateForBot("a", "linux-perf", 100, 200),
+		candidateForBot("b", "mac-m1-perf", 150, 250),
+		candidateForBot("c", "linux-perf", 180, 300),
+	})
+	require.Len(t, batches, 2)
+	assert.Equal(t, []string{"a", "c"}, batches[0].GroupIDs)
+	assert.Equal(t, "linux-perf", batches[0].Anomaly.Paramset["bot"])
+	assert.Equal(t, []string{"b"}, batches[1].GroupIDs)
+	assert.Equal(t, "mac-m1-perf", batches[1].Anomaly.Paramset["bot"])
+}
+
+func TestCoalesce_DisjointRanges_SeparateBatches(t *testing.T) {
+	batches := BisectBatchPolicy{}.Coalesce([]BisectCandidate{
+		candidate("a", 100, 200),
+		candidate("b", 201, 300),
+	})
+	require.Len(t, batches, 2)
+	assert.Equal(t, []string{"a"}, batches[0].GroupIDs)
+	assert.Equal(t, []string{"b"}, batches[1].GroupIDs)
+}
+
+func TestCoalesce_MaxCommitRange_StartsNewBatch(t *testing.T) {
+	batches := BisectBatchPolicy{MaxCommitRange: 150}.Coalesce([]BisectCandidate{
+		candidate("a", 100, 200),
+		candidate("b", 150, 250),
+		candidate("c", 240, 300),
+	})
+	require.Len(t, batches, 2)
+	assert.Equal(t, []string{"a", "b"}, batches[0].GroupIDs)
+	assert.Equal(t, []string{"c"}, batches[1].GroupIDs)
+	assert.Equal(t, int64(240), batches[1].StartCommit)
+}
+
+func TestCoalesce_NoCandidates_NoBatches(t *testing.T) {
+	assert.Empty(t, BisectBatchPolicy{}.Coalesce(nil))
+}
+
+// fakeBisector returns the job IDs in order, or fails if err is set.
+type fakeBisector struct {
+	jobIDs  []string
+	err     error
+	batches []BisectBatch
+}
+
+func (f *fakeBisector) Bisect(ctx context.Context, batch BisectBatch) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.batches = append(f.batches, batch)
+	jobID := f.jobIDs[0]
+	f.jobIDs = f.jobIDs[1:]
+	return jobID, nil
+}
+
+// fakeClient records the bisection ID set on each anomaly group.
+type fakeClient struct {
+	ag.AnomalyGroupServiceClient
+	bisectionIDs map[string]string
+}
+
+func (f *fakeClient) LoadAnomalyGroupByID(ctx context.Context, in *ag.LoadAnomalyGroupByIDRequest, opts ...grpc.CallOption) (*ag.LoadAnomalyGroupByIDResponse, error) {
+	return &ag.LoadAnomalyGroupByIDResponse{
+		AnomalyGroup: &ag.AnomalyGroup{
+			GroupId:     in.AnomalyGroupId,
+			BisectionId: f.bisectionIDs[in.AnomalyGroupId],
+		},
+	}, nil
+}
+
+func (f *fakeClient) UpdateAnomalyGroup(ctx context.Context, in *ag.UpdateAnomalyGroupRequest, opts ...grpc.CallOption) (*ag.UpdateAnomalyGroupResponse, error) {
+	f.bisectionIDs[in.AnomalyGroupId] = in.BisectionId
+	return &ag.UpdateAnomalyGroupResponse{}, nil
+}
+
+func TestFlush_OverlappingGroups_OneBisectForAllGroups(t *testing.T) {
+	ctx := context.Background()
+	bisector := &fakeBisector{jobIDs: []string{"job-1", "job-2"}}
+	client := &fakeClient{bisectionIDs: map[string]string{}}
+	b := NewBisectBatcher(BisectBatchPolicy{}, bisector, client)
+	b.Add(candidate("a", 100, 200))
+	b.Add(candidate("b", 150, 250))
+	b.Add(candidate("c", 400, 500))
+
+	batches, err := b.Flush(ctx)
+	require.NoError(t, err)
+	assert.Len(t, batches, 2)
+	assert.Equal(t, map[string]string{
+		"a": "job-1",
+		"b": "job-1",
+		"c": "job-2",
+	}, client.bisectionIDs)
+
+	// Nothing is left to bisect.
+	batches, err = b.Flush(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, batches)
+}
+
+func TestFlush_GroupAlreadyBisected_GroupIsSkipped(t *testing.T) {
+	ctx := context.Background()
+	bisector := &fakeBisector{jobIDs: []string{"job-2"}}
+	client := &fakeClient{bisectionIDs: map[string]string{"a": "job-1"}}
+	b := NewBisectBatcher(BisectBatchPolicy{}, bisector, client)
+	b.Add(candidate("a", 100, 200))
+	b.Add(candidate("b", 150, 250))
+
+	batches, err := b.Flush(ctx)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	assert.Equal(t, []string{"b"}, batches[0].GroupIDs)
+	assert.Equal(t, map[string]string{
+		"a": "job-1",
+		"b": "job-2",
+	}, client.bisectionIDs)
+}
+
+func TestFlush_SameGroupAddedTwice_RangeNarrowed(t *testing.T) {
+	ctx := context.Background()
+	bisector := &fakeBisector{jobIDs: []string{"job-1"}}
+	client := &fakeClient{bisectionIDs: map[string]string{}}
+	b := NewBisectBatcher(BisectBatchPolicy{}, bisector, client)
+	b.Add(candidate("a", 100, 200))
+	b.Add(candidate("a", 150, 250))
+
+	_, err := b.Flush(ctx)
+	require.NoError(t, err)
+	require.Len(t, bisector.batches, 1)
+	assert.Equal(t, []string{"a"}, bisector.batches[0].GroupIDs)
+	assert.Equal(t, int64(150), bisector.batches[0].StartCommit)
+	assert.Equal(t, int64(200), bisector.batches[0].EndCommit)
+}
+
+func TestAdd_SameGroupAddedTwice_CallersAnomalyUnchanged(t *testing.T) {
+	b := NewBisectBatcher(BisectBatchPolicy{}, &fakeBisector{}, &fakeClient{})
+	first := candidate("a", 100, 200)
+	b.Add(first)
+	b.Add(candidate("a", 150, 250))
+
+	assert.Equal(t, int64(100), first.Anomaly.StartCommit)
+	assert.Equal(t, int64(200), first.Anomaly.EndCommit)
+}
+
+func TestFlush_BisectFails_GroupsQueuedAgain(t *testing.T) {
+	ctx := context.Background()
+	bisector := &fakeBisector{err: errors.New("pinpoint is down")}
+	client := &fakeClient{bisectionIDs: map[string]string{}}
+	b := NewBisectBatcher(BisectBatchPolicy{}, bisector, client)
+	b.Add(candidate("a", 100, 200))
+
+	_, err := b.Flush(ctx)
+	require.Error(t, err)
+	assert.Empty(t, client.bisectionIDs)
+
+	bisector.err = nil
+	bisector.jobIDs = []string{"job-1"}
+	batches, err := b.Flush(ctx)
+	require.NoError(t, err)
+	assert.Len(t, batches, 1)
+	assert.Equal(t, map[string]string{"a": "job-1"}, client.bisectionIDs)
+}
@@ -19,32 +19,55 @@ import (
 	"go.skia.org/infra/go/sql/pool/wrapper/timeout"
 	"go.skia.org/infra/go/sql/schema"
 	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/alerts/localalertstore"
 	"go.skia.org/infra/perf/go/alerts/sqlalertstore"
 	"go.skia.org/infra/perf/go/anomalygroup"
 	ag_store "go.skia.org/infra/perf/go/anomalygroup/sqlanomalygroupstore"
+	"go.skia.org/infra/perf/go/cache"
+	"go.skia.org/infra/perf/go/cache/local"
+	"go.skia.org/infra/perf/go/cache/memcached"
 	"go.skia.org/infra/perf/go/config"
 	"go.skia.org/infra/perf/go/culprit"
 	culprit_store "go.skia.org/infra/perf/go/culprit/sqlculpritstore"
+	"go.skia.org/infra/perf/go/dataframe"
+	"go.skia.org/infra/perf/go/dfbuilder"
 	"go.skia.org/infra/perf/go/favorites"
+	"go.skia.org/infra/perf/go/favorites/localfavoritestore"
 	favorite_store "go.skia.org/infra/perf/go/favorites/sqlfavoritestore"
 	"go.skia.org/infra/perf/go/file"
 	"go.skia.org/infra/perf/go/file/dirsource"
 	"go.skia.org/infra/perf/go/file/gcssource"
 	"go.skia.org/infra/perf/go/filestore/gcs"
 	perfgit "go.skia.org/infra/perf/go/git"
+	"go.skia.org/infra/perf/go/git/providers"
 	"go.skia.org/infra/perf/go/graphsshortcut"
 	"go.skia.org/infra/perf/go/graphsshortcut/graphsshortcutstore"
+	"go.skia.org/infra/perf/go/graphsshortcut/localgraphsshortcutstore"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/noise"
+	"go.skia.org/infra/perf/go/noise/localnoisestore"
+	"go.skia.org/infra/perf/go/noise/sqlnoisestore"
+	"go.skia.org/infra/perf/go/openmetrics"
 	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/regression/localregressionstore"
 	"go.skia.org/infra/perf/go/regression/sqlregression2store"
 	"go.skia.org/infra/perf/go/regression/sqlregressionstore"
 	"go.skia.org/infra/perf/go/shortcut"
+	"go.skia.org/infra/perf/go/shortcut/localshortcutstore"
 	"go.skia.org/infra/perf/go/shortcut/sqlshortcutstore"
+	"go.skia.org/infra/perf/go/slo"
+	"go.skia.org/infra/perf/go/slo/sqlslosource"
 	"go.skia.org/infra/perf/go/sql"
 	"go.skia.org/infra/perf/go/sql/expectedschema"
 	"go.skia.org/infra/perf/go/subscription"
+	"go.skia.org/infra/perf/go/subscription/localsubscriptionstore"
 	subscription_store "go.skia.org/infra/perf/go/subscription/sqlsubscriptionstore"
 	"go.skia.org/infra/perf/go/tracestore"
+	"go.skia.org/infra/perf/go/tracestore/localtracestore"
 	"go.skia.org/infra/perf/go/tracestore/sqltracestore"
+	"go.skia.org/infra/perf/go/triagerules"
+	"go.skia.org/infra/perf/go/triagerules/localtriagerulestore"
+	"go.skia.org/infra/perf/go/triagerules/sqltriagerulestore"
 )
 
 // pgxLogAdaptor allows bubbling pgx logs up into our application.
@@ -126,6 +149,66 @@ func NewCockroachDBFromConfig(ctx context.Context, instanceConfig *config.Instan
 	return singletonPool, err
 }
 
+// singletonLocalDB is the one and only instance of localstore.DB that an
+// application should have, used in NewLocalDBFromConfig.
+var singletonLocalDB *localstore.DB
+
+// singletonLocalGit is the one and only perfgit.Git for a 'local' datastore,
+// so that the trace store and the rest of the application share the same
+// commits.
+var singletonLocalGit perfgit.Git
+
+// singletonLocalMutex is used to enforce the singleton nature of
+// singletonLocalDB and singletonLocalGit.
+var singletonLocalMutex sync.Mutex
+
+// NewLocalDBFromConfig opens, or creates, the embedded file database used for
+// the 'local' datastore type.
+func NewLocalDBFromConfig(instanceConfig *config.InstanceConfig) (*localstore.DB, error) {
+	singletonLocalMutex.Lock()
+	defer singletonLocalMutex.Unlock()
+
+	return newLocalDBFromConfig(instanceConfig)
+}
+
+// newLocalDBFromConfig is NewLocalDBFromConfig but presumes that
+// singletonLocalMutex is already held.
+func newLocalDBFromConfig(instanceConfig *config.InstanceConfig) (*localstore.DB, error) {
+	if singletonLocalDB != nil {
+		return singletonLocalDB, nil
+	}
+	db, err := localstore.New(instanceConfig.DataStoreConfig.ConnectionString)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	singletonLocalDB = db
+	return singletonLocalDB, nil
+}
+
+// newLocalPerfGitFromConfig returns the perfgit.Git for a 'local' datastore.
+func newLocalPerfGitFromConfig(ctx context.Context, instanceConfig *config.InstanceConfig) (perfgit.Git, error) {
+	singletonLocalMutex.Lock()
+	defer singletonLocalMutex.Unlock()
+
+	if singletonLocalGit != nil {
+		return singletonLocalGit, nil
+	}
+	db, err := newLocalDBFromConfig(instanceConfig)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	gp, err := providers.New(ctx, instanceConfig)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	g, err := perfgit.NewLocal(ctx, gp, db, instanceConfig)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	singletonLocalGit = g
+	return singletonLocalGit, nil
+}
+
 // NewPerfGitFromConfig return a new perfgit.Git for the given instanceConfig.
 //
 // The instance created does not poll by default, callers need to call
@@ -137,6 +220,8 @@ func NewPerfGitFromConfig(ctx context.Context, local bool, instanceConfig *confi
 
 	switch instanceConfig.DataStoreConfig.DataStoreType {
 	case config.CockroachDBDataStoreType:
+	case config.LocalDataStoreType:
+		return newLocalPerfGitFromConfig(ctx, instanceConfig)
 	default:
 		return nil, skerr.Fmt("Unknown datastore_type: %q", instanceConfig.DataStoreConfig.DataStoreType)
 	}
@@ -154,6 +239,61 @@ func NewPerfGitFromConfig(ctx context.Context, local bool, instanceConfig *confi
 	return g, nil
 }
 
+// defaultQueryCacheSize is the number of per-tile query results kept in memory
+// if memcached isn't configured.
+const defaultQueryCacheSize = 200
+
+// NewQueryCacheFromConfig creates a new dfbuilder.QueryCache from the
+// InstanceConfig, or returns nil if query caching isn't enabled.
+func NewQueryCacheFromConfig(instanceConfig *config.InstanceConfig) (*dfbuilder.QueryCache, error) {
+	cacheConfig := instanceConfig.DataStoreConfig.CacheConfig
+	if cacheConfig == nil || !cacheConfig.EnableQueryCache {
+		return nil, nil
+	}
+	c, err := newCache(cacheConfig, defaultQueryCacheSize)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to build query cache.")
+	}
+	return dfbuilder.NewQueryCache(c), nil
+}
+
+// newCache returns a cache.Cache backed by memcached if the CacheConfig lists
+// memcached servers, otherwise an in-memory cache of the given size.
+func newCache(cacheConfig *config.CacheConfig, size int) (cache.Cache, error) {
+	if cacheConfig != nil && len(cacheConfig.MemcachedServers) > 0 {
+		return memcached.New(cacheConfig.MemcachedServers, cacheConfig.Namespace)
+	}
+	return local.New(size)
+}
+
+// NewOpenMetricsExporterFromConfig creates a new openmetrics.Exporter from the
+// InstanceConfig, or returns nil if no OpenMetrics queries are configured.
+func NewOpenMetricsExporterFromConfig(instanceConfig *config.InstanceConfig, dfb dataframe.DataFrameBuilder) (*openmetrics.Exporter, error) {
+	if len(instanceConfig.OpenMetricsConfig.Queries) == 0 {
+		return nil, nil
+	}
+	// Each query is cached as a single entry.
+	c, err := newCache(instanceConfig.DataStoreConfig.CacheConfig, len(instanceConfig.OpenMetricsConfig.Queries))
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to build OpenMetrics cache.")
+	}
+	return openmetrics.New(dfb, c, instanceConfig.OpenMetricsConfig)
+}
+
+// NewSLOReporterFromConfig creates a new slo.Reporter from the InstanceConfig,
+// it returns nil if no SLOs are configured or the datastore is local. Summary
+// emails are only sent if emailer is not nil.
+func NewSLOReporterFromConfig(ctx context.Context, instanceConfig *config.InstanceConfig, emailer slo.Emailer) (*slo.Reporter, error) {
+	if instanceConfig.SLOConfig == nil || instanceConfig.DataStoreConfig.DataStoreType != config.CockroachDBDataStoreType {
+		return nil, nil
+	}
+	db, err := NewCockroachDBFromConfig(ctx, instanceConfig, true)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return slo.New(sqlslosource.New(db), emailer, *instanceConfig.SLOConfig)
+}
+
 // NewTraceStoreFromConfig creates a new TraceStore from the InstanceConfig.
 //
 // If local is true then we aren't running in production.
@@ -165,6 +305,16 @@ func NewTraceStoreFromConfig(ctx context.Context, local bool, instanceConfig *co
 			return nil, skerr.Wrap(err)
 		}
 		return sqltracestore.New(db, instanceConfig.DataStoreConfig)
+	case config.LocalDataStoreType:
+		db, err := NewLocalDBFromConfig(instanceConfig)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		g, err := newLocalPerfGitFromConfig(ctx, instanceConfig)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return localtracestore.New(db, g, instanceConfig.DataStoreConfig)
 	}
 	return nil, skerr.Fmt("Unknown datastore type: %q", instanceConfig.DataStoreConfig.DataStoreType)
 }
@@ -178,6 +328,12 @@ func NewAlertStoreFromConfig(ctx context.Context, local bool, instanceConfig *co
 			return nil, skerr.Wrap(err)
 		}
 		return sqlalertstore.New(db)
+	case config.LocalDataStoreType:
+		db, err := NewLocalDBFromConfig(instanceConfig)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return localalertstore.New(db)
 	}
 	return nil, skerr.Fmt("Unknown datastore type: %q", instanceConfig.DataStoreConfig.DataStoreType)
 }
@@ -199,6 +355,12 @@ func NewRegressionStoreFromConfig(ctx context.Context, local bool, instanceConfi
 		} else {
 			return sqlregressionstore.New(db)
 		}
+	case config.LocalDataStoreType:
+		db, err := NewLocalDBFromConfig(instanceConfig)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return localregressionstore.New(db)
 	}
 	return nil, skerr.Fmt("Unknown datastore type: %q", instanceConfig.DataStoreConfig.DataStoreType)
 }
@@ -213,6 +375,12 @@ func NewShortcutStoreFromConfig(ctx context.Context, local bool, instanceConfig
 			return nil, skerr.Wrap(err)
 		}
 		return sqlshortcutstore.New(db)
+	case config.LocalDataStoreType:
+		db, err := NewLocalDBFromConfig(instanceConfig)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return localshortcutstore.New(db)
 	}
 	return nil, skerr.Fmt("Unknown datastore type: %q", instanceConfig.DataStoreConfig.DataStoreType)
 }
@@ -227,6 +395,12 @@ func NewGraphsShortcutStoreFromConfig(ctx context.Context, local bool, instanceC
 			return nil, skerr.Wrap(err)
 		}
 		return graphsshortcutstore.New(db)
+	case config.LocalDataStoreType:
+		db, err := NewLocalDBFromConfig(instanceConfig)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return localgraphsshortcutstore.New(db)
 	}
 	return nil, skerr.Fmt("Unknown datastore type: %q", instanceConfig.DataStoreConfig.DataStoreType)
 }
@@ -297,6 +471,12 @@ func NewSubscriptionStoreFromConfig(ctx context.Context, instanceConfig *config.
 			return nil, skerr.Wrap(err)
 		}
 		return subscription_store.New(db)
+	case config.LocalDataStoreType:
+		db, err := NewLocalDBFromConfig(instanceConfig)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return localsubscriptionstore.New(db)
 	}
 	return nil, skerr.Fmt("Unknown datastore type: %q", instanceConfig.DataStoreConfig.DataStoreType)
 }
@@ -311,6 +491,53 @@ func NewFavoriteStoreFromConfig(ctx context.Context, instanceConfig *config.Inst
 			return nil, skerr.Wrap(err)
 		}
 		return favorite_store.New(db), nil
+	case config.LocalDataStoreType:
+		db, err := NewLocalDBFromConfig(instanceConfig)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return localfavoritestore.New(db)
+	}
+	return nil, skerr.Fmt("Unknown datastore type: %q", instanceConfig.DataStoreConfig.DataStoreType)
+}
+
+// NewTriageRuleStoreFromConfig creates a new triagerules.Store from the
+// InstanceConfig which provides access to the auto-triage rules and their
+// audit trail.
+func NewTriageRuleStoreFromConfig(ctx context.Context, instanceConfig *config.InstanceConfig) (triagerules.Store, error) {
+	switch instanceConfig.DataStoreConfig.DataStoreType {
+	case config.CockroachDBDataStoreType:
+		db, err := NewCockroachDBFromConfig(ctx, instanceConfig, true)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return sqltriagerulestore.New(db)
+	case config.LocalDataStoreType:
+		db, err := NewLocalDBFromConfig(instanceConfig)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return localtriagerulestore.New(db)
+	}
+	return nil, skerr.Fmt("Unknown datastore type: %q", instanceConfig.DataStoreConfig.DataStoreType)
+}
+
+// NewNoiseStoreFromConfig creates a new noise.Store from the InstanceConfig
+// which provides access to the per-trace noise estimates.
+func NewNoiseStoreFromConfig(ctx context.Context, instanceConfig *config.InstanceConfig) (noise.Store, error) {
+	switch instanceConfig.DataStoreConfig.DataStoreType {
+	case config.CockroachDBDataStoreType:
+		db, err := NewCockroachDBFromConfig(ctx, instanceConfig, true)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return sqlnoisestore.New(db)
+	case config.LocalDataStoreType:
+		db, err := NewLocalDBFromConfig(instanceConfig)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return localnoisestore.New(db)
 	}
 	return nil, skerr.Fmt("Unknown datastore type: %q", instanceConfig.DataStoreConfig.DataStoreType)
 }
@@ -8,4 +8,11 @@ type Cache interface {
 
 	// Exists returns true  if the key is found in the cache.
 	Exists(key string) bool
+
+	// SetValue adds a key with the given value to the cache.
+	SetValue(key string, value []byte)
+
+	// GetValue returns the value for the key and true if the key is found in
+	// the cache, otherwise it returns false.
+	GetValue(key string) ([]byte, bool)
 }
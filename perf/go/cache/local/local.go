@@ -33,5 +33,20 @@ func (c *Cache) Exists(key string) bool {
 	return c.cache.Contains(key)
 }
 
+// SetValue implements the cache.Cache interface.
+func (c *Cache) SetValue(key string, value []byte) {
+	_ = c.cache.Add(key, value)
+}
+
+// GetValue implements the cache.Cache interface.
+func (c *Cache) GetValue(key string) ([]byte, bool) {
+	value, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	b, ok := value.([]byte)
+	return b, ok
+}
+
 // Confirm we implement the interface.
 var _ cache.Cache = (*Cache)(nil)
@@ -28,3 +28,30 @@ func TestCache_Exists_FalseOnMiss(t *testing.T) {
 	ok := c.Exists("foo")
 	assert.False(t, ok)
 }
+
+func TestCache_GetValue_Success(t *testing.T) {
+	c, err := New(12)
+	require.NoError(t, err)
+
+	c.SetValue("foo", []byte("bar"))
+	value, ok := c.GetValue("foo")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("bar"), value)
+}
+
+func TestCache_GetValue_FalseOnMiss(t *testing.T) {
+	c, err := New(12)
+	require.NoError(t, err)
+
+	_, ok := c.GetValue("foo")
+	assert.False(t, ok)
+}
+
+func TestCache_GetValue_KeyAddedWithoutValue_FalseOnMiss(t *testing.T) {
+	c, err := New(12)
+	require.NoError(t, err)
+
+	c.Add("foo")
+	_, ok := c.GetValue("foo")
+	assert.False(t, ok)
+}
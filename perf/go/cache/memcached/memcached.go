@@ -85,5 +85,31 @@ func (c *Cache) Exists(key string) bool {
 	return exists
 }
 
+// SetValue implements the cache.Cache interface.
+//
+// Unlike Add, values are not stored in the local cache, since they can be
+// changed by other instances sharing the memcached server.
+func (c *Cache) SetValue(key string, value []byte) {
+	err := c.client.Set(&memcache.Item{
+		Key:   key + c.namespace,
+		Value: value,
+	})
+	if err != nil {
+		sklog.Errorf("Memcached failed to write: %q %s", key, err)
+	}
+}
+
+// GetValue implements the cache.Cache interface.
+func (c *Cache) GetValue(key string) ([]byte, bool) {
+	item, err := c.client.Get(key + c.namespace)
+	if err != nil {
+		if err != memcache.ErrCacheMiss {
+			sklog.Errorf("Memcached failed to read: %q %s", key, err)
+		}
+		return nil, false
+	}
+	return item.Value, true
+}
+
 // Confirm we implement the interface.
 var _ cache.Cache = (*Cache)(nil)
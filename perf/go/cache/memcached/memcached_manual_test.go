@@ -50,3 +50,21 @@ func TestCache_Exists_FalseOnMiss(t *testing.T) {
 	ok := c.Exists("qux")
 	assert.False(t, ok)
 }
+
+func TestCache_GetValue_Success(t *testing.T) {
+	c, err := New(localServerAddress, "test-namespace")
+	require.NoError(t, err)
+
+	c.SetValue("value-foo", []byte("bar"))
+	value, ok := c.GetValue("value-foo")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("bar"), value)
+}
+
+func TestCache_GetValue_FalseOnMiss(t *testing.T) {
+	c, err := New(localServerAddress, "test-namespace")
+	require.NoError(t, err)
+
+	_, ok := c.GetValue("value-qux")
+	assert.False(t, ok)
+}
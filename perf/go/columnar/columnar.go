@@ -0,0 +1,209 @@
+// Package columnar writes trace values in columnar file formats, Parquet and
+// Arrow IPC, for analysis in tools outside of Perf.
+//
+// Every file has one row per (trace, commit) that has a value, with the
+// columns:
+//
+//	trace_id          string
+//	commit_number     int64
+//	git_hash          string
+//	commit_timestamp  int64, Unix timestamp in seconds.
+//	value             float32
+//
+// followed by one nullable string column for each param key, in the order
+// given to New.
+//
+// Rows are buffered and written out in batches, Parquet row groups or Arrow
+// record batches, so the memory used doesn't grow with the number of rows
+// written. The files are encoded by the Apache Arrow Go library.
+package columnar
+
+import (
+	"io"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/ipc"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet"
+	"github.com/apache/arrow/go/v15/parquet/compress"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/perf/go/git/provider"
+)
+
+// Format is a columnar file format.
+type Format string
+
+const (
+	// Parquet is the Apache Parquet file format.
+	Parquet Format = "parquet"
+
+	// Arrow is the Apache Arrow IPC streaming format.
+	Arrow Format = "arrow"
+)
+
+// AllFormats is the list of all valid Formats.
+var AllFormats = []Format{Parquet, Arrow}
+
+// IsValid returns true if the Format is one of AllFormats.
+func (f Format) IsValid() bool {
+	for _, format := range AllFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// The names of the columns that appear in every file.
+const (
+	TraceIDColumn         = "trace_id"
+	CommitNumberColumn    = "commit_number"
+	GitHashColumn         = "git_hash"
+	CommitTimestampColumn = "commit_timestamp"
+	ValueColumn           = "value"
+)
+
+var fixedColumns = []string{TraceIDColumn, CommitNumberColumn, GitHashColumn, CommitTimestampColumn, ValueColumn}
+
+// DefaultBatchSize is the default number of rows buffered before they are
+// written out.
+const DefaultBatchSize = 64 * 1024
+
+// Row is a single value of a single trace at a single commit.
+type Row struct {
+	TraceID string
+	Params  paramtools.Params
+	Commit  provider.Commit
+	Value   float32
+}
+
+// Writer writes Rows.
+type Writer interface {
+	// Write a single row. Rows may be buffered until Close is called.
+	Write(row Row) error
+
+	// Close flushes all buffered rows and finishes the file. It does not close
+	// the underlying io.Writer.
+	Close() error
+}
+
+// recordWriter writes Arrow records in a specific format. It is implemented
+// by both ipc.Writer and pqarrow.FileWriter.
+type recordWriter interface {
+	Write(rec arrow.Record) error
+	Close() error
+}
+
+// noCloseWriter hides the Close method of an io.Writer, since
+// pqarrow.FileWriter closes its sink if it is an io.WriteCloser.
+type noCloseWriter struct {
+	io.Writer
+}
+
+// schema returns the Arrow schema of a file with the given param keys.
+func schema(keys []string) *arrow.Schema {
+	fields := []arrow.Field{
+		{Name: TraceIDColumn, Type: arrow.BinaryTypes.String},
+		{Name: CommitNumberColumn, Type: arrow.PrimitiveTypes.Int64},
+		{Name: GitHashColumn, Type: arrow.BinaryTypes.String},
+		{Name: CommitTimestampColumn, Type: arrow.PrimitiveTypes.Int64},
+		{Name: ValueColumn, Type: arrow.PrimitiveTypes.Float32},
+	}
+	for _, key := range keys {
+		fields = append(fields, arrow.Field{Name: key, Type: arrow.BinaryTypes.String, Nullable: true})
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// batchingWriter implements Writer by buffering rows in a RecordBuilder and
+// passing each full record to a recordWriter.
+type batchingWriter struct {
+	keys      []string
+	batchSize int
+	builder   *array.RecordBuilder
+	numRows   int
+	out       recordWriter
+}
+
+// Write implements Writer.
+func (w *batchingWriter) Write(row Row) error {
+	b := w.builder
+	b.Field(0).(*array.StringBuilder).Append(row.TraceID)
+	b.Field(1).(*array.Int64Builder).Append(int64(row.Commit.CommitNumber))
+	b.Field(2).(*array.StringBuilder).Append(row.Commit.GitHash)
+	b.Field(3).(*array.Int64Builder).Append(row.Commit.Timestamp)
+	b.Field(4).(*array.Float32Builder).Append(row.Value)
+	for i, key := range w.keys {
+		field := b.Field(len(fixedColumns) + i).(*array.StringBuilder)
+		if value, ok := row.Params[key]; ok {
+			field.Append(value)
+		} else {
+			field.AppendNull()
+		}
+	}
+	w.numRows++
+	if w.numRows < w.batchSize {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *batchingWriter) flush() error {
+	if w.numRows == 0 {
+		return nil
+	}
+	rec := w.builder.NewRecord()
+	defer rec.Release()
+	w.numRows = 0
+	return skerr.Wrap(w.out.Write(rec))
+}
+
+// Close implements Writer.
+func (w *batchingWriter) Close() error {
+	defer w.builder.Release()
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return skerr.Wrap(w.out.Close())
+}
+
+// New returns a Writer that writes rows in the given format to w, with one
+// column for each of the given param keys.
+//
+// batchSize is the number of rows buffered before being written, if <= 0 then
+// DefaultBatchSize is used.
+func New(format Format, w io.Writer, keys []string, batchSize int) (Writer, error) {
+	for _, key := range keys {
+		if util.In(key, fixedColumns) {
+			return nil, skerr.Fmt("Param key %q conflicts with a column of the same name.", key)
+		}
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	s := schema(keys)
+	var out recordWriter
+	switch format {
+	case Parquet:
+		props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+		pw, err := pqarrow.NewFileWriter(s, noCloseWriter{w}, props, pqarrow.DefaultWriterProps())
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		out = pw
+	case Arrow:
+		out = ipc.NewWriter(w, ipc.WithSchema(s))
+	default:
+		return nil, skerr.Fmt("Unknown format %q, must be one of %v", format, AllFormats)
+	}
+	return &batchingWriter{
+		keys:      keys,
+		batchSize: batchSize,
+		builder:   array.NewRecordBuilder(memory.DefaultAllocator, s),
+		out:       out,
+	}, nil
+}
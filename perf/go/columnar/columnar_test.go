@@ -0,0 +1,204 @@
+package columnar
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/ipc"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/file"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/perf/go/git/provider"
+)
+
+var (
+	commit1 = provider.Commit{CommitNumber: 10, GitHash: "aaaa", Timestamp: 1600000000}
+	commit2 = provider.Commit{CommitNumber: 11, GitHash: "bbbb", Timestamp: 1600000100}
+
+	testKeys = []string{"arch", "config"}
+
+	testRows = []Row{
+		{TraceID: ",arch=x86,config=8888,", Params: paramtools.Params{"arch": "x86", "config": "8888"}, Commit: commit1, Value: 1.5},
+		{TraceID: ",arch=x86,config=8888,", Params: paramtools.Params{"arch": "x86", "config": "8888"}, Commit: commit2, Value: 2.5},
+		{TraceID: ",arch=arm,", Params: paramtools.Params{"arch": "arm"}, Commit: commit1, Value: -3},
+	}
+)
+
+// decodedRow is a row as read back from a file, where nil params are nulls.
+type decodedRow struct {
+	traceID      string
+	commitNumber int64
+	gitHash      string
+	timestamp    int64
+	value        float32
+	params       []*string
+}
+
+func str(s string) *string {
+	return &s
+}
+
+func expectedRows() []decodedRow {
+	return []decodedRow{
+		{",arch=x86,config=8888,", 10, "aaaa", 1600000000, 1.5, []*string{str("x86"), str("8888")}},
+		{",arch=x86,config=8888,", 11, "bbbb", 1600000100, 2.5, []*string{str("x86"), str("8888")}},
+		{",arch=arm,", 10, "aaaa", 1600000000, -3, []*string{str("arm"), nil}},
+	}
+}
+
+func writeRows(t *testing.T, format Format, rows []Row, batchSize int) []byte {
+	var buf bytes.Buffer
+	w, err := New(format, &buf, testKeys, batchSize)
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestNew_ParamKeyConflictsWithColumn_ReturnsError(t *testing.T) {
+	_, err := New(Parquet, &bytes.Buffer{}, []string{"value"}, 0)
+	require.Error(t, err)
+}
+
+func TestFormatIsValid(t *testing.T) {
+	assert.True(t, Parquet.IsValid())
+	assert.True(t, Arrow.IsValid())
+	assert.False(t, Format("csv").IsValid())
+	assert.False(t, Format("").IsValid())
+}
+
+func TestNew_UnknownFormat_ReturnsError(t *testing.T) {
+	_, err := New("csv", &bytes.Buffer{}, testKeys, 0)
+	require.Error(t, err)
+}
+
+// decodeRecord returns the rows of a record read back by the Arrow library.
+func decodeRecord(rec arrow.Record) []decodedRow {
+	ret := make([]decodedRow, rec.NumRows())
+	for i := range ret {
+		row := decodedRow{
+			traceID:      rec.Column(0).(*array.String).Value(i),
+			commitNumber: rec.Column(1).(*array.Int64).Value(i),
+			gitHash:      rec.Column(2).(*array.String).Value(i),
+			timestamp:    rec.Column(3).(*array.Int64).Value(i),
+			value:        rec.Column(4).(*array.Float32).Value(i),
+		}
+		for c := len(fixedColumns); c < int(rec.NumCols()); c++ {
+			col := rec.Column(c).(*array.String)
+			if col.IsNull(i) {
+				row.params = append(row.params, nil)
+			} else {
+				row.params = append(row.params, str(col.Value(i)))
+			}
+		}
+		ret[i] = row
+	}
+	return ret
+}
+
+func fieldNames(schema *arrow.Schema) []string {
+	ret := []string{}
+	for _, field := range schema.Fields() {
+		ret = append(ret, field.Name)
+	}
+	return ret
+}
+
+func readParquet(t *testing.T, b []byte) (names []string, numRowGroups int, rows []decodedRow) {
+	pf, err := file.NewParquetReader(bytes.NewReader(b))
+	require.NoError(t, err)
+	defer pf.Close()
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	require.NoError(t, err)
+	table, err := fr.ReadTable(context.Background())
+	require.NoError(t, err)
+	defer table.Release()
+	tr := array.NewTableReader(table, -1)
+	defer tr.Release()
+	for tr.Next() {
+		rows = append(rows, decodeRecord(tr.Record())...)
+	}
+	return fieldNames(table.Schema()), pf.NumRowGroups(), rows
+}
+
+func TestParquet_RoundTrip(t *testing.T) {
+	names, numRowGroups, rows := readParquet(t, writeRows(t, Parquet, testRows, 0))
+	assert.Equal(t, []string{"trace_id", "commit_number", "git_hash", "commit_timestamp", "value", "arch", "config"}, names)
+	assert.Equal(t, 1, numRowGroups)
+	assert.Equal(t, expectedRows(), rows)
+}
+
+func TestParquet_SmallBatchSize_WritesMultipleRowGroups(t *testing.T) {
+	_, numRowGroups, rows := readParquet(t, writeRows(t, Parquet, testRows, 2))
+	assert.Equal(t, 2, numRowGroups)
+	assert.Equal(t, expectedRows(), rows)
+}
+
+func TestParquet_NoRows_WritesValidFile(t *testing.T) {
+	names, _, rows := readParquet(t, writeRows(t, Parquet, nil, 0))
+	assert.Len(t, names, 7)
+	assert.Empty(t, rows)
+}
+
+func TestParquet_CloseableWriter_IsNotClosed(t *testing.T) {
+	w := &closeRecorder{}
+	cw, err := New(Parquet, w, testKeys, 0)
+	require.NoError(t, err)
+	require.NoError(t, cw.Close())
+	assert.False(t, w.closed)
+}
+
+// closeRecorder is an io.WriteCloser that records if it was closed.
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func readArrow(t *testing.T, b []byte) (schema *arrow.Schema, numBatches int, rows []decodedRow) {
+	r, err := ipc.NewReader(bytes.NewReader(b))
+	require.NoError(t, err)
+	defer r.Release()
+	for r.Next() {
+		rows = append(rows, decodeRecord(r.Record())...)
+		numBatches++
+	}
+	require.NoError(t, r.Err())
+	return r.Schema(), numBatches, rows
+}
+
+func TestArrow_RoundTrip(t *testing.T) {
+	schema, numBatches, rows := readArrow(t, writeRows(t, Arrow, testRows, 0))
+	assert.Equal(t, []string{"trace_id", "commit_number", "git_hash", "commit_timestamp", "value", "arch", "config"}, fieldNames(schema))
+	nullable := []bool{}
+	for _, field := range schema.Fields() {
+		nullable = append(nullable, field.Nullable)
+	}
+	assert.Equal(t, []bool{false, false, false, false, false, true, true}, nullable)
+	assert.Equal(t, 1, numBatches)
+	assert.Equal(t, expectedRows(), rows)
+}
+
+func TestArrow_SmallBatchSize_WritesMultipleRecordBatches(t *testing.T) {
+	_, numBatches, rows := readArrow(t, writeRows(t, Arrow, testRows, 2))
+	assert.Equal(t, 2, numBatches)
+	assert.Equal(t, expectedRows(), rows)
+}
+
+func TestArrow_NoRows_WritesSchemaAndEndOfStream(t *testing.T) {
+	schema, _, rows := readArrow(t, writeRows(t, Arrow, nil, 0))
+	assert.Len(t, schema.Fields(), 7)
+	assert.Empty(t, rows)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	iSchema "github.com/invopop/jsonschema"
@@ -83,6 +84,49 @@ type NotifyConfig struct {
 	// formatted as Markdow. Sent when a detected regression is no longer
 	// detectable.
 	MissingBody []string `json:"missing_body,omitempty"`
+
+	// WebhookURL is the URL that notifications are POSTed to. Only required if
+	// Notifications is set to one of the webhook types.
+	WebhookURL string `json:"webhook_url,omitempty"`
+
+	// WebhookDefaultChannel is the channel that notifications are sent to if
+	// the alert doesn't specify a channel in alerts.Alert.WebhookChannel. If
+	// both are empty then the channel is left up to the webhook.
+	WebhookDefaultChannel string `json:"webhook_default_channel,omitempty"`
+
+	// WebhookTokenSecretProject is the name of the GCP project where the token
+	// sent to the webhook is stored in the secret manager. If set, along with
+	// WebhookTokenSecretName, the token is sent as a bearer token in the
+	// Authorization header of every request. Slack incoming webhooks don't
+	// need a token, but they don't support threading either, so to have
+	// missing regressions threaded under the message that announced them set
+	// WebhookURL to https://slack.com/api/chat.postMessage and supply a bot
+	// token here.
+	WebhookTokenSecretProject string `json:"webhook_token_secret_project,omitempty"`
+
+	// WebhookTokenSecretName is the name of the secret in the secret manager
+	// that contains the token sent to the webhook.
+	WebhookTokenSecretName string `json:"webhook_token_secret_name,omitempty"`
+
+	// BisectBatch, if set, makes the anomalygrouper notifier bisect the
+	// anomaly groups of alerts with the "bisect" action in batches, so that
+	// groups with overlapping commit ranges share a single Pinpoint job.
+	BisectBatch *BisectBatchConfig `json:"bisect_batch,omitempty"`
+}
+
+// BisectBatchConfig controls how anomaly groups are batched into Pinpoint
+// bisect jobs.
+type BisectBatchConfig struct {
+	// Period is how long anomaly groups are collected before the batches are
+	// bisected. The collected groups are only held in memory, so the groups
+	// collected when an instance restarts are not bisected, which makes
+	// shorter periods lose fewer groups.
+	Period DurationAsString `json:"period"`
+
+	// MaxCommitRange is the widest commit range a single bisect job may
+	// cover. Groups that would widen a batch past it are bisected in another
+	// job. If 0 then batches aren't limited.
+	MaxCommitRange int64 `json:"max_commit_range,omitempty"`
 }
 
 // NotifyConfig controls how notifications are sent, and their format.
@@ -114,15 +158,22 @@ type CulpritNotifyConfig struct {
 }
 
 // DataStoreType determines what type of datastore to build. Applies to
-// tracestore.Store, alerts.Store, regression.Store, and shortcut.Store.
+// tracestore.Store, alerts.Store, regression.Store, shortcut.Store, and the
+// perfgit.Git commit cache.
 type DataStoreType string
 
 const (
 	// CockroachDBDataStoreType is for storing all data in a CockroachDB database.
 	CockroachDBDataStoreType DataStoreType = "cockroachdb"
+
+	// LocalDataStoreType is for storing all data in an embedded file database
+	// in a local directory, which allows running a small instance of Perf
+	// without any external services. Only appropriate for tests, demos, and
+	// local experiments.
+	LocalDataStoreType DataStoreType = "local"
 )
 
-// CacheConfig is the config for LRU caches in the trace store.
+// CacheConfig is the config for LRU caches in the trace store and dfbuilder.
 type CacheConfig struct {
 	// The names of the memcached servers to use, for example:
 	//
@@ -138,6 +189,13 @@ type CacheConfig struct {
 	// The name to postfix to keys, to allow more than one instance of Perf to
 	// use a common memcached cluster.
 	Namespace string `json:"namespace"`
+
+	// EnableQueryCache, if true, caches the results of trace queries against
+	// tiles that are no longer the latest tile, which speeds up repeated loads
+	// of large queries in the UI. Cached results for a tile are invalidated
+	// when ingestion events arrive for that tile, so file_ingestion_pubsub_topic_name
+	// must be set.
+	EnableQueryCache bool `json:"enable_query_cache,omitempty"`
 }
 
 // DataStoreConfig is the configuration for how Perf stores data.
@@ -155,6 +213,10 @@ type DataStoreConfig struct {
 	// connection string must exist and the user given in the connection string
 	// must have rights to create, delete, and alter tables as Perf will do
 	// database migrations on startup.
+	//
+	// If the datastore type is 'local' then this value is the path of the
+	// directory where the data is stored. The directory will be created if it
+	// doesn't exist.
 	ConnectionString string `json:"connection_string"`
 
 	// TileSize is the size of each tile in commits. This value is used for all
@@ -180,8 +242,31 @@ const (
 	// DirSourceType is for a local filesystem directory and is only appropriate
 	// for tests and demo mode.
 	DirSourceType SourceType = "dir"
+
+	// PrometheusRemoteWriteSourceType accepts data pushed over HTTP using the
+	// Prometheus remote-write protocol instead of reading files.
+	PrometheusRemoteWriteSourceType SourceType = "prometheus_remote_write"
+)
+
+// CommitPositionLabelType determines how the value of the commit position
+// label of Prometheus remote-write samples is interpreted.
+type CommitPositionLabelType string
+
+const (
+	// CommitNumberLabelType means the label value is a commit number.
+	CommitNumberLabelType CommitPositionLabelType = "commit_number"
+
+	// GitHashLabelType means the label value is a git hash.
+	GitHashLabelType CommitPositionLabelType = "git_hash"
 )
 
+// AllCommitPositionLabelTypes is a list of all possible values of type
+// CommitPositionLabelType.
+var AllCommitPositionLabelTypes = []CommitPositionLabelType{
+	CommitNumberLabelType,
+	GitHashLabelType,
+}
+
 // SourceConfig is the config for where ingestable files come from.
 type SourceConfig struct {
 	// SourceType is the type of file.Source to use. This value will determine
@@ -225,9 +310,31 @@ type SourceConfig struct {
 	// is a list of Google Cloud Storage URLs, e.g.
 	// "gs://skia-perf/nano-json-v1". For a source of type "dir" is must only
 	// have a single entry and be populated with a local filesystem directory
-	// name.
+	// name. For a source of type "prometheus_remote_write" it must only have a
+	// single entry which is the address to listen on, e.g. ":8001".
 	Sources []string `json:"sources"`
 
+	// CommitPositionLabel is the name of the Prometheus label whose value is
+	// the commit that each sample belongs to. The label is not included in the
+	// trace params. Only used for source of type "prometheus_remote_write".
+	CommitPositionLabel string `json:"commit_position_label,omitempty"`
+
+	// CommitPositionLabelType is how the value of the CommitPositionLabel is
+	// interpreted, either as a commit number or as a git hash. Defaults to
+	// "commit_number". Only used for source of type "prometheus_remote_write".
+	CommitPositionLabelType CommitPositionLabelType `json:"commit_position_label_type,omitempty"`
+
+	// BearerTokenSecretProject is the name of the GCP project where the bearer
+	// token that remote-write requests must present is stored in the secret
+	// manager. Only used for source of type "prometheus_remote_write".
+	BearerTokenSecretProject string `json:"bearer_token_secret_project,omitempty"`
+
+	// BearerTokenSecretName is the name of the secret in the secret manager
+	// that contains the bearer token that remote-write requests must present
+	// in their Authorization header. Only used for source of type
+	// "prometheus_remote_write".
+	BearerTokenSecretName string `json:"bearer_token_secret_name,omitempty"`
+
 	// RejectIfNameMatches is a regex. If it matches the file.Name then the file
 	// will be ignored. Leave the empty string to disable rejection.
 	RejectIfNameMatches string `json:"reject_if_name_matches,omitempty"`
@@ -347,6 +454,39 @@ type GitRepoConfig struct {
 	// Branch is a specific branch that the commits should be tracked from.
 	// If this is empty, the main branch will be used.
 	Branch string `json:"branch,omitempty"`
+
+	// Dependencies are other repos whose revisions are pinned in a DEPS file
+	// in this repo. A range of commits in this repo can then be expanded into
+	// the dependency commits that were rolled in by that range.
+	Dependencies []DependencyRepoConfig `json:"dependencies,omitempty"`
+}
+
+// DependencyRepoConfig describes a repo that the main repo depends on via a
+// DEPS file. The dependency repo is read using the same Provider and
+// GitAuthType as the main repo.
+type DependencyRepoConfig struct {
+	// Name is the display name of the dependency, e.g. "skia".
+	Name string `json:"name"`
+
+	// URL that the dependency repo is fetched from.
+	URL string `json:"url"`
+
+	// Dir is the directory into which the dependency repo should be checked
+	// out. Only used by the "git" Provider.
+	Dir string `json:"dir,omitempty"`
+
+	// DepsID is the ID of the dependency in the DEPS file, which for git
+	// dependencies is the repo URL. Defaults to URL.
+	DepsID string `json:"deps_id,omitempty"`
+
+	// DepsFile is the path of the DEPS file in the main repo. Defaults to
+	// "DEPS".
+	DepsFile string `json:"deps_file,omitempty"`
+
+	// CommitURL is a Go format string that joins the URL with a commit hash
+	// to produce the URL of a web page that shows that exact commit. Defaults
+	// to "%s/+show/%s".
+	CommitURL string `json:"commit_url,omitempty"`
 }
 
 // TraceFormat is the format used to display trace info on the instance.
@@ -362,6 +502,146 @@ var AllTraceFormats []TraceFormat = []TraceFormat{
 	DefaultTraceFormat,
 }
 
+// RetentionPolicy is what to do with trace data that is older than the
+// retention period.
+type RetentionPolicy string
+
+const (
+	// NoRetentionPolicy keeps all trace data forever.
+	NoRetentionPolicy RetentionPolicy = ""
+
+	// DropRetentionPolicy removes all trace data from old tiles.
+	DropRetentionPolicy RetentionPolicy = "drop"
+
+	// DownsampleRetentionPolicy replaces the values in old tiles with one
+	// average value per window of commits.
+	DownsampleRetentionPolicy RetentionPolicy = "downsample"
+)
+
+// AllRetentionPolicies is a list of all valid RetentionPolicy values.
+var AllRetentionPolicies []RetentionPolicy = []RetentionPolicy{
+	NoRetentionPolicy,
+	DropRetentionPolicy,
+	DownsampleRetentionPolicy,
+}
+
+// RetentionConfig controls how long raw trace data is kept.
+type RetentionConfig struct {
+	// Policy is applied to every tile older than the KeepTiles most recent
+	// tiles. The default is to keep all data.
+	Policy RetentionPolicy `json:"policy,omitempty"`
+
+	// KeepTiles is the number of most recent tiles, including the latest
+	// tile, that are never compacted. Must be at least 1 if Policy is set.
+	KeepTiles int `json:"keep_tiles,omitempty"`
+
+	// DownsampleWindow is the number of commits that are averaged into a
+	// single value when Policy is "downsample".
+	DownsampleWindow int32 `json:"downsample_window,omitempty"`
+}
+
+// NoiseConfig controls the per-trace noise estimates used by the "noise" step
+// detection.
+type NoiseConfig struct {
+	// NumTiles is the number of most recent tiles that the noise of each
+	// trace is estimated over. If 0 then the noise estimates aren't updated
+	// and the "noise" step detection estimates the noise from the traces
+	// being analyzed.
+	NumTiles int `json:"num_tiles,omitempty"`
+
+	// Period is how often the noise estimates are updated. Defaults to 24
+	// hours.
+	Period DurationAsString `json:"period,omitempty"`
+}
+
+// OpenMetricsQuery selects the traces exported as a single OpenMetrics gauge.
+type OpenMetricsQuery struct {
+	// Name of the gauge, which must be a valid OpenMetrics metric name, e.g.
+	// "skia_render_time_ms".
+	Name string `json:"name"`
+
+	// Query selects the traces to export, in URL query format, e.g.
+	// "config=8888&test=draw_a_circle". Each matching trace is exported as a
+	// series of the gauge, with the params of the trace as labels.
+	Query string `json:"query"`
+
+	// Help is the optional description of the gauge.
+	Help string `json:"help,omitempty"`
+}
+
+// OpenMetricsConfig configures the read-only endpoint that exports the latest
+// value of selected traces in the OpenMetrics text format, so they can be
+// scraped into other monitoring systems.
+type OpenMetricsConfig struct {
+	// Queries are the gauges to export. If empty then the endpoint is not
+	// served.
+	Queries []OpenMetricsQuery `json:"queries,omitempty"`
+
+	// MaxSeries is the most series, i.e. traces, exported for a single query.
+	// Traces past the limit, in trace id order, are dropped. Defaults to
+	// 1000.
+	MaxSeries int `json:"max_series,omitempty"`
+
+	// NumCommits is how many of the most recent commits are searched for the
+	// latest value of each trace. Defaults to 20.
+	NumCommits int32 `json:"num_commits,omitempty"`
+
+	// CacheDuration is how long the exported values of a query are cached
+	// before they are loaded again. Defaults to 5 minutes.
+	CacheDuration DurationAsString `json:"cache_duration,omitempty"`
+}
+
+// ShortcutGCConfig controls the garbage collection of shortcuts that are no
+// longer used.
+type ShortcutGCConfig struct {
+	// MaxAge is how long a shortcut, or graphs shortcut, can go without being
+	// stored or retrieved before it is deleted. Shortcuts that appear in the
+	// url of a favorite are never deleted. If 0 then shortcuts are kept
+	// forever.
+	MaxAge DurationAsString `json:"max_age,omitempty"`
+
+	// Period is how often unused shortcuts are looked for. Defaults to 24
+	// hours.
+	Period DurationAsString `json:"period,omitempty"`
+}
+
+// SLOConfig configures the reporting of how long sheriffs take to triage
+// regressions and to file bugs for anomaly groups, per subscription.
+type SLOConfig struct {
+	// TriageTarget is how soon after it is found a regression should be
+	// triaged. Defaults to 1 day.
+	TriageTarget DurationAsString `json:"triage_target,omitempty"`
+
+	// BugTarget is how soon after an anomaly group is created a bug should be
+	// filed for it. Defaults to 3 days.
+	BugTarget DurationAsString `json:"bug_target,omitempty"`
+
+	// Window is how far back the regressions and anomaly groups are reported
+	// on. Defaults to 7 days.
+	Window DurationAsString `json:"window,omitempty"`
+
+	// ReportEmails are the addresses the weekly summary is sent to. If empty
+	// then no summary is sent.
+	ReportEmails []string `json:"report_emails,omitempty"`
+
+	// ReportDay is the day of the week the summary is sent on, e.g. "Monday",
+	// which is the default.
+	ReportDay string `json:"report_day,omitempty"`
+}
+
+// ReportWeekday returns the day of the week the summary is sent on.
+func (c SLOConfig) ReportWeekday() (time.Weekday, error) {
+	if c.ReportDay == "" {
+		return time.Monday, nil
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), c.ReportDay) {
+			return day, nil
+		}
+	}
+	return time.Monday, skerr.Fmt("Unknown day of the week: %q", c.ReportDay)
+}
+
 // DurationAsString allows serializing a Duration as a string, and also handles
 // deserializing the empty string.
 type DurationAsString time.Duration
@@ -819,6 +1099,40 @@ type QueryConfig struct {
 	RedisConfig RedisConfig `json:"redis_config,omitempty"`
 }
 
+// SheriffConfigSourceConfig configures importing sheriff configs from a local
+// file or a file in a git repo, for instances that can't reach LUCI Config.
+//
+// Only one of File and URL may be set. If neither is set then sheriff configs
+// aren't imported from a file or git.
+type SheriffConfigSourceConfig struct {
+	// File is the path of a local sheriff config file in prototext format.
+	File string `json:"file,omitempty"`
+
+	// URL of the git repo that contains the sheriff config file. The repo is
+	// read with the same Provider and GitAuthType as the git_repo_config.
+	URL string `json:"url,omitempty"`
+
+	// Dir is the directory the git repo is checked out into, if the
+	// git_repo_config Provider needs a checkout.
+	Dir string `json:"dir,omitempty"`
+
+	// Branch of the git repo to read the sheriff config file from. Defaults to
+	// the main branch.
+	Branch string `json:"branch,omitempty"`
+
+	// Path of the sheriff config file in the git repo.
+	Path string `json:"path,omitempty"`
+
+	// PollPeriod is how often the file is checked for changes. Defaults to one
+	// minute.
+	PollPeriod DurationAsString `json:"poll_period,omitempty"`
+}
+
+// Enabled returns true if a sheriff config source has been configured.
+func (s SheriffConfigSourceConfig) Enabled() bool {
+	return s.File != "" || s.URL != ""
+}
+
 // InstanceConfig contains all the info needed by a Perf instance.
 type InstanceConfig struct {
 	// URL is the root URL at which this instance is available, for example: "https://example.com".
@@ -879,6 +1193,16 @@ type InstanceConfig struct {
 	CulpritNotifyConfig CulpritNotifyConfig `json:"culprit_notify_config,omitempty"`
 	AnomalyConfig       AnomalyConfig       `json:"anomaly_config,omitempty"`
 	QueryConfig         QueryConfig         `json:"query_config,omitempty"`
+	RetentionConfig     RetentionConfig     `json:"retention_config,omitempty"`
+	NoiseConfig         NoiseConfig         `json:"noise_config,omitempty"`
+	OpenMetricsConfig   OpenMetricsConfig   `json:"open_metrics_config,omitempty"`
+	ShortcutGCConfig    ShortcutGCConfig    `json:"shortcut_gc_config,omitempty"`
+
+	// SLOConfig, if set, enables the triage latency metrics, the
+	// /_/slo endpoint and the weekly summary email.
+	SLOConfig *SLOConfig `json:"slo_config,omitempty"`
+
+	SheriffConfigSource SheriffConfigSourceConfig `json:"sheriff_config_source,omitempty"`
 
 	// Measurement ID to use when tracking user metrics with Google Analytics.
 	GoogleAnalyticsMeasurementID string `json:"ga_measurement_id,omitempty"`
@@ -10,6 +10,7 @@ import (
 	_ "embed" // For embed functionality.
 
 	"go.skia.org/infra/go/jsonschema"
+	"go.skia.org/infra/go/query"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/sklog"
 	"go.skia.org/infra/go/util"
@@ -31,6 +32,9 @@ import (
 //go:embed instanceConfigSchema.json
 var schema []byte
 
+// openMetricsNameRegex matches valid OpenMetrics metric names.
+var openMetricsNameRegex = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
 // InstanceConfigFromFile returns the deserialized JSON of an InstanceConfig
 // found in filename.
 //
@@ -73,6 +77,14 @@ func Validate(i config.InstanceConfig) error {
 		}
 	}
 
+	isWebhook := i.NotifyConfig.Notifications == notifytypes.SlackWebhook || i.NotifyConfig.Notifications == notifytypes.GenericWebhook
+	if isWebhook && i.NotifyConfig.WebhookURL == "" {
+		return skerr.Fmt("webhook_url must be supplied when `notifications` is set to %q", i.NotifyConfig.Notifications)
+	}
+	if (i.NotifyConfig.WebhookTokenSecretProject == "") != (i.NotifyConfig.WebhookTokenSecretName == "") {
+		return skerr.Fmt("webhook_token_secret_project and webhook_token_secret_name must be supplied together")
+	}
+
 	if i.CulpritNotifyConfig.Notifications == notifytypes.MarkdownIssueTracker {
 		if i.CulpritNotifyConfig.IssueTrackerAPIKeySecretProject == "" {
 			return skerr.Fmt("issue_tracker_api_key_secret_project must be supplied when `notifications` is set to %q", i.CulpritNotifyConfig.Notifications)
@@ -82,6 +94,122 @@ func Validate(i config.InstanceConfig) error {
 		}
 	}
 
+	if i.IngestionConfig.SourceConfig.SourceType == config.PrometheusRemoteWriteSourceType {
+		if len(i.IngestionConfig.SourceConfig.Sources) != 1 {
+			return skerr.Fmt("sources must have a single entry, the address to listen on, when `source_type` is %q", config.PrometheusRemoteWriteSourceType)
+		}
+		if i.IngestionConfig.SourceConfig.CommitPositionLabel == "" {
+			return skerr.Fmt("commit_position_label must be supplied when `source_type` is %q", config.PrometheusRemoteWriteSourceType)
+		}
+		switch i.IngestionConfig.SourceConfig.CommitPositionLabelType {
+		case "", config.CommitNumberLabelType, config.GitHashLabelType:
+		default:
+			return skerr.Fmt("commit_position_label_type must be one of %v, got %q", config.AllCommitPositionLabelTypes, i.IngestionConfig.SourceConfig.CommitPositionLabelType)
+		}
+		if i.IngestionConfig.SourceConfig.BearerTokenSecretProject == "" {
+			return skerr.Fmt("bearer_token_secret_project must be supplied when `source_type` is %q", config.PrometheusRemoteWriteSourceType)
+		}
+		if i.IngestionConfig.SourceConfig.BearerTokenSecretName == "" {
+			return skerr.Fmt("bearer_token_secret_name must be supplied when `source_type` is %q", config.PrometheusRemoteWriteSourceType)
+		}
+	}
+
+	dependencyNames := map[string]bool{}
+	for _, dep := range i.GitRepoConfig.Dependencies {
+		if dep.Name == "" || dep.URL == "" {
+			return skerr.Fmt("Every entry in `dependencies` must have a name and a url.")
+		}
+		if dependencyNames[dep.Name] {
+			return skerr.Fmt("Duplicate dependency name: %q", dep.Name)
+		}
+		dependencyNames[dep.Name] = true
+	}
+
+	switch i.RetentionConfig.Policy {
+	case config.NoRetentionPolicy:
+	case config.DropRetentionPolicy, config.DownsampleRetentionPolicy:
+		if i.RetentionConfig.KeepTiles < 1 {
+			return skerr.Fmt("keep_tiles must be at least 1 when `policy` is %q", i.RetentionConfig.Policy)
+		}
+		if i.RetentionConfig.Policy == config.DownsampleRetentionPolicy && i.RetentionConfig.DownsampleWindow < 2 {
+			return skerr.Fmt("downsample_window must be at least 2 when `policy` is %q", i.RetentionConfig.Policy)
+		}
+	default:
+		return skerr.Fmt("Invalid retention policy: %q", i.RetentionConfig.Policy)
+	}
+
+	if b := i.NotifyConfig.BisectBatch; b != nil {
+		if i.NotifyConfig.Notifications != notifytypes.AnomalyGrouper {
+			return skerr.Fmt("`bisect_batch` may only be set when `notifications` is %q", notifytypes.AnomalyGrouper)
+		}
+		if b.Period <= 0 {
+			return skerr.Fmt("period in `bisect_batch` must be positive")
+		}
+		if b.MaxCommitRange < 0 {
+			return skerr.Fmt("max_commit_range in `bisect_batch` must not be negative, got %d", b.MaxCommitRange)
+		}
+	}
+
+	if c := i.DataStoreConfig.CacheConfig; c != nil && c.EnableQueryCache && i.IngestionConfig.FileIngestionTopicName == "" {
+		return skerr.Fmt("file_ingestion_pubsub_topic_name must be supplied when `enable_query_cache` is set, otherwise cached query results are never invalidated")
+	}
+
+	if i.NoiseConfig.NumTiles < 0 {
+		return skerr.Fmt("num_tiles in `noise_config` must not be negative, got %d", i.NoiseConfig.NumTiles)
+	}
+
+	if i.ShortcutGCConfig.MaxAge < 0 {
+		return skerr.Fmt("max_age in `shortcut_gc_config` must not be negative, got %s", time.Duration(i.ShortcutGCConfig.MaxAge))
+	}
+	if i.ShortcutGCConfig.Period < 0 {
+		return skerr.Fmt("period in `shortcut_gc_config` must not be negative, got %s", time.Duration(i.ShortcutGCConfig.Period))
+	}
+
+	if slo := i.SLOConfig; slo != nil {
+		if slo.TriageTarget < 0 || slo.BugTarget < 0 || slo.Window < 0 {
+			return skerr.Fmt("triage_target, bug_target and window in `slo_config` must not be negative")
+		}
+		if _, err := slo.ReportWeekday(); err != nil {
+			return skerr.Wrapf(err, "Invalid report_day in `slo_config`")
+		}
+		for _, email := range slo.ReportEmails {
+			if email == "" {
+				return skerr.Fmt("report_emails in `slo_config` must not contain empty addresses")
+			}
+		}
+	}
+
+	if i.OpenMetricsConfig.MaxSeries < 0 {
+		return skerr.Fmt("max_series in `open_metrics_config` must not be negative, got %d", i.OpenMetricsConfig.MaxSeries)
+	}
+	if i.OpenMetricsConfig.NumCommits < 0 {
+		return skerr.Fmt("num_commits in `open_metrics_config` must not be negative, got %d", i.OpenMetricsConfig.NumCommits)
+	}
+	metricNames := map[string]bool{}
+	for _, q := range i.OpenMetricsConfig.Queries {
+		if !openMetricsNameRegex.MatchString(q.Name) {
+			return skerr.Fmt("Invalid metric name in `open_metrics_config`: %q", q.Name)
+		}
+		if metricNames[q.Name] {
+			return skerr.Fmt("Duplicate metric name in `open_metrics_config`: %q", q.Name)
+		}
+		metricNames[q.Name] = true
+		parsed, err := query.NewFromString(q.Query)
+		if err != nil {
+			return skerr.Wrapf(err, "Invalid query for metric %q in `open_metrics_config`", q.Name)
+		}
+		if parsed.Empty() {
+			return skerr.Fmt("The query for metric %q in `open_metrics_config` must not be empty.", q.Name)
+		}
+	}
+
+	if i.SheriffConfigSource.File != "" && i.SheriffConfigSource.URL != "" {
+		return skerr.Fmt("Only one of file and url may be set in `sheriff_config_source`.")
+	}
+	if i.SheriffConfigSource.URL != "" && i.SheriffConfigSource.Path == "" {
+		return skerr.Fmt("path must be supplied when `sheriff_config_source` has a url.")
+	}
+
 	if i.InvalidParamCharRegex != "" {
 		re, err := regexp.Compile(i.InvalidParamCharRegex)
 		if err != nil {
@@ -96,7 +224,7 @@ func Validate(i config.InstanceConfig) error {
 	}
 
 	// Validate the Notify Config.
-	if i.NotifyConfig.Notifications == notifytypes.MarkdownIssueTracker && (len(i.NotifyConfig.Body) > 0 || i.NotifyConfig.Subject != "" || len(i.NotifyConfig.MissingBody) > 0 || i.NotifyConfig.MissingSubject != "") {
+	if (i.NotifyConfig.Notifications == notifytypes.MarkdownIssueTracker || isWebhook) && (len(i.NotifyConfig.Body) > 0 || i.NotifyConfig.Subject != "" || len(i.NotifyConfig.MissingBody) > 0 || i.NotifyConfig.MissingSubject != "") {
 		f, err := notify.NewMarkdownFormatter("", &(i.NotifyConfig))
 		if err != nil {
 			return skerr.Wrapf(err, "creating MarkdownFormatter")
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -76,6 +77,99 @@ func TestInstanceConfigValidate_MarkdownIssueTrackerButAPIKeySecretNameNotSet_Re
 	require.Contains(t, Validate(i).Error(), "issue_tracker_api_key_secret_name must be supplied")
 }
 
+func TestInstanceConfigValidate_SlackWebhookButWebhookURLNotSet_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		NotifyConfig: config.NotifyConfig{
+			Notifications: notifytypes.SlackWebhook,
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "webhook_url must be supplied")
+}
+
+func TestInstanceConfigValidate_GenericWebhookWithWebhookURL_Success(t *testing.T) {
+	i := config.InstanceConfig{
+		NotifyConfig: config.NotifyConfig{
+			Notifications: notifytypes.GenericWebhook,
+			WebhookURL:    "https://example.org/hook",
+		},
+	}
+	require.NoError(t, Validate(i))
+}
+
+func TestInstanceConfigValidate_SlackWebhookWithTokenSecretProjectButNoName_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		NotifyConfig: config.NotifyConfig{
+			Notifications:             notifytypes.SlackWebhook,
+			WebhookURL:                "https://slack.com/api/chat.postMessage",
+			WebhookTokenSecretProject: "skia-infra-public",
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "webhook_token_secret_project and webhook_token_secret_name must be supplied together")
+}
+
+func TestInstanceConfigValidate_QueryCacheWithoutIngestionTopic_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		DataStoreConfig: config.DataStoreConfig{
+			CacheConfig: &config.CacheConfig{EnableQueryCache: true},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "file_ingestion_pubsub_topic_name must be supplied")
+
+	i.IngestionConfig.FileIngestionTopicName = "perf-ingestion"
+	require.NoError(t, Validate(i))
+}
+
+func TestInstanceConfigValidate_PrometheusRemoteWriteWithoutAddress_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		IngestionConfig: config.IngestionConfig{
+			SourceConfig: config.SourceConfig{
+				SourceType:          config.PrometheusRemoteWriteSourceType,
+				CommitPositionLabel: "commit_position",
+			},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "sources must have a single entry")
+}
+
+func TestInstanceConfigValidate_PrometheusRemoteWriteWithoutCommitPositionLabel_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		IngestionConfig: config.IngestionConfig{
+			SourceConfig: config.SourceConfig{
+				SourceType: config.PrometheusRemoteWriteSourceType,
+				Sources:    []string{":8001"},
+			},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "commit_position_label must be supplied")
+}
+
+func TestInstanceConfigValidate_PrometheusRemoteWriteWithUnknownCommitPositionLabelType_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		IngestionConfig: config.IngestionConfig{
+			SourceConfig: config.SourceConfig{
+				SourceType:              config.PrometheusRemoteWriteSourceType,
+				Sources:                 []string{":8001"},
+				CommitPositionLabel:     "commit_position",
+				CommitPositionLabelType: "svn",
+			},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "commit_position_label_type must be one of")
+}
+
+func TestInstanceConfigValidate_PrometheusRemoteWriteWithoutBearerTokenSecret_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		IngestionConfig: config.IngestionConfig{
+			SourceConfig: config.SourceConfig{
+				SourceType:          config.PrometheusRemoteWriteSourceType,
+				Sources:             []string{":8001"},
+				CommitPositionLabel: "commit_position",
+			},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "bearer_token_secret_project must be supplied")
+}
+
 func TestInstanceConfigValidate_CulpritNotify_MarkdownIssueTrackerButAPIKeySecretProjectNotSet_ReturnsError(t *testing.T) {
 	i := config.InstanceConfig{
 		CulpritNotifyConfig: config.CulpritNotifyConfig{
@@ -108,3 +202,201 @@ func TestInstanceConfigValidate_InvalidParamCharRegexMatchesEqual_ReturnsError(t
 	}
 	require.Contains(t, Validate(i).Error(), "invalid_param_char_regex must match")
 }
+
+func TestInstanceConfigValidate_RetentionPolicyWithoutKeepTiles_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		RetentionConfig: config.RetentionConfig{
+			Policy: config.DropRetentionPolicy,
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "keep_tiles must be at least 1")
+}
+
+func TestInstanceConfigValidate_DownsampleWithoutWindow_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		RetentionConfig: config.RetentionConfig{
+			Policy:    config.DownsampleRetentionPolicy,
+			KeepTiles: 10,
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "downsample_window must be at least 2")
+}
+
+func TestInstanceConfigValidate_UnknownRetentionPolicy_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		RetentionConfig: config.RetentionConfig{
+			Policy:    "shred",
+			KeepTiles: 10,
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "Invalid retention policy")
+}
+
+func TestInstanceConfigValidate_DownsampleWithWindow_Success(t *testing.T) {
+	i := config.InstanceConfig{
+		RetentionConfig: config.RetentionConfig{
+			Policy:           config.DownsampleRetentionPolicy,
+			KeepTiles:        10,
+			DownsampleWindow: 8,
+		},
+	}
+	require.NoError(t, Validate(i))
+}
+
+func TestInstanceConfigValidate_NegativeNoiseTiles_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		NoiseConfig: config.NoiseConfig{
+			NumTiles: -1,
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "num_tiles in `noise_config` must not be negative")
+}
+
+func TestInstanceConfigValidate_NegativeShortcutMaxAge_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		ShortcutGCConfig: config.ShortcutGCConfig{
+			MaxAge: config.DurationAsString(-time.Hour),
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "max_age in `shortcut_gc_config` must not be negative")
+}
+
+func TestInstanceConfigValidate_SLOConfigInvalidReportDay_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		SLOConfig: &config.SLOConfig{
+			ReportDay: "Someday",
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "Invalid report_day in `slo_config`")
+}
+
+func TestInstanceConfigValidate_SLOConfigNegativeTarget_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		SLOConfig: &config.SLOConfig{
+			TriageTarget: config.DurationAsString(-time.Hour),
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "must not be negative")
+}
+
+func TestInstanceConfigValidate_SLOConfig_Success(t *testing.T) {
+	i := config.InstanceConfig{
+		SLOConfig: &config.SLOConfig{
+			TriageTarget: config.DurationAsString(24 * time.Hour),
+			ReportEmails: []string{"sheriffs@example.org"},
+			ReportDay:    "friday",
+		},
+	}
+	require.NoError(t, Validate(i))
+}
+
+func TestInstanceConfigValidate_BisectBatchWithoutAnomalyGrouper_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		NotifyConfig: config.NotifyConfig{
+			Notifications: notifytypes.HTMLEmail,
+			BisectBatch: &config.BisectBatchConfig{
+				Period: config.DurationAsString(time.Hour),
+			},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "`bisect_batch` may only be set")
+}
+
+func TestInstanceConfigValidate_BisectBatchWithoutPeriod_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		NotifyConfig: config.NotifyConfig{
+			Notifications: notifytypes.AnomalyGrouper,
+			BisectBatch:   &config.BisectBatchConfig{},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "period in `bisect_batch` must be positive")
+}
+
+func TestInstanceConfigValidate_DependencyWithoutURL_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		GitRepoConfig: config.GitRepoConfig{
+			Dependencies: []config.DependencyRepoConfig{{Name: "skia"}},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "must have a name and a url")
+}
+
+func TestInstanceConfigValidate_DuplicateDependencyNames_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		GitRepoConfig: config.GitRepoConfig{
+			Dependencies: []config.DependencyRepoConfig{
+				{Name: "skia", URL: "https://skia.googlesource.com/skia"},
+				{Name: "skia", URL: "https://skia.googlesource.com/skia"},
+			},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "Duplicate dependency name")
+}
+
+func TestInstanceConfigValidate_SheriffConfigSourceWithFileAndURL_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		SheriffConfigSource: config.SheriffConfigSourceConfig{
+			File: "/etc/perf/sheriff.cfg",
+			URL:  "https://example.com/config.git",
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "Only one of file and url")
+}
+
+func TestInstanceConfigValidate_SheriffConfigSourceURLWithoutPath_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		SheriffConfigSource: config.SheriffConfigSourceConfig{
+			URL: "https://example.com/config.git",
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "path must be supplied")
+}
+
+func TestInstanceConfigValidate_SheriffConfigSourceFile_Success(t *testing.T) {
+	i := config.InstanceConfig{
+		SheriffConfigSource: config.SheriffConfigSourceConfig{
+			File: "/etc/perf/sheriff.cfg",
+		},
+	}
+	require.NoError(t, Validate(i))
+}
+
+func TestInstanceConfigValidate_OpenMetricsInvalidName_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		OpenMetricsConfig: config.OpenMetricsConfig{
+			Queries: []config.OpenMetricsQuery{{Name: "render-time", Query: "config=8888"}},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "Invalid metric name")
+}
+
+func TestInstanceConfigValidate_OpenMetricsDuplicateName_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		OpenMetricsConfig: config.OpenMetricsConfig{
+			Queries: []config.OpenMetricsQuery{
+				{Name: "render_time", Query: "config=8888"},
+				{Name: "render_time", Query: "config=565"},
+			},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "Duplicate metric name")
+}
+
+func TestInstanceConfigValidate_OpenMetricsEmptyQuery_ReturnsError(t *testing.T) {
+	i := config.InstanceConfig{
+		OpenMetricsConfig: config.OpenMetricsConfig{
+			Queries: []config.OpenMetricsQuery{{Name: "render_time"}},
+		},
+	}
+	require.Contains(t, Validate(i).Error(), "must not be empty")
+}
+
+func TestInstanceConfigValidate_OpenMetricsQuery_Success(t *testing.T) {
+	i := config.InstanceConfig{
+		OpenMetricsConfig: config.OpenMetricsConfig{
+			Queries:   []config.OpenMetricsQuery{{Name: "render_time", Query: "config=8888"}},
+			MaxSeries: 100,
+		},
+	}
+	require.NoError(t, Validate(i))
+}
@@ -16,6 +16,7 @@ import (
 	"go.skia.org/infra/go/vec32"
 	"go.skia.org/infra/perf/go/dataframe"
 	perfgit "go.skia.org/infra/perf/go/git"
+	"go.skia.org/infra/perf/go/git/provider"
 	"go.skia.org/infra/perf/go/progress"
 	"go.skia.org/infra/perf/go/tracefilter"
 	"go.skia.org/infra/perf/go/tracesetbuilder"
@@ -61,6 +62,9 @@ type builder struct {
 	filterParentTraces Filtering
 	mux                *sync.Mutex
 
+	// queryCache caches the results of QueryTraces, may be nil.
+	queryCache *QueryCache
+
 	newTimer                      metrics2.Float64SummaryMetric
 	newByTileTimer                metrics2.Float64SummaryMetric
 	newFromQueryAndRangeTimer     metrics2.Float64SummaryMetric
@@ -72,8 +76,12 @@ type builder struct {
 }
 
 // NewDataFrameBuilderFromTraceStore builds a DataFrameBuilder.
-func NewDataFrameBuilderFromTraceStore(git perfgit.Git, store tracestore.TraceStore, numPreflightTiles int, filterParentTraces Filtering) dataframe.DataFrameBuilder {
+//
+// If queryCache is not nil then it is used to cache the results of queries
+// against completed tiles.
+func NewDataFrameBuilderFromTraceStore(git perfgit.Git, store tracestore.TraceStore, numPreflightTiles int, filterParentTraces Filtering, queryCache *QueryCache) dataframe.DataFrameBuilder {
 	return &builder{
+		queryCache:                    queryCache,
 		git:                           git,
 		store:                         store,
 		numPreflightTiles:             numPreflightTiles,
@@ -161,6 +169,17 @@ func (b *builder) new(ctx context.Context, colHeaders []*dataframe.ColumnHeader,
 		progress.Message("Tiles", fmt.Sprintf("%d/%d", tilesCompleted, len(mapper)))
 	}
 
+	// Only look up the latest tile if it's needed by the query cache.
+	latestTile := types.BadTileNumber
+	if b.queryCache != nil {
+		var err error
+		latestTile, err = b.store.GetLatestTile(ctx)
+		if err != nil {
+			sklog.Warningf("Failed to find the latest tile, not using the query cache: %s", err)
+			latestTile = types.BadTileNumber
+		}
+	}
+
 	var g errgroup.Group
 	// For each tile.
 	for _, tileNumber := range mapper {
@@ -173,7 +192,7 @@ func (b *builder) new(ctx context.Context, colHeaders []*dataframe.ColumnHeader,
 			// Query for matching traces in the given tile.
 			queryContext, cancel := context.WithTimeout(ctx, singleTileQueryTimeout)
 			defer cancel()
-			traces, commits, err := b.store.QueryTraces(queryContext, tileNumber, q)
+			traces, commits, err := b.queryTraces(queryContext, tileNumber, latestTile, q)
 			if err != nil {
 				return err
 			}
@@ -201,6 +220,15 @@ func (b *builder) new(ctx context.Context, colHeaders []*dataframe.ColumnHeader,
 	return d.Compress(), nil
 }
 
+// queryTraces returns the traces that match the query in the given tile,
+// using the query cache if there is one.
+func (b *builder) queryTraces(ctx context.Context, tileNumber, latestTile types.TileNumber, q *query.Query) (types.TraceSet, []provider.Commit, error) {
+	if b.queryCache == nil {
+		return b.store.QueryTraces(ctx, tileNumber, q)
+	}
+	return b.queryCache.QueryTraces(ctx, b.store, tileNumber, latestTile, q)
+}
+
 // See DataFrameBuilder.
 func (b *builder) NewFromQueryAndRange(ctx context.Context, begin, end time.Time, q *query.Query, downsample bool, progress progress.Progress) (*dataframe.DataFrame, error) {
 	ctx, span := trace.StartSpan(ctx, "dfbuilder.NewFromQueryAndRange")
@@ -74,7 +74,7 @@ func TestBuildNew(t *testing.T) {
 	store, err := sqltracestore.New(db, instanceConfig.DataStoreConfig)
 	require.NoError(t, err)
 
-	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces)
+	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces, nil)
 
 	// Add some points to the first and second tile.
 	err = addValuesAtIndex(store, 0, map[string]float32{
@@ -252,7 +252,7 @@ func TestPreflightQuery_EmptyQuery_ReturnsError(t *testing.T) {
 	store, err := sqltracestore.New(db, instanceConfig.DataStoreConfig)
 	require.NoError(t, err)
 
-	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces)
+	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces, nil)
 
 	// Add some points to the first tile.
 	err = addValuesAtIndex(store, 0, map[string]float32{
@@ -279,7 +279,7 @@ func TestPreflightQuery_NonEmptyQuery_Success(t *testing.T) {
 	store, err := sqltracestore.New(db, instanceConfig.DataStoreConfig)
 	require.NoError(t, err)
 
-	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces)
+	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces, nil)
 
 	// Add some points to the first tile.
 	err = addValuesAtIndex(store, 0, map[string]float32{
@@ -322,7 +322,7 @@ func TestPreflightQuery_TilesContainDifferentNumberOfMatches_ReturnedParamSetRef
 	store, err := sqltracestore.New(db, instanceConfig.DataStoreConfig)
 	require.NoError(t, err)
 
-	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces)
+	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces, nil)
 
 	// Add some points to the first tile.
 	err = addValuesAtIndex(store, 0, map[string]float32{
@@ -371,7 +371,7 @@ func TestNumMatches_EmptyQuery_ReturnsError(t *testing.T) {
 	store, err := sqltracestore.New(db, instanceConfig.DataStoreConfig)
 	require.NoError(t, err)
 
-	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces)
+	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces, nil)
 	q, err := query.NewFromString("")
 	require.NoError(t, err)
 	_, err = builder.NumMatches(ctx, q)
@@ -388,7 +388,7 @@ func TestNumMatches_NonEmptyQuery_Success(t *testing.T) {
 	store, err := sqltracestore.New(db, instanceConfig.DataStoreConfig)
 	require.NoError(t, err)
 
-	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces)
+	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces, nil)
 
 	// Add some points to the first tile.
 	err = addValuesAtIndex(store, 0, map[string]float32{
@@ -417,7 +417,7 @@ func TestNumMatches_TilesContainDifferentNumberOfMatches_TheLargerOfTheTwoCounts
 	store, err := sqltracestore.New(db, instanceConfig.DataStoreConfig)
 	require.NoError(t, err)
 
-	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces)
+	builder := NewDataFrameBuilderFromTraceStore(g, store, 2, doNotFilterParentTraces, nil)
 
 	// Add some points to the latest tile.
 	err = addValuesAtIndex(store, types.CommitNumber(instanceConfig.DataStoreConfig.TileSize+1), map[string]float32{
@@ -0,0 +1,151 @@
+package dfbuilder
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/gob"
+	"fmt"
+	"strconv"
+
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/now"
+	"go.skia.org/infra/go/query"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/perf/go/cache"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/tracestore"
+	"go.skia.org/infra/perf/go/types"
+)
+
+// QueryCache is a read-through cache of the results of
+// tracestore.TraceStore.QueryTraces for a single tile.
+//
+// Only completed tiles, i.e. tiles older than the latest tile, are cached,
+// since the latest tile changes with every ingested file. Data can still
+// arrive for older tiles, for example when backfilling, so every tile has a
+// generation which is part of the cache key of every result for that tile.
+// Invalidate changes the generation, which orphans all the cached results for
+// the tile. There is also a generation shared by all tiles, which is part of
+// every cache key, for when it isn't known which tile changed, see
+// InvalidateAll. The generations live in the same cache as the results and can
+// be evicted, so a missing generation is treated as a miss and gets a new
+// generation, rather than falling back to a default that older results may
+// have been stored under.
+//
+// Results larger than maxCachedResultSize are not cached, since memcached
+// rejects items larger than 1MB.
+type QueryCache struct {
+	cache cache.Cache
+
+	hits          metrics2.Counter
+	misses        metrics2.Counter
+	invalidations metrics2.Counter
+	tooLarge      metrics2.Counter
+}
+
+// maxCachedResultSize is the size in bytes of the largest encoded result that
+// is cached. It leaves room below memcached's default 1MB item size limit for
+// the key and the item overhead.
+const maxCachedResultSize = 1000 * 1000
+
+// NewQueryCache returns a new QueryCache that stores results in the given
+// cache.Cache.
+func NewQueryCache(c cache.Cache) *QueryCache {
+	return &QueryCache{
+		cache:         c,
+		hits:          metrics2.GetCounter("perfserver_dfbuilder_query_cache_hits"),
+		misses:        metrics2.GetCounter("perfserver_dfbuilder_query_cache_misses"),
+		invalidations: metrics2.GetCounter("perfserver_dfbuilder_query_cache_invalidations"),
+		tooLarge:      metrics2.GetCounter("perfserver_dfbuilder_query_cache_too_large"),
+	}
+}
+
+// queryCacheEntry is the value stored for each cached query result.
+type queryCacheEntry struct {
+	Traces  types.TraceSet
+	Commits []provider.Commit
+}
+
+// allTilesGenerationKey is the key of the generation shared by all tiles.
+const allTilesGenerationKey = "dfbuilder-generation-all"
+
+func generationKey(tileNumber types.TileNumber) string {
+	return fmt.Sprintf("dfbuilder-generation-%d", tileNumber)
+}
+
+// generation returns the current generation stored under the key, starting a
+// new one if there is none, e.g. because it was evicted from the cache.
+func (c *QueryCache) generation(ctx context.Context, key string) string {
+	if b, ok := c.cache.GetValue(key); ok {
+		return string(b)
+	}
+	return c.newGeneration(ctx, key)
+}
+
+// newGeneration starts a new generation stored under the key and returns it.
+// Results stored under any previous generation are never read again.
+func (c *QueryCache) newGeneration(ctx context.Context, key string) string {
+	gen := strconv.FormatInt(now.Now(ctx).UnixNano(), 10)
+	c.cache.SetValue(key, []byte(gen))
+	return gen
+}
+
+// resultKey returns the cache key for the results of the query on the tile in
+// the given generations. The query is hashed since cache keys are limited in
+// length.
+func resultKey(tileNumber types.TileNumber, allTilesGeneration, generation string, q *query.Query) string {
+	return fmt.Sprintf("dfbuilder-query-%d-%s-%s-%x", tileNumber, allTilesGeneration, generation, md5.Sum([]byte(q.KeyValueString())))
+}
+
+// Invalidate removes all the cached results for the given tile.
+func (c *QueryCache) Invalidate(ctx context.Context, tileNumber types.TileNumber) {
+	c.invalidations.Inc(1)
+	c.newGeneration(ctx, generationKey(tileNumber))
+}
+
+// InvalidateAll removes all the cached results for every tile. Use it when
+// data has changed but it isn't known which tile it changed.
+func (c *QueryCache) InvalidateAll(ctx context.Context) {
+	c.invalidations.Inc(1)
+	c.newGeneration(ctx, allTilesGenerationKey)
+}
+
+// QueryTraces returns the results of store.QueryTraces, from the cache if
+// possible. Results are only cached if tileNumber is older than latestTile.
+func (c *QueryCache) QueryTraces(ctx context.Context, store tracestore.TraceStore, tileNumber, latestTile types.TileNumber, q *query.Query) (types.TraceSet, []provider.Commit, error) {
+	if tileNumber >= latestTile {
+		return store.QueryTraces(ctx, tileNumber, q)
+	}
+
+	allTilesGen := c.generation(ctx, allTilesGenerationKey)
+	gen := c.generation(ctx, generationKey(tileNumber))
+	key := resultKey(tileNumber, allTilesGen, gen, q)
+	if b, ok := c.cache.GetValue(key); ok {
+		var entry queryCacheEntry
+		err := gob.NewDecoder(bytes.NewReader(b)).Decode(&entry)
+		if err == nil {
+			c.hits.Inc(1)
+			return entry.Traces, entry.Commits, nil
+		}
+		sklog.Warningf("Failed to decode cached query result for tile %d: %s", tileNumber, err)
+	}
+	c.misses.Inc(1)
+
+	traces, commits, err := store.QueryTraces(ctx, tileNumber, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(queryCacheEntry{Traces: traces, Commits: commits}); err != nil {
+		sklog.Warningf("Failed to encode query result for tile %d: %s", tileNumber, err)
+		return traces, commits, nil
+	}
+	if buf.Len() > maxCachedResultSize {
+		c.tooLarge.Inc(1)
+		sklog.Infof("Not caching query result for tile %d, it is %d bytes.", tileNumber, buf.Len())
+		return traces, commits, nil
+	}
+	c.cache.SetValue(key, buf.Bytes())
+	return traces, commits, nil
+}
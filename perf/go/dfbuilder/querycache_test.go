@@ -0,0 +1,162 @@
+package dfbuilder
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/query"
+	"go.skia.org/infra/go/testutils"
+	"go.skia.org/infra/perf/go/cache/local"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/tracestore/mocks"
+	"go.skia.org/infra/perf/go/types"
+)
+
+const (
+	completedTile types.TileNumber = 1
+	latestTile    types.TileNumber = 2
+)
+
+var (
+	cachedTraces = types.TraceSet{
+		",arch=x86,config=8888,": types.Trace{1, 2},
+	}
+	cachedCommits = []provider.Commit{
+		{CommitNumber: 512, GitHash: "abc"},
+		{CommitNumber: 513, GitHash: "def"},
+	}
+)
+
+func newQueryCacheForTest(t *testing.T) (*QueryCache, *mocks.TraceStore, *query.Query) {
+	c, err := local.New(10)
+	require.NoError(t, err)
+	q, err := query.New(url.Values{"config": []string{"8888"}})
+	require.NoError(t, err)
+	return NewQueryCache(c), mocks.NewTraceStore(t), q
+}
+
+func TestQueryCache_CompletedTile_StoreIsOnlyQueriedOnce(t *testing.T) {
+	qc, store, q := newQueryCacheForTest(t)
+	store.On("QueryTraces", testutils.AnyContext, completedTile, q).Return(cachedTraces, cachedCommits, nil).Once()
+
+	for i := 0; i < 2; i++ {
+		traces, commits, err := qc.QueryTraces(context.Background(), store, completedTile, latestTile, q)
+		require.NoError(t, err)
+		assert.Equal(t, cachedTraces, traces)
+		assert.Equal(t, cachedCommits, commits)
+	}
+}
+
+func TestQueryCache_LatestTile_IsNotCached(t *testing.T) {
+	qc, store, q := newQueryCacheForTest(t)
+	store.On("QueryTraces", testutils.AnyContext, latestTile, q).Return(cachedTraces, cachedCommits, nil).Twice()
+
+	for i := 0; i < 2; i++ {
+		_, _, err := qc.QueryTraces(context.Background(), store, latestTile, latestTile, q)
+		require.NoError(t, err)
+	}
+}
+
+func TestQueryCache_DifferentQueries_AreCachedSeparately(t *testing.T) {
+	qc, store, q := newQueryCacheForTest(t)
+	q2, err := query.New(url.Values{"config": []string{"565"}})
+	require.NoError(t, err)
+	store.On("QueryTraces", testutils.AnyContext, completedTile, q).Return(cachedTraces, cachedCommits, nil).Once()
+	store.On("QueryTraces", testutils.AnyContext, completedTile, q2).Return(types.TraceSet{}, cachedCommits, nil).Once()
+
+	traces, _, err := qc.QueryTraces(context.Background(), store, completedTile, latestTile, q)
+	require.NoError(t, err)
+	assert.Equal(t, cachedTraces, traces)
+	traces, _, err = qc.QueryTraces(context.Background(), store, completedTile, latestTile, q2)
+	require.NoError(t, err)
+	assert.Empty(t, traces)
+}
+
+func TestQueryCache_Invalidate_StoreIsQueriedAgain(t *testing.T) {
+	qc, store, q := newQueryCacheForTest(t)
+	store.On("QueryTraces", testutils.AnyContext, completedTile, q).Return(cachedTraces, cachedCommits, nil).Twice()
+
+	_, _, err := qc.QueryTraces(context.Background(), store, completedTile, latestTile, q)
+	require.NoError(t, err)
+	qc.Invalidate(context.Background(), completedTile)
+	_, _, err = qc.QueryTraces(context.Background(), store, completedTile, latestTile, q)
+	require.NoError(t, err)
+}
+
+func TestQueryCache_GenerationEvicted_StoreIsQueriedAgain(t *testing.T) {
+	c := mapCache{}
+	qc := NewQueryCache(c)
+	_, store, q := newQueryCacheForTest(t)
+	store.On("QueryTraces", testutils.AnyContext, completedTile, q).Return(cachedTraces, cachedCommits, nil).Twice()
+
+	_, _, err := qc.QueryTraces(context.Background(), store, completedTile, latestTile, q)
+	require.NoError(t, err)
+	// Data arrives for the tile, then the generation is evicted before the
+	// results are.
+	qc.Invalidate(context.Background(), completedTile)
+	delete(c, generationKey(completedTile))
+	_, _, err = qc.QueryTraces(context.Background(), store, completedTile, latestTile, q)
+	require.NoError(t, err)
+}
+
+func TestQueryCache_InvalidateAll_StoreIsQueriedAgain(t *testing.T) {
+	qc, store, q := newQueryCacheForTest(t)
+	store.On("QueryTraces", testutils.AnyContext, completedTile, q).Return(cachedTraces, cachedCommits, nil).Twice()
+
+	_, _, err := qc.QueryTraces(context.Background(), store, completedTile, latestTile, q)
+	require.NoError(t, err)
+	qc.InvalidateAll(context.Background())
+	_, _, err = qc.QueryTraces(context.Background(), store, completedTile, latestTile, q)
+	require.NoError(t, err)
+}
+
+func TestQueryCache_ResultTooLarge_IsNotCached(t *testing.T) {
+	qc, store, q := newQueryCacheForTest(t)
+	trace := make(types.Trace, maxCachedResultSize/4)
+	for i := range trace {
+		trace[i] = float32(i) + 0.1
+	}
+	largeTraces := types.TraceSet{
+		",arch=x86,config=8888,": trace,
+	}
+	store.On("QueryTraces", testutils.AnyContext, completedTile, q).Return(largeTraces, cachedCommits, nil).Twice()
+
+	for i := 0; i < 2; i++ {
+		traces, _, err := qc.QueryTraces(context.Background(), store, completedTile, latestTile, q)
+		require.NoError(t, err)
+		assert.Equal(t, largeTraces, traces)
+	}
+}
+
+func TestQueryCache_StoreReturnsError_ErrorIsReturnedAndNotCached(t *testing.T) {
+	qc, store, q := newQueryCacheForTest(t)
+	myErr := errors.New("my error")
+	store.On("QueryTraces", testutils.AnyContext, completedTile, q).Return(nil, nil, myErr).Twice()
+
+	for i := 0; i < 2; i++ {
+		_, _, err := qc.QueryTraces(context.Background(), store, completedTile, latestTile, q)
+		require.ErrorIs(t, err, myErr)
+	}
+}
+
+// mapCache is a cache.Cache that never evicts anything on its own, so tests
+// can control exactly which keys are evicted.
+type mapCache map[string][]byte
+
+func (m mapCache) Add(key string) { m[key] = nil }
+
+func (m mapCache) Exists(key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+func (m mapCache) SetValue(key string, value []byte) { m[key] = value }
+
+func (m mapCache) GetValue(key string) ([]byte, bool) {
+	b, ok := m[key]
+	return b, ok
+}
@@ -15,6 +15,7 @@ import (
 	"go.skia.org/infra/perf/go/dataframe"
 	perfgit "go.skia.org/infra/perf/go/git"
 	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/noise"
 	"go.skia.org/infra/perf/go/progress"
 	"go.skia.org/infra/perf/go/regression"
 	"go.skia.org/infra/perf/go/shortcut"
@@ -31,16 +32,18 @@ type RegressionAtCommit struct {
 type Requests struct {
 	perfGit       perfgit.Git
 	shortcutStore shortcut.Store
+	noiseStore    noise.Store
 	dfBuilder     dataframe.DataFrameBuilder
 	tracker       progress.Tracker
 	paramsProvier regression.ParamsetProvider
 }
 
 // New create a new dryrun Request processor.
-func New(perfGit perfgit.Git, tracker progress.Tracker, shortcutStore shortcut.Store, dfBuilder dataframe.DataFrameBuilder, paramsProvider regression.ParamsetProvider) *Requests {
+func New(perfGit perfgit.Git, tracker progress.Tracker, shortcutStore shortcut.Store, noiseStore noise.Store, dfBuilder dataframe.DataFrameBuilder, paramsProvider regression.ParamsetProvider) *Requests {
 	ret := &Requests{
 		perfGit:       perfGit,
 		shortcutStore: shortcutStore,
+		noiseStore:    noiseStore,
 		dfBuilder:     dfBuilder,
 		tracker:       tracker,
 		paramsProvier: paramsProvider,
@@ -130,7 +133,7 @@ func (d *Requests) StartHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	go func() {
-		err := regression.ProcessRegressions(ctx, req, detectorResponseProcessor, d.perfGit, d.shortcutStore, d.dfBuilder, d.paramsProvier(), regression.ExpandBaseAlertByGroupBy, regression.ContinueOnError, config.Config.AnomalyConfig)
+		err := regression.ProcessRegressions(ctx, req, detectorResponseProcessor, d.perfGit, d.shortcutStore, d.noiseStore, d.dfBuilder, d.paramsProvier(), regression.ExpandBaseAlertByGroupBy, regression.ContinueOnError, config.Config.AnomalyConfig)
 		if err != nil {
 			req.Progress.Error(err.Error())
 		} else {
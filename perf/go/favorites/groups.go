@@ -0,0 +1,59 @@
+package favorites
+
+import (
+	"net/http"
+	"sync"
+
+	"go.skia.org/infra/go/allowed"
+	"go.skia.org/infra/go/sklog"
+)
+
+// InfraAuthGroups implements Groups using the groups in Chrome Infra Auth.
+//
+// The members of a group are loaded the first time the group is looked up,
+// and are then refreshed periodically. A group that fails to load has no
+// members, and is loaded again the next time it is looked up.
+type InfraAuthGroups struct {
+	// load returns the members of the named group.
+	load func(group string) (allowed.Allow, error)
+
+	// mutex protects groups.
+	mutex  sync.Mutex
+	groups map[string]allowed.Allow
+}
+
+// NewInfraAuthGroups returns a new *InfraAuthGroups. The client must be
+// authenticated and allowed to read the groups from Chrome Infra Auth.
+func NewInfraAuthGroups(client *http.Client) *InfraAuthGroups {
+	return newInfraAuthGroups(func(group string) (allowed.Allow, error) {
+		return allowed.NewAllowedFromChromeInfraAuth(client, group)
+	})
+}
+
+func newInfraAuthGroups(load func(group string) (allowed.Allow, error)) *InfraAuthGroups {
+	return &InfraAuthGroups{
+		load:   load,
+		groups: map[string]allowed.Allow{},
+	}
+}
+
+// IsMember implements Groups.
+func (g *InfraAuthGroups) IsMember(group, userId string) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	members, ok := g.groups[group]
+	if !ok {
+		var err error
+		members, err = g.load(group)
+		if err != nil {
+			sklog.Errorf("Failed to load the members of group %q: %s", group, err)
+			return false
+		}
+		g.groups[group] = members
+	}
+	return members.Member(userId)
+}
+
+// Confirm that *InfraAuthGroups implements Groups.
+var _ Groups = (*InfraAuthGroups)(nil)
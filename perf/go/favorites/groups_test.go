@@ -0,0 +1,34 @@
+package favorites
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.skia.org/infra/go/allowed"
+)
+
+func TestInfraAuthGroupsIsMember_GroupIsOnlyLoadedOnce(t *testing.T) {
+	loads := 0
+	g := newInfraAuthGroups(func(group string) (allowed.Allow, error) {
+		loads++
+		return allowed.NewAllowedFromList([]string{"a@example.org"}), nil
+	})
+	assert.True(t, g.IsMember("perf-sheriffs", "a@example.org"))
+	assert.False(t, g.IsMember("perf-sheriffs", "b@example.org"))
+	assert.Equal(t, 1, loads)
+}
+
+func TestInfraAuthGroupsIsMember_LoadFails_NotAMemberAndLoadedAgain(t *testing.T) {
+	loads := 0
+	g := newInfraAuthGroups(func(group string) (allowed.Allow, error) {
+		loads++
+		if loads == 1 {
+			return nil, errors.New("my error")
+		}
+		return allowed.NewAllowedFromList([]string{"a@example.org"}), nil
+	})
+	assert.False(t, g.IsMember("perf-sheriffs", "a@example.org"))
+	assert.True(t, g.IsMember("perf-sheriffs", "a@example.org"))
+	assert.Equal(t, 2, loads)
+}
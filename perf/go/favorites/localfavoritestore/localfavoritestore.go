@@ -0,0 +1,225 @@
+// Package localfavoritestore implements favorites.Store using a localstore.DB.
+package localfavoritestore
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/perf/go/favorites"
+	"go.skia.org/infra/perf/go/localstore"
+)
+
+// favoritesTable is the name of the table in the localstore.DB.
+const favoritesTable = "favorites"
+
+// table is the data stored in the favorites table.
+type table struct {
+	NextID    int64
+	Favorites map[int64]favorites.Favorite
+	Teams     map[string]favorites.Team
+}
+
+// FavoriteStore implements the favorites.Store interface using a
+// localstore.DB.
+type FavoriteStore struct {
+	db *localstore.DB
+
+	// mutex protects data.
+	mutex sync.Mutex
+	data  table
+}
+
+// New returns a new *FavoriteStore.
+func New(db *localstore.DB) (*FavoriteStore, error) {
+	ret := &FavoriteStore{
+		db: db,
+		data: table{
+			NextID:    1,
+			Favorites: map[int64]favorites.Favorite{},
+			Teams:     map[string]favorites.Team{},
+		},
+	}
+	if err := db.Read(favoritesTable, &ret.data); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	// Tables written before teams were added don't have the Teams map.
+	if ret.data.Teams == nil {
+		ret.data.Teams = map[string]favorites.Team{}
+	}
+	return ret, nil
+}
+
+// Get implements the favorites.Store interface.
+func (s *FavoriteStore) Get(ctx context.Context, id int64) (*favorites.Favorite, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	fav, ok := s.data.Favorites[id]
+	if !ok {
+		return nil, skerr.Fmt("Failed to load favorite.")
+	}
+	return &fav, nil
+}
+
+// Create implements the favorites.Store interface.
+func (s *FavoriteStore) Create(ctx context.Context, req *favorites.SaveRequest) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := s.data.NextID
+	s.data.NextID++
+	s.data.Favorites[id] = favorites.Favorite{
+		ID:           id,
+		UserId:       req.UserId,
+		Name:         req.Name,
+		Url:          req.Url,
+		Description:  req.Description,
+		LastModified: time.Now().Unix(),
+		Team:         req.Team,
+	}
+	if err := s.db.Write(favoritesTable, s.data); err != nil {
+		return skerr.Wrapf(err, "Failed to insert favorite")
+	}
+	return nil
+}
+
+// Update implements the favorites.Store interface.
+func (s *FavoriteStore) Update(ctx context.Context, req *favorites.SaveRequest, id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	fav, ok := s.data.Favorites[id]
+	if !ok {
+		return nil
+	}
+	fav.Name = req.Name
+	fav.Url = req.Url
+	fav.Description = req.Description
+	fav.LastModified = time.Now().Unix()
+	s.data.Favorites[id] = fav
+	if err := s.db.Write(favoritesTable, s.data); err != nil {
+		return skerr.Wrapf(err, "Failed to update favorite with id=%d", id)
+	}
+	return nil
+}
+
+// Delete implements the favorites.Store interface.
+func (s *FavoriteStore) Delete(ctx context.Context, id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.data.Favorites, id)
+	if err := s.db.Write(favoritesTable, s.data); err != nil {
+		return skerr.Wrapf(err, "Failed to delete favorite with id=%d", id)
+	}
+	return nil
+}
+
+// List implements the favorites.Store interface.
+func (s *FavoriteStore) List(ctx context.Context, userId string) ([]*favorites.Favorite, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ret := []*favorites.Favorite{}
+	for _, fav := range s.data.Favorites {
+		if fav.UserId != userId || fav.Team != "" {
+			continue
+		}
+		fav := fav
+		ret = append(ret, &fav)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+	return ret, nil
+}
+
+// ListForTeam implements the favorites.Store interface.
+func (s *FavoriteStore) ListForTeam(ctx context.Context, team string) ([]*favorites.Favorite, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ret := []*favorites.Favorite{}
+	for _, fav := range s.data.Favorites {
+		if fav.Team != team {
+			continue
+		}
+		fav := fav
+		ret = append(ret, &fav)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+	return ret, nil
+}
+
+// ListURLs implements the favorites.Store interface.
+func (s *FavoriteStore) ListURLs(ctx context.Context) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ret := make([]string, 0, len(s.data.Favorites))
+	for _, fav := range s.data.Favorites {
+		ret = append(ret, fav.Url)
+	}
+	return ret, nil
+}
+
+// GetTeam implements the favorites.Store interface.
+func (s *FavoriteStore) GetTeam(ctx context.Context, name string) (*favorites.Team, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	team, ok := s.data.Teams[name]
+	if !ok {
+		return nil, skerr.Wrapf(favorites.ErrTeamNotFound, "Failed to load team %q", name)
+	}
+	team.Members = append([]string{}, team.Members...)
+	return &team, nil
+}
+
+// UpdateTeam implements the favorites.Store interface.
+func (s *FavoriteStore) UpdateTeam(ctx context.Context, team *favorites.Team, userId string, groups favorites.Groups) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if existing, ok := s.data.Teams[team.Name]; ok && !existing.IsMember(userId, groups) {
+		return skerr.Wrapf(favorites.ErrNotTeamMember, "%q is not a member of team %q", userId, team.Name)
+	}
+
+	s.data.Teams[team.Name] = favorites.Team{
+		Name:         team.Name,
+		Members:      append([]string{}, team.Members...),
+		LastModified: time.Now().Unix(),
+	}
+	if err := s.db.Write(favoritesTable, s.data); err != nil {
+		return skerr.Wrapf(err, "Failed to update team %q", team.Name)
+	}
+	return nil
+}
+
+// ListTeams implements the favorites.Store interface.
+func (s *FavoriteStore) ListTeams(ctx context.Context, userId string, groups favorites.Groups) ([]*favorites.Team, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ret := []*favorites.Team{}
+	for _, team := range s.data.Teams {
+		if !team.IsMember(userId, groups) {
+			continue
+		}
+		team := team
+		team.Members = append([]string{}, team.Members...)
+		ret = append(ret, &team)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
+
+// Confirm that *FavoriteStore implements favorites.Store.
+var _ favorites.Store = (*FavoriteStore)(nil)
@@ -0,0 +1,87 @@
+package localfavoritestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/favorites"
+	"go.skia.org/infra/perf/go/localstore"
+)
+
+func TestFavoriteStore_CreateUpdateListDelete(t *testing.T) {
+	ctx := context.Background()
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	store, err := New(db)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Create(ctx, &favorites.SaveRequest{UserId: "a@example.org", Name: "fav1", Url: "https://a.example.org"}))
+	require.NoError(t, store.Create(ctx, &favorites.SaveRequest{UserId: "a@example.org", Name: "fav2", Url: "https://b.example.org"}))
+	require.NoError(t, store.Create(ctx, &favorites.SaveRequest{UserId: "b@example.org", Name: "fav3", Url: "https://c.example.org"}))
+
+	favs, err := store.List(ctx, "a@example.org")
+	require.NoError(t, err)
+	require.Len(t, favs, 2)
+	assert.Equal(t, "fav1", favs[0].Name)
+	assert.Equal(t, "fav2", favs[1].Name)
+
+	require.NoError(t, store.Update(ctx, &favorites.SaveRequest{Name: "renamed", Url: "https://d.example.org"}, favs[0].ID))
+	require.NoError(t, store.Delete(ctx, favs[1].ID))
+
+	// Reopen and confirm the changes were persisted.
+	store, err = New(db)
+	require.NoError(t, err)
+	fav, err := store.Get(ctx, favs[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", fav.Name)
+	assert.Equal(t, "a@example.org", fav.UserId)
+
+	_, err = store.Get(ctx, favs[1].ID)
+	require.Error(t, err)
+}
+
+func TestFavoriteStore_Teams(t *testing.T) {
+	ctx := context.Background()
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	store, err := New(db)
+	require.NoError(t, err)
+
+	require.NoError(t, store.UpdateTeam(ctx, &favorites.Team{Name: "team1", Members: []string{"a@example.org", "@example.com"}}, "a@example.org", nil))
+	require.NoError(t, store.UpdateTeam(ctx, &favorites.Team{Name: "team2", Members: []string{"b@example.org"}}, "b@example.org", nil))
+	err = store.UpdateTeam(ctx, &favorites.Team{Name: "team2", Members: []string{"a@example.org"}}, "a@example.org", nil)
+	require.ErrorIs(t, err, favorites.ErrNotTeamMember)
+	require.NoError(t, store.Create(ctx, &favorites.SaveRequest{UserId: "a@example.org", Name: "mine", Url: "https://a.example.org"}))
+	require.NoError(t, store.Create(ctx, &favorites.SaveRequest{UserId: "a@example.org", Name: "shared", Url: "https://b.example.org", Team: "team1"}))
+
+	favs, err := store.List(ctx, "a@example.org")
+	require.NoError(t, err)
+	require.Len(t, favs, 1)
+	assert.Equal(t, "mine", favs[0].Name)
+
+	// Reopen and confirm the teams were persisted.
+	store, err = New(db)
+	require.NoError(t, err)
+	favs, err = store.ListForTeam(ctx, "team1")
+	require.NoError(t, err)
+	require.Len(t, favs, 1)
+	assert.Equal(t, "shared", favs[0].Name)
+	assert.Equal(t, "team1", favs[0].Team)
+
+	teams, err := store.ListTeams(ctx, "c@example.com", nil)
+	require.NoError(t, err)
+	require.Len(t, teams, 1)
+	assert.Equal(t, "team1", teams[0].Name)
+
+	team, err := store.GetTeam(ctx, "team2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b@example.org"}, team.Members)
+	_, err = store.GetTeam(ctx, "team3")
+	require.ErrorIs(t, err, favorites.ErrTeamNotFound)
+
+	urls, err := store.ListURLs(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"https://a.example.org", "https://b.example.org"}, urls)
+}
@@ -21,6 +21,26 @@ type FavoriteSchema struct {
 	// Stored as a Unix timestamp.
 	LastModified int `sql:"last_modified INT"`
 
+	// The team that owns this favorite, or NULL if the favorite belongs only
+	// to the user.
+	TeamName string `sql:"team_name STRING"`
+
 	// Index used to query favorites based on user id
 	byUserIdIndex struct{} `sql:"INDEX by_user_id (user_id)"`
+
+	// Index used to query favorites based on team name
+	byTeamNameIndex struct{} `sql:"INDEX by_team_name (team_name)"`
+}
+
+// FavoriteTeamSchema represents the SQL schema of the FavoriteTeams table.
+type FavoriteTeamSchema struct {
+	// Unique name of the team.
+	Name string `sql:"name STRING PRIMARY KEY"`
+
+	// The emails of the team members, or domains in the form "@example.com"
+	// that every user in the domain is a member of.
+	Members []string `sql:"members STRING ARRAY"`
+
+	// Stored as a Unix timestamp.
+	LastModified int `sql:"last_modified INT"`
 }
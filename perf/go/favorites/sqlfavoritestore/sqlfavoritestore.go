@@ -6,6 +6,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/jackc/pgx/v4"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/sql/pool"
 	"go.skia.org/infra/perf/go/favorites"
@@ -21,13 +22,25 @@ const (
 	updateFavorite
 	deleteFavorite
 	listFavorites
+	listTeamFavorites
+	listFavoriteURLs
+	getTeam
+	getTeamForUpdate
+	upsertTeam
+	listTeams
 )
 
 // statements holds all the raw SQL statemens.
 var statements = map[statement]string{
 	getFavorite: `
 		SELECT
-			*
+			id,
+			user_id,
+			name,
+			url,
+			description,
+			last_modified,
+			COALESCE(team_name, '')
 		FROM
 			Favorites
 		WHERE
@@ -35,9 +48,9 @@ var statements = map[statement]string{
 	`,
 	insertFavorite: `
 		INSERT INTO
-			Favorites (user_id, name, url, description, last_modified)
+			Favorites (user_id, name, url, description, last_modified, team_name)
 		VALUES
-			($1, $2, $3, $4, $5)
+			($1, $2, $3, $4, $5, NULLIF($6, ''))
 	`,
 
 	updateFavorite: `
@@ -65,6 +78,60 @@ var statements = map[statement]string{
 			Favorites
 		WHERE
 			user_id=$1
+			AND team_name IS NULL
+	`,
+	listTeamFavorites: `
+		SELECT
+			id,
+			user_id,
+			name,
+			url,
+			description
+		FROM
+			Favorites
+		WHERE
+			team_name=$1
+	`,
+	listFavoriteURLs: `
+		SELECT
+			url
+		FROM
+			Favorites
+	`,
+	getTeam: `
+		SELECT
+			name,
+			members,
+			last_modified
+		FROM
+			FavoriteTeams
+		WHERE
+			name=$1
+	`,
+	getTeamForUpdate: `
+		SELECT
+			name,
+			members,
+			last_modified
+		FROM
+			FavoriteTeams
+		WHERE
+			name=$1
+		FOR UPDATE
+	`,
+	upsertTeam: `
+		UPSERT INTO
+			FavoriteTeams (name, members, last_modified)
+		VALUES
+			($1, $2, $3)
+	`,
+	listTeams: `
+		SELECT
+			name,
+			members,
+			last_modified
+		FROM
+			FavoriteTeams
 	`,
 }
 
@@ -91,6 +158,7 @@ func (s *FavoriteStore) Get(ctx context.Context, id int64) (*favorites.Favorite,
 		&fav.Url,
 		&fav.Description,
 		&fav.LastModified,
+		&fav.Team,
 	); err != nil {
 		return nil, skerr.Wrapf(err, "Failed to load favorite.")
 	}
@@ -100,7 +168,7 @@ func (s *FavoriteStore) Get(ctx context.Context, id int64) (*favorites.Favorite,
 // Create implements the favorites.Store interface.
 func (s *FavoriteStore) Create(ctx context.Context, req *favorites.SaveRequest) error {
 	now := time.Now().Unix()
-	if _, err := s.db.Exec(ctx, statements[insertFavorite], req.UserId, req.Name, req.Url, req.Description, now); err != nil {
+	if _, err := s.db.Exec(ctx, statements[insertFavorite], req.UserId, req.Name, req.Url, req.Description, now, req.Team); err != nil {
 		return skerr.Wrapf(err, "Failed to insert favorite")
 	}
 	return nil
@@ -141,3 +209,100 @@ func (s *FavoriteStore) List(ctx context.Context, userId string) ([]*favorites.F
 	}
 	return ret, nil
 }
+
+// ListForTeam implements the favorites.Store interface.
+func (s *FavoriteStore) ListForTeam(ctx context.Context, team string) ([]*favorites.Favorite, error) {
+	rows, err := s.db.Query(ctx, statements[listTeamFavorites], team)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to list favorites for team %q", team)
+	}
+	defer rows.Close()
+
+	ret := []*favorites.Favorite{}
+	for rows.Next() {
+		f := &favorites.Favorite{Team: team}
+		if err := rows.Scan(&f.ID, &f.UserId, &f.Name, &f.Url, &f.Description); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		ret = append(ret, f)
+	}
+	return ret, nil
+}
+
+// ListURLs implements the favorites.Store interface.
+func (s *FavoriteStore) ListURLs(ctx context.Context) ([]string, error) {
+	rows, err := s.db.Query(ctx, statements[listFavoriteURLs])
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to list favorite urls")
+	}
+	defer rows.Close()
+
+	ret := []string{}
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		ret = append(ret, url)
+	}
+	return ret, nil
+}
+
+// GetTeam implements the favorites.Store interface.
+func (s *FavoriteStore) GetTeam(ctx context.Context, name string) (*favorites.Team, error) {
+	team := &favorites.Team{}
+	if err := s.db.QueryRow(ctx, statements[getTeam], name).Scan(&team.Name, &team.Members, &team.LastModified); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, skerr.Wrapf(favorites.ErrTeamNotFound, "Failed to load team %q", name)
+		}
+		return nil, skerr.Wrapf(err, "Failed to load team %q", name)
+	}
+	return team, nil
+}
+
+// UpdateTeam implements the favorites.Store interface.
+func (s *FavoriteStore) UpdateTeam(ctx context.Context, team *favorites.Team, userId string, groups favorites.Groups) error {
+	err := s.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		existing := &favorites.Team{}
+		err := tx.QueryRow(ctx, statements[getTeamForUpdate], team.Name).Scan(&existing.Name, &existing.Members, &existing.LastModified)
+		if err == nil {
+			if !existing.IsMember(userId, groups) {
+				return skerr.Wrapf(favorites.ErrNotTeamMember, "%q is not a member of team %q", userId, team.Name)
+			}
+		} else if err != pgx.ErrNoRows {
+			return skerr.Wrap(err)
+		}
+		if _, err := tx.Exec(ctx, statements[upsertTeam], team.Name, team.Members, time.Now().Unix()); err != nil {
+			return skerr.Wrap(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return skerr.Wrapf(err, "Failed to update team %q", team.Name)
+	}
+	return nil
+}
+
+// ListTeams implements the favorites.Store interface.
+func (s *FavoriteStore) ListTeams(ctx context.Context, userId string, groups favorites.Groups) ([]*favorites.Team, error) {
+	rows, err := s.db.Query(ctx, statements[listTeams])
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to list teams")
+	}
+	defer rows.Close()
+
+	ret := []*favorites.Team{}
+	for rows.Next() {
+		team := &favorites.Team{}
+		if err := rows.Scan(&team.Name, &team.Members, &team.LastModified); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		if team.IsMember(userId, groups) {
+			ret = append(ret, team)
+		}
+	}
+	return ret, nil
+}
+
+// Confirm that *FavoriteStore implements favorites.Store.
+var _ favorites.Store = (*FavoriteStore)(nil)
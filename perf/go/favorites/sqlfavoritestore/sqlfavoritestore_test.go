@@ -303,3 +303,78 @@ func TestList_ForUserId_EmptyList(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, favFromDb, 0)
 }
+
+func TestList_TeamFavorites_NotListedForUser(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setUp(t)
+
+	require.NoError(t, store.Create(ctx, &favorites.SaveRequest{UserId: "a@b.com", Name: "fav1", Url: "url/fav1"}))
+	require.NoError(t, store.Create(ctx, &favorites.SaveRequest{UserId: "a@b.com", Name: "fav2", Url: "url/fav2", Team: "team1"}))
+
+	favs, err := store.List(ctx, "a@b.com")
+	require.NoError(t, err)
+	require.Len(t, favs, 1)
+	require.Equal(t, "fav1", favs[0].Name)
+
+	favs, err = store.ListForTeam(ctx, "team1")
+	require.NoError(t, err)
+	require.Len(t, favs, 1)
+	require.Equal(t, "fav2", favs[0].Name)
+	require.Equal(t, "a@b.com", favs[0].UserId)
+	require.Equal(t, "team1", favs[0].Team)
+
+	fav, err := store.Get(ctx, favs[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, "team1", fav.Team)
+
+	urls, err := store.ListURLs(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"url/fav1", "url/fav2"}, urls)
+}
+
+func TestUpdateTeam_CreateAndReplaceMembers(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setUp(t)
+
+	require.NoError(t, store.UpdateTeam(ctx, &favorites.Team{Name: "team1", Members: []string{"a@b.com"}}, "a@b.com", nil))
+	require.NoError(t, store.UpdateTeam(ctx, &favorites.Team{Name: "team2", Members: []string{"@c.com"}}, "x@c.com", nil))
+
+	team, err := store.GetTeam(ctx, "team1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a@b.com"}, team.Members)
+
+	require.NoError(t, store.UpdateTeam(ctx, &favorites.Team{Name: "team1", Members: []string{"a@b.com", "x@c.com"}}, "a@b.com", nil))
+	team, err = store.GetTeam(ctx, "team1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a@b.com", "x@c.com"}, team.Members)
+
+	teams, err := store.ListTeams(ctx, "x@c.com", nil)
+	require.NoError(t, err)
+	require.Len(t, teams, 2)
+
+	teams, err = store.ListTeams(ctx, "a@b.com", nil)
+	require.NoError(t, err)
+	require.Len(t, teams, 1)
+	require.Equal(t, "team1", teams[0].Name)
+}
+
+func TestUpdateTeam_NotAMember_ReturnsError(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setUp(t)
+
+	require.NoError(t, store.UpdateTeam(ctx, &favorites.Team{Name: "team1", Members: []string{"a@b.com"}}, "a@b.com", nil))
+	err := store.UpdateTeam(ctx, &favorites.Team{Name: "team1", Members: []string{"x@c.com"}}, "x@c.com", nil)
+	require.ErrorIs(t, err, favorites.ErrNotTeamMember)
+
+	team, err := store.GetTeam(ctx, "team1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a@b.com"}, team.Members)
+}
+
+func TestGetTeam_NonExistentTeam_ReturnsError(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setUp(t)
+
+	_, err := store.GetTeam(ctx, "unknown")
+	require.ErrorIs(t, err, favorites.ErrTeamNotFound)
+}
@@ -2,8 +2,26 @@ package favorites
 
 import (
 	"context"
+	"errors"
+	"strings"
 )
 
+// ErrTeamNotFound is returned by Store.GetTeam if the team doesn't exist.
+var ErrTeamNotFound = errors.New("team not found")
+
+// ErrNotTeamMember is returned by Store.UpdateTeam if the user isn't a member
+// of the existing team.
+var ErrNotTeamMember = errors.New("not a member of the team")
+
+// GroupPrefix starts the Team.Members entries that are groups of users.
+const GroupPrefix = "group:"
+
+// Groups looks up the members of named groups of users.
+type Groups interface {
+	// IsMember returns true if the user is a member of the named group.
+	IsMember(group, userId string) bool
+}
+
 // Favorite is a struct that represents a favorite.
 type Favorite struct {
 	ID           int64
@@ -12,6 +30,10 @@ type Favorite struct {
 	Url          string
 	Description  string
 	LastModified int64
+
+	// Team is the name of the team that owns the favorite, or empty if the
+	// favorite belongs only to UserId.
+	Team string
 }
 
 type SaveRequest struct {
@@ -19,6 +41,44 @@ type SaveRequest struct {
 	Name        string
 	Url         string
 	Description string
+
+	// Team, if not empty, makes the favorite a team favorite, which can be
+	// seen and edited by every member of the team.
+	Team string
+}
+
+// Team is a named group of users that share a collection of favorites.
+type Team struct {
+	Name string
+
+	// Members are the emails of the users that can see and edit the team's
+	// favorites. An entry of the form "@example.com" makes every user in
+	// that domain a member, and an entry of the form "group:some-group" makes
+	// every member of that group a member.
+	Members []string
+
+	LastModified int64
+}
+
+// IsMember returns true if the given user is a member of the team. Group
+// entries in Members are looked up in groups, and match nobody if groups is
+// nil.
+func (t *Team) IsMember(userId string, groups Groups) bool {
+	if userId == "" {
+		return false
+	}
+	for _, member := range t.Members {
+		if member == userId {
+			return true
+		}
+		if strings.HasPrefix(member, "@") && strings.HasSuffix(userId, member) {
+			return true
+		}
+		if groups != nil && strings.HasPrefix(member, GroupPrefix) && groups.IsMember(strings.TrimPrefix(member, GroupPrefix), userId) {
+			return true
+		}
+	}
+	return false
 }
 
 // Store is the interface used to persist Favorites.
@@ -35,6 +95,27 @@ type Store interface {
 	// Delete removes the Favorite with the given id.
 	Delete(ctx context.Context, id int64) error
 
-	// List retrieves all the Favorites by user id (email).
+	// List retrieves all the Favorites by user id (email), not including
+	// team favorites.
 	List(ctx context.Context, userId string) ([]*Favorite, error)
+
+	// ListForTeam retrieves all the Favorites owned by the given team.
+	ListForTeam(ctx context.Context, team string) ([]*Favorite, error)
+
+	// ListURLs returns the urls of all the favorites, personal and team
+	// owned.
+	ListURLs(ctx context.Context) ([]string, error)
+
+	// GetTeam fetches the team with the given name. The error wraps
+	// ErrTeamNotFound if the team doesn't exist.
+	GetTeam(ctx context.Context, name string) (*Team, error)
+
+	// UpdateTeam creates the team, or replaces the members of an existing
+	// team that userId is a member of. The membership check and the write
+	// are done atomically. The error wraps ErrNotTeamMember if the team
+	// exists and userId isn't a member of it.
+	UpdateTeam(ctx context.Context, team *Team, userId string, groups Groups) error
+
+	// ListTeams returns all the teams the given user is a member of.
+	ListTeams(ctx context.Context, userId string, groups Groups) ([]*Team, error)
 }
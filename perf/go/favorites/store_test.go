@@ -0,0 +1,37 @@
+package favorites
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGroups is a Groups that maps group names to their members.
+type fakeGroups map[string][]string
+
+func (f fakeGroups) IsMember(group, userId string) bool {
+	for _, member := range f[group] {
+		if member == userId {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTeamIsMember(t *testing.T) {
+	team := &Team{Members: []string{"a@example.org", "@example.com"}}
+	assert.True(t, team.IsMember("a@example.org", nil))
+	assert.True(t, team.IsMember("b@example.com", nil))
+	assert.False(t, team.IsMember("b@example.org", nil))
+	assert.False(t, team.IsMember("b@notexample.com", nil))
+	assert.False(t, team.IsMember("", nil))
+}
+
+func TestTeamIsMember_GroupMembers(t *testing.T) {
+	team := &Team{Members: []string{"a@example.org", "group:perf-sheriffs"}}
+	groups := fakeGroups{"perf-sheriffs": []string{"b@example.org"}}
+	assert.True(t, team.IsMember("a@example.org", groups))
+	assert.True(t, team.IsMember("b@example.org", groups))
+	assert.False(t, team.IsMember("c@example.org", groups))
+	assert.False(t, team.IsMember("b@example.org", nil))
+}
@@ -23,17 +23,20 @@ import (
 	"sync"
 	"time"
 
+	"cloud.google.com/go/pubsub"
 	"github.com/go-chi/chi/v5"
 	"github.com/unrolled/secure"
 	"go.opencensus.io/trace"
 	"go.skia.org/infra/go/alogin"
 	"go.skia.org/infra/go/alogin/proxylogin"
 	"go.skia.org/infra/go/auditlog"
+	"go.skia.org/infra/go/auth"
 	"go.skia.org/infra/go/baseapp"
 	"go.skia.org/infra/go/calc"
 	"go.skia.org/infra/go/httputils"
 	"go.skia.org/infra/go/metrics2"
 	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/pubsub/sub"
 	"go.skia.org/infra/go/query"
 	"go.skia.org/infra/go/roles"
 	"go.skia.org/infra/go/skerr"
@@ -58,17 +61,23 @@ import (
 	"go.skia.org/infra/perf/go/git/provider"
 	"go.skia.org/infra/perf/go/graphsshortcut"
 	"go.skia.org/infra/perf/go/ingest/format"
+	"go.skia.org/infra/perf/go/ingestevents"
+	"go.skia.org/infra/perf/go/noise"
 	"go.skia.org/infra/perf/go/notify"
 	"go.skia.org/infra/perf/go/notifytypes"
+	"go.skia.org/infra/perf/go/openmetrics"
 	"go.skia.org/infra/perf/go/pinpoint"
+	"go.skia.org/infra/perf/go/pivot"
 	"go.skia.org/infra/perf/go/progress"
 	"go.skia.org/infra/perf/go/psrefresh"
 	"go.skia.org/infra/perf/go/regression"
 	"go.skia.org/infra/perf/go/regression/continuous"
 	"go.skia.org/infra/perf/go/shortcut"
+	"go.skia.org/infra/perf/go/slo"
 	"go.skia.org/infra/perf/go/subscription"
 	"go.skia.org/infra/perf/go/tracestore"
 	"go.skia.org/infra/perf/go/tracing"
+	"go.skia.org/infra/perf/go/triagerules"
 	"go.skia.org/infra/perf/go/trybot/results"
 	"go.skia.org/infra/perf/go/trybot/results/dfloader"
 	"go.skia.org/infra/perf/go/types"
@@ -76,6 +85,7 @@ import (
 	"go.skia.org/infra/perf/go/urlprovider"
 	pp_service "go.skia.org/infra/pinpoint/go/service"
 	pinpoint_pb "go.skia.org/infra/pinpoint/proto/v1"
+	"golang.org/x/oauth2/google"
 )
 
 const (
@@ -107,6 +117,10 @@ const (
 	// making a request that involves the database. For more complex requests
 	// use config.QueryMaxRuntime.
 	defaultDatabaseTimeout = time.Minute
+
+	// queryCacheSubscriptionExpiration is how long the per-host ingestion event
+	// subscriptions used to invalidate the query cache are kept once unused.
+	queryCacheSubscriptionExpiration = 7 * 24 * time.Hour
 )
 
 var (
@@ -137,6 +151,14 @@ type Frontend struct {
 
 	favStore favorites.Store
 
+	// teamGroups looks up the groups that are members of favorite teams. It
+	// is nil when running locally, in which case groups have no members.
+	teamGroups favorites.Groups
+
+	triageRuleStore triagerules.Store
+
+	noiseStore noise.Store
+
 	continuous []*continuous.Continuous
 
 	// provides access to the ingested files.
@@ -160,8 +182,20 @@ type Frontend struct {
 
 	dfBuilder dataframe.DataFrameBuilder
 
+	// queryCache caches the results of queries in dfBuilder, it is nil if
+	// caching isn't enabled.
+	queryCache *dfbuilder.QueryCache
+
 	trybotResultsLoader results.Loader
 
+	// openMetricsExporter serves the latest values of the configured traces
+	// in the OpenMetrics format, it is nil if no queries are configured.
+	openMetricsExporter *openmetrics.Exporter
+
+	// sloReporter serves the triage and bug filing latency per subscription,
+	// it is nil if no SLOs are configured.
+	sloReporter *slo.Reporter
+
 	// distFileSystem is the ./dist directory of files produced by Bazel.
 	distFileSystem http.FileSystem
 
@@ -337,6 +371,46 @@ func newParamsetProvider(pf *psrefresh.ParamSetRefresher) regression.ParamsetPro
 	}
 }
 
+// invalidateQueryCacheOnIngestEvents listens for ingestion events and
+// invalidates the cached query results for the tiles that data was written to.
+// It only returns if ctx is cancelled.
+func (f *Frontend) invalidateQueryCacheOnIngestEvents(ctx context.Context) {
+	// Validation guarantees the topic is set when the query cache is enabled.
+	topicName := config.Config.IngestionConfig.FileIngestionTopicName
+	// Every frontend needs to see every event since each may have its own
+	// in-memory cache, so use a subscription per host.
+	expirationPolicy := queryCacheSubscriptionExpiration
+	subscription, err := sub.NewWithSubNameProviderAndExpirationPolicy(ctx, f.flags.Local, config.Config.IngestionConfig.SourceConfig.Project, topicName, sub.NewBroadcastNameProvider(f.flags.Local, topicName), &expirationPolicy, 1)
+	if err != nil {
+		sklog.Errorf("Failed to subscribe to ingestion events, cached query results will not be invalidated: %s", err)
+		return
+	}
+	for {
+		err := subscription.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+			// There's no point in retrying messages, so always Ack.
+			msg.Ack()
+			ie, err := ingestevents.DecodePubSubBody(msg.Data)
+			if err != nil {
+				sklog.Errorf("Failed to decode ingestion PubSub event: %s", err)
+				return
+			}
+			if ie.CommitNumber == types.BadCommitNumber {
+				// Older ingesters don't say which commit the data was
+				// written to, so it could be in any tile.
+				f.queryCache.InvalidateAll(ctx)
+				return
+			}
+			f.queryCache.Invalidate(ctx, f.traceStore.TileNumber(ie.CommitNumber))
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			sklog.Errorf("Failed receiving ingestion events: %s", err)
+		}
+	}
+}
+
 // initialize the application.
 func (f *Frontend) initialize() {
 	rand.Seed(time.Now().UnixNano())
@@ -461,12 +535,21 @@ func (f *Frontend) initialize() {
 
 	sklog.Info("About to build dfbuilder.")
 
+	f.queryCache, err = builders.NewQueryCacheFromConfig(config.Config)
+	if err != nil {
+		sklog.Fatalf("Failed to build query cache: %s", err)
+	}
+	if f.queryCache != nil {
+		go f.invalidateQueryCacheOnIngestEvents(ctx)
+	}
+
 	sklog.Info("Filter parent traces: %s", config.Config.FilterParentTraces)
 	f.dfBuilder = dfbuilder.NewDataFrameBuilderFromTraceStore(
 		f.perfGit,
 		f.traceStore,
 		f.flags.NumParamSetsForQueries,
-		dfbuilder.Filtering(config.Config.FilterParentTraces))
+		dfbuilder.Filtering(config.Config.FilterParentTraces),
+		f.queryCache)
 
 	if config.Config.FetchChromePerfAnomalies {
 		f.anomalyApiClient, err = chromeperf.NewAnomalyApiClient(ctx)
@@ -489,10 +572,21 @@ func (f *Frontend) initialize() {
 		}
 	}
 
+	f.openMetricsExporter, err = builders.NewOpenMetricsExporterFromConfig(config.Config, f.dfBuilder)
+	if err != nil {
+		sklog.Fatalf("Failed to build OpenMetrics exporter: %s", err)
+	}
+
+	// The weekly summary email is sent by the maintenance task.
+	f.sloReporter, err = builders.NewSLOReporterFromConfig(ctx, config.Config, nil)
+	if err != nil {
+		sklog.Fatalf("Failed to build SLO reporter: %s", err)
+	}
+
 	f.urlProvider = urlprovider.New(f.perfGit)
 
 	// TODO(jcgregorio) Implement store.TryBotStore and add a reference to it here.
-	f.trybotResultsLoader = dfloader.New(f.dfBuilder, nil, f.perfGit)
+	f.trybotResultsLoader = dfloader.New(f.dfBuilder, nil, f.perfGit, f.traceStore)
 
 	alerts.DefaultSparse = f.flags.DefaultSparse
 
@@ -513,7 +607,7 @@ func (f *Frontend) initialize() {
 	if f.flags.NoEmail {
 		config.Config.NotifyConfig.Notifications = notifytypes.None
 	}
-	f.notifier, err = notify.New(ctx, &config.Config.NotifyConfig, config.Config.URL, f.flags.CommitRangeURL)
+	f.notifier, err = notify.New(ctx, &config.Config.NotifyConfig, config.Config.URL, f.flags.CommitRangeURL, f.perfGit, f.traceStore)
 	if err != nil {
 		sklog.Fatal(err)
 	}
@@ -537,18 +631,35 @@ func (f *Frontend) initialize() {
 	if err != nil {
 		sklog.Fatalf("Failed to build favorite.Store: %s", err)
 	}
+	if !f.flags.Local {
+		ts, err := google.DefaultTokenSource(ctx, auth.ScopeUserinfoEmail)
+		if err != nil {
+			sklog.Fatalf("Failed to create token source: %s", err)
+		}
+		f.teamGroups = favorites.NewInfraAuthGroups(httputils.DefaultClientConfig().WithTokenSource(ts).With2xxOnly().Client())
+	}
+
+	f.triageRuleStore, err = builders.NewTriageRuleStoreFromConfig(ctx, cfg)
+	if err != nil {
+		sklog.Fatalf("Failed to build triagerules.Store: %s", err)
+	}
+
+	f.noiseStore, err = builders.NewNoiseStoreFromConfig(ctx, cfg)
+	if err != nil {
+		sklog.Fatalf("Failed to build noise.Store: %s", err)
+	}
 
 	paramsProvider := newParamsetProvider(f.paramsetRefresher)
 
-	f.dryrunRequests = dryrun.New(f.perfGit, f.progressTracker, f.shortcutStore, f.dfBuilder, paramsProvider)
+	f.dryrunRequests = dryrun.New(f.perfGit, f.progressTracker, f.shortcutStore, f.noiseStore, f.dfBuilder, paramsProvider)
 
 	if f.flags.DoClustering {
 		go func() {
 			for i := 0; i < f.flags.NumContinuousParallel; i++ {
 				// Start running continuous clustering looking for regressions.
 				time.Sleep(startClusterDelay)
-				c := continuous.New(f.perfGit, f.shortcutStore, f.configProvider, f.regStore, f.notifier, paramsProvider, *f.urlProvider,
-					f.dfBuilder, cfg, f.flags)
+				c := continuous.New(f.perfGit, f.shortcutStore, f.noiseStore, f.configProvider, f.regStore, f.notifier, paramsProvider, *f.urlProvider,
+					f.dfBuilder, f.traceStore, cfg, f.flags, triagerules.NewEngine(f.triageRuleStore))
 				f.continuous = append(f.continuous, c)
 				go c.Run(context.Background())
 			}
@@ -743,7 +854,8 @@ func (f *Frontend) frameStartHandler(w http.ResponseWriter, r *http.Request) {
 			f.perfGit,
 			f.traceStore,
 			f.flags.NumParamSetsForQueries,
-			dfbuilder.Filtering(false))
+			dfbuilder.Filtering(false),
+			f.queryCache)
 	}
 	f.progressTracker.Add(fr.Progress)
 	go func() {
@@ -766,6 +878,46 @@ func (f *Frontend) frameStartHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// pivotCSVHandler takes a POST'd FrameRequest with a pivot.Request and returns
+// the pivoted results as CSV.
+//
+// Unlike frameStartHandler the request is processed synchronously, so it is
+// suitable for generating reports from scripts.
+func (f *Frontend) pivotCSVHandler(w http.ResponseWriter, r *http.Request) {
+	fr := frame.NewFrameRequest()
+	if err := json.NewDecoder(r.Body).Decode(fr); err != nil {
+		httputils.ReportError(w, err, "Failed to decode JSON.", http.StatusBadRequest)
+		return
+	}
+	auditlog.LogWithUser(r, f.loginProvider.LoggedInAs(r).String(), "pivot_csv", fr)
+	if fr.Pivot == nil {
+		httputils.ReportError(w, fmt.Errorf("Missing pivot."), "A pivot request is required.", http.StatusBadRequest)
+		return
+	}
+	if err := fr.Pivot.Valid(); err != nil {
+		httputils.ReportError(w, err, "Invalid pivot request.", http.StatusBadRequest)
+		return
+	}
+	if len(fr.Formulas) == 0 && len(fr.Queries) == 0 && fr.Keys == "" {
+		httputils.ReportError(w, fmt.Errorf("Invalid query."), "Empty queries are not allowed.", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), config.QueryMaxRunTime)
+	defer cancel()
+	df, err := frame.DataFrameFromFrameRequest(ctx, fr, f.perfGit, f.dfBuilder, f.shortcutStore)
+	if err != nil {
+		httputils.ReportError(w, err, "Failed to build pivot.", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=pivot.csv")
+	if err := pivot.WriteCSV(w, *fr.Pivot, df); err != nil {
+		sklog.Errorf("Failed to write CSV: %s", err)
+	}
+}
+
 func (f *Frontend) alertGroupQueryHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), defaultDatabaseTimeout)
 	defer cancel()
@@ -802,7 +954,8 @@ func (f *Frontend) alertGroupQueryHandler(w http.ResponseWriter, r *http.Request
 			}
 
 			shortcutObj := graphsshortcut.GraphsShortcut{
-				Graphs: graphs,
+				Graphs:    graphs,
+				CreatedBy: string(f.loginProvider.LoggedInAs(r)),
 			}
 
 			shortcutId, err := f.graphsShortcutStore.InsertShortcut(ctx, &shortcutObj)
@@ -988,6 +1141,10 @@ type CIDHandlerResponse struct {
 	// LogEntry is the full git log entry for the first commit in the
 	// CommitSlice.
 	LogEntry string `json:"logEntry"`
+
+	// DependencyRolls are the dependency repo commits rolled in by the first
+	// commit in the CommitSlice.
+	DependencyRolls []provider.DependencyRoll `json:"dependencyRolls"`
 }
 
 // cidHandler takes the POST'd list of dataframe.ColumnHeaders, and returns a
@@ -1013,10 +1170,19 @@ func (f *Frontend) cidHandler(w http.ResponseWriter, r *http.Request) {
 		logEntry = "<<< Failed to load >>>"
 		sklog.Errorf("Failed to get log entry: %s", err)
 	}
+	dependencyRolls := []provider.DependencyRoll{}
+	if previous, err := f.perfGit.PreviousCommitNumberFromCommitNumber(ctx, cids[0]); err == nil {
+		dependencyRolls, err = f.perfGit.DependencyRolls(ctx, previous, cids[0])
+		if err != nil {
+			dependencyRolls = []provider.DependencyRoll{}
+			sklog.Errorf("Failed to get dependency rolls: %s", err)
+		}
+	}
 
 	resp := CIDHandlerResponse{
-		CommitSlice: commits,
-		LogEntry:    logEntry,
+		CommitSlice:     commits,
+		LogEntry:        logEntry,
+		DependencyRolls: dependencyRolls,
 	}
 
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -1053,7 +1219,7 @@ func (f *Frontend) clusterStartHandler(w http.ResponseWriter, r *http.Request) {
 
 	go func() {
 		// This intentionally does not use r.Context() because we want it to outlive this request.
-		err := regression.ProcessRegressions(context.Background(), req, cb, f.perfGit, f.shortcutStore, f.dfBuilder, f.paramsetRefresher.Get(), regression.ExpandBaseAlertByGroupBy, regression.ReturnOnError, config.Config.AnomalyConfig)
+		err := regression.ProcessRegressions(context.Background(), req, cb, f.perfGit, f.shortcutStore, f.noiseStore, f.dfBuilder, f.paramsetRefresher.Get(), regression.ExpandBaseAlertByGroupBy, regression.ReturnOnError, config.Config.AnomalyConfig)
 		if err != nil {
 			sklog.Errorf("ProcessRegressions returned: %s", err)
 			req.Progress.Error("Failed to load data.")
@@ -1086,7 +1252,13 @@ func (f *Frontend) keysHandler(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 	w.Header().Set("Content-Type", "application/json")
 
-	id, err := f.shortcutStore.Insert(ctx, r.Body)
+	sc := &shortcut.Shortcut{}
+	if err := json.NewDecoder(r.Body).Decode(sc); err != nil {
+		httputils.ReportError(w, err, "Unable to read shortcut body.", http.StatusBadRequest)
+		return
+	}
+	sc.CreatedBy = string(f.loginProvider.LoggedInAs(r))
+	id, err := f.shortcutStore.InsertShortcut(ctx, sc)
 	if err != nil {
 		httputils.ReportError(w, err, "Error inserting shortcut.", http.StatusInternalServerError)
 		return
@@ -1134,6 +1306,7 @@ func (f *Frontend) createGraphsShortcutHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	shortcut.CreatedBy = string(f.loginProvider.LoggedInAs(r))
 	id, err := f.graphsShortcutStore.InsertShortcut(ctx, shortcut)
 	if err != nil {
 		httputils.ReportError(w, err, "Error inserting graphs shortcut.", http.StatusInternalServerError)
@@ -1907,6 +2080,108 @@ func (f *Frontend) alertDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// defaultTriageRuleAuditLimit is the number of audit entries returned by
+// triageRuleAuditHandler if no limit is given.
+const defaultTriageRuleAuditLimit = 100
+
+func (f *Frontend) triageRuleListHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), defaultDatabaseTimeout)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/json")
+
+	show := chi.URLParam(r, "show")
+	resp, err := f.triageRuleStore.List(ctx, show == "true")
+	if err != nil {
+		httputils.ReportError(w, err, "Failed to retrieve triage rules.", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		sklog.Errorf("Failed to write JSON response: %s", err)
+	}
+}
+
+func (f *Frontend) triageRuleNewHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(triagerules.NewRule()); err != nil {
+		sklog.Errorf("Failed to write JSON response: %s", err)
+	}
+}
+
+func (f *Frontend) triageRuleUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), defaultDatabaseTimeout)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/json")
+
+	rule := triagerules.NewRule()
+	if err := json.NewDecoder(r.Body).Decode(rule); err != nil {
+		httputils.ReportError(w, err, "Failed to decode JSON.", http.StatusInternalServerError)
+		return
+	}
+
+	if !f.isEditor(w, r, "triage-rule-update", rule) {
+		return
+	}
+
+	if err := rule.Validate(); err != nil {
+		httputils.ReportError(w, err, "Invalid triage rule.", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.triageRuleStore.Save(ctx, rule); err != nil {
+		httputils.ReportError(w, err, "Failed to save triage rule.", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(rule); err != nil {
+		sklog.Errorf("Failed to write JSON response: %s", err)
+	}
+}
+
+func (f *Frontend) triageRuleDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), defaultDatabaseTimeout)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/json")
+
+	sid := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(sid, 10, 64)
+	if err != nil {
+		httputils.ReportError(w, err, "Failed to parse triage rule id.", http.StatusBadRequest)
+		return
+	}
+
+	if !f.isEditor(w, r, "triage-rule-delete", sid) {
+		return
+	}
+
+	if err := f.triageRuleStore.Delete(ctx, id); err != nil {
+		httputils.ReportError(w, err, "Failed to delete the triage rule.", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (f *Frontend) triageRuleAuditHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), defaultDatabaseTimeout)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/json")
+
+	limit := defaultTriageRuleAuditLimit
+	if s := r.FormValue("limit"); s != "" {
+		var err error
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit <= 0 {
+			httputils.ReportError(w, err, "Invalid limit.", http.StatusBadRequest)
+			return
+		}
+	}
+	resp, err := f.triageRuleStore.ListAudit(ctx, limit)
+	if err != nil {
+		httputils.ReportError(w, err, "Failed to retrieve the triage rule audit trail.", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		sklog.Errorf("Failed to write JSON response: %s", err)
+	}
+}
+
 // TryBugRequest is a request to try a bug template URI.
 type TryBugRequest struct {
 	BugURITemplate string `json:"bug_uri_template"`
@@ -2037,21 +2312,235 @@ func (f *Frontend) createBisectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// favoritesHandler returns the favorites config for the instance
+// favoritesHandler returns the favorites config for the instance, followed by
+// a section for each team the user is a member of.
 func (f *Frontend) favoritesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), defaultDatabaseTimeout)
+	defer cancel()
 	w.Header().Set("Content-Type", "application/json")
 
 	fav := config.Favorites{
 		Sections: []config.FavoritesSectionConfig{},
 	}
 	if config.Config.Favorites.Sections != nil {
-		fav = config.Config.Favorites
+		fav.Sections = append(fav.Sections, config.Config.Favorites.Sections...)
+	}
+	if f.favStore != nil {
+		fav.Sections = append(fav.Sections, f.teamFavoriteSections(ctx, string(f.loginProvider.LoggedInAs(r)))...)
 	}
 	if err := json.NewEncoder(w).Encode(fav); err != nil {
 		sklog.Errorf("Error writing the Favorites json to response: %s", err)
 	}
 }
 
+// teamFavoriteSections returns a favorites section for each team the user is
+// a member of. Teams that fail to load are skipped.
+func (f *Frontend) teamFavoriteSections(ctx context.Context, user string) []config.FavoritesSectionConfig {
+	ret := []config.FavoritesSectionConfig{}
+	if user == "" {
+		return ret
+	}
+	teams, err := f.favStore.ListTeams(ctx, user, f.teamGroups)
+	if err != nil {
+		sklog.Errorf("Failed to load teams for %q: %s", user, err)
+		return ret
+	}
+	for _, team := range teams {
+		favs, err := f.favStore.ListForTeam(ctx, team.Name)
+		if err != nil {
+			sklog.Errorf("Failed to load favorites for team %q: %s", team.Name, err)
+			continue
+		}
+		section := config.FavoritesSectionConfig{
+			Name:  team.Name,
+			Links: []config.FavoritesSectionLinkConfig{},
+		}
+		for _, fav := range favs {
+			section.Links = append(section.Links, config.FavoritesSectionLinkConfig{
+				Text:        fav.Name,
+				Href:        fav.Url,
+				Description: fav.Description,
+			})
+		}
+		ret = append(ret, section)
+	}
+	return ret
+}
+
+// UpdateFavoriteTeamRequest is the JSON body of a request to
+// updateFavoriteTeamHandler.
+type UpdateFavoriteTeamRequest struct {
+	Name string `json:"name"`
+
+	// Members are emails, domains in the form "@example.com", or groups in
+	// the form "group:some-group".
+	Members []string `json:"members"`
+}
+
+// SaveTeamFavoriteRequest is the JSON body of a request to
+// saveTeamFavoriteHandler.
+type SaveTeamFavoriteRequest struct {
+	// ID of the favorite to update, or 0 to create a new favorite.
+	ID          int64  `json:"id"`
+	Team        string `json:"team"`
+	Name        string `json:"name"`
+	Url         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// DeleteTeamFavoriteRequest is the JSON body of a request to
+// deleteTeamFavoriteHandler.
+type DeleteTeamFavoriteRequest struct {
+	ID int64 `json:"id"`
+}
+
+// isTeamMember reports an error and returns false if the logged in user isn't
+// a member of the named team.
+func (f *Frontend) isTeamMember(ctx context.Context, w http.ResponseWriter, r *http.Request, teamName string) bool {
+	user := string(f.loginProvider.LoggedInAs(r))
+	if user == "" {
+		httputils.ReportError(w, fmt.Errorf("Not logged in."), "You must be logged in to complete this action.", http.StatusUnauthorized)
+		return false
+	}
+	team, err := f.favStore.GetTeam(ctx, teamName)
+	if err != nil {
+		httputils.ReportError(w, err, "Failed to load team.", http.StatusInternalServerError)
+		return false
+	}
+	if !team.IsMember(user, f.teamGroups) {
+		httputils.ReportError(w, fmt.Errorf("%q is not a member of %q", user, teamName), "You must be a member of the team to complete this action.", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// updateFavoriteTeamHandler creates a team, or replaces the members of a team
+// the user is a member of.
+func (f *Frontend) updateFavoriteTeamHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), defaultDatabaseTimeout)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/json")
+
+	var req UpdateFavoriteTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputils.ReportError(w, err, "Failed to decode JSON.", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" {
+		httputils.ReportError(w, fmt.Errorf("Missing team name."), "A team name is required.", http.StatusBadRequest)
+		return
+	}
+	user := string(f.loginProvider.LoggedInAs(r))
+	if user == "" {
+		httputils.ReportError(w, fmt.Errorf("Not logged in."), "You must be logged in to complete this action.", http.StatusUnauthorized)
+		return
+	}
+	team := &favorites.Team{Name: req.Name, Members: req.Members}
+	if !team.IsMember(user, f.teamGroups) {
+		httputils.ReportError(w, fmt.Errorf("%q is not in the new members of %q", user, req.Name), "You can't remove yourself from a team.", http.StatusBadRequest)
+		return
+	}
+	// Anyone can create a new team, but only members can change one, which
+	// the store checks in the same transaction as the write.
+	auditlog.LogWithUser(r, user, "update_favorite_team", req)
+	if err := f.favStore.UpdateTeam(ctx, team, user, f.teamGroups); err != nil {
+		if errors.Is(err, favorites.ErrNotTeamMember) {
+			httputils.ReportError(w, err, "You must be a member of the team to complete this action.", http.StatusForbidden)
+			return
+		}
+		httputils.ReportError(w, err, "Failed to update team.", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(map[string]string{}); err != nil {
+		sklog.Errorf("Failed to write or encode output: %s", err)
+	}
+}
+
+// saveTeamFavoriteHandler creates or updates a favorite owned by a team the
+// user is a member of.
+func (f *Frontend) saveTeamFavoriteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), defaultDatabaseTimeout)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/json")
+
+	var req SaveTeamFavoriteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputils.ReportError(w, err, "Failed to decode JSON.", http.StatusBadRequest)
+		return
+	}
+	if req.ID != 0 {
+		existing, err := f.favStore.Get(ctx, req.ID)
+		if err != nil {
+			httputils.ReportError(w, err, "Failed to load favorite.", http.StatusInternalServerError)
+			return
+		}
+		req.Team = existing.Team
+	}
+	if req.Team == "" {
+		httputils.ReportError(w, fmt.Errorf("Not a team favorite."), "The favorite must belong to a team.", http.StatusBadRequest)
+		return
+	}
+	if !f.isTeamMember(ctx, w, r, req.Team) {
+		return
+	}
+	user := string(f.loginProvider.LoggedInAs(r))
+	auditlog.LogWithUser(r, user, "save_team_favorite", req)
+	saveRequest := &favorites.SaveRequest{
+		UserId:      user,
+		Name:        req.Name,
+		Url:         req.Url,
+		Description: req.Description,
+		Team:        req.Team,
+	}
+	var err error
+	if req.ID == 0 {
+		err = f.favStore.Create(ctx, saveRequest)
+	} else {
+		err = f.favStore.Update(ctx, saveRequest, req.ID)
+	}
+	if err != nil {
+		httputils.ReportError(w, err, "Failed to save favorite.", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(map[string]string{}); err != nil {
+		sklog.Errorf("Failed to write or encode output: %s", err)
+	}
+}
+
+// deleteTeamFavoriteHandler deletes a favorite owned by a team the user is a
+// member of.
+func (f *Frontend) deleteTeamFavoriteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), defaultDatabaseTimeout)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/json")
+
+	var req DeleteTeamFavoriteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputils.ReportError(w, err, "Failed to decode JSON.", http.StatusBadRequest)
+		return
+	}
+	existing, err := f.favStore.Get(ctx, req.ID)
+	if err != nil {
+		httputils.ReportError(w, err, "Failed to load favorite.", http.StatusInternalServerError)
+		return
+	}
+	if existing.Team == "" {
+		httputils.ReportError(w, fmt.Errorf("Not a team favorite."), "The favorite must belong to a team.", http.StatusBadRequest)
+		return
+	}
+	if !f.isTeamMember(ctx, w, r, existing.Team) {
+		return
+	}
+	auditlog.LogWithUser(r, string(f.loginProvider.LoggedInAs(r)), "delete_team_favorite", req)
+	if err := f.favStore.Delete(ctx, req.ID); err != nil {
+		httputils.ReportError(w, err, "Failed to delete favorite.", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(map[string]string{}); err != nil {
+		sklog.Errorf("Failed to write or encode output: %s", err)
+	}
+}
+
 // defaultsHandler returns the default settings
 func (f *Frontend) defaultsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -2123,6 +2612,7 @@ func (f *Frontend) GetHandler(allowedHosts []string) http.Handler {
 	router.Post("/_/keys/", f.keysHandler)
 
 	router.Post("/_/frame/start", f.frameStartHandler)
+	router.Post("/_/pivot/csv", f.pivotCSVHandler)
 	router.Post("/_/cluster/start", f.clusterStartHandler)
 	router.Post("/_/trybot/load/", f.trybotLoadHandler)
 	router.Post("/_/dryrun/start", f.dryrunRequests.StartHandler)
@@ -2140,6 +2630,12 @@ func (f *Frontend) GetHandler(allowedHosts []string) http.Handler {
 	router.Post("/_/alert/bug/try", f.alertBugTryHandler)
 	router.Post("/_/alert/notify/try", f.alertNotifyTryHandler)
 
+	router.Get("/_/triagerules/list/{show}", f.triageRuleListHandler)
+	router.Get("/_/triagerules/new", f.triageRuleNewHandler)
+	router.Post("/_/triagerules/update", f.triageRuleUpdateHandler)
+	router.Post("/_/triagerules/delete/{id:[0-9]+}", f.triageRuleDeleteHandler)
+	router.Get("/_/triagerules/audit", f.triageRuleAuditHandler)
+
 	router.Get("/_/login/status", f.loginStatus)
 
 	router.Post("/_/shortcut/get", f.getGraphsShortcutHandler)
@@ -2148,11 +2644,21 @@ func (f *Frontend) GetHandler(allowedHosts []string) http.Handler {
 	router.Post("/_/bisect/create", f.createBisectHandler)
 
 	router.Get("/_/favorites/", f.favoritesHandler)
+	router.Post("/_/favorites/team/update", f.updateFavoriteTeamHandler)
+	router.Post("/_/favorites/team/save", f.saveTeamFavoriteHandler)
+	router.Post("/_/favorites/team/delete", f.deleteTeamFavoriteHandler)
 	router.Get("/_/defaults/", f.defaultsHandler)
 	router.Get("/_/revision/", f.revisionHandler)
 
 	router.Get("/_/subscriptions", f.subscriptionsHandler)
 	router.Get("/_/regressions", f.regressionsHandler)
+
+	if f.openMetricsExporter != nil {
+		router.Get("/_/metrics", f.openMetricsExporter.ServeHTTP)
+	}
+	if f.sloReporter != nil {
+		router.Get("/_/slo", f.sloReporter.ServeHTTP)
+	}
 	return router
 }
 
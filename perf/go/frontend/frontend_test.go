@@ -3,6 +3,7 @@ package frontend
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -13,6 +14,10 @@ import (
 	"go.skia.org/infra/go/alogin/mocks"
 	"go.skia.org/infra/go/roles"
 	"go.skia.org/infra/go/testutils"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/favorites"
+	"go.skia.org/infra/perf/go/favorites/localfavoritestore"
+	"go.skia.org/infra/perf/go/localstore"
 	"go.skia.org/infra/perf/go/regression"
 	regressionMocks "go.skia.org/infra/perf/go/regression/mocks"
 	subscriptionMocks "go.skia.org/infra/perf/go/subscription/mocks"
@@ -190,3 +195,91 @@ func TestFrontendRegressionsHandler_Success(t *testing.T) {
 	require.Contains(t, w.Body.String(), "r2")
 	require.Contains(t, w.Body.String(), "r3")
 }
+
+func setupTeamFavoritesForTest(t *testing.T, body interface{}) (*httptest.ResponseRecorder, *http.Request, *Frontend) {
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	favStore, err := localfavoritestore.New(db)
+	require.NoError(t, err)
+	require.NoError(t, favStore.UpdateTeam(context.Background(), &favorites.Team{Name: "team1", Members: []string{"member@example.org", "group:team1-viewers"}}, "member@example.org", nil))
+
+	var b bytes.Buffer
+	require.NoError(t, json.NewEncoder(&b).Encode(body))
+	r := httptest.NewRequest("POST", "/not-used", &b)
+	login := mocks.NewLogin(t)
+	login.On("LoggedInAs", r).Return(alogin.EMail("member@example.org")).Maybe()
+	f := &Frontend{
+		loginProvider: login,
+		favStore:      favStore,
+	}
+	return httptest.NewRecorder(), r, f
+}
+
+func TestFrontendSaveTeamFavoriteHandler_Member_FavoriteIsListedInTeamSection(t *testing.T) {
+	w, r, f := setupTeamFavoritesForTest(t, SaveTeamFavoriteRequest{Team: "team1", Name: "fav", Url: "/e/?keys=X1"})
+	f.saveTeamFavoriteHandler(w, r)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	sections := f.teamFavoriteSections(context.Background(), "member@example.org")
+	require.Equal(t, []config.FavoritesSectionConfig{
+		{
+			Name:  "team1",
+			Links: []config.FavoritesSectionLinkConfig{{Text: "fav", Href: "/e/?keys=X1"}},
+		},
+	}, sections)
+	require.Empty(t, f.teamFavoriteSections(context.Background(), "other@example.org"))
+}
+
+func TestFrontendSaveTeamFavoriteHandler_NotAMember_ReportsError(t *testing.T) {
+	w, r, f := setupTeamFavoritesForTest(t, SaveTeamFavoriteRequest{Team: "team1", Name: "fav", Url: "/e/?keys=X1"})
+	f.loginProvider = mocks.NewLogin(t)
+	f.loginProvider.(*mocks.Login).On("LoggedInAs", r).Return(alogin.EMail("other@example.org"))
+	f.saveTeamFavoriteHandler(w, r)
+	require.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+}
+
+func TestFrontendUpdateFavoriteTeamHandler_NewTeam_Success(t *testing.T) {
+	w, r, f := setupTeamFavoritesForTest(t, UpdateFavoriteTeamRequest{Name: "team2", Members: []string{"@example.org"}})
+	f.updateFavoriteTeamHandler(w, r)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	team, err := f.favStore.GetTeam(context.Background(), "team2")
+	require.NoError(t, err)
+	require.Equal(t, []string{"@example.org"}, team.Members)
+}
+
+func TestFrontendUpdateFavoriteTeamHandler_NotAMemberOfExistingTeam_ReportsError(t *testing.T) {
+	w, r, f := setupTeamFavoritesForTest(t, UpdateFavoriteTeamRequest{Name: "team1", Members: []string{"other@example.org"}})
+	f.loginProvider = mocks.NewLogin(t)
+	f.loginProvider.(*mocks.Login).On("LoggedInAs", r).Return(alogin.EMail("other@example.org"))
+	f.updateFavoriteTeamHandler(w, r)
+	require.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+
+	team, err := f.favStore.GetTeam(context.Background(), "team1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"member@example.org", "group:team1-viewers"}, team.Members)
+}
+
+func TestFrontendUpdateFavoriteTeamHandler_MemberThroughGroup_Success(t *testing.T) {
+	w, r, f := setupTeamFavoritesForTest(t, UpdateFavoriteTeamRequest{Name: "team1", Members: []string{"group:team1-viewers"}})
+	f.loginProvider = mocks.NewLogin(t)
+	f.loginProvider.(*mocks.Login).On("LoggedInAs", r).Return(alogin.EMail("other@example.org"))
+	f.teamGroups = fakeGroups{"team1-viewers": "other@example.org"}
+	f.updateFavoriteTeamHandler(w, r)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	require.Len(t, f.teamFavoriteSections(context.Background(), "other@example.org"), 1)
+}
+
+// fakeGroups is a favorites.Groups where each group has a single member.
+type fakeGroups map[string]string
+
+func (f fakeGroups) IsMember(group, userId string) bool {
+	return f[group] == userId
+}
+
+func TestFrontendUpdateFavoriteTeamHandler_RemovesSelf_ReportsError(t *testing.T) {
+	w, r, f := setupTeamFavoritesForTest(t, UpdateFavoriteTeamRequest{Name: "team1", Members: []string{"other@example.org"}})
+	f.updateFavoriteTeamHandler(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
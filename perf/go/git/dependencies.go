@@ -0,0 +1,158 @@
+package git
+
+import (
+	"context"
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru"
+	"go.skia.org/infra/go/depot_tools/deps_parser"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/git/providers"
+)
+
+const (
+	// pinnedRevisionCacheSize is the number of (dependency, git hash) pairs
+	// whose pinned revision is cached. Each entry is about 150 bytes.
+	pinnedRevisionCacheSize = 10_000
+
+	// maxDependencyCommits is the maximum number of commits returned for a
+	// single DependencyRoll.
+	maxDependencyCommits = 200
+
+	defaultDependencyCommitURL = "%s/+show/%s"
+)
+
+// dependency is a repo that the main repo pins via a DEPS file.
+type dependency struct {
+	cfg config.DependencyRepoConfig
+	gp  provider.Provider
+}
+
+// dependencyTracker maps commits in the main repo to the revisions of the
+// dependency repos pinned in the main repo's DEPS file.
+type dependencyTracker struct {
+	// gp is the Provider for the main repo.
+	gp provider.Provider
+
+	deps []dependency
+
+	// pinned caches the dependency revision pinned at a main repo git hash.
+	// Since commits are immutable the entries never go stale.
+	pinned *lru.Cache
+}
+
+// newDependencyTracker returns a new *dependencyTracker for the dependencies
+// in the instance config. The dependency repos are read with the same kind of
+// Provider as the main repo.
+func newDependencyTracker(ctx context.Context, gp provider.Provider, instanceConfig *config.InstanceConfig) (*dependencyTracker, error) {
+	deps := []dependency{}
+	for _, depCfg := range instanceConfig.GitRepoConfig.Dependencies {
+		depInstanceConfig := *instanceConfig
+		depInstanceConfig.GitRepoConfig = config.GitRepoConfig{
+			GitAuthType: instanceConfig.GitRepoConfig.GitAuthType,
+			Provider:    instanceConfig.GitRepoConfig.Provider,
+			URL:         depCfg.URL,
+			Dir:         depCfg.Dir,
+		}
+		depGp, err := providers.New(ctx, &depInstanceConfig)
+		if err != nil {
+			return nil, skerr.Wrapf(err, "Failed to build provider for dependency %q", depCfg.Name)
+		}
+		deps = append(deps, dependency{cfg: depCfg, gp: depGp})
+	}
+	return newDependencyTrackerFromDependencies(gp, deps)
+}
+
+func newDependencyTrackerFromDependencies(gp provider.Provider, deps []dependency) (*dependencyTracker, error) {
+	pinned, err := lru.New(pinnedRevisionCacheSize)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return &dependencyTracker{
+		gp:     gp,
+		deps:   deps,
+		pinned: pinned,
+	}, nil
+}
+
+// update brings all the dependency repos up to date.
+func (d *dependencyTracker) update(ctx context.Context) error {
+	for _, dep := range d.deps {
+		if err := dep.gp.Update(ctx); err != nil {
+			return skerr.Wrapf(err, "Failed to update dependency %q", dep.cfg.Name)
+		}
+	}
+	return nil
+}
+
+// pinnedRevision returns the revision of the dependency pinned in the DEPS
+// file at the given main repo git hash.
+func (d *dependencyTracker) pinnedRevision(ctx context.Context, dep dependency, gitHash string) (string, error) {
+	key := dep.cfg.Name + "@" + gitHash
+	if rev, ok := d.pinned.Get(key); ok {
+		return rev.(string), nil
+	}
+	depsFile := dep.cfg.DepsFile
+	if depsFile == "" {
+		depsFile = deps_parser.DepsFileName
+	}
+	content, err := d.gp.ReadFileAtCommit(ctx, gitHash, depsFile)
+	if err != nil {
+		return "", skerr.Wrap(err)
+	}
+	depsID := dep.cfg.DepsID
+	if depsID == "" {
+		depsID = dep.cfg.URL
+	}
+	entry, err := deps_parser.GetDep(string(content), depsID)
+	if err != nil {
+		return "", skerr.Wrapf(err, "Failed to find dependency %q in %s at %s", dep.cfg.Name, depsFile, gitHash)
+	}
+	d.pinned.Add(key, entry.Version)
+	return entry.Version, nil
+}
+
+// rolls returns the dependency commits rolled into the main repo by the main
+// repo commits in (begin, end]. Only dependencies whose pinned revision
+// changed across the range are returned.
+func (d *dependencyTracker) rolls(ctx context.Context, begin, end string) ([]provider.DependencyRoll, error) {
+	ret := []provider.DependencyRoll{}
+	for _, dep := range d.deps {
+		beginRev, err := d.pinnedRevision(ctx, dep, begin)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		endRev, err := d.pinnedRevision(ctx, dep, end)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		if beginRev == endRev {
+			continue
+		}
+		commits, err := dep.gp.CommitsInRange(ctx, beginRev, endRev)
+		if err != nil {
+			return nil, skerr.Wrapf(err, "Failed to load commits of dependency %q", dep.cfg.Name)
+		}
+		roll := provider.DependencyRoll{
+			Name:  dep.cfg.Name,
+			Begin: beginRev,
+			End:   endRev,
+		}
+		if len(commits) > maxDependencyCommits {
+			commits = commits[len(commits)-maxDependencyCommits:]
+			roll.Truncated = true
+		}
+		commitURL := dep.cfg.CommitURL
+		if commitURL == "" {
+			commitURL = defaultDependencyCommitURL
+		}
+		for i := range commits {
+			commits[i].URL = fmt.Sprintf(commitURL, dep.cfg.URL, commits[i].GitHash)
+		}
+		roll.Commits = commits
+		ret = append(ret, roll)
+	}
+	return ret, nil
+}
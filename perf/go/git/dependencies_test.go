@@ -0,0 +1,141 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/config"
+)
+
+const depURL = "https://example.org/dep.git"
+
+// depsFile returns the contents of a DEPS file that pins the dependency at
+// depURL to the given revision.
+func depsFile(revision string) string {
+	return fmt.Sprintf(`vars = {
+  'dep_revision': '%s',
+}
+deps = {
+  'src/third_party/dep': '%s' + '@' + Var('dep_revision'),
+}
+`, revision, depURL)
+}
+
+// newDependencyTrackerForTest returns a dependencyTracker for a main repo with
+// commits aaa, bbb, ccc, ddd which pin the dependency at 111, 111, 333, 444
+// respectively.
+func newDependencyTrackerForTest(t *testing.T) (*fakeProvider, *fakeProvider, *dependencyTracker) {
+	mainGp := &fakeProvider{
+		files: map[string]map[string]string{
+			"aaa": {"DEPS": depsFile("111")},
+			"bbb": {"DEPS": depsFile("111")},
+			"ccc": {"DEPS": depsFile("333")},
+			"ddd": {"DEPS": depsFile("444")},
+		},
+	}
+	for _, hash := range []string{"aaa", "bbb", "ccc", "ddd"} {
+		mainGp.add(hash)
+	}
+	depGp := &fakeProvider{}
+	for _, hash := range []string{"111", "222", "333", "444"} {
+		depGp.add(hash)
+	}
+	d, err := newDependencyTrackerFromDependencies(mainGp, []dependency{
+		{
+			cfg: config.DependencyRepoConfig{
+				Name: "dep",
+				URL:  depURL,
+			},
+			gp: depGp,
+		},
+	})
+	require.NoError(t, err)
+	return mainGp, depGp, d
+}
+
+func TestDependencyTrackerRolls_DependencyRolledInRange_ReturnsRolledCommits(t *testing.T) {
+	_, _, d := newDependencyTrackerForTest(t)
+
+	rolls, err := d.rolls(context.Background(), "aaa", "ccc")
+	require.NoError(t, err)
+	require.Len(t, rolls, 1)
+	assert.Equal(t, "dep", rolls[0].Name)
+	assert.Equal(t, "111", rolls[0].Begin)
+	assert.Equal(t, "333", rolls[0].End)
+	assert.False(t, rolls[0].Truncated)
+	require.Len(t, rolls[0].Commits, 2)
+	assert.Equal(t, "222", rolls[0].Commits[0].GitHash)
+	assert.Equal(t, "333", rolls[0].Commits[1].GitHash)
+	assert.Equal(t, depURL+"/+show/333", rolls[0].Commits[1].URL)
+}
+
+func TestDependencyTrackerRolls_DependencyNotRolledInRange_ReturnsEmpty(t *testing.T) {
+	_, _, d := newDependencyTrackerForTest(t)
+
+	rolls, err := d.rolls(context.Background(), "aaa", "bbb")
+	require.NoError(t, err)
+	assert.Empty(t, rolls)
+}
+
+func TestDependencyTrackerRolls_PinnedRevisionsAreCached(t *testing.T) {
+	mainGp, _, d := newDependencyTrackerForTest(t)
+
+	_, err := d.rolls(context.Background(), "aaa", "ccc")
+	require.NoError(t, err)
+
+	// Removing the DEPS files shouldn't matter since the revisions are cached.
+	mainGp.files = nil
+	rolls, err := d.rolls(context.Background(), "aaa", "ccc")
+	require.NoError(t, err)
+	require.Len(t, rolls, 1)
+}
+
+func TestDependencyTrackerRolls_CustomCommitURL_IsUsed(t *testing.T) {
+	_, _, d := newDependencyTrackerForTest(t)
+	d.deps[0].cfg.CommitURL = "%s/commit/%s"
+
+	rolls, err := d.rolls(context.Background(), "ccc", "ddd")
+	require.NoError(t, err)
+	require.Len(t, rolls, 1)
+	assert.Equal(t, depURL+"/commit/444", rolls[0].Commits[0].URL)
+}
+
+func TestDependencyTrackerRolls_MissingDepsFile_ReturnsError(t *testing.T) {
+	mainGp, _, d := newDependencyTrackerForTest(t)
+	delete(mainGp.files, "ddd")
+
+	_, err := d.rolls(context.Background(), "ccc", "ddd")
+	require.Error(t, err)
+}
+
+func TestDependencyTrackerRolls_DependencyNotInDepsFile_ReturnsError(t *testing.T) {
+	_, _, d := newDependencyTrackerForTest(t)
+	d.deps[0].cfg.DepsID = "https://example.org/some-other-dep.git"
+
+	_, err := d.rolls(context.Background(), "aaa", "ccc")
+	require.Error(t, err)
+}
+
+func TestLocalImpl_DependencyRolls_NoDependenciesConfigured_ReturnsEmpty(t *testing.T) {
+	ctx, _, _, g := newLocalForTest(t)
+
+	rolls, err := g.DependencyRolls(ctx, 0, 3)
+	require.NoError(t, err)
+	assert.Empty(t, rolls)
+}
+
+func TestLocalImpl_DependencyRolls_Success(t *testing.T) {
+	ctx, _, _, g := newLocalForTest(t)
+	_, _, d := newDependencyTrackerForTest(t)
+	g.deps = d
+
+	rolls, err := g.DependencyRolls(ctx, 1, 3)
+	require.NoError(t, err)
+	require.Len(t, rolls, 1)
+	assert.Equal(t, "111", rolls[0].Begin)
+	assert.Equal(t, "444", rolls[0].End)
+	assert.Len(t, rolls[0].Commits, 3)
+}
@@ -174,6 +174,9 @@ var statements = map[statement]string{
 type Impl struct {
 	gp provider.Provider
 
+	// deps tracks the dependency repos pinned in the repo's DEPS file.
+	deps *dependencyTracker
+
 	instanceConfig *config.InstanceConfig
 
 	db pool.Pool
@@ -212,6 +215,10 @@ func New(ctx context.Context, local bool, db pool.Pool, instanceConfig *config.I
 	if err != nil {
 		return nil, skerr.Wrap(err)
 	}
+	deps, err := newDependencyTracker(ctx, gp, instanceConfig)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
 
 	// If the commit_number_regex config is not empty, will parse commit number from git hash field.
 	commitNumberRegex := instanceConfig.GitRepoConfig.CommitNumberRegex
@@ -224,6 +231,7 @@ func New(ctx context.Context, local bool, db pool.Pool, instanceConfig *config.I
 
 	ret := &Impl{
 		gp:                                     gp,
+		deps:                                   deps,
 		db:                                     db,
 		cache:                                  cache,
 		instanceConfig:                         instanceConfig,
@@ -278,6 +286,9 @@ func (g *Impl) Update(ctx context.Context) error {
 	if err := g.gp.Update(ctx); err != nil {
 		return skerr.Wrap(err)
 	}
+	if err := g.deps.update(ctx); err != nil {
+		return skerr.Wrap(err)
+	}
 
 	nextCommitNumber := types.CommitNumber(0)
 	mostRecentGitHash, mostRecentCommitNumber, err := g.getMostRecentCommit(ctx)
@@ -336,14 +347,20 @@ func (g *Impl) Update(ctx context.Context) error {
 // matchs[0][1] will be "master"
 // matchs[0][2] will be "727901"
 func (g *Impl) getCommitNumberFromCommit(body string) (types.CommitNumber, error) {
-	matchs := g.commitNumberRegex.FindAllStringSubmatch(body, -1)
+	return commitNumberFromCommitBody(g.commitNumberRegex, body)
+}
+
+// commitNumberFromCommitBody parses the commit number from the commit body
+// using the given regex. See getCommitNumberFromCommit.
+func commitNumberFromCommitBody(commitNumberRegex *regexp.Regexp, body string) (types.CommitNumber, error) {
+	matchs := commitNumberRegex.FindAllStringSubmatch(body, -1)
 	if len(matchs) <= 0 {
-		return types.BadCommitNumber, skerr.Fmt("Failed to match commit number key by regex %q from commit body: %q", g.commitNumberRegex.String(), body)
+		return types.BadCommitNumber, skerr.Fmt("Failed to match commit number key by regex %q from commit body: %q", commitNumberRegex.String(), body)
 	}
 
 	match := matchs[len(matchs)-1]
 	if len(match) < 3 {
-		return types.BadCommitNumber, skerr.Fmt("Failed to match commit number by regex %q from commit body: %q", g.commitNumberRegex.String(), body)
+		return types.BadCommitNumber, skerr.Fmt("Failed to match commit number by regex %q from commit body: %q", commitNumberRegex.String(), body)
 	}
 
 	result, err := strconv.Atoi(match[2])
@@ -588,6 +605,15 @@ func (g *Impl) CommitNumbersWhenFileChangesInCommitNumberRange(ctx context.Conte
 	return ret, nil
 }
 
+// FilesChangedInCommit implements Git.
+func (g *Impl) FilesChangedInCommit(ctx context.Context, commitNumber types.CommitNumber) ([]string, error) {
+	hash, err := g.GitHashFromCommitNumber(ctx, commitNumber)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return g.gp.FilesChangedInCommit(ctx, hash)
+}
+
 // LogEntry implements Git.
 func (g *Impl) LogEntry(ctx context.Context, commit types.CommitNumber) (string, error) {
 	hash, err := g.GitHashFromCommitNumber(ctx, commit)
@@ -597,6 +623,22 @@ func (g *Impl) LogEntry(ctx context.Context, commit types.CommitNumber) (string,
 	return g.gp.LogEntry(ctx, hash)
 }
 
+// DependencyRolls implements Git.
+func (g *Impl) DependencyRolls(ctx context.Context, begin, end types.CommitNumber) ([]provider.DependencyRoll, error) {
+	if len(g.deps.deps) == 0 {
+		return []provider.DependencyRoll{}, nil
+	}
+	beginHash, err := g.GitHashFromCommitNumber(ctx, begin)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	endHash, err := g.GitHashFromCommitNumber(ctx, end)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return g.deps.rolls(ctx, beginHash, endHash)
+}
+
 // RepoSuppliedCommitNumber implements Git.
 func (g *Impl) RepoSuppliedCommitNumber() bool {
 	return g.repoSuppliedCommitNumber
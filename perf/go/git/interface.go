@@ -59,6 +59,16 @@ type Git interface {
 	// range is exclusive of the begin commit and inclusive of the end commit.
 	CommitNumbersWhenFileChangesInCommitNumberRange(ctx context.Context, begin, end types.CommitNumber, filename string) ([]types.CommitNumber, error)
 
+	// FilesChangedInCommit returns the paths, relative to the root of the repo,
+	// of the files that the given commit changed.
+	FilesChangedInCommit(ctx context.Context, commitNumber types.CommitNumber) ([]string, error)
+
+	// DependencyRolls returns the commits of each dependency repo that were
+	// rolled in by the commits in (begin, end], i.e. exclusive of begin and
+	// inclusive of end. Dependencies that weren't rolled in the range are
+	// omitted.
+	DependencyRolls(ctx context.Context, begin, end types.CommitNumber) ([]provider.DependencyRoll, error)
+
 	// LogEntry returns the full log entry of a commit (minus the diff) as a string.
 	LogEntry(ctx context.Context, commit types.CommitNumber) (string, error)
 
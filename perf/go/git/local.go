@@ -0,0 +1,457 @@
+package git
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/types"
+)
+
+// commitsTable is the name of the table in the localstore.DB that stores the
+// commits.
+const commitsTable = "commits"
+
+// localCommits is the data stored in the commits table.
+type localCommits struct {
+	// Commits is sorted by CommitNumber.
+	Commits []provider.Commit
+}
+
+// LocalImpl implements Git, storing a copy of the needed commit info in a
+// localstore.DB instead of an SQL database.
+//
+// The commits are kept in memory and reloaded whenever the commits table is
+// changed, so several processes can share the same localstore.DB.
+type LocalImpl struct {
+	gp provider.Provider
+
+	// deps tracks the dependency repos pinned in the repo's DEPS file.
+	deps *dependencyTracker
+
+	instanceConfig *config.InstanceConfig
+
+	db *localstore.DB
+
+	repoSuppliedCommitNumber bool
+	commitNumberRegex        *regexp.Regexp
+
+	// mutex protects commits and commitsVersion.
+	mutex sync.RWMutex
+
+	// commits is sorted by CommitNumber.
+	commits []provider.Commit
+
+	// commitsVersion is the version of the table commits was loaded from.
+	commitsVersion localstore.Version
+}
+
+// NewLocal creates a new *LocalImpl from the given instance configuration.
+//
+// The instance created does not poll by default, callers need to call
+// StartBackgroundPolling().
+func NewLocal(ctx context.Context, gp provider.Provider, db *localstore.DB, instanceConfig *config.InstanceConfig) (*LocalImpl, error) {
+	deps, err := newDependencyTracker(ctx, gp, instanceConfig)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+
+	ret := &LocalImpl{
+		gp:             gp,
+		deps:           deps,
+		instanceConfig: instanceConfig,
+		db:             db,
+	}
+	if err := ret.load(); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	if commitNumberRegex := instanceConfig.GitRepoConfig.CommitNumberRegex; len(commitNumberRegex) > 0 {
+		ret.repoSuppliedCommitNumber = true
+		ret.commitNumberRegex = regexp.MustCompile(commitNumberRegex)
+	}
+
+	if err := ret.Update(ctx); err != nil {
+		return nil, skerr.Wrapf(err, "Failed first update step for config %v", *instanceConfig)
+	}
+
+	return ret, nil
+}
+
+// load reloads the commits if the commits table has changed since they were
+// last loaded. The caller must hold g.mutex for writing.
+func (g *LocalImpl) load() error {
+	version, err := g.db.Version(commitsTable)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if version == g.commitsVersion {
+		return nil
+	}
+	var stored localCommits
+	version, err = g.db.ReadVersion(commitsTable, &stored)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	g.commits = stored.Commits
+	g.commitsVersion = version
+	return nil
+}
+
+// refresh is like load, but only needs the caller to not hold g.mutex.
+func (g *LocalImpl) refresh() error {
+	version, err := g.db.Version(commitsTable)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	g.mutex.RLock()
+	current := version == g.commitsVersion
+	g.mutex.RUnlock()
+	if current {
+		return nil
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.load()
+}
+
+// StartBackgroundPolling implements Git.
+func (g *LocalImpl) StartBackgroundPolling(ctx context.Context, duration time.Duration) {
+	go func() {
+		liveness := metrics2.NewLiveness("perf_git_udpate_polling_livenes")
+		ctx := context.Background()
+		for range time.Tick(duration) {
+			if err := g.Update(ctx); err != nil {
+				sklog.Errorf("Failed to update git repo: %s", err)
+			} else {
+				liveness.Reset()
+			}
+		}
+	}()
+}
+
+// Update implements Git.
+func (g *LocalImpl) Update(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, config.QueryMaxRunTime)
+	defer cancel()
+
+	if err := g.gp.Update(ctx); err != nil {
+		return skerr.Wrap(err)
+	}
+	if err := g.deps.update(ctx); err != nil {
+		return skerr.Wrap(err)
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	// Another process may have already added some of the commits.
+	if err := g.load(); err != nil {
+		return skerr.Wrap(err)
+	}
+
+	mostRecentGitHash := ""
+	nextCommitNumber := types.CommitNumber(0)
+	if n := len(g.commits); n > 0 {
+		mostRecentGitHash = g.commits[n-1].GitHash
+		nextCommitNumber = g.commits[n-1].CommitNumber + 1
+	}
+
+	newCommits := []provider.Commit{}
+	err := g.gp.CommitsFromMostRecentGitHashToHead(ctx, mostRecentGitHash, func(p provider.Commit) error {
+		if g.repoSuppliedCommitNumber {
+			commitNumber, err := commitNumberFromCommitBody(g.commitNumberRegex, p.Body)
+			if err != nil {
+				sklog.Errorf("Failed to add commit %q, because cannot find commit number with the error: %s", p.GitHash, err)
+				return nil
+			}
+			nextCommitNumber = commitNumber
+		}
+		newCommits = append(newCommits, provider.Commit{
+			CommitNumber: nextCommitNumber,
+			GitHash:      p.GitHash,
+			Timestamp:    p.Timestamp,
+			Author:       p.Author,
+			Subject:      p.Subject,
+		})
+		nextCommitNumber++
+		return nil
+	})
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if len(newCommits) == 0 {
+		return nil
+	}
+
+	commits := append(append([]provider.Commit{}, g.commits...), newCommits...)
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].CommitNumber < commits[j].CommitNumber
+	})
+	version, err := g.db.WriteVersion(commitsTable, localCommits{Commits: commits})
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	g.commits = commits
+	g.commitsVersion = version
+	sklog.Infof("Added %d commits this update cycle.", len(newCommits))
+	return nil
+}
+
+// indexOfCommitNumber returns the index into g.commits of the first commit
+// with a CommitNumber >= commitNumber. The caller must hold g.mutex.
+func (g *LocalImpl) indexOfCommitNumber(commitNumber types.CommitNumber) int {
+	return sort.Search(len(g.commits), func(i int) bool {
+		return g.commits[i].CommitNumber >= commitNumber
+	})
+}
+
+// commitFromCommitNumber returns the commit with the given CommitNumber. The
+// caller must hold g.mutex.
+func (g *LocalImpl) commitFromCommitNumber(commitNumber types.CommitNumber) (provider.Commit, error) {
+	i := g.indexOfCommitNumber(commitNumber)
+	if i == len(g.commits) || g.commits[i].CommitNumber != commitNumber {
+		return BadCommit, skerr.Fmt("Failed to find CommitNumber: %d", commitNumber)
+	}
+	ret := g.commits[i]
+	ret.URL = urlFromParts(g.instanceConfig, ret)
+	return ret, nil
+}
+
+// GetCommitNumber implements Git.
+func (g *LocalImpl) GetCommitNumber(ctx context.Context, githash string, commitNumber types.CommitNumber) (types.CommitNumber, error) {
+	if g.repoSuppliedCommitNumber {
+		_, err := g.GitHashFromCommitNumber(ctx, commitNumber)
+		if err != nil {
+			return types.BadCommitNumber, err
+		}
+		return commitNumber, nil
+	}
+
+	return g.CommitNumberFromGitHash(ctx, githash)
+}
+
+// CommitNumberFromGitHash implements Git.
+func (g *LocalImpl) CommitNumberFromGitHash(ctx context.Context, githash string) (types.CommitNumber, error) {
+	if err := g.refresh(); err != nil {
+		return types.BadCommitNumber, skerr.Wrap(err)
+	}
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	for _, c := range g.commits {
+		if c.GitHash == githash {
+			return c.CommitNumber, nil
+		}
+	}
+	return types.BadCommitNumber, skerr.Fmt("Failed get for hash: %q", githash)
+}
+
+// CommitFromCommitNumber implements Git.
+func (g *LocalImpl) CommitFromCommitNumber(ctx context.Context, commitNumber types.CommitNumber) (provider.Commit, error) {
+	if err := g.refresh(); err != nil {
+		return BadCommit, skerr.Wrap(err)
+	}
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	return g.commitFromCommitNumber(commitNumber)
+}
+
+// CommitSliceFromCommitNumberSlice implements Git.
+func (g *LocalImpl) CommitSliceFromCommitNumberSlice(ctx context.Context, commitNumberSlice []types.CommitNumber) ([]provider.Commit, error) {
+	if err := g.refresh(); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	ret := make([]provider.Commit, len(commitNumberSlice))
+	for i, commitNumber := range commitNumberSlice {
+		c, err := g.commitFromCommitNumber(commitNumber)
+		if err != nil {
+			return ret, skerr.Wrapf(err, "failed looking up CommitNumber %d", commitNumber)
+		}
+		ret[i] = c
+	}
+	return ret, nil
+}
+
+// CommitNumberFromTime implements Git.
+func (g *LocalImpl) CommitNumberFromTime(ctx context.Context, t time.Time) (types.CommitNumber, error) {
+	if err := g.refresh(); err != nil {
+		return types.BadCommitNumber, skerr.Wrap(err)
+	}
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	if len(g.commits) == 0 {
+		return types.BadCommitNumber, skerr.Fmt("No commits found.")
+	}
+	if t.IsZero() {
+		return g.commits[len(g.commits)-1].CommitNumber, nil
+	}
+	ts := t.Unix()
+	for i := len(g.commits) - 1; i >= 0; i-- {
+		if g.commits[i].Timestamp <= ts {
+			return g.commits[i].CommitNumber, nil
+		}
+	}
+	return types.BadCommitNumber, skerr.Fmt("Failed get for time: %q", t)
+}
+
+// CommitSliceFromTimeRange implements Git.
+func (g *LocalImpl) CommitSliceFromTimeRange(ctx context.Context, begin, end time.Time) ([]provider.Commit, error) {
+	if err := g.refresh(); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	ret := []provider.Commit{}
+	for _, c := range g.commits {
+		if c.Timestamp >= begin.Unix() && c.Timestamp < end.Unix() {
+			c.URL = urlFromParts(g.instanceConfig, c)
+			ret = append(ret, c)
+		}
+	}
+	return ret, nil
+}
+
+// CommitSliceFromCommitNumberRange implements Git.
+func (g *LocalImpl) CommitSliceFromCommitNumberRange(ctx context.Context, begin, end types.CommitNumber) ([]provider.Commit, error) {
+	if err := g.refresh(); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	ret := []provider.Commit{}
+	for i := g.indexOfCommitNumber(begin); i < len(g.commits) && g.commits[i].CommitNumber <= end; i++ {
+		ret = append(ret, g.commits[i])
+	}
+	return ret, nil
+}
+
+// GitHashFromCommitNumber implements Git.
+func (g *LocalImpl) GitHashFromCommitNumber(ctx context.Context, commitNumber types.CommitNumber) (string, error) {
+	c, err := g.CommitFromCommitNumber(ctx, commitNumber)
+	if err != nil {
+		return "", skerr.Wrapf(err, "Failed to find git hash for commit number: %v", commitNumber)
+	}
+	return c.GitHash, nil
+}
+
+// previousCommit returns the commit that comes before the given commitNumber.
+func (g *LocalImpl) previousCommit(commitNumber types.CommitNumber) (provider.Commit, error) {
+	if err := g.refresh(); err != nil {
+		return BadCommit, skerr.Wrap(err)
+	}
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	i := g.indexOfCommitNumber(commitNumber)
+	if i == 0 {
+		return BadCommit, skerr.Fmt("Failed to find previous commit for commit number: %v", commitNumber)
+	}
+	return g.commits[i-1], nil
+}
+
+// PreviousGitHashFromCommitNumber implements Git.
+func (g *LocalImpl) PreviousGitHashFromCommitNumber(ctx context.Context, commitNumber types.CommitNumber) (string, error) {
+	c, err := g.previousCommit(commitNumber)
+	if err != nil {
+		return "", err
+	}
+	return c.GitHash, nil
+}
+
+// PreviousCommitNumberFromCommitNumber implements Git.
+func (g *LocalImpl) PreviousCommitNumberFromCommitNumber(ctx context.Context, commitNumber types.CommitNumber) (types.CommitNumber, error) {
+	c, err := g.previousCommit(commitNumber)
+	if err != nil {
+		return types.BadCommitNumber, err
+	}
+	return c.CommitNumber, nil
+}
+
+// CommitNumbersWhenFileChangesInCommitNumberRange implements Git.
+func (g *LocalImpl) CommitNumbersWhenFileChangesInCommitNumberRange(ctx context.Context, begin, end types.CommitNumber, filename string) ([]types.CommitNumber, error) {
+	var beginHash string
+	if begin != types.BadCommitNumber && begin-1 != types.BadCommitNumber {
+		var err error
+		beginHash, err = g.PreviousGitHashFromCommitNumber(ctx, begin)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+	}
+
+	endHash, err := g.GitHashFromCommitNumber(ctx, end)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+
+	hashes, err := g.gp.GitHashesInRangeForFile(ctx, beginHash, endHash, filename)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	var ret []types.CommitNumber
+	for _, githash := range hashes {
+		commitNumber, err := g.CommitNumberFromGitHash(ctx, githash)
+		if err != nil {
+			return nil, skerr.Wrapf(err, "git log returned invalid git hash: %q", githash)
+		}
+		ret = append(ret, commitNumber)
+	}
+
+	return ret, nil
+}
+
+// FilesChangedInCommit implements Git.
+func (g *LocalImpl) FilesChangedInCommit(ctx context.Context, commitNumber types.CommitNumber) ([]string, error) {
+	hash, err := g.GitHashFromCommitNumber(ctx, commitNumber)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return g.gp.FilesChangedInCommit(ctx, hash)
+}
+
+// LogEntry implements Git.
+func (g *LocalImpl) LogEntry(ctx context.Context, commit types.CommitNumber) (string, error) {
+	hash, err := g.GitHashFromCommitNumber(ctx, commit)
+	if err != nil {
+		return "", skerr.Wrap(err)
+	}
+	return g.gp.LogEntry(ctx, hash)
+}
+
+// DependencyRolls implements Git.
+func (g *LocalImpl) DependencyRolls(ctx context.Context, begin, end types.CommitNumber) ([]provider.DependencyRoll, error) {
+	if len(g.deps.deps) == 0 {
+		return []provider.DependencyRoll{}, nil
+	}
+	beginHash, err := g.GitHashFromCommitNumber(ctx, begin)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	endHash, err := g.GitHashFromCommitNumber(ctx, end)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return g.deps.rolls(ctx, beginHash, endHash)
+}
+
+// RepoSuppliedCommitNumber implements Git.
+func (g *LocalImpl) RepoSuppliedCommitNumber() bool {
+	return g.repoSuppliedCommitNumber
+}
+
+// Confirm that *LocalImpl implements Git.
+var _ Git = (*LocalImpl)(nil)
@@ -0,0 +1,191 @@
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/types"
+)
+
+var localStartTime = time.Unix(1680000000, 0)
+
+// fakeProvider is a provider.Provider that serves a fixed list of commits.
+type fakeProvider struct {
+	commits []provider.Commit
+
+	// files maps a git hash to the contents of the files at that commit.
+	files map[string]map[string]string
+}
+
+func (f *fakeProvider) CommitsFromMostRecentGitHashToHead(ctx context.Context, mostRecentGitHash string, cb provider.CommitProcessor) error {
+	found := mostRecentGitHash == ""
+	for _, c := range f.commits {
+		if found {
+			if err := cb(c); err != nil {
+				return err
+			}
+		}
+		if c.GitHash == mostRecentGitHash {
+			found = true
+		}
+	}
+	return nil
+}
+
+func (f *fakeProvider) GitHashesInRangeForFile(ctx context.Context, begin, end, filename string) ([]string, error) {
+	return []string{end}, nil
+}
+
+func (f *fakeProvider) FilesChangedInCommit(ctx context.Context, gitHash string) ([]string, error) {
+	return []string{gitHash + ".txt"}, nil
+}
+
+func (f *fakeProvider) CommitsInRange(ctx context.Context, begin, end string) ([]provider.Commit, error) {
+	ret := []provider.Commit{}
+	inRange := false
+	for _, c := range f.commits {
+		if inRange {
+			ret = append(ret, c)
+		}
+		if c.GitHash == begin {
+			inRange = true
+		}
+		if c.GitHash == end {
+			break
+		}
+	}
+	return ret, nil
+}
+
+func (f *fakeProvider) LogEntry(ctx context.Context, gitHash string) (string, error) {
+	return "commit " + gitHash, nil
+}
+
+func (f *fakeProvider) ReadFileAtCommit(ctx context.Context, gitHash, filename string) ([]byte, error) {
+	content, ok := f.files[gitHash][filename]
+	if !ok {
+		return nil, skerr.Fmt("%s not found at %s", filename, gitHash)
+	}
+	return []byte(content), nil
+}
+
+func (f *fakeProvider) Update(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakeProvider) add(gitHash string) {
+	f.commits = append(f.commits, provider.Commit{
+		GitHash:   gitHash,
+		Timestamp: localStartTime.Add(time.Duration(len(f.commits)) * time.Minute).Unix(),
+		Author:    "somebody@example.org",
+		Subject:   "Commit " + gitHash,
+	})
+}
+
+func newLocalForTest(t *testing.T) (context.Context, *fakeProvider, *localstore.DB, *LocalImpl) {
+	ctx := context.Background()
+	gp := &fakeProvider{}
+	for _, hash := range []string{"aaa", "bbb", "ccc", "ddd"} {
+		gp.add(hash)
+	}
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	instanceConfig := &config.InstanceConfig{
+		GitRepoConfig: config.GitRepoConfig{
+			URL: "https://example.org/repo",
+		},
+	}
+	g, err := NewLocal(ctx, gp, db, instanceConfig)
+	require.NoError(t, err)
+	return ctx, gp, db, g
+}
+
+func TestLocalImpl_CommitLookups_Success(t *testing.T) {
+	ctx, _, _, g := newLocalForTest(t)
+
+	commitNumber, err := g.CommitNumberFromGitHash(ctx, "ccc")
+	require.NoError(t, err)
+	assert.Equal(t, types.CommitNumber(2), commitNumber)
+
+	hash, err := g.GitHashFromCommitNumber(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "bbb", hash)
+
+	hash, err = g.PreviousGitHashFromCommitNumber(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "aaa", hash)
+
+	_, err = g.PreviousCommitNumberFromCommitNumber(ctx, 0)
+	require.Error(t, err)
+
+	commits, err := g.CommitSliceFromCommitNumberRange(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "bbb", commits[0].GitHash)
+	assert.Equal(t, "ccc", commits[1].GitHash)
+
+	commitNumber, err = g.CommitNumberFromTime(ctx, localStartTime.Add(90*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, types.CommitNumber(1), commitNumber)
+
+	commitNumber, err = g.CommitNumberFromTime(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, types.CommitNumber(3), commitNumber)
+
+	files, err := g.FilesChangedInCommit(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ccc.txt"}, files)
+
+	c, err := g.CommitFromCommitNumber(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org/repo/+show/ddd", c.URL)
+
+	_, err = g.CommitFromCommitNumber(ctx, 4)
+	require.Error(t, err)
+}
+
+func TestLocalImpl_UpdateAndReopen_CommitsArePersisted(t *testing.T) {
+	ctx, gp, db, g := newLocalForTest(t)
+
+	gp.add("eee")
+	require.NoError(t, g.Update(ctx))
+
+	// Reopen the database and confirm all the commits are still there.
+	g2, err := NewLocal(ctx, &fakeProvider{}, db, g.instanceConfig)
+	require.NoError(t, err)
+	commits, err := g2.CommitSliceFromCommitNumberRange(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, commits, 5)
+	assert.Equal(t, types.CommitNumber(4), commits[4].CommitNumber)
+	assert.Equal(t, "eee", commits[4].GitHash)
+}
+
+func TestLocalImpl_UpdateFromAnotherProcess_CommitsAreReloaded(t *testing.T) {
+	ctx, gp, db, g := newLocalForTest(t)
+
+	// Open the directory again, as another process would, with a provider
+	// that has no new commits.
+	otherDB, err := localstore.New(db.Dir())
+	require.NoError(t, err)
+	other, err := NewLocal(ctx, &fakeProvider{}, otherDB, g.instanceConfig)
+	require.NoError(t, err)
+	_, err = other.CommitFromCommitNumber(ctx, 4)
+	require.Error(t, err)
+
+	gp.add("eee")
+	require.NoError(t, g.Update(ctx))
+
+	hash, err := other.GitHashFromCommitNumber(ctx, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "eee", hash)
+	commitNumber, err := other.CommitNumberFromGitHash(ctx, "eee")
+	require.NoError(t, err)
+	assert.Equal(t, types.CommitNumber(4), commitNumber)
+}
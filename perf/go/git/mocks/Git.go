@@ -223,6 +223,36 @@ func (_m *Git) CommitSliceFromTimeRange(ctx context.Context, begin time.Time, en
 	return r0, r1
 }
 
+// FilesChangedInCommit provides a mock function with given fields: ctx, commitNumber
+func (_m *Git) FilesChangedInCommit(ctx context.Context, commitNumber types.CommitNumber) ([]string, error) {
+	ret := _m.Called(ctx, commitNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FilesChangedInCommit")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, types.CommitNumber) ([]string, error)); ok {
+		return rf(ctx, commitNumber)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, types.CommitNumber) []string); ok {
+		r0 = rf(ctx, commitNumber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, types.CommitNumber) error); ok {
+		r1 = rf(ctx, commitNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCommitNumber provides a mock function with given fields: ctx, githash, commitNumber
 func (_m *Git) GetCommitNumber(ctx context.Context, githash string, commitNumber types.CommitNumber) (types.CommitNumber, error) {
 	ret := _m.Called(ctx, githash, commitNumber)
@@ -279,6 +309,36 @@ func (_m *Git) GitHashFromCommitNumber(ctx context.Context, commitNumber types.C
 	return r0, r1
 }
 
+// DependencyRolls provides a mock function with given fields: ctx, begin, end
+func (_m *Git) DependencyRolls(ctx context.Context, begin types.CommitNumber, end types.CommitNumber) ([]provider.DependencyRoll, error) {
+	ret := _m.Called(ctx, begin, end)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DependencyRolls")
+	}
+
+	var r0 []provider.DependencyRoll
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, types.CommitNumber, types.CommitNumber) ([]provider.DependencyRoll, error)); ok {
+		return rf(ctx, begin, end)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, types.CommitNumber, types.CommitNumber) []provider.DependencyRoll); ok {
+		r0 = rf(ctx, begin, end)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]provider.DependencyRoll)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, types.CommitNumber, types.CommitNumber) error); ok {
+		r1 = rf(ctx, begin, end)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // LogEntry provides a mock function with given fields: ctx, commit
 func (_m *Git) LogEntry(ctx context.Context, commit types.CommitNumber) (string, error) {
 	ret := _m.Called(ctx, commit)
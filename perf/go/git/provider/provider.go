@@ -35,6 +35,25 @@ func (c Commit) HumanTime() string {
 	return human.Duration(time.Since(time.Unix(c.Timestamp, 0)))
 }
 
+// DependencyRoll is the range of commits in a dependency repo that were rolled
+// into the main repo by a range of commits in the main repo.
+type DependencyRoll struct {
+	// Name of the dependency, from the DependencyRepoConfig.
+	Name string `json:"name"`
+
+	// Begin and End are the revisions of the dependency that were pinned at
+	// the beginning and end of the range of main repo commits.
+	Begin string `json:"begin"`
+	End   string `json:"end"`
+
+	// Commits are the dependency commits in (Begin, End], oldest first.
+	Commits []Commit `json:"commits"`
+
+	// Truncated is true if there were more commits in the range than are
+	// returned in Commits, in which case the most recent commits are kept.
+	Truncated bool `json:"truncated"`
+}
+
 // CommitProcessor is a callback function that will be called with a Commit.
 // Used in GitProvider.
 type CommitProcessor func(c Commit) error
@@ -55,9 +74,23 @@ type Provider interface {
 	// string then the scan should go back to the initial commit of the repo.
 	GitHashesInRangeForFile(ctx context.Context, begin, end, filename string) ([]string, error)
 
+	// FilesChangedInCommit returns the paths, relative to the root of the
+	// repo, of the files that the given commit added, modified, renamed or
+	// deleted.
+	FilesChangedInCommit(ctx context.Context, gitHash string) ([]string, error)
+
+	// CommitsInRange returns all the commits in (begin, end], i.e. exclusive
+	// of begin and inclusive of end, oldest first. The returned Commits do not
+	// have a valid CommitNumber.
+	CommitsInRange(ctx context.Context, begin, end string) ([]Commit, error)
+
 	// LogEntry returns the full log entry of a commit (minus the diff) as a string.
 	LogEntry(ctx context.Context, gitHash string) (string, error)
 
+	// ReadFileAtCommit returns the contents of the given file as of the given
+	// commit.
+	ReadFileAtCommit(ctx context.Context, gitHash, filename string) ([]byte, error)
+
 	// Update does any necessary work, like a `git pull`, to ensure that the
 	// GitProvider has the most recent commits available.
 	Update(ctx context.Context) error
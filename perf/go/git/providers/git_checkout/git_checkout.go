@@ -164,6 +164,27 @@ func (i Impl) GitHashesInRangeForFile(ctx context.Context, begin, end, filename
 	return ret, nil
 }
 
+// FilesChangedInCommit implements provider.Provider.
+func (i Impl) FilesChangedInCommit(ctx context.Context, gitHash string) ([]string, error) {
+	// --root lists the files of the initial commit as added.
+	cmd := exec.CommandContext(ctx, i.gitFullPath, "diff-tree", "--no-commit-id", "--name-only", "-r", "--root", gitHash)
+	cmd.Dir = i.repoFullPath
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, skerr.Wrapf(err, "Failed running %q: stderr: %q", cmd.String(), stderr.String())
+	}
+	ret := []string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line != "" {
+			ret = append(ret, line)
+		}
+	}
+	return ret, nil
+}
+
 // LogEntry implements provider.Provider.
 func (i Impl) LogEntry(ctx context.Context, hash string) (string, error) {
 	// Build the git log command to run.
@@ -181,6 +202,43 @@ func (i Impl) LogEntry(ctx context.Context, hash string) (string, error) {
 	return out.String(), nil
 }
 
+// CommitsInRange implements provider.Provider.
+func (i Impl) CommitsInRange(ctx context.Context, begin, end string) ([]provider.Commit, error) {
+	cmd := exec.CommandContext(ctx, i.gitFullPath, "rev-list", begin+".."+end, `--pretty=%aN <%aE>%n%s%n%ct`, "--reverse")
+	cmd.Dir = i.repoFullPath
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, skerr.Wrapf(err, "Failed running %q: stderr: %q", cmd.String(), stderr.String())
+	}
+
+	ret := []provider.Commit{}
+	err := parseGitRevLogStream(io.NopCloser(&out), func(p provider.Commit) error {
+		ret = append(ret, p)
+		return nil
+	})
+	if err != nil {
+		return nil, skerr.Wrapf(err, "parsing git stdout")
+	}
+	return ret, nil
+}
+
+// ReadFileAtCommit implements provider.Provider.
+func (i Impl) ReadFileAtCommit(ctx context.Context, gitHash, filename string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, i.gitFullPath, "show", gitHash+":"+filename)
+	cmd.Dir = i.repoFullPath
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, skerr.Wrapf(err, "Failed running %q: stderr: %q", cmd.String(), stderr.String())
+	}
+	return out.Bytes(), nil
+}
+
 type parseGitRevLogStreamProcessSingleCommit func(commit provider.Commit) error
 
 // parseGitRevLogStream parses the input stream for input of the form:
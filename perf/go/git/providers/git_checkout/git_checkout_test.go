@@ -308,3 +308,66 @@ func TestCommitsFromMostRecentGitHashToHead_ProvideNonEmptyGitHash_ReceiveAllNew
 	})
 	require.NoError(t, err)
 }
+
+func TestCommitsInRange_BeginIsExcludedAndEndIsIncluded(t *testing.T) {
+	ctx, _, hashes, instanceConfig := NewForTest(t)
+	g, err := New(ctx, instanceConfig)
+	require.NoError(t, err)
+
+	commits, err := g.CommitsInRange(ctx, hashes[1], hashes[3])
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, hashes[2], commits[0].GitHash)
+	assert.Equal(t, hashes[3], commits[1].GitHash)
+	assert.Equal(t, StartTime.Add(3*time.Minute).Unix(), commits[1].Timestamp)
+	assert.Equal(t, "test <test@google.com>", commits[1].Author)
+}
+
+func TestCommitsInRange_BadCommitId_ReturnsError(t *testing.T) {
+	ctx, _, hashes, instanceConfig := NewForTest(t)
+	g, err := New(ctx, instanceConfig)
+	require.NoError(t, err)
+
+	_, err = g.CommitsInRange(ctx, "this-is-not-a-known-git-hash", hashes[3])
+	require.Error(t, err)
+}
+
+func TestReadFileAtCommit_FileChangesBetweenCommits(t *testing.T) {
+	ctx, _, hashes, instanceConfig := NewForTest(t)
+	g, err := New(ctx, instanceConfig)
+	require.NoError(t, err)
+
+	first, err := g.ReadFileAtCommit(ctx, hashes[0], "foo.txt")
+	require.NoError(t, err)
+	second, err := g.ReadFileAtCommit(ctx, hashes[1], "foo.txt")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	// bar.txt doesn't exist until the fourth commit.
+	_, err = g.ReadFileAtCommit(ctx, hashes[0], "bar.txt")
+	require.Error(t, err)
+}
+
+func TestFilesChangedInCommit_ReturnsFilesChangedByOnlyThatCommit(t *testing.T) {
+	ctx, _, hashes, instanceConfig := NewForTest(t)
+	g, err := New(ctx, instanceConfig)
+	require.NoError(t, err)
+
+	files, err := g.FilesChangedInCommit(ctx, hashes[3])
+	require.NoError(t, err)
+	require.Equal(t, []string{"bar.txt"}, files)
+
+	// The first commit in the repo has no parent.
+	files, err = g.FilesChangedInCommit(ctx, hashes[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo.txt"}, files)
+}
+
+func TestFilesChangedInCommit_BadCommitId_ReturnsError(t *testing.T) {
+	ctx, _, _, instanceConfig := NewForTest(t)
+	g, err := New(ctx, instanceConfig)
+	require.NoError(t, err)
+
+	_, err = g.FilesChangedInCommit(ctx, "not-a-valid-hash")
+	require.Error(t, err)
+}
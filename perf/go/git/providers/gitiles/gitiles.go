@@ -18,6 +18,10 @@ import (
 
 const (
 	batchSize = 100
+
+	// devNull is the path used in a TreeDiff for the missing side of an
+	// added or deleted file.
+	devNull = "/dev/null"
 )
 
 // Gitiles implements provider.Provider.
@@ -102,6 +106,26 @@ func (g *Gitiles) GitHashesInRangeForFile(ctx context.Context, begin, end, filen
 	return ret, nil
 }
 
+// FilesChangedInCommit implements provider.Provider.
+func (g *Gitiles) FilesChangedInCommit(ctx context.Context, gitHash string) ([]string, error) {
+	diffs, err := g.gr.GetTreeDiffs(ctx, gitHash)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "loading tree diffs")
+	}
+	ret := []string{}
+	for _, diff := range diffs {
+		// Added files have an OldPath of /dev/null, and deleted files a NewPath
+		// of /dev/null.
+		if diff.OldPath != devNull {
+			ret = append(ret, diff.OldPath)
+		}
+		if diff.NewPath != devNull && diff.NewPath != diff.OldPath {
+			ret = append(ret, diff.NewPath)
+		}
+	}
+	return ret, nil
+}
+
 // LogEntry implements provider.Provider.
 func (g *Gitiles) LogEntry(ctx context.Context, gitHash string) (string, error) {
 	lc, err := g.gr.Log(ctx, gitHash, gitiles.LogLimit(1))
@@ -121,6 +145,34 @@ Date %s
 %s`, commit.Hash, commit.Author, commit.Timestamp.Format(time.RFC822Z), commit.Subject, commit.Body), nil
 }
 
+// CommitsInRange implements provider.Provider.
+func (g *Gitiles) CommitsInRange(ctx context.Context, begin, end string) ([]provider.Commit, error) {
+	lc, err := g.gr.Log(ctx, git.LogFromTo(begin, end), gitiles.LogReverse())
+	if err != nil {
+		return nil, skerr.Wrapf(err, "loading commits")
+	}
+	ret := make([]provider.Commit, len(lc))
+	for i, c := range lc {
+		ret[i] = provider.Commit{
+			GitHash:   c.Hash,
+			Timestamp: c.Timestamp.Unix(),
+			Author:    c.Author,
+			Subject:   c.Subject,
+			Body:      c.Body,
+		}
+	}
+	return ret, nil
+}
+
+// ReadFileAtCommit implements provider.Provider.
+func (g *Gitiles) ReadFileAtCommit(ctx context.Context, gitHash, filename string) ([]byte, error) {
+	b, err := g.gr.ReadFileAtRef(ctx, filename, gitHash)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "reading %q at %q", filename, gitHash)
+	}
+	return b, nil
+}
+
 // Update implements provider.Provider.
 func (g *Gitiles) Update(ctx context.Context) error {
 	return nil
@@ -259,3 +259,74 @@ func TestCommitsFromMostRecentGitHashToHead_NonMainBranch_Success(t *testing.T)
 	err := gp.CommitsFromMostRecentGitHashToHead(context.Background(), startCommit, cb)
 	require.NoError(t, err)
 }
+
+func TestCommitsInRange_HappyPath(t *testing.T) {
+	mockRepo := gitiles_mocks.NewGitilesRepo(t)
+	mockRepo.On("Log", testutils.AnyContext, git.LogFromTo(beginHash, endHash), gitiles.LogReverse()).Return(commitDetailsForOneCommit, nil)
+
+	gp := &Gitiles{
+		gr: mockRepo,
+	}
+	commits, err := gp.CommitsInRange(context.Background(), beginHash, endHash)
+	require.NoError(t, err)
+	require.Equal(t, []provider.Commit{
+		{
+			GitHash:   gitHash,
+			Timestamp: time.Time{}.Unix(),
+			Author:    author,
+			Subject:   subject,
+			Body:      body,
+		},
+	}, commits)
+}
+
+func TestCommitsInRange_GitilesAPIReturnsError_ReturnsError(t *testing.T) {
+	mockRepo := gitiles_mocks.NewGitilesRepo(t)
+	mockRepo.On("Log", testutils.AnyContext, git.LogFromTo(beginHash, endHash), gitiles.LogReverse()).Return(nil, errMock)
+
+	gp := &Gitiles{
+		gr: mockRepo,
+	}
+	_, err := gp.CommitsInRange(context.Background(), beginHash, endHash)
+	require.ErrorIs(t, err, errMock)
+}
+
+func TestReadFileAtCommit_HappyPath(t *testing.T) {
+	mockRepo := gitiles_mocks.NewGitilesRepo(t)
+	mockRepo.On("ReadFileAtRef", testutils.AnyContext, "DEPS", gitHash).Return([]byte("deps = {}"), nil)
+
+	gp := &Gitiles{
+		gr: mockRepo,
+	}
+	b, err := gp.ReadFileAtCommit(context.Background(), gitHash, "DEPS")
+	require.NoError(t, err)
+	require.Equal(t, "deps = {}", string(b))
+}
+
+func TestFilesChangedInCommit_AddedModifiedRenamedAndDeletedFiles_ReturnsAllPaths(t *testing.T) {
+	mockRepo := gitiles_mocks.NewGitilesRepo(t)
+	mockRepo.On("GetTreeDiffs", testutils.AnyContext, gitHash).Return([]*gitiles.TreeDiff{
+		{Type: "add", OldPath: "/dev/null", NewPath: "src/new.cpp"},
+		{Type: "modify", OldPath: "src/core/foo.cpp", NewPath: "src/core/foo.cpp"},
+		{Type: "rename", OldPath: "include/old.h", NewPath: "include/new.h"},
+		{Type: "delete", OldPath: "bar.txt", NewPath: "/dev/null"},
+	}, nil)
+
+	gp := &Gitiles{
+		gr: mockRepo,
+	}
+	files, err := gp.FilesChangedInCommit(context.Background(), gitHash)
+	require.NoError(t, err)
+	require.Equal(t, []string{"src/new.cpp", "src/core/foo.cpp", "include/old.h", "include/new.h", "bar.txt"}, files)
+}
+
+func TestFilesChangedInCommit_GitilesAPIReturnsError_ReturnsError(t *testing.T) {
+	mockRepo := gitiles_mocks.NewGitilesRepo(t)
+	mockRepo.On("GetTreeDiffs", testutils.AnyContext, gitHash).Return(nil, errMock)
+
+	gp := &Gitiles{
+		gr: mockRepo,
+	}
+	_, err := gp.FilesChangedInCommit(context.Background(), gitHash)
+	require.ErrorIs(t, err, errMock)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"time"
 )
 
 // GraphConfig represent the configurations used to populate a single graph.
@@ -19,6 +20,21 @@ type GraphConfig struct {
 // GraphsShortcut is a list of GraphConfigs, it is used in the Store interface.
 type GraphsShortcut struct {
 	Graphs []GraphConfig `json:"graphs"`
+
+	// CreatedBy is the email of the user that is storing the shortcut. It is
+	// only recorded when the shortcut is first stored.
+	CreatedBy string `json:"-"`
+}
+
+// Metadata describes who created a graphs shortcut and when it was last used.
+// Like shortcut.Metadata, Created is the zero time for graphs shortcuts stored
+// before metadata was recorded, and LastAccessed is only updated once every
+// shortcut.AccessUpdateInterval.
+type Metadata struct {
+	ID           string
+	CreatedBy    string
+	Created      time.Time
+	LastAccessed time.Time
 }
 
 // Store is an interface for things that persists Graphs Shortcuts.
@@ -29,6 +45,17 @@ type Store interface {
 
 	// GetShortcut retrieves parsed graph configs for the given id.
 	GetShortcut(ctx context.Context, id string) (*GraphsShortcut, error)
+
+	// GetMetadata returns the Metadata for the shortcut with the given id.
+	GetMetadata(ctx context.Context, id string) (*Metadata, error)
+
+	// ListUnaccessedSince returns the ids of all the shortcuts that haven't
+	// been stored or retrieved since the given time, excluding those stored
+	// before metadata was recorded.
+	ListUnaccessedSince(ctx context.Context, before time.Time) ([]string, error)
+
+	// Delete removes the shortcuts with the given ids.
+	Delete(ctx context.Context, ids []string) error
 }
 
 func (s GraphsShortcut) GetID() string {
@@ -5,10 +5,14 @@ package graphsshortcutstore
 import (
 	"context"
 	"encoding/json"
+	"time"
 
+	"go.skia.org/infra/go/now"
 	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
 	"go.skia.org/infra/go/sql/pool"
 	"go.skia.org/infra/perf/go/graphsshortcut"
+	"go.skia.org/infra/perf/go/shortcut"
 )
 
 // statement is an SQL statement identifier.
@@ -18,25 +22,64 @@ const (
 	// The identifiers for all the SQL statements used.
 	insertShortcut statement = iota
 	getShortcut
+	touchShortcut
+	getMetadata
+	listUnaccessedSince
+	deleteShortcuts
 )
 
 // statements holds all the raw SQL statemens.
 var statements = map[statement]string{
 	insertShortcut: `
 		INSERT INTO
-			GraphsShortcuts (id, graphs)
+			GraphsShortcuts (id, graphs, created_by, created_at)
 		VALUES
-			($1, $2)
-		ON CONFLICT
-		DO NOTHING`,
+			($1, $2, NULLIF($3, ''), now())
+		ON CONFLICT (id)
+		DO UPDATE SET last_accessed=now()`,
 	getShortcut: `
 		SELECT
-			(graphs)
+			graphs,
+			last_accessed
 		FROM
 			GraphsShortcuts
 		WHERE
 			id=$1
 		`,
+	touchShortcut: `
+		UPDATE
+			GraphsShortcuts
+		SET
+			last_accessed=now()
+		WHERE
+			id=$1
+		`,
+	getMetadata: `
+		SELECT
+			id,
+			COALESCE(created_by, ''),
+			created_at,
+			last_accessed
+		FROM
+			GraphsShortcuts
+		WHERE
+			id=$1
+		`,
+	listUnaccessedSince: `
+		SELECT
+			id
+		FROM
+			GraphsShortcuts
+		WHERE
+			last_accessed < $1
+			AND created_at IS NOT NULL
+		`,
+	deleteShortcuts: `
+		DELETE FROM
+			GraphsShortcuts
+		WHERE
+			id = ANY($1)
+		`,
 }
 
 // GraphsShortcutStore implements the graphsshortcut.Store interface using an SQL
@@ -59,7 +102,7 @@ func (s *GraphsShortcutStore) InsertShortcut(ctx context.Context, sc *graphsshor
 	if err != nil {
 		return "", err
 	}
-	if _, err := s.db.Exec(ctx, statements[insertShortcut], id, string(b)); err != nil {
+	if _, err := s.db.Exec(ctx, statements[insertShortcut], id, string(b), sc.CreatedBy); err != nil {
 		return "", skerr.Wrap(err)
 	}
 	return id, nil
@@ -68,12 +111,64 @@ func (s *GraphsShortcutStore) InsertShortcut(ctx context.Context, sc *graphsshor
 // GetShortcut implements the graphsshortcut.Store interface.
 func (s *GraphsShortcutStore) GetShortcut(ctx context.Context, id string) (*graphsshortcut.GraphsShortcut, error) {
 	var encoded string
-	if err := s.db.QueryRow(ctx, statements[getShortcut], id).Scan(&encoded); err != nil {
+	var lastAccessed time.Time
+	if err := s.db.QueryRow(ctx, statements[getShortcut], id).Scan(&encoded, &lastAccessed); err != nil {
 		return nil, skerr.Wrapf(err, "Failed to load shortcuts.")
 	}
+	if now.Now(ctx).Sub(lastAccessed) > shortcut.AccessUpdateInterval {
+		// Failing to record the access isn't fatal, it only affects garbage
+		// collection.
+		if _, err := s.db.Exec(ctx, statements[touchShortcut], id); err != nil {
+			sklog.Warningf("Failed to record access to shortcut %q: %s", id, err)
+		}
+	}
 	var sc graphsshortcut.GraphsShortcut
 	if err := json.Unmarshal([]byte(encoded), &sc); err != nil {
 		return nil, skerr.Wrapf(err, "Failed to decode keys.")
 	}
 	return &sc, nil
 }
+
+// GetMetadata implements the graphsshortcut.Store interface.
+func (s *GraphsShortcutStore) GetMetadata(ctx context.Context, id string) (*graphsshortcut.Metadata, error) {
+	ret := &graphsshortcut.Metadata{}
+	var created *time.Time
+	if err := s.db.QueryRow(ctx, statements[getMetadata], id).Scan(&ret.ID, &ret.CreatedBy, &created, &ret.LastAccessed); err != nil {
+		return nil, skerr.Wrapf(err, "Failed to load shortcut metadata.")
+	}
+	// created_at is NULL for shortcuts stored before metadata was recorded.
+	if created != nil {
+		ret.Created = *created
+	}
+	return ret, nil
+}
+
+// ListUnaccessedSince implements the graphsshortcut.Store interface.
+func (s *GraphsShortcutStore) ListUnaccessedSince(ctx context.Context, before time.Time) ([]string, error) {
+	rows, err := s.db.Query(ctx, statements[listUnaccessedSince], before)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to query for unaccessed shortcuts.")
+	}
+	defer rows.Close()
+
+	ret := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, nil
+}
+
+// Delete implements the graphsshortcut.Store interface.
+func (s *GraphsShortcutStore) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := s.db.Exec(ctx, statements[deleteShortcuts], ids); err != nil {
+		return skerr.Wrapf(err, "Failed to delete shortcuts.")
+	}
+	return nil
+}
@@ -1,7 +1,24 @@
 package schema
 
+import "time"
+
 type GraphsShortcutSchema struct {
 	ID string `sql:"id TEXT UNIQUE NOT NULL PRIMARY KEY"`
 
 	Graphs string `sql:"graphs TEXT"`
+
+	// CreatedBy is the email of the user that created the shortcut, or empty
+	// if it wasn't created by a user.
+	CreatedBy string `sql:"created_by TEXT"`
+
+	// CreatedAt is when the shortcut was first stored. It has no default, so
+	// shortcuts stored before the column was added are NULL and never garbage
+	// collected, since their age is unknown.
+	CreatedAt time.Time `sql:"created_at TIMESTAMPTZ"`
+
+	// LastAccessed is when the shortcut was last stored or retrieved.
+	LastAccessed time.Time `sql:"last_accessed TIMESTAMPTZ DEFAULT now()"`
+
+	// Index used to find shortcuts that haven't been accessed recently.
+	byLastAccessedIndex struct{} `sql:"INDEX by_last_accessed (last_accessed)"`
 }
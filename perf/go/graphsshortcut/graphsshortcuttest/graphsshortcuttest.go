@@ -6,6 +6,7 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -48,12 +49,45 @@ func GetNonExistent(t *testing.T, store graphsshortcut.Store) {
 	require.Error(t, err)
 }
 
+// MetadataAndDelete tests that the creator and access times are recorded, and
+// that unaccessed shortcuts can be found and deleted.
+func MetadataAndDelete(t *testing.T, store graphsshortcut.Store) {
+	ctx := context.Background()
+	id, err := store.InsertShortcut(ctx, &graphsshortcut.GraphsShortcut{
+		Graphs: []graphsshortcut.GraphConfig{
+			{Queries: []string{"arch=x86"}},
+		},
+		CreatedBy: "alice@example.org",
+	})
+	require.NoError(t, err)
+
+	md, err := store.GetMetadata(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, id, md.ID)
+	assert.Equal(t, "alice@example.org", md.CreatedBy)
+	assert.False(t, md.Created.IsZero())
+
+	// Times are compared with a wide margin since the database clock may
+	// differ from ours.
+	ids, err := store.ListUnaccessedSince(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+	ids, err = store.ListUnaccessedSince(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{id}, ids)
+
+	require.NoError(t, store.Delete(ctx, ids))
+	_, err = store.GetShortcut(ctx, id)
+	require.Error(t, err)
+}
+
 // SubTestFunction is a func we will call to test one aspect of an
 // implementation of graphsshortcut.Store.
 type SubTestFunction func(t *testing.T, store graphsshortcut.Store)
 
 // SubTests are all the subtests we have for graphsshortcut.Store.
 var SubTests = map[string]SubTestFunction{
-	"GraphsShortcut_InsertGet":      InsertGet,
-	"GraphsShortcut_GetNonExistent": GetNonExistent,
+	"GraphsShortcut_InsertGet":         InsertGet,
+	"GraphsShortcut_GetNonExistent":    GetNonExistent,
+	"GraphsShortcut_MetadataAndDelete": MetadataAndDelete,
 }
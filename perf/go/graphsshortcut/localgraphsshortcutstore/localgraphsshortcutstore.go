@@ -0,0 +1,172 @@
+// Package localgraphsshortcutstore implements graphsshortcut.Store using a
+// localstore.DB.
+package localgraphsshortcutstore
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/perf/go/graphsshortcut"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/shortcut"
+)
+
+// graphsShortcutsTable is the name of the table in the localstore.DB.
+const graphsShortcutsTable = "graphsshortcuts"
+
+// table is the data stored in the graphs shortcuts table.
+type table struct {
+	// Shortcuts maps shortcut ids to the JSON serialized
+	// graphsshortcut.GraphsShortcut.
+	Shortcuts map[string]string
+
+	// Metadata maps shortcut ids to their graphsshortcut.Metadata.
+	Metadata map[string]graphsshortcut.Metadata
+}
+
+// LocalGraphsShortcutStore implements the graphsshortcut.Store interface using
+// a localstore.DB.
+type LocalGraphsShortcutStore struct {
+	db *localstore.DB
+
+	// mutex protects data.
+	mutex sync.Mutex
+	data  table
+}
+
+// New returns a new *LocalGraphsShortcutStore.
+func New(db *localstore.DB) (*LocalGraphsShortcutStore, error) {
+	ret := &LocalGraphsShortcutStore{
+		db: db,
+		data: table{
+			Shortcuts: map[string]string{},
+			Metadata:  map[string]graphsshortcut.Metadata{},
+		},
+	}
+	if err := db.Read(graphsShortcutsTable, &ret.data); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	// Tables written before metadata was added don't have the Metadata map.
+	if ret.data.Metadata == nil {
+		ret.data.Metadata = map[string]graphsshortcut.Metadata{}
+	}
+	return ret, nil
+}
+
+// InsertShortcut implements the graphsshortcut.Store interface.
+func (s *LocalGraphsShortcutStore) InsertShortcut(ctx context.Context, sc *graphsshortcut.GraphsShortcut) (string, error) {
+	id := (*sc).GetID()
+	b, err := json.Marshal(sc)
+	if err != nil {
+		return "", err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	if _, ok := s.data.Shortcuts[id]; ok {
+		s.touch(id, now)
+		return id, nil
+	}
+	s.data.Shortcuts[id] = string(b)
+	s.data.Metadata[id] = graphsshortcut.Metadata{
+		ID:           id,
+		CreatedBy:    sc.CreatedBy,
+		Created:      now,
+		LastAccessed: now,
+	}
+	if err := s.db.Write(graphsShortcutsTable, s.data); err != nil {
+		delete(s.data.Shortcuts, id)
+		delete(s.data.Metadata, id)
+		return "", skerr.Wrap(err)
+	}
+	return id, nil
+}
+
+// GetShortcut implements the graphsshortcut.Store interface.
+func (s *LocalGraphsShortcutStore) GetShortcut(ctx context.Context, id string) (*graphsshortcut.GraphsShortcut, error) {
+	s.mutex.Lock()
+	encoded, ok := s.data.Shortcuts[id]
+	if ok && time.Since(s.metadata(id).LastAccessed) > shortcut.AccessUpdateInterval {
+		s.touch(id, time.Now())
+	}
+	s.mutex.Unlock()
+	if !ok {
+		return nil, skerr.Fmt("Failed to load shortcut %q.", id)
+	}
+	var sc graphsshortcut.GraphsShortcut
+	if err := json.Unmarshal([]byte(encoded), &sc); err != nil {
+		return nil, skerr.Wrapf(err, "Failed to decode keys.")
+	}
+	return &sc, nil
+}
+
+// GetMetadata implements the graphsshortcut.Store interface.
+func (s *LocalGraphsShortcutStore) GetMetadata(ctx context.Context, id string) (*graphsshortcut.Metadata, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.data.Shortcuts[id]; !ok {
+		return nil, skerr.Fmt("Failed to load shortcut metadata %q.", id)
+	}
+	ret := s.metadata(id)
+	return &ret, nil
+}
+
+// ListUnaccessedSince implements the graphsshortcut.Store interface.
+func (s *LocalGraphsShortcutStore) ListUnaccessedSince(ctx context.Context, before time.Time) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ret := []string{}
+	for id := range s.data.Shortcuts {
+		if m := s.metadata(id); !m.Created.IsZero() && m.LastAccessed.Before(before) {
+			ret = append(ret, id)
+		}
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// Delete implements the graphsshortcut.Store interface.
+func (s *LocalGraphsShortcutStore) Delete(ctx context.Context, ids []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, id := range ids {
+		delete(s.data.Shortcuts, id)
+		delete(s.data.Metadata, id)
+	}
+	if err := s.db.Write(graphsShortcutsTable, s.data); err != nil {
+		return skerr.Wrapf(err, "Failed to delete shortcuts.")
+	}
+	return nil
+}
+
+// metadata returns the metadata for the given shortcut id. Shortcuts stored
+// before metadata was recorded get a zero Metadata. The caller must hold the
+// mutex.
+func (s *LocalGraphsShortcutStore) metadata(id string) graphsshortcut.Metadata {
+	ret, ok := s.data.Metadata[id]
+	if !ok {
+		ret.ID = id
+	}
+	return ret
+}
+
+// touch updates the last accessed time of the given shortcut. Failing to
+// persist the new time isn't fatal, it only affects garbage collection. The
+// caller must hold the mutex.
+func (s *LocalGraphsShortcutStore) touch(id string, now time.Time) {
+	m := s.metadata(id)
+	m.LastAccessed = now
+	s.data.Metadata[id] = m
+	if err := s.db.Write(graphsShortcutsTable, s.data); err != nil {
+		sklog.Warningf("Failed to record access to shortcut %q: %s", id, err)
+	}
+}
+
+// Confirm that *LocalGraphsShortcutStore implements graphsshortcut.Store.
+var _ graphsshortcut.Store = (*LocalGraphsShortcutStore)(nil)
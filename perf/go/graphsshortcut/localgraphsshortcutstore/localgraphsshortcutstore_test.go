@@ -0,0 +1,21 @@
+package localgraphsshortcutstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/graphsshortcut/graphsshortcuttest"
+	"go.skia.org/infra/perf/go/localstore"
+)
+
+func TestShortcutStore_Local(t *testing.T) {
+	for name, subTest := range graphsshortcuttest.SubTests {
+		t.Run(name, func(t *testing.T) {
+			db, err := localstore.New(t.TempDir())
+			require.NoError(t, err)
+			store, err := New(db)
+			require.NoError(t, err)
+			subTest(t, store)
+		})
+	}
+}
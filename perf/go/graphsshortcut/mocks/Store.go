@@ -4,6 +4,7 @@ package mocks
 
 import (
 	context "context"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 	graphsshortcut "go.skia.org/infra/perf/go/graphsshortcut"
@@ -14,6 +15,54 @@ type Store struct {
 	mock.Mock
 }
 
+// Delete provides a mock function with given fields: ctx, ids
+func (_m *Store) Delete(ctx context.Context, ids []string) error {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetMetadata provides a mock function with given fields: ctx, id
+func (_m *Store) GetMetadata(ctx context.Context, id string) (*graphsshortcut.Metadata, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMetadata")
+	}
+
+	var r0 *graphsshortcut.Metadata
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*graphsshortcut.Metadata, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *graphsshortcut.Metadata); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*graphsshortcut.Metadata)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetShortcut provides a mock function with given fields: ctx, id
 func (_m *Store) GetShortcut(ctx context.Context, id string) (*graphsshortcut.GraphsShortcut, error) {
 	ret := _m.Called(ctx, id)
@@ -72,6 +121,36 @@ func (_m *Store) InsertShortcut(ctx context.Context, shortcut *graphsshortcut.Gr
 	return r0, r1
 }
 
+// ListUnaccessedSince provides a mock function with given fields: ctx, before
+func (_m *Store) ListUnaccessedSince(ctx context.Context, before time.Time) ([]string, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUnaccessedSince")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]string, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []string); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewStore creates a new instance of Store. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewStore(t interface {
@@ -37,6 +37,9 @@ type SingleMeasurement struct {
 
 // Result represents one or more measurements.
 //
+// See ResultV2 for a version that also carries units, improvement direction,
+// and raw samples.
+//
 // Only one of Measurement or Measurements should be populated.
 //
 // The idea behind Measurements is that you may have more than one metric you
@@ -202,7 +205,8 @@ func Parse(r io.Reader) (Format, error) {
 	return fileFormat, nil
 }
 
-// Validate the body of an ingested file against the schema for Format.
+// Validate the body of an ingested file against the schema for Format, or for
+// FormatV2 if the file is version 2.
 //
 // If there was an error loading the file a list of schema violations may be
 // returned also.
@@ -211,6 +215,12 @@ func Validate(r io.Reader) ([]string, error) {
 	if err != nil {
 		return nil, skerr.Wrapf(err, "failed to read bytes")
 	}
+	var version struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(b, &version); err == nil && version.Version == FileFormatVersion2 {
+		return validateV2(b)
+	}
 	_, err = Parse(bytes.NewReader(b))
 	if err != nil {
 		return nil, skerr.Wrapf(err, "failed to parse")
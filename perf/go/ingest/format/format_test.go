@@ -89,3 +89,97 @@ func TestValidate_ExampleWithData_Success(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, schemaViolations)
 }
+
+func TestParseV2_GoodVersion(t *testing.T) {
+	_, err := ParseV2(bytes.NewReader([]byte("{\"version\":2}")))
+	assert.NoError(t, err)
+}
+
+func TestParseV2_BadVersion(t *testing.T) {
+	_, err := ParseV2(bytes.NewReader([]byte("{\"version\":1}")))
+	assert.Equal(t, ErrFileWrongVersion, err)
+}
+
+func TestParseV2_InvalidImprovementDirection_ReturnsError(t *testing.T) {
+	_, err := ParseV2(strings.NewReader(`{
+		"version": 2,
+		"results": [
+			{
+				"key": {"test": "foo"},
+				"improvement_direction": "sideways"
+			}
+		]
+	}`))
+	assert.Error(t, err)
+}
+
+func TestParseV2_ExampleWithData_Success(t *testing.T) {
+	f, err := ParseV2(strings.NewReader(`{
+		"version": 2,
+		"git_hash": "cd5...663",
+		"results": [
+			{
+				"key": {"test": "draw_a_circle"},
+				"unit": "ms",
+				"improvement_direction": "down",
+				"measurements": {
+					"stat": [
+						{
+							"value": "median",
+							"measurement": 1.5,
+							"samples": [1.2, 1.5, 2.4]
+						}
+					]
+				}
+			}
+		]
+	}`))
+	require.NoError(t, err)
+	require.Len(t, f.Results, 1)
+	assert.Equal(t, "ms", f.Results[0].Unit)
+	assert.Equal(t, ImprovementDirectionDown, f.Results[0].ImprovementDirection)
+	assert.Equal(t, []float32{1.2, 1.5, 2.4}, f.Results[0].Measurements["stat"][0].Samples)
+}
+
+func TestValidate_Version2ExampleWithData_Success(t *testing.T) {
+	r := strings.NewReader(`{
+		"version": 2,
+		"git_hash": "cd5...663",
+		"key": {
+			"arch": "x86"
+		},
+		"results": [
+			{
+				"key": {
+					"test": "some_test_name"
+				},
+				"unit": "ms",
+				"improvement_direction": "down",
+				"measurement": 1.5,
+				"samples": [1.2, 1.5, 2.4]
+			}
+		]
+	}`)
+	schemaViolations, err := Validate(r)
+	require.NoError(t, err)
+	require.Empty(t, schemaViolations)
+}
+
+func TestValidate_Version1FileWithVersion2Fields_ReturnsError(t *testing.T) {
+	r := strings.NewReader(`{
+		"version": 1,
+		"git_hash": "cd5...663",
+		"results": [
+			{
+				"key": {
+					"test": "some_test_name"
+				},
+				"unit": "ms",
+				"measurement": 1.5
+			}
+		]
+	}`)
+	schemaViolations, err := Validate(r)
+	require.Error(t, err)
+	require.NotEmpty(t, schemaViolations)
+}
@@ -0,0 +1,184 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"go.skia.org/infra/go/jsonschema"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/perf/go/types"
+
+	_ "embed" // For embed functionality.
+)
+
+// schemaV2 is a json schema for FormatV2, it is created by running go
+// generate on ./generate/main.go.
+//
+//go:embed formatSchemaV2.json
+var schemaV2 []byte
+
+// FileFormatVersion2 is the version of the FormatV2 ingestion format.
+const FileFormatVersion2 = 2
+
+// ImprovementDirection is the direction a measurement moves in when the thing
+// being measured gets better.
+type ImprovementDirection string
+
+const (
+	// ImprovementDirectionUp means higher values are better, e.g. frames per
+	// second.
+	ImprovementDirectionUp ImprovementDirection = "up"
+
+	// ImprovementDirectionDown means lower values are better, e.g. time
+	// taken.
+	ImprovementDirectionDown ImprovementDirection = "down"
+
+	// ImprovementDirectionUnknown means it isn't known which direction is
+	// better, so a step in either direction could be a regression.
+	ImprovementDirectionUnknown ImprovementDirection = ""
+)
+
+// AllImprovementDirections is the list of all valid ImprovementDirection
+// values, not including ImprovementDirectionUnknown.
+var AllImprovementDirections = []ImprovementDirection{ImprovementDirectionUp, ImprovementDirectionDown}
+
+// SingleMeasurementV2 is used in ResultV2, see the usage there.
+type SingleMeasurementV2 struct {
+	// Value is the value part of the key=value pair in a trace id.
+	Value string `json:"value"`
+
+	// Measurement is a single measurement from a test run.
+	Measurement float32 `json:"measurement"`
+
+	// Samples are the optional raw samples that Measurement was computed
+	// from.
+	Samples []float32 `json:"samples,omitempty"`
+}
+
+// ResultV2 represents one or more measurements along with their metadata.
+//
+// Only one of Measurement or Measurements should be populated, see Result for
+// how Measurements are turned into trace ids.
+type ResultV2 struct {
+	// Key contains key=value pairs will be part of the trace id.
+	Key map[string]string `json:"key"`
+
+	// Unit is the optional unit of the measurements, e.g. "ms". It is stored
+	// as trace metadata, not as part of the trace id.
+	Unit string `json:"unit,omitempty"`
+
+	// ImprovementDirection is the optional direction the measurements move in
+	// when they improve. It is stored as trace metadata, not as part of the
+	// trace id.
+	ImprovementDirection ImprovementDirection `json:"improvement_direction,omitempty" jsonschema:"enum=up,enum=down"`
+
+	// Measurement is a single measurement from a test run.
+	Measurement float32 `json:"measurement,omitempty"`
+
+	// Samples are the optional raw samples that Measurement was computed
+	// from.
+	Samples []float32 `json:"samples,omitempty"`
+
+	// Measurements maps from a key to a list of values for that key with
+	// associated measurements. Each key=value pair will be part of the trace id.
+	Measurements map[string][]SingleMeasurementV2 `json:"measurements,omitempty"`
+}
+
+// FormatV2 is version 2 of the Format ingestion file format.
+//
+// It is a superset of Format that adds units, improvement direction, and raw
+// samples to each result. For example:
+//
+//	{
+//	    "version": 2,
+//	    "git_hash": "cd5...663",
+//	    "key": {
+//	        "arch": "x86"
+//	    },
+//	    "results": [
+//	        {
+//	            "key": {
+//	                "test": "draw_a_circle"
+//	            },
+//	            "unit": "ms",
+//	            "improvement_direction": "down",
+//	            "measurements": {
+//	                "stat": [
+//	                    {
+//	                        "value": "median",
+//	                        "measurement": 1.5,
+//	                        "samples": [1.2, 1.5, 2.4]
+//	                    }
+//	                ]
+//	            }
+//	        }
+//	    ]
+//	}
+//
+// Will produce this trace id and value:
+//
+//	,arch=x86,stat=median,test=draw_a_circle, = 1.5
+//
+// with the samples [1.2, 1.5, 2.4] stored alongside the value, and the unit and
+// improvement direction stored as the metadata of the trace.
+type FormatV2 struct {
+	// Version is the file format version. It should be 2 for this format.
+	Version int `json:"version" jsonschema:"enum=2"`
+
+	// GitHash of the repo when these tests were run.
+	GitHash string `json:"git_hash"`
+
+	// Issue is the Changelist ID.
+	Issue types.CL `json:"issue,omitempty"`
+
+	// Patchset is the tryjob patch identifier. For Gerrit this is an integer
+	// serialized as a string.
+	Patchset string `json:"patchset,omitempty"`
+
+	// Key contains key=value pairs that are part of all trace ids.
+	Key map[string]string `json:"key,omitempty"`
+
+	// Results are all the test results.
+	Results []ResultV2 `json:"results"`
+
+	// Links are any URLs to further information about this run, e.g. link to a
+	// CI run.
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// ParseV2 parses the stream out of the io.Reader into FormatV2. The caller is
+// responsible for calling Close on the reader.
+func ParseV2(r io.Reader) (FormatV2, error) {
+	var fileFormat FormatV2
+	if err := json.NewDecoder(r).Decode(&fileFormat); err != nil {
+		return FormatV2{}, skerr.Wrap(err)
+	}
+	if fileFormat.Version != FileFormatVersion2 {
+		return FormatV2{}, ErrFileWrongVersion
+	}
+	for _, result := range fileFormat.Results {
+		if result.ImprovementDirection != ImprovementDirectionUnknown && !isValidImprovementDirection(result.ImprovementDirection) {
+			return FormatV2{}, skerr.Fmt("Invalid improvement direction: %q", result.ImprovementDirection)
+		}
+	}
+	return fileFormat, nil
+}
+
+func isValidImprovementDirection(d ImprovementDirection) bool {
+	for _, valid := range AllImprovementDirections {
+		if d == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// validateV2 the body of an ingested file against the schema for FormatV2.
+func validateV2(b []byte) ([]string, error) {
+	_, err := ParseV2(bytes.NewReader(b))
+	if err != nil {
+		return nil, skerr.Wrapf(err, "failed to parse")
+	}
+	return jsonschema.Validate(b, schemaV2)
+}
@@ -10,4 +10,5 @@ import (
 
 func main() {
 	jsonschema.GenerateSchema("../formatSchema.json", &format.Format{})
+	jsonschema.GenerateSchema("../formatSchemaV2.json", &format.FormatV2{})
 }
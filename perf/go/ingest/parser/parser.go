@@ -20,6 +20,7 @@ import (
 	"go.skia.org/infra/perf/go/config"
 	"go.skia.org/infra/perf/go/file"
 	"go.skia.org/infra/perf/go/ingest/format"
+	"go.skia.org/infra/perf/go/tracestore"
 	"go.skia.org/infra/perf/go/types"
 )
 
@@ -201,6 +202,44 @@ func getParamsAndValuesFromVersion1Format(f format.Format, invalidParamCharRegex
 	return paramSlice, measurementSlice
 }
 
+// getParamsAndValuesFromVersion2Format returns four parallel slices, each
+// slice contains the params, the float, the raw samples, and the trace metadata
+// for a single value of a trace. The samples entry is nil for values that have
+// no samples.
+func getParamsAndValuesFromVersion2Format(f format.FormatV2, invalidParamCharRegex *regexp.Regexp) ([]paramtools.Params, []float32, [][]float32, []tracestore.TraceMetadata) {
+	paramSlice := []paramtools.Params{}
+	keyParams := paramtools.Params(f.Key)
+	measurementSlice := []float32{}
+	samplesSlice := [][]float32{}
+	metadataSlice := []tracestore.TraceMetadata{}
+	for _, result := range f.Results {
+		p := keyParams.Copy()
+		p.Add(result.Key)
+		metadata := tracestore.TraceMetadata{
+			Unit:                 result.Unit,
+			ImprovementDirection: string(result.ImprovementDirection),
+		}
+		if len(result.Measurements) == 0 {
+			paramSlice = append(paramSlice, query.ForceValidWithRegex(p, invalidParamCharRegex))
+			measurementSlice = append(measurementSlice, result.Measurement)
+			samplesSlice = append(samplesSlice, result.Samples)
+			metadataSlice = append(metadataSlice, metadata)
+		} else {
+			for key, measurements := range result.Measurements {
+				for _, measurement := range measurements {
+					singleParam := p.Copy()
+					singleParam[key] = measurement.Value
+					paramSlice = append(paramSlice, query.ForceValidWithRegex(singleParam, invalidParamCharRegex))
+					measurementSlice = append(measurementSlice, measurement.Measurement)
+					samplesSlice = append(samplesSlice, measurement.Samples)
+					metadataSlice = append(metadataSlice, metadata)
+				}
+			}
+		}
+	}
+	return paramSlice, measurementSlice, samplesSlice, metadataSlice
+}
+
 // checkBranchName returns the branch name and true if the file should continue
 // to be processed. Note that if the 'params' don't contain a key named 'branch'
 // then the file should be processed, in which case the returned branch name is
@@ -216,23 +255,33 @@ func (p *Parser) checkBranchName(params map[string]string) (string, bool) {
 	return "", true
 }
 
-func (p *Parser) extractFromLegacyFile(r io.Reader, filename string) ([]paramtools.Params, []float32, string, map[string]string, error) {
+func (p *Parser) extractFromLegacyFile(r io.Reader, filename string) ([]paramtools.Params, []float32, [][]float32, []tracestore.TraceMetadata, string, map[string]string, error) {
 	benchData, err := format.ParseLegacyFormat(r)
 	if err != nil {
-		return nil, nil, "", nil, err
+		return nil, nil, nil, nil, "", nil, err
 	}
 	params, values := getParamsAndValuesFromLegacyFormat(benchData)
-	return params, values, benchData.Hash, benchData.Key, nil
+	return params, values, make([][]float32, len(values)), make([]tracestore.TraceMetadata, len(values)), benchData.Hash, benchData.Key, nil
 }
 
-func (p *Parser) extractFromVersion1File(r io.Reader, filename string) ([]paramtools.Params, []float32, string, map[string]string, error) {
+func (p *Parser) extractFromVersion1File(r io.Reader, filename string) ([]paramtools.Params, []float32, [][]float32, []tracestore.TraceMetadata, string, map[string]string, error) {
 	f, err := format.Parse(r)
 	if err != nil {
 		sklog.Warningf("Failed to parse the version one file: %s, got error: %s", filename, err)
-		return nil, nil, "", nil, err
+		return nil, nil, nil, nil, "", nil, err
 	}
 	params, values := getParamsAndValuesFromVersion1Format(f, p.invalidParamCharRegex)
-	return params, values, f.GitHash, f.Key, nil
+	return params, values, make([][]float32, len(values)), make([]tracestore.TraceMetadata, len(values)), f.GitHash, f.Key, nil
+}
+
+func (p *Parser) extractFromVersion2File(r io.Reader, filename string) ([]paramtools.Params, []float32, [][]float32, []tracestore.TraceMetadata, string, map[string]string, error) {
+	f, err := format.ParseV2(r)
+	if err != nil {
+		sklog.Warningf("Failed to parse the version two file: %s, got error: %s", filename, err)
+		return nil, nil, nil, nil, "", nil, err
+	}
+	params, values, samples, metadata := getParamsAndValuesFromVersion2Format(f, p.invalidParamCharRegex)
+	return params, values, samples, metadata, f.GitHash, f.Key, nil
 }
 
 // Parse the given file.File contents.
@@ -245,6 +294,16 @@ func (p *Parser) extractFromVersion1File(r io.Reader, filename string) ([]paramt
 //
 // The File.Contents will be closed when this func returns.
 func (p *Parser) Parse(ctx context.Context, file file.File) ([]paramtools.Params, []float32, string, error) {
+	params, values, _, _, hash, err := p.ParseWithMetadata(ctx, file)
+	return params, values, hash, err
+}
+
+// ParseWithMetadata is like Parse, but also returns the raw samples that each
+// value was computed from and the metadata of each trace as third and fourth
+// parallel slices. The entry in the samples slice is nil, and the entry in the
+// metadata slice is empty, for values that have none, which includes all values
+// from files that aren't in format.FormatV2.
+func (p *Parser) ParseWithMetadata(ctx context.Context, file file.File) ([]paramtools.Params, []float32, [][]float32, []tracestore.TraceMetadata, string, error) {
 	_, span := trace.StartSpan(ctx, "ingest.parser.Parse")
 	defer span.End()
 
@@ -258,37 +317,45 @@ func (p *Parser) Parse(ctx context.Context, file file.File) ([]paramtools.Params
 	sklog.Infof("Finished readall.")
 	if err != nil {
 		p.parseFailCounter.Inc(1)
-		return nil, nil, "", skerr.Wrap(err)
+		return nil, nil, nil, nil, "", skerr.Wrap(err)
 	}
 	r := bytes.NewReader(b)
 
 	// Expect the file to be in format.FileFormat.
 	sklog.Info("About to extract")
-	params, values, hash, commonKeys, err := p.extractFromVersion1File(r, file.Name)
+	params, values, samples, metadata, hash, commonKeys, err := p.extractFromVersion1File(r, file.Name)
+	if err != nil {
+		// Fallback to version 2 of the format.
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, nil, nil, nil, "", skerr.Wrap(err)
+		}
+		sklog.Info("About to extract from version 2.")
+		params, values, samples, metadata, hash, commonKeys, err = p.extractFromVersion2File(r, file.Name)
+	}
 	if err != nil {
 		// Fallback to the legacy format.
 		if _, err := r.Seek(0, io.SeekStart); err != nil {
-			return nil, nil, "", skerr.Wrap(err)
+			return nil, nil, nil, nil, "", skerr.Wrap(err)
 		}
 		sklog.Info("About to extract from legacy.")
-		params, values, hash, commonKeys, err = p.extractFromLegacyFile(r, file.Name)
+		params, values, samples, metadata, hash, commonKeys, err = p.extractFromLegacyFile(r, file.Name)
 	}
 	if err != nil && err != ErrFileShouldBeSkipped {
 		p.parseFailCounter.Inc(1)
 	}
 	if err != nil {
-		return nil, nil, "", err
+		return nil, nil, nil, nil, "", err
 	}
 	branch, ok := p.checkBranchName(commonKeys)
 	if !ok {
-		return nil, nil, "", ErrFileShouldBeSkipped
+		return nil, nil, nil, nil, "", ErrFileShouldBeSkipped
 	}
 	if len(params) == 0 {
 		metrics2.GetCounter("perf_ingest_parser_no_data_in_file", map[string]string{"branch": branch}).Inc(1)
 		sklog.Infof("No data in: %q", file.Name)
-		return nil, nil, "", ErrFileShouldBeSkipped
+		return nil, nil, nil, nil, "", ErrFileShouldBeSkipped
 	}
-	return params, values, hash, nil
+	return params, values, samples, metadata, hash, nil
 }
 
 // ParseTryBot extracts the issue and patch identifiers from the file.File.
@@ -310,6 +377,15 @@ func (p *Parser) ParseTryBot(file file.File) (types.CL, string, error) {
 
 	parsed, err := format.Parse(r)
 	if err != nil {
+		// Fallback to version 2 of the format.
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			p.parseFailCounter.Inc(1)
+			return "", "", skerr.Wrap(err)
+		}
+		if parsedV2, err := format.ParseV2(r); err == nil {
+			return parsedV2.Issue, parsedV2.Patchset, nil
+		}
+
 		// Fallback to legacy format.
 		if _, err := r.Seek(0, io.SeekStart); err != nil {
 			p.parseFailCounter.Inc(1)
@@ -17,12 +17,14 @@ import (
 	"go.skia.org/infra/perf/go/config/validate"
 	"go.skia.org/infra/perf/go/file"
 	"go.skia.org/infra/perf/go/ingest/format"
+	"go.skia.org/infra/perf/go/tracestore"
 	"go.skia.org/infra/perf/go/types"
 )
 
 const goodBranchName = "some-branch-name"
 const legacyVersionName = "legacy"
 const versionOneName = "version_1"
+const versionTwoName = "version_2"
 
 var (
 	expectedGoodParams = paramtools.Params{
@@ -67,10 +69,90 @@ func TestGetParamsAndValuesFromFormat_Success(t *testing.T) {
 	assert.Contains(t, params, expectedGoodParams)
 }
 
+func TestGetParamsAndValuesFromVersion2Format_Success(t *testing.T) {
+	r := testutils.GetReader(t, filepath.Join(versionTwoName, "success.json"))
+
+	f, err := format.ParseV2(r)
+	require.NoError(t, err)
+
+	params, values, samples, metadata := getParamsAndValuesFromVersion2Format(f, query.InvalidChar)
+	assert.Len(t, values, 4)
+	assert.Len(t, params, 4)
+	assert.Len(t, samples, 4)
+	assert.Len(t, metadata, 4)
+	assert.Contains(t, values, float32(858))
+	assert.Contains(t, params, expectedGoodParams)
+
+	// The unit and improvement direction are metadata, not trace params.
+	expectedParamsWithMetadata := paramtools.Params{
+		"arch":        "x86",
+		"branch":      "some-branch-name",
+		"config":      "8888",
+		"gpu":         "GTX660",
+		"model":       "ShuttleA",
+		"os":          "Ubuntu12",
+		"source_type": "bench",
+		"sub_result":  "min_ms",
+		"system":      "UNIX",
+		"test":        "DeferredSurfaceCopy_discardable_640_480",
+	}
+	for i, p := range params {
+		if p.Equal(expectedParamsWithMetadata) {
+			assert.Equal(t, float32(2.223606), values[i])
+			assert.Equal(t, []float32{2.223606, 2.3, 2.5}, samples[i])
+		} else {
+			assert.Empty(t, samples[i])
+		}
+		if p["test"] == "DeferredSurfaceCopy_discardable_640_480" {
+			assert.Equal(t, tracestore.TraceMetadata{Unit: "ms", ImprovementDirection: "down"}, metadata[i])
+		} else {
+			assert.True(t, metadata[i].IsEmpty())
+		}
+	}
+	assert.Contains(t, params, expectedParamsWithMetadata)
+}
+
+func TestParseWithMetadata_Version2File_ReturnsSamplesAndMetadataParallelToValues(t *testing.T) {
+	p, f := parserForTest(t, versionTwoName, "success.json")
+	params, values, samples, metadata, gitHash, err := p.ParseWithMetadata(context.Background(), f)
+	require.NoError(t, err)
+	assert.Equal(t, "fe4a4029a080bc955e9588d05a6cd9eb490845d4", gitHash)
+	assert.Len(t, params, 4)
+	assert.Len(t, values, 4)
+	require.Len(t, samples, 4)
+	require.Len(t, metadata, 4)
+	numWithSamples := 0
+	for _, s := range samples {
+		if len(s) > 0 {
+			numWithSamples++
+		}
+	}
+	assert.Equal(t, 1, numWithSamples)
+	numWithMetadata := 0
+	for _, m := range metadata {
+		if !m.IsEmpty() {
+			numWithMetadata++
+		}
+	}
+	assert.Equal(t, 3, numWithMetadata)
+}
+
+func TestParseWithMetadata_Version1File_ReturnsNoSamplesOrMetadata(t *testing.T) {
+	p, f := parserForTest(t, versionOneName, "success.json")
+	params, _, samples, metadata, _, err := p.ParseWithMetadata(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, samples, len(params))
+	require.Len(t, metadata, len(params))
+	for i := range samples {
+		assert.Nil(t, samples[i])
+		assert.True(t, metadata[i].IsEmpty())
+	}
+}
+
 func TestParser(t *testing.T) {
 	// Loop over all the ingestion formats we support. Parallel test files with
 	// the same names are held in subdirectories of 'testdata'.
-	for _, ingestionFormat := range []string{legacyVersionName, versionOneName} {
+	for _, ingestionFormat := range []string{legacyVersionName, versionOneName, versionTwoName} {
 		for name, subTest := range SubTests {
 			subTestName := fmt.Sprintf("%s_%s", name, ingestionFormat)
 			t.Run(subTestName, func(t *testing.T) {
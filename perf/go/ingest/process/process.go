@@ -3,6 +3,7 @@ package process
 
 import (
 	"context"
+	"net/http"
 	"sync"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"go.skia.org/infra/go/metrics2"
 	"go.skia.org/infra/go/paramtools"
 	"go.skia.org/infra/go/query"
+	"go.skia.org/infra/go/secret"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/sklog"
 	"golang.org/x/oauth2/google"
@@ -20,6 +22,7 @@ import (
 	"go.skia.org/infra/perf/go/file"
 	"go.skia.org/infra/perf/go/git"
 	"go.skia.org/infra/perf/go/ingest/parser"
+	"go.skia.org/infra/perf/go/ingest/remotewrite"
 	"go.skia.org/infra/perf/go/ingestevents"
 	"go.skia.org/infra/perf/go/tracestore"
 	"go.skia.org/infra/perf/go/tracing"
@@ -36,7 +39,7 @@ const defaultDatabaseTimeout = time.Minute
 // sendPubSubEvent sends the unencoded params and paramset found in a single
 // ingested file to the PubSub topic specified in the selected Perf instances
 // configuration data.
-func sendPubSubEvent(ctx context.Context, pubSubClient *pubsub.Client, topicName string, params []paramtools.Params, paramset paramtools.ReadOnlyParamSet, filename string) error {
+func sendPubSubEvent(ctx context.Context, pubSubClient *pubsub.Client, topicName string, params []paramtools.Params, paramset paramtools.ReadOnlyParamSet, filename string, commitNumber types.CommitNumber) error {
 	if topicName == "" {
 		return nil
 	}
@@ -49,9 +52,10 @@ func sendPubSubEvent(ctx context.Context, pubSubClient *pubsub.Client, topicName
 		traceIDs = append(traceIDs, key)
 	}
 	ie := &ingestevents.IngestEvent{
-		TraceIDs: traceIDs,
-		ParamSet: paramset,
-		Filename: filename,
+		TraceIDs:     traceIDs,
+		ParamSet:     paramset,
+		Filename:     filename,
+		CommitNumber: commitNumber,
 	}
 	body, err := ingestevents.CreatePubSubBody(ie)
 	if err != nil {
@@ -128,7 +132,7 @@ func (w *workerInfo) processSingleFile(f file.File) error {
 	w.filesReceived.Inc(1)
 
 	// Parse the file.
-	params, values, gitHash, err := w.p.Parse(ctx, f)
+	params, values, samples, metadata, gitHash, err := w.p.ParseWithMetadata(ctx, f)
 	if err != nil {
 		if err == parser.ErrFileShouldBeSkipped {
 			sklog.Debugf("File should be skipped %v: %s", f, err)
@@ -184,24 +188,23 @@ func (w *workerInfo) processSingleFile(f file.File) error {
 	ps.Normalize()
 
 	sklog.Info("WriteTraces")
-	const retries = writeRetries
-	i := 0
-	writeFailed := false
-	for {
-		// Write data to the trace store.
-		err := w.store.WriteTraces(ctx, commitNumber, params, values, ps, f.Name, time.Now())
-		if err == nil {
-			break
-		}
-		i++
-		if i > retries {
-			writeFailed = true
-			break
-		}
+	err = writeWithRetries(func() error {
+		return w.store.WriteTraces(ctx, commitNumber, params, values, ps, f.Name, time.Now())
+	})
+	// Only files in the version 2 format carry samples and metadata.
+	if err == nil && hasSamples(samples) {
+		err = writeWithRetries(func() error {
+			return w.store.WriteSamples(ctx, commitNumber, params, samples)
+		})
 	}
-	if writeFailed {
+	if err == nil && hasMetadata(metadata) {
+		err = writeWithRetries(func() error {
+			return w.store.WriteTraceMetadata(ctx, params, metadata)
+		})
+	}
+	if err != nil {
 		w.failedToWrite.Inc(1)
-		sklog.Errorf("Failed to write after %d retries %q: %s", retries, f.Name, err)
+		sklog.Errorf("Failed to write after %d retries %q: %s", writeRetries, f.Name, err)
 		nackMessageIfNecessary(w.dlEnabled, f)
 	} else {
 		if f.PubSubMsg != nil {
@@ -212,7 +215,7 @@ func (w *workerInfo) processSingleFile(f file.File) error {
 		w.successfulWriteCount.Inc(int64(len(params)))
 	}
 
-	if err := sendPubSubEvent(ctx, w.pubSubClient, w.instanceConfig.IngestionConfig.FileIngestionTopicName, params, ps.Freeze(), f.Name); err != nil {
+	if err := sendPubSubEvent(ctx, w.pubSubClient, w.instanceConfig.IngestionConfig.FileIngestionTopicName, params, ps.Freeze(), f.Name, commitNumber); err != nil {
 		sklog.Errorf("Failed to send pubsub event: %s", err)
 	} else {
 		sklog.Info("FileIngestionTopicName pubsub message sent.")
@@ -220,6 +223,18 @@ func (w *workerInfo) processSingleFile(f file.File) error {
 	return nil
 }
 
+// writeWithRetries calls write until it succeeds, retrying up to writeRetries
+// times, and returns the last error if it never succeeds.
+func writeWithRetries(write func() error) error {
+	var err error
+	for i := 0; i <= writeRetries; i++ {
+		if err = write(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
 // worker ingests files that arrive on the given 'ch' channel.
 func worker(ctx context.Context, wg *sync.WaitGroup, g git.Git, store tracestore.TraceStore, ch <-chan file.File, pubSubClient *pubsub.Client, instanceConfig *config.InstanceConfig) {
 	// Metrics.
@@ -277,16 +292,6 @@ func Start(ctx context.Context, local bool, numParallelIngesters int, instanceCo
 		}
 	}
 
-	// New file.Source.
-	source, err := builders.NewSourceFromConfig(ctx, instanceConfig, local)
-	if err != nil {
-		return skerr.Wrap(err)
-	}
-	ch, err := source.Start(ctx)
-	if err != nil {
-		return skerr.Wrap(err)
-	}
-
 	// New TraceStore.
 	store, err := builders.NewTraceStoreFromConfig(ctx, local, instanceConfig)
 	if err != nil {
@@ -302,6 +307,20 @@ func Start(ctx context.Context, local bool, numParallelIngesters int, instanceCo
 	// Polling isn't needed because we call update on the repo if we find a git hash we don't recognize.
 	// g.StartBackgroundPolling(ctx, gitRefreshDuration)
 
+	if instanceConfig.IngestionConfig.SourceConfig.SourceType == config.PrometheusRemoteWriteSourceType {
+		return serveRemoteWrite(ctx, store, g, pubSubClient, instanceConfig)
+	}
+
+	// New file.Source.
+	source, err := builders.NewSourceFromConfig(ctx, instanceConfig, local)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	ch, err := source.Start(ctx)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+
 	sklog.Info("Waiting on files to process.")
 
 	var wg sync.WaitGroup
@@ -316,6 +335,36 @@ func Start(ctx context.Context, local bool, numParallelIngesters int, instanceCo
 	return nil
 }
 
+// serveRemoteWrite accepts data pushed via Prometheus remote-write requests and
+// writes it to the trace store. It only returns on error.
+func serveRemoteWrite(ctx context.Context, store tracestore.TraceStore, g git.Git, pubSubClient *pubsub.Client, instanceConfig *config.InstanceConfig) error {
+	sources := instanceConfig.IngestionConfig.SourceConfig.Sources
+	if len(sources) != 1 {
+		return skerr.Fmt("For a source_type of %q there must be a single entry for 'sources', found %d.", config.PrometheusRemoteWriteSourceType, len(sources))
+	}
+	sourceConfig := instanceConfig.IngestionConfig.SourceConfig
+	secretClient, err := secret.NewClient(ctx)
+	if err != nil {
+		return skerr.Wrapf(err, "creating secret client")
+	}
+	bearerToken, err := secretClient.Get(ctx, sourceConfig.BearerTokenSecretProject, sourceConfig.BearerTokenSecretName, secret.VersionLatest)
+	if err != nil {
+		return skerr.Wrapf(err, "loading bearer token from project: %q  name: %q", sourceConfig.BearerTokenSecretProject, sourceConfig.BearerTokenSecretName)
+	}
+	h, err := remotewrite.New(store, g, instanceConfig, bearerToken, func(ctx context.Context, commitNumber types.CommitNumber, params []paramtools.Params, ps paramtools.ReadOnlyParamSet, source string) {
+		if err := sendPubSubEvent(ctx, pubSubClient, instanceConfig.IngestionConfig.FileIngestionTopicName, params, ps, source, commitNumber); err != nil {
+			sklog.Errorf("Failed to send pubsub event: %s", err)
+		}
+	})
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(remotewrite.URLPath, h)
+	sklog.Infof("Listening for Prometheus remote-write requests at %s%s", sources[0], remotewrite.URLPath)
+	return skerr.Wrap(http.ListenAndServe(sources[0], mux))
+}
+
 func nackMessageIfNecessary(dlEnabled bool, f file.File) {
 	if dlEnabled {
 		// This message will be available to the ingestor immediately.
@@ -323,3 +372,23 @@ func nackMessageIfNecessary(dlEnabled bool, f file.File) {
 		sklog.Debugf("Message nacked during message process: %v", f.PubSubMsg)
 	}
 }
+
+// hasSamples returns true if any of the entries in samples is non-empty.
+func hasSamples(samples [][]float32) bool {
+	for _, s := range samples {
+		if len(s) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMetadata returns true if any of the entries in metadata is non-empty.
+func hasMetadata(metadata []tracestore.TraceMetadata) bool {
+	for _, m := range metadata {
+		if !m.IsEmpty() {
+			return true
+		}
+	}
+	return false
+}
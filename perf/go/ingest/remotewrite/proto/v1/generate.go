@@ -0,0 +1,5 @@
+// Generate the go code from the protocol buffer definitions.
+//go:generate bazelisk run --config=mayberemote //:protoc -- --go_opt=module=go.skia.org/infra/perf/go/ingest/remotewrite/proto/v1 --go_out=. ./remote.proto
+//go:generate bazelisk run --config=mayberemote //:goimports "--run_under=cd $PWD &&" -- -w remote.pb.go
+
+package v1
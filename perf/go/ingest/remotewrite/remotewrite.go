@@ -0,0 +1,356 @@
+// Package remotewrite ingests data that is pushed to Perf using the Prometheus
+// remote-write protocol, see
+// https://prometheus.io/docs/concepts/remote_write_spec/.
+//
+// Each time series becomes a trace, with the labels as the trace params,
+// except for the label configured as the commit position label, whose value,
+// either a commit number or a git hash depending on the configured label type,
+// determines the commit the sample is written to.
+//
+// Requests must present the configured bearer token in their Authorization
+// header.
+package remotewrite
+
+import (
+	"context"
+	"crypto/subtle"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/now"
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/query"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/git"
+	pb "go.skia.org/infra/perf/go/ingest/remotewrite/proto/v1"
+	"go.skia.org/infra/perf/go/tracestore"
+	"go.skia.org/infra/perf/go/types"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// URLPath is the path that remote-write requests are accepted on.
+	URLPath = "/api/v1/write"
+
+	// MetricNameParam is the param key that the Prometheus metric name, i.e.
+	// the value of the __name__ label, is stored under.
+	MetricNameParam = "metric"
+
+	// metricNameLabel is the Prometheus label that holds the metric name.
+	metricNameLabel = "__name__"
+
+	// maxRequestSize is the largest compressed request body accepted.
+	maxRequestSize = 32 * 1024 * 1024
+
+	// maxDecodedRequestSize is the largest decompressed request body accepted.
+	maxDecodedRequestSize = 128 * 1024 * 1024
+
+	// minUpdateInterval is the minimum time between updates of the repo
+	// triggered by commit position labels that can't be found, so that
+	// senders can't force an update on every request.
+	minUpdateInterval = time.Minute
+
+	// bearerPrefix is the prefix of the Authorization header value.
+	bearerPrefix = "Bearer "
+
+	// defaultDatabaseTimeout is the context timeout used when writing the
+	// traces of a single request.
+	defaultDatabaseTimeout = time.Minute
+
+	// Source is the source recorded for all the traces written from
+	// remote-write requests. It doesn't identify the sender, since every
+	// distinct source adds a row to the source files table.
+	Source = "prometheus_remote_write"
+)
+
+// OnIngest is called after the traces for a single commit have been written.
+type OnIngest func(ctx context.Context, commitNumber types.CommitNumber, params []paramtools.Params, ps paramtools.ReadOnlyParamSet, source string)
+
+// Handler is an http.Handler for Prometheus remote-write requests.
+type Handler struct {
+	store                 tracestore.TraceStore
+	g                     git.Git
+	bearerToken           string
+	commitPositionLabel   string
+	commitPositionType    config.CommitPositionLabelType
+	invalidParamCharRegex *regexp.Regexp
+	onIngest              OnIngest
+
+	// mutex protects lastUpdate.
+	mutex sync.Mutex
+
+	// lastUpdate is the last time the repo was updated because of a commit
+	// position label that couldn't be found.
+	lastUpdate time.Time
+
+	requestsReceived     metrics2.Counter
+	unauthorized         metrics2.Counter
+	badRequests          metrics2.Counter
+	missingCommitLabel   metrics2.Counter
+	badCommit            metrics2.Counter
+	failedToWrite        metrics2.Counter
+	successfulWriteCount metrics2.Counter
+}
+
+// New returns a new Handler that writes to the given store. Requests must
+// present bearerToken in their Authorization header. onIngest may be nil.
+func New(store tracestore.TraceStore, g git.Git, instanceConfig *config.InstanceConfig, bearerToken string, onIngest OnIngest) (*Handler, error) {
+	if bearerToken == "" {
+		return nil, skerr.Fmt("A bearer token must be supplied.")
+	}
+	commitPositionLabel := instanceConfig.IngestionConfig.SourceConfig.CommitPositionLabel
+	if commitPositionLabel == "" {
+		return nil, skerr.Fmt("A commit_position_label must be supplied.")
+	}
+	commitPositionType := instanceConfig.IngestionConfig.SourceConfig.CommitPositionLabelType
+	switch commitPositionType {
+	case "":
+		commitPositionType = config.CommitNumberLabelType
+	case config.CommitNumberLabelType, config.GitHashLabelType:
+	default:
+		return nil, skerr.Fmt("Unknown commit_position_label_type: %q", commitPositionType)
+	}
+	invalidParamCharRegex := query.InvalidChar
+	if instanceConfig.InvalidParamCharRegex != "" {
+		var err error
+		invalidParamCharRegex, err = regexp.Compile(instanceConfig.InvalidParamCharRegex)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+	}
+	return &Handler{
+		store:                 store,
+		g:                     g,
+		bearerToken:           bearerToken,
+		commitPositionLabel:   commitPositionLabel,
+		commitPositionType:    commitPositionType,
+		invalidParamCharRegex: invalidParamCharRegex,
+		onIngest:              onIngest,
+		requestsReceived:      metrics2.GetCounter("perfserver_ingest_remote_write_requests_received"),
+		unauthorized:          metrics2.GetCounter("perfserver_ingest_remote_write_unauthorized"),
+		badRequests:           metrics2.GetCounter("perfserver_ingest_remote_write_bad_requests"),
+		missingCommitLabel:    metrics2.GetCounter("perfserver_ingest_remote_write_missing_commit_label"),
+		badCommit:             metrics2.GetCounter("perfserver_ingest_remote_write_bad_commit"),
+		failedToWrite:         metrics2.GetCounter("perfserver_ingest_remote_write_failed_to_write"),
+		successfulWriteCount:  metrics2.GetCounter("perfserver_ingest_remote_write_num_points_written"),
+	}, nil
+}
+
+// ServeHTTP implements http.Handler.
+//
+// Per the remote-write spec, malformed requests get a 4xx response, which the
+// sender will not retry, while failures to write to the TraceStore get a 5xx
+// response, which the sender will retry.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.requestsReceived.Inc(1)
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is supported.", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(r) {
+		h.unauthorized.Inc(1)
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized.", http.StatusUnauthorized)
+		return
+	}
+	compressed, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize+1))
+	if err != nil {
+		h.badRequest(w, skerr.Wrapf(err, "Failed to read request body"))
+		return
+	}
+	if len(compressed) > maxRequestSize {
+		h.badRequest(w, skerr.Fmt("Request body is larger than %d bytes.", maxRequestSize))
+		return
+	}
+	decodedLen, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		h.badRequest(w, skerr.Wrapf(err, "Failed to decompress request body"))
+		return
+	}
+	if decodedLen > maxDecodedRequestSize {
+		h.badRequest(w, skerr.Fmt("Decompressed request body is larger than %d bytes.", maxDecodedRequestSize))
+		return
+	}
+	b, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		h.badRequest(w, skerr.Wrapf(err, "Failed to decompress request body"))
+		return
+	}
+	req := &pb.WriteRequest{}
+	if err := proto.Unmarshal(b, req); err != nil {
+		h.badRequest(w, skerr.Wrapf(err, "Failed to decode WriteRequest"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), defaultDatabaseTimeout)
+	defer cancel()
+	if err := h.Ingest(ctx, req, Source); err != nil {
+		sklog.Errorf("Failed to ingest remote-write request: %s", err)
+		http.Error(w, "Failed to write traces.", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorized returns true if the request presents the bearer token.
+func (h *Handler) authorized(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, bearerPrefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, bearerPrefix)), []byte(h.bearerToken)) == 1
+}
+
+func (h *Handler) badRequest(w http.ResponseWriter, err error) {
+	h.badRequests.Inc(1)
+	sklog.Warningf("Bad remote-write request: %s", err)
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// commitTraces are the traces to be written to a single commit.
+type commitTraces struct {
+	params []paramtools.Params
+	values []float32
+
+	// timestamps of the samples in values, used to keep only the latest
+	// sample for each trace.
+	timestamps []int64
+
+	// index maps trace ids to their index in params and values.
+	index map[string]int
+}
+
+func (c *commitTraces) add(traceID string, p paramtools.Params, sample *pb.Sample) {
+	if i, ok := c.index[traceID]; ok {
+		if sample.Timestamp >= c.timestamps[i] {
+			c.values[i] = float32(sample.Value)
+			c.timestamps[i] = sample.Timestamp
+		}
+		return
+	}
+	c.index[traceID] = len(c.params)
+	c.params = append(c.params, p)
+	c.values = append(c.values, float32(sample.Value))
+	c.timestamps = append(c.timestamps, sample.Timestamp)
+}
+
+// Ingest writes the samples in the WriteRequest to the TraceStore. If a time
+// series has more than one sample for the same commit then only the most
+// recent sample is written.
+//
+// Time series without a commit position label, or with a commit that can't be
+// found, are skipped. An error is only returned if writing to the TraceStore
+// fails.
+func (h *Handler) Ingest(ctx context.Context, req *pb.WriteRequest, source string) error {
+	byCommitPosition := map[string]*commitTraces{}
+	for _, ts := range req.Timeseries {
+		p := paramtools.Params{}
+		commitPosition := ""
+		for _, label := range ts.Labels {
+			switch label.Name {
+			case h.commitPositionLabel:
+				commitPosition = label.Value
+			case metricNameLabel:
+				p[MetricNameParam] = label.Value
+			default:
+				p[label.Name] = label.Value
+			}
+		}
+		if commitPosition == "" {
+			h.missingCommitLabel.Inc(1)
+			continue
+		}
+		p = query.ForceValidWithRegex(p, h.invalidParamCharRegex)
+		traceID, err := query.MakeKey(p)
+		if err != nil {
+			h.badRequests.Inc(1)
+			sklog.Warningf("Invalid params from remote-write labels %v: %s", ts.Labels, err)
+			continue
+		}
+		for _, sample := range ts.Samples {
+			// Stale markers and other non-finite values can't be stored.
+			if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+				continue
+			}
+			c, ok := byCommitPosition[commitPosition]
+			if !ok {
+				c = &commitTraces{index: map[string]int{}}
+				byCommitPosition[commitPosition] = c
+			}
+			c.add(traceID, p, sample)
+		}
+	}
+
+	for commitPosition, c := range byCommitPosition {
+		commitNumber, err := h.commitNumber(ctx, commitPosition)
+		if err != nil {
+			h.badCommit.Inc(1)
+			sklog.Errorf("Failed to find commit for %s=%q: %s", h.commitPositionLabel, commitPosition, err)
+			continue
+		}
+		ps := paramtools.NewParamSet(c.params...)
+		ps.Normalize()
+		if err := h.store.WriteTraces(ctx, commitNumber, c.params, c.values, ps, source, now.Now(ctx)); err != nil {
+			h.failedToWrite.Inc(1)
+			return skerr.Wrapf(err, "Failed to write traces for commit %d", commitNumber)
+		}
+		h.successfulWriteCount.Inc(int64(len(c.params)))
+		if h.onIngest != nil {
+			h.onIngest(ctx, commitNumber, c.params, ps.Freeze(), source)
+		}
+	}
+	return nil
+}
+
+// commitNumber returns the CommitNumber for the value of a commit position
+// label, which is interpreted according to the configured label type.
+func (h *Handler) commitNumber(ctx context.Context, commitPosition string) (types.CommitNumber, error) {
+	lookup := func() (types.CommitNumber, error) {
+		if h.commitPositionType == config.GitHashLabelType {
+			return h.g.CommitNumberFromGitHash(ctx, commitPosition)
+		}
+		n, err := strconv.ParseInt(commitPosition, 10, 32)
+		if err != nil {
+			return types.BadCommitNumber, skerr.Wrapf(err, "Not a commit number")
+		}
+		commitNumber := types.CommitNumber(n)
+		if _, err := h.g.GitHashFromCommitNumber(ctx, commitNumber); err != nil {
+			return types.BadCommitNumber, skerr.Wrap(err)
+		}
+		return commitNumber, nil
+	}
+	commitNumber, err := lookup()
+	if err == nil {
+		return commitNumber, nil
+	}
+	// The commit may have landed after our last update of the repo.
+	if !h.updateIfStale(ctx) {
+		return types.BadCommitNumber, skerr.Wrap(err)
+	}
+	return lookup()
+}
+
+// updateIfStale updates the repo, unless it was already updated less than
+// minUpdateInterval ago, and returns true if the repo was updated.
+func (h *Handler) updateIfStale(ctx context.Context) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if now.Now(ctx).Sub(h.lastUpdate) < minUpdateInterval {
+		return false
+	}
+	h.lastUpdate = now.Now(ctx)
+	if err := h.g.Update(ctx); err != nil {
+		sklog.Errorf("Failed to Update: %s", err)
+	}
+	return true
+}
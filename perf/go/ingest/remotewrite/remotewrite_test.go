@@ -0,0 +1,343 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/now"
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/testutils"
+	"go.skia.org/infra/perf/go/config"
+	gitMocks "go.skia.org/infra/perf/go/git/mocks"
+	pb "go.skia.org/infra/perf/go/ingest/remotewrite/proto/v1"
+	tracestoreMocks "go.skia.org/infra/perf/go/tracestore/mocks"
+	"go.skia.org/infra/perf/go/types"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	commitPositionLabel = "commit_position"
+	source              = "my-source"
+	bearerToken         = "my-token"
+)
+
+var errMyMockError = errors.New("my mock error")
+
+// marshalWriteRequest returns the serialized, uncompressed, WriteRequest.
+func marshalWriteRequest(t *testing.T, req *pb.WriteRequest) []byte {
+	b, err := proto.Marshal(req)
+	require.NoError(t, err)
+	return b
+}
+
+func newForTest(t *testing.T, labelType config.CommitPositionLabelType) (*Handler, *tracestoreMocks.TraceStore, *gitMocks.Git) {
+	store := tracestoreMocks.NewTraceStore(t)
+	g := gitMocks.NewGit(t)
+	instanceConfig := &config.InstanceConfig{
+		IngestionConfig: config.IngestionConfig{
+			SourceConfig: config.SourceConfig{
+				SourceType:              config.PrometheusRemoteWriteSourceType,
+				CommitPositionLabel:     commitPositionLabel,
+				CommitPositionLabelType: labelType,
+			},
+		},
+	}
+	h, err := New(store, g, instanceConfig, bearerToken, nil)
+	require.NoError(t, err)
+	return h, store, g
+}
+
+func testRequest() *pb.WriteRequest {
+	return &pb.WriteRequest{
+		Timeseries: []*pb.TimeSeries{
+			{
+				Labels: []*pb.Label{
+					{Name: "__name__", Value: "frame_time"},
+					{Name: "bot", Value: "linux-perf"},
+					{Name: commitPositionLabel, Value: "12"},
+				},
+				Samples: []*pb.Sample{
+					{Value: 2.5, Timestamp: 2000},
+					{Value: 1.5, Timestamp: 1000},
+				},
+			},
+			{
+				Labels: []*pb.Label{
+					{Name: "__name__", Value: "frame_time"},
+					{Name: "bot", Value: "mac perf"},
+					{Name: commitPositionLabel, Value: "12"},
+				},
+				Samples: []*pb.Sample{
+					{Value: 3, Timestamp: 1000},
+					{Value: math.NaN(), Timestamp: 3000},
+				},
+			},
+			{
+				// No commit position label so it is skipped.
+				Labels: []*pb.Label{
+					{Name: "__name__", Value: "frame_time"},
+				},
+				Samples: []*pb.Sample{
+					{Value: 4, Timestamp: 1000},
+				},
+			},
+		},
+	}
+}
+
+var expectedParams = []paramtools.Params{
+	{MetricNameParam: "frame_time", "bot": "linux-perf"},
+	{MetricNameParam: "frame_time", "bot": "mac_perf"},
+}
+
+func TestIngest_CommitNumberLabel_WritesLatestSampleForEachTrace(t *testing.T) {
+	h, store, g := newForTest(t, config.CommitNumberLabelType)
+	g.On("GitHashFromCommitNumber", testutils.AnyContext, types.CommitNumber(12)).Return("abcdef", nil)
+	ps := paramtools.NewParamSet(expectedParams...)
+	ps.Normalize()
+	store.On("WriteTraces", testutils.AnyContext, types.CommitNumber(12), expectedParams, []float32{2.5, 3}, ps, source, mock.Anything).Return(nil)
+
+	require.NoError(t, h.Ingest(context.Background(), testRequest(), source))
+}
+
+func TestIngest_GitHashLabelNotFoundUntilUpdate_WritesTraces(t *testing.T) {
+	h, store, g := newForTest(t, config.GitHashLabelType)
+	req := &pb.WriteRequest{
+		Timeseries: []*pb.TimeSeries{
+			{
+				Labels:  []*pb.Label{{Name: "bot", Value: "linux"}, {Name: commitPositionLabel, Value: "abcdef"}},
+				Samples: []*pb.Sample{{Value: 1, Timestamp: 1000}},
+			},
+		},
+	}
+	g.On("CommitNumberFromGitHash", testutils.AnyContext, "abcdef").Return(types.BadCommitNumber, errMyMockError).Once()
+	g.On("Update", testutils.AnyContext).Return(nil)
+	g.On("CommitNumberFromGitHash", testutils.AnyContext, "abcdef").Return(types.CommitNumber(7), nil).Once()
+	store.On("WriteTraces", testutils.AnyContext, types.CommitNumber(7), []paramtools.Params{{"bot": "linux"}}, []float32{1}, mock.Anything, source, mock.Anything).Return(nil)
+
+	require.NoError(t, h.Ingest(context.Background(), req, source))
+}
+
+func TestIngest_GitHashLabelThatIsAllDigits_IsLookedUpAsGitHash(t *testing.T) {
+	h, store, g := newForTest(t, config.GitHashLabelType)
+	req := &pb.WriteRequest{
+		Timeseries: []*pb.TimeSeries{
+			{
+				Labels:  []*pb.Label{{Name: "bot", Value: "linux"}, {Name: commitPositionLabel, Value: "1234567"}},
+				Samples: []*pb.Sample{{Value: 1, Timestamp: 1000}},
+			},
+		},
+	}
+	g.On("CommitNumberFromGitHash", testutils.AnyContext, "1234567").Return(types.CommitNumber(7), nil)
+	store.On("WriteTraces", testutils.AnyContext, types.CommitNumber(7), []paramtools.Params{{"bot": "linux"}}, []float32{1}, mock.Anything, source, mock.Anything).Return(nil)
+
+	require.NoError(t, h.Ingest(context.Background(), req, source))
+}
+
+func TestIngest_CommitNumberLabelThatIsNotANumber_TracesAreSkipped(t *testing.T) {
+	h, _, g := newForTest(t, config.CommitNumberLabelType)
+	req := &pb.WriteRequest{
+		Timeseries: []*pb.TimeSeries{
+			{
+				Labels:  []*pb.Label{{Name: "bot", Value: "linux"}, {Name: commitPositionLabel, Value: "abcdef"}},
+				Samples: []*pb.Sample{{Value: 1, Timestamp: 1000}},
+			},
+		},
+	}
+	g.On("Update", testutils.AnyContext).Return(nil)
+
+	// The store and git mocks will fail the test if WriteTraces or
+	// CommitNumberFromGitHash are called.
+	require.NoError(t, h.Ingest(context.Background(), req, source))
+}
+
+func TestIngest_NoCommitPositionLabelType_DefaultsToCommitNumber(t *testing.T) {
+	h, store, g := newForTest(t, "")
+	g.On("GitHashFromCommitNumber", testutils.AnyContext, types.CommitNumber(12)).Return("abcdef", nil)
+	store.On("WriteTraces", testutils.AnyContext, types.CommitNumber(12), expectedParams, []float32{2.5, 3}, mock.Anything, source, mock.Anything).Return(nil)
+
+	require.NoError(t, h.Ingest(context.Background(), testRequest(), source))
+}
+
+func TestIngest_WriteTraces_UsesTimeFromContext(t *testing.T) {
+	h, store, g := newForTest(t, config.CommitNumberLabelType)
+	ts := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	g.On("GitHashFromCommitNumber", testutils.AnyContext, types.CommitNumber(12)).Return("abcdef", nil)
+	store.On("WriteTraces", testutils.AnyContext, types.CommitNumber(12), expectedParams, []float32{2.5, 3}, mock.Anything, source, ts).Return(nil)
+
+	ctx := context.WithValue(context.Background(), now.ContextKey, ts)
+	require.NoError(t, h.Ingest(ctx, testRequest(), source))
+}
+
+func TestIngest_UnknownCommit_TracesAreSkipped(t *testing.T) {
+	h, _, g := newForTest(t, config.CommitNumberLabelType)
+	g.On("GitHashFromCommitNumber", testutils.AnyContext, types.CommitNumber(12)).Return("", errMyMockError)
+	g.On("Update", testutils.AnyContext).Return(nil)
+
+	// The store mock will fail the test if WriteTraces is called.
+	require.NoError(t, h.Ingest(context.Background(), testRequest(), source))
+}
+
+func TestIngest_WriteTracesFails_ReturnsError(t *testing.T) {
+	h, store, g := newForTest(t, config.CommitNumberLabelType)
+	g.On("GitHashFromCommitNumber", testutils.AnyContext, types.CommitNumber(12)).Return("abcdef", nil)
+	store.On("WriteTraces", testutils.AnyContext, types.CommitNumber(12), mock.Anything, mock.Anything, mock.Anything, source, mock.Anything).Return(errMyMockError)
+
+	require.ErrorIs(t, h.Ingest(context.Background(), testRequest(), source), errMyMockError)
+}
+
+// newRequest returns a remote-write request with the given body that presents
+// the bearer token.
+func newRequest(body []byte) *http.Request {
+	r := httptest.NewRequest("POST", URLPath, bytes.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+bearerToken)
+	return r
+}
+
+func TestServeHTTP_ValidRequest_ReturnsNoContent(t *testing.T) {
+	h, store, g := newForTest(t, config.CommitNumberLabelType)
+	g.On("GitHashFromCommitNumber", testutils.AnyContext, types.CommitNumber(12)).Return("abcdef", nil)
+	store.On("WriteTraces", testutils.AnyContext, types.CommitNumber(12), expectedParams, []float32{2.5, 3}, mock.Anything, Source, mock.Anything).Return(nil)
+
+	body := snappy.Encode(nil, marshalWriteRequest(t, testRequest()))
+	r := newRequest(body)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestServeHTTP_NotSnappyCompressed_ReturnsBadRequest(t *testing.T) {
+	h, _, _ := newForTest(t, config.CommitNumberLabelType)
+
+	r := newRequest([]byte("not snappy"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestServeHTTP_TruncatedWriteRequest_ReturnsBadRequest(t *testing.T) {
+	h, _, _ := newForTest(t, config.CommitNumberLabelType)
+
+	b := marshalWriteRequest(t, testRequest())
+	r := newRequest(snappy.Encode(nil, b[:len(b)-3]))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to decode WriteRequest")
+}
+
+func TestServeHTTP_NoAuthorizationHeader_ReturnsUnauthorized(t *testing.T) {
+	h, _, _ := newForTest(t, config.CommitNumberLabelType)
+
+	body := snappy.Encode(nil, marshalWriteRequest(t, testRequest()))
+	r := httptest.NewRequest("POST", URLPath, bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestServeHTTP_WrongBearerToken_ReturnsUnauthorized(t *testing.T) {
+	h, _, _ := newForTest(t, config.CommitNumberLabelType)
+
+	body := snappy.Encode(nil, marshalWriteRequest(t, testRequest()))
+	r := newRequest(body)
+	r.Header.Set("Authorization", "Bearer not-my-token")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestServeHTTP_DecodedLengthTooLarge_ReturnsBadRequest(t *testing.T) {
+	h, _, _ := newForTest(t, config.CommitNumberLabelType)
+
+	// A snappy block starts with the uvarint encoded length of the decoded
+	// data.
+	body := binary.AppendUvarint(nil, maxDecodedRequestSize+1)
+	body = append(body, 0, 0, 0, 0)
+	r := newRequest(body)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Decompressed request body is larger")
+}
+
+func TestServeHTTP_WriteFails_ReturnsInternalServerErrorSoSenderRetries(t *testing.T) {
+	h, store, g := newForTest(t, config.CommitNumberLabelType)
+	g.On("GitHashFromCommitNumber", testutils.AnyContext, types.CommitNumber(12)).Return("abcdef", nil)
+	store.On("WriteTraces", testutils.AnyContext, types.CommitNumber(12), mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errMyMockError)
+
+	body := snappy.Encode(nil, marshalWriteRequest(t, testRequest()))
+	r := newRequest(body)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestServeHTTP_GetRequest_ReturnsMethodNotAllowed(t *testing.T) {
+	h, _, _ := newForTest(t, config.CommitNumberLabelType)
+
+	r := httptest.NewRequest("GET", URLPath, nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestIngest_UnknownCommitTwiceWithinUpdateInterval_UpdatesOnce(t *testing.T) {
+	h, _, g := newForTest(t, config.CommitNumberLabelType)
+	g.On("GitHashFromCommitNumber", testutils.AnyContext, types.CommitNumber(12)).Return("", errMyMockError)
+	g.On("Update", testutils.AnyContext).Return(nil).Once()
+
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.WithValue(context.Background(), now.ContextKey, start)
+	require.NoError(t, h.Ingest(ctx, testRequest(), source))
+	ctx = context.WithValue(context.Background(), now.ContextKey, start.Add(minUpdateInterval/2))
+	require.NoError(t, h.Ingest(ctx, testRequest(), source))
+	g.AssertNumberOfCalls(t, "Update", 1)
+
+	// Once the interval has passed the repo is updated again.
+	g.On("Update", testutils.AnyContext).Return(nil).Once()
+	ctx = context.WithValue(context.Background(), now.ContextKey, start.Add(minUpdateInterval))
+	require.NoError(t, h.Ingest(ctx, testRequest(), source))
+	g.AssertNumberOfCalls(t, "Update", 2)
+}
+
+func TestNew_NoCommitPositionLabel_ReturnsError(t *testing.T) {
+	_, err := New(nil, nil, &config.InstanceConfig{}, bearerToken, nil)
+	require.Error(t, err)
+}
+
+func TestNew_NoBearerToken_ReturnsError(t *testing.T) {
+	instanceConfig := &config.InstanceConfig{
+		IngestionConfig: config.IngestionConfig{
+			SourceConfig: config.SourceConfig{
+				CommitPositionLabel: commitPositionLabel,
+			},
+		},
+	}
+	_, err := New(nil, nil, instanceConfig, "", nil)
+	require.Error(t, err)
+}
+
+func TestNew_UnknownCommitPositionLabelType_ReturnsError(t *testing.T) {
+	instanceConfig := &config.InstanceConfig{
+		IngestionConfig: config.IngestionConfig{
+			SourceConfig: config.SourceConfig{
+				CommitPositionLabel:     commitPositionLabel,
+				CommitPositionLabelType: "svn",
+			},
+		},
+	}
+	_, err := New(nil, nil, instanceConfig, bearerToken, nil)
+	require.Error(t, err)
+}
@@ -13,6 +13,7 @@ import (
 	"go.skia.org/infra/go/paramtools"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/perf/go/types"
 )
 
 // IngestEvent is the PubSub body that is sent from the ingesters each time
@@ -26,6 +27,11 @@ type IngestEvent struct {
 
 	// Filename of the file ingested.
 	Filename string
+
+	// CommitNumber is the commit that the data was written to. It is
+	// types.BadCommitNumber for events sent by ingesters that predate this
+	// field.
+	CommitNumber types.CommitNumber
 }
 
 // CreatePubSubBody takes an IngestEvent and returns a byte slice that is a
@@ -41,7 +47,9 @@ func CreatePubSubBody(body *IngestEvent) ([]byte, error) {
 
 // DecodePubSubBody decodes an IngestEvent encoded by CreatePubSubBody.
 func DecodePubSubBody(b []byte) (*IngestEvent, error) {
-	var ret IngestEvent
+	ret := IngestEvent{
+		CommitNumber: types.BadCommitNumber,
+	}
 	buf := bytes.NewBuffer(b)
 	r, err := gzip.NewReader(buf)
 	if err != nil {
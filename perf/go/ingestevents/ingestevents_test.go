@@ -1,10 +1,15 @@
 package ingestevents
 
 import (
+	"bytes"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/perf/go/types"
 )
 
 func TestCreatePubSubBody(t *testing.T) {
@@ -29,8 +34,9 @@ func TestCreatePubSubBody(t *testing.T) {
 		{
 			name: "some data",
 			args: &IngestEvent{
-				TraceIDs: []string{",foo=bar,baz=quux,"},
-				ParamSet: paramtools.NewReadOnlyParamSet(paramtools.Params{"foo": "bar", "baz": "quux"}),
+				TraceIDs:     []string{",foo=bar,baz=quux,"},
+				ParamSet:     paramtools.NewReadOnlyParamSet(paramtools.Params{"foo": "bar", "baz": "quux"}),
+				CommitNumber: 12,
 			},
 		},
 	}
@@ -45,3 +51,15 @@ func TestCreatePubSubBody(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodePubSubBody_EventWithoutCommitNumber_ReturnsBadCommitNumber(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, util.WithGzipWriter(&buf, func(w io.Writer) error {
+		_, err := w.Write([]byte(`{"TraceIDs":[",foo=bar,"],"Filename":"gs://bucket/file.json"}`))
+		return err
+	}))
+	ie, err := DecodePubSubBody(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, types.BadCommitNumber, ie.CommitNumber)
+	assert.Equal(t, "gs://bucket/file.json", ie.Filename)
+}
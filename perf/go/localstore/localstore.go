@@ -0,0 +1,216 @@
+// Package localstore is a small embedded file database used by the 'local'
+// datastore type, which allows a single perfserver to run without any
+// external services, such as for local experiments, demos, and tests.
+//
+// The database is a directory, and each table in the database is a single Go
+// value that is gob encoded into its own file in that directory. Tables are
+// always written atomically, so a crash in the middle of a write leaves the
+// previous version of the table intact.
+//
+// Each write of a table is stamped with a new Version, which lets callers
+// that keep tables in memory notice when another process sharing the same
+// directory, such as the ingester, the frontend, or the maintenance job, has
+// changed a table, and reload it. Processes that read a table, modify it, and
+// write it back must hold the database's Lock for the whole sequence, which is
+// an flock(2) on a file in the directory, so that they don't overwrite each
+// other's changes.
+package localstore
+
+import (
+	"encoding/gob"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/util"
+)
+
+// fileExtension is appended to the table name to get the filename.
+const fileExtension = ".gob"
+
+// lockFilename is the name of the file in the database directory that Lock
+// locks.
+const lockFilename = "lock"
+
+// Version identifies a single write of a table. The zero Version means the
+// table doesn't exist.
+type Version uint64
+
+// newVersion returns a new random, non-zero, Version.
+func newVersion() Version {
+	for {
+		if ret := Version(rand.Uint64()); ret != 0 {
+			return ret
+		}
+	}
+}
+
+// DB is an embedded file database.
+type DB struct {
+	dir string
+
+	// mutex serializes reads and writes to the table files.
+	mutex sync.Mutex
+}
+
+// New returns a new *DB that stores all its tables in the given directory.
+// The directory is created if it doesn't already exist.
+func New(dir string) (*DB, error) {
+	if dir == "" {
+		return nil, skerr.Fmt("A directory must be supplied for a local database.")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, skerr.Wrapf(err, "Failed to create database directory %q", dir)
+	}
+	return &DB{
+		dir: dir,
+	}, nil
+}
+
+// Dir returns the directory the database is stored in.
+func (db *DB) Dir() string {
+	return db.dir
+}
+
+func (db *DB) filename(table string) string {
+	return filepath.Join(db.dir, table+fileExtension)
+}
+
+// Read decodes the named table into dst, which must be a pointer. If the table
+// has never been written then dst is left unchanged and no error is returned.
+func (db *DB) Read(table string, dst interface{}) error {
+	_, err := db.ReadVersion(table, dst)
+	return err
+}
+
+// ReadVersion is like Read, but also returns the Version of the table that
+// was read, which is zero if the table doesn't exist.
+func (db *DB) ReadVersion(table string, dst interface{}) (Version, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	f, err := os.Open(db.filename(table))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, skerr.Wrapf(err, "Failed to open table %q", table)
+	}
+	defer util.Close(f)
+	dec := gob.NewDecoder(f)
+	var version Version
+	if err := dec.Decode(&version); err != nil {
+		return 0, skerr.Wrapf(err, "Failed to decode version of table %q", table)
+	}
+	if err := dec.Decode(dst); err != nil {
+		return 0, skerr.Wrapf(err, "Failed to decode table %q", table)
+	}
+	return version, nil
+}
+
+// Version returns the current Version of the named table, which is zero if
+// the table doesn't exist. This only decodes the start of the table file, so
+// it is much cheaper than ReadVersion.
+func (db *DB) Version(table string) (Version, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	f, err := os.Open(db.filename(table))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, skerr.Wrapf(err, "Failed to open table %q", table)
+	}
+	defer util.Close(f)
+	var version Version
+	if err := gob.NewDecoder(f).Decode(&version); err != nil {
+		return 0, skerr.Wrapf(err, "Failed to decode version of table %q", table)
+	}
+	return version, nil
+}
+
+// Write atomically replaces the contents of the named table with src.
+func (db *DB) Write(table string, src interface{}) error {
+	_, err := db.WriteVersion(table, src)
+	return err
+}
+
+// WriteVersion is like Write, but also returns the new Version of the table.
+func (db *DB) WriteVersion(table string, src interface{}) (Version, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	version := newVersion()
+	err := util.WithWriteFile(db.filename(table), func(w io.Writer) error {
+		enc := gob.NewEncoder(w)
+		if err := enc.Encode(version); err != nil {
+			return err
+		}
+		return enc.Encode(src)
+	})
+	if err != nil {
+		return 0, skerr.Wrapf(err, "Failed to write table %q", table)
+	}
+	return version, nil
+}
+
+// Delete removes the named table. It is not an error to delete a table that
+// doesn't exist.
+func (db *DB) Delete(table string) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	if err := os.Remove(db.filename(table)); err != nil && !os.IsNotExist(err) {
+		return skerr.Wrapf(err, "Failed to delete table %q", table)
+	}
+	return nil
+}
+
+// Lock takes an exclusive lock on the database that is shared with every
+// other process, and every other call to Lock in this process, using the same
+// directory. It blocks until the lock is available and returns a func that
+// releases it. Lock is not reentrant.
+func (db *DB) Lock() (func(), error) {
+	f, err := os.OpenFile(filepath.Join(db.dir, lockFilename), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to open lock file.")
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		util.Close(f)
+		return nil, skerr.Wrapf(err, "Failed to lock database.")
+	}
+	// Closing the file releases the lock.
+	return func() { util.Close(f) }, nil
+}
+
+// Tables returns the sorted names of all the tables that begin with the given
+// prefix.
+func (db *DB) Tables(prefix string) ([]string, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	entries, err := os.ReadDir(db.dir)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to list tables.")
+	}
+	ret := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, fileExtension) {
+			continue
+		}
+		name = strings.TrimSuffix(name, fileExtension)
+		if strings.HasPrefix(name, prefix) {
+			ret = append(ret, name)
+		}
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
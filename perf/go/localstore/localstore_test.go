@@ -0,0 +1,136 @@
+package localstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testTable struct {
+	Values map[string]int
+}
+
+func TestReadWrite_RoundTrip_Success(t *testing.T) {
+	db, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	src := testTable{Values: map[string]int{"a": 1, "b": 2}}
+	require.NoError(t, db.Write("test", src))
+
+	var dst testTable
+	require.NoError(t, db.Read("test", &dst))
+	assert.Equal(t, src, dst)
+}
+
+func TestRead_TableDoesNotExist_DestinationUnchanged(t *testing.T) {
+	db, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	dst := testTable{Values: map[string]int{"a": 1}}
+	require.NoError(t, db.Read("unknown", &dst))
+	assert.Equal(t, testTable{Values: map[string]int{"a": 1}}, dst)
+}
+
+func TestTables_ListsTablesWithPrefix(t *testing.T) {
+	db, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, db.Write("tile-2", testTable{}))
+	require.NoError(t, db.Write("tile-1", testTable{}))
+	require.NoError(t, db.Write("other", testTable{}))
+
+	tables, err := db.Tables("tile-")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tile-1", "tile-2"}, tables)
+
+	require.NoError(t, db.Delete("tile-1"))
+	require.NoError(t, db.Delete("tile-1"))
+	tables, err = db.Tables("tile-")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tile-2"}, tables)
+}
+
+func TestNew_EmptyDirectory_ReturnsError(t *testing.T) {
+	_, err := New("")
+	require.Error(t, err)
+}
+
+func TestVersion_ChangesOnEveryWriteAndIsZeroForMissingTables(t *testing.T) {
+	db, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	version, err := db.Version("test")
+	require.NoError(t, err)
+	assert.Equal(t, Version(0), version)
+
+	written, err := db.WriteVersion("test", testTable{Values: map[string]int{"a": 1}})
+	require.NoError(t, err)
+	assert.NotEqual(t, Version(0), written)
+	version, err = db.Version("test")
+	require.NoError(t, err)
+	assert.Equal(t, written, version)
+
+	// A second DB on the same directory, as used by another process, sees the
+	// write and changes the version with its own writes.
+	other, err := New(db.Dir())
+	require.NoError(t, err)
+	var dst testTable
+	read, err := other.ReadVersion("test", &dst)
+	require.NoError(t, err)
+	assert.Equal(t, written, read)
+	assert.Equal(t, map[string]int{"a": 1}, dst.Values)
+
+	rewritten, err := other.WriteVersion("test", testTable{})
+	require.NoError(t, err)
+	assert.NotEqual(t, written, rewritten)
+	version, err = db.Version("test")
+	require.NoError(t, err)
+	assert.Equal(t, rewritten, version)
+
+	require.NoError(t, db.Delete("test"))
+	version, err = db.Version("test")
+	require.NoError(t, err)
+	assert.Equal(t, Version(0), version)
+}
+
+func TestLock_HeldByAnotherDB_BlocksUntilReleased(t *testing.T) {
+	db, err := New(t.TempDir())
+	require.NoError(t, err)
+	// Each DB opens its own lock file, just like another process would.
+	other, err := New(db.Dir())
+	require.NoError(t, err)
+
+	unlock, err := db.Lock()
+	require.NoError(t, err)
+
+	locked := make(chan struct{})
+	go func() {
+		otherUnlock, err := other.Lock()
+		assert.NoError(t, err)
+		close(locked)
+		otherUnlock()
+	}()
+
+	select {
+	case <-locked:
+		require.Fail(t, "Lock should block while the lock is held.")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	<-locked
+}
+
+func TestTables_LockFileIsNotATable(t *testing.T) {
+	db, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	unlock, err := db.Lock()
+	require.NoError(t, err)
+	unlock()
+
+	tables, err := db.Tables("")
+	require.NoError(t, err)
+	assert.Empty(t, tables)
+}
@@ -6,11 +6,18 @@ import (
 	"time"
 
 	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sql/pool"
 	"go.skia.org/infra/perf/go/builders"
 	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/noise"
+	"go.skia.org/infra/perf/go/notify"
 	"go.skia.org/infra/perf/go/redis"
 	"go.skia.org/infra/perf/go/regression/migration"
+	"go.skia.org/infra/perf/go/sheriffconfig/service"
+	"go.skia.org/infra/perf/go/sheriffconfig/source"
+	"go.skia.org/infra/perf/go/shortcutgc"
 	"go.skia.org/infra/perf/go/sql/expectedschema"
+	"go.skia.org/infra/perf/go/tracestore/retention"
 	"go.skia.org/infra/perf/go/tracing"
 )
 
@@ -25,6 +32,25 @@ const (
 	regressionMigrationBatchSize = 50
 
 	redisCacheRefreshPeriod = time.Minute * 30
+
+	// How often to apply the retention policy to the trace data.
+	retentionPeriod = time.Hour * 24
+
+	// How often to update the per-trace noise estimates if the instance
+	// config doesn't specify a period.
+	defaultNoiseUpdatePeriod = time.Hour * 24
+
+	// How often to check the sheriff config source for changes if the
+	// instance config doesn't specify a poll_period.
+	defaultSheriffConfigPollPeriod = time.Minute
+
+	// How often to look for unused shortcuts if the instance config doesn't
+	// specify a period.
+	defaultShortcutGCPeriod = time.Hour * 24
+
+	// How often to refresh the SLO metrics and check if the weekly summary
+	// email is due.
+	sloUpdatePeriod = time.Hour
 )
 
 // Start all the long running processes. This function does not return if all
@@ -34,14 +60,21 @@ func Start(ctx context.Context, flags config.MaintenanceFlags, instanceConfig *c
 		return skerr.Wrapf(err, "Start tracing.")
 	}
 
+	// The local datastore has no SQL schema to migrate.
+	isLocal := instanceConfig.DataStoreConfig.DataStoreType == config.LocalDataStoreType
+
 	// Migrate schema if needed.
-	db, err := builders.NewCockroachDBFromConfig(ctx, instanceConfig, false)
-	if err != nil {
-		return skerr.Wrapf(err, "Failed to create CockroachDB instance.")
-	}
-	err = expectedschema.ValidateAndMigrateNewSchema(ctx, db)
-	if err != nil {
-		return skerr.Wrapf(err, "Failed to migrate schema.")
+	var db pool.Pool
+	if !isLocal {
+		var err error
+		db, err = builders.NewCockroachDBFromConfig(ctx, instanceConfig, false)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to create CockroachDB instance.")
+		}
+		err = expectedschema.ValidateAndMigrateNewSchema(ctx, db)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to migrate schema.")
+		}
 	}
 
 	// New perfgit.Git.
@@ -54,7 +87,7 @@ func Start(ctx context.Context, flags config.MaintenanceFlags, instanceConfig *c
 	g.StartBackgroundPolling(ctx, gitRepoUpdatePeriod)
 
 	// Migrate regression schema if specified.
-	if flags.MigrateRegressions {
+	if flags.MigrateRegressions && !isLocal {
 		migrator, err := migration.New(ctx, db)
 		if err != nil {
 			return skerr.Wrapf(err, "Failed to build regression schema migrator.")
@@ -73,5 +106,99 @@ func Start(ctx context.Context, flags config.MaintenanceFlags, instanceConfig *c
 		}
 	}
 
+	if instanceConfig.RetentionConfig.Policy != config.NoRetentionPolicy {
+		traceStore, err := builders.NewTraceStoreFromConfig(ctx, flags.Local, instanceConfig)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build TraceStore.")
+		}
+		compactor, err := retention.New(traceStore, instanceConfig.RetentionConfig)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build retention compactor.")
+		}
+		go compactor.Start(ctx, retentionPeriod)
+	}
+
+	if instanceConfig.NoiseConfig.NumTiles > 0 {
+		traceStore, err := builders.NewTraceStoreFromConfig(ctx, flags.Local, instanceConfig)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build TraceStore.")
+		}
+		noiseStore, err := builders.NewNoiseStoreFromConfig(ctx, instanceConfig)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build NoiseStore.")
+		}
+		updater, err := noise.NewUpdater(traceStore, noiseStore, instanceConfig.NoiseConfig.NumTiles)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build noise updater.")
+		}
+		period := time.Duration(instanceConfig.NoiseConfig.Period)
+		if period <= 0 {
+			period = defaultNoiseUpdatePeriod
+		}
+		go updater.Start(ctx, period)
+	}
+
+	if instanceConfig.SheriffConfigSource.Enabled() {
+		subscriptionStore, err := builders.NewSubscriptionStoreFromConfig(ctx, instanceConfig)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build SubscriptionStore.")
+		}
+		alertStore, err := builders.NewAlertStoreFromConfig(ctx, flags.Local, instanceConfig)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build AlertStore.")
+		}
+		src, err := source.New(ctx, instanceConfig)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build sheriff config source.")
+		}
+		sheriffConfigService, err := service.New(ctx, db, subscriptionStore, alertStore, nil)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build sheriff config service.")
+		}
+		period := time.Duration(instanceConfig.SheriffConfigSource.PollPeriod)
+		if period <= 0 {
+			period = defaultSheriffConfigPollPeriod
+		}
+		go sheriffConfigService.WatchSource(ctx, src, period)
+	}
+
+	if instanceConfig.ShortcutGCConfig.MaxAge > 0 {
+		shortcutStore, err := builders.NewShortcutStoreFromConfig(ctx, flags.Local, instanceConfig)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build ShortcutStore.")
+		}
+		graphsShortcutStore, err := builders.NewGraphsShortcutStoreFromConfig(ctx, flags.Local, instanceConfig)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build GraphsShortcutStore.")
+		}
+		favoriteStore, err := builders.NewFavoriteStoreFromConfig(ctx, instanceConfig)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build FavoriteStore.")
+		}
+		// The sweeper only looks up the shortcuts of stored regressions, which
+		// doesn't need the alert configs.
+		regressionStore, err := builders.NewRegressionStoreFromConfig(ctx, flags.Local, instanceConfig, nil)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build RegressionStore.")
+		}
+		sweeper, err := shortcutgc.New(shortcutStore, graphsShortcutStore, favoriteStore, regressionStore, time.Duration(instanceConfig.ShortcutGCConfig.MaxAge))
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build shortcut sweeper.")
+		}
+		period := time.Duration(instanceConfig.ShortcutGCConfig.Period)
+		if period <= 0 {
+			period = defaultShortcutGCPeriod
+		}
+		go sweeper.Start(ctx, period)
+	}
+
+	if instanceConfig.SLOConfig != nil && !isLocal {
+		reporter, err := builders.NewSLOReporterFromConfig(ctx, instanceConfig, notify.NewEmailTransport())
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to build SLO reporter.")
+		}
+		go reporter.Start(ctx, sloUpdatePeriod)
+	}
+
 	select {}
 }
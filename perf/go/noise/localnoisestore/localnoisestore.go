@@ -0,0 +1,64 @@
+// Package localnoisestore implements noise.Store using a localstore.DB.
+package localnoisestore
+
+import (
+	"context"
+	"sync"
+
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/noise"
+)
+
+// noiseTable is the name of the table of noise estimates in the
+// localstore.DB.
+const noiseTable = "tracenoise"
+
+// LocalNoiseStore implements the noise.Store interface.
+type LocalNoiseStore struct {
+	db *localstore.DB
+
+	// mutex protects estimates.
+	mutex     sync.Mutex
+	estimates map[string]float32
+}
+
+// New returns a new *LocalNoiseStore.
+func New(db *localstore.DB) (*LocalNoiseStore, error) {
+	ret := &LocalNoiseStore{
+		db:        db,
+		estimates: map[string]float32{},
+	}
+	if err := db.Read(noiseTable, &ret.estimates); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return ret, nil
+}
+
+// Get implements the noise.Store interface.
+func (s *LocalNoiseStore) Get(ctx context.Context, traceNames []string) (map[string]float32, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ret := map[string]float32{}
+	for _, traceName := range traceNames {
+		if noise, ok := s.estimates[traceName]; ok {
+			ret[traceName] = noise
+		}
+	}
+	return ret, nil
+}
+
+// Write implements the noise.Store interface.
+func (s *LocalNoiseStore) Write(ctx context.Context, estimates map[string]float32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for traceName, noise := range estimates {
+		s.estimates[traceName] = noise
+	}
+	return skerr.Wrap(s.db.Write(noiseTable, s.estimates))
+}
+
+// Confirm that *LocalNoiseStore implements noise.Store.
+var _ noise.Store = (*LocalNoiseStore)(nil)
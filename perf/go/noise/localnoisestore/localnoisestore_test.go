@@ -0,0 +1,48 @@
+package localnoisestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/localstore"
+)
+
+func setUp(t *testing.T) (*LocalNoiseStore, *localstore.DB) {
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	store, err := New(db)
+	require.NoError(t, err)
+	return store, db
+}
+
+func TestStore_WriteGet(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setUp(t)
+
+	require.NoError(t, store.Write(ctx, map[string]float32{
+		",arch=x86,": 0.5,
+		",arch=arm,": 2,
+	}))
+	require.NoError(t, store.Write(ctx, map[string]float32{",arch=x86,": 1.5}))
+
+	estimates, err := store.Get(ctx, []string{",arch=x86,", ",arch=arm,", ",arch=riscv,"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float32{
+		",arch=x86,": 1.5,
+		",arch=arm,": 2,
+	}, estimates)
+}
+
+func TestNew_EstimatesArePersisted(t *testing.T) {
+	ctx := context.Background()
+	store, db := setUp(t)
+	require.NoError(t, store.Write(ctx, map[string]float32{",arch=x86,": 0.5}))
+
+	reopened, err := New(db)
+	require.NoError(t, err)
+	estimates, err := reopened.Get(ctx, []string{",arch=x86,"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float32{",arch=x86,": 0.5}, estimates)
+}
@@ -0,0 +1,19 @@
+// Package noise keeps an estimate of the noise in each trace, so that
+// regressions can be detected relative to each trace's own noise, see
+// types.NoiseStep.
+package noise
+
+import (
+	"context"
+)
+
+// Store persists the noise estimates of traces.
+type Store interface {
+	// Get returns the noise estimates for the given trace names. Traces that
+	// don't have an estimate are not present in the returned map.
+	Get(ctx context.Context, traceNames []string) (map[string]float32, error)
+
+	// Write stores the given noise estimates, keyed by trace name, replacing
+	// any previous estimates for the same traces.
+	Write(ctx context.Context, estimates map[string]float32) error
+}
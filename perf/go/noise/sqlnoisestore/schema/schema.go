@@ -0,0 +1,14 @@
+package schema
+
+import "time"
+
+// TraceNoiseSchema represents the SQL schema of the TraceNoise table.
+type TraceNoiseSchema struct {
+	// The name of the trace, e.g. ",arch=x86,config=8888,".
+	TraceName string `sql:"trace_name TEXT PRIMARY KEY"`
+
+	// The estimated standard deviation of the noise in the trace.
+	Noise float32 `sql:"noise REAL"`
+
+	LastUpdated time.Time `sql:"last_updated TIMESTAMPTZ DEFAULT now()"`
+}
@@ -0,0 +1,117 @@
+// Package sqlnoisestore implements noise.Store using SQL.
+//
+// Please see perf/go/sql/schema.go for the database schema used.
+package sqlnoisestore
+
+import (
+	"context"
+	"time"
+
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sql/pool"
+	"go.skia.org/infra/go/sql/sqlutil"
+	"go.skia.org/infra/perf/go/noise"
+)
+
+// statement is an SQL statement identifier.
+type statement int
+
+const (
+	// The identifiers for all the SQL statements used.
+	getNoise statement = iota
+	upsertNoise
+)
+
+// statements holds all the raw SQL statements used.
+var statements = map[statement]string{
+	getNoise: `
+		SELECT
+			trace_name, noise
+		FROM
+			TraceNoise
+		WHERE
+			trace_name = ANY($1)
+		`,
+	upsertNoise: `
+		UPSERT INTO
+			TraceNoise (trace_name, noise, last_updated)
+		VALUES
+		`,
+}
+
+const (
+	// upsertNoiseColsPerRow is the number of columns in the upsertNoise
+	// statement.
+	upsertNoiseColsPerRow = 3
+
+	// writeBatchSize is the number of rows written by a single statement.
+	writeBatchSize = 1000
+)
+
+// SQLNoiseStore implements the noise.Store interface.
+type SQLNoiseStore struct {
+	// db is the database interface.
+	db pool.Pool
+}
+
+// New returns a new *SQLNoiseStore.
+//
+// We presume all migrations have been run against db before this function is
+// called.
+func New(db pool.Pool) (*SQLNoiseStore, error) {
+	return &SQLNoiseStore{
+		db: db,
+	}, nil
+}
+
+// Get implements the noise.Store interface.
+func (s *SQLNoiseStore) Get(ctx context.Context, traceNames []string) (map[string]float32, error) {
+	ret := map[string]float32{}
+	if len(traceNames) == 0 {
+		return ret, nil
+	}
+	rows, err := s.db.Query(ctx, statements[getNoise], traceNames)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to load noise estimates")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var traceName string
+		var noise float32
+		if err := rows.Scan(&traceName, &noise); err != nil {
+			return nil, skerr.Wrapf(err, "Failed to read noise estimate")
+		}
+		ret[traceName] = noise
+	}
+	return ret, skerr.Wrap(rows.Err())
+}
+
+// Write implements the noise.Store interface.
+func (s *SQLNoiseStore) Write(ctx context.Context, estimates map[string]float32) error {
+	now := time.Now()
+	arguments := make([]interface{}, 0, upsertNoiseColsPerRow*writeBatchSize)
+	flush := func() error {
+		numRows := len(arguments) / upsertNoiseColsPerRow
+		if numRows == 0 {
+			return nil
+		}
+		statement := statements[upsertNoise] + sqlutil.ValuesPlaceholders(upsertNoiseColsPerRow, numRows)
+		if _, err := s.db.Exec(ctx, statement, arguments...); err != nil {
+			return skerr.Wrapf(err, "Failed to write noise estimates")
+		}
+		arguments = arguments[:0]
+		return nil
+	}
+	for traceName, noise := range estimates {
+		arguments = append(arguments, traceName, noise, now)
+		if len(arguments) == upsertNoiseColsPerRow*writeBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// Confirm that *SQLNoiseStore implements noise.Store.
+var _ noise.Store = (*SQLNoiseStore)(nil)
@@ -0,0 +1,52 @@
+package sqlnoisestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/sql/sqltest"
+)
+
+func setUp(t *testing.T) *SQLNoiseStore {
+	db := sqltest.NewCockroachDBForTests(t, "noisestore")
+	store, err := New(db)
+	require.NoError(t, err)
+	return store
+}
+
+func TestWrite_NewEstimates_EstimatesAreReturnedByGet(t *testing.T) {
+	ctx := context.Background()
+	store := setUp(t)
+
+	require.NoError(t, store.Write(ctx, map[string]float32{
+		",arch=x86,": 0.5,
+		",arch=arm,": 2,
+	}))
+
+	estimates, err := store.Get(ctx, []string{",arch=x86,", ",arch=arm,", ",arch=riscv,"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float32{
+		",arch=x86,": 0.5,
+		",arch=arm,": 2,
+	}, estimates)
+}
+
+func TestWrite_ExistingEstimate_EstimateIsReplaced(t *testing.T) {
+	ctx := context.Background()
+	store := setUp(t)
+
+	require.NoError(t, store.Write(ctx, map[string]float32{",arch=x86,": 0.5}))
+	require.NoError(t, store.Write(ctx, map[string]float32{",arch=x86,": 1.5}))
+
+	estimates, err := store.Get(ctx, []string{",arch=x86,"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float32{",arch=x86,": 1.5}, estimates)
+}
+
+func TestGet_NoTraceNames_ReturnsEmpty(t *testing.T) {
+	estimates, err := setUp(t).Get(context.Background(), []string{})
+	require.NoError(t, err)
+	assert.Empty(t, estimates)
+}
@@ -0,0 +1,115 @@
+package noise
+
+import (
+	"context"
+	"time"
+
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/perf/go/stepfit"
+	"go.skia.org/infra/perf/go/tracestore"
+	"go.skia.org/infra/perf/go/types"
+)
+
+const (
+	// updateTimeout is the longest a single periodic update may run.
+	updateTimeout = 6 * time.Hour
+
+	// batchSize is the number of traces read and written at a time.
+	batchSize = 1000
+)
+
+// Updater estimates the noise of every trace over the most recent tiles of
+// a TraceStore and writes the estimates to a Store.
+type Updater struct {
+	traceStore tracestore.TraceStore
+	store      Store
+
+	// numTiles is the number of most recent tiles the noise is estimated
+	// over.
+	numTiles int
+
+	tracesUpdated metrics2.Counter
+	failures      metrics2.Counter
+}
+
+// NewUpdater returns a new *Updater that estimates the noise of each trace
+// over the numTiles most recent tiles.
+func NewUpdater(traceStore tracestore.TraceStore, store Store, numTiles int) (*Updater, error) {
+	if numTiles < 1 {
+		return nil, skerr.Fmt("At least one tile must be used to estimate noise, got num_tiles=%d", numTiles)
+	}
+	return &Updater{
+		traceStore:    traceStore,
+		store:         store,
+		numTiles:      numTiles,
+		tracesUpdated: metrics2.GetCounter("perf_noise_traces_updated"),
+		failures:      metrics2.GetCounter("perf_noise_update_failures"),
+	}, nil
+}
+
+// Update estimates the noise of every trace in the latest tile over the
+// most recent tiles and writes the estimates to the Store. Traces that don't
+// have enough data points to estimate their noise are skipped. Returns the
+// number of traces whose estimate was written.
+func (u *Updater) Update(ctx context.Context) (int, error) {
+	latest, err := u.traceStore.GetLatestTile(ctx)
+	if err != nil {
+		return 0, skerr.Wrapf(err, "Failed to find the latest tile")
+	}
+	oldest := latest - types.TileNumber(u.numTiles-1)
+	if oldest < 0 {
+		oldest = 0
+	}
+	begin := types.CommitNumber(int32(oldest) * u.traceStore.TileSize())
+	end := types.CommitNumber(int32(latest+1)*u.traceStore.TileSize() - 1)
+
+	traceNames, err := u.traceStore.GetTraceIDs(ctx, latest)
+	if err != nil {
+		return 0, skerr.Wrapf(err, "Failed to list the traces in tile %d", latest)
+	}
+	updated := 0
+	for i := 0; i < len(traceNames); i += batchSize {
+		batch := traceNames[i:]
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		ts, _, err := u.traceStore.ReadTracesForCommitRange(ctx, batch, begin, end)
+		if err != nil {
+			return updated, skerr.Wrapf(err, "Failed to read traces for commits [%d, %d]", begin, end)
+		}
+		estimates := make(map[string]float32, len(ts))
+		for traceName, trace := range ts {
+			if noise := stepfit.NoiseEstimate(trace); noise > 0 {
+				estimates[traceName] = noise
+			}
+		}
+		if len(estimates) == 0 {
+			continue
+		}
+		if err := u.store.Write(ctx, estimates); err != nil {
+			return updated, skerr.Wrapf(err, "Failed to write noise estimates")
+		}
+		updated += len(estimates)
+		u.tracesUpdated.Inc(int64(len(estimates)))
+	}
+	return updated, nil
+}
+
+// Start updates the noise estimates, and then does so again every period,
+// until the context is cancelled.
+func (u *Updater) Start(ctx context.Context, period time.Duration) {
+	util.RepeatCtx(ctx, period, func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+		defer cancel()
+		updated, err := u.Update(ctx)
+		if err != nil {
+			sklog.Errorf("Failed to update noise estimates: %s", err)
+			u.failures.Inc(1)
+			return
+		}
+		sklog.Infof("Updated the noise estimates of %d traces.", updated)
+	})
+}
@@ -0,0 +1,87 @@
+package noise
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/vec32"
+	"go.skia.org/infra/perf/go/tracestore/mocks"
+	"go.skia.org/infra/perf/go/types"
+)
+
+const (
+	x = vec32.MissingDataSentinel
+
+	quietTrace = ",arch=x86,config=8888,"
+	noisyTrace = ",arch=arm,config=8888,"
+	emptyTrace = ",arch=arm,config=565,"
+)
+
+// mapStore is a Store that keeps the estimates in memory.
+type mapStore map[string]float32
+
+func (m mapStore) Get(ctx context.Context, traceNames []string) (map[string]float32, error) {
+	ret := map[string]float32{}
+	for _, traceName := range traceNames {
+		if noise, ok := m[traceName]; ok {
+			ret[traceName] = noise
+		}
+	}
+	return ret, nil
+}
+
+func (m mapStore) Write(ctx context.Context, estimates map[string]float32) error {
+	for traceName, noise := range estimates {
+		m[traceName] = noise
+	}
+	return nil
+}
+
+func TestNewUpdater_NoTiles_ReturnsError(t *testing.T) {
+	_, err := NewUpdater(mocks.NewTraceStore(t), mapStore{}, 0)
+	require.Error(t, err)
+}
+
+func TestUpdate_EstimatesNoiseOverMostRecentTiles(t *testing.T) {
+	ctx := context.Background()
+	traceStore := mocks.NewTraceStore(t)
+	traceStore.On("GetLatestTile", ctx).Return(types.TileNumber(3), nil)
+	traceStore.On("TileSize").Return(int32(4))
+	traceStore.On("GetTraceIDs", ctx, types.TileNumber(3)).Return([]string{emptyTrace, noisyTrace, quietTrace}, nil)
+	// Two tiles of four commits each, i.e. commits [8, 15].
+	traceStore.On("ReadTracesForCommitRange", ctx, []string{emptyTrace, noisyTrace, quietTrace}, types.CommitNumber(8), types.CommitNumber(15)).Return(types.TraceSet{
+		quietTrace: {1, 1.1, 1, 1.1, 1, 1.1, x, x},
+		noisyTrace: {1, 2, 1, 2, 1, 2, x, x},
+		emptyTrace: {x, x, x, x, x, 1, x, x},
+	}, nil, nil)
+	store := mapStore{}
+	u, err := NewUpdater(traceStore, store, 2)
+	require.NoError(t, err)
+
+	updated, err := u.Update(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+	require.Len(t, store, 2)
+	assert.InDelta(t, 0.1*1.0483, store[quietTrace], 0.001)
+	assert.InDelta(t, 1.0483, store[noisyTrace], 0.001)
+}
+
+func TestUpdate_FewerTilesThanNumTiles_StartsAtFirstTile(t *testing.T) {
+	ctx := context.Background()
+	traceStore := mocks.NewTraceStore(t)
+	traceStore.On("GetLatestTile", ctx).Return(types.TileNumber(0), nil)
+	traceStore.On("TileSize").Return(int32(4))
+	traceStore.On("GetTraceIDs", ctx, types.TileNumber(0)).Return([]string{",arch=x86,"}, nil)
+	traceStore.On("ReadTracesForCommitRange", ctx, []string{",arch=x86,"}, types.CommitNumber(0), types.CommitNumber(3)).Return(types.TraceSet{
+		",arch=x86,": {1, 2, 1, 2},
+	}, nil, nil)
+	store := mapStore{}
+	u, err := NewUpdater(traceStore, store, 10)
+	require.NoError(t, err)
+
+	updated, err := u.Update(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+}
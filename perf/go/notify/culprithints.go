@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/types"
+)
+
+// maxCulpritHints is the maximum number of culprit hints added to a
+// notification.
+const maxCulpritHints = 10
+
+// PathChangeFinder finds the commits that changed a path and the files a
+// commit changed. It is implemented by perfgit.Git.
+type PathChangeFinder interface {
+	CommitNumbersWhenFileChangesInCommitNumberRange(ctx context.Context, begin, end types.CommitNumber, filename string) ([]types.CommitNumber, error)
+	CommitSliceFromCommitNumberRange(ctx context.Context, begin, end types.CommitNumber) ([]provider.Commit, error)
+	FilesChangedInCommit(ctx context.Context, commitNumber types.CommitNumber) ([]string, error)
+	CommitFromCommitNumber(ctx context.Context, commitNumber types.CommitNumber) (provider.Commit, error)
+}
+
+// CulpritHint is a commit in the range of a regression that touched at least
+// one of the paths in Alert.CulpritPaths.
+type CulpritHint struct {
+	Commit provider.Commit
+
+	// Paths are the globs from Alert.CulpritPaths that the commit touched, in
+	// the order they appear in the alert.
+	Paths []string
+}
+
+// culpritHints returns the commits in (previousCommit, commit] that touched
+// any of the alert's CulpritPaths. Commits that touched more of the paths are
+// ranked first, and ties go to the most recent commit. At most maxCulpritHints
+// are returned.
+//
+// The hints only add detail to a notification, so errors are logged and not
+// returned.
+func culpritHints(ctx context.Context, finder PathChangeFinder, alert *alerts.Alert, commit, previousCommit provider.Commit) []CulpritHint {
+	if finder == nil || alert == nil || previousCommit.CommitNumber >= commit.CommitNumber {
+		return nil
+	}
+	globs := alert.CulpritPathGlobs()
+	if len(globs) == 0 {
+		return nil
+	}
+
+	// Gitiles can't match globs, and git pathspecs match them differently
+	// than path.Match, so the repo is only asked for the commits that changed
+	// the literal directory prefix of each glob, and the globs are then
+	// matched against the files each of those commits changed.
+	paths := map[types.CommitNumber][]string{}
+	for _, commitNumber := range candidateCommits(ctx, finder, globs, previousCommit.CommitNumber, commit.CommitNumber) {
+		files, err := finder.FilesChangedInCommit(ctx, commitNumber)
+		if err != nil {
+			sklog.Warningf("Failed to find the files changed by commit %d: %s", commitNumber, err)
+			continue
+		}
+		for _, glob := range globs {
+			for _, file := range files {
+				if matchPathGlob(glob, file) {
+					paths[commitNumber] = append(paths[commitNumber], glob)
+					break
+				}
+			}
+		}
+	}
+
+	ranked := make([]types.CommitNumber, 0, len(paths))
+	for commitNumber := range paths {
+		ranked = append(ranked, commitNumber)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if len(paths[ranked[i]]) != len(paths[ranked[j]]) {
+			return len(paths[ranked[i]]) > len(paths[ranked[j]])
+		}
+		return ranked[i] > ranked[j]
+	})
+	if len(ranked) > maxCulpritHints {
+		ranked = ranked[:maxCulpritHints]
+	}
+
+	var ret []CulpritHint
+	for _, commitNumber := range ranked {
+		c, err := finder.CommitFromCommitNumber(ctx, commitNumber)
+		if err != nil {
+			sklog.Warningf("Failed to load commit %d: %s", commitNumber, err)
+			continue
+		}
+		ret = append(ret, CulpritHint{
+			Commit: c,
+			Paths:  paths[commitNumber],
+		})
+	}
+	return ret
+}
+
+// candidateCommits returns the commits in (previousCommit, commit] that changed
+// the literal directory prefix of any of the globs.
+func candidateCommits(ctx context.Context, finder PathChangeFinder, globs []string, previousCommit, commit types.CommitNumber) []types.CommitNumber {
+	// Both finder methods are inclusive of begin.
+	begin := previousCommit + 1
+	candidates := map[types.CommitNumber]bool{}
+	for _, glob := range globs {
+		prefix := literalPrefix(glob)
+		if prefix == "" {
+			// The glob can match files anywhere in the repo, so every commit
+			// in the range is a candidate.
+			commits, err := finder.CommitSliceFromCommitNumberRange(ctx, begin, commit)
+			if err != nil {
+				sklog.Warningf("Failed to load commits in (%d, %d]: %s", previousCommit, commit, err)
+				continue
+			}
+			for _, c := range commits {
+				candidates[c.CommitNumber] = true
+			}
+			continue
+		}
+		commitNumbers, err := finder.CommitNumbersWhenFileChangesInCommitNumberRange(ctx, begin, commit, prefix)
+		if err != nil {
+			sklog.Warningf("Failed to find commits that changed %q in (%d, %d]: %s", prefix, previousCommit, commit, err)
+			continue
+		}
+		for _, commitNumber := range commitNumbers {
+			candidates[commitNumber] = true
+		}
+	}
+	ret := make([]types.CommitNumber, 0, len(candidates))
+	for commitNumber := range candidates {
+		ret = append(ret, commitNumber)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret
+}
+
+// literalPrefix returns the leading path elements of the glob that don't
+// contain any special characters, e.g. "src/core" for "src/core/**/*.cpp", or
+// the empty string if the first element is a pattern.
+func literalPrefix(glob string) string {
+	var literal []string
+	for _, element := range strings.Split(glob, "/") {
+		if strings.ContainsAny(element, `*?[\`) {
+			break
+		}
+		literal = append(literal, element)
+	}
+	return strings.Join(literal, "/")
+}
+
+// matchPathGlob returns true if the glob matches the file or one of its parent
+// directories. Each path element of the glob is matched against a path
+// element of the file with path.Match, except for "**" which matches any
+// number of path elements.
+func matchPathGlob(glob, file string) bool {
+	return matchPathElements(strings.Split(glob, "/"), strings.Split(file, "/"))
+}
+
+func matchPathElements(glob, file []string) bool {
+	if len(glob) == 0 {
+		// The glob matched the file or one of its parent directories.
+		return true
+	}
+	if glob[0] == "**" {
+		for i := 0; i <= len(file); i++ {
+			if matchPathElements(glob[1:], file[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(file) == 0 {
+		return false
+	}
+	if matched, err := path.Match(glob[0], file[0]); err != nil || !matched {
+		return false
+	}
+	return matchPathElements(glob[1:], file[1:])
+}
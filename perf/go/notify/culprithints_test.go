@@ -0,0 +1,201 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/types"
+)
+
+// fakePathFinder is a repo where each commit changed the given files.
+type fakePathFinder struct {
+	// files maps commit numbers to the files changed by that commit.
+	files map[types.CommitNumber][]string
+	err   error
+
+	// prefixes are the paths passed to
+	// CommitNumbersWhenFileChangesInCommitNumberRange.
+	prefixes []string
+
+	// begin and end are the range of the last call to
+	// CommitNumbersWhenFileChangesInCommitNumberRange or
+	// CommitSliceFromCommitNumberRange.
+	begin, end types.CommitNumber
+}
+
+func (f *fakePathFinder) CommitNumbersWhenFileChangesInCommitNumberRange(ctx context.Context, begin, end types.CommitNumber, filename string) ([]types.CommitNumber, error) {
+	f.begin, f.end = begin, end
+	f.prefixes = append(f.prefixes, filename)
+	var ret []types.CommitNumber
+	for commitNumber, files := range f.files {
+		if commitNumber < begin || commitNumber > end {
+			continue
+		}
+		for _, file := range files {
+			if file == filename || strings.HasPrefix(file, filename+"/") {
+				ret = append(ret, commitNumber)
+				break
+			}
+		}
+	}
+	return ret, f.err
+}
+
+func (f *fakePathFinder) CommitSliceFromCommitNumberRange(ctx context.Context, begin, end types.CommitNumber) ([]provider.Commit, error) {
+	f.begin, f.end = begin, end
+	var ret []provider.Commit
+	for commitNumber := range f.files {
+		if commitNumber >= begin && commitNumber <= end {
+			ret = append(ret, provider.Commit{CommitNumber: commitNumber})
+		}
+	}
+	return ret, f.err
+}
+
+func (f *fakePathFinder) FilesChangedInCommit(ctx context.Context, commitNumber types.CommitNumber) ([]string, error) {
+	return f.files[commitNumber], f.err
+}
+
+func (f *fakePathFinder) CommitFromCommitNumber(ctx context.Context, commitNumber types.CommitNumber) (provider.Commit, error) {
+	return provider.Commit{
+		CommitNumber: commitNumber,
+		Subject:      fmt.Sprintf("Commit %d", commitNumber),
+		URL:          fmt.Sprintf("https://example.com/+show/%d", commitNumber),
+	}, nil
+}
+
+func alertWithCulpritPaths(paths string) *alerts.Alert {
+	a := *alertForTest
+	a.CulpritPaths = paths
+	return &a
+}
+
+func TestCulpritHints_NoCulpritPaths_ReturnsNil(t *testing.T) {
+	finder := &fakePathFinder{files: map[types.CommitNumber][]string{11: {"src/foo.cpp"}}}
+	require.Nil(t, culpritHints(context.Background(), finder, alertForTest, commitWithNumber, previousCommitWithNumber))
+}
+
+func TestCulpritHints_NilFinder_ReturnsNil(t *testing.T) {
+	require.Nil(t, culpritHints(context.Background(), nil, alertWithCulpritPaths("src/**"), commitWithNumber, previousCommitWithNumber))
+}
+
+func TestCulpritHints_FinderFails_ReturnsNil(t *testing.T) {
+	finder := &fakePathFinder{files: map[types.CommitNumber][]string{11: {"src/foo.cpp"}}, err: errMock}
+	require.Nil(t, culpritHints(context.Background(), finder, alertWithCulpritPaths("src/**"), commitWithNumber, previousCommitWithNumber))
+}
+
+func TestCulpritHints_MultiplePaths_RankedByPathsMatchedThenRecency(t *testing.T) {
+	finder := &fakePathFinder{
+		files: map[types.CommitNumber][]string{
+			10: {"src/core/SkFoo.cpp"},
+			11: {"src/core/SkFoo.cpp", "include/core/SkFoo.h"},
+			12: {"src/core/effects/SkBar.cpp", "gn/BUILD.gn"},
+		},
+	}
+	hints := culpritHints(context.Background(), finder, alertWithCulpritPaths("src/core/**,include/**/*.h,docs"), commitWithNumber, previousCommitWithNumber)
+
+	// The range (10, 12] is passed as [11, 12].
+	assert.Equal(t, types.CommitNumber(11), finder.begin)
+	assert.Equal(t, types.CommitNumber(12), finder.end)
+	// Only the literal prefix of each glob is passed to the repo.
+	assert.Equal(t, []string{"src/core", "include", "docs"}, finder.prefixes)
+	require.Len(t, hints, 2)
+	assert.Equal(t, types.CommitNumber(11), hints[0].Commit.CommitNumber)
+	assert.Equal(t, []string{"src/core/**", "include/**/*.h"}, hints[0].Paths)
+	assert.Equal(t, types.CommitNumber(12), hints[1].Commit.CommitNumber)
+	assert.Equal(t, []string{"src/core/**"}, hints[1].Paths)
+}
+
+func TestCulpritHints_GlobWithoutLiteralPrefix_AllCommitsInRangeAreMatched(t *testing.T) {
+	finder := &fakePathFinder{
+		files: map[types.CommitNumber][]string{
+			11: {"src/core/BUILD.gn"},
+			12: {"src/core/SkFoo.cpp"},
+		},
+	}
+	hints := culpritHints(context.Background(), finder, alertWithCulpritPaths("**/*.gn"), commitWithNumber, previousCommitWithNumber)
+
+	assert.Empty(t, finder.prefixes)
+	require.Len(t, hints, 1)
+	assert.Equal(t, types.CommitNumber(11), hints[0].Commit.CommitNumber)
+}
+
+func TestCulpritHints_TooManyCommits_Truncated(t *testing.T) {
+	files := map[types.CommitNumber][]string{}
+	for i := types.CommitNumber(0); i < 20; i++ {
+		files[i] = []string{"src/foo.cpp"}
+	}
+	finder := &fakePathFinder{files: files}
+	commit := provider.Commit{CommitNumber: 30}
+	hints := culpritHints(context.Background(), finder, alertWithCulpritPaths("src/**"), commit, provider.Commit{CommitNumber: 0})
+	require.Len(t, hints, maxCulpritHints)
+	assert.Equal(t, types.CommitNumber(19), hints[0].Commit.CommitNumber)
+}
+
+func TestLiteralPrefix(t *testing.T) {
+	assert.Equal(t, "src/core", literalPrefix("src/core/**"))
+	assert.Equal(t, "include", literalPrefix("include/*.h"))
+	assert.Equal(t, "src/core/SkFoo.cpp", literalPrefix("src/core/SkFoo.cpp"))
+	assert.Equal(t, "src", literalPrefix("src/[ab]/foo"))
+	assert.Equal(t, "", literalPrefix("**/*.gn"))
+}
+
+func TestMatchPathGlob(t *testing.T) {
+	testCases := []struct {
+		glob     string
+		file     string
+		expected bool
+	}{
+		{"src/core/**", "src/core/SkFoo.cpp", true},
+		{"src/core/**", "src/core/effects/SkBar.cpp", true},
+		{"src/core/**", "src/corex/SkFoo.cpp", false},
+		{"src/core", "src/core/effects/SkBar.cpp", true},
+		{"include/*.h", "include/SkFoo.h", true},
+		{"include/*.h", "include/core/SkFoo.h", false},
+		{"include/**/*.h", "include/SkFoo.h", true},
+		{"include/**/*.h", "include/core/SkFoo.h", true},
+		{"**/BUILD.gn", "BUILD.gn", true},
+		{"**/BUILD.gn", "src/core/BUILD.gn", true},
+		{"src/?.cpp", "src/a.cpp", true},
+		{"src/[ab].cpp", "src/c.cpp", false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, matchPathGlob(tc.glob, tc.file), "%s %s", tc.glob, tc.file)
+	}
+}
+
+func TestMarkdownFormatter_WithCulpritHints_HintsAreAppendedToBody(t *testing.T) {
+	f, err := NewMarkdownFormatter("", &config.NotifyConfig{})
+	require.NoError(t, err)
+	f.pathFinder = &fakePathFinder{files: map[types.CommitNumber][]string{11: {"src/foo.cpp", "include/foo.h"}}}
+
+	body, _, err := f.FormatNewRegression(context.Background(), commitWithNumber, previousCommitWithNumber, alertWithCulpritPaths("src/**,include/*.h"), cl, instanceURL, frameResponse)
+	require.NoError(t, err)
+	require.Contains(t, body, "\n\nCommits that touched the culprit paths:\n  - [Commit 11](https://example.com/+show/11) (src/**, include/*.h)\n")
+}
+
+func TestMarkdownFormatter_NoCulpritHints_BodyIsUnchanged(t *testing.T) {
+	f, err := NewMarkdownFormatter("", &config.NotifyConfig{})
+	require.NoError(t, err)
+	f.pathFinder = &fakePathFinder{}
+
+	body, _, err := f.FormatNewRegression(context.Background(), commitWithNumber, previousCommitWithNumber, alertWithCulpritPaths("src/**"), cl, instanceURL, frameResponse)
+	require.NoError(t, err)
+	require.NotContains(t, body, "culprit paths")
+}
+
+func TestHTMLFormatter_WithCulpritHints_HintsAreAppendedToBody(t *testing.T) {
+	f := HTMLFormatter{pathFinder: &fakePathFinder{files: map[types.CommitNumber][]string{12: {"src/foo.cpp"}}}}
+
+	body, _, err := f.FormatNewRegression(context.Background(), commitWithNumber, previousCommitWithNumber, alertWithCulpritPaths("src/**"), cl, instanceURL, frameResponse)
+	require.NoError(t, err)
+	require.Contains(t, body, "Commits that touched the culprit paths:")
+	require.Contains(t, body, `<li><a href="https://example.com/&#43;show/12">Commit 12</a> (src/**)</li>`)
+}
@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"context"
+
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/types"
+)
+
+// DependencyRoller finds the dependency repo commits that were rolled in by a
+// range of commits. It is implemented by perfgit.Git.
+type DependencyRoller interface {
+	DependencyRolls(ctx context.Context, begin, end types.CommitNumber) ([]provider.DependencyRoll, error)
+}
+
+// dependencyRolls returns the dependency repo commits rolled in by the commits
+// in (previousCommit, commit]. The rolls only add detail to a notification, so
+// errors are logged and not returned.
+func dependencyRolls(ctx context.Context, roller DependencyRoller, commit, previousCommit provider.Commit) []provider.DependencyRoll {
+	if roller == nil || previousCommit.CommitNumber >= commit.CommitNumber {
+		return nil
+	}
+	rolls, err := roller.DependencyRolls(ctx, previousCommit.CommitNumber, commit.CommitNumber)
+	if err != nil {
+		sklog.Warningf("Failed to load dependency rolls for (%d, %d]: %s", previousCommit.CommitNumber, commit.CommitNumber, err)
+		return nil
+	}
+	return rolls
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/types"
+)
+
+type fakeRoller struct {
+	rolls []provider.DependencyRoll
+	err   error
+}
+
+func (f fakeRoller) DependencyRolls(ctx context.Context, begin, end types.CommitNumber) ([]provider.DependencyRoll, error) {
+	return f.rolls, f.err
+}
+
+var (
+	rollsForTest = []provider.DependencyRoll{
+		{
+			Name:  "engine",
+			Begin: "111",
+			End:   "333",
+			Commits: []provider.Commit{
+				{GitHash: "222", Subject: "Engine commit two", URL: "https://example.com/engine/+show/222"},
+				{GitHash: "333", Subject: "Engine commit three", URL: "https://example.com/engine/+show/333"},
+			},
+			Truncated: true,
+		},
+	}
+
+	commitWithNumber         = provider.Commit{CommitNumber: 12, GitHash: "d261e1075a93677442fdf7fe72aba7e583863664", Subject: "An example commit use for testing."}
+	previousCommitWithNumber = provider.Commit{CommitNumber: 10, GitHash: "fb49909acafba5e031b90a265a6ce059cda85019"}
+)
+
+func TestDependencyRolls_NilRoller_ReturnsNil(t *testing.T) {
+	require.Nil(t, dependencyRolls(context.Background(), nil, commitWithNumber, previousCommitWithNumber))
+}
+
+func TestDependencyRolls_EmptyRange_DoesNotCallRoller(t *testing.T) {
+	require.Nil(t, dependencyRolls(context.Background(), fakeRoller{rolls: rollsForTest}, commitWithNumber, commitWithNumber))
+}
+
+func TestDependencyRolls_RollerFails_ReturnsNil(t *testing.T) {
+	require.Nil(t, dependencyRolls(context.Background(), fakeRoller{err: errMock}, commitWithNumber, previousCommitWithNumber))
+}
+
+func TestMarkdownFormatter_WithDependencyRolls_RollsAreAppendedToBody(t *testing.T) {
+	f, err := NewMarkdownFormatter("", &config.NotifyConfig{})
+	require.NoError(t, err)
+	f.roller = fakeRoller{rolls: rollsForTest}
+
+	body, _, err := f.FormatNewRegression(context.Background(), commitWithNumber, previousCommitWithNumber, alertForTest, cl, instanceURL, frameResponse)
+	require.NoError(t, err)
+	require.Contains(t, body, "From Alert [MyAlert](https://perf.skia.org/a/?123)\n\nRolled in engine commits:\n  - [Engine commit two](https://example.com/engine/+show/222)\n  - [Engine commit three](https://example.com/engine/+show/333)\n  - Older commits are not shown.\n")
+}
+
+func TestMarkdownFormatter_NoDependencyRolls_BodyIsUnchanged(t *testing.T) {
+	f, err := NewMarkdownFormatter("", &config.NotifyConfig{})
+	require.NoError(t, err)
+	f.roller = fakeRoller{rolls: []provider.DependencyRoll{}}
+
+	body, _, err := f.FormatNewRegression(context.Background(), commitWithNumber, previousCommitWithNumber, alertForTest, cl, instanceURL, frameResponse)
+	require.NoError(t, err)
+	require.NotContains(t, body, "Rolled in")
+	require.Contains(t, body, "From Alert [MyAlert](https://perf.skia.org/a/?123)\n")
+}
+
+func TestHTMLFormatter_WithDependencyRolls_RollsAreAppendedToBody(t *testing.T) {
+	f := HTMLFormatter{roller: fakeRoller{rolls: rollsForTest}}
+
+	body, _, err := f.FormatNewRegression(context.Background(), commitWithNumber, previousCommitWithNumber, alertForTest, cl, instanceURL, frameResponse)
+	require.NoError(t, err)
+	require.Contains(t, body, "Rolled in engine commits:")
+	require.Contains(t, body, `<li><a href="https://example.com/engine/&#43;show/222">Engine commit two</a></li>`)
+	require.Contains(t, body, "Older engine commits are not shown.")
+}
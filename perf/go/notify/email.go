@@ -63,3 +63,16 @@ func (e EmailTransport) SendRegressionMissing(ctx context.Context, threadingRefe
 	}
 	return nil
 }
+
+// SendReport sends an HTML email, that isn't about a single regression, to
+// the given addresses.
+func (e EmailTransport) SendReport(ctx context.Context, to []string, subject, body string) error {
+	if len(to) == 0 {
+		return skerr.Fmt("No report sent. No email addresses given.")
+	}
+	_, err := e.client.SendWithMarkup("", fromAddress, to, subject, "", body, "")
+	if err != nil {
+		return skerr.Wrapf(err, "sending report by email")
+	}
+	return nil
+}
@@ -21,3 +21,9 @@ func TestEmailTransportSendRegressionMissing_EmailIsMissing_ReturnsError(t *test
 	require.Contains(t, err.Error(), "No email address")
 
 }
+
+func TestEmailTransportSendReport_NoAddresses_ReturnsError(t *testing.T) {
+	e := NewEmailTransport()
+	err := e.SendReport(context.Background(), nil, "", "")
+	require.Contains(t, err.Error(), "No email addresses")
+}
@@ -37,6 +37,31 @@ const (
 <p>
 	From Alert <a href="{{.URL}}/a/?{{ .Alert.IDAsString }}">{{ .Alert.DisplayName }}</a>
 </p>
+{{- range .DependencyRolls }}
+<p>
+	Rolled in {{ .Name }} commits:
+</p>
+<ul>
+{{- range .Commits }}
+	<li><a href="{{ .URL }}">{{ .Subject }}</a></li>
+{{- end }}
+</ul>
+{{- if .Truncated }}
+<p>
+	Older {{ .Name }} commits are not shown.
+</p>
+{{- end }}
+{{- end }}
+{{- if .CulpritHints }}
+<p>
+	Commits that touched the culprit paths:
+</p>
+<ul>
+{{- range .CulpritHints }}
+	<li><a href="{{ .Commit.URL }}">{{ .Commit.Subject }}</a> ({{ range $i, $p := .Paths }}{{ if $i }}, {{ end }}{{ $p }}{{ end }})</li>
+{{- end }}
+</ul>
+{{- end }}
 `
 	regressionMissingHTML = `<b>Alert</b><br><br>
 <p>
@@ -60,6 +85,31 @@ const (
 <p>
 	From Alert <a href="{{.URL}}/a/?{{ .Alert.IDAsString }}">{{ .Alert.DisplayName }}</a>
 </p>
+{{- range .DependencyRolls }}
+<p>
+	Rolled in {{ .Name }} commits:
+</p>
+<ul>
+{{- range .Commits }}
+	<li><a href="{{ .URL }}">{{ .Subject }}</a></li>
+{{- end }}
+</ul>
+{{- if .Truncated }}
+<p>
+	Older {{ .Name }} commits are not shown.
+</p>
+{{- end }}
+{{- end }}
+{{- if .CulpritHints }}
+<p>
+	Commits that touched the culprit paths:
+</p>
+<ul>
+{{- range .CulpritHints }}
+	<li><a href="{{ .Commit.URL }}">{{ .Commit.Subject }}</a> ({{ range $i, $p := .Paths }}{{ if $i }}, {{ end }}{{ $p }}{{ end }})</li>
+{{- end }}
+</ul>
+{{- end }}
 `
 )
 
@@ -71,6 +121,14 @@ var (
 // HTMLFormatter implements Formatter.
 type HTMLFormatter struct {
 	commitRangeURITemplate string
+
+	// roller is optional, and if set is used to populate
+	// TemplateContext.DependencyRolls.
+	roller DependencyRoller
+
+	// pathFinder is optional, and if set is used to populate
+	// TemplateContext.CulpritHints.
+	pathFinder PathChangeFinder
 }
 
 // NewHTMLFormatter returns a new HTMLFormatter.
@@ -88,6 +146,9 @@ func (h HTMLFormatter) FormatNewRegression(ctx context.Context, commit, previous
 		CommitURL: URLFromCommitRange(commit, previousCommit, h.commitRangeURITemplate),
 		Alert:     alert,
 		Cluster:   cl,
+
+		DependencyRolls: dependencyRolls(ctx, h.roller, commit, previousCommit),
+		CulpritHints:    culpritHints(ctx, h.pathFinder, alert, commit, previousCommit),
 	}
 
 	var b bytes.Buffer
@@ -107,6 +168,9 @@ func (h HTMLFormatter) FormatRegressionMissing(ctx context.Context, commit, prev
 		CommitURL: URLFromCommitRange(commit, previousCommit, h.commitRangeURITemplate),
 		Alert:     alert,
 		Cluster:   cl,
+
+		DependencyRolls: dependencyRolls(ctx, h.roller, commit, previousCommit),
+		CulpritHints:    culpritHints(ctx, h.pathFinder, alert, commit, previousCommit),
 	}
 
 	var b bytes.Buffer
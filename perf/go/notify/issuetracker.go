@@ -22,6 +22,8 @@ type IssueTrackerTransport struct {
 	sendNewRegressionFail     metrics2.Counter
 	sendRegressionMissing     metrics2.Counter
 	sendRegressionMissingFail metrics2.Counter
+	setPriority               metrics2.Counter
+	setPriorityFail           metrics2.Counter
 }
 
 // NewIssueTrackerTransport returns a new IssueTrackerTransport.
@@ -51,6 +53,8 @@ func NewIssueTrackerTransport(ctx context.Context, cfg *config.NotifyConfig) (*I
 		sendNewRegressionFail:     metrics2.GetCounter("perf_issue_tracker_sent_new_regression_fail"),
 		sendRegressionMissing:     metrics2.GetCounter("perf_issue_tracker_sent_regression_missing"),
 		sendRegressionMissingFail: metrics2.GetCounter("perf_issue_tracker_sent_regression_missing_fail"),
+		setPriority:               metrics2.GetCounter("perf_issue_tracker_set_priority"),
+		setPriorityFail:           metrics2.GetCounter("perf_issue_tracker_set_priority_fail"),
 	}, nil
 }
 
@@ -109,3 +113,24 @@ func (t *IssueTrackerTransport) SendRegressionMissing(ctx context.Context, threa
 	t.sendRegressionMissing.Inc(1)
 	return nil
 }
+
+// SetPriority implements PrioritySetter.
+func (t *IssueTrackerTransport) SetPriority(ctx context.Context, threadingReference string, priority int) error {
+	issueID, err := strconv.ParseInt(threadingReference, 10, 64)
+	if err != nil {
+		return skerr.Wrapf(err, "invalid issue id #%s", threadingReference)
+	}
+
+	_, err = t.client.Issues.Modify(issueID, &issuetracker.ModifyIssueRequest{
+		Add: &issuetracker.IssueState{
+			Priority: fmt.Sprintf("P%d", priority),
+		},
+		AddMask: "priority",
+	}).Do()
+	if err != nil {
+		t.setPriorityFail.Inc(1)
+		return skerr.Wrapf(err, "updating priority of issue: %d", issueID)
+	}
+	t.setPriority.Inc(1)
+	return nil
+}
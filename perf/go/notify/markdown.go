@@ -32,15 +32,50 @@ With:
   - Direction {{.Cluster.StepFit.Status}}.
 
 From Alert [{{ .Alert.DisplayName }}]({{.URL}}/a/?{{ .Alert.IDAsString }})
+{{- range .DependencyRolls }}
+
+Rolled in {{ .Name }} commits:
+{{- range .Commits }}
+  - [{{ .Subject }}]({{ .URL }})
+{{- end }}
+{{- if .Truncated }}
+  - Older commits are not shown.
+{{- end }}
+{{- end }}
+{{- if .CulpritHints }}
+
+Commits that touched the culprit paths:
+{{- range .CulpritHints }}
+  - [{{ .Commit.Subject }}]({{ .Commit.URL }}) ({{ range $i, $p := .Paths }}{{ if $i }}, {{ end }}{{ $p }}{{ end }})
+{{- end }}
+{{- end }}
 `
 	defaultRegressionMissingMarkdownSubject = `{{ .Alert.DisplayName }} - Regression no longer found for {{ .Commit.Subject }}`
 	defaultRegressionMissingMarkdown        = `The Perf Regression can no longer be detected. This issue is being automatically closed.
 `
 )
 
+// defaultTemplates are the templates used if the NotifyConfig doesn't supply
+// its own.
+type defaultTemplates struct {
+	body           string
+	subject        string
+	missingBody    string
+	missingSubject string
+}
+
+var markdownDefaultTemplates = defaultTemplates{
+	body:           defaultRegressionMarkdown,
+	subject:        defaultRegressionMarkdownSubject,
+	missingBody:    defaultRegressionMissingMarkdown,
+	missingSubject: defaultRegressionMissingMarkdownSubject,
+}
+
 // MarkdownFormatter implement Formatter.
 type MarkdownFormatter struct {
 	commitRangeURITemplate                   string
+	roller                                   DependencyRoller
+	pathFinder                               PathChangeFinder
 	markdownTemplateNewRegression            *template.Template
 	markdownTemplateNewRegressionSubject     *template.Template
 	markdownTemplateRegressionMissing        *template.Template
@@ -69,27 +104,34 @@ func buildIDFromSubject(subject string) string {
 
 // NewMarkdownFormatter return a new MarkdownFormatter.
 func NewMarkdownFormatter(commitRangeURITemplate string, notifyConfig *config.NotifyConfig) (MarkdownFormatter, error) {
+	return newMarkdownFormatter(commitRangeURITemplate, notifyConfig, markdownDefaultTemplates)
+}
+
+// newMarkdownFormatter returns a new MarkdownFormatter that uses the templates
+// in notifyConfig, falling back to the given defaults.
+func newMarkdownFormatter(commitRangeURITemplate string, notifyConfig *config.NotifyConfig, defaults defaultTemplates) (MarkdownFormatter, error) {
 	body := strings.Join(notifyConfig.Body, "\n")
 	if body == "" {
-		body = defaultRegressionMarkdown
+		body = defaults.body
 	}
 	subject := notifyConfig.Subject
 	if subject == "" {
-		subject = defaultRegressionMarkdownSubject
+		subject = defaults.subject
 	}
 
 	missingBody := strings.Join(notifyConfig.MissingBody, "\n")
 	if missingBody == "" {
-		missingBody = defaultRegressionMissingMarkdown
+		missingBody = defaults.missingBody
 	}
 
 	missingSubject := notifyConfig.MissingSubject
 	if missingSubject == "" {
-		missingSubject = defaultRegressionMissingMarkdownSubject
+		missingSubject = defaults.missingSubject
 	}
 
 	funcMap := template.FuncMap{
 		"buildIDFromSubject": buildIDFromSubject,
+		"slackEscape":        slackEscape,
 	}
 
 	markdownTemplateNewRegression, err := template.New("newRegressionMarkdown").Funcs(funcMap).Parse(body)
@@ -158,6 +200,8 @@ func (h MarkdownFormatter) FormatNewRegression(ctx context.Context, commit, prev
 		Alert:           alert,
 		Cluster:         cl,
 		ParamSet:        frame.DataFrame.ParamSet,
+		DependencyRolls: dependencyRolls(ctx, h.roller, commit, previousCommit),
+		CulpritHints:    culpritHints(ctx, h.pathFinder, alert, commit, previousCommit),
 	}
 
 	var body bytes.Buffer
@@ -183,6 +227,8 @@ func (h MarkdownFormatter) FormatRegressionMissing(ctx context.Context, commit,
 		Alert:           alert,
 		Cluster:         cl,
 		ParamSet:        frame.DataFrame.ParamSet,
+		DependencyRolls: dependencyRolls(ctx, h.roller, commit, previousCommit),
+		CulpritHints:    culpritHints(ctx, h.pathFinder, alert, commit, previousCommit),
 	}
 
 	var body bytes.Buffer
@@ -3,33 +3,47 @@ package notify
 
 import (
 	"context"
+	"time"
 
 	"go.skia.org/infra/go/paramtools"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/perf/go/alerts"
 	ag "go.skia.org/infra/perf/go/anomalygroup/notifier"
+	agutils "go.skia.org/infra/perf/go/anomalygroup/utils"
+	backend "go.skia.org/infra/perf/go/backend/client"
 	"go.skia.org/infra/perf/go/clustering2"
 	"go.skia.org/infra/perf/go/config"
 	"go.skia.org/infra/perf/go/dataframe"
+	perfgit "go.skia.org/infra/perf/go/git"
 	"go.skia.org/infra/perf/go/git/provider"
 	"go.skia.org/infra/perf/go/notifytypes"
+	"go.skia.org/infra/perf/go/pinpoint"
 	"go.skia.org/infra/perf/go/stepfit"
+	"go.skia.org/infra/perf/go/tracestore"
 	"go.skia.org/infra/perf/go/ui/frame"
 )
 
-// Formatter has implementations for both HTML and Markdown.
+// Formatter has implementations for HTML, Markdown, and Slack.
 type Formatter interface {
 	// Return body and subject.
 	FormatNewRegression(ctx context.Context, commit, previousCommit provider.Commit, alert *alerts.Alert, cl *clustering2.ClusterSummary, URL string, frame *frame.FrameResponse) (string, string, error)
 	FormatRegressionMissing(ctx context.Context, commit, previousCommit provider.Commit, alert *alerts.Alert, cl *clustering2.ClusterSummary, URL string, frame *frame.FrameResponse) (string, string, error)
 }
 
-// Transport has implementations for email, issuetracker, and the noop implementation.
+// Transport has implementations for email, issuetracker, webhooks, and the noop implementation.
 type Transport interface {
 	SendNewRegression(ctx context.Context, alert *alerts.Alert, body, subject string) (threadingReference string, err error)
 	SendRegressionMissing(ctx context.Context, threadingReference string, alert *alerts.Alert, body, subject string) (err error)
 }
 
+// PrioritySetter is implemented by Transports, and by the Notifiers that use
+// them, that can change the priority of the issue filed for a regression.
+type PrioritySetter interface {
+	// SetPriority sets the priority of the issue identified by
+	// threadingReference, where 0 is the highest priority.
+	SetPriority(ctx context.Context, threadingReference string, priority int) error
+}
+
 const (
 	fromAddress = "alertserver@skia.org"
 )
@@ -65,6 +79,14 @@ type TemplateContext struct {
 
 	// ParamSet for all the matching traces.
 	ParamSet paramtools.ReadOnlyParamSet
+
+	// DependencyRolls are the commits of dependency repos that were rolled
+	// in by the commits in `(PreviousCommit, Commit]`.
+	DependencyRolls []provider.DependencyRoll
+
+	// CulpritHints are the commits in `(PreviousCommit, Commit]` that touched
+	// the paths in Alert.CulpritPaths, most likely culprit first.
+	CulpritHints []CulpritHint
 }
 
 // Notifier provides an interface for regression notification functions
@@ -129,6 +151,19 @@ func (n *defaultNotifier) RegressionMissing(ctx context.Context, commit, previou
 	return nil
 }
 
+// SetPriority implements PrioritySetter. It fails if the transport doesn't
+// support priorities.
+func (n *defaultNotifier) SetPriority(ctx context.Context, threadingReference string, priority int) error {
+	setter, ok := n.transport.(PrioritySetter)
+	if !ok {
+		return skerr.Fmt("transport %T does not support setting priorities", n.transport)
+	}
+	if err := setter.SetPriority(ctx, threadingReference, priority); err != nil {
+		return skerr.Wrapf(err, "setting priority")
+	}
+	return nil
+}
+
 // ExampleSend sends an example for dummy data for the given alerts.Config.
 func (n *defaultNotifier) ExampleSend(ctx context.Context, alert *alerts.Alert) error {
 	commit := provider.Commit{
@@ -180,12 +215,29 @@ func (n *defaultNotifier) ExampleSend(ctx context.Context, alert *alerts.Alert)
 }
 
 // New returns a Notifier of the selected type.
-func New(ctx context.Context, cfg *config.NotifyConfig, URL, commitRangeURITemplate string) (Notifier, error) {
+//
+// If perfGit is not nil then it is used to add the rolled in dependency
+// commits to notifications, and to look up the commits to bisect when
+// cfg.BisectBatch is set. traceStore is used to look up the improvement
+// directions of the anomalies to bisect when cfg.BisectBatch is set.
+func New(ctx context.Context, cfg *config.NotifyConfig, URL, commitRangeURITemplate string, perfGit perfgit.Git, traceStore tracestore.TraceStore) (Notifier, error) {
+	var roller DependencyRoller
+	var pathFinder PathChangeFinder
+	if perfGit != nil {
+		roller = perfGit
+		pathFinder = perfGit
+	}
 	switch cfg.Notifications {
 	case notifytypes.None:
-		return newNotifier(NewHTMLFormatter(commitRangeURITemplate), NewNoopTransport(), URL), nil
+		f := NewHTMLFormatter(commitRangeURITemplate)
+		f.roller = roller
+		f.pathFinder = pathFinder
+		return newNotifier(f, NewNoopTransport(), URL), nil
 	case notifytypes.HTMLEmail:
-		return newNotifier(NewHTMLFormatter(commitRangeURITemplate), NewEmailTransport(), URL), nil
+		f := NewHTMLFormatter(commitRangeURITemplate)
+		f.roller = roller
+		f.pathFinder = pathFinder
+		return newNotifier(f, NewEmailTransport(), URL), nil
 	case notifytypes.MarkdownIssueTracker:
 		tracker, err := NewIssueTrackerTransport(ctx, cfg)
 		if err != nil {
@@ -195,12 +247,71 @@ func New(ctx context.Context, cfg *config.NotifyConfig, URL, commitRangeURITempl
 		if err != nil {
 			return nil, skerr.Wrap(err)
 		}
+		f.roller = roller
+		f.pathFinder = pathFinder
 		return newNotifier(f, tracker, URL), nil
+	case notifytypes.SlackWebhook:
+		webhook, err := NewWebhookTransport(ctx, cfg)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		f, err := NewSlackFormatter(commitRangeURITemplate, cfg)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		f.roller = roller
+		f.pathFinder = pathFinder
+		return newNotifier(f, webhook, URL), nil
+	case notifytypes.GenericWebhook:
+		webhook, err := NewWebhookTransport(ctx, cfg)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		f, err := NewMarkdownFormatter(commitRangeURITemplate, cfg)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		f.roller = roller
+		f.pathFinder = pathFinder
+		return newNotifier(f, webhook, URL), nil
 	case notifytypes.ChromeperfAlerting:
 		return NewChromePerfNotifier(ctx, nil)
 	case notifytypes.AnomalyGrouper:
-		return ag.NewAnomalyGroupNotifier(ctx, nil), nil
+		grouper, err := newAnomalyGrouper(ctx, cfg, perfGit, traceStore)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		return ag.NewAnomalyGroupNotifier(ctx, grouper), nil
 	default:
 		return nil, skerr.Fmt("invalid Notifier type: %s, must be one of: %v", cfg.Notifications, notifytypes.AllNotifierTypes)
 	}
 }
+
+// newAnomalyGrouper returns the AnomalyGrouper for the anomalygrouper
+// notifier, or nil to use the default one. If cfg.BisectBatch is set then the
+// grouper bisects the anomaly groups in batches.
+func newAnomalyGrouper(ctx context.Context, cfg *config.NotifyConfig, perfGit perfgit.Git, traceStore tracestore.TraceStore) (agutils.AnomalyGrouper, error) {
+	if cfg.BisectBatch == nil {
+		return nil, nil
+	}
+	if perfGit == nil {
+		return nil, skerr.Fmt("A git repo is required to bisect anomaly groups.")
+	}
+	if traceStore == nil {
+		return nil, skerr.Fmt("A trace store is required to bisect anomaly groups.")
+	}
+	pinpointClient, err := pinpoint.New(ctx)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	client, err := backend.NewAnomalyGroupServiceClient("", false)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to create anomaly group client")
+	}
+	batcher := agutils.NewBisectBatcher(
+		agutils.BisectBatchPolicy{MaxCommitRange: cfg.BisectBatch.MaxCommitRange},
+		agutils.NewPinpointBisector(pinpointClient, perfGit),
+		client)
+	go batcher.Start(ctx, time.Duration(cfg.BisectBatch.Period))
+	return &agutils.AnomalyGrouperImpl{BisectBatcher: batcher, TraceStore: traceStore}, nil
+}
@@ -176,6 +176,36 @@ func TestExampleSendWithHTMLFormatter_SendNewRegressionReturnsError_ReturnsError
 	require.Contains(t, err.Error(), "sending new regression message")
 }
 
+// priorityTransport is a Transport that also supports setting priorities.
+type priorityTransport struct {
+	*mocks.Transport
+	threadingReference string
+	priority           int
+}
+
+func (p *priorityTransport) SetPriority(ctx context.Context, threadingReference string, priority int) error {
+	p.threadingReference = threadingReference
+	p.priority = priority
+	return nil
+}
+
+func TestSetPriority_TransportSupportsPriorities_SetsPriority(t *testing.T) {
+	tr := &priorityTransport{Transport: mocks.NewTransport(t)}
+	n := newNotifier(NewHTMLFormatter(""), tr, instanceURL)
+
+	err := n.(PrioritySetter).SetPriority(context.Background(), mockThreadingID, 1)
+	require.NoError(t, err)
+	require.Equal(t, mockThreadingID, tr.threadingReference)
+	require.Equal(t, 1, tr.priority)
+}
+
+func TestSetPriority_TransportDoesNotSupportPriorities_ReturnsError(t *testing.T) {
+	n := newNotifier(NewHTMLFormatter(""), mocks.NewTransport(t), instanceURL)
+
+	err := n.(PrioritySetter).SetPriority(context.Background(), mockThreadingID, 1)
+	require.Error(t, err)
+}
+
 func TestExampleSendWithHTMLFormatterAndEMailTransport_HappyPath(t *testing.T) {
 	const expectedMessageID = "<the-actual-message-id>"
 
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"strings"
+
+	"go.skia.org/infra/perf/go/config"
+)
+
+const (
+	defaultRegressionSlackSubject = `{{ slackEscape .Alert.DisplayName }} - Regression found for {{ slackEscape .Commit.Subject }}`
+	defaultRegressionSlack        = `A Perf Regression ({{.Cluster.StepFit.Status}}) has been found at <{{.URL}}/g/t/{{.Commit.GitHash}}|{{.Commit.GitHash}}>.
+
+*Commit:* <{{.CommitURL}}|{{ slackEscape .Commit.Subject }}>
+*Matching traces:* {{.Cluster.Num}}
+*Direction:* {{.Cluster.StepFit.Status}}
+
+<{{.ViewOnDashboard}}|View on dashboard> - From Alert <{{.URL}}/a/?{{ .Alert.IDAsString }}|{{ slackEscape .Alert.DisplayName }}>
+`
+	defaultRegressionMissingSlackSubject = `{{ slackEscape .Alert.DisplayName }} - Regression no longer found for {{ slackEscape .Commit.Subject }}`
+	defaultRegressionMissingSlack        = `The Perf Regression can no longer be detected.
+`
+)
+
+var slackDefaultTemplates = defaultTemplates{
+	body:           defaultRegressionSlack,
+	subject:        defaultRegressionSlackSubject,
+	missingBody:    defaultRegressionMissingSlack,
+	missingSubject: defaultRegressionMissingSlackSubject,
+}
+
+// slackEscaper escapes the control characters in Slack's mrkdwn format.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackEscape is a template func for notify templates that escapes a string so
+// that it can be safely embedded in a Slack message, for example as the text
+// of a link.
+func slackEscape(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+// SlackFormatter implements Formatter by emitting messages in Slack's mrkdwn
+// format.
+//
+// The templates in config.NotifyConfig are used if supplied, otherwise Slack
+// specific defaults are used.
+type SlackFormatter struct {
+	MarkdownFormatter
+}
+
+// NewSlackFormatter returns a new SlackFormatter.
+func NewSlackFormatter(commitRangeURITemplate string, notifyConfig *config.NotifyConfig) (SlackFormatter, error) {
+	f, err := newMarkdownFormatter(commitRangeURITemplate, notifyConfig, slackDefaultTemplates)
+	if err != nil {
+		return SlackFormatter{}, err
+	}
+	return SlackFormatter{MarkdownFormatter: f}, nil
+}
+
+var _ Formatter = SlackFormatter{}
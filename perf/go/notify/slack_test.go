@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/clustering2"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/dataframe"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/stepfit"
+	"go.skia.org/infra/perf/go/ui/frame"
+)
+
+func TestSlackEscape_ControlCharacters_AreEscaped(t *testing.T) {
+	require.Equal(t, "a &lt;b&gt; &amp; c", slackEscape("a <b> & c"))
+}
+
+func TestSlackFormatter_FormatNewRegression_UsesSlackDefaults(t *testing.T) {
+	f, err := NewSlackFormatter("", &config.NotifyConfig{})
+	require.NoError(t, err)
+
+	commit := provider.Commit{
+		GitHash: "abc123",
+		Subject: "Speed up <blur>",
+		URL:     "https://example.org/c/abc123",
+	}
+	alert := &alerts.Alert{
+		IDAsString:  "12",
+		DisplayName: "My & Alert",
+	}
+	cl := &clustering2.ClusterSummary{
+		Num: 3,
+		StepFit: &stepfit.StepFit{
+			Status: stepfit.HIGH,
+		},
+		StepPoint: &dataframe.ColumnHeader{
+			Offset: 2,
+		},
+	}
+	fr := &frame.FrameResponse{
+		DataFrame: &dataframe.DataFrame{
+			ParamSet: paramtools.ReadOnlyParamSet{},
+		},
+	}
+	body, subject, err := f.FormatNewRegression(context.Background(), commit, provider.Commit{}, alert, cl, "https://perf.example.org", fr)
+	require.NoError(t, err)
+	require.Equal(t, "My &amp; Alert - Regression found for Speed up &lt;blur&gt;", subject)
+	require.Contains(t, body, "<https://perf.example.org/g/t/abc123|abc123>")
+	require.Contains(t, body, "*Matching traces:* 3")
+	require.Contains(t, body, "<https://perf.example.org/a/?12|My &amp; Alert>")
+
+	body, subject, err = f.FormatRegressionMissing(context.Background(), commit, provider.Commit{}, alert, cl, "https://perf.example.org", fr)
+	require.NoError(t, err)
+	require.Equal(t, "My &amp; Alert - Regression no longer found for Speed up &lt;blur&gt;", subject)
+	require.Equal(t, "The Perf Regression can no longer be detected.\n", body)
+}
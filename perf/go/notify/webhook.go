@@ -0,0 +1,228 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"go.skia.org/infra/go/httputils"
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/secret"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/notifytypes"
+)
+
+const (
+	// webhookEventNewRegression is the generic webhook event sent when a new
+	// regression is found.
+	webhookEventNewRegression = "new_regression"
+
+	// webhookEventRegressionMissing is the generic webhook event sent when a
+	// regression can no longer be found.
+	webhookEventRegressionMissing = "regression_missing"
+
+	// maxWebhookResponseSize is the maximum number of bytes read from a
+	// webhook response.
+	maxWebhookResponseSize = 1024 * 1024
+)
+
+// slackMessage is the JSON body POSTed to a Slack webhook.
+type slackMessage struct {
+	Channel     string `json:"channel,omitempty"`
+	Text        string `json:"text"`
+	ThreadTS    string `json:"thread_ts,omitempty"`
+	UnfurlLinks bool   `json:"unfurl_links"`
+}
+
+// slackResponse is the response from a Slack API compatible endpoint, such as
+// chat.postMessage, which requires a token. Incoming webhooks just respond with
+// the text "ok", in which case no threading is possible.
+type slackResponse struct {
+	OK    *bool  `json:"ok"`
+	Error string `json:"error"`
+	TS    string `json:"ts"`
+}
+
+// genericWebhookMessage is the JSON body POSTed to a generic webhook.
+type genericWebhookMessage struct {
+	Event              string `json:"event"`
+	AlertID            string `json:"alert_id"`
+	AlertName          string `json:"alert_name"`
+	Channel            string `json:"channel,omitempty"`
+	Subject            string `json:"subject"`
+	Body               string `json:"body"`
+	ThreadingReference string `json:"threading_reference,omitempty"`
+}
+
+// genericWebhookResponse is the optional JSON response from a generic webhook.
+// If the webhook returns a threading_reference then it will be passed back in
+// the message sent when the regression goes missing.
+type genericWebhookResponse struct {
+	ThreadingReference string `json:"threading_reference"`
+}
+
+// WebhookTransport implements Transport by POSTing JSON to a webhook, either
+// in the format that Slack expects, or in a generic format.
+//
+// Failed requests are retried with an exponential backoff.
+type WebhookTransport struct {
+	client                    *http.Client
+	url                       string
+	token                     string
+	format                    notifytypes.Type
+	defaultChannel            string
+	sendNewRegression         metrics2.Counter
+	sendNewRegressionFail     metrics2.Counter
+	sendRegressionMissing     metrics2.Counter
+	sendRegressionMissingFail metrics2.Counter
+}
+
+// NewWebhookTransport returns a new WebhookTransport. The format of the
+// messages sent is controlled by cfg.Notifications, which must be one of the
+// webhook types. If cfg names a token secret then the token is loaded from the
+// secret manager and sent with every request.
+func NewWebhookTransport(ctx context.Context, cfg *config.NotifyConfig) (*WebhookTransport, error) {
+	token := ""
+	if cfg.WebhookTokenSecretProject != "" && cfg.WebhookTokenSecretName != "" {
+		secretClient, err := secret.NewClient(ctx)
+		if err != nil {
+			return nil, skerr.Wrapf(err, "creating secret client")
+		}
+		token, err = secretClient.Get(ctx, cfg.WebhookTokenSecretProject, cfg.WebhookTokenSecretName, secret.VersionLatest)
+		if err != nil {
+			return nil, skerr.Wrapf(err, "loading webhook token from project: %q  name: %q", cfg.WebhookTokenSecretProject, cfg.WebhookTokenSecretName)
+		}
+	}
+	return newWebhookTransport(cfg, token, httputils.DefaultClientConfig().With2xxOnly().Client())
+}
+
+func newWebhookTransport(cfg *config.NotifyConfig, token string, client *http.Client) (*WebhookTransport, error) {
+	if cfg.WebhookURL == "" {
+		return nil, skerr.Fmt("webhook_url must be supplied when `notifications` is set to %q", cfg.Notifications)
+	}
+	if cfg.Notifications != notifytypes.SlackWebhook && cfg.Notifications != notifytypes.GenericWebhook {
+		return nil, skerr.Fmt("invalid webhook notifier type: %q", cfg.Notifications)
+	}
+	return &WebhookTransport{
+		client:                    client,
+		url:                       cfg.WebhookURL,
+		token:                     token,
+		format:                    cfg.Notifications,
+		defaultChannel:            cfg.WebhookDefaultChannel,
+		sendNewRegression:         metrics2.GetCounter("perf_webhook_sent_new_regression"),
+		sendNewRegressionFail:     metrics2.GetCounter("perf_webhook_sent_new_regression_fail"),
+		sendRegressionMissing:     metrics2.GetCounter("perf_webhook_sent_regression_missing"),
+		sendRegressionMissingFail: metrics2.GetCounter("perf_webhook_sent_regression_missing_fail"),
+	}, nil
+}
+
+// channel returns the channel that notifications for the given alert should be
+// routed to.
+func (t *WebhookTransport) channel(alert *alerts.Alert) string {
+	if alert.WebhookChannel != "" {
+		return alert.WebhookChannel
+	}
+	return t.defaultChannel
+}
+
+// post sends the JSON encoded payload to the webhook and returns the body of
+// the response.
+func (t *WebhookTransport) post(ctx context.Context, payload interface{}) ([]byte, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "encoding webhook payload")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(b))
+	if err != nil {
+		return nil, skerr.Wrapf(err, "creating webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "sending webhook request")
+	}
+	defer util.Close(resp.Body)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseSize))
+	if err != nil {
+		return nil, skerr.Wrapf(err, "reading webhook response")
+	}
+	return body, nil
+}
+
+// send formats and sends a single message, returning the threading reference
+// for the message, which may be the empty string if the webhook doesn't
+// support threading.
+func (t *WebhookTransport) send(ctx context.Context, event, threadingReference string, alert *alerts.Alert, body, subject string) (string, error) {
+	if t.format == notifytypes.SlackWebhook {
+		respBody, err := t.post(ctx, slackMessage{
+			Channel:  t.channel(alert),
+			Text:     "*" + subject + "*\n" + body,
+			ThreadTS: threadingReference,
+		})
+		if err != nil {
+			return "", err
+		}
+		var resp slackResponse
+		if err := json.Unmarshal(respBody, &resp); err != nil {
+			// Incoming webhooks respond with plain text.
+			return "", nil
+		}
+		if resp.OK != nil && !*resp.OK {
+			return "", skerr.Fmt("slack returned an error: %q", resp.Error)
+		}
+		return resp.TS, nil
+	}
+
+	respBody, err := t.post(ctx, genericWebhookMessage{
+		Event:              event,
+		AlertID:            alert.IDAsString,
+		AlertName:          alert.DisplayName,
+		Channel:            t.channel(alert),
+		Subject:            subject,
+		Body:               body,
+		ThreadingReference: threadingReference,
+	})
+	if err != nil {
+		return "", err
+	}
+	var resp genericWebhookResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		// A response body is optional.
+		return "", nil
+	}
+	return resp.ThreadingReference, nil
+}
+
+// SendNewRegression implements Transport.
+func (t *WebhookTransport) SendNewRegression(ctx context.Context, alert *alerts.Alert, body, subject string) (string, error) {
+	threadingReference, err := t.send(ctx, webhookEventNewRegression, "", alert, body, subject)
+	if err != nil {
+		t.sendNewRegressionFail.Inc(1)
+		return "", skerr.Wrapf(err, "sending new regression to webhook for alert #%s", alert.IDAsString)
+	}
+	t.sendNewRegression.Inc(1)
+	return threadingReference, nil
+}
+
+// SendRegressionMissing implements Transport.
+//
+// If the threadingReference is not empty then the message is sent as a reply
+// in the same thread as the message that announced the regression.
+func (t *WebhookTransport) SendRegressionMissing(ctx context.Context, threadingReference string, alert *alerts.Alert, body, subject string) error {
+	if _, err := t.send(ctx, webhookEventRegressionMissing, threadingReference, alert, body, subject); err != nil {
+		t.sendRegressionMissingFail.Inc(1)
+		return skerr.Wrapf(err, "sending regression missing to webhook for alert #%s", alert.IDAsString)
+	}
+	t.sendRegressionMissing.Inc(1)
+	return nil
+}
+
+var _ Transport = (*WebhookTransport)(nil)
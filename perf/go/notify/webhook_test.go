@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/httputils"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/notifytypes"
+)
+
+// webhookServer records the JSON bodies POSTed to it, and their Authorization
+// headers, and replies with the given responses in order, repeating the last
+// response once exhausted.
+type webhookServer struct {
+	requests       []map[string]interface{}
+	authorizations []string
+	codes          []int
+	responses      []string
+}
+
+func (w *webhookServer) ServeHTTP(resp http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.requests = append(w.requests, body)
+	w.authorizations = append(w.authorizations, r.Header.Get("Authorization"))
+	i := len(w.requests) - 1
+	if i >= len(w.codes) {
+		i = len(w.codes) - 1
+	}
+	resp.WriteHeader(w.codes[i])
+	_, _ = resp.Write([]byte(w.responses[i]))
+}
+
+func newWebhookTransportForTest(t *testing.T, notifications notifytypes.Type, token string, s *webhookServer) *WebhookTransport {
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	ret, err := newWebhookTransport(&config.NotifyConfig{
+		Notifications:         notifications,
+		WebhookURL:            server.URL,
+		WebhookDefaultChannel: "#perf",
+	}, token, httputils.DefaultClientConfig().With2xxOnly().Client())
+	require.NoError(t, err)
+	return ret
+}
+
+func TestNewWebhookTransport_NoURL_ReturnsError(t *testing.T) {
+	_, err := NewWebhookTransport(context.Background(), &config.NotifyConfig{Notifications: notifytypes.SlackWebhook})
+	require.Contains(t, err.Error(), "webhook_url must be supplied")
+}
+
+func TestWebhookTransport_SlackPostMessageWithToken_ThreadsRegressionMissingUnderNewRegression(t *testing.T) {
+	s := &webhookServer{
+		codes:     []int{http.StatusOK},
+		responses: []string{`{"ok": true, "ts": "1700000000.000100"}`},
+	}
+	tr := newWebhookTransportForTest(t, notifytypes.SlackWebhook, "xoxb-token", s)
+	alert := &alerts.Alert{IDAsString: "1", WebhookChannel: "#my-team"}
+
+	ref, err := tr.SendNewRegression(context.Background(), alert, "the body", "the subject")
+	require.NoError(t, err)
+	require.Equal(t, "1700000000.000100", ref)
+
+	err = tr.SendRegressionMissing(context.Background(), ref, alert, "gone", "missing")
+	require.NoError(t, err)
+
+	require.Len(t, s.requests, 2)
+	require.Equal(t, "#my-team", s.requests[0]["channel"])
+	require.Equal(t, "*the subject*\nthe body", s.requests[0]["text"])
+	require.NotContains(t, s.requests[0], "thread_ts")
+	require.Equal(t, "1700000000.000100", s.requests[1]["thread_ts"])
+	require.Equal(t, "*missing*\ngone", s.requests[1]["text"])
+	require.Equal(t, []string{"Bearer xoxb-token", "Bearer xoxb-token"}, s.authorizations)
+}
+
+func TestWebhookTransport_SlackIncomingWebhookRespondsWithPlainText_NoThreadingReference(t *testing.T) {
+	s := &webhookServer{
+		codes:     []int{http.StatusOK},
+		responses: []string{"ok"},
+	}
+	tr := newWebhookTransportForTest(t, notifytypes.SlackWebhook, "", s)
+
+	ref, err := tr.SendNewRegression(context.Background(), &alerts.Alert{IDAsString: "1"}, "the body", "the subject")
+	require.NoError(t, err)
+	require.Equal(t, "", ref)
+	require.Equal(t, "#perf", s.requests[0]["channel"])
+	require.Equal(t, "", s.authorizations[0])
+}
+
+func TestWebhookTransport_SlackRespondsNotOK_ReturnsError(t *testing.T) {
+	s := &webhookServer{
+		codes:     []int{http.StatusOK},
+		responses: []string{`{"ok": false, "error": "channel_not_found"}`},
+	}
+	tr := newWebhookTransportForTest(t, notifytypes.SlackWebhook, "", s)
+
+	_, err := tr.SendNewRegression(context.Background(), &alerts.Alert{IDAsString: "1"}, "the body", "the subject")
+	require.Contains(t, err.Error(), "channel_not_found")
+}
+
+func TestWebhookTransport_Generic_SendsEventsAndThreadingReference(t *testing.T) {
+	s := &webhookServer{
+		codes:     []int{http.StatusOK},
+		responses: []string{`{"threading_reference": "thread-1"}`},
+	}
+	tr := newWebhookTransportForTest(t, notifytypes.GenericWebhook, "", s)
+	alert := &alerts.Alert{IDAsString: "7", DisplayName: "My Alert"}
+
+	ref, err := tr.SendNewRegression(context.Background(), alert, "the body", "the subject")
+	require.NoError(t, err)
+	require.Equal(t, "thread-1", ref)
+	require.NoError(t, tr.SendRegressionMissing(context.Background(), ref, alert, "gone", "missing"))
+
+	require.Equal(t, map[string]interface{}{
+		"event":      webhookEventNewRegression,
+		"alert_id":   "7",
+		"alert_name": "My Alert",
+		"channel":    "#perf",
+		"subject":    "the subject",
+		"body":       "the body",
+	}, s.requests[0])
+	require.Equal(t, webhookEventRegressionMissing, s.requests[1]["event"])
+	require.Equal(t, "thread-1", s.requests[1]["threading_reference"])
+}
+
+func TestWebhookTransport_ServerErrorIsRetried_Success(t *testing.T) {
+	s := &webhookServer{
+		codes:     []int{http.StatusServiceUnavailable, http.StatusOK},
+		responses: []string{"try again", "{}"},
+	}
+	tr := newWebhookTransportForTest(t, notifytypes.GenericWebhook, "", s)
+
+	_, err := tr.SendNewRegression(context.Background(), &alerts.Alert{IDAsString: "1"}, "the body", "the subject")
+	require.NoError(t, err)
+	require.Len(t, s.requests, 2)
+}
+
+func TestWebhookTransport_ClientError_ReturnsError(t *testing.T) {
+	s := &webhookServer{
+		codes:     []int{http.StatusBadRequest},
+		responses: []string{"bad"},
+	}
+	tr := newWebhookTransportForTest(t, notifytypes.GenericWebhook, "", s)
+
+	err := tr.SendRegressionMissing(context.Background(), "", &alerts.Alert{IDAsString: "1"}, "the body", "the subject")
+	require.Error(t, err)
+	require.Len(t, s.requests, 1)
+}
@@ -18,9 +18,17 @@ const (
 	// AnomalyGrouper means send the regression to grouping logic and take action as needed
 	AnomalyGrouper Type = "anomalygroup"
 
+	// SlackWebhook means send Slack formatted messages to a Slack compatible
+	// webhook.
+	SlackWebhook Type = "slack_webhook"
+
+	// GenericWebhook means send Markdown formatted notifications as a JSON
+	// POST to a webhook.
+	GenericWebhook Type = "webhook"
+
 	// None means do not send any notification.
 	None Type = "none"
 )
 
 // AllNotifierTypes is the list of all valid NotifyTypes.
-var AllNotifierTypes []Type = []Type{HTMLEmail, MarkdownIssueTracker, None}
+var AllNotifierTypes []Type = []Type{HTMLEmail, MarkdownIssueTracker, SlackWebhook, GenericWebhook, None}
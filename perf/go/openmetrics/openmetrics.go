@@ -0,0 +1,304 @@
+// Package openmetrics exports the latest values of selected traces in the
+// OpenMetrics text format, so that Perf data can be scraped by Prometheus and
+// displayed on existing dashboards.
+//
+// See https://github.com/OpenObservability/OpenMetrics/blob/main/specification/OpenMetrics.md.
+package openmetrics
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/now"
+	"go.skia.org/infra/go/query"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/go/vec32"
+	"go.skia.org/infra/perf/go/cache"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/dataframe"
+	"go.skia.org/infra/perf/go/progress"
+)
+
+const (
+	// ContentType is the content type of the OpenMetrics text format.
+	ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+	defaultMaxSeries     = 1000
+	defaultNumCommits    = 20
+	defaultCacheDuration = 5 * time.Minute
+)
+
+// gauge is a single query from the config, with the query parsed.
+type gauge struct {
+	name  string
+	help  string
+	query *query.Query
+
+	// cacheKey is the key the rendered gauge is stored under in the cache.
+	cacheKey string
+}
+
+// cacheEntry is the value stored in the cache for each gauge.
+type cacheEntry struct {
+	Created time.Time
+	Body    []byte
+}
+
+// Exporter writes the latest values of the traces that match the configured
+// queries as OpenMetrics gauges.
+type Exporter struct {
+	dfb           dataframe.DataFrameBuilder
+	cache         cache.Cache
+	gauges        []gauge
+	maxSeries     int
+	numCommits    int32
+	cacheDuration time.Duration
+
+	cacheHits     metrics2.Counter
+	cacheMisses   metrics2.Counter
+	seriesDropped metrics2.Counter
+	queryFailures metrics2.Counter
+}
+
+// New returns a new *Exporter for the given config. The rendered gauges are
+// cached in the given cache.Cache.
+func New(dfb dataframe.DataFrameBuilder, c cache.Cache, cfg config.OpenMetricsConfig) (*Exporter, error) {
+	ret := &Exporter{
+		dfb:           dfb,
+		cache:         c,
+		maxSeries:     cfg.MaxSeries,
+		numCommits:    cfg.NumCommits,
+		cacheDuration: time.Duration(cfg.CacheDuration),
+		cacheHits:     metrics2.GetCounter("perfserver_openmetrics_cache_hits"),
+		cacheMisses:   metrics2.GetCounter("perfserver_openmetrics_cache_misses"),
+		seriesDropped: metrics2.GetCounter("perfserver_openmetrics_series_dropped"),
+		queryFailures: metrics2.GetCounter("perfserver_openmetrics_query_failures"),
+	}
+	if ret.maxSeries <= 0 {
+		ret.maxSeries = defaultMaxSeries
+	}
+	if ret.numCommits <= 0 {
+		ret.numCommits = defaultNumCommits
+	}
+	if ret.cacheDuration <= 0 {
+		ret.cacheDuration = defaultCacheDuration
+	}
+	for _, q := range cfg.Queries {
+		parsed, err := query.NewFromString(q.Query)
+		if err != nil {
+			return nil, skerr.Wrapf(err, "Invalid query for metric %q", q.Name)
+		}
+		ret.gauges = append(ret.gauges, gauge{
+			name:     q.Name,
+			help:     q.Help,
+			query:    parsed,
+			cacheKey: fmt.Sprintf("openmetrics-%s-%x", q.Name, md5.Sum([]byte(parsed.KeyValueString()))),
+		})
+	}
+	return ret, nil
+}
+
+// Write writes all the gauges to w in the OpenMetrics text format. A gauge
+// whose traces fail to load is left out, so a single bad query doesn't break
+// the scrape of all the others.
+func (e *Exporter) Write(ctx context.Context, w io.Writer) error {
+	for _, g := range e.gauges {
+		body, err := e.render(ctx, g)
+		if err != nil {
+			e.queryFailures.Inc(1)
+			sklog.Errorf("Failed to load traces for metric %q: %s", g.name, err)
+			continue
+		}
+		if _, err := w.Write(body); err != nil {
+			return skerr.Wrap(err)
+		}
+	}
+	_, err := io.WriteString(w, "# EOF\n")
+	return skerr.Wrap(err)
+}
+
+// ServeHTTP implements http.Handler.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := e.Write(r.Context(), &buf); err != nil {
+		sklog.Errorf("Failed to write metrics: %s", err)
+		http.Error(w, "Failed to write metrics.", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		sklog.Errorf("Failed to send metrics: %s", err)
+	}
+}
+
+// render returns the gauge in the OpenMetrics text format, from the cache if
+// a recent enough copy is available.
+func (e *Exporter) render(ctx context.Context, g gauge) ([]byte, error) {
+	if b, ok := e.cache.GetValue(g.cacheKey); ok {
+		var entry cacheEntry
+		err := gob.NewDecoder(bytes.NewReader(b)).Decode(&entry)
+		if err == nil && now.Now(ctx).Sub(entry.Created) < e.cacheDuration {
+			e.cacheHits.Inc(1)
+			return entry.Body, nil
+		}
+	}
+	e.cacheMisses.Inc(1)
+
+	df, err := e.dfb.NewNFromQuery(ctx, now.Now(ctx), g.query, e.numCommits, progress.New())
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	body := e.format(g, df)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cacheEntry{Created: now.Now(ctx), Body: body}); err != nil {
+		sklog.Warningf("Failed to encode metric %q for the cache: %s", g.name, err)
+		return body, nil
+	}
+	e.cache.SetValue(g.cacheKey, buf.Bytes())
+	return body, nil
+}
+
+// format returns the gauge for the latest value of each trace in the
+// DataFrame, limited to maxSeries traces.
+func (e *Exporter) format(g gauge, df *dataframe.DataFrame) []byte {
+	traceIDs := make([]string, 0, len(df.TraceSet))
+	for traceID := range df.TraceSet {
+		traceIDs = append(traceIDs, traceID)
+	}
+	sort.Strings(traceIDs)
+
+	var buf bytes.Buffer
+	if g.help != "" {
+		fmt.Fprintf(&buf, "# HELP %s %s\n", g.name, helpEscaper.Replace(g.help))
+	}
+	fmt.Fprintf(&buf, "# TYPE %s gauge\n", g.name)
+	series := 0
+	for _, traceID := range traceIDs {
+		value, ok := latest(df.TraceSet[traceID])
+		if !ok {
+			continue
+		}
+		if series == e.maxSeries {
+			e.seriesDropped.Inc(1)
+			continue
+		}
+		params, err := query.ParseKey(traceID)
+		if err != nil {
+			sklog.Warningf("Skipping invalid trace id %q: %s", traceID, err)
+			continue
+		}
+		series++
+		buf.WriteString(g.name)
+		buf.WriteString(labels(params))
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatFloat(float64(value), 'g', -1, 32))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// latest returns the last value of the trace that isn't missing.
+func latest(values []float32) (float32, bool) {
+	for i := len(values) - 1; i >= 0; i-- {
+		if values[i] != vec32.MissingDataSentinel {
+			return values[i], true
+		}
+	}
+	return 0, false
+}
+
+// labels returns the params formatted as an OpenMetrics label set, e.g.
+// `{arch="x86",config="8888"}`.
+func labels(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	names := labelNames(keys)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(names[key])
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(params[key]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// labelNames returns the label name to use for each of the sorted param keys.
+// Keys that labelName maps to the same name, e.g. "a-b" and "a_b", would
+// otherwise produce duplicate labels, so a key that is already a valid label
+// name keeps it, and the other keys get the lowest numeric suffix, e.g.
+// "a_b_2", that no other key uses.
+func labelNames(keys []string) map[string]string {
+	ret := make(map[string]string, len(keys))
+	used := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if name := labelName(key); name == key {
+			ret[key] = name
+			used[name] = true
+		}
+	}
+	for _, key := range keys {
+		if _, ok := ret[key]; ok {
+			continue
+		}
+		name := labelName(key)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s_%d", labelName(key), i)
+		}
+		ret[key] = name
+		used[name] = true
+	}
+	return ret
+}
+
+// labelName converts a param key into a valid label name by replacing all
+// invalid characters with underscores. Names starting with a digit, or with
+// the reserved "__" prefix, get an extra prefix.
+func labelName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		valid := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9')
+		if i == 0 && r >= '0' && r <= '9' {
+			b.WriteString("param_")
+			valid = true
+		}
+		if valid {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	ret := b.String()
+	if strings.HasPrefix(ret, "__") {
+		ret = "param" + ret
+	}
+	return ret
+}
+
+// labelValueEscaper escapes label values.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// helpEscaper escapes help text, in which OpenMetrics doesn't escape double
+// quotes.
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
@@ -0,0 +1,177 @@
+package openmetrics
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/now"
+	"go.skia.org/infra/go/vec32"
+	"go.skia.org/infra/perf/go/cache/local"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/dataframe"
+	"go.skia.org/infra/perf/go/dataframe/mocks"
+	"go.skia.org/infra/perf/go/types"
+)
+
+const e = vec32.MissingDataSentinel
+
+func newDataFrame() *dataframe.DataFrame {
+	return &dataframe.DataFrame{
+		TraceSet: types.TraceSet{
+			",arch=x86,config=8888,": []float32{1, 2, 3},
+			",arch=arm,config=8888,": []float32{4, 5, e},
+			",arch=x86,config=565,":  []float32{e, e, e},
+		},
+	}
+}
+
+func newExporterForTest(t *testing.T, cfg config.OpenMetricsConfig) (*Exporter, *mocks.DataFrameBuilder) {
+	dfb := &mocks.DataFrameBuilder{}
+	c, err := local.New(10)
+	require.NoError(t, err)
+	exporter, err := New(dfb, c, cfg)
+	require.NoError(t, err)
+	return exporter, dfb
+}
+
+var renderTimeConfig = config.OpenMetricsConfig{
+	Queries: []config.OpenMetricsQuery{
+		{
+			Name:  "render_time",
+			Query: "config=8888",
+			Help:  "Render time in ms.",
+		},
+	},
+}
+
+func TestWrite_LatestValueOfEachTrace_WritesGauge(t *testing.T) {
+	exporter, dfb := newExporterForTest(t, renderTimeConfig)
+	dfb.On("NewNFromQuery", mock.Anything, mock.Anything, mock.Anything, int32(defaultNumCommits), mock.Anything).Return(newDataFrame(), nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, exporter.Write(context.Background(), &buf))
+	assert.Equal(t, `# HELP render_time Render time in ms.
+# TYPE render_time gauge
+render_time{arch="arm",config="8888"} 5
+render_time{arch="x86",config="8888"} 3
+# EOF
+`, buf.String())
+}
+
+func TestWrite_MaxSeries_ExtraTracesDropped(t *testing.T) {
+	cfg := renderTimeConfig
+	cfg.MaxSeries = 1
+	exporter, dfb := newExporterForTest(t, cfg)
+	dfb.On("NewNFromQuery", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(newDataFrame(), nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, exporter.Write(context.Background(), &buf))
+	assert.Equal(t, `# HELP render_time Render time in ms.
+# TYPE render_time gauge
+render_time{arch="arm",config="8888"} 5
+# EOF
+`, buf.String())
+}
+
+func TestWrite_CachedUntilCacheDurationPasses(t *testing.T) {
+	exporter, dfb := newExporterForTest(t, renderTimeConfig)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dfb.On("NewNFromQuery", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(newDataFrame(), nil)
+
+	ctx := now.TimeTravelingContext(start)
+	require.NoError(t, exporter.Write(ctx, &bytes.Buffer{}))
+	require.NoError(t, exporter.Write(ctx, &bytes.Buffer{}))
+	dfb.AssertNumberOfCalls(t, "NewNFromQuery", 1)
+
+	ctx.SetTime(start.Add(defaultCacheDuration))
+	require.NoError(t, exporter.Write(ctx, &bytes.Buffer{}))
+	dfb.AssertNumberOfCalls(t, "NewNFromQuery", 2)
+}
+
+func TestWrite_QueryFails_OtherGaugesStillWritten(t *testing.T) {
+	cfg := config.OpenMetricsConfig{
+		Queries: []config.OpenMetricsQuery{
+			{Name: "broken", Query: "config=565"},
+			{Name: "render_time", Query: "config=8888"},
+		},
+	}
+	exporter, dfb := newExporterForTest(t, cfg)
+	dfb.On("NewNFromQuery", mock.Anything, mock.Anything, mock.MatchedBy(func(q interface{}) bool {
+		return exporter.gauges[0].query == q
+	}), mock.Anything, mock.Anything).Return(nil, assert.AnError)
+	dfb.On("NewNFromQuery", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(newDataFrame(), nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, exporter.Write(context.Background(), &buf))
+	assert.Equal(t, `# TYPE render_time gauge
+render_time{arch="arm",config="8888"} 5
+render_time{arch="x86",config="8888"} 3
+# EOF
+`, buf.String())
+}
+
+func TestServeHTTP_SetsOpenMetricsContentType(t *testing.T) {
+	exporter, dfb := newExporterForTest(t, renderTimeConfig)
+	dfb.On("NewNFromQuery", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(newDataFrame(), nil)
+
+	w := httptest.NewRecorder()
+	exporter.ServeHTTP(w, httptest.NewRequest("GET", "/_/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "# EOF\n")
+}
+
+func TestLabels_InvalidNamesAndValues_AreEscaped(t *testing.T) {
+	assert.Equal(t, `{param_2d="b",param__name="a",sub_test="c\"d\\e\nf"}`, labels(map[string]string{
+		"sub-test": "c\"d\\e\nf",
+		"2d":       "b",
+		"__name":   "a",
+	}))
+}
+
+func TestLabels_NamesCollide_AreDisambiguated(t *testing.T) {
+	assert.Equal(t, `{a_b_3="b",a_b="a",a_b_2="c"}`, labels(map[string]string{
+		"a_b":   "a",
+		"a-b":   "b",
+		"a_b_2": "c",
+	}))
+}
+
+func TestLabels_InvalidNamesCollide_AreDisambiguated(t *testing.T) {
+	assert.Equal(t, `{a_b="a",a_b_2="b"}`, labels(map[string]string{
+		"a-b": "a",
+		"a.b": "b",
+	}))
+}
+
+func TestWrite_HelpWithQuotes_QuotesNotEscaped(t *testing.T) {
+	cfg := config.OpenMetricsConfig{
+		Queries: []config.OpenMetricsQuery{
+			{
+				Name:  "render_time",
+				Query: "config=8888",
+				Help:  "Render time of \"8888\" in ms.\nC:\\ path.",
+			},
+		},
+	}
+	exporter, dfb := newExporterForTest(t, cfg)
+	dfb.On("NewNFromQuery", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(newDataFrame(), nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, exporter.Write(context.Background(), &buf))
+	assert.Contains(t, buf.String(), `# HELP render_time Render time of "8888" in ms.\nC:\\ path.`+"\n")
+}
+
+func TestNew_InvalidQuery_ReturnsError(t *testing.T) {
+	_, err := New(&mocks.DataFrameBuilder{}, nil, config.OpenMetricsConfig{
+		Queries: []config.OpenMetricsQuery{{Name: "bad", Query: "%gh&%ij"}},
+	})
+	require.Error(t, err)
+}
@@ -19,19 +19,26 @@ import (
 	"go.skia.org/infra/go/gcs"
 	"go.skia.org/infra/go/gcs/gcsclient"
 	"go.skia.org/infra/go/httputils"
+	"go.skia.org/infra/go/paramtools"
 	"go.skia.org/infra/go/query"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/sklog"
 	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/go/vec32"
 	"go.skia.org/infra/perf/go/alerts"
 	"go.skia.org/infra/perf/go/builders"
+	"go.skia.org/infra/perf/go/columnar"
 	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/dfbuilder"
 	"go.skia.org/infra/perf/go/file"
 	"go.skia.org/infra/perf/go/ingest/format"
 	"go.skia.org/infra/perf/go/ingest/parser"
 	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/regression/backtest"
+	"go.skia.org/infra/perf/go/sheriffconfig/service"
 	"go.skia.org/infra/perf/go/shortcut"
 	"go.skia.org/infra/perf/go/tracestore"
+	"go.skia.org/infra/perf/go/tracestore/retention"
 	"go.skia.org/infra/perf/go/trybot/samplesloader/gcssamplesloader"
 	"go.skia.org/infra/perf/go/types"
 	"golang.org/x/oauth2/google"
@@ -49,11 +56,14 @@ type Application interface {
 	DatabaseRestoreRegressions(local bool, instanceConfig *config.InstanceConfig, inputFile string) error
 	TilesLast(store tracestore.TraceStore) error
 	TilesList(store tracestore.TraceStore, num int) error
+	TilesCompact(store tracestore.TraceStore, instanceConfig *config.InstanceConfig, dryrun bool) error
 	TracesList(store tracestore.TraceStore, queryString string, tileNumber types.TileNumber) error
-	TracesExport(store tracestore.TraceStore, queryString string, begin, end types.CommitNumber, outputFile string) error
+	TracesExport(store tracestore.TraceStore, queryString string, begin, end types.CommitNumber, format, outputFile string) error
 	IngestForceReingest(local bool, instanceConfig *config.InstanceConfig, start, stop string, dryrun bool) error
 	IngestValidate(inputFile string, verbose bool) error
 	TrybotReference(local bool, store tracestore.TraceStore, instanceConfig *config.InstanceConfig, trybotFilename string, outputFilename string, numCommits int) error
+	AlertsBacktest(local bool, instanceConfig *config.InstanceConfig, alertFile, alertID string, begin, end types.CommitNumber, tolerance int, outputFile string) error
+	SheriffValidate(inputFile string) error
 }
 
 // app implements Application.
@@ -70,6 +80,10 @@ const regressionBatchSize = 1000
 // ackDeadline is the acknowledge deadline of the Pub/Sub subscriptions.
 const ackDeadline = 10 * time.Minute
 
+// backtestNumPreflightTiles is the number of tiles searched for matching traces
+// when backtesting, the same as the default for the frontend.
+const backtestNumPreflightTiles = 2
+
 func createPubSubTopic(ctx context.Context, client *pubsub.Client, topicName string) (*pubsub.Topic, error) {
 	topic := client.Topic(topicName)
 	ok, err := topic.Exists(ctx)
@@ -628,6 +642,32 @@ func (app) TilesList(store tracestore.TraceStore, num int) error {
 	return nil
 }
 
+// TilesCompact applies the retention policy in the instance config to the
+// tiles that fall outside the retention period and prints how many rows were
+// removed, or would be removed if dryrun is true.
+func (app) TilesCompact(store tracestore.TraceStore, instanceConfig *config.InstanceConfig, dryrun bool) error {
+	compactor, err := retention.New(store, instanceConfig.RetentionConfig)
+	if err != nil {
+		return skerr.Wrapf(err, "Invalid retention_config")
+	}
+	results, err := compactor.Compact(context.Background(), dryrun)
+	fmt.Println("tile\tvalues\tsamples\tpostings\tparamsets")
+	var total int64
+	for _, res := range results {
+		fmt.Printf("%d\t%d\t%d\t%d\t%d\n", res.TileNumber, res.Values, res.Samples, res.Postings, res.ParamSets)
+		total += res.Total()
+	}
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if dryrun {
+		fmt.Printf("Would remove %d rows from %d tiles.\n", total, len(results))
+	} else {
+		fmt.Printf("Removed %d rows from %d tiles.\n", total, len(results))
+	}
+	return nil
+}
+
 // TracesList list trace ids that match the given query in the given tile.
 func (app) TracesList(store tracestore.TraceStore, queryString string, tileNumber types.TileNumber) error {
 	if tileNumber == types.BadTileNumber {
@@ -655,14 +695,33 @@ func (app) TracesList(store tracestore.TraceStore, queryString string, tileNumbe
 	return nil
 }
 
-// TracesExport exports the matching traces and their values as JSON.
-func (app) TracesExport(store tracestore.TraceStore, queryString string, begin, end types.CommitNumber, outputFile string) error {
+// JSONExportFormat is the format for TracesExport that writes a single JSON
+// object of trace ids to values. The other valid formats are the
+// columnar.Formats.
+const JSONExportFormat = "json"
+
+// TracesExport exports the matching traces and their values in the given
+// format.
+//
+// JSON exports are limited to the tile that contains begin, while columnar
+// exports cover every tile in the commit range and are written out as they are
+// read, one tile at a time.
+func (app) TracesExport(store tracestore.TraceStore, queryString string, begin, end types.CommitNumber, format, outputFile string) error {
 	ctx := context.Background()
 
 	// If --end is unspecified then just return values for the --begin commit.
 	if end == types.BadCommitNumber {
 		end = begin
 	}
+	if end < begin {
+		return skerr.Fmt("Invalid commit range, --end %d must not be before --begin %d", end, begin)
+	}
+	// Check the format before any tiles are read, or the output file is
+	// created.
+	isJSON := format == JSONExportFormat || format == ""
+	if !isJSON && !columnar.Format(format).IsValid() {
+		return skerr.Fmt("Unknown --format %q, must be %q or one of %v", format, JSONExportFormat, columnar.AllFormats)
+	}
 
 	values, err := url.ParseQuery(queryString)
 	if err != nil {
@@ -673,19 +732,46 @@ func (app) TracesExport(store tracestore.TraceStore, queryString string, begin,
 		return err
 	}
 
-	// First get all the trace names for the given query.
-	tileNumber := types.TileNumberFromCommitNumber(begin, store.TileSize())
+	export := func(w io.Writer) error {
+		if isJSON {
+			return tracesExportJSON(ctx, store, q, begin, end, w)
+		}
+		return tracesExportColumnar(ctx, store, q, begin, end, columnar.Format(format), w)
+	}
+	if outputFile != "" {
+		return util.WithWriteFile(outputFile, export)
+	}
+	return export(os.Stdout)
+}
+
+// queryTraceIDs returns the params of all the traces in the given tile that
+// match the query, keyed by trace id.
+func queryTraceIDs(ctx context.Context, store tracestore.TraceStore, tileNumber types.TileNumber, q *query.Query) (map[string]paramtools.Params, error) {
 	ch, err := store.QueryTracesIDOnly(ctx, tileNumber, q)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	traceNames := []string{}
+	ret := map[string]paramtools.Params{}
 	for p := range ch {
 		traceName, err := query.MakeKey(p)
 		if err != nil {
 			sklog.Warningf("Invalid trace name found in query response: %s", err)
 			continue
 		}
+		ret[traceName] = p
+	}
+	return ret, nil
+}
+
+func tracesExportJSON(ctx context.Context, store tracestore.TraceStore, q *query.Query, begin, end types.CommitNumber, w io.Writer) error {
+	// First get all the trace names for the given query.
+	tileNumber := types.TileNumberFromCommitNumber(begin, store.TileSize())
+	traces, err := queryTraceIDs(ctx, store, tileNumber, q)
+	if err != nil {
+		return err
+	}
+	traceNames := make([]string, 0, len(traces))
+	for traceName := range traces {
 		traceNames = append(traceNames, traceName)
 	}
 
@@ -694,14 +780,80 @@ func (app) TracesExport(store tracestore.TraceStore, queryString string, begin,
 	if err != nil {
 		return err
 	}
+	return json.NewEncoder(w).Encode(ts)
+}
 
-	// Write the JSON results.
-	if outputFile != "" {
-		return util.WithWriteFile(outputFile, func(w io.Writer) error {
-			return json.NewEncoder(w).Encode(ts)
-		})
+func tracesExportColumnar(ctx context.Context, store tracestore.TraceStore, q *query.Query, begin, end types.CommitNumber, format columnar.Format, w io.Writer) error {
+	beginTile := store.TileNumber(begin)
+	endTile := store.TileNumber(end)
+
+	// The columns must be known before the first row is written, so gather
+	// the param keys across all the tiles first.
+	ps := paramtools.NewParamSet()
+	for tileNumber := beginTile; tileNumber <= endTile; tileNumber++ {
+		tilePs, err := store.GetParamSet(ctx, tileNumber)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to load ParamSet for tile %d", tileNumber)
+		}
+		ps.AddParamSet(tilePs)
+	}
+	keys := ps.Keys()
+	sort.Strings(keys)
+
+	cw, err := columnar.New(format, w, keys, columnar.DefaultBatchSize)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+
+	// Now export the matching traces one tile at a time.
+	for tileNumber := beginTile; tileNumber <= endTile; tileNumber++ {
+		tileBegin, tileEnd := types.TileCommitRangeForTileNumber(tileNumber, store.TileSize())
+		if tileBegin < begin {
+			tileBegin = begin
+		}
+		if tileEnd > end {
+			tileEnd = end
+		}
+		traces, err := queryTraceIDs(ctx, store, tileNumber, q)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to query tile %d", tileNumber)
+		}
+		if len(traces) == 0 {
+			continue
+		}
+		traceNames := make([]string, 0, len(traces))
+		for traceName := range traces {
+			traceNames = append(traceNames, traceName)
+		}
+		sort.Strings(traceNames)
+
+		ts, commits, err := store.ReadTracesForCommitRange(ctx, traceNames, tileBegin, tileEnd)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to read traces for tile %d", tileNumber)
+		}
+		for _, traceName := range traceNames {
+			trace, ok := ts[traceName]
+			if !ok {
+				continue
+			}
+			for i, value := range trace {
+				if value == vec32.MissingDataSentinel || i >= len(commits) {
+					continue
+				}
+				err := cw.Write(columnar.Row{
+					TraceID: traceName,
+					Params:  traces[traceName],
+					Commit:  commits[i],
+					Value:   value,
+				})
+				if err != nil {
+					return skerr.Wrap(err)
+				}
+			}
+		}
+		sklog.Infof("Exported tile %d", tileNumber)
 	}
-	return json.NewEncoder(os.Stdout).Encode(ts)
+	return cw.Close()
 }
 
 // IngestForceReingest forces data to be reingested over the given time range.
@@ -816,7 +968,7 @@ func (app) IngestValidate(inputFile string, verbose bool) error {
 		if err != nil {
 			return fmt.Errorf("Failed to create parser: %s", skerr.Unwrap(err))
 		}
-		p, v, hash, err := parser.Parse(ctx, f)
+		p, v, samples, metadata, hash, err := parser.ParseWithMetadata(ctx, f)
 		if err != nil {
 			return fmt.Errorf("Parse Failed: %s", skerr.Unwrap(err))
 		}
@@ -827,7 +979,14 @@ func (app) IngestValidate(inputFile string, verbose bool) error {
 			if err != nil {
 				return fmt.Errorf("Could not make a valid key from %v: %s ", params, err)
 			}
-			fmt.Printf("  %s = %g\n", key, v[i])
+			line := fmt.Sprintf("  %s = %g", key, v[i])
+			if len(samples[i]) > 0 {
+				line += fmt.Sprintf(" samples=%v", samples[i])
+			}
+			if !metadata[i].IsEmpty() {
+				line += fmt.Sprintf(" unit=%q improvement_direction=%q", metadata[i].Unit, metadata[i].ImprovementDirection)
+			}
+			fmt.Println(line)
 		}
 
 		fmt.Printf("Links:\n")
@@ -931,6 +1090,100 @@ func (app) TrybotReference(local bool, store tracestore.TraceStore, instanceConf
 	})
 }
 
+// AlertsBacktest runs regression detection for an alert over a range of
+// commits and writes, as JSON, how the regressions found compare to the ones
+// already stored for the alert.
+//
+// The alert is loaded from alertFile if given, otherwise the stored alert with
+// the ID alertID is used. The found regressions are compared with the stored
+// regressions for alertID, or for the ID in alertFile if alertID is empty.
+func (app) AlertsBacktest(local bool, instanceConfig *config.InstanceConfig, alertFile, alertID string, begin, end types.CommitNumber, tolerance int, outputFile string) error {
+	ctx := context.Background()
+
+	if end == types.BadCommitNumber {
+		end = begin
+	}
+	if end < begin {
+		return skerr.Fmt("Invalid commit range, --end %d must not be before --begin %d", end, begin)
+	}
+	if tolerance < 0 {
+		return skerr.Fmt("--tolerance must not be negative, got %d", tolerance)
+	}
+
+	var alert *alerts.Alert
+	if alertFile != "" {
+		err := util.WithReadFile(alertFile, func(f io.Reader) error {
+			alert = alerts.NewConfig()
+			return json.NewDecoder(f).Decode(alert)
+		})
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to read alert from %q", alertFile)
+		}
+		if alertID == "" {
+			alertID = alert.IDAsString
+		}
+	} else {
+		if alertID == "" {
+			return skerr.Fmt("One of --in or --alert_id must be supplied.")
+		}
+		alertStore, err := builders.NewAlertStoreFromConfig(ctx, local, instanceConfig)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		all, err := alertStore.List(ctx, true)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		for _, a := range all {
+			if a.IDAsString == alertID {
+				alert = a
+				break
+			}
+		}
+		if alert == nil {
+			return skerr.Fmt("No alert found with ID %q", alertID)
+		}
+	}
+
+	perfGit, err := builders.NewPerfGitFromConfig(ctx, local, instanceConfig)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	store, err := builders.NewTraceStoreFromConfig(ctx, local, instanceConfig)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	regressionStore, err := getRegressionStore(ctx, local, instanceConfig)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	tileNumber, err := store.GetLatestTile(ctx)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	ps, err := store.GetParamSet(ctx, tileNumber)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+
+	dfBuilder := dfbuilder.NewDataFrameBuilderFromTraceStore(perfGit, store, backtestNumPreflightTiles, dfbuilder.Filtering(instanceConfig.FilterParentTraces), nil)
+	b := backtest.New(perfGit, dfBuilder, regressionStore, instanceConfig.AnomalyConfig)
+	res, err := b.Run(ctx, alert, alertID, begin, end, int32(tolerance), ps)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+
+	write := func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+	if outputFile != "" {
+		return util.WithWriteFile(outputFile, write)
+	}
+	return write(os.Stdout)
+}
+
 func getRegressionStore(ctx context.Context, local bool, instanceConfig *config.InstanceConfig) (regression.Store, error) {
 	alertStore, err := builders.NewAlertStoreFromConfig(ctx, local, instanceConfig)
 	if err != nil {
@@ -948,5 +1201,20 @@ func getRegressionStore(ctx context.Context, local bool, instanceConfig *config.
 	return regressionStore, nil
 }
 
+// SheriffValidate validates a sheriff config file in prototext format, without
+// needing access to LUCI Config or the database.
+func (app) SheriffValidate(inputFile string) error {
+	b, err := os.ReadFile(inputFile)
+	if err != nil {
+		return skerr.Wrapf(err, "Failed to read sheriff config %q", inputFile)
+	}
+	if err := service.ValidateFileContent(b); err != nil {
+		// Unwrap the error since this gets printed as a user facing error message.
+		return fmt.Errorf("Validation Failed: %s", skerr.Unwrap(err))
+	}
+	fmt.Println("Sheriff config is valid.")
+	return nil
+}
+
 // Confirm app implements App.
 var _ Application = app{}
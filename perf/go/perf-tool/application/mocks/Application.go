@@ -16,6 +16,24 @@ type Application struct {
 	mock.Mock
 }
 
+// AlertsBacktest provides a mock function with given fields: local, instanceConfig, alertFile, alertID, begin, end, tolerance, outputFile
+func (_m *Application) AlertsBacktest(local bool, instanceConfig *config.InstanceConfig, alertFile string, alertID string, begin types.CommitNumber, end types.CommitNumber, tolerance int, outputFile string) error {
+	ret := _m.Called(local, instanceConfig, alertFile, alertID, begin, end, tolerance, outputFile)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AlertsBacktest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(bool, *config.InstanceConfig, string, string, types.CommitNumber, types.CommitNumber, int, string) error); ok {
+		r0 = rf(local, instanceConfig, alertFile, alertID, begin, end, tolerance, outputFile)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ConfigCreatePubSubTopicsAndSubscriptions provides a mock function with given fields: instanceConfig
 func (_m *Application) ConfigCreatePubSubTopicsAndSubscriptions(instanceConfig *config.InstanceConfig) error {
 	ret := _m.Called(instanceConfig)
@@ -178,6 +196,42 @@ func (_m *Application) IngestValidate(inputFile string, verbose bool) error {
 	return r0
 }
 
+// SheriffValidate provides a mock function with given fields: inputFile
+func (_m *Application) SheriffValidate(inputFile string) error {
+	ret := _m.Called(inputFile)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SheriffValidate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(inputFile)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TilesCompact provides a mock function with given fields: store, instanceConfig, dryrun
+func (_m *Application) TilesCompact(store tracestore.TraceStore, instanceConfig *config.InstanceConfig, dryrun bool) error {
+	ret := _m.Called(store, instanceConfig, dryrun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TilesCompact")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(tracestore.TraceStore, *config.InstanceConfig, bool) error); ok {
+		r0 = rf(store, instanceConfig, dryrun)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // TilesLast provides a mock function with given fields: store
 func (_m *Application) TilesLast(store tracestore.TraceStore) error {
 	ret := _m.Called(store)
@@ -214,17 +268,17 @@ func (_m *Application) TilesList(store tracestore.TraceStore, num int) error {
 	return r0
 }
 
-// TracesExport provides a mock function with given fields: store, queryString, begin, end, outputFile
-func (_m *Application) TracesExport(store tracestore.TraceStore, queryString string, begin types.CommitNumber, end types.CommitNumber, outputFile string) error {
-	ret := _m.Called(store, queryString, begin, end, outputFile)
+// TracesExport provides a mock function with given fields: store, queryString, begin, end, format, outputFile
+func (_m *Application) TracesExport(store tracestore.TraceStore, queryString string, begin types.CommitNumber, end types.CommitNumber, format string, outputFile string) error {
+	ret := _m.Called(store, queryString, begin, end, format, outputFile)
 
 	if len(ret) == 0 {
 		panic("no return value specified for TracesExport")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(tracestore.TraceStore, string, types.CommitNumber, types.CommitNumber, string) error); ok {
-		r0 = rf(store, queryString, begin, end, outputFile)
+	if rf, ok := ret.Get(0).(func(tracestore.TraceStore, string, types.CommitNumber, types.CommitNumber, string, string) error); ok {
+		r0 = rf(store, queryString, begin, end, format, outputFile)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -22,12 +22,14 @@ import (
 
 // flag names
 const (
+	alertIDFlagName          = "alert_id"
 	backupToDateFlagName     = "backup_to_date"
 	beginCommitFlagName      = "begin"
 	configFilenameFlagName   = "config_filename"
 	connectionStringFlagName = "connection_string"
 	dryrunFlagName           = "dryrun"
 	endCommitFlagName        = "end"
+	formatFlagName           = "format"
 	inputFilenameFlagName    = "in"
 	localFlagName            = "local"
 	loggingFlagName          = "logging"
@@ -37,6 +39,7 @@ const (
 	startTimeFlagName        = "start"
 	stopTimeFlagName         = "stop"
 	tileNumberFlagName       = "tile"
+	toleranceFlagName        = "tolerance"
 	trybotFilenameFlagName   = "filename"
 	trybotNumCommitsFlagName = "num"
 	verboseFlagName          = "verbose"
@@ -76,6 +79,24 @@ var inputFilenameFlag = &cli.StringFlag{
 	Required: true,
 }
 
+var optionalInputFilenameFlag = &cli.StringFlag{
+	Name:  inputFilenameFlagName,
+	Value: "",
+	Usage: "The input filename.",
+}
+
+var alertIDFlag = &cli.StringFlag{
+	Name:  alertIDFlagName,
+	Value: "",
+	Usage: "The ID of the alert.",
+}
+
+var toleranceFlag = &cli.IntFlag{
+	Name:  toleranceFlagName,
+	Value: 0,
+	Usage: "The number of commits a found regression may be from a stored regression and still match it.",
+}
+
 var backupToDateFlag = &cli.StringFlag{
 	Name:  backupToDateFlagName,
 	Value: "",
@@ -135,6 +156,12 @@ var endCommitFlag = &cli.Int64Flag{
 	Usage: "The commit number to load data to.",
 }
 
+var formatFlag = &cli.StringFlag{
+	Name:  formatFlagName,
+	Value: application.JSONExportFormat,
+	Usage: "The output format, one of: json, parquet, arrow.",
+}
+
 var startTimeFlag = &cli.StringFlag{
 	Name:  startTimeFlagName,
 	Value: "",
@@ -153,6 +180,12 @@ var dryrunFlag = &cli.BoolFlag{
 	Usage: "Just display the list of files to send.",
 }
 
+var compactDryrunFlag = &cli.BoolFlag{
+	Name:  dryrunFlagName,
+	Value: false,
+	Usage: "Only report how many rows would be removed.",
+}
+
 var loggingFlag = &cli.BoolFlag{
 	Name:  loggingFlagName,
 	Value: false,
@@ -252,6 +285,64 @@ func actualMain(app application.Application) {
 					},
 				},
 			},
+			{
+				Name: "alerts",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "backtest",
+						Usage: "Runs regression detection for an alert over the commits in [--begin, --end] and reports, as JSON, how the regressions found compare to the ones already stored for the alert.",
+						Description: `The alert to run is read as JSON from --in, which allows trying out changes
+to an alert before saving them, or if --in isn't supplied the stored alert
+with the ID --alert_id is used.
+
+The regressions found are compared with the regressions stored for
+--alert_id, or for the id in the --in file if --alert_id isn't supplied.
+Nothing is written to the database.`,
+						Flags: []cli.Flag{
+							localFlag,
+							configFilenameFlag,
+							connectionStringFlag,
+							optionalInputFilenameFlag,
+							alertIDFlag,
+							beginCommitFlag,
+							endCommitFlag,
+							toleranceFlag,
+							optionalOutputFilenameFlag,
+						},
+						Action: func(c *cli.Context) error {
+							instanceConfig, err := instanceConfigFromFlags(c)
+							if err != nil {
+								return skerr.Wrap(err)
+							}
+							return app.AlertsBacktest(
+								c.Bool(localFlagName),
+								instanceConfig,
+								c.String(inputFilenameFlagName),
+								c.String(alertIDFlagName),
+								types.CommitNumber(c.Int64(beginCommitFlagName)),
+								types.CommitNumber(c.Int64(endCommitFlagName)),
+								c.Int(toleranceFlagName),
+								c.String(outputFilenameFlagName))
+						},
+					},
+				},
+			},
+			{
+				Name: "sheriff",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "validate",
+						Usage:     "Validates a sheriff config file in prototext format, without needing access to LUCI Config.",
+						ArgsUsage: "<file>",
+						Action: func(c *cli.Context) error {
+							if c.NArg() != 1 {
+								return fmt.Errorf("Expected exactly one sheriff config file, got %d arguments.", c.NArg())
+							}
+							return app.SheriffValidate(c.Args().First())
+						},
+					},
+				},
+			},
 			{
 				Name: "tiles",
 				Subcommands: []*cli.Command{
@@ -289,6 +380,30 @@ func actualMain(app application.Application) {
 							return app.TilesList(store, c.Int(numTilesListFlagName))
 						},
 					},
+					{
+						Name:  "compact",
+						Usage: "Applies the instance retention policy to the tiles older than the retention period.",
+						Description: `Drops or downsamples the trace data in every tile that falls
+outside the retention period given in the retention_config of the
+instance config, and prints the number of rows removed from each tile.`,
+						Flags: []cli.Flag{
+							localFlag,
+							configFilenameFlag,
+							connectionStringFlag,
+							compactDryrunFlag,
+						},
+						Action: func(c *cli.Context) error {
+							instanceConfig, err := instanceConfigFromFlags(c)
+							if err != nil {
+								return skerr.Wrap(err)
+							}
+							store, err := builders.NewTraceStoreFromConfig(context.Background(), c.Bool(localFlagName), instanceConfig)
+							if err != nil {
+								return skerr.Wrap(err)
+							}
+							return app.TilesCompact(store, instanceConfig, c.Bool(dryrunFlagName))
+						},
+					},
 				},
 			},
 			{
@@ -318,7 +433,7 @@ func actualMain(app application.Application) {
 					},
 					{
 						Name:  "export",
-						Usage: "Writes a JSON files with the traces that match --query for the given range of commits.",
+						Usage: "Writes the traces that match --query for the given range of commits. JSON output only covers the tile that contains --begin, while parquet and arrow output are streamed across every tile in the range, with one row per trace and commit.",
 						Flags: []cli.Flag{
 							localFlag,
 							configFilenameFlag,
@@ -327,6 +442,7 @@ func actualMain(app application.Application) {
 							optionalOutputFilenameFlag,
 							beginCommitFlag,
 							endCommitFlag,
+							formatFlag,
 						},
 						Action: func(c *cli.Context) error {
 							store, err := getStore(c)
@@ -339,6 +455,7 @@ func actualMain(app application.Application) {
 								c.String(queryFlagName),
 								types.CommitNumber(c.Int64(beginCommitFlagName)),
 								types.CommitNumber(c.Int64(endCommitFlagName)),
+								c.String(formatFlagName),
 								c.String(outputFilenameFlagName))
 						},
 					},
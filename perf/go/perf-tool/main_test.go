@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.skia.org/infra/perf/go/config"
 	"go.skia.org/infra/perf/go/perf-tool/application/mocks"
+	"go.skia.org/infra/perf/go/types"
 )
 
 func createInstanceConfigFile(t *testing.T) string {
@@ -44,3 +45,23 @@ func TestActualMain_ConfigCreatePubSubTopics_Success(t *testing.T) {
 	actualMain(app)
 	app.AssertExpectations(t)
 }
+
+func TestActualMain_AlertsBacktest_Success(t *testing.T) {
+	app := &mocks.Application{}
+	app.On("AlertsBacktest", true, mock.AnythingOfType("*config.InstanceConfig"), "", "12", types.CommitNumber(100), types.CommitNumber(200), 2, "").Return(nil)
+
+	filename := createInstanceConfigFile(t)
+
+	os.Args = []string{"perf-tool", "alerts", "backtest", "--config_filename=" + filename, "--alert_id=12", "--begin=100", "--end=200", "--tolerance=2"}
+	actualMain(app)
+	app.AssertExpectations(t)
+}
+
+func TestActualMain_SheriffValidate_Success(t *testing.T) {
+	app := &mocks.Application{}
+	app.On("SheriffValidate", "sheriff.cfg").Return(nil)
+
+	os.Args = []string{"perf-tool", "sheriff", "validate", "sheriff.cfg"}
+	actualMain(app)
+	app.AssertExpectations(t)
+}
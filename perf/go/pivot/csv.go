@@ -0,0 +1,87 @@
+package pivot
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/query"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/vec32"
+	"go.skia.org/infra/perf/go/dataframe"
+)
+
+// WriteCSV writes the DataFrame returned from Pivot for the given Request as
+// CSV.
+//
+// There is one column for each GroupBy key, followed by either one column for
+// each Summary operation, or, if there are no Summary operations, one column
+// for each commit. Rows are sorted by the GroupBy values, with each subtotal
+// row following the rows it summarizes and leaving the columns for the keys
+// it doesn't group by empty. Missing values are written as empty cells.
+func WriteCSV(w io.Writer, req Request, df *dataframe.DataFrame) error {
+	cw := csv.NewWriter(w)
+
+	header := append([]string{}, req.GroupBy...)
+	if len(req.Summary) > 0 {
+		for _, op := range req.Summary {
+			header = append(header, string(op))
+		}
+	} else {
+		for _, col := range df.Header {
+			header = append(header, time.Unix(int64(col.Timestamp), 0).UTC().Format(time.RFC3339))
+		}
+	}
+	if err := cw.Write(header); err != nil {
+		return skerr.Wrap(err)
+	}
+
+	type row struct {
+		params  paramtools.Params
+		traceID string
+	}
+	rows := make([]row, 0, len(df.TraceSet))
+	for traceID := range df.TraceSet {
+		p, err := query.ParseKeyFast(traceID)
+		if err != nil {
+			continue
+		}
+		rows = append(rows, row{params: p, traceID: traceID})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		for _, key := range req.GroupBy {
+			a, aOk := rows[i].params[key]
+			b, bOk := rows[j].params[key]
+			if aOk != bOk {
+				// Subtotals come after the rows they summarize.
+				return aOk
+			}
+			if a != b {
+				return a < b
+			}
+		}
+		return rows[i].traceID < rows[j].traceID
+	})
+
+	for _, r := range rows {
+		line := make([]string, 0, len(header))
+		for _, key := range req.GroupBy {
+			line = append(line, r.params[key])
+		}
+		for _, x := range df.TraceSet[r.traceID] {
+			if x == vec32.MissingDataSentinel {
+				line = append(line, "")
+			} else {
+				line = append(line, strconv.FormatFloat(float64(x), 'g', -1, 32))
+			}
+		}
+		if err := cw.Write(line); err != nil {
+			return skerr.Wrap(err)
+		}
+	}
+	cw.Flush()
+	return skerr.Wrap(cw.Error())
+}
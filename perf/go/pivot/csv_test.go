@@ -0,0 +1,56 @@
+package pivot
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/vec32"
+	"go.skia.org/infra/perf/go/dataframe"
+	"go.skia.org/infra/perf/go/types"
+)
+
+func TestWriteCSV_SummaryWithSubtotals_SubtotalsFollowTheirGroup(t *testing.T) {
+	req := Request{
+		GroupBy:   []string{"arch", "device"},
+		Operation: Sum,
+		Summary:   []Operation{Sum, P50},
+		Subtotals: true,
+	}
+	df, err := Pivot(context.Background(), req, dataframeForTesting())
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, WriteCSV(&b, req, df))
+	assert.Equal(t, `arch,device,sum,p50
+arm,Nexus5,6,2
+arm,Nexus7,60,20
+arm,,66,22
+intel,Nexus5,18,6
+intel,Nexus7,180,60
+intel,,198,66
+`, b.String())
+}
+
+func TestWriteCSV_NoSummary_OneColumnPerCommitAndMissingValuesAreEmpty(t *testing.T) {
+	req := Request{
+		GroupBy:   []string{"arch"},
+		Operation: Sum,
+	}
+	df := dataframe.NewEmpty()
+	df.Header = []*dataframe.ColumnHeader{
+		{Offset: 1, Timestamp: 0},
+		{Offset: 2, Timestamp: 3600},
+	}
+	df.TraceSet = types.TraceSet{
+		",arch=x86,": types.Trace{1.5, vec32.MissingDataSentinel},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, WriteCSV(&b, req, df))
+	assert.Equal(t, `arch,1970-01-01T00:00:00Z,1970-01-01T01:00:00Z
+x86,1.5,
+`, b.String())
+}
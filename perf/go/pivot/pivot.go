@@ -54,6 +54,18 @@
 //
 // Note that muliple Summary operations can be applied, and each one will
 // generate its own column in the resulting TraceSet.
+//
+// When grouping by more than one key the groups can also be treated as a
+// hierarchy by setting Subtotals. For example, grouping by "arch" and then
+// "config" with Subtotals set will return both the ",arch=arm,config=8888,"
+// style traces and also a subtotal trace for each "arch", such as ",arch=arm,",
+// that is calculated by applying the Operation to all the traces in that
+// "arch".
+//
+// Finally, all the results can be expressed as a ratio to a baseline group by
+// setting Baseline. For example, a Baseline of {"arch": "intel"} divides the
+// values of ",arch=arm,config=8888," by the values of
+// ",arch=intel,config=8888,".
 package pivot
 
 import (
@@ -63,6 +75,7 @@ import (
 	"go.skia.org/infra/go/paramtools"
 	"go.skia.org/infra/go/query"
 	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/util"
 	"go.skia.org/infra/go/vec32"
 	"go.skia.org/infra/perf/go/dataframe"
 	"go.skia.org/infra/perf/go/types"
@@ -80,10 +93,21 @@ const (
 	Count Operation = "count"
 	Min   Operation = "min"
 	Max   Operation = "max"
+	P50   Operation = "p50"
+	P90   Operation = "p90"
+	P99   Operation = "p99"
+
+	// TrimmedMean is the mean after discarding the smallest and largest
+	// trimmedMeanFraction of the values.
+	TrimmedMean Operation = "trimmed_mean"
 )
 
 // AllOperations for exporting to TypeScript.
-var AllOperations = []Operation{Sum, Avg, Geo, Std, Count, Min, Max}
+var AllOperations = []Operation{Sum, Avg, Geo, Std, Count, Min, Max, P50, P90, P99, TrimmedMean}
+
+// trimmedMeanFraction is the fraction of values discarded from each end by the
+// TrimmedMean operation.
+const trimmedMeanFraction = 0.1
 
 // Request controls how a pivot is done.
 type Request struct {
@@ -96,6 +120,17 @@ type Request struct {
 	// If Summary is the empty slice then the Summary is commits, i.e. a plot.
 	// otherwise produce one column for each Operation in Summary.
 	Summary []Operation `json:"summary"`
+
+	// If Subtotals is true then in addition to the groups formed from all the
+	// GroupBy keys, also return the groups formed from every prefix of
+	// GroupBy, i.e. a subtotal for each level of the hierarchy.
+	Subtotals bool `json:"subtotals,omitempty"`
+
+	// Baseline, if not empty, identifies the baseline group by a value for one
+	// or more of the GroupBy keys. Every result is then divided by the result
+	// of the group that has the same params, except with the Baseline values
+	// substituted. Groups that don't have a baseline group are dropped.
+	Baseline paramtools.Params `json:"baseline,omitempty"`
 }
 
 type groupByOperation func(types.TraceSet) types.Trace
@@ -117,6 +152,17 @@ func stdDev(a []float32) float32 {
 	return stddev
 }
 
+func percentile(p float64) operationFunctions {
+	return operationFunctions{
+		groupByOperation: func(rows types.TraceSet) types.Trace {
+			return calc.PercentileFuncImpl(rows, p)
+		},
+		summaryOperation: func(a []float32) float32 {
+			return vec32.PercentileE(a, p)
+		},
+	}
+}
+
 // opMap contains all the known operation implementations for both GroupBy and
 // Summary operations. Keeping it in a table like this ensures that we always
 // have both groupBy and summary functions available.
@@ -149,6 +195,22 @@ var opMap map[Operation]operationFunctions = map[Operation]operationFunctions{
 		groupByOperation: calc.MaxFuncImpl,
 		summaryOperation: vec32.Max,
 	},
+	P50: percentile(50),
+	P90: percentile(90),
+	P99: percentile(99),
+	TrimmedMean: {
+		groupByOperation: func(rows types.TraceSet) types.Trace {
+			return calc.TrimmedMeanFuncImpl(rows, trimmedMeanFraction)
+		},
+		summaryOperation: func(a []float32) float32 {
+			return vec32.TrimmedMeanE(a, trimmedMeanFraction)
+		},
+	},
+}
+
+func isValidOperation(op Operation) bool {
+	_, ok := opMap[op]
+	return ok
 }
 
 // Valid returns an error if the Request is not valid.
@@ -157,32 +219,38 @@ func (o Request) Valid() error {
 		return skerr.Fmt("at least one GroupBy value must be supplied.")
 	}
 
-	valid := false
-	for _, op := range AllOperations {
-		if op == o.Operation {
-			valid = true
-			break
-		}
-	}
-	if !valid {
+	if !isValidOperation(o.Operation) {
 		return skerr.Fmt("invalid Operation value: %q", o.Operation)
 	}
 
-	valid = false
 	for _, incomingOp := range o.Summary {
-		for _, op := range AllOperations {
-			if op == incomingOp {
-				valid = true
-				break
-			}
-		}
-		if !valid {
+		if !isValidOperation(incomingOp) {
 			return skerr.Fmt("invalid Summary value: %q", incomingOp)
 		}
 	}
+
+	for key := range o.Baseline {
+		if !util.In(key, o.GroupBy) {
+			return skerr.Fmt("Baseline key %q must also be a GroupBy key.", key)
+		}
+	}
 	return nil
 }
 
+// groupLevels returns the lists of keys to group by, starting with all the
+// GroupBy keys, followed by each shorter prefix of GroupBy if Subtotals are
+// requested.
+func (o Request) groupLevels() [][]string {
+	if !o.Subtotals {
+		return [][]string{o.GroupBy}
+	}
+	ret := make([][]string, 0, len(o.GroupBy))
+	for n := len(o.GroupBy); n > 0; n-- {
+		ret = append(ret, o.GroupBy[:n])
+	}
+	return ret
+}
+
 // Returns nil if a groupBy key is missing from fullKey.
 func groupKeyFromTraceKey(fullKeyAsParam paramtools.Params, groupBy []string) string {
 	ret := paramtools.Params{}
@@ -207,11 +275,53 @@ func Pivot(ctx context.Context, req Request, df *dataframe.DataFrame) (*datafram
 	}
 	ret := dataframe.NewEmpty()
 
+	for _, groupBy := range req.groupLevels() {
+		if err := groupTraces(ctx, req.Operation, groupBy, df, ret.TraceSet); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+	}
+
+	ret.BuildParamSet()
+
+	// Return now if there aren't any Summary operations.
+	if len(req.Summary) == 0 {
+		// Use the original Header from the DataFrame.
+		ret.Header = df.Header
+		return applyBaseline(req.Baseline, ret), nil
+	}
+
+	// Make summary columns.
+	for groupKey, trace := range ret.TraceSet {
+		summaryValues := make(types.Trace, len(req.Summary))
+		for i, op := range req.Summary {
+			summaryValues[i] = opMap[op].summaryOperation(trace)
+		}
+		ret.TraceSet[groupKey] = summaryValues
+		if ctx.Err() != nil {
+			return nil, skerr.Wrap(ctx.Err())
+		}
+
+	}
+
+	// Adjust Header to match the Summary columns.
+	ret.Header = make([]*dataframe.ColumnHeader, len(req.Summary))
+	for i := 0; i < len(req.Summary); i++ {
+		ret.Header[i] = &dataframe.ColumnHeader{
+			Offset: types.CommitNumber(i),
+		}
+	}
+
+	return applyBaseline(req.Baseline, ret), nil
+}
+
+// groupTraces groups all the traces in df by the groupBy keys, applies the
+// Operation to each group, and adds the resulting traces to traceSet.
+func groupTraces(ctx context.Context, op Operation, groupBy []string, df *dataframe.DataFrame, traceSet types.TraceSet) error {
 	// Pre-populate groupedTraceSets with empty types.TraceSet{}s.
 	groupedTraceSets := map[string]types.TraceSet{}
-	cpCh, err := df.ParamSet.CartesianProduct(req.GroupBy)
+	cpCh, err := df.ParamSet.CartesianProduct(groupBy)
 	if err != nil {
-		return nil, skerr.Wrap(err)
+		return skerr.Wrap(err)
 	}
 	for p := range cpCh {
 		groupID, err := query.MakeKeyFast(p)
@@ -228,7 +338,7 @@ func Pivot(ctx context.Context, req Request, df *dataframe.DataFrame) (*datafram
 			continue
 		}
 
-		groupKey := groupKeyFromTraceKey(p, req.GroupBy)
+		groupKey := groupKeyFromTraceKey(p, groupBy)
 
 		// If the trace doesn't fit in any group then ignore it.
 		if groupKey == "" {
@@ -242,41 +352,50 @@ func Pivot(ctx context.Context, req Request, df *dataframe.DataFrame) (*datafram
 		if len(traces) == 0 {
 			continue
 		}
-		ret.TraceSet[groupID] = opMap[req.Operation].groupByOperation(traces)
+		traceSet[groupID] = opMap[op].groupByOperation(traces)
 		if ctx.Err() != nil {
-			return nil, skerr.Wrap(ctx.Err())
+			return skerr.Wrap(ctx.Err())
 		}
 	}
+	return nil
+}
 
-	ret.BuildParamSet()
-
-	// Return now if there aren't any Summary operations.
-	if len(req.Summary) == 0 {
-		// Use the original Header from the DataFrame.
-		ret.Header = df.Header
-		return ret, nil
+// applyBaseline divides every trace in df by the trace of its baseline group,
+// which is found by substituting the baseline values into the trace params.
+// Subtotal traces that don't contain a baseline key are their own baseline.
+// Traces without a baseline trace are removed.
+func applyBaseline(baseline paramtools.Params, df *dataframe.DataFrame) *dataframe.DataFrame {
+	if len(baseline) == 0 {
+		return df
 	}
-
-	// Make summary columns.
-	for groupKey, trace := range ret.TraceSet {
-		summaryValues := make(types.Trace, len(req.Summary))
-		for i, op := range req.Summary {
-			summaryValues[i] = opMap[op].summaryOperation(trace)
+	traceSet := types.TraceSet{}
+	for traceID, trace := range df.TraceSet {
+		p, err := query.ParseKeyFast(traceID)
+		if err != nil {
+			continue
 		}
-		ret.TraceSet[groupKey] = summaryValues
-		if ctx.Err() != nil {
-			return nil, skerr.Wrap(ctx.Err())
+		for key, value := range baseline {
+			if _, ok := p[key]; ok {
+				p[key] = value
+			}
 		}
-
-	}
-
-	// Adjust Header to match the Summary columns.
-	ret.Header = make([]*dataframe.ColumnHeader, len(req.Summary))
-	for i := 0; i < len(req.Summary); i++ {
-		ret.Header[i] = &dataframe.ColumnHeader{
-			Offset: types.CommitNumber(i),
+		baselineID, err := query.MakeKeyFast(p)
+		if err != nil {
+			continue
 		}
+		baselineTrace, ok := df.TraceSet[baselineID]
+		if !ok {
+			continue
+		}
+		ratio := vec32.New(len(trace))
+		for i, x := range trace {
+			if x != vec32.MissingDataSentinel && baselineTrace[i] != vec32.MissingDataSentinel && baselineTrace[i] != 0 {
+				ratio[i] = x / baselineTrace[i]
+			}
+		}
+		traceSet[traceID] = ratio
 	}
-
-	return ret, nil
+	df.TraceSet = traceSet
+	df.BuildParamSet()
+	return df
 }
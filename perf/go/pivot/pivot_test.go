@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/vec32"
 	"go.skia.org/infra/perf/go/dataframe"
 	"go.skia.org/infra/perf/go/types"
 )
@@ -186,3 +187,149 @@ func TestPivot_ContextIsCancelled_ReturnsError(t *testing.T) {
 	_, err := Pivot(ctx, req, df)
 	require.Contains(t, err.Error(), "canceled")
 }
+
+func TestOptionsValid_BaselineKeyNotInGroupBy_ReturnsError(t *testing.T) {
+	assert.Contains(t, Request{
+		GroupBy:   []string{"arch"},
+		Operation: Avg,
+		Baseline:  paramtools.Params{"config": "8888"},
+	}.Valid().Error(), "config")
+}
+
+func TestOptionsValid_SecondSummaryIsBad_ReturnsError(t *testing.T) {
+	assert.Contains(t, Request{
+		GroupBy:   []string{"test"},
+		Operation: Avg,
+		Summary:   []Operation{Avg, badValue},
+	}.Valid().Error(), badValue)
+}
+
+func TestPivot_PercentileOperationWithPercentileSummary_Success(t *testing.T) {
+	req := Request{
+		GroupBy:   []string{"arch"},
+		Operation: P50,
+		Summary:   []Operation{P50, P99},
+	}
+	df := dataframeForTesting()
+	df, err := Pivot(context.Background(), req, df)
+	require.NoError(t, err)
+	require.Equal(t, types.TraceSet{
+		// Most arm values are 0, so per commit medians are {0, 0, 0}.
+		",arch=arm,": types.Trace{0, 0},
+		// Per commit medians are {5.5, 11, 16.5}.
+		",arch=intel,": types.Trace{11, 16.39},
+	}, df.TraceSet)
+}
+
+func TestPivot_TrimmedMeanOperation_Success(t *testing.T) {
+	req := Request{
+		GroupBy:   []string{"arch"},
+		Operation: TrimmedMean,
+	}
+	df := dataframeForTesting()
+	df, err := Pivot(context.Background(), req, df)
+	require.NoError(t, err)
+	// Only 6 traces per arch, so no values are trimmed.
+	require.Equal(t, types.TraceSet{
+		",arch=arm,":   types.Trace{11.0 / 6, 22.0 / 6, 33.0 / 6},
+		",arch=intel,": types.Trace{33.0 / 6, 66.0 / 6, 99.0 / 6},
+	}, df.TraceSet)
+}
+
+func TestPivot_Subtotals_ReturnsGroupsForEachLevel(t *testing.T) {
+	req := Request{
+		GroupBy:   []string{"arch", "device"},
+		Operation: Sum,
+		Subtotals: true,
+	}
+	df := dataframeForTesting()
+	df, err := Pivot(context.Background(), req, df)
+	require.NoError(t, err)
+	require.Equal(t, types.TraceSet{
+		",arch=arm,device=Nexus5,":   types.Trace{1, 2, 3},
+		",arch=intel,device=Nexus5,": types.Trace{3, 6, 9},
+		",arch=arm,device=Nexus7,":   types.Trace{10, 20, 30},
+		",arch=intel,device=Nexus7,": types.Trace{30, 60, 90},
+		",arch=arm,":                 types.Trace{11, 22, 33},
+		",arch=intel,":               types.Trace{33, 66, 99},
+	}, df.TraceSet)
+	require.Equal(t, []string{"Nexus5", "Nexus7"}, df.ParamSet["device"])
+}
+
+func TestPivot_SubtotalsWithSummary_SubtotalsAreCalculatedFromAllTracesInGroup(t *testing.T) {
+	req := Request{
+		GroupBy:   []string{"arch", "device"},
+		Operation: Max,
+		Summary:   []Operation{Max},
+		Subtotals: true,
+	}
+	df := dataframeForTesting()
+	df, err := Pivot(context.Background(), req, df)
+	require.NoError(t, err)
+	assert.Equal(t, types.Trace{30}, df.TraceSet[",arch=arm,"])
+	assert.Equal(t, types.Trace{3}, df.TraceSet[",arch=intel,device=Nexus5,"])
+}
+
+func TestPivot_Baseline_ReturnsRatioToBaselineGroup(t *testing.T) {
+	req := Request{
+		GroupBy:   []string{"arch", "device"},
+		Operation: Sum,
+		Baseline:  paramtools.Params{"arch": "intel"},
+	}
+	df := dataframeForTesting()
+	df, err := Pivot(context.Background(), req, df)
+	require.NoError(t, err)
+	require.Equal(t, types.TraceSet{
+		",arch=arm,device=Nexus5,":   types.Trace{1.0 / 3, 1.0 / 3, 1.0 / 3},
+		",arch=intel,device=Nexus5,": types.Trace{1, 1, 1},
+		",arch=arm,device=Nexus7,":   types.Trace{1.0 / 3, 1.0 / 3, 1.0 / 3},
+		",arch=intel,device=Nexus7,": types.Trace{1, 1, 1},
+	}, df.TraceSet)
+}
+
+func TestPivot_BaselineWithSummary_RatioIsOfSummaryValues(t *testing.T) {
+	req := Request{
+		GroupBy:   []string{"arch"},
+		Operation: Sum,
+		Summary:   []Operation{Sum},
+		Baseline:  paramtools.Params{"arch": "arm"},
+	}
+	df := dataframeForTesting()
+	df, err := Pivot(context.Background(), req, df)
+	require.NoError(t, err)
+	require.Equal(t, types.TraceSet{
+		",arch=arm,":   types.Trace{1},
+		",arch=intel,": types.Trace{3},
+	}, df.TraceSet)
+}
+
+func TestPivot_BaselineGroupDoesNotExist_AllGroupsAreRemoved(t *testing.T) {
+	req := Request{
+		GroupBy:   []string{"arch"},
+		Operation: Sum,
+		Baseline:  paramtools.Params{"arch": "risc-v"},
+	}
+	df := dataframeForTesting()
+	df, err := Pivot(context.Background(), req, df)
+	require.NoError(t, err)
+	require.Empty(t, df.TraceSet)
+}
+
+func TestPivot_BaselineHasZeroAndMissingValues_RatioIsMissing(t *testing.T) {
+	req := Request{
+		GroupBy:   []string{"arch"},
+		Operation: Sum,
+		Baseline:  paramtools.Params{"arch": "arm"},
+	}
+	df := dataframe.NewEmpty()
+	df.TraceSet = types.TraceSet{
+		",arch=arm,":   types.Trace{0, vec32.MissingDataSentinel, 2},
+		",arch=intel,": types.Trace{1, 1, vec32.MissingDataSentinel},
+	}
+	df.Header = []*dataframe.ColumnHeader{{Offset: 0}, {Offset: 1}, {Offset: 2}}
+	df.BuildParamSet()
+	df, err := Pivot(context.Background(), req, df)
+	require.NoError(t, err)
+	e := vec32.MissingDataSentinel
+	require.Equal(t, types.Trace{e, e, e}, df.TraceSet[",arch=intel,"])
+}
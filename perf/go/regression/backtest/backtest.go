@@ -0,0 +1,358 @@
+// Package backtest runs regression detection for an Alert over a historical
+// range of commits and compares the regressions it finds with the ones already
+// in the regression store, so that a proposed Alert or detector change can be
+// evaluated before it is rolled out.
+package backtest
+
+import (
+	"context"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/clustering2"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/dataframe"
+	perfgit "go.skia.org/infra/perf/go/git"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/shortcut"
+	"go.skia.org/infra/perf/go/types"
+)
+
+// Finding is a single regression, either found by the backtest or already in
+// the regression store.
+type Finding struct {
+	CommitNumber types.CommitNumber     `json:"commit_number"`
+	ClusterType  regression.ClusterType `json:"cluster_type"`
+	NumTraces    int                    `json:"num_traces"`
+	StepSize     float32                `json:"step_size"`
+	Regression   float32                `json:"regression"`
+
+	// Status is the triage status of a regression in the store, and is empty
+	// for regressions found by the backtest.
+	Status regression.Status `json:"status,omitempty"`
+}
+
+// Match is a regression found by the backtest that corresponds to one already
+// in the regression store.
+type Match struct {
+	Found    Finding `json:"found"`
+	Existing Finding `json:"existing"`
+}
+
+// Result is the comparison of the regressions found by the backtest with the
+// regressions already in the store.
+//
+// Precision is the fraction of the found regressions that are in the store,
+// and Recall is the fraction of the stored regressions that were found. Both
+// are 1 if there is nothing to compare.
+type Result struct {
+	AlertID   string             `json:"alert_id"`
+	Begin     types.CommitNumber `json:"begin"`
+	End       types.CommitNumber `json:"end"`
+	Tolerance int32              `json:"tolerance"`
+
+	NumFound    int `json:"num_found"`
+	NumExisting int `json:"num_existing"`
+
+	// Matched regressions were both found and are in the store.
+	Matched []Match `json:"matched"`
+
+	// New regressions were found but aren't in the store.
+	New []Finding `json:"new"`
+
+	// Missed regressions are in the store but weren't found.
+	Missed []Finding `json:"missed"`
+
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+
+	// MissedBugs is the number of Missed regressions that were triaged as
+	// Negative, i.e. real bugs.
+	MissedBugs int `json:"missed_bugs"`
+
+	// MatchedFalseAlarms is the number of Matched regressions that were triaged
+	// as Positive, i.e. expected changes that the Alert still reports.
+	MatchedFalseAlarms int `json:"matched_false_alarms"`
+}
+
+// Compare the found regressions with the existing ones. A found regression
+// matches an existing one if they are in the same direction and their commit
+// numbers are no more than tolerance apart. Each existing regression matches
+// at most one found regression.
+func Compare(found, existing []Finding, tolerance int32) *Result {
+	found = sortedFindings(found)
+	existing = sortedFindings(existing)
+	ret := &Result{
+		Tolerance:   tolerance,
+		NumFound:    len(found),
+		NumExisting: len(existing),
+		Matched:     []Match{},
+		New:         []Finding{},
+		Missed:      []Finding{},
+	}
+
+	used := make([]bool, len(existing))
+	for _, f := range found {
+		best := -1
+		for i, e := range existing {
+			if used[i] || e.ClusterType != f.ClusterType {
+				continue
+			}
+			dist := distance(e.CommitNumber, f.CommitNumber)
+			if dist > tolerance {
+				continue
+			}
+			if best == -1 || dist < distance(existing[best].CommitNumber, f.CommitNumber) {
+				best = i
+			}
+		}
+		if best == -1 {
+			ret.New = append(ret.New, f)
+			continue
+		}
+		used[best] = true
+		ret.Matched = append(ret.Matched, Match{Found: f, Existing: existing[best]})
+		if existing[best].Status == regression.Positive {
+			ret.MatchedFalseAlarms++
+		}
+	}
+	for i, e := range existing {
+		if used[i] {
+			continue
+		}
+		ret.Missed = append(ret.Missed, e)
+		if e.Status == regression.Negative {
+			ret.MissedBugs++
+		}
+	}
+
+	ret.Precision = ratio(len(ret.Matched), len(found))
+	ret.Recall = ratio(len(ret.Matched), len(existing))
+	return ret
+}
+
+func distance(a, b types.CommitNumber) int32 {
+	d := int32(a - b)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func ratio(num, denom int) float64 {
+	if denom == 0 {
+		return 1
+	}
+	return float64(num) / float64(denom)
+}
+
+// sortedFindings returns a copy of findings sorted by commit number and then
+// cluster type.
+func sortedFindings(findings []Finding) []Finding {
+	ret := append([]Finding{}, findings...)
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].CommitNumber != ret[j].CommitNumber {
+			return ret[i].CommitNumber < ret[j].CommitNumber
+		}
+		return ret[i].ClusterType < ret[j].ClusterType
+	})
+	return ret
+}
+
+func findingFromCluster(commitNumber types.CommitNumber, clusterType regression.ClusterType, cl *clustering2.ClusterSummary, status regression.Status) Finding {
+	ret := Finding{
+		CommitNumber: commitNumber,
+		ClusterType:  clusterType,
+		NumTraces:    cl.Num,
+		Status:       status,
+	}
+	if ret.NumTraces == 0 {
+		ret.NumTraces = len(cl.Keys)
+	}
+	if cl.StepFit != nil {
+		ret.StepSize = cl.StepFit.StepSize
+		ret.Regression = cl.StepFit.Regression
+	}
+	return ret
+}
+
+// FindingsFromRegressions returns the Findings for the given Alert in the
+// regressions loaded from the regression store.
+func FindingsFromRegressions(regressions map[types.CommitNumber]*regression.AllRegressionsForCommit, alertID string) []Finding {
+	ret := []Finding{}
+	for commitNumber, all := range regressions {
+		reg, ok := all.ByAlertID[alertID]
+		if !ok {
+			continue
+		}
+		if reg.Low != nil {
+			ret = append(ret, findingFromCluster(commitNumber, regression.LowClusterType, reg.Low, reg.LowStatus.Status))
+		}
+		if reg.High != nil {
+			ret = append(ret, findingFromCluster(commitNumber, regression.HighClusterType, reg.High, reg.HighStatus.Status))
+		}
+	}
+	return sortedFindings(ret)
+}
+
+// Backtester runs regression detection over historical data.
+type Backtester struct {
+	perfGit         perfgit.Git
+	dfBuilder       dataframe.DataFrameBuilder
+	regressionStore regression.Store
+	anomalyConfig   config.AnomalyConfig
+}
+
+// New returns a new *Backtester.
+func New(perfGit perfgit.Git, dfBuilder dataframe.DataFrameBuilder, regressionStore regression.Store, anomalyConfig config.AnomalyConfig) *Backtester {
+	return &Backtester{
+		perfGit:         perfGit,
+		dfBuilder:       dfBuilder,
+		regressionStore: regressionStore,
+		anomalyConfig:   anomalyConfig,
+	}
+}
+
+// Detect runs regression detection for the Alert over the commits in [begin,
+// end] and returns what was found. At most one regression per commit and
+// direction is returned, the one with the largest regression factor.
+func (b *Backtester) Detect(ctx context.Context, alert *alerts.Alert, begin, end types.CommitNumber, ps paramtools.ReadOnlyParamSet) ([]Finding, error) {
+	if end < begin {
+		return nil, skerr.Fmt("End commit %d must not come before begin commit %d.", end, begin)
+	}
+	if err := alert.Validate(); err != nil {
+		return nil, skerr.Wrapf(err, "Invalid alert")
+	}
+
+	// The windows centered on begin and end need Radius commits on either
+	// side, so extend the domain to cover them.
+	last, err := b.perfGit.CommitNumberFromTime(ctx, time.Time{})
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to find the most recent commit")
+	}
+	domainEnd := end + types.CommitNumber(alert.Radius)
+	if domainEnd > last {
+		domainEnd = last
+	}
+	endCommit, err := b.perfGit.CommitFromCommitNumber(ctx, domainEnd)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to look up commit %d", domainEnd)
+	}
+
+	req := regression.NewRegressionDetectionRequest()
+	req.Alert = alert
+	req.Domain = types.Domain{
+		N:   int32(end-begin+1) + int32(2*alert.Radius),
+		End: time.Unix(endCommit.Timestamp, 0),
+	}
+
+	type key struct {
+		commitNumber types.CommitNumber
+		clusterType  regression.ClusterType
+	}
+	found := map[key]Finding{}
+	add := func(f Finding) {
+		if f.CommitNumber < begin || f.CommitNumber > end {
+			return
+		}
+		k := key{commitNumber: f.CommitNumber, clusterType: f.ClusterType}
+		if prev, ok := found[k]; ok && math.Abs(float64(prev.Regression)) >= math.Abs(float64(f.Regression)) {
+			return
+		}
+		found[k] = f
+	}
+	processor := func(ctx context.Context, _ *regression.RegressionDetectionRequest, resps []*regression.RegressionDetectionResponse, _ string) {
+		for _, resp := range resps {
+			commit, reg, err := regression.RegressionFromClusterResponse(ctx, resp, alert, b.perfGit)
+			if err != nil {
+				sklog.Errorf("Failed to convert to Regression: %s", err)
+				continue
+			}
+			if reg.Low != nil {
+				add(findingFromCluster(commit.CommitNumber, regression.LowClusterType, reg.Low, ""))
+			}
+			if reg.High != nil {
+				add(findingFromCluster(commit.CommitNumber, regression.HighClusterType, reg.High, ""))
+			}
+		}
+	}
+
+	// Shortcuts for the found clusters aren't needed, so don't write them to
+	// the database.
+	err = regression.ProcessRegressions(ctx, req, processor, b.perfGit, discardShortcutStore{}, nil, b.dfBuilder, ps, regression.ExpandBaseAlertByGroupBy, regression.ContinueOnError, b.anomalyConfig)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to run regression detection")
+	}
+
+	ret := make([]Finding, 0, len(found))
+	for _, f := range found {
+		ret = append(ret, f)
+	}
+	return sortedFindings(ret), nil
+}
+
+// Run the backtest for the Alert over the commits in [begin, end] and compare
+// the results with the regressions stored for the Alert with the ID
+// compareAlertID. See Compare for the meaning of tolerance.
+func (b *Backtester) Run(ctx context.Context, alert *alerts.Alert, compareAlertID string, begin, end types.CommitNumber, tolerance int32, ps paramtools.ReadOnlyParamSet) (*Result, error) {
+	found, err := b.Detect(ctx, alert, begin, end, ps)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	regressions, err := b.regressionStore.Range(ctx, begin, end)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to load existing regressions")
+	}
+	ret := Compare(found, FindingsFromRegressions(regressions, compareAlertID), tolerance)
+	ret.AlertID = compareAlertID
+	ret.Begin = begin
+	ret.End = end
+	return ret, nil
+}
+
+// discardShortcutStore is a shortcut.Store that only computes shortcut IDs and
+// never stores anything.
+type discardShortcutStore struct{}
+
+// Insert implements shortcut.Store.
+func (discardShortcutStore) Insert(ctx context.Context, r io.Reader) (string, error) {
+	return "", skerr.Fmt("Not implemented.")
+}
+
+// InsertShortcut implements shortcut.Store.
+func (discardShortcutStore) InsertShortcut(ctx context.Context, s *shortcut.Shortcut) (string, error) {
+	return shortcut.IDFromKeys(s), nil
+}
+
+// Get implements shortcut.Store.
+func (discardShortcutStore) Get(ctx context.Context, id string) (*shortcut.Shortcut, error) {
+	return nil, skerr.Fmt("Not implemented.")
+}
+
+// GetAll implements shortcut.Store.
+func (discardShortcutStore) GetAll(ctx context.Context) (<-chan *shortcut.Shortcut, error) {
+	return nil, skerr.Fmt("Not implemented.")
+}
+
+// GetMetadata implements shortcut.Store.
+func (discardShortcutStore) GetMetadata(ctx context.Context, id string) (*shortcut.Metadata, error) {
+	return nil, skerr.Fmt("Not implemented.")
+}
+
+// ListUnaccessedSince implements shortcut.Store.
+func (discardShortcutStore) ListUnaccessedSince(ctx context.Context, before time.Time) ([]string, error) {
+	return nil, skerr.Fmt("Not implemented.")
+}
+
+// Delete implements shortcut.Store.
+func (discardShortcutStore) Delete(ctx context.Context, ids []string) error {
+	return skerr.Fmt("Not implemented.")
+}
+
+var _ shortcut.Store = discardShortcutStore{}
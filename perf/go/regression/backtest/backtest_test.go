@@ -0,0 +1,139 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/clustering2"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/stepfit"
+	"go.skia.org/infra/perf/go/types"
+)
+
+func finding(commitNumber types.CommitNumber, clusterType regression.ClusterType, status regression.Status) Finding {
+	return Finding{
+		CommitNumber: commitNumber,
+		ClusterType:  clusterType,
+		Status:       status,
+	}
+}
+
+func TestCompare_NothingFoundOrExisting_PrecisionAndRecallAreOne(t *testing.T) {
+	res := Compare(nil, nil, 0)
+	assert.Equal(t, 1.0, res.Precision)
+	assert.Equal(t, 1.0, res.Recall)
+	assert.Empty(t, res.Matched)
+	assert.Empty(t, res.New)
+	assert.Empty(t, res.Missed)
+}
+
+func TestCompare_ExactMatches_Success(t *testing.T) {
+	found := []Finding{
+		finding(10, regression.HighClusterType, ""),
+		finding(20, regression.LowClusterType, ""),
+		finding(30, regression.HighClusterType, ""),
+	}
+	existing := []Finding{
+		finding(10, regression.HighClusterType, regression.Negative),
+		finding(20, regression.HighClusterType, regression.Negative),
+		finding(40, regression.LowClusterType, regression.Untriaged),
+	}
+	res := Compare(found, existing, 0)
+
+	require.Len(t, res.Matched, 1)
+	assert.Equal(t, types.CommitNumber(10), res.Matched[0].Found.CommitNumber)
+	assert.Equal(t, regression.Negative, res.Matched[0].Existing.Status)
+
+	// A match must be in the same direction.
+	assert.Equal(t, []Finding{found[1], found[2]}, res.New)
+	assert.Equal(t, []Finding{existing[1], existing[2]}, res.Missed)
+
+	assert.Equal(t, 3, res.NumFound)
+	assert.Equal(t, 3, res.NumExisting)
+	assert.InDelta(t, 1.0/3, res.Precision, 1e-9)
+	assert.InDelta(t, 1.0/3, res.Recall, 1e-9)
+	assert.Equal(t, 1, res.MissedBugs)
+	assert.Equal(t, 0, res.MatchedFalseAlarms)
+}
+
+func TestCompare_WithTolerance_MatchesClosestAndOnlyOnce(t *testing.T) {
+	found := []Finding{
+		finding(11, regression.LowClusterType, ""),
+		finding(12, regression.LowClusterType, ""),
+	}
+	existing := []Finding{
+		finding(12, regression.LowClusterType, regression.Positive),
+	}
+	res := Compare(found, existing, 2)
+
+	// The first found regression gets the only existing one, which leaves the
+	// second as new.
+	require.Len(t, res.Matched, 1)
+	assert.Equal(t, types.CommitNumber(11), res.Matched[0].Found.CommitNumber)
+	assert.Equal(t, []Finding{found[1]}, res.New)
+	assert.Empty(t, res.Missed)
+	assert.Equal(t, 0.5, res.Precision)
+	assert.Equal(t, 1.0, res.Recall)
+	assert.Equal(t, 1, res.MatchedFalseAlarms)
+}
+
+func TestCompare_OutsideTolerance_NoMatch(t *testing.T) {
+	res := Compare(
+		[]Finding{finding(10, regression.LowClusterType, "")},
+		[]Finding{finding(13, regression.LowClusterType, regression.Untriaged)},
+		2)
+	assert.Empty(t, res.Matched)
+	assert.Len(t, res.New, 1)
+	assert.Len(t, res.Missed, 1)
+	assert.Equal(t, 0.0, res.Precision)
+	assert.Equal(t, 0.0, res.Recall)
+}
+
+func TestFindingsFromRegressions_OnlyReturnsFindingsForTheAlert(t *testing.T) {
+	cl := &clustering2.ClusterSummary{
+		Keys: []string{",arch=x86,", ",arch=arm,"},
+		StepFit: &stepfit.StepFit{
+			StepSize:   -2,
+			Regression: -5,
+		},
+	}
+	regressions := map[types.CommitNumber]*regression.AllRegressionsForCommit{
+		5: {
+			ByAlertID: map[string]*regression.Regression{
+				"1": {
+					Low:        cl,
+					LowStatus:  regression.TriageStatus{Status: regression.Negative},
+					High:       cl,
+					HighStatus: regression.TriageStatus{Status: regression.Untriaged},
+				},
+				"2": {
+					Low: cl,
+				},
+			},
+		},
+		3: {
+			ByAlertID: map[string]*regression.Regression{
+				"1": {
+					High:       cl,
+					HighStatus: regression.TriageStatus{Status: regression.Positive},
+				},
+			},
+		},
+	}
+	expected := []Finding{
+		{CommitNumber: 3, ClusterType: regression.HighClusterType, NumTraces: 2, StepSize: -2, Regression: -5, Status: regression.Positive},
+		{CommitNumber: 5, ClusterType: regression.HighClusterType, NumTraces: 2, StepSize: -2, Regression: -5, Status: regression.Untriaged},
+		{CommitNumber: 5, ClusterType: regression.LowClusterType, NumTraces: 2, StepSize: -2, Regression: -5, Status: regression.Negative},
+	}
+	assert.Equal(t, expected, FindingsFromRegressions(regressions, "1"))
+}
+
+func TestDetect_EndBeforeBegin_ReturnsError(t *testing.T) {
+	b := New(nil, nil, nil, config.AnomalyConfig{})
+	_, err := b.Detect(context.Background(), alerts.NewConfig(), 10, 5, nil)
+	require.Error(t, err)
+}
@@ -19,14 +19,19 @@ import (
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/sklog"
 	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/clustering2"
 	"go.skia.org/infra/perf/go/config"
 	"go.skia.org/infra/perf/go/dataframe"
 	perfgit "go.skia.org/infra/perf/go/git"
+	"go.skia.org/infra/perf/go/git/provider"
 	"go.skia.org/infra/perf/go/ingestevents"
+	"go.skia.org/infra/perf/go/noise"
 	"go.skia.org/infra/perf/go/notify"
 	"go.skia.org/infra/perf/go/regression"
 	"go.skia.org/infra/perf/go/shortcut"
 	"go.skia.org/infra/perf/go/stepfit"
+	"go.skia.org/infra/perf/go/tracestore"
+	"go.skia.org/infra/perf/go/triagerules"
 	"go.skia.org/infra/perf/go/types"
 	"go.skia.org/infra/perf/go/urlprovider"
 )
@@ -54,16 +59,26 @@ const (
 type Continuous struct {
 	perfGit        perfgit.Git
 	shortcutStore  shortcut.Store
+	noiseStore     noise.Store
 	store          regression.Store
 	provider       alerts.ConfigProvider
 	notifier       notify.Notifier
 	paramsProvider regression.ParamsetProvider
 	urlProvider    urlprovider.URLProvider
 	dfBuilder      dataframe.DataFrameBuilder
+	traceStore     tracestore.TraceStore
 	pollingDelay   time.Duration
 	instanceConfig *config.InstanceConfig
 	flags          *config.FrontendFlags
 
+	// triageEngine auto-triages newly found regressions. May be nil, in which
+	// case no auto-triage is done.
+	triageEngine *triagerules.Engine
+
+	// improvementsSkipped counts the clusters that weren't reported because
+	// they are improvements.
+	improvementsSkipped metrics2.Counter
+
 	mutex   sync.Mutex // Protects current.
 	current *alerts.Alert
 }
@@ -73,33 +88,101 @@ type Continuous struct {
 //	provider - Produces the slice of alerts.Config's that determine the clustering to perform.
 //	numCommits - The number of commits to run the clustering over.
 //	radius - The number of commits on each side of a commit to include when clustering.
+//	traceStore - Supplies the trace metadata used to recognize improvements.
+//	triageEngine - Auto-triages newly found regressions, may be nil.
 func New(
 	perfGit perfgit.Git,
 	shortcutStore shortcut.Store,
+	noiseStore noise.Store,
 	provider alerts.ConfigProvider,
 	store regression.Store,
 	notifier notify.Notifier,
 	paramsProvider regression.ParamsetProvider,
 	urlProvider urlprovider.URLProvider,
 	dfBuilder dataframe.DataFrameBuilder,
+	traceStore tracestore.TraceStore,
 	instanceConfig *config.InstanceConfig,
-	flags *config.FrontendFlags) *Continuous {
+	flags *config.FrontendFlags,
+	triageEngine *triagerules.Engine) *Continuous {
 	return &Continuous{
 		perfGit:        perfGit,
 		store:          store,
 		provider:       provider,
 		notifier:       notifier,
 		shortcutStore:  shortcutStore,
+		noiseStore:     noiseStore,
 		current:        &alerts.Alert{},
 		paramsProvider: paramsProvider,
 		urlProvider:    urlProvider,
 		dfBuilder:      dfBuilder,
+		traceStore:     traceStore,
 		pollingDelay:   pollingClusteringDelay,
 		instanceConfig: instanceConfig,
 		flags:          flags,
+		triageEngine:   triageEngine,
+
+		improvementsSkipped: metrics2.GetCounter("perf_regression_improvements_skipped"),
+	}
+}
+
+// autoTriage applies the triage rules to a newly found regression and stores
+// the resulting triage status. It returns nil if no rule applied.
+func (c *Continuous) autoTriage(ctx context.Context, commitNumber types.CommitNumber, details provider.Commit, cfg *alerts.Alert, cl *clustering2.ClusterSummary, clusterType regression.ClusterType) *triagerules.Result {
+	if c.triageEngine == nil {
+		return nil
+	}
+	res, err := c.triageEngine.Apply(ctx, commitNumber, cfg, details, cl, clusterType)
+	if err != nil {
+		sklog.Errorf("Failed to apply triage rules: %s", err)
+		return nil
+	}
+	if res == nil {
+		return nil
+	}
+	sklog.Infof("Triage rule %q applied to %s regression at %d for alert %q", res.Rule.Name, clusterType, commitNumber, cfg.IDAsString)
+	if clusterType == regression.LowClusterType {
+		err = c.store.TriageLow(ctx, commitNumber, cfg.IDAsString, res.TriageStatus)
+	} else {
+		err = c.store.TriageHigh(ctx, commitNumber, cfg.IDAsString, res.TriageStatus)
+	}
+	if err != nil {
+		sklog.Errorf("Failed to store triage status from triage rule %q: %s", res.Rule.Name, err)
+	}
+	return res
+}
+
+// setPriority sets the priority of the issue filed for a regression if the
+// triage rule that applied to it asks for that.
+func (c *Continuous) setPriority(ctx context.Context, res *triagerules.Result, notificationID string) {
+	if res == nil || res.Rule.Action != triagerules.BumpPriority || notificationID == "" {
+		return
+	}
+	setter, ok := c.notifier.(notify.PrioritySetter)
+	if !ok {
+		sklog.Errorf("Triage rule %q sets priorities but the notifier doesn't support them.", res.Rule.Name)
+		return
+	}
+	if err := setter.SetPriority(ctx, notificationID, res.Rule.Priority); err != nil {
+		sklog.Errorf("Failed to set priority from triage rule %q: %s", res.Rule.Name, err)
 	}
 }
 
+// isImprovement returns true if the step found in the cluster is an
+// improvement according to the improvement direction in the metadata of its
+// traces. If the metadata can't be read then the step is treated as a
+// regression.
+func (c *Continuous) isImprovement(ctx context.Context, cl *clustering2.ClusterSummary) bool {
+	if cl.StepFit == nil {
+		return false
+	}
+	metadata, err := c.traceStore.ReadTraceMetadata(ctx, cl.Keys)
+	if err != nil {
+		sklog.Errorf("Failed to read trace metadata: %s", err)
+		return false
+	}
+	return regression.IsImprovement(cl.Keys, metadata, cl.StepFit.Status)
+}
+
 func (c *Continuous) reportRegressions(ctx context.Context, req *regression.RegressionDetectionRequest, resps []*regression.RegressionDetectionResponse, cfg *alerts.Alert) {
 	key := cfg.IDAsString
 	for _, resp := range resps {
@@ -142,6 +225,14 @@ func (c *Continuous) reportRegressions(ctx context.Context, req *regression.Regr
 				// the detected regression has gone away then also send a
 				// follow-up email.
 
+				// Traces ingested with an improvement direction tell us which
+				// way is better, so don't report improvements as regressions.
+				if c.isImprovement(ctx, cl) {
+					sklog.Infof("Skipping improvement at %s: StepFit: %v AlertID: %s", details.Subject, *cl.StepFit, key)
+					c.improvementsSkipped.Inc(1)
+					continue
+				}
+
 				if cl.StepFit.Status == stepfit.LOW && len(cl.Keys) >= cfg.MinimumNum && (cfg.DirectionAsString == alerts.DOWN || cfg.DirectionAsString == alerts.BOTH) {
 					sklog.Infof("Found Low regression at %s: StepFit: %v Shortcut: %s AlertID: %s req: %#v", details.Subject, *cl.StepFit, cl.Shortcut, c.current.IDAsString, *req)
 
@@ -151,17 +242,20 @@ func (c *Continuous) reportRegressions(ctx context.Context, req *regression.Regr
 						continue
 					}
 					if isNew {
-						notificationID, err := c.notifier.RegressionFound(ctx, details, previousCommitDetails, cfg, cl, resp.Frame)
-						if err != nil {
-							sklog.Errorf("Failed to send notification: %s", err)
-						}
-						cl.NotificationID = notificationID
-
-						if notificationID != "" {
-							_, err := c.store.SetLow(ctx, commitNumber, key, resp.Frame, cl)
+						if res := c.autoTriage(ctx, commitNumber, details, cfg, cl, regression.LowClusterType); res == nil || res.Notify {
+							notificationID, err := c.notifier.RegressionFound(ctx, details, previousCommitDetails, cfg, cl, resp.Frame)
 							if err != nil {
-								sklog.Errorf("save cluster with notification: %s", err)
+								sklog.Errorf("Failed to send notification: %s", err)
+							}
+							cl.NotificationID = notificationID
+
+							if notificationID != "" {
+								_, err := c.store.SetLow(ctx, commitNumber, key, resp.Frame, cl)
+								if err != nil {
+									sklog.Errorf("save cluster with notification: %s", err)
+								}
 							}
+							c.setPriority(ctx, res, notificationID)
 						}
 					}
 				}
@@ -173,17 +267,20 @@ func (c *Continuous) reportRegressions(ctx context.Context, req *regression.Regr
 						continue
 					}
 					if isNew {
-						notificationID, err := c.notifier.RegressionFound(ctx, details, previousCommitDetails, cfg, cl, resp.Frame)
-						if err != nil {
-							sklog.Errorf("Failed to send notification: %s", err)
-						}
-						cl.NotificationID = notificationID
-
-						if notificationID != "" {
-							_, err := c.store.SetHigh(ctx, commitNumber, key, resp.Frame, cl)
+						if res := c.autoTriage(ctx, commitNumber, details, cfg, cl, regression.HighClusterType); res == nil || res.Notify {
+							notificationID, err := c.notifier.RegressionFound(ctx, details, previousCommitDetails, cfg, cl, resp.Frame)
 							if err != nil {
-								sklog.Errorf("save cluster with notification: %s", err)
+								sklog.Errorf("Failed to send notification: %s", err)
+							}
+							cl.NotificationID = notificationID
+
+							if notificationID != "" {
+								_, err := c.store.SetHigh(ctx, commitNumber, key, resp.Frame, cl)
+								if err != nil {
+									sklog.Errorf("save cluster with notification: %s", err)
+								}
 							}
+							c.setPriority(ctx, res, notificationID)
 						}
 					}
 				}
@@ -550,7 +647,7 @@ func (c *Continuous) ProcessAlertConfig(ctx context.Context, cfg *alerts.Alert,
 
 	var err error
 	ctxutil.WithContextTimeout(ctx, config.QueryMaxRunTime, func(ctx context.Context) {
-		err = regression.ProcessRegressions(ctx, req, clusterResponseProcessor, c.perfGit, c.shortcutStore, c.dfBuilder, c.paramsProvider(), expandBaseRequest, regression.ContinueOnError, c.instanceConfig.AnomalyConfig)
+		err = regression.ProcessRegressions(ctx, req, clusterResponseProcessor, c.perfGit, c.shortcutStore, c.noiseStore, c.dfBuilder, c.paramsProvider(), expandBaseRequest, regression.ContinueOnError, c.instanceConfig.AnomalyConfig)
 	})
 	if err != nil {
 		sklog.Warningf("Failed regression detection: Query: %q Error: %s", req.Query, err)
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/metrics2"
 	"go.skia.org/infra/go/paramtools"
 	"go.skia.org/infra/go/testutils"
 	"go.skia.org/infra/perf/go/alerts"
@@ -24,6 +26,10 @@ import (
 	regressionmocks "go.skia.org/infra/perf/go/regression/mocks"
 	shortcutmocks "go.skia.org/infra/perf/go/shortcut/mocks"
 	"go.skia.org/infra/perf/go/stepfit"
+	"go.skia.org/infra/perf/go/tracestore"
+	tracestoremocks "go.skia.org/infra/perf/go/tracestore/mocks"
+	"go.skia.org/infra/perf/go/triagerules"
+	triagerulesmocks "go.skia.org/infra/perf/go/triagerules/mocks"
 	"go.skia.org/infra/perf/go/types"
 	"go.skia.org/infra/perf/go/ui/frame"
 	"golang.org/x/exp/slices"
@@ -169,6 +175,7 @@ type allMocks struct {
 	notifier         *notifymocks.Notifier
 	dataFrameBuilder *mocks.DataFrameBuilder
 	configProvider   *alertconfigmocks.ConfigProvider
+	traceStore       *tracestoremocks.TraceStore
 }
 
 func createArgsForReportRegressions(t *testing.T) (*Continuous, *regression.RegressionDetectionRequest, []*regression.RegressionDetectionResponse, *alerts.Alert, allMocks) {
@@ -181,6 +188,7 @@ func createArgsForReportRegressions(t *testing.T) (*Continuous, *regression.Regr
 		return nil
 	}
 	dfb := mocks.NewDataFrameBuilder(t)
+	ts := tracestoremocks.NewTraceStore(t)
 	i := &config.InstanceConfig{}
 	f := &config.FrontendFlags{}
 
@@ -196,10 +204,13 @@ func createArgsForReportRegressions(t *testing.T) (*Continuous, *regression.Regr
 		notifier:       n,
 		paramsProvider: pp,
 		dfBuilder:      dfb,
+		traceStore:     ts,
 		pollingDelay:   time.Microsecond,
 		instanceConfig: i,
 		flags:          f,
 		current:        &alerts.Alert{},
+
+		improvementsSkipped: metrics2.GetCounter("perf_regression_improvements_skipped"),
 	}
 
 	allMocks := allMocks{
@@ -209,6 +220,7 @@ func createArgsForReportRegressions(t *testing.T) (*Continuous, *regression.Regr
 		notifier:         n,
 		dataFrameBuilder: dfb,
 		configProvider:   cp,
+		traceStore:       ts,
 	}
 
 	return c, req, resp, cfg, allMocks
@@ -272,6 +284,7 @@ func TestReportRegressions_OneNewStepDownRegressionFound_OneRegressionStoredAndN
 
 	// First call to CommitFromCommitNumber is for the previous commit.
 	allMocks.perfGit.On("CommitFromCommitNumber", testutils.AnyContext, types.CommitNumber(1)).Return(previousCommit, nil)
+	allMocks.traceStore.On("ReadTraceMetadata", testutils.AnyContext, mock.Anything).Return(map[string]tracestore.TraceMetadata{}, nil)
 	cfg.DirectionAsString = alerts.DOWN
 
 	// Returns true to indicate that this is a newly found regression. Note that
@@ -339,3 +352,194 @@ func TestTraceIdForIngestEvent_MultipleConfigs_Matching(t *testing.T) {
 	assert.NotNil(t, configTracesMap)
 	assert.Equal(t, 2, len(configTracesMap))
 }
+
+func TestReportRegressions_NewRegressionMatchesIgnoreTriageRule_RegressionTriagedAndNotNotified(t *testing.T) {
+	ctx := context.Background()
+	c, req, resp, cfg, allMocks := createArgsForReportRegressions(t)
+	triageRuleStore := triagerulesmocks.NewStore(t)
+	c.triageEngine = triagerules.NewEngine(triageRuleStore)
+
+	const regressionCommitNumber = types.CommitNumber(2)
+	resp = append(resp, &regression.RegressionDetectionResponse{
+		Frame: &frame.FrameResponse{
+			DataFrame: &dataframe.DataFrame{
+				Header: []*dataframe.ColumnHeader{
+					{Offset: 1},
+					{Offset: regressionCommitNumber},
+				},
+				ParamSet: paramtools.ReadOnlyParamSet{
+					"device_name": []string{"sailfish"},
+				},
+			},
+		},
+		Summary: &clustering2.ClusterSummaries{
+			Clusters: []*clustering2.ClusterSummary{
+				{
+					Keys: []string{
+						",device_name=sailfish,",
+					},
+					StepFit: &stepfit.StepFit{
+						Status: stepfit.LOW,
+					},
+					StepPoint: &dataframe.ColumnHeader{
+						Offset: regressionCommitNumber,
+					},
+				},
+			},
+		},
+	})
+
+	commitAtStep := provider.Commit{
+		Author:  "autoroll@example.com",
+		Subject: "Roll deps.",
+	}
+	allMocks.perfGit.On("CommitFromCommitNumber", testutils.AnyContext, regressionCommitNumber).Return(commitAtStep, nil)
+	allMocks.perfGit.On("CommitFromCommitNumber", testutils.AnyContext, types.CommitNumber(1)).Return(provider.Commit{}, nil)
+	allMocks.traceStore.On("ReadTraceMetadata", testutils.AnyContext, mock.Anything).Return(map[string]tracestore.TraceMetadata{}, nil)
+	cfg.DirectionAsString = alerts.DOWN
+
+	rule := triagerules.NewRule()
+	rule.ID = 1
+	rule.Name = "Ignore rolls"
+	rule.AuthorRegex = "^autoroll@"
+	rule.Action = triagerules.Ignore
+	triageRuleStore.On("List", testutils.AnyContext, false).Return([]*triagerules.Rule{rule}, nil)
+	triageRuleStore.On("RecordAudit", testutils.AnyContext, mock.Anything).Return(nil)
+
+	// Only called once since no notification is sent.
+	allMocks.regressionStore.On("SetLow", testutils.AnyContext, regressionCommitNumber, cfg.IDAsString, resp[0].Frame, resp[0].Summary.Clusters[0]).Return(true, nil).Once()
+	allMocks.regressionStore.On("TriageLow", testutils.AnyContext, regressionCommitNumber, cfg.IDAsString, rule.TriageStatus()).Return(nil)
+
+	c.reportRegressions(ctx, req, resp, cfg)
+
+	allMocks.notifier.AssertNotCalled(t, "RegressionFound")
+	require.Empty(t, resp[0].Summary.Clusters[0].NotificationID)
+}
+
+// prioritySettingNotifier is a Notifier that also records the priorities set
+// through notify.PrioritySetter.
+type prioritySettingNotifier struct {
+	*notifymocks.Notifier
+	threadingReference string
+	priority           int
+}
+
+func (p *prioritySettingNotifier) SetPriority(ctx context.Context, threadingReference string, priority int) error {
+	p.threadingReference = threadingReference
+	p.priority = priority
+	return nil
+}
+
+func TestReportRegressions_NewRegressionMatchesBumpPriorityTriageRule_RegressionNotifiedAndPrioritySet(t *testing.T) {
+	ctx := context.Background()
+	c, req, resp, cfg, allMocks := createArgsForReportRegressions(t)
+	n := &prioritySettingNotifier{Notifier: allMocks.notifier}
+	c.notifier = n
+	triageRuleStore := triagerulesmocks.NewStore(t)
+	c.triageEngine = triagerules.NewEngine(triageRuleStore)
+
+	const regressionCommitNumber = types.CommitNumber(2)
+	resp = append(resp, &regression.RegressionDetectionResponse{
+		Frame: &frame.FrameResponse{
+			DataFrame: &dataframe.DataFrame{
+				Header: []*dataframe.ColumnHeader{
+					{Offset: 1},
+					{Offset: regressionCommitNumber},
+				},
+				ParamSet: paramtools.ReadOnlyParamSet{
+					"device_name": []string{"sailfish"},
+				},
+			},
+		},
+		Summary: &clustering2.ClusterSummaries{
+			Clusters: []*clustering2.ClusterSummary{
+				{
+					Keys: []string{
+						",device_name=sailfish,",
+					},
+					StepFit: &stepfit.StepFit{
+						Status: stepfit.LOW,
+					},
+					StepPoint: &dataframe.ColumnHeader{
+						Offset: regressionCommitNumber,
+					},
+				},
+			},
+		},
+	})
+
+	commitAtStep := provider.Commit{
+		Author:  "someone@example.com",
+		Subject: "Change the renderer.",
+	}
+	previousCommit := provider.Commit{}
+	allMocks.perfGit.On("CommitFromCommitNumber", testutils.AnyContext, regressionCommitNumber).Return(commitAtStep, nil)
+	allMocks.perfGit.On("CommitFromCommitNumber", testutils.AnyContext, types.CommitNumber(1)).Return(previousCommit, nil)
+	allMocks.traceStore.On("ReadTraceMetadata", testutils.AnyContext, mock.Anything).Return(map[string]tracestore.TraceMetadata{}, nil)
+	cfg.DirectionAsString = alerts.DOWN
+
+	rule := triagerules.NewRule()
+	rule.ID = 1
+	rule.Name = "Renderer changes are important"
+	rule.SubjectRegex = "renderer"
+	rule.Action = triagerules.BumpPriority
+	rule.Priority = 1
+	triageRuleStore.On("List", testutils.AnyContext, false).Return([]*triagerules.Rule{rule}, nil)
+	triageRuleStore.On("RecordAudit", testutils.AnyContext, mock.Anything).Return(nil)
+
+	const notificationID = "12345"
+	allMocks.regressionStore.On("SetLow", testutils.AnyContext, regressionCommitNumber, cfg.IDAsString, resp[0].Frame, resp[0].Summary.Clusters[0]).Return(true, nil).Twice()
+	allMocks.regressionStore.On("TriageLow", testutils.AnyContext, regressionCommitNumber, cfg.IDAsString, rule.TriageStatus()).Return(nil)
+	allMocks.notifier.On("RegressionFound", ctx, commitAtStep, previousCommit, cfg, resp[0].Summary.Clusters[0], resp[0].Frame).Return(notificationID, nil)
+
+	c.reportRegressions(ctx, req, resp, cfg)
+
+	require.Equal(t, notificationID, resp[0].Summary.Clusters[0].NotificationID)
+	require.Equal(t, notificationID, n.threadingReference)
+	require.Equal(t, 1, n.priority)
+}
+
+func TestReportRegressions_StepDownOnTracesWhereDownIsBetter_NotStoredOrNotified(t *testing.T) {
+	ctx := context.Background()
+	c, req, resp, cfg, allMocks := createArgsForReportRegressions(t)
+
+	const regressionCommitNumber = types.CommitNumber(2)
+	resp = append(resp, &regression.RegressionDetectionResponse{
+		Frame: &frame.FrameResponse{
+			DataFrame: &dataframe.DataFrame{
+				Header: []*dataframe.ColumnHeader{
+					{Offset: 1},
+					{Offset: regressionCommitNumber},
+				},
+				ParamSet: paramtools.ReadOnlyParamSet{
+					"device_name": []string{"sailfish"},
+				},
+			},
+		},
+		Summary: &clustering2.ClusterSummaries{
+			Clusters: []*clustering2.ClusterSummary{
+				{
+					Keys: []string{
+						",device_name=sailfish,",
+					},
+					StepFit: &stepfit.StepFit{
+						Status: stepfit.LOW,
+					},
+					StepPoint: &dataframe.ColumnHeader{
+						Offset: regressionCommitNumber,
+					},
+				},
+			},
+		},
+	})
+
+	allMocks.perfGit.On("CommitFromCommitNumber", testutils.AnyContext, regressionCommitNumber).Return(provider.Commit{}, nil)
+	allMocks.perfGit.On("CommitFromCommitNumber", testutils.AnyContext, types.CommitNumber(1)).Return(provider.Commit{}, nil)
+	allMocks.traceStore.On("ReadTraceMetadata", testutils.AnyContext, []string{",device_name=sailfish,"}).Return(map[string]tracestore.TraceMetadata{
+		",device_name=sailfish,": {Unit: "ms", ImprovementDirection: "down"},
+	}, nil)
+	cfg.DirectionAsString = alerts.BOTH
+
+	// The mocks will fail the test if SetLow or RegressionFound are called.
+	c.reportRegressions(ctx, req, resp, cfg)
+}
@@ -18,6 +18,7 @@ import (
 	"go.skia.org/infra/perf/go/dataframe"
 	"go.skia.org/infra/perf/go/dfiter"
 	perfgit "go.skia.org/infra/perf/go/git"
+	"go.skia.org/infra/perf/go/noise"
 	"go.skia.org/infra/perf/go/progress"
 	"go.skia.org/infra/perf/go/shortcut"
 	"go.skia.org/infra/perf/go/types"
@@ -117,6 +118,7 @@ type regressionDetectionProcess struct {
 	iter                      dfiter.DataFrameIterator
 	detectorResponseProcessor DetectorResponseProcessor
 	shortcutStore             shortcut.Store
+	noiseStore                noise.Store
 }
 
 // BaseAlertHandling determines how Alerts should be handled by ProcessRegressions.
@@ -150,6 +152,7 @@ func ProcessRegressions(ctx context.Context,
 	detectorResponseProcessor DetectorResponseProcessor,
 	perfGit perfgit.Git,
 	shortcutStore shortcut.Store,
+	noiseStore noise.Store,
 	dfBuilder dataframe.DataFrameBuilder,
 	ps paramtools.ReadOnlyParamSet,
 	expandBaseRequest BaseAlertHandling,
@@ -195,6 +198,7 @@ func ProcessRegressions(ctx context.Context,
 			perfGit:                   perfGit,
 			detectorResponseProcessor: detectorResponseProcessor,
 			shortcutStore:             shortcutStore,
+			noiseStore:                noiseStore,
 			iter:                      iter,
 		}
 		detectionProcess.iter = iter
@@ -289,6 +293,26 @@ func (p *regressionDetectionProcess) shortcutFromKeys(ctx context.Context, summa
 	return nil
 }
 
+// traceNoise returns the stored noise estimates of the traces in the
+// DataFrame if the Alert uses types.NoiseStep. If the estimates can't be
+// loaded then nil is returned and the noise of each trace is estimated from
+// the trace itself.
+func (p *regressionDetectionProcess) traceNoise(ctx context.Context, df *dataframe.DataFrame) map[string]float32 {
+	if p.request.Alert.Step != types.NoiseStep || p.noiseStore == nil {
+		return nil
+	}
+	traceNames := make([]string, 0, len(df.TraceSet))
+	for traceName := range df.TraceSet {
+		traceNames = append(traceNames, traceName)
+	}
+	ret, err := p.noiseStore.Get(ctx, traceNames)
+	if err != nil {
+		sklog.Warningf("Failed to load noise estimates: %s", err)
+		return nil
+	}
+	return ret
+}
+
 // run does the work in a RegressionDetectionProcess. It does not return until all the
 // work is done or the request failed. Should be run as a Go routine.
 func (p *regressionDetectionProcess) run(ctx context.Context) error {
@@ -337,7 +361,7 @@ func (p *regressionDetectionProcess) run(ctx context.Context) error {
 			p.request.Progress.Message("K", fmt.Sprintf("%d", k))
 			summary, err = clustering2.CalculateClusterSummaries(ctx, df, k, config.MinStdDev, p.detectionProgress, p.request.Alert.Interesting, p.request.Alert.Step)
 		case types.StepFitGrouping:
-			summary, err = StepFit(ctx, df, k, config.MinStdDev, p.detectionProgress, p.request.Alert.Interesting, p.request.Alert.Step)
+			summary, err = StepFit(ctx, df, k, config.MinStdDev, p.detectionProgress, p.request.Alert.Interesting, p.request.Alert.Step, p.traceNoise(ctx, df))
 		default:
 			err = skerr.Fmt("Invalid type of clustering: %s", p.request.Alert.Algo)
 		}
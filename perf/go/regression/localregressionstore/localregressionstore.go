@@ -0,0 +1,276 @@
+// Package localregressionstore implements the regression.Store interface on a
+// localstore.DB.
+package localregressionstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/clustering2"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/types"
+	"go.skia.org/infra/perf/go/ui/frame"
+)
+
+// regressionsTable is the name of the table in the localstore.DB.
+const regressionsTable = "regressions"
+
+// regressionRow is a single stored Regression.
+type regressionRow struct {
+	CommitNumber types.CommitNumber
+	AlertID      int64
+
+	// Regression is the JSON serialized regression.Regression.
+	Regression string
+}
+
+// table is the data stored in the regressions table.
+type table struct {
+	// Rows are keyed by rowKey.
+	Rows map[string]regressionRow
+}
+
+func rowKey(commitNumber types.CommitNumber, alertID int64) string {
+	return fmt.Sprintf("%d-%d", commitNumber, alertID)
+}
+
+// LocalRegressionStore implements the regression.Store interface.
+type LocalRegressionStore struct {
+	db *localstore.DB
+
+	// mutex protects data.
+	mutex sync.Mutex
+	data  table
+
+	regressionFoundCounterLow  metrics2.Counter
+	regressionFoundCounterHigh metrics2.Counter
+}
+
+// New returns a new *LocalRegressionStore.
+func New(db *localstore.DB) (*LocalRegressionStore, error) {
+	ret := &LocalRegressionStore{
+		db: db,
+		data: table{
+			Rows: map[string]regressionRow{},
+		},
+		regressionFoundCounterLow:  metrics2.GetCounter("perf_regression_store_found", map[string]string{"direction": "low"}),
+		regressionFoundCounterHigh: metrics2.GetCounter("perf_regression_store_found", map[string]string{"direction": "high"}),
+	}
+	if err := db.Read(regressionsTable, &ret.data); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return ret, nil
+}
+
+// GetRegressionsBySubName implements the regression.Store interface.
+//
+// Subscriptions are not tracked by this store, so no regressions are
+// returned.
+func (s *LocalRegressionStore) GetRegressionsBySubName(ctx context.Context, sub_name string, limit int, offset int) ([]*regression.Regression, error) {
+	return nil, nil
+}
+
+// Range implements the regression.Store interface.
+func (s *LocalRegressionStore) Range(ctx context.Context, begin, end types.CommitNumber) (map[types.CommitNumber]*regression.AllRegressionsForCommit, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ret := map[types.CommitNumber]*regression.AllRegressionsForCommit{}
+	for _, row := range s.data.Rows {
+		if row.CommitNumber < begin || row.CommitNumber > end {
+			continue
+		}
+		var r regression.Regression
+		if err := json.Unmarshal([]byte(row.Regression), &r); err != nil {
+			return nil, skerr.Wrapf(err, "Failed to decode a single regression in range: %d %d", begin, end)
+		}
+		allForCommit, ok := ret[row.CommitNumber]
+		if !ok {
+			allForCommit = regression.New()
+		}
+		allForCommit.ByAlertID[alerts.IDToString(row.AlertID)] = &r
+		ret[row.CommitNumber] = allForCommit
+	}
+	return ret, nil
+}
+
+// SetHigh implements the regression.Store interface.
+func (s *LocalRegressionStore) SetHigh(ctx context.Context, commitNumber types.CommitNumber, alertID string, df *frame.FrameResponse, high *clustering2.ClusterSummary) (bool, error) {
+	ret := false
+	err := s.readModifyWrite(commitNumber, alertID, false /* mustExist*/, func(r *regression.Regression) {
+		if r.Frame == nil {
+			r.Frame = df
+			ret = true
+		}
+		r.High = high
+		if r.HighStatus.Status == regression.None {
+			r.HighStatus.Status = regression.Untriaged
+		}
+	})
+	s.regressionFoundCounterHigh.Inc(1)
+	return ret, err
+}
+
+// SetLow implements the regression.Store interface.
+func (s *LocalRegressionStore) SetLow(ctx context.Context, commitNumber types.CommitNumber, alertID string, df *frame.FrameResponse, low *clustering2.ClusterSummary) (bool, error) {
+	ret := false
+	err := s.readModifyWrite(commitNumber, alertID, false /* mustExist*/, func(r *regression.Regression) {
+		if r.Frame == nil {
+			r.Frame = df
+			ret = true
+		}
+		r.Low = low
+		if r.LowStatus.Status == regression.None {
+			r.LowStatus.Status = regression.Untriaged
+		}
+	})
+	s.regressionFoundCounterLow.Inc(1)
+	return ret, err
+}
+
+// TriageLow implements the regression.Store interface.
+func (s *LocalRegressionStore) TriageLow(ctx context.Context, commitNumber types.CommitNumber, alertID string, tr regression.TriageStatus) error {
+	return s.readModifyWrite(commitNumber, alertID, true /* mustExist*/, func(r *regression.Regression) {
+		r.LowStatus = tr
+	})
+}
+
+// TriageHigh implements the regression.Store interface.
+func (s *LocalRegressionStore) TriageHigh(ctx context.Context, commitNumber types.CommitNumber, alertID string, tr regression.TriageStatus) error {
+	return s.readModifyWrite(commitNumber, alertID, true /* mustExist*/, func(r *regression.Regression) {
+		r.HighStatus = tr
+	})
+}
+
+// Write implements the regression.Store interface.
+func (s *LocalRegressionStore) Write(ctx context.Context, regressions map[types.CommitNumber]*regression.AllRegressionsForCommit) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for commitNumber, allRegressionsForCommit := range regressions {
+		for alertIDString, reg := range allRegressionsForCommit.ByAlertID {
+			if err := s.setRow(commitNumber, alertIDString, reg); err != nil {
+				return err
+			}
+		}
+	}
+	return skerr.Wrap(s.db.Write(regressionsTable, s.data))
+}
+
+// setRow serializes the Regression into s.data. The caller must hold s.mutex.
+func (s *LocalRegressionStore) setRow(commitNumber types.CommitNumber, alertIDString string, r *regression.Regression) error {
+	if alertIDString == alerts.BadAlertIDAsAsString {
+		return skerr.Fmt("Failed to convert alertIDString %q to an int.", alertIDString)
+	}
+	alertID := alerts.IDAsStringToInt(alertIDString)
+	b, err := json.Marshal(r)
+	if err != nil {
+		return skerr.Wrapf(err, "Failed to serialize regression for alertID: %d  commitNumber=%d", alertID, commitNumber)
+	}
+	s.data.Rows[rowKey(commitNumber, alertID)] = regressionRow{
+		CommitNumber: commitNumber,
+		AlertID:      alertID,
+		Regression:   string(b),
+	}
+	return nil
+}
+
+// readModifyWrite reads the Regression at the given commitNumber and alert id
+// and then calls the given callback, giving the caller a chance to modify the
+// struct, before writing it back to the database.
+//
+// If mustExist is true then the read must be successful, otherwise a new
+// default Regression will be used and stored back to the database after the
+// callback is called.
+func (s *LocalRegressionStore) readModifyWrite(commitNumber types.CommitNumber, alertIDString string, mustExist bool, cb func(r *regression.Regression)) error {
+	if alertIDString == alerts.BadAlertIDAsAsString {
+		return skerr.Fmt("Failed to convert alertIDString %q to an int.", alertIDString)
+	}
+	alertID := alerts.IDAsStringToInt(alertIDString)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r := regression.NewRegression()
+	r.Id = ""
+	row, ok := s.data.Rows[rowKey(commitNumber, alertID)]
+	if ok {
+		if err := json.Unmarshal([]byte(row.Regression), r); err != nil {
+			sklog.Warningf("Failed to deserialize the JSON Regression: %s", err)
+		}
+	} else if mustExist {
+		return skerr.Fmt("Regression doesn't exist.")
+	}
+
+	cb(r)
+
+	if err := s.setRow(commitNumber, alertIDString, r); err != nil {
+		return err
+	}
+	return skerr.Wrap(s.db.Write(regressionsTable, s.data))
+}
+
+// GetByIDs implements the regression.Store interface.
+func (s *LocalRegressionStore) GetByIDs(ctx context.Context, ids []string) ([]*regression.Regression, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	wanted := map[string]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	ret := []*regression.Regression{}
+	for _, row := range s.data.Rows {
+		r := regression.NewRegression()
+		if err := json.Unmarshal([]byte(row.Regression), r); err != nil {
+			return nil, skerr.Wrapf(err, "Failed to decode regression for commitNumber=%d alertID=%d", row.CommitNumber, row.AlertID)
+		}
+		if r.Id == "" || !wanted[r.Id] {
+			continue
+		}
+		r.CommitNumber = row.CommitNumber
+		r.AlertId = row.AlertID
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
+
+// ReferencedShortcuts implements the regression.Store interface.
+func (s *LocalRegressionStore) ReferencedShortcuts(ctx context.Context, ids []string) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	wanted := map[string]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	found := map[string]bool{}
+	for _, row := range s.data.Rows {
+		r := regression.NewRegression()
+		if err := json.Unmarshal([]byte(row.Regression), r); err != nil {
+			return nil, skerr.Wrapf(err, "Failed to decode regression for commitNumber=%d alertID=%d", row.CommitNumber, row.AlertID)
+		}
+		for _, cl := range []*clustering2.ClusterSummary{r.Low, r.High} {
+			if cl != nil && wanted[cl.Shortcut] {
+				found[cl.Shortcut] = true
+			}
+		}
+	}
+	ret := make([]string, 0, len(found))
+	for id := range found {
+		ret = append(ret, id)
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// Confirm that LocalRegressionStore implements regression.Store.
+var _ regression.Store = (*LocalRegressionStore)(nil)
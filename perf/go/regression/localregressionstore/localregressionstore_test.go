@@ -0,0 +1,57 @@
+package localregressionstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/clustering2"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/regression/regressiontest"
+	"go.skia.org/infra/perf/go/types"
+	"go.skia.org/infra/perf/go/ui/frame"
+)
+
+func TestLocalRegressionStore(t *testing.T) {
+	for name, subTest := range regressiontest.SubTests {
+		t.Run(name, func(t *testing.T) {
+			db, err := localstore.New(t.TempDir())
+			require.NoError(t, err)
+			store, err := New(db)
+			require.NoError(t, err)
+			subTest(t, store)
+		})
+	}
+}
+
+func TestLocalRegressionStore_ReopenAndGetByIDs_Success(t *testing.T) {
+	ctx := context.Background()
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	store, err := New(db)
+	require.NoError(t, err)
+
+	_, err = store.SetHigh(ctx, 5, "2", &frame.FrameResponse{}, &clustering2.ClusterSummary{Num: 10})
+	require.NoError(t, err)
+	r := regression.NewRegression()
+	r.Id = "some-id"
+	require.NoError(t, store.Write(ctx, map[types.CommitNumber]*regression.AllRegressionsForCommit{
+		7: {ByAlertID: map[string]*regression.Regression{"3": r}},
+	}))
+
+	// Reopen and confirm everything was persisted.
+	store, err = New(db)
+	require.NoError(t, err)
+	ranges, err := store.Range(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, ranges, 2)
+	assert.Equal(t, regression.Untriaged, ranges[5].ByAlertID["2"].HighStatus.Status)
+
+	regs, err := store.GetByIDs(ctx, []string{"some-id"})
+	require.NoError(t, err)
+	require.Len(t, regs, 1)
+	assert.Equal(t, types.CommitNumber(7), regs[0].CommitNumber)
+	assert.Equal(t, int64(3), regs[0].AlertId)
+}
@@ -111,6 +111,36 @@ func (_m *Store) Range(ctx context.Context, begin types.CommitNumber, end types.
 	return r0, r1
 }
 
+// ReferencedShortcuts provides a mock function with given fields: ctx, ids
+func (_m *Store) ReferencedShortcuts(ctx context.Context, ids []string) ([]string, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReferencedShortcuts")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]string, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []string); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetHigh provides a mock function with given fields: ctx, commitNumber, alertID, df, high
 func (_m *Store) SetHigh(ctx context.Context, commitNumber types.CommitNumber, alertID string, df *frame.FrameResponse, high *clustering2.ClusterSummary) (bool, error) {
 	ret := _m.Called(ctx, commitNumber, alertID, df, high)
@@ -8,6 +8,9 @@ import (
 
 	"github.com/google/uuid"
 	"go.skia.org/infra/perf/go/clustering2"
+	"go.skia.org/infra/perf/go/ingest/format"
+	"go.skia.org/infra/perf/go/stepfit"
+	"go.skia.org/infra/perf/go/tracestore"
 	"go.skia.org/infra/perf/go/types"
 	"go.skia.org/infra/perf/go/ui/frame"
 )
@@ -148,3 +151,28 @@ func (r *Regression) GetClusterTypeAndSummaryAndTriageStatus() (ClusterType, *cl
 		return NoneClusterType, nil, TriageStatus{}
 	}
 }
+
+// IsImprovement returns true if a step with the given status is an improvement
+// for every one of the traces, i.e. every trace has an improvement direction in
+// its metadata and the step goes in that direction. Traces without an
+// improvement direction are never considered improved.
+func IsImprovement(traceIDs []string, metadata map[string]tracestore.TraceMetadata, status stepfit.StepFitStatus) bool {
+	if len(traceIDs) == 0 {
+		return false
+	}
+	for _, traceID := range traceIDs {
+		switch format.ImprovementDirection(metadata[traceID].ImprovementDirection) {
+		case format.ImprovementDirectionUp:
+			if status != stepfit.HIGH {
+				return false
+			}
+		case format.ImprovementDirectionDown:
+			if status != stepfit.LOW {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.skia.org/infra/perf/go/clustering2"
 	"go.skia.org/infra/perf/go/stepfit"
+	"go.skia.org/infra/perf/go/tracestore"
 	"go.skia.org/infra/perf/go/ui/frame"
 )
 
@@ -89,3 +90,25 @@ func TestMerge(t *testing.T) {
 	assert.Equal(t, r.High, clbetter)
 	assert.Equal(t, r.Frame, dfbetter)
 }
+
+func TestIsImprovement(t *testing.T) {
+	up := ",test=fps,"
+	down := ",test=ms,"
+	none := ",test=other,"
+	metadata := map[string]tracestore.TraceMetadata{
+		up:   {Unit: "fps", ImprovementDirection: "up"},
+		down: {Unit: "ms", ImprovementDirection: "down"},
+	}
+
+	assert.True(t, IsImprovement([]string{up}, metadata, stepfit.HIGH))
+	assert.False(t, IsImprovement([]string{up}, metadata, stepfit.LOW))
+	assert.True(t, IsImprovement([]string{down}, metadata, stepfit.LOW))
+	assert.False(t, IsImprovement([]string{down}, metadata, stepfit.HIGH))
+
+	// Every trace must improve.
+	assert.False(t, IsImprovement([]string{up, down}, metadata, stepfit.HIGH))
+	assert.False(t, IsImprovement([]string{up, none}, metadata, stepfit.HIGH))
+	assert.False(t, IsImprovement([]string{none}, metadata, stepfit.HIGH))
+	assert.False(t, IsImprovement([]string{}, metadata, stepfit.HIGH))
+	assert.False(t, IsImprovement([]string{up}, nil, stepfit.HIGH))
+}
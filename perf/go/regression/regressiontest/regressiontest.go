@@ -142,6 +142,22 @@ func Write(t *testing.T, store regression.Store) {
 	assert.Equal(t, reg, ranges[2])
 }
 
+// ReferencedShortcuts tests that the shortcuts of stored regressions are
+// reported as referenced.
+func ReferencedShortcuts(t *testing.T, store regression.Store) {
+	ctx, c := getTestVars()
+
+	df := &frame.FrameResponse{}
+	_, err := store.SetLow(ctx, c, "1", df, &clustering2.ClusterSummary{Num: 50, Shortcut: "Xlow"})
+	require.NoError(t, err)
+	_, err = store.SetHigh(ctx, c, "1", df, &clustering2.ClusterSummary{Num: 50, Shortcut: "Xhigh"})
+	require.NoError(t, err)
+
+	ids, err := store.ReferencedShortcuts(ctx, []string{"Xlow", "Xhigh", "Xunused", "Xlo"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Xlow", "Xhigh"}, ids)
+}
+
 // SubTestFunction is a func we will call to test one aspect of an
 // implementation of regression.Store.
 type SubTestFunction func(t *testing.T, store regression.Store)
@@ -152,4 +168,5 @@ var SubTests = map[string]SubTestFunction{
 	"Range_Exact":                 Range_Exact,
 	"TriageNonExistentRegression": TriageNonExistentRegression,
 	"TestWrite":                   Write,
+	"ReferencedShortcuts":         ReferencedShortcuts,
 }
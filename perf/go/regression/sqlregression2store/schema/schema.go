@@ -47,6 +47,10 @@ type Regression2Schema struct {
 	// Triage message for the regression.
 	TriageMessage string `sql:"triage_message TEXT"`
 
+	// The timestamp when the regression was first triaged as positive or
+	// negative, or NULL if it hasn't been.
+	TriageTime time.Time `sql:"triage_time TIMESTAMPTZ"`
+
 	// Index used to query regressions based on alert id
 	byAlertIdIndex struct{} `sql:"INDEX by_alert_id (alert_id)"`
 
@@ -45,6 +45,8 @@ const (
 	readRange
 	readByIDs
 	readBySubName
+	markTriaged
+	referencedShortcuts
 )
 
 // statementContext provides a struct to expand sql statement templates.
@@ -103,14 +105,62 @@ var statementFormats = map[statementFormat]string{
 		OFFSET
 			$3
 		`,
+	markTriaged: `
+		UPDATE
+			Regressions2
+		SET
+			triage_time=now()
+		WHERE
+			commit_number=$1
+			AND alert_id=$2
+			AND triage_time IS NULL
+			AND triage_status IN ('positive', 'negative')
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					TriageRuleAudit t
+				WHERE
+					t.alert_id=$3
+					AND t.commit_number=Regressions2.commit_number
+					AND t.cluster_type=Regressions2.cluster_type
+					AND t.action IN ('ignore', 'bug')
+			)
+		`,
+	referencedShortcuts: `
+		SELECT DISTINCT
+			cluster_summary->>'shortcut'
+		FROM
+			Regressions2
+		WHERE
+			cluster_summary->>'shortcut' = ANY($1)
+		`,
+}
+
+// triageTimeColumn is only written by the markTriaged statement, so that the
+// time of the first triage isn't overwritten when a regression is written
+// back.
+const triageTimeColumn = "triage_time"
+
+// regressions2Columns returns the columns of the Regressions2 table that are
+// read and written as a whole.
+func regressions2Columns() []string {
+	ret := make([]string, 0, len(sql.Regressions2))
+	for _, col := range sql.Regressions2 {
+		if col != triageTimeColumn {
+			ret = append(ret, col)
+		}
+	}
+	return ret
 }
 
 // New returns a new instance of SQLRegression2Store
 func New(db pool.Pool, alertConfigProvider alerts.ConfigProvider) (*SQLRegression2Store, error) {
 	templates := map[statementFormat]string{}
+	columns := regressions2Columns()
 	context := statementContext{
-		Columns:            strings.Join(sql.Regressions2, ","),
-		ValuesPlaceholders: sqlutil.ValuesPlaceholders(len(sql.Regressions2), 1),
+		Columns:            strings.Join(columns, ","),
+		ValuesPlaceholders: sqlutil.ValuesPlaceholders(len(columns), 1),
 	}
 	for key, tmpl := range statementFormats {
 		t, err := template.New("").Parse(tmpl)
@@ -210,10 +260,14 @@ func (s *SQLRegression2Store) TriageLow(ctx context.Context, commitNumber types.
 	// TODO(ashwinpv): This code will update all regressions with the <commit_id, alert_id> pair.
 	// Once we move all the data to the new db, this will need to be updated to take in a specific
 	// regression id and update only that.
-	return s.readModifyWriteCompat(ctx, commitNumber, alertID, true, func(r *regression.Regression) bool {
+	err := s.readModifyWriteCompat(ctx, commitNumber, alertID, true, func(r *regression.Regression) bool {
 		r.LowStatus = tr
 		return true
 	})
+	if err != nil {
+		return err
+	}
+	return s.markTriaged(ctx, commitNumber, alertID)
 }
 
 // TriageHigh implements the regression.Store interface.
@@ -221,10 +275,25 @@ func (s *SQLRegression2Store) TriageHigh(ctx context.Context, commitNumber types
 	// TODO(ashwinpv): This code will update all regressions with the <commit_id, alert_id> pair.
 	// Once we move all the data to the new db, this will need to be updated to take in a specific
 	// regression id and update only that.
-	return s.readModifyWriteCompat(ctx, commitNumber, alertID, true, func(r *regression.Regression) bool {
+	err := s.readModifyWriteCompat(ctx, commitNumber, alertID, true, func(r *regression.Regression) bool {
 		r.HighStatus = tr
 		return true
 	})
+	if err != nil {
+		return err
+	}
+	return s.markTriaged(ctx, commitNumber, alertID)
+}
+
+// markTriaged records the time the regressions for the given commit and alert
+// were first triaged, which is used to measure how long triage takes.
+// Regressions that a triage rule already marked as positive or negative are
+// skipped, since they were never waiting on a person.
+func (s *SQLRegression2Store) markTriaged(ctx context.Context, commitNumber types.CommitNumber, alertIDString string) error {
+	if _, err := s.db.Exec(ctx, s.statements[markTriaged], commitNumber, alerts.IDAsStringToInt(alertIDString), alertIDString); err != nil {
+		return skerr.Wrapf(err, "Failed to record triage time for alertID: %s commitNumber=%d", alertIDString, commitNumber)
+	}
+	return nil
 }
 
 // Write implements the regression.Store interface.
@@ -517,6 +586,27 @@ func isRegressionImprovement(paramset map[string][]string, stepFitStatus stepfit
 	return false
 }
 
+// ReferencedShortcuts implements the regression.Store interface.
+func (s *SQLRegression2Store) ReferencedShortcuts(ctx context.Context, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := s.db.Query(ctx, s.statements[referencedShortcuts], ids)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to query for referenced shortcuts.")
+	}
+	defer rows.Close()
+	ret := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, nil
+}
+
 // Confirm that SQLRegressionStore implements regression.Store.
 var _ regression.Store = (*SQLRegression2Store)(nil)
 
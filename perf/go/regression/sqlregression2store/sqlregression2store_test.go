@@ -293,3 +293,56 @@ func runClusterSummaryAndTriageTest(t *testing.T, isHighRegression bool, alertsP
 		assert.Equal(t, regression.TriageStatus{}, reg.HighStatus)
 	}
 }
+
+func readTriageTime(ctx context.Context, t *testing.T, store *SQLRegression2Store, commitNumber types.CommitNumber) *time.Time {
+	var triageTime *time.Time
+	err := store.db.QueryRow(ctx, "SELECT triage_time FROM Regressions2 WHERE commit_number=$1", commitNumber).Scan(&triageTime)
+	assert.NoError(t, err)
+	return triageTime
+}
+
+func TestTriageHigh_TriageTimeIsOnlySetOnFirstTriage(t *testing.T) {
+	alertsProvider := alerts_mock.NewConfigProvider(t)
+	alertsProvider.On("GetAlertConfig", alertId).Return(&alerts.Alert{
+		IDAsString: "1111",
+		Algo:       types.StepFitGrouping,
+	}, nil)
+	store := setupStore(t, alertsProvider)
+	ctx := context.Background()
+
+	r := generateNewRegression()
+	alertIdStr := alerts.IDToString(r.AlertId)
+	_, err := store.SetHigh(ctx, r.CommitNumber, alertIdStr, r.Frame, r.High)
+	assert.NoError(t, err)
+	assert.Nil(t, readTriageTime(ctx, t, store, r.CommitNumber))
+
+	err = store.TriageHigh(ctx, r.CommitNumber, alertIdStr, regression.TriageStatus{Status: regression.Positive})
+	assert.NoError(t, err)
+	first := readTriageTime(ctx, t, store, r.CommitNumber)
+	assert.NotNil(t, first)
+
+	err = store.TriageHigh(ctx, r.CommitNumber, alertIdStr, regression.TriageStatus{Status: regression.Negative, Message: "changed my mind"})
+	assert.NoError(t, err)
+	assert.Equal(t, first, readTriageTime(ctx, t, store, r.CommitNumber))
+}
+
+func TestTriageHigh_TriagedByRule_TriageTimeIsNotSet(t *testing.T) {
+	alertsProvider := alerts_mock.NewConfigProvider(t)
+	alertsProvider.On("GetAlertConfig", alertId).Return(&alerts.Alert{
+		IDAsString: "1111",
+		Algo:       types.StepFitGrouping,
+	}, nil)
+	store := setupStore(t, alertsProvider)
+	ctx := context.Background()
+
+	r := generateNewRegression()
+	alertIdStr := alerts.IDToString(r.AlertId)
+	_, err := store.SetHigh(ctx, r.CommitNumber, alertIdStr, r.Frame, r.High)
+	assert.NoError(t, err)
+	_, err = store.db.Exec(ctx, `INSERT INTO TriageRuleAudit (rule_id, alert_id, commit_number, cluster_type, action) VALUES (1, $1, $2, 'high', 'bug')`, alertIdStr, r.CommitNumber)
+	assert.NoError(t, err)
+
+	err = store.TriageHigh(ctx, r.CommitNumber, alertIdStr, regression.TriageStatus{Status: regression.Negative, Message: "b/123"})
+	assert.NoError(t, err)
+	assert.Nil(t, readTriageTime(ctx, t, store, r.CommitNumber))
+}
@@ -30,6 +30,7 @@ const (
 	readRange
 	batchReadMigration
 	markMigrated
+	referencedShortcuts
 )
 
 // statementsByDialect holds all the raw SQL statemens used per Dialect of SQL.
@@ -74,6 +75,17 @@ var statements = map[statement]string{
 		WHERE
 			commit_number=$2 AND alert_id=$3
 		`,
+	referencedShortcuts: `
+		SELECT DISTINCT
+			shortcut
+		FROM (
+			SELECT regression::JSONB->'low'->>'shortcut' AS shortcut FROM Regressions
+			UNION ALL
+			SELECT regression::JSONB->'high'->>'shortcut' AS shortcut FROM Regressions
+		)
+		WHERE
+			shortcut = ANY($1)
+		`,
 }
 
 // SQLRegressionStore implements the regression.Store interface.
@@ -330,5 +342,26 @@ func (s *SQLRegressionStore) GetByIDs(ctx context.Context, ids []string) ([]*reg
 	return nil, skerr.Fmt("GetByIDs are not implemented in old version of regression store.")
 }
 
+// ReferencedShortcuts implements the regression.Store interface.
+func (s *SQLRegressionStore) ReferencedShortcuts(ctx context.Context, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := s.db.Query(ctx, statements[referencedShortcuts], ids)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to query for referenced shortcuts.")
+	}
+	defer rows.Close()
+	ret := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, nil
+}
+
 // Confirm that SQLRegressionStore implements regression.Store.
 var _ regression.Store = (*SQLRegressionStore)(nil)
@@ -13,7 +13,10 @@ import (
 )
 
 // StepFit finds regressions by looking at each trace individually and seeing if that looks like a regression.
-func StepFit(ctx context.Context, df *dataframe.DataFrame, k int, stddevThreshold float32, progress clustering2.Progress, interesting float32, stepDetection types.StepDetection) (*clustering2.ClusterSummaries, error) {
+//
+// noise holds the previously estimated noise of each trace, keyed by trace
+// name, which is used by types.NoiseStep. It may be nil.
+func StepFit(ctx context.Context, df *dataframe.DataFrame, k int, stddevThreshold float32, progress clustering2.Progress, interesting float32, stepDetection types.StepDetection, noise map[string]float32) (*clustering2.ClusterSummaries, error) {
 	low := clustering2.NewClusterSummary(ctx)
 	high := clustering2.NewClusterSummary(ctx)
 	// Normalize each trace and then run through stepfit. If interesting then
@@ -25,7 +28,7 @@ func StepFit(ctx context.Context, df *dataframe.DataFrame, k int, stddevThreshol
 			sklog.Infof("stepfit count: %d", count)
 		}
 		var sf *stepfit.StepFit
-		sf = stepfit.GetStepFitAtMid(trace, stddevThreshold, interesting, stepDetection)
+		sf = stepfit.GetStepFitAtMidWithNoise(trace, stddevThreshold, interesting, stepDetection, noise[key])
 
 		isLow := sf.Status == stepfit.LOW
 		isHigh := sf.Status == stepfit.HIGH
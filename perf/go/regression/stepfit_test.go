@@ -56,10 +56,38 @@ func TestStepFit(t *testing.T) {
 	ps.Normalize()
 	df.ParamSet = ps.Freeze()
 
-	sum, err := StepFit(ctx, df, 4, 0.01, nil, 50, types.OriginalStep)
+	sum, err := StepFit(ctx, df, 4, 0.01, nil, 50, types.OriginalStep, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, sum)
 	assert.Equal(t, 1, len(sum.Clusters))
 	assert.Equal(t, df.Header[2], sum.Clusters[0].StepPoint)
 	assert.Equal(t, 2, len(sum.Clusters[0].Keys))
 }
+
+func TestStepFit_NoiseStep_OnlyFlagsTracesWhereStepIsLargeRelativeToTheirNoise(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	df := &dataframe.DataFrame{
+		TraceSet: types.TraceSet{
+			",arch=x86,config=8888,": []float32{1, 1, 2, 2, 2},
+			",arch=arm,config=8888,": []float32{1, 1, 2, 2, 2},
+		},
+		ParamSet: paramtools.NewReadOnlyParamSet(),
+	}
+	for i := 0; i < 5; i++ {
+		df.Header = append(df.Header, &dataframe.ColumnHeader{
+			Offset:    types.CommitNumber(i),
+			Timestamp: dataframe.TimestampSeconds(now.Add(time.Duration(i) * time.Minute).Unix()),
+		})
+	}
+	noise := map[string]float32{
+		",arch=x86,config=8888,": 0.1,
+		",arch=arm,config=8888,": 1,
+	}
+
+	sum, err := StepFit(ctx, df, 4, 0.01, nil, 5, types.NoiseStep, noise)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(sum.Clusters))
+	assert.Equal(t, []string{",arch=x86,config=8888,"}, sum.Clusters[0].Keys)
+	assert.Equal(t, df.Header[2], sum.Clusters[0].StepPoint)
+}
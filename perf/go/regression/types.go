@@ -39,6 +39,11 @@ type Store interface {
 	// Given a list of regression IDs (only in the regression2store),
 	// return a list of regressions.
 	GetByIDs(ctx context.Context, ids []string) ([]*Regression, error)
+
+	// ReferencedShortcuts returns the subset of the given shortcut ids that
+	// are the Shortcut of a stored regression. Alert emails and filed bugs
+	// link to these shortcuts.
+	ReferencedShortcuts(ctx context.Context, ids []string) ([]string, error)
 }
 
 // FullSummary describes a single regression.
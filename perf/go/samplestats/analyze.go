@@ -23,6 +23,12 @@ const (
 	TTest Test = "ttest"
 )
 
+// AllTests is a list of all possible values of type Test.
+var AllTests = []Test{
+	UTest,
+	TTest,
+}
+
 // Config controls the analysis done on the samples.
 type Config struct {
 	// Alpha is the p-value cutoff to report a change as significant. If 0 then
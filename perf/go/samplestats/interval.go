@@ -0,0 +1,36 @@
+package samplestats
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// ConfidenceInterval returns the confidence interval, at the 1-alpha level,
+// for the difference in means of the 'after' and 'before' samples of the
+// given Row, i.e. after-before, using the Welch-Satterthwaite approximation.
+// If alpha is 0 then the default value of 0.05 is used.
+func ConfidenceInterval(alpha float64, row Row) (float64, float64, error) {
+	if alpha == 0 {
+		alpha = defaultAlpha
+	}
+	if alpha <= 0 || alpha >= 1 {
+		return 0, 0, fmt.Errorf("alpha must be in (0, 1), got %g", alpha)
+	}
+	before, after := row.Samples[0], row.Samples[1]
+	n1, n2 := float64(len(before.Values)), float64(len(after.Values))
+	if n1 < 2 || n2 < 2 {
+		return 0, 0, fmt.Errorf("at least two samples are needed on each side, got %d and %d", len(before.Values), len(after.Values))
+	}
+	diff := after.Mean - before.Mean
+	v1 := before.StdDev * before.StdDev / n1
+	v2 := after.StdDev * after.StdDev / n2
+	se := math.Sqrt(v1 + v2)
+	if se == 0 {
+		return diff, diff, nil
+	}
+	dof := (v1 + v2) * (v1 + v2) / (v1*v1/(n1-1) + v2*v2/(n2-1))
+	t := stats.InvCDF(stats.TDist{V: dof})(1 - alpha/2)
+	return diff - t*se, diff + t*se, nil
+}
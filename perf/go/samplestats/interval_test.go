@@ -0,0 +1,58 @@
+package samplestats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/perf/go/ingest/parser"
+)
+
+func analyzeOne(t *testing.T, before, after []float64) Row {
+	res := Analyze(Config{All: true, Test: TTest},
+		map[string]parser.Samples{",name=test1,": {Params: paramtools.Params{"name": "test1"}, Values: before}},
+		map[string]parser.Samples{",name=test1,": {Params: paramtools.Params{"name": "test1"}, Values: after}},
+	)
+	require.Len(t, res.Rows, 1)
+	return res.Rows[0]
+}
+
+func TestConfidenceInterval_SignificantChange_IntervalExcludesZero(t *testing.T) {
+	row := analyzeOne(t, []float64{12, 11, 13, 15}, []float64{2, 1, 3, 5})
+	lo, hi, err := ConfidenceInterval(0, row)
+	require.NoError(t, err)
+	// The difference in means is -10, the standard error is 1.2076 and the
+	// t-distribution has 6 degrees of freedom.
+	assert.InDelta(t, -12.954927, lo, 1e-5)
+	assert.InDelta(t, -7.045073, hi, 1e-5)
+}
+
+func TestConfidenceInterval_NoChange_IntervalContainsZero(t *testing.T) {
+	row := analyzeOne(t, []float64{1, 2, 3, 4}, []float64{4, 3, 2, 1})
+	lo, hi, err := ConfidenceInterval(0.1, row)
+	require.NoError(t, err)
+	assert.Less(t, lo, 0.0)
+	assert.Greater(t, hi, 0.0)
+	assert.InDelta(t, -hi, lo, 1e-9)
+}
+
+func TestConfidenceInterval_NoVariance_IntervalIsTheDifference(t *testing.T) {
+	row := analyzeOne(t, []float64{1, 1}, []float64{3, 3})
+	lo, hi, err := ConfidenceInterval(0, row)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, lo)
+	assert.Equal(t, 2.0, hi)
+}
+
+func TestConfidenceInterval_SingleSample_ReturnsError(t *testing.T) {
+	row := analyzeOne(t, []float64{1, 2, 3}, []float64{3})
+	_, _, err := ConfidenceInterval(0, row)
+	require.Error(t, err)
+}
+
+func TestConfidenceInterval_InvalidAlpha_ReturnsError(t *testing.T) {
+	row := analyzeOne(t, []float64{1, 2, 3}, []float64{3, 4, 5})
+	_, _, err := ConfidenceInterval(1.5, row)
+	require.Error(t, err)
+}
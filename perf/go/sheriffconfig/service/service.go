@@ -1,16 +1,62 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v4"
 	"go.skia.org/infra/go/luciconfig"
+	"go.skia.org/infra/go/metrics2"
 	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/go/sql/pool"
+	"go.skia.org/infra/go/util"
 	"go.skia.org/infra/perf/go/alerts"
 	pb "go.skia.org/infra/perf/go/sheriffconfig/proto/v1"
+	"go.skia.org/infra/perf/go/sheriffconfig/source"
 	"go.skia.org/infra/perf/go/sheriffconfig/validate"
 	"go.skia.org/infra/perf/go/subscription"
+	subscription_pb "go.skia.org/infra/perf/go/subscription/proto/v1"
+	"go.skia.org/infra/perf/go/types"
 )
 
+// patternKeys maps the fields of a Pattern to the trace keys they match.
+var patternKeys = []struct {
+	key   string
+	value func(*pb.Pattern) string
+}{
+	{key: "master", value: (*pb.Pattern).GetMain},
+	{key: "bot", value: (*pb.Pattern).GetBot},
+	{key: "benchmark", value: (*pb.Pattern).GetBenchmark},
+	{key: "test", value: (*pb.Pattern).GetTest},
+	{key: "subtest_1", value: (*pb.Pattern).GetSubtest1},
+	{key: "subtest_2", value: (*pb.Pattern).GetSubtest2},
+	{key: "subtest_3", value: (*pb.Pattern).GetSubtest3},
+}
+
+var steps = map[pb.AnomalyConfig_Step]types.StepDetection{
+	pb.AnomalyConfig_ORIGINAL_STEP:  types.OriginalStep,
+	pb.AnomalyConfig_ABSOLUTE_STEP:  types.AbsoluteStep,
+	pb.AnomalyConfig_CONST_STEP:     types.Const,
+	pb.AnomalyConfig_PERCENT_STEP:   types.PercentStep,
+	pb.AnomalyConfig_COHEN_STEP:     types.CohenStep,
+	pb.AnomalyConfig_MANN_WHITNEY_U: types.MannWhitneyU,
+}
+
+var actions = map[pb.AnomalyConfig_Action]types.AlertAction{
+	pb.AnomalyConfig_NOACTION: types.NoAction,
+	pb.AnomalyConfig_TRIAGE:   types.FileIssue,
+	pb.AnomalyConfig_BISECT:   types.Bisection,
+}
+
+// defaultBugPriorityAndSeverity is used if a subscription doesn't specify a
+// bug priority or severity, i.e. P2 and S2.
+const defaultBugPriorityAndSeverity = 2
+
 // Function to address validation requests.
 // Simply return the validation error, or nil if there's none.
 func ValidateContent(content string) error {
@@ -26,36 +72,66 @@ func ValidateContent(content string) error {
 	return nil
 }
 
+// ValidateFileContent validates the content of a sheriff config file in
+// prototext format, including that every subscription can be converted into
+// Alerts. Returns nil if the config is valid.
+func ValidateFileContent(content []byte) error {
+	config, err := validate.UnmarshalProto(content)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if err := validate.ValidateConfig(config); err != nil {
+		return skerr.Wrap(err)
+	}
+	if _, _, err := processConfig(config, ""); err != nil {
+		return skerr.Wrap(err)
+	}
+	return nil
+}
+
 type sheriffconfigService struct {
+	// db is optional, and if set the subscriptions and alerts are written in a
+	// single transaction.
+	db                  pool.Pool
 	subscriptionStore   subscription.Store
 	alertStore          alerts.Store
 	luciconfigApiClient luciconfig.ApiClient
+
+	importFailures metrics2.Counter
+	imports        metrics2.Counter
 }
 
 // Create new SheriffConfig service.
+//
+// If luciconfigApiClient is nil then a LUCI Config client is created the first
+// time ImportSheriffConfig is called, so the service can also be used in
+// environments without access to LUCI Config.
 func New(ctx context.Context,
+	db pool.Pool,
 	subscriptionStore subscription.Store,
 	alertStore alerts.Store,
 	luciconfigApiClient luciconfig.ApiClient) (*sheriffconfigService, error) {
 
-	if luciconfigApiClient == nil {
-		var err error
-		luciconfigApiClient, err = luciconfig.NewApiClient(ctx)
-		if err != nil {
-			return nil, skerr.Fmt("Failed to create new LUCI Config client: %s.", err)
-		}
-	}
-
 	return &sheriffconfigService{
+		db:                  db,
 		subscriptionStore:   subscriptionStore,
 		alertStore:          alertStore,
 		luciconfigApiClient: luciconfigApiClient,
+		importFailures:      metrics2.GetCounter("perf_sheriffconfig_import_failures"),
+		imports:             metrics2.GetCounter("perf_sheriffconfig_imports"),
 	}, nil
 }
 
 // Fetches specified path config from LUCI Config, transforms it and stores it in the CockroachDB
 // in Subscription and Alert tables.
 func (s *sheriffconfigService) ImportSheriffConfig(ctx context.Context, path string) error {
+	if s.luciconfigApiClient == nil {
+		var err error
+		s.luciconfigApiClient, err = luciconfig.NewApiClient(ctx)
+		if err != nil {
+			return skerr.Fmt("Failed to create new LUCI Config client: %s.", err)
+		}
+	}
 
 	configs, err := s.luciconfigApiClient.GetProjectConfigs(path)
 	if err != nil {
@@ -66,7 +142,8 @@ func (s *sheriffconfigService) ImportSheriffConfig(ctx context.Context, path str
 		return skerr.Fmt("Couldn't find any configs under path: %s,", path)
 	}
 
-	var sheriffconfigs []*pb.SheriffConfig
+	var subscriptions []*subscription_pb.Subscription
+	var saveRequests []*alerts.SaveRequest
 	for _, config := range configs {
 		sheriffconfig, err := validate.DeserializeProto(config.Content)
 		if err != nil {
@@ -76,10 +153,243 @@ func (s *sheriffconfigService) ImportSheriffConfig(ctx context.Context, path str
 		if err != nil {
 			return skerr.Wrap(err)
 		}
-		sheriffconfigs = append(sheriffconfigs, sheriffconfig)
+		subs, reqs, err := processConfig(sheriffconfig, config.Revision)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		subscriptions = append(subscriptions, subs...)
+		saveRequests = append(saveRequests, reqs...)
 	}
 
-	//TODO(eduardoyap): Add logic here to persist validated sheriff configs to database.
+	return s.store(ctx, subscriptions, saveRequests)
+}
 
+// ImportSheriffConfigContent validates the content of a sheriff config file
+// in prototext format, transforms it and stores it in the Subscription and
+// Alert stores. The import is skipped if the revision is the one that was
+// imported last.
+func (s *sheriffconfigService) ImportSheriffConfigContent(ctx context.Context, content []byte, revision string) error {
+	config, err := validate.UnmarshalProto(content)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if err := validate.ValidateConfig(config); err != nil {
+		return skerr.Wrap(err)
+	}
+
+	imported, err := s.importedRevision(ctx)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if imported == revision {
+		sklog.Infof("Sheriff config at revision %s has already been imported.", revision)
+		return nil
+	}
+
+	subscriptions, saveRequests, err := processConfig(config, revision)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+
+	// The config may be going back to an earlier revision, whose
+	// subscriptions are already stored.
+	existing, err := s.subscriptionStore.GetAllSubscriptions(ctx)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	type key struct {
+		name     string
+		revision string
+	}
+	stored := map[key]bool{}
+	for _, sub := range existing {
+		stored[key{name: sub.Name, revision: sub.Revision}] = true
+	}
+	newSubscriptions := make([]*subscription_pb.Subscription, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		if !stored[key{name: sub.Name, revision: sub.Revision}] {
+			newSubscriptions = append(newSubscriptions, sub)
+		}
+	}
+	return s.store(ctx, newSubscriptions, saveRequests)
+}
+
+// WatchSource imports the sheriff config from src every period whenever its
+// content has changed. It does not return until the context is cancelled.
+func (s *sheriffconfigService) WatchSource(ctx context.Context, src source.Source, period time.Duration) {
+	var lastContent []byte
+	util.RepeatCtx(ctx, period, func(ctx context.Context) {
+		content, revision, err := src.Read(ctx)
+		if err != nil {
+			s.importFailures.Inc(1)
+			sklog.Errorf("Failed to read sheriff config: %s", err)
+			return
+		}
+		if lastContent != nil && bytes.Equal(content, lastContent) {
+			return
+		}
+		if err := s.ImportSheriffConfigContent(ctx, content, revision); err != nil {
+			s.importFailures.Inc(1)
+			sklog.Errorf("Failed to import sheriff config at revision %s: %s", revision, err)
+			return
+		}
+		s.imports.Inc(1)
+		lastContent = content
+	})
+}
+
+// importedRevision returns the revision of the sheriff config that was
+// imported last, which is the revision of the active subscription Alerts, or
+// "" if there are none.
+func (s *sheriffconfigService) importedRevision(ctx context.Context) (string, error) {
+	configs, err := s.alertStore.List(ctx, false)
+	if err != nil {
+		return "", skerr.Wrapf(err, "Failed to list alerts")
+	}
+	for _, cfg := range configs {
+		if cfg.SubscriptionName != "" {
+			return cfg.SubscriptionRevision, nil
+		}
+	}
+	return "", nil
+}
+
+// store writes the subscriptions and replaces all the subscription Alerts,
+// in a single transaction if the service has a db.
+func (s *sheriffconfigService) store(ctx context.Context, subscriptions []*subscription_pb.Subscription, saveRequests []*alerts.SaveRequest) error {
+	var tx pgx.Tx
+	if s.db != nil {
+		var err error
+		tx, err = s.db.Begin(ctx)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+	}
+
+	if err := s.subscriptionStore.InsertSubscriptions(ctx, subscriptions, tx); err != nil {
+		rollback(ctx, tx)
+		return skerr.Wrapf(err, "Failed to insert subscriptions")
+	}
+	if err := s.alertStore.ReplaceAll(ctx, saveRequests, tx); err != nil {
+		rollback(ctx, tx)
+		return skerr.Wrapf(err, "Failed to replace alerts")
+	}
+
+	if tx != nil {
+		return skerr.Wrap(tx.Commit(ctx))
+	}
 	return nil
 }
+
+func rollback(ctx context.Context, tx pgx.Tx) {
+	if tx == nil {
+		return
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		sklog.Errorf("Failed on rollback: %s", err)
+	}
+}
+
+// processConfig transforms a validated SheriffConfig into the Subscriptions
+// and Alerts to store. One Alert is created for every match pattern of every
+// Anomaly Config.
+func processConfig(config *pb.SheriffConfig, revision string) ([]*subscription_pb.Subscription, []*alerts.SaveRequest, error) {
+	subscriptions := []*subscription_pb.Subscription{}
+	saveRequests := []*alerts.SaveRequest{}
+	for _, sub := range config.Subscriptions {
+		subscriptions = append(subscriptions, makeSubscription(sub, revision))
+		for i, anomalyConfig := range sub.AnomalyConfigs {
+			for j, match := range anomalyConfig.Rules.Match {
+				query, err := buildQuery(match, anomalyConfig.Rules.Exclude)
+				if err != nil {
+					return nil, nil, skerr.Fmt("Error for Subscription %q, Anomaly Config at index %d, Match Pattern at index %d: %s", sub.Name, i, j, skerr.Unwrap(err))
+				}
+				saveRequests = append(saveRequests, &alerts.SaveRequest{
+					Cfg: makeAlert(sub, anomalyConfig, query, revision),
+					SubKey: &alerts.SubKey{
+						SubName:     sub.Name,
+						SubRevision: revision,
+					},
+				})
+			}
+		}
+	}
+	return subscriptions, saveRequests, nil
+}
+
+func makeSubscription(sub *pb.Subscription, revision string) *subscription_pb.Subscription {
+	priority := int32(defaultBugPriorityAndSeverity)
+	if sub.BugPriority != pb.Subscription_P_UNSPECIFIED {
+		priority = int32(sub.BugPriority) - 1
+	}
+	severity := int32(defaultBugPriorityAndSeverity)
+	if sub.BugSeverity != pb.Subscription_S_UNSPECIFIED {
+		severity = int32(sub.BugSeverity) - 1
+	}
+	return &subscription_pb.Subscription{
+		Name:         sub.Name,
+		Revision:     revision,
+		BugLabels:    sub.BugLabels,
+		Hotlists:     sub.HotlistLabels,
+		BugComponent: sub.BugComponent,
+		BugPriority:  priority,
+		BugSeverity:  severity,
+		BugCcEmails:  sub.BugCcEmails,
+		ContactEmail: sub.ContactEmail,
+	}
+}
+
+func makeAlert(sub *pb.Subscription, anomalyConfig *pb.AnomalyConfig, query, revision string) *alerts.Alert {
+	cfg := alerts.NewConfig()
+	cfg.DisplayName = sub.Name
+	cfg.Query = query
+	cfg.Alert = sub.ContactEmail
+	cfg.Owner = sub.ContactEmail
+	cfg.Algo = types.StepFitGrouping
+	cfg.Step = steps[anomalyConfig.Step]
+	cfg.Radius = int(anomalyConfig.Radius)
+	cfg.Interesting = anomalyConfig.Threshold
+	cfg.Action = actions[anomalyConfig.Action]
+	if component, err := strconv.ParseInt(sub.BugComponent, 10, 64); err == nil {
+		cfg.IssueTrackerComponent = alerts.SerializesToString(component)
+	}
+	cfg.SubscriptionName = sub.Name
+	cfg.SubscriptionRevision = revision
+	return cfg
+}
+
+// buildQuery returns the Alert query that selects the traces that match the
+// pattern and none of the exclude patterns.
+//
+// Alert queries can't express a regex exclusion, or an exclusion on a key that
+// is already matched with a regex, so those are reported as errors.
+func buildQuery(match *pb.Pattern, excludes []*pb.Pattern) (string, error) {
+	values := url.Values{}
+	for _, pk := range patternKeys {
+		if value := pk.value(match); value != "" {
+			values.Set(pk.key, value)
+		}
+	}
+	for _, exclude := range excludes {
+		for _, pk := range patternKeys {
+			value := pk.value(exclude)
+			if value == "" {
+				continue
+			}
+			if strings.HasPrefix(value, "~") {
+				return "", skerr.Fmt("Regex exclude patterns are not supported: %s=%s", pk.key, value)
+			}
+			existing, ok := values[pk.key]
+			if !ok || strings.HasPrefix(existing[0], "!") {
+				values.Add(pk.key, "!"+value)
+				continue
+			}
+			if strings.HasPrefix(existing[0], "~") {
+				return "", skerr.Fmt("Can't exclude %s=%s from the regex match %s", pk.key, value, existing[0])
+			}
+			// The key is matched exactly, so excluding another value of it has
+			// no effect.
+		}
+	}
+	return values.Encode(), nil
+}
@@ -2,21 +2,33 @@ package service
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	configApi "go.chromium.org/luci/common/api/luci_config/config/v1"
 	luciconfig_mocks "go.skia.org/infra/go/luciconfig/mocks"
+	"go.skia.org/infra/go/skerr"
+	pool_mocks "go.skia.org/infra/go/sql/pool/mocks"
+	"go.skia.org/infra/go/testutils"
+	"go.skia.org/infra/perf/go/alerts"
 	alert_mocks "go.skia.org/infra/perf/go/alerts/mock"
+	pb "go.skia.org/infra/perf/go/sheriffconfig/proto/v1"
+	"go.skia.org/infra/perf/go/sheriffconfig/validate"
 	subscription_mocks "go.skia.org/infra/perf/go/subscription/mocks"
+	subscription_pb "go.skia.org/infra/perf/go/subscription/proto/v1"
+	"go.skia.org/infra/perf/go/types"
 )
 
 func setUp(ctx context.Context, t *testing.T) (*sheriffconfigService, *subscription_mocks.Store, *alert_mocks.Store, *luciconfig_mocks.ApiClient) {
 	subscriptionStore := new(subscription_mocks.Store)
 	alertStore := new(alert_mocks.Store)
 	luciconfigApiClient := new(luciconfig_mocks.ApiClient)
-	service, err := New(ctx, subscriptionStore, alertStore, luciconfigApiClient)
+	service, err := New(ctx, nil, subscriptionStore, alertStore, luciconfigApiClient)
 	require.NoError(t, err)
 	return service, subscriptionStore, alertStore, luciconfigApiClient
 }
@@ -43,7 +55,7 @@ func TestImportSheriffConfig_InvalidConfig(t *testing.T) {
 func TestImportSheriffConfig_ValidConfig(t *testing.T) {
 	ctx := context.Background()
 
-	service, _, _, apiClient := setUp(ctx, t)
+	service, subscriptionStore, alertStore, apiClient := setUp(ctx, t)
 
 	// Content translates to:
 	// subscriptions {
@@ -58,13 +70,273 @@ func TestImportSheriffConfig_ValidConfig(t *testing.T) {
 	// }
 	mockReturn := []*configApi.LuciConfigGetConfigMultiResponseMessageConfigEntry{
 		{
-			Content: "c3Vic2NyaXB0aW9ucyB7CgluYW1lOiAiYSIKCWNvbnRhY3RfZW1haWw6ICJ0ZXN0QGdvb2dsZS5jb20iCglidWdfY29tcG9uZW50OiAiQT5CPkMiCglhbm9tYWx5X2NvbmZpZ3MgewoJCXJ1bGVzOiB7CgkJCW1hdGNoOiB7bWFpbjogIkNocm9taXVtUGVyZiJ9CgkJfQoJfQp9",
+			Content:  "c3Vic2NyaXB0aW9ucyB7CgluYW1lOiAiYSIKCWNvbnRhY3RfZW1haWw6ICJ0ZXN0QGdvb2dsZS5jb20iCglidWdfY29tcG9uZW50OiAiQT5CPkMiCglhbm9tYWx5X2NvbmZpZ3MgewoJCXJ1bGVzOiB7CgkJCW1hdGNoOiB7bWFpbjogIkNocm9taXVtUGVyZiJ9CgkJfQoJfQp9",
+			Revision: "abcd",
 		},
 	}
 
 	apiClient.On("GetProjectConfigs", "dummy.path").Return(mockReturn, nil)
+	subscriptionStore.On("InsertSubscriptions", testutils.AnyContext, mock.MatchedBy(func(subs []*subscription_pb.Subscription) bool {
+		return len(subs) == 1 && subs[0].Name == "a" && subs[0].Revision == "abcd"
+	}), nil).Return(nil)
+	alertStore.On("ReplaceAll", testutils.AnyContext, mock.MatchedBy(func(reqs []*alerts.SaveRequest) bool {
+		return len(reqs) == 1 && reqs[0].Cfg.Query == "master=ChromiumPerf" && reqs[0].SubKey.SubRevision == "abcd"
+	}), nil).Return(nil)
 
 	err := service.ImportSheriffConfig(ctx, "dummy.path")
 
 	require.NoError(t, err)
 }
+
+const validFileContent = `
+subscriptions {
+	name: "a"
+	contact_email: "test@google.com"
+	bug_component: "1325852"
+	bug_priority: P1
+	anomaly_configs {
+		step: COHEN_STEP
+		radius: 6
+		threshold: 2.5
+		action: BISECT
+		rules: {
+			match: {main: "ChromiumPerf" bot: "~lacros-.*-perf"}
+			match: {main: "ChromiumPerf" benchmark: "Jetstream2"}
+			exclude: {benchmark: "Speedometer2"}
+		}
+	}
+}
+`
+
+func TestProcessConfig_ValidConfig_OneAlertPerMatchPattern(t *testing.T) {
+	config, err := validate.UnmarshalProto([]byte(validFileContent))
+	require.NoError(t, err)
+
+	subs, reqs, err := processConfig(config, "abcd")
+	require.NoError(t, err)
+
+	assert.Equal(t, []*subscription_pb.Subscription{
+		{
+			Name:         "a",
+			Revision:     "abcd",
+			BugComponent: "1325852",
+			BugPriority:  1,
+			BugSeverity:  2,
+			ContactEmail: "test@google.com",
+		},
+	}, subs)
+
+	require.Len(t, reqs, 2)
+	assert.Equal(t, "benchmark=%21Speedometer2&bot=~lacros-.%2A-perf&master=ChromiumPerf", reqs[0].Cfg.Query)
+	assert.Equal(t, "benchmark=Jetstream2&master=ChromiumPerf", reqs[1].Cfg.Query)
+	cfg := reqs[0].Cfg
+	assert.Equal(t, alerts.BadAlertIDAsAsString, cfg.IDAsString)
+	assert.Equal(t, "a", cfg.DisplayName)
+	assert.Equal(t, "test@google.com", cfg.Alert)
+	assert.Equal(t, types.CohenStep, cfg.Step)
+	assert.Equal(t, types.StepFitGrouping, cfg.Algo)
+	assert.Equal(t, 6, cfg.Radius)
+	assert.Equal(t, float32(2.5), cfg.Interesting)
+	assert.Equal(t, types.Bisection, cfg.Action)
+	assert.Equal(t, alerts.SerializesToString(1325852), cfg.IssueTrackerComponent)
+	assert.Equal(t, "abcd", cfg.SubscriptionRevision)
+	assert.Equal(t, &alerts.SubKey{SubName: "a", SubRevision: "abcd"}, reqs[0].SubKey)
+}
+
+func TestBuildQuery_RegexExclude_ReturnsError(t *testing.T) {
+	_, err := buildQuery(&pb.Pattern{Main: "ChromiumPerf"}, []*pb.Pattern{{Bot: "~lacros.*"}})
+	require.Error(t, err)
+}
+
+func TestBuildQuery_ExcludeFromRegexMatch_ReturnsError(t *testing.T) {
+	_, err := buildQuery(&pb.Pattern{Bot: "~lacros.*"}, []*pb.Pattern{{Bot: "lacros-eve-perf"}})
+	require.Error(t, err)
+}
+
+func TestBuildQuery_ExcludeFromExactMatch_ExcludeIsDropped(t *testing.T) {
+	query, err := buildQuery(&pb.Pattern{Bot: "linux-perf"}, []*pb.Pattern{{Bot: "lacros-eve-perf"}})
+	require.NoError(t, err)
+	assert.Equal(t, "bot=linux-perf", query)
+}
+
+func TestBuildQuery_MultipleExcludesOnSameKey_AllAreExcluded(t *testing.T) {
+	query, err := buildQuery(&pb.Pattern{Main: "ChromiumPerf"}, []*pb.Pattern{{Bot: "a"}, {Bot: "b"}})
+	require.NoError(t, err)
+	assert.Equal(t, "bot=%21a&bot=%21b&master=ChromiumPerf", query)
+}
+
+func TestValidateFileContent_ValidConfig_Success(t *testing.T) {
+	require.NoError(t, ValidateFileContent([]byte(validFileContent)))
+}
+
+func TestValidateFileContent_InvalidConfig_ReturnsError(t *testing.T) {
+	err := ValidateFileContent([]byte(`subscriptions { name: "a" }`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Error for Subscription at index 0:")
+}
+
+// importedAlerts returns the active Alerts of a sheriff config imported at
+// the given revision.
+func importedAlerts(revision string) []*alerts.Alert {
+	return []*alerts.Alert{
+		{DisplayName: "not from a subscription"},
+		{SubscriptionName: "a", SubscriptionRevision: revision},
+	}
+}
+
+func TestImportSheriffConfigContent_NewRevision_StoresSubscriptionsAndAlerts(t *testing.T) {
+	ctx := context.Background()
+	service, subscriptionStore, alertStore, _ := setUp(ctx, t)
+
+	alertStore.On("List", testutils.AnyContext, false).Return(importedAlerts("old"), nil)
+	subscriptionStore.On("GetAllSubscriptions", testutils.AnyContext).Return([]*subscription_pb.Subscription{{Name: "a", Revision: "old"}}, nil)
+	subscriptionStore.On("InsertSubscriptions", testutils.AnyContext, mock.MatchedBy(func(subs []*subscription_pb.Subscription) bool {
+		return len(subs) == 1 && subs[0].Revision == "new"
+	}), nil).Return(nil)
+	alertStore.On("ReplaceAll", testutils.AnyContext, mock.MatchedBy(func(reqs []*alerts.SaveRequest) bool {
+		return len(reqs) == 2
+	}), nil).Return(nil)
+
+	require.NoError(t, service.ImportSheriffConfigContent(ctx, []byte(validFileContent), "new"))
+	subscriptionStore.AssertExpectations(t)
+	alertStore.AssertExpectations(t)
+}
+
+func TestImportSheriffConfigContent_RevisionAlreadyImported_NothingIsStored(t *testing.T) {
+	ctx := context.Background()
+	service, subscriptionStore, alertStore, _ := setUp(ctx, t)
+
+	alertStore.On("List", testutils.AnyContext, false).Return(importedAlerts("abcd"), nil)
+
+	require.NoError(t, service.ImportSheriffConfigContent(ctx, []byte(validFileContent), "abcd"))
+	subscriptionStore.AssertNotCalled(t, "InsertSubscriptions", mock.Anything, mock.Anything, mock.Anything)
+	alertStore.AssertNotCalled(t, "ReplaceAll", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImportSheriffConfigContent_RevertToEarlierRevision_AlertsAreReplacedAndStoredSubscriptionsAreNotInsertedAgain(t *testing.T) {
+	ctx := context.Background()
+	service, subscriptionStore, alertStore, _ := setUp(ctx, t)
+
+	// The config was imported at "abcd" and then at "new", and now goes back
+	// to the content of "abcd".
+	alertStore.On("List", testutils.AnyContext, false).Return(importedAlerts("new"), nil)
+	subscriptionStore.On("GetAllSubscriptions", testutils.AnyContext).Return([]*subscription_pb.Subscription{{Name: "a", Revision: "abcd"}, {Name: "a", Revision: "new"}}, nil)
+	subscriptionStore.On("InsertSubscriptions", testutils.AnyContext, []*subscription_pb.Subscription{}, nil).Return(nil)
+	alertStore.On("ReplaceAll", testutils.AnyContext, mock.MatchedBy(func(reqs []*alerts.SaveRequest) bool {
+		return len(reqs) == 2 && reqs[0].SubKey.SubRevision == "abcd"
+	}), nil).Return(nil)
+
+	require.NoError(t, service.ImportSheriffConfigContent(ctx, []byte(validFileContent), "abcd"))
+	subscriptionStore.AssertExpectations(t)
+	alertStore.AssertExpectations(t)
+}
+
+func TestImportSheriffConfigContent_ReplaceAlertsFails_ReturnsError(t *testing.T) {
+	ctx := context.Background()
+	service, subscriptionStore, alertStore, _ := setUp(ctx, t)
+
+	alertStore.On("List", testutils.AnyContext, false).Return([]*alerts.Alert{}, nil)
+	subscriptionStore.On("GetAllSubscriptions", testutils.AnyContext).Return([]*subscription_pb.Subscription{}, nil)
+	subscriptionStore.On("InsertSubscriptions", testutils.AnyContext, mock.Anything, nil).Return(nil)
+	alertStore.On("ReplaceAll", testutils.AnyContext, mock.Anything, nil).Return(skerr.Fmt("my error"))
+
+	err := service.ImportSheriffConfigContent(ctx, []byte(validFileContent), "abcd")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to replace alerts")
+}
+
+// fakeTx is a pgx.Tx that records whether it was committed or rolled back.
+type fakeTx struct {
+	pgx.Tx
+	committed  bool
+	rolledBack bool
+}
+
+func (f *fakeTx) Commit(ctx context.Context) error {
+	f.committed = true
+	return nil
+}
+
+func (f *fakeTx) Rollback(ctx context.Context) error {
+	f.rolledBack = true
+	return nil
+}
+
+func TestImportSheriffConfigContent_WithDB_BothWritesUseOneTransaction(t *testing.T) {
+	ctx := context.Background()
+	_, subscriptionStore, alertStore, _ := setUp(ctx, t)
+	tx := &fakeTx{}
+	db := &pool_mocks.Pool{}
+	db.On("Begin", testutils.AnyContext).Return(tx, nil)
+	service, err := New(ctx, db, subscriptionStore, alertStore, nil)
+	require.NoError(t, err)
+
+	alertStore.On("List", testutils.AnyContext, false).Return([]*alerts.Alert{}, nil)
+	subscriptionStore.On("GetAllSubscriptions", testutils.AnyContext).Return([]*subscription_pb.Subscription{}, nil)
+	subscriptionStore.On("InsertSubscriptions", testutils.AnyContext, mock.Anything, tx).Return(nil)
+	alertStore.On("ReplaceAll", testutils.AnyContext, mock.Anything, tx).Return(nil)
+
+	require.NoError(t, service.ImportSheriffConfigContent(ctx, []byte(validFileContent), "abcd"))
+	subscriptionStore.AssertExpectations(t)
+	alertStore.AssertExpectations(t)
+	assert.True(t, tx.committed)
+	assert.False(t, tx.rolledBack)
+}
+
+func TestImportSheriffConfigContent_WithDBAndReplaceAlertsFails_TransactionIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	_, subscriptionStore, alertStore, _ := setUp(ctx, t)
+	tx := &fakeTx{}
+	db := &pool_mocks.Pool{}
+	db.On("Begin", testutils.AnyContext).Return(tx, nil)
+	service, err := New(ctx, db, subscriptionStore, alertStore, nil)
+	require.NoError(t, err)
+
+	alertStore.On("List", testutils.AnyContext, false).Return([]*alerts.Alert{}, nil)
+	subscriptionStore.On("GetAllSubscriptions", testutils.AnyContext).Return([]*subscription_pb.Subscription{}, nil)
+	subscriptionStore.On("InsertSubscriptions", testutils.AnyContext, mock.Anything, tx).Return(nil)
+	alertStore.On("ReplaceAll", testutils.AnyContext, mock.Anything, tx).Return(skerr.Fmt("my error"))
+
+	err = service.ImportSheriffConfigContent(ctx, []byte(validFileContent), "abcd")
+	require.Error(t, err)
+	assert.False(t, tx.committed)
+	assert.True(t, tx.rolledBack)
+}
+
+// fakeSource returns each of its contents in turn, and cancels the context
+// once they have all been returned.
+type fakeSource struct {
+	contents []string
+	calls    int
+	cancel   context.CancelFunc
+}
+
+func (f *fakeSource) Read(ctx context.Context) ([]byte, string, error) {
+	content := f.contents[f.calls]
+	f.calls++
+	if f.calls == len(f.contents) {
+		f.cancel()
+	}
+	return []byte(content), content, nil
+}
+
+func TestWatchSource_ContentChanges_OnlyChangedContentIsImported(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	service, subscriptionStore, alertStore, _ := setUp(ctx, t)
+
+	alertStore.On("List", testutils.AnyContext, false).Return([]*alerts.Alert{}, nil)
+	subscriptionStore.On("GetAllSubscriptions", testutils.AnyContext).Return([]*subscription_pb.Subscription{}, nil)
+	subscriptionStore.On("InsertSubscriptions", testutils.AnyContext, mock.Anything, nil).Return(nil)
+	alertStore.On("ReplaceAll", testutils.AnyContext, mock.Anything, nil).Return(nil)
+
+	changed := strings.Replace(validFileContent, "radius: 6", "radius: 8", 1)
+	src := &fakeSource{
+		contents: []string{validFileContent, validFileContent, changed},
+		cancel:   cancel,
+	}
+	service.WatchSource(ctx, src, time.Millisecond)
+
+	assert.Equal(t, 3, src.calls)
+	subscriptionStore.AssertNumberOfCalls(t, "InsertSubscriptions", 2)
+	alertStore.AssertNumberOfCalls(t, "ReplaceAll", 2)
+}
@@ -0,0 +1,114 @@
+// Package source reads sheriff configs from a local file or from a file in a
+// git repo, for instances that can't reach LUCI Config.
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/git/providers"
+)
+
+// Source is somewhere a sheriff config can be read from.
+type Source interface {
+	// Read returns the current content of the sheriff config, in prototext
+	// format, and the revision it was read at.
+	Read(ctx context.Context) ([]byte, string, error)
+}
+
+// File is a Source that reads a sheriff config from a local file.
+type File struct {
+	path string
+}
+
+// NewFile returns a new *File that reads the sheriff config at path.
+func NewFile(path string) *File {
+	return &File{
+		path: path,
+	}
+}
+
+// Read implements Source. The revision is the SHA-256 hash of the content.
+func (f *File) Read(ctx context.Context) ([]byte, string, error) {
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, "", skerr.Wrapf(err, "Failed to read sheriff config %q", f.path)
+	}
+	return b, fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+// Git is a Source that reads a sheriff config from a file in a git repo.
+type Git struct {
+	gp   provider.Provider
+	path string
+
+	// head is the most recent commit seen in the repo.
+	head string
+}
+
+// NewGit returns a new *Git that reads the file at path in the repo gp.
+func NewGit(gp provider.Provider, path string) *Git {
+	return &Git{
+		gp:   gp,
+		path: path,
+	}
+}
+
+// Read implements Source. The file is read at the most recent commit of the
+// repo, and the revision is the git hash of that commit.
+func (g *Git) Read(ctx context.Context) ([]byte, string, error) {
+	if err := g.gp.Update(ctx); err != nil {
+		return nil, "", skerr.Wrap(err)
+	}
+	err := g.gp.CommitsFromMostRecentGitHashToHead(ctx, g.head, func(c provider.Commit) error {
+		g.head = c.GitHash
+		return nil
+	})
+	if err != nil {
+		return nil, "", skerr.Wrap(err)
+	}
+	if g.head == "" {
+		return nil, "", skerr.Fmt("No commits found in the sheriff config repo.")
+	}
+	b, err := g.gp.ReadFileAtCommit(ctx, g.head, g.path)
+	if err != nil {
+		return nil, "", skerr.Wrapf(err, "Failed to read sheriff config %q at %s", g.path, g.head)
+	}
+	return b, g.head, nil
+}
+
+// New returns the Source described by the SheriffConfigSource of the
+// instanceConfig.
+func New(ctx context.Context, instanceConfig *config.InstanceConfig) (Source, error) {
+	cfg := instanceConfig.SheriffConfigSource
+	if cfg.File != "" {
+		return NewFile(cfg.File), nil
+	}
+	if cfg.URL == "" {
+		return nil, skerr.Fmt("No sheriff config source configured.")
+	}
+
+	// Read the repo with the same kind of Provider as the main repo.
+	repoInstanceConfig := *instanceConfig
+	repoInstanceConfig.GitRepoConfig = config.GitRepoConfig{
+		GitAuthType: instanceConfig.GitRepoConfig.GitAuthType,
+		Provider:    instanceConfig.GitRepoConfig.Provider,
+		URL:         cfg.URL,
+		Dir:         cfg.Dir,
+		Branch:      cfg.Branch,
+	}
+	gp, err := providers.New(ctx, &repoInstanceConfig)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to build provider for sheriff config repo")
+	}
+	return NewGit(gp, cfg.Path), nil
+}
+
+// Confirm that *File and *Git implement Source.
+var _ Source = (*File)(nil)
+var _ Source = (*Git)(nil)
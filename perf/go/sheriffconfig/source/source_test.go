@@ -0,0 +1,141 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/git/provider"
+)
+
+const configContent = `subscriptions { name: "a" }`
+
+// fakeProvider is a provider.Provider for a repo whose commits and file
+// contents are held in memory.
+type fakeProvider struct {
+	commits []string
+
+	// files maps a git hash to the content of the sheriff config file.
+	files map[string]string
+
+	updateCount int
+}
+
+func (f *fakeProvider) CommitsFromMostRecentGitHashToHead(ctx context.Context, mostRecentGitHash string, cb provider.CommitProcessor) error {
+	found := mostRecentGitHash == ""
+	for _, hash := range f.commits {
+		if found {
+			if err := cb(provider.Commit{GitHash: hash}); err != nil {
+				return err
+			}
+		}
+		if hash == mostRecentGitHash {
+			found = true
+		}
+	}
+	return nil
+}
+
+func (f *fakeProvider) GitHashesInRangeForFile(ctx context.Context, begin, end, filename string) ([]string, error) {
+	return nil, skerr.Fmt("not implemented")
+}
+
+func (f *fakeProvider) FilesChangedInCommit(ctx context.Context, gitHash string) ([]string, error) {
+	return nil, skerr.Fmt("not implemented")
+}
+
+func (f *fakeProvider) CommitsInRange(ctx context.Context, begin, end string) ([]provider.Commit, error) {
+	return nil, skerr.Fmt("not implemented")
+}
+
+func (f *fakeProvider) LogEntry(ctx context.Context, gitHash string) (string, error) {
+	return "", skerr.Fmt("not implemented")
+}
+
+func (f *fakeProvider) ReadFileAtCommit(ctx context.Context, gitHash, filename string) ([]byte, error) {
+	content, ok := f.files[gitHash]
+	if !ok || filename != "sheriff.cfg" {
+		return nil, skerr.Fmt("file not found")
+	}
+	return []byte(content), nil
+}
+
+func (f *fakeProvider) Update(ctx context.Context) error {
+	f.updateCount++
+	return nil
+}
+
+func TestFileRead_FileExists_ReturnsContentAndHashAsRevision(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "sheriff.cfg")
+	require.NoError(t, os.WriteFile(filename, []byte(configContent), 0644))
+
+	content, revision, err := NewFile(filename).Read(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, configContent, string(content))
+	assert.Len(t, revision, 64)
+
+	// The revision changes when the content changes.
+	require.NoError(t, os.WriteFile(filename, []byte(`subscriptions { name: "b" }`), 0644))
+	_, newRevision, err := NewFile(filename).Read(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, revision, newRevision)
+}
+
+func TestFileRead_FileMissing_ReturnsError(t *testing.T) {
+	_, _, err := NewFile(filepath.Join(t.TempDir(), "missing.cfg")).Read(context.Background())
+	require.Error(t, err)
+}
+
+func TestGitRead_NewCommitsArrive_ReadsFileAtMostRecentCommit(t *testing.T) {
+	gp := &fakeProvider{
+		commits: []string{"aaa", "bbb"},
+		files: map[string]string{
+			"aaa": `subscriptions { name: "a" }`,
+			"bbb": `subscriptions { name: "b" }`,
+			"ccc": `subscriptions { name: "c" }`,
+		},
+	}
+	g := NewGit(gp, "sheriff.cfg")
+
+	content, revision, err := g.Read(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, `subscriptions { name: "b" }`, string(content))
+	assert.Equal(t, "bbb", revision)
+
+	// No new commits.
+	_, revision, err = g.Read(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "bbb", revision)
+
+	gp.commits = append(gp.commits, "ccc")
+	content, revision, err = g.Read(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, `subscriptions { name: "c" }`, string(content))
+	assert.Equal(t, "ccc", revision)
+	assert.Equal(t, 3, gp.updateCount)
+}
+
+func TestGitRead_EmptyRepo_ReturnsError(t *testing.T) {
+	_, _, err := NewGit(&fakeProvider{}, "sheriff.cfg").Read(context.Background())
+	require.Error(t, err)
+}
+
+func TestNew_FileConfigured_ReturnsFile(t *testing.T) {
+	src, err := New(context.Background(), &config.InstanceConfig{
+		SheriffConfigSource: config.SheriffConfigSourceConfig{
+			File: "/etc/perf/sheriff.cfg",
+		},
+	})
+	require.NoError(t, err)
+	assert.IsType(t, &File{}, src)
+}
+
+func TestNew_NothingConfigured_ReturnsError(t *testing.T) {
+	_, err := New(context.Background(), &config.InstanceConfig{})
+	require.Error(t, err)
+}
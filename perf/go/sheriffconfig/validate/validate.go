@@ -142,9 +142,16 @@ func DeserializeProto(encoded string) (*sheriff_configpb.SheriffConfig, error) {
 		return nil, skerr.Fmt("Failed to decode Base64 string: %s", err)
 	}
 	fmt.Printf("%s\n", decoded)
+
+	return UnmarshalProto(decoded)
+}
+
+// Transform prototext data, such as the content of a sheriff config file, into
+// SheriffConfig proto.
+func UnmarshalProto(content []byte) (*sheriff_configpb.SheriffConfig, error) {
 	config := &sheriff_configpb.SheriffConfig{}
 
-	err = prototext.Unmarshal(decoded, config)
+	err := prototext.Unmarshal(content, config)
 	if err != nil {
 		return nil, skerr.Fmt("Failed to unmarshal prototext: %s", err)
 	}
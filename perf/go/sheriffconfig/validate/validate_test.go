@@ -326,3 +326,24 @@ func TestDeserializeProto_ValidPrototext(t *testing.T) {
 		t.Errorf("Protos are not equal")
 	}
 }
+
+func TestUnmarshalProto_InvalidPrototext(t *testing.T) {
+	_, err := UnmarshalProto([]byte(`subscriptions { invalidfield: "a" }`))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to unmarshal prototext")
+}
+
+func TestUnmarshalProto_ValidPrototext(t *testing.T) {
+	config, err := UnmarshalProto([]byte(`subscriptions { name: "a" }`))
+
+	require.NoError(t, err)
+	expectedconfig := &pb.SheriffConfig{
+		Subscriptions: []*pb.Subscription{
+			{
+				Name: "a",
+			},
+		},
+	}
+	assert.True(t, proto.Equal(config, expectedconfig))
+}
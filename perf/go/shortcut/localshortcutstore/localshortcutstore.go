@@ -0,0 +1,209 @@
+// Package localshortcutstore implements shortcut.Store using a localstore.DB.
+package localshortcutstore
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"go.skia.org/infra/go/query"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/shortcut"
+)
+
+// shortcutsTable is the name of the table in the localstore.DB.
+const shortcutsTable = "shortcuts"
+
+// table is the data stored in the shortcuts table.
+type table struct {
+	// Shortcuts maps shortcut ids to the JSON serialized shortcut.Shortcut.
+	Shortcuts map[string]string
+
+	// Metadata maps shortcut ids to their shortcut.Metadata.
+	Metadata map[string]shortcut.Metadata
+}
+
+// LocalShortcutStore implements the shortcut.Store interface using a
+// localstore.DB.
+type LocalShortcutStore struct {
+	db *localstore.DB
+
+	// mutex protects data.
+	mutex sync.Mutex
+	data  table
+}
+
+// New returns a new *LocalShortcutStore.
+func New(db *localstore.DB) (*LocalShortcutStore, error) {
+	ret := &LocalShortcutStore{
+		db: db,
+		data: table{
+			Shortcuts: map[string]string{},
+			Metadata:  map[string]shortcut.Metadata{},
+		},
+	}
+	if err := db.Read(shortcutsTable, &ret.data); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	// Tables written before metadata was added don't have the Metadata map.
+	if ret.data.Metadata == nil {
+		ret.data.Metadata = map[string]shortcut.Metadata{}
+	}
+	return ret, nil
+}
+
+// Insert implements the shortcut.Store interface.
+func (s *LocalShortcutStore) Insert(ctx context.Context, r io.Reader) (string, error) {
+	shortcut := &shortcut.Shortcut{}
+	if err := json.NewDecoder(r).Decode(shortcut); err != nil {
+		return "", skerr.Wrapf(err, "Unable to read shortcut body")
+	}
+	return s.InsertShortcut(ctx, shortcut)
+}
+
+// InsertShortcut implements the shortcut.Store interface.
+func (s *LocalShortcutStore) InsertShortcut(ctx context.Context, sc *shortcut.Shortcut) (string, error) {
+	for _, key := range sc.Keys {
+		if !query.IsValid(key) {
+			return "", skerr.Fmt("Tried to store an invalid trace key: %q", key)
+		}
+	}
+	id := shortcut.IDFromKeys(sc)
+	b, err := json.Marshal(sc)
+	if err != nil {
+		return "", err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	if _, ok := s.data.Shortcuts[id]; ok {
+		s.touch(id, now)
+		return id, nil
+	}
+	s.data.Shortcuts[id] = string(b)
+	s.data.Metadata[id] = shortcut.Metadata{
+		ID:           id,
+		CreatedBy:    sc.CreatedBy,
+		Created:      now,
+		LastAccessed: now,
+	}
+	if err := s.db.Write(shortcutsTable, s.data); err != nil {
+		delete(s.data.Shortcuts, id)
+		delete(s.data.Metadata, id)
+		return "", skerr.Wrap(err)
+	}
+	return id, nil
+}
+
+// Get implements the shortcut.Store interface.
+func (s *LocalShortcutStore) Get(ctx context.Context, id string) (*shortcut.Shortcut, error) {
+	s.mutex.Lock()
+	encoded, ok := s.data.Shortcuts[id]
+	if ok && time.Since(s.metadata(id).LastAccessed) > shortcut.AccessUpdateInterval {
+		s.touch(id, time.Now())
+	}
+	s.mutex.Unlock()
+	if !ok {
+		return nil, skerr.Fmt("Failed to load shortcut %q.", id)
+	}
+	var sc shortcut.Shortcut
+	if err := json.Unmarshal([]byte(encoded), &sc); err != nil {
+		return nil, skerr.Wrapf(err, "Failed to decode keys.")
+	}
+	return &sc, nil
+}
+
+// GetAll implements the shortcut.Store interface.
+func (s *LocalShortcutStore) GetAll(ctx context.Context) (<-chan *shortcut.Shortcut, error) {
+	s.mutex.Lock()
+	all := make([]string, 0, len(s.data.Shortcuts))
+	for _, encoded := range s.data.Shortcuts {
+		all = append(all, encoded)
+	}
+	s.mutex.Unlock()
+
+	ret := make(chan *shortcut.Shortcut)
+	go func() {
+		defer close(ret)
+		for _, encoded := range all {
+			var sc shortcut.Shortcut
+			if err := json.Unmarshal([]byte(encoded), &sc); err != nil {
+				sklog.Warningf("Failed to decode all shortcuts: %s", err)
+				continue
+			}
+			ret <- &sc
+		}
+	}()
+	return ret, nil
+}
+
+// GetMetadata implements the shortcut.Store interface.
+func (s *LocalShortcutStore) GetMetadata(ctx context.Context, id string) (*shortcut.Metadata, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.data.Shortcuts[id]; !ok {
+		return nil, skerr.Fmt("Failed to load shortcut metadata %q.", id)
+	}
+	ret := s.metadata(id)
+	return &ret, nil
+}
+
+// ListUnaccessedSince implements the shortcut.Store interface.
+func (s *LocalShortcutStore) ListUnaccessedSince(ctx context.Context, before time.Time) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ret := []string{}
+	for id := range s.data.Shortcuts {
+		if m := s.metadata(id); !m.Created.IsZero() && m.LastAccessed.Before(before) {
+			ret = append(ret, id)
+		}
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// Delete implements the shortcut.Store interface.
+func (s *LocalShortcutStore) Delete(ctx context.Context, ids []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, id := range ids {
+		delete(s.data.Shortcuts, id)
+		delete(s.data.Metadata, id)
+	}
+	if err := s.db.Write(shortcutsTable, s.data); err != nil {
+		return skerr.Wrapf(err, "Failed to delete shortcuts.")
+	}
+	return nil
+}
+
+// metadata returns the metadata for the given shortcut id. Shortcuts stored
+// before metadata was recorded get a zero Metadata. The caller must hold the
+// mutex.
+func (s *LocalShortcutStore) metadata(id string) shortcut.Metadata {
+	ret, ok := s.data.Metadata[id]
+	if !ok {
+		ret.ID = id
+	}
+	return ret
+}
+
+// touch updates the last accessed time of the given shortcut. Failing to
+// persist the new time isn't fatal, it only affects garbage collection. The
+// caller must hold the mutex.
+func (s *LocalShortcutStore) touch(id string, now time.Time) {
+	m := s.metadata(id)
+	m.LastAccessed = now
+	s.data.Metadata[id] = m
+	if err := s.db.Write(shortcutsTable, s.data); err != nil {
+		sklog.Warningf("Failed to record access to shortcut %q: %s", id, err)
+	}
+}
+
+// Confirm that *LocalShortcutStore implements shortcut.Store.
+var _ shortcut.Store = (*LocalShortcutStore)(nil)
@@ -0,0 +1,21 @@
+package localshortcutstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/shortcut/shortcuttest"
+)
+
+func TestShortcutStore_Local(t *testing.T) {
+	for name, subTest := range shortcuttest.SubTests {
+		t.Run(name, func(t *testing.T) {
+			db, err := localstore.New(t.TempDir())
+			require.NoError(t, err)
+			store, err := New(db)
+			require.NoError(t, err)
+			subTest(t, store)
+		})
+	}
+}
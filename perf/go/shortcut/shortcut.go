@@ -7,11 +7,30 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"time"
 )
 
 // Shortcut is a list of Trace ids, it is used in the Store interface.
 type Shortcut struct {
 	Keys []string `json:"keys"`
+
+	// CreatedBy is the email of the user that is storing the shortcut. It is
+	// only recorded when the shortcut is first stored.
+	CreatedBy string `json:"-"`
+}
+
+// AccessUpdateInterval is how old the last accessed time of a shortcut may get
+// before retrieving the shortcut records a new one. Most retrievals are then
+// pure reads, and last accessed times are accurate to within this interval.
+const AccessUpdateInterval = 24 * time.Hour
+
+// Metadata describes who created a shortcut and when it was last used. Created
+// is the zero time for shortcuts stored before metadata was recorded.
+type Metadata struct {
+	ID           string
+	CreatedBy    string
+	Created      time.Time
+	LastAccessed time.Time
 }
 
 // Store is an interface for things that persists Shortcuts.
@@ -30,6 +49,17 @@ type Store interface {
 	// GetAll returns a channel that provides all the Shortcuts stored. This is
 	// used to migrate between backends.
 	GetAll(ctx context.Context) (<-chan *Shortcut, error)
+
+	// GetMetadata returns the Metadata for the shortcut with the given id.
+	GetMetadata(ctx context.Context, id string) (*Metadata, error)
+
+	// ListUnaccessedSince returns the ids of all the shortcuts that haven't
+	// been stored or retrieved since the given time. Shortcuts stored before
+	// metadata was recorded are never returned, since their age is unknown.
+	ListUnaccessedSince(ctx context.Context, before time.Time) ([]string, error)
+
+	// Delete removes the shortcuts with the given ids.
+	Delete(ctx context.Context, ids []string) error
 }
 
 // IDFromKeys returns a unique ID for the set of keys found
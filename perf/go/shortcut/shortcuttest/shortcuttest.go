@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -81,13 +82,54 @@ func GetAll(t *testing.T, store shortcut.Store) {
 	assert.True(t, strings.HasPrefix(all[0].Keys[0], ",arch=x86,test=test"))
 }
 
+// MetadataAndDelete tests that the creator and access times are recorded, and
+// that unaccessed shortcuts can be found and deleted.
+func MetadataAndDelete(t *testing.T, store shortcut.Store) {
+	ctx := context.Background()
+	id, err := store.InsertShortcut(ctx, &shortcut.Shortcut{
+		Keys:      []string{",arch=x86,test=testA,"},
+		CreatedBy: "alice@example.org",
+	})
+	require.NoError(t, err)
+
+	// Storing the same shortcut again doesn't change the creator.
+	_, err = store.InsertShortcut(ctx, &shortcut.Shortcut{
+		Keys:      []string{",arch=x86,test=testA,"},
+		CreatedBy: "bob@example.org",
+	})
+	require.NoError(t, err)
+
+	md, err := store.GetMetadata(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, id, md.ID)
+	assert.Equal(t, "alice@example.org", md.CreatedBy)
+	assert.False(t, md.Created.IsZero())
+	assert.False(t, md.LastAccessed.Before(md.Created))
+
+	// Times are compared with a wide margin since the database clock may
+	// differ from ours.
+	ids, err := store.ListUnaccessedSince(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+	ids, err = store.ListUnaccessedSince(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{id}, ids)
+
+	require.NoError(t, store.Delete(ctx, ids))
+	_, err = store.Get(ctx, id)
+	require.Error(t, err)
+	_, err = store.GetMetadata(ctx, id)
+	require.Error(t, err)
+}
+
 // SubTestFunction is a func we will call to test one aspect of an
 // implementation of regression.Store.
 type SubTestFunction func(t *testing.T, store shortcut.Store)
 
 // SubTests are all the subtests we have for regression.Store.
 var SubTests = map[string]SubTestFunction{
-	"Shortcut_GetAll":         GetAll,
-	"Shortcut_InsertGet":      InsertGet,
-	"Shortcut_GetNonExistent": GetNonExistent,
+	"Shortcut_GetAll":            GetAll,
+	"Shortcut_InsertGet":         InsertGet,
+	"Shortcut_GetNonExistent":    GetNonExistent,
+	"Shortcut_MetadataAndDelete": MetadataAndDelete,
 }
@@ -7,7 +7,9 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"time"
 
+	"go.skia.org/infra/go/now"
 	"go.skia.org/infra/go/query"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/sklog"
@@ -22,6 +24,10 @@ const (
 	// The identifiers for all the SQL statements used.
 	insertShortcut statement = iota
 	getShortcut
+	touchShortcut
+	getMetadata
+	listUnaccessedSince
+	deleteShortcuts
 	getAllShortcuts
 )
 
@@ -29,19 +35,54 @@ const (
 var statements = map[statement]string{
 	insertShortcut: `
 		INSERT INTO
-			Shortcuts (id, trace_ids)
+			Shortcuts (id, trace_ids, created_by, created_at)
 		VALUES
-			($1, $2)
-		ON CONFLICT
-		DO NOTHING`,
+			($1, $2, NULLIF($3, ''), now())
+		ON CONFLICT (id)
+		DO UPDATE SET last_accessed=now()`,
 	getShortcut: `
 		SELECT
-			(trace_ids)
+			trace_ids,
+			last_accessed
 		FROM
 			Shortcuts
 		WHERE
 			id=$1
 		`,
+	touchShortcut: `
+		UPDATE
+			Shortcuts
+		SET
+			last_accessed=now()
+		WHERE
+			id=$1
+		`,
+	getMetadata: `
+		SELECT
+			id,
+			COALESCE(created_by, ''),
+			created_at,
+			last_accessed
+		FROM
+			Shortcuts
+		WHERE
+			id=$1
+		`,
+	listUnaccessedSince: `
+		SELECT
+			id
+		FROM
+			Shortcuts
+		WHERE
+			last_accessed < $1
+			AND created_at IS NOT NULL
+		`,
+	deleteShortcuts: `
+		DELETE FROM
+			Shortcuts
+		WHERE
+			id = ANY($1)
+		`,
 	getAllShortcuts: `
 		SELECT
 			(trace_ids)
@@ -87,7 +128,7 @@ func (s *SQLShortcutStore) InsertShortcut(ctx context.Context, sc *shortcut.Shor
 	if err != nil {
 		return "", err
 	}
-	if _, err := s.db.Exec(ctx, statements[insertShortcut], id, string(b)); err != nil {
+	if _, err := s.db.Exec(ctx, statements[insertShortcut], id, string(b), sc.CreatedBy); err != nil {
 		return "", skerr.Wrap(err)
 	}
 	return id, nil
@@ -96,9 +137,17 @@ func (s *SQLShortcutStore) InsertShortcut(ctx context.Context, sc *shortcut.Shor
 // Get implements the shortcut.Store interface.
 func (s *SQLShortcutStore) Get(ctx context.Context, id string) (*shortcut.Shortcut, error) {
 	var encoded string
-	if err := s.db.QueryRow(ctx, statements[getShortcut], id).Scan(&encoded); err != nil {
+	var lastAccessed time.Time
+	if err := s.db.QueryRow(ctx, statements[getShortcut], id).Scan(&encoded, &lastAccessed); err != nil {
 		return nil, skerr.Wrapf(err, "Failed to load shortcuts.")
 	}
+	if now.Now(ctx).Sub(lastAccessed) > shortcut.AccessUpdateInterval {
+		// Failing to record the access isn't fatal, it only affects garbage
+		// collection.
+		if _, err := s.db.Exec(ctx, statements[touchShortcut], id); err != nil {
+			sklog.Warningf("Failed to record access to shortcut %q: %s", id, err)
+		}
+	}
 	var sc shortcut.Shortcut
 	if err := json.Unmarshal([]byte(encoded), &sc); err != nil {
 		return nil, skerr.Wrapf(err, "Failed to decode keys.")
@@ -136,3 +185,47 @@ func (s *SQLShortcutStore) GetAll(ctx context.Context) (<-chan *shortcut.Shortcu
 
 	return ret, nil
 }
+
+// GetMetadata implements the shortcut.Store interface.
+func (s *SQLShortcutStore) GetMetadata(ctx context.Context, id string) (*shortcut.Metadata, error) {
+	ret := &shortcut.Metadata{}
+	var created *time.Time
+	if err := s.db.QueryRow(ctx, statements[getMetadata], id).Scan(&ret.ID, &ret.CreatedBy, &created, &ret.LastAccessed); err != nil {
+		return nil, skerr.Wrapf(err, "Failed to load shortcut metadata.")
+	}
+	// created_at is NULL for shortcuts stored before metadata was recorded.
+	if created != nil {
+		ret.Created = *created
+	}
+	return ret, nil
+}
+
+// ListUnaccessedSince implements the shortcut.Store interface.
+func (s *SQLShortcutStore) ListUnaccessedSince(ctx context.Context, before time.Time) ([]string, error) {
+	rows, err := s.db.Query(ctx, statements[listUnaccessedSince], before)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to query for unaccessed shortcuts.")
+	}
+	defer rows.Close()
+
+	ret := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, nil
+}
+
+// Delete implements the shortcut.Store interface.
+func (s *SQLShortcutStore) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := s.db.Exec(ctx, statements[deleteShortcuts], ids); err != nil {
+		return skerr.Wrapf(err, "Failed to delete shortcuts.")
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package shortcutgc deletes shortcuts and graphs shortcuts that haven't been
+// used in a long time.
+package shortcutgc
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/now"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/perf/go/favorites"
+	"go.skia.org/infra/perf/go/graphsshortcut"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/shortcut"
+)
+
+// sweepTimeout is the longest a single sweep is allowed to run.
+const sweepTimeout = time.Hour
+
+// Sweeper deletes the shortcuts that haven't been stored or retrieved within
+// maxAge, unless they are referenced by a favorite or by a stored regression,
+// which alert emails and filed bugs link to. Shortcuts stored before their
+// creation time was recorded are never deleted.
+type Sweeper struct {
+	shortcuts   shortcut.Store
+	graphs      graphsshortcut.Store
+	favorites   favorites.Store
+	regressions regression.Store
+	maxAge      time.Duration
+
+	shortcutsDeleted metrics2.Counter
+	graphsDeleted    metrics2.Counter
+	referencedKept   metrics2.Counter
+	failures         metrics2.Counter
+}
+
+// New returns a new *Sweeper.
+func New(shortcuts shortcut.Store, graphs graphsshortcut.Store, favs favorites.Store, regressions regression.Store, maxAge time.Duration) (*Sweeper, error) {
+	if maxAge <= 0 {
+		return nil, skerr.Fmt("maxAge must be positive, got %s", maxAge)
+	}
+	return &Sweeper{
+		shortcuts:        shortcuts,
+		graphs:           graphs,
+		favorites:        favs,
+		regressions:      regressions,
+		maxAge:           maxAge,
+		shortcutsDeleted: metrics2.GetCounter("perf_shortcutgc_deleted", map[string]string{"type": "shortcut"}),
+		graphsDeleted:    metrics2.GetCounter("perf_shortcutgc_deleted", map[string]string{"type": "graphs"}),
+		referencedKept:   metrics2.GetCounter("perf_shortcutgc_referenced_kept"),
+		failures:         metrics2.GetCounter("perf_shortcutgc_failures"),
+	}, nil
+}
+
+// Sweep deletes all the unused shortcuts and graphs shortcuts.
+func (s *Sweeper) Sweep(ctx context.Context) error {
+	// Load the favorites before the candidates, so a favorite created during
+	// the sweep can only refer to a shortcut that was just accessed, and
+	// thus isn't a candidate.
+	urls, err := s.favorites.ListURLs(ctx)
+	if err != nil {
+		return skerr.Wrapf(err, "Failed to load favorites.")
+	}
+	inFavorites := idsInURLs(urls)
+	before := now.Now(ctx).Add(-s.maxAge)
+
+	ids, err := s.shortcuts.ListUnaccessedSince(ctx, before)
+	if err != nil {
+		return skerr.Wrapf(err, "Failed to find unused shortcuts.")
+	}
+	shortcutIDs, err := s.unreferenced(ctx, ids, inFavorites)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if err := s.shortcuts.Delete(ctx, shortcutIDs); err != nil {
+		return skerr.Wrapf(err, "Failed to delete shortcuts.")
+	}
+	s.shortcutsDeleted.Inc(int64(len(shortcutIDs)))
+
+	ids, err = s.graphs.ListUnaccessedSince(ctx, before)
+	if err != nil {
+		return skerr.Wrapf(err, "Failed to find unused graphs shortcuts.")
+	}
+	graphIDs, err := s.unreferenced(ctx, ids, inFavorites)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if err := s.graphs.Delete(ctx, graphIDs); err != nil {
+		return skerr.Wrapf(err, "Failed to delete graphs shortcuts.")
+	}
+	s.graphsDeleted.Inc(int64(len(graphIDs)))
+
+	sklog.Infof("Deleted %d shortcuts and %d graphs shortcuts not used since %s.", len(shortcutIDs), len(graphIDs), before)
+	return nil
+}
+
+// unreferenced returns the ids that are neither in inFavorites nor referenced
+// by a stored regression.
+func (s *Sweeper) unreferenced(ctx context.Context, ids []string, inFavorites map[string]bool) ([]string, error) {
+	inRegressions, err := s.regressions.ReferencedShortcuts(ctx, ids)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to find shortcuts referenced by regressions.")
+	}
+	referenced := map[string]bool{}
+	for _, id := range inRegressions {
+		referenced[id] = true
+	}
+	ret := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if inFavorites[id] || referenced[id] {
+			s.referencedKept.Inc(1)
+			continue
+		}
+		ret = append(ret, id)
+	}
+	return ret, nil
+}
+
+// idsInURLs returns every query parameter value in the given urls, including
+// those in the fragment, which is where shortcut ids appear in Perf URLs.
+// Matching whole values avoids keeping a shortcut just because its id is a
+// substring of an unrelated URL.
+func idsInURLs(urls []string) map[string]bool {
+	ret := map[string]bool{}
+	addValues := func(rawQuery string) {
+		values, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			// ParseQuery still returns the values it could parse.
+			sklog.Warningf("Failed to fully parse favorite query %q: %s", rawQuery, err)
+		}
+		for _, vals := range values {
+			for _, v := range vals {
+				ret[v] = true
+			}
+		}
+	}
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil {
+			sklog.Warningf("Ignoring invalid favorite URL %q: %s", raw, err)
+			continue
+		}
+		addValues(u.RawQuery)
+		addValues(u.Fragment)
+	}
+	return ret
+}
+
+// Start sweeps the unused shortcuts, and then does so again every period,
+// until the context is cancelled.
+func (s *Sweeper) Start(ctx context.Context, period time.Duration) {
+	util.RepeatCtx(ctx, period, func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, sweepTimeout)
+		defer cancel()
+		if err := s.Sweep(ctx); err != nil {
+			sklog.Errorf("Failed to garbage collect shortcuts: %s", err)
+			s.failures.Inc(1)
+		}
+	})
+}
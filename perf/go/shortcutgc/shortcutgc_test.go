@@ -0,0 +1,169 @@
+package shortcutgc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/now"
+	"go.skia.org/infra/perf/go/clustering2"
+	"go.skia.org/infra/perf/go/favorites"
+	"go.skia.org/infra/perf/go/favorites/localfavoritestore"
+	"go.skia.org/infra/perf/go/graphsshortcut"
+	"go.skia.org/infra/perf/go/graphsshortcut/localgraphsshortcutstore"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/regression/localregressionstore"
+	"go.skia.org/infra/perf/go/shortcut"
+	"go.skia.org/infra/perf/go/shortcut/localshortcutstore"
+	"go.skia.org/infra/perf/go/ui/frame"
+)
+
+func setUp(t *testing.T) (*Sweeper, shortcut.Store, graphsshortcut.Store, favorites.Store, regression.Store) {
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	shortcuts, err := localshortcutstore.New(db)
+	require.NoError(t, err)
+	graphs, err := localgraphsshortcutstore.New(db)
+	require.NoError(t, err)
+	favs, err := localfavoritestore.New(db)
+	require.NoError(t, err)
+	regressions, err := localregressionstore.New(db)
+	require.NoError(t, err)
+	s, err := New(shortcuts, graphs, favs, regressions, time.Hour)
+	require.NoError(t, err)
+	return s, shortcuts, graphs, favs, regressions
+}
+
+func TestSweep_UnusedShortcuts_AreDeleted(t *testing.T) {
+	ctx := context.Background()
+	s, shortcuts, graphs, _, _ := setUp(t)
+
+	id, err := shortcuts.InsertShortcut(ctx, &shortcut.Shortcut{Keys: []string{",arch=x86,"}})
+	require.NoError(t, err)
+	graphID, err := graphs.InsertShortcut(ctx, &graphsshortcut.GraphsShortcut{
+		Graphs: []graphsshortcut.GraphConfig{{Queries: []string{"arch=x86"}}},
+	})
+	require.NoError(t, err)
+
+	later := context.WithValue(ctx, now.ContextKey, time.Now().Add(2*time.Hour))
+	require.NoError(t, s.Sweep(later))
+
+	_, err = shortcuts.Get(ctx, id)
+	require.Error(t, err)
+	_, err = graphs.GetShortcut(ctx, graphID)
+	require.Error(t, err)
+}
+
+func TestSweep_RecentlyUsedShortcuts_AreKept(t *testing.T) {
+	ctx := context.Background()
+	s, shortcuts, graphs, _, _ := setUp(t)
+
+	id, err := shortcuts.InsertShortcut(ctx, &shortcut.Shortcut{Keys: []string{",arch=x86,"}})
+	require.NoError(t, err)
+	graphID, err := graphs.InsertShortcut(ctx, &graphsshortcut.GraphsShortcut{
+		Graphs: []graphsshortcut.GraphConfig{{Queries: []string{"arch=x86"}}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Sweep(ctx))
+
+	_, err = shortcuts.Get(ctx, id)
+	require.NoError(t, err)
+	_, err = graphs.GetShortcut(ctx, graphID)
+	require.NoError(t, err)
+}
+
+func TestSweep_ShortcutsInFavorites_AreKept(t *testing.T) {
+	ctx := context.Background()
+	s, shortcuts, graphs, favs, _ := setUp(t)
+
+	id, err := shortcuts.InsertShortcut(ctx, &shortcut.Shortcut{Keys: []string{",arch=x86,"}})
+	require.NoError(t, err)
+	unusedID, err := shortcuts.InsertShortcut(ctx, &shortcut.Shortcut{Keys: []string{",arch=arm,"}})
+	require.NoError(t, err)
+	graphID, err := graphs.InsertShortcut(ctx, &graphsshortcut.GraphsShortcut{
+		Graphs: []graphsshortcut.GraphConfig{{Queries: []string{"arch=x86"}}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, favs.Create(ctx, &favorites.SaveRequest{UserId: "a@example.org", Name: "keys", Url: "/e/?keys=" + id}))
+	require.NoError(t, favs.Create(ctx, &favorites.SaveRequest{UserId: "a@example.org", Name: "graphs", Url: "/m/?shortcut=" + graphID, Team: "team1"}))
+	// Only whole values count, so a favorite that contains the id of the
+	// unused shortcut as a substring doesn't keep it.
+	require.NoError(t, favs.Create(ctx, &favorites.SaveRequest{UserId: "a@example.org", Name: "other", Url: "/e/?keys=" + unusedID + "suffix"}))
+
+	later := context.WithValue(ctx, now.ContextKey, time.Now().Add(2*time.Hour))
+	require.NoError(t, s.Sweep(later))
+
+	_, err = shortcuts.Get(ctx, id)
+	require.NoError(t, err)
+	_, err = graphs.GetShortcut(ctx, graphID)
+	require.NoError(t, err)
+	_, err = shortcuts.Get(ctx, unusedID)
+	assert.Error(t, err)
+}
+
+func TestSweep_ShortcutsInRegressions_AreKept(t *testing.T) {
+	ctx := context.Background()
+	s, shortcuts, _, _, regressions := setUp(t)
+
+	id, err := shortcuts.InsertShortcut(ctx, &shortcut.Shortcut{Keys: []string{",arch=x86,"}})
+	require.NoError(t, err)
+	unusedID, err := shortcuts.InsertShortcut(ctx, &shortcut.Shortcut{Keys: []string{",arch=arm,"}})
+	require.NoError(t, err)
+	_, err = regressions.SetHigh(ctx, 1, "1", &frame.FrameResponse{}, &clustering2.ClusterSummary{Shortcut: id})
+	require.NoError(t, err)
+
+	later := context.WithValue(ctx, now.ContextKey, time.Now().Add(2*time.Hour))
+	require.NoError(t, s.Sweep(later))
+
+	_, err = shortcuts.Get(ctx, id)
+	require.NoError(t, err)
+	_, err = shortcuts.Get(ctx, unusedID)
+	assert.Error(t, err)
+}
+
+func TestSweep_ShortcutsStoredBeforeMetadata_AreKept(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := localstore.New(dir)
+	require.NoError(t, err)
+	// A shortcuts table written before metadata was recorded.
+	legacy := struct {
+		Shortcuts map[string]string
+	}{
+		Shortcuts: map[string]string{"Xold": `{"keys":[",arch=x86,"]}`},
+	}
+	require.NoError(t, db.Write("shortcuts", legacy))
+	shortcuts, err := localshortcutstore.New(db)
+	require.NoError(t, err)
+	graphs, err := localgraphsshortcutstore.New(db)
+	require.NoError(t, err)
+	favs, err := localfavoritestore.New(db)
+	require.NoError(t, err)
+	regressions, err := localregressionstore.New(db)
+	require.NoError(t, err)
+	s, err := New(shortcuts, graphs, favs, regressions, time.Hour)
+	require.NoError(t, err)
+
+	later := context.WithValue(ctx, now.ContextKey, time.Now().Add(2*time.Hour))
+	require.NoError(t, s.Sweep(later))
+
+	_, err = shortcuts.Get(ctx, "Xold")
+	require.NoError(t, err)
+}
+
+func TestIDsInURLs_QueryAndFragmentValues(t *testing.T) {
+	assert.Equal(t, map[string]bool{
+		"X1":   true,
+		"8888": true,
+		"X2":   true,
+	}, idsInURLs([]string{"/e/?keys=X1&config=8888", "https://perf.example.org/m/#shortcut=X2"}))
+}
+
+func TestNew_ZeroMaxAge_ReturnsError(t *testing.T) {
+	_, err := New(nil, nil, nil, nil, 0)
+	require.Error(t, err)
+}
@@ -0,0 +1,395 @@
+// Package slo reports how long sheriffs take to triage regressions and to file
+// bugs for anomaly groups, per subscription, against the targets in the
+// instance config.
+package slo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.skia.org/infra/go/httputils"
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/now"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/perf/go/config"
+)
+
+const (
+	defaultTriageTarget = 24 * time.Hour
+	defaultBugTarget    = 3 * 24 * time.Hour
+	defaultWindow       = 7 * 24 * time.Hour
+
+	// NoSubscription is the subscription reported for regressions whose
+	// alert isn't part of a subscription.
+	NoSubscription = "none"
+
+	// computeTimeout is the longest a single report is allowed to take.
+	computeTimeout = 10 * time.Minute
+)
+
+// TriageRecord is a single regression.
+type TriageRecord struct {
+	Subscription string
+	Created      time.Time
+
+	// Triaged is when the regression was first triaged, or the zero time if
+	// it is still untriaged.
+	Triaged time.Time
+}
+
+// BugRecord is a single anomaly group whose action is to file a bug.
+type BugRecord struct {
+	Subscription string
+	Created      time.Time
+
+	// Filed is when the bug was filed, or the zero time if it hasn't been.
+	Filed time.Time
+}
+
+// Source loads the regressions and anomaly groups to report on, and keeps
+// track of which days the summary was sent on.
+type Source interface {
+	// TriageRecords returns all the regressions found since the given time.
+	TriageRecords(ctx context.Context, since time.Time) ([]TriageRecord, error)
+
+	// BugRecords returns all the anomaly groups created since the given
+	// time.
+	BugRecords(ctx context.Context, since time.Time) ([]BugRecord, error)
+
+	// SummarySent returns true if the summary was already sent on the given
+	// day, in the format "2006-01-02".
+	SummarySent(ctx context.Context, day string) (bool, error)
+
+	// RecordSummarySent records that the summary was sent on the given day.
+	RecordSummarySent(ctx context.Context, day string) error
+}
+
+// Emailer sends the weekly summary, notify.EmailTransport implements it.
+type Emailer interface {
+	SendReport(ctx context.Context, to []string, subject, body string) error
+}
+
+// Latency summarizes how long it took to act on a set of regressions or
+// anomaly groups.
+type Latency struct {
+	// Total is the number of regressions or anomaly groups.
+	Total int `json:"total"`
+
+	// Pending is how many of them haven't been acted on yet.
+	Pending int `json:"pending"`
+
+	// MedianSeconds and P90Seconds are over the ones that were acted on.
+	MedianSeconds float64 `json:"median_seconds"`
+	P90Seconds    float64 `json:"p90_seconds"`
+
+	// Breaches is how many were acted on after the target, or are still
+	// pending and older than the target.
+	Breaches int `json:"breaches"`
+}
+
+// SubscriptionReport is the report for a single subscription.
+type SubscriptionReport struct {
+	Subscription string  `json:"subscription"`
+	Triage       Latency `json:"triage"`
+	Bug          Latency `json:"bug"`
+}
+
+// Report is the triage latency of all subscriptions over a window of time.
+type Report struct {
+	Begin               time.Time            `json:"begin"`
+	End                 time.Time            `json:"end"`
+	TriageTargetSeconds float64              `json:"triage_target_seconds"`
+	BugTargetSeconds    float64              `json:"bug_target_seconds"`
+	Subscriptions       []SubscriptionReport `json:"subscriptions"`
+}
+
+// latencyBuilder accumulates the durations for a Latency.
+type latencyBuilder struct {
+	durations []time.Duration
+	pending   int
+	breaches  int
+}
+
+func (b *latencyBuilder) add(created, done, end time.Time, target time.Duration) {
+	if done.IsZero() {
+		b.pending++
+		if end.Sub(created) > target {
+			b.breaches++
+		}
+		return
+	}
+	d := done.Sub(created)
+	if d < 0 {
+		d = 0
+	}
+	if d > target {
+		b.breaches++
+	}
+	b.durations = append(b.durations, d)
+}
+
+func (b *latencyBuilder) latency() Latency {
+	sort.Slice(b.durations, func(i, j int) bool { return b.durations[i] < b.durations[j] })
+	return Latency{
+		Total:         len(b.durations) + b.pending,
+		Pending:       b.pending,
+		MedianSeconds: percentile(b.durations, 0.5).Seconds(),
+		P90Seconds:    percentile(b.durations, 0.9).Seconds(),
+		Breaches:      b.breaches,
+	}
+}
+
+// percentile returns the nearest-rank percentile of the sorted durations, or
+// 0 if there are none.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// Aggregate builds the Report for the window [begin, end) from the given
+// records, sorted by subscription name.
+func Aggregate(triage []TriageRecord, bugs []BugRecord, begin, end time.Time, triageTarget, bugTarget time.Duration) *Report {
+	type builders struct {
+		triage latencyBuilder
+		bug    latencyBuilder
+	}
+	bySub := map[string]*builders{}
+	get := func(sub string) *builders {
+		if sub == "" {
+			sub = NoSubscription
+		}
+		b, ok := bySub[sub]
+		if !ok {
+			b = &builders{}
+			bySub[sub] = b
+		}
+		return b
+	}
+	for _, r := range triage {
+		if r.Created.Before(begin) || !r.Created.Before(end) {
+			continue
+		}
+		get(r.Subscription).triage.add(r.Created, r.Triaged, end, triageTarget)
+	}
+	for _, r := range bugs {
+		if r.Created.Before(begin) || !r.Created.Before(end) {
+			continue
+		}
+		get(r.Subscription).bug.add(r.Created, r.Filed, end, bugTarget)
+	}
+
+	ret := &Report{
+		Begin:               begin,
+		End:                 end,
+		TriageTargetSeconds: triageTarget.Seconds(),
+		BugTargetSeconds:    bugTarget.Seconds(),
+		Subscriptions:       []SubscriptionReport{},
+	}
+	for sub, b := range bySub {
+		ret.Subscriptions = append(ret.Subscriptions, SubscriptionReport{
+			Subscription: sub,
+			Triage:       b.triage.latency(),
+			Bug:          b.bug.latency(),
+		})
+	}
+	sort.Slice(ret.Subscriptions, func(i, j int) bool {
+		return ret.Subscriptions[i].Subscription < ret.Subscriptions[j].Subscription
+	})
+	return ret
+}
+
+// Reporter computes Reports from a Source, exports them as metrics, serves
+// them as JSON, and emails a weekly summary.
+type Reporter struct {
+	source       Source
+	emailer      Emailer
+	triageTarget time.Duration
+	bugTarget    time.Duration
+	window       time.Duration
+	reportEmails []string
+	reportDay    time.Weekday
+
+	failures metrics2.Counter
+}
+
+// New returns a new *Reporter. The emailer may be nil if no summary is to be
+// sent.
+func New(source Source, emailer Emailer, cfg config.SLOConfig) (*Reporter, error) {
+	reportDay, err := cfg.ReportWeekday()
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	ret := &Reporter{
+		source:       source,
+		emailer:      emailer,
+		triageTarget: time.Duration(cfg.TriageTarget),
+		bugTarget:    time.Duration(cfg.BugTarget),
+		window:       time.Duration(cfg.Window),
+		reportEmails: cfg.ReportEmails,
+		reportDay:    reportDay,
+		failures:     metrics2.GetCounter("perf_slo_failures"),
+	}
+	if ret.triageTarget <= 0 {
+		ret.triageTarget = defaultTriageTarget
+	}
+	if ret.bugTarget <= 0 {
+		ret.bugTarget = defaultBugTarget
+	}
+	if ret.window <= 0 {
+		ret.window = defaultWindow
+	}
+	return ret, nil
+}
+
+// Report returns the Report for the given window ending now.
+func (r *Reporter) Report(ctx context.Context, window time.Duration) (*Report, error) {
+	end := now.Now(ctx)
+	begin := end.Add(-window)
+	triage, err := r.source.TriageRecords(ctx, begin)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to load regressions.")
+	}
+	bugs, err := r.source.BugRecords(ctx, begin)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to load anomaly groups.")
+	}
+	return Aggregate(triage, bugs, begin, end, r.triageTarget, r.bugTarget), nil
+}
+
+// UpdateMetrics exports the Report for the configured window as metrics.
+func (r *Reporter) UpdateMetrics(ctx context.Context) error {
+	report, err := r.Report(ctx, r.window)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	for _, sub := range report.Subscriptions {
+		for kind, l := range map[string]Latency{"triage": sub.Triage, "bug": sub.Bug} {
+			tags := map[string]string{"subscription": sub.Subscription, "kind": kind}
+			metrics2.GetInt64Metric("perf_slo_total", tags).Update(int64(l.Total))
+			metrics2.GetInt64Metric("perf_slo_pending", tags).Update(int64(l.Pending))
+			metrics2.GetInt64Metric("perf_slo_breaches", tags).Update(int64(l.Breaches))
+			metrics2.GetFloat64Metric("perf_slo_latency_median_s", tags).Update(l.MedianSeconds)
+			metrics2.GetFloat64Metric("perf_slo_latency_p90_s", tags).Update(l.P90Seconds)
+		}
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler, serving the Report as JSON. The window
+// defaults to the configured one, and can be set in days with the "days"
+// query parameter.
+func (r *Reporter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), computeTimeout)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/json")
+
+	window := r.window
+	if days := req.FormValue("days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			httputils.ReportError(w, fmt.Errorf("Invalid days: %q", days), "The days parameter must be a positive integer.", http.StatusBadRequest)
+			return
+		}
+		window = time.Duration(n) * 24 * time.Hour
+	}
+	report, err := r.Report(ctx, window)
+	if err != nil {
+		httputils.ReportError(w, err, "Failed to compute the report.", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		sklog.Errorf("Failed to write or encode output: %s", err)
+	}
+}
+
+// summaryTemplate is the body of the weekly summary email.
+var summaryTemplate = template.Must(template.New("summary").Funcs(template.FuncMap{
+	"duration": func(seconds float64) string {
+		return (time.Duration(seconds) * time.Second).Round(time.Minute).String()
+	},
+}).Parse(`<b>Triage latency from {{ .Begin.Format "2006-01-02" }} to {{ .End.Format "2006-01-02" }}</b>
+<p>Targets: triage within {{ duration .TriageTargetSeconds }}, file a bug within {{ duration .BugTargetSeconds }}.</p>
+<table>
+<tr><th>Subscription</th><th>Regressions</th><th>Untriaged</th><th>Median time to triage</th><th>P90 time to triage</th><th>Triage breaches</th><th>Groups</th><th>Without bug</th><th>Median time to bug</th><th>Bug breaches</th></tr>
+{{ range .Subscriptions -}}
+<tr><td>{{ .Subscription }}</td><td>{{ .Triage.Total }}</td><td>{{ .Triage.Pending }}</td><td>{{ duration .Triage.MedianSeconds }}</td><td>{{ duration .Triage.P90Seconds }}</td><td>{{ .Triage.Breaches }}</td><td>{{ .Bug.Total }}</td><td>{{ .Bug.Pending }}</td><td>{{ duration .Bug.MedianSeconds }}</td><td>{{ .Bug.Breaches }}</td></tr>
+{{ end -}}
+</table>
+`))
+
+// FormatSummary returns the HTML body of the summary email for the Report.
+func FormatSummary(report *Report) (string, error) {
+	var b bytes.Buffer
+	if err := summaryTemplate.Execute(&b, report); err != nil {
+		return "", skerr.Wrap(err)
+	}
+	return b.String(), nil
+}
+
+// SendSummaryIfDue sends the summary email if today is the report day and it
+// hasn't been sent today already.
+func (r *Reporter) SendSummaryIfDue(ctx context.Context) error {
+	if r.emailer == nil || len(r.reportEmails) == 0 {
+		return nil
+	}
+	ts := now.Now(ctx)
+	today := ts.Format("2006-01-02")
+	if ts.Weekday() != r.reportDay {
+		return nil
+	}
+	sent, err := r.source.SummarySent(ctx, today)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if sent {
+		return nil
+	}
+	report, err := r.Report(ctx, r.window)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	body, err := FormatSummary(report)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	subject := fmt.Sprintf("Perf triage latency for the week ending %s", today)
+	if err := r.emailer.SendReport(ctx, r.reportEmails, subject, body); err != nil {
+		return skerr.Wrap(err)
+	}
+	return skerr.Wrap(r.source.RecordSummarySent(ctx, today))
+}
+
+// Start updates the metrics and checks if the summary is due, and then does
+// so again every period, until the context is cancelled.
+func (r *Reporter) Start(ctx context.Context, period time.Duration) {
+	util.RepeatCtx(ctx, period, func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, computeTimeout)
+		defer cancel()
+		if err := r.UpdateMetrics(ctx); err != nil {
+			sklog.Errorf("Failed to update SLO metrics: %s", err)
+			r.failures.Inc(1)
+		}
+		if err := r.SendSummaryIfDue(ctx); err != nil {
+			sklog.Errorf("Failed to send SLO summary: %s", err)
+			r.failures.Inc(1)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/now"
+	"go.skia.org/infra/perf/go/config"
+)
+
+// A Monday.
+var testTime = time.Date(2024, time.May, 6, 12, 0, 0, 0, time.UTC)
+
+// testContext returns a context whose current time is testTime.
+func testContext() context.Context {
+	return context.WithValue(context.Background(), now.ContextKey, testTime)
+}
+
+type fakeSource struct {
+	triage []TriageRecord
+	bugs   []BugRecord
+	sent   map[string]bool
+}
+
+func (f *fakeSource) TriageRecords(ctx context.Context, since time.Time) ([]TriageRecord, error) {
+	return f.triage, nil
+}
+
+func (f *fakeSource) BugRecords(ctx context.Context, since time.Time) ([]BugRecord, error) {
+	return f.bugs, nil
+}
+
+func (f *fakeSource) SummarySent(ctx context.Context, day string) (bool, error) {
+	return f.sent[day], nil
+}
+
+func (f *fakeSource) RecordSummarySent(ctx context.Context, day string) error {
+	f.sent[day] = true
+	return nil
+}
+
+type fakeEmailer struct {
+	to      []string
+	subject string
+	body    string
+	sent    int
+}
+
+func (f *fakeEmailer) SendReport(ctx context.Context, to []string, subject, body string) error {
+	f.to = to
+	f.subject = subject
+	f.body = body
+	f.sent++
+	return nil
+}
+
+func hoursAgo(h int) time.Time {
+	return testTime.Add(-time.Duration(h) * time.Hour)
+}
+
+func testSource() *fakeSource {
+	return &fakeSource{
+		triage: []TriageRecord{
+			{Subscription: "sub-a", Created: hoursAgo(50), Triaged: hoursAgo(48)},
+			{Subscription: "sub-a", Created: hoursAgo(50), Triaged: hoursAgo(46)},
+			{Subscription: "sub-a", Created: hoursAgo(50), Triaged: hoursAgo(20)},
+			{Subscription: "sub-a", Created: hoursAgo(30)},
+			{Subscription: "sub-a", Created: hoursAgo(2)},
+			{Subscription: "", Created: hoursAgo(1), Triaged: hoursAgo(0)},
+			// Outside the window.
+			{Subscription: "sub-a", Created: hoursAgo(24 * 30)},
+		},
+		bugs: []BugRecord{
+			{Subscription: "sub-a", Created: hoursAgo(100), Filed: hoursAgo(99)},
+			{Subscription: "sub-b", Created: hoursAgo(100)},
+		},
+		sent: map[string]bool{},
+	}
+}
+
+func TestAggregate_MultipleSubscriptions_SortedAndSummarized(t *testing.T) {
+	src := testSource()
+	report := Aggregate(src.triage, src.bugs, testTime.Add(-7*24*time.Hour), testTime, 24*time.Hour, 72*time.Hour)
+
+	require.Len(t, report.Subscriptions, 3)
+	assert.Equal(t, NoSubscription, report.Subscriptions[0].Subscription)
+	assert.Equal(t, "sub-a", report.Subscriptions[1].Subscription)
+	assert.Equal(t, "sub-b", report.Subscriptions[2].Subscription)
+
+	subA := report.Subscriptions[1]
+	assert.Equal(t, Latency{
+		Total:         5,
+		Pending:       2,
+		MedianSeconds: (4 * time.Hour).Seconds(),
+		P90Seconds:    (30 * time.Hour).Seconds(),
+		// Triaged after 30h, and pending for 30h.
+		Breaches: 2,
+	}, subA.Triage)
+	assert.Equal(t, Latency{Total: 1, MedianSeconds: 3600, P90Seconds: 3600}, subA.Bug)
+
+	// The pending bug is older than the 72h target.
+	assert.Equal(t, Latency{Total: 1, Pending: 1, Breaches: 1}, report.Subscriptions[2].Bug)
+}
+
+func TestAggregate_NoRecords_EmptyReport(t *testing.T) {
+	report := Aggregate(nil, nil, testTime.Add(-time.Hour), testTime, time.Hour, time.Hour)
+	assert.Empty(t, report.Subscriptions)
+	assert.Equal(t, 3600.0, report.TriageTargetSeconds)
+}
+
+func TestPercentile(t *testing.T) {
+	d := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, time.Duration(5), percentile(d, 0.5))
+	assert.Equal(t, time.Duration(9), percentile(d, 0.9))
+	assert.Equal(t, time.Duration(0), percentile(nil, 0.5))
+	assert.Equal(t, time.Duration(1), percentile(d[:1], 0.9))
+}
+
+func newReporter(t *testing.T, emailer Emailer, cfg config.SLOConfig) *Reporter {
+	return newReporterWithSource(t, testSource(), emailer, cfg)
+}
+
+func newReporterWithSource(t *testing.T, source Source, emailer Emailer, cfg config.SLOConfig) *Reporter {
+	r, err := New(source, emailer, cfg)
+	require.NoError(t, err)
+	return r
+}
+
+func TestServeHTTP_DaysParameter_LimitsWindow(t *testing.T) {
+	r := newReporter(t, nil, config.SLOConfig{})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/_/slo?days=1", nil).WithContext(testContext()))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report Report
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+	require.Len(t, report.Subscriptions, 2)
+	assert.Equal(t, 1, report.Subscriptions[1].Triage.Total)
+	assert.Equal(t, testTime.Add(-24*time.Hour), report.Begin.UTC())
+}
+
+func TestServeHTTP_InvalidDays_ReturnsError(t *testing.T) {
+	r := newReporter(t, nil, config.SLOConfig{})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/_/slo?days=-1", nil).WithContext(testContext()))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSendSummaryIfDue_OnReportDay_SendsOnce(t *testing.T) {
+	emailer := &fakeEmailer{}
+	r := newReporter(t, emailer, config.SLOConfig{ReportEmails: []string{"sheriffs@example.org"}})
+
+	require.NoError(t, r.SendSummaryIfDue(testContext()))
+	require.NoError(t, r.SendSummaryIfDue(testContext()))
+	assert.Equal(t, 1, emailer.sent)
+	assert.Equal(t, []string{"sheriffs@example.org"}, emailer.to)
+	assert.Equal(t, "Perf triage latency for the week ending 2024-05-06", emailer.subject)
+	assert.Contains(t, emailer.body, "<td>sub-a</td><td>5</td><td>2</td><td>4h0m0s</td><td>30h0m0s</td><td>2</td>")
+}
+
+func TestSendSummaryIfDue_AlreadySentBeforeRestart_DoesNotSendAgain(t *testing.T) {
+	emailer := &fakeEmailer{}
+	source := testSource()
+	cfg := config.SLOConfig{ReportEmails: []string{"sheriffs@example.org"}}
+
+	require.NoError(t, newReporterWithSource(t, source, emailer, cfg).SendSummaryIfDue(testContext()))
+	require.NoError(t, newReporterWithSource(t, source, emailer, cfg).SendSummaryIfDue(testContext()))
+	assert.Equal(t, 1, emailer.sent)
+	assert.True(t, source.sent["2024-05-06"])
+}
+
+func TestSendSummaryIfDue_NotReportDay_DoesNotSend(t *testing.T) {
+	emailer := &fakeEmailer{}
+	r := newReporter(t, emailer, config.SLOConfig{ReportEmails: []string{"sheriffs@example.org"}, ReportDay: "Friday"})
+
+	require.NoError(t, r.SendSummaryIfDue(testContext()))
+	assert.Equal(t, 0, emailer.sent)
+}
+
+func TestUpdateMetrics_Success(t *testing.T) {
+	r := newReporter(t, nil, config.SLOConfig{})
+	require.NoError(t, r.UpdateMetrics(testContext()))
+}
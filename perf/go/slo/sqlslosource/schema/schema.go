@@ -0,0 +1,12 @@
+package schema
+
+import "time"
+
+// SLOSummarySchema represents the SQL schema of the SLOSummaries table, which
+// records the days the weekly summary email was sent on.
+type SLOSummarySchema struct {
+	// The day the summary was sent on, in the format "2006-01-02".
+	Day string `sql:"day TEXT PRIMARY KEY"`
+
+	SentAt time.Time `sql:"sent_at TIMESTAMPTZ DEFAULT now()"`
+}
@@ -0,0 +1,157 @@
+// Package sqlslosource implements slo.Source using an SQL database.
+package sqlslosource
+
+import (
+	"context"
+	"time"
+
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sql/pool"
+	"go.skia.org/infra/perf/go/slo"
+)
+
+// statement is an SQL statement identifier.
+type statement int
+
+const (
+	// The identifiers for all the SQL statements used.
+	triageRecords statement = iota
+	bugRecords
+	summarySent
+	recordSummarySent
+)
+
+// statements holds all the raw SQL statements.
+var statements = map[statement]string{
+	// Regressions that a triage rule marked as positive or negative are left
+	// out since they were never waiting on a person.
+	triageRecords: `
+		SELECT
+			COALESCE(a.sub_name, ''),
+			r.creation_time,
+			r.triage_time
+		FROM
+			Regressions2 r
+		INNER JOIN
+			Alerts a ON r.alert_id=a.id
+		WHERE
+			r.creation_time >= $1
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					TriageRuleAudit t
+				WHERE
+					t.alert_id=CAST(r.alert_id AS TEXT)
+					AND t.commit_number=r.commit_number
+					AND t.cluster_type=r.cluster_type
+					AND t.action IN ('ignore', 'bug')
+			)
+		`,
+	// Merged groups are left out since their anomalies are counted in the
+	// group they were merged into.
+	bugRecords: `
+		SELECT
+			COALESCE(group_meta_data->>'subscription_name', ''),
+			creation_time,
+			CASE WHEN COALESCE(reported_issue_id, '') = '' THEN NULL ELSE action_time END
+		FROM
+			AnomalyGroups
+		WHERE
+			creation_time >= $1
+			AND UPPER(action) = 'REPORT'
+			AND merged_into IS NULL
+		`,
+	summarySent: `
+		SELECT
+			COUNT(*)
+		FROM
+			SLOSummaries
+		WHERE
+			day=$1
+		`,
+	recordSummarySent: `
+		UPSERT INTO
+			SLOSummaries (day, sent_at)
+		VALUES
+			($1, now())
+		`,
+}
+
+// SQLSource implements slo.Source.
+type SQLSource struct {
+	db pool.Pool
+}
+
+// New returns a new *SQLSource.
+func New(db pool.Pool) *SQLSource {
+	return &SQLSource{
+		db: db,
+	}
+}
+
+// TriageRecords implements slo.Source.
+func (s *SQLSource) TriageRecords(ctx context.Context, since time.Time) ([]slo.TriageRecord, error) {
+	rows, err := s.db.Query(ctx, statements[triageRecords], since)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to query regressions.")
+	}
+	defer rows.Close()
+
+	ret := []slo.TriageRecord{}
+	for rows.Next() {
+		var r slo.TriageRecord
+		var triaged *time.Time
+		if err := rows.Scan(&r.Subscription, &r.Created, &triaged); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		if triaged != nil {
+			r.Triaged = *triaged
+		}
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
+
+// BugRecords implements slo.Source.
+func (s *SQLSource) BugRecords(ctx context.Context, since time.Time) ([]slo.BugRecord, error) {
+	rows, err := s.db.Query(ctx, statements[bugRecords], since)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to query anomaly groups.")
+	}
+	defer rows.Close()
+
+	ret := []slo.BugRecord{}
+	for rows.Next() {
+		var r slo.BugRecord
+		var filed *time.Time
+		if err := rows.Scan(&r.Subscription, &r.Created, &filed); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		if filed != nil {
+			r.Filed = *filed
+		}
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
+
+// SummarySent implements slo.Source.
+func (s *SQLSource) SummarySent(ctx context.Context, day string) (bool, error) {
+	var count int
+	if err := s.db.QueryRow(ctx, statements[summarySent], day).Scan(&count); err != nil {
+		return false, skerr.Wrapf(err, "Failed to read summary for day %q.", day)
+	}
+	return count > 0, nil
+}
+
+// RecordSummarySent implements slo.Source.
+func (s *SQLSource) RecordSummarySent(ctx context.Context, day string) error {
+	if _, err := s.db.Exec(ctx, statements[recordSummarySent], day); err != nil {
+		return skerr.Wrapf(err, "Failed to record summary for day %q.", day)
+	}
+	return nil
+}
+
+// Confirm that *SQLSource implements slo.Source.
+var _ slo.Source = (*SQLSource)(nil)
@@ -0,0 +1,128 @@
+package sqlslosource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/sql/pool"
+	"go.skia.org/infra/perf/go/slo"
+	"go.skia.org/infra/perf/go/sql/sqltest"
+)
+
+var (
+	created = time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	later   = created.Add(3 * time.Hour)
+)
+
+func setUp(t *testing.T) (*SQLSource, pool.Pool) {
+	db := sqltest.NewCockroachDBForTests(t, "slo")
+	return New(db), db
+}
+
+func TestTriageRecords_ReturnsTriagedAndPendingRegressions(t *testing.T) {
+	ctx := context.Background()
+	s, db := setUp(t)
+
+	_, err := db.Exec(ctx, `INSERT INTO Alerts (id, alert, sub_name) VALUES (1, '{}', 'sub-a'), (2, '{}', NULL)`)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		INSERT INTO Regressions2 (commit_number, alert_id, creation_time, triage_time) VALUES
+			(10, 1, $1, $2),
+			(11, 2, $1, NULL),
+			(12, 1, $3, NULL)`, created, later, created.Add(-24*time.Hour))
+	require.NoError(t, err)
+
+	records, err := s.TriageRecords(ctx, created)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.ElementsMatch(t, []slo.TriageRecord{
+		{Subscription: "sub-a", Created: created, Triaged: later},
+		{Subscription: "", Created: created},
+	}, utc(records))
+}
+
+func TestTriageRecords_SkipsRegressionsTriagedByRule(t *testing.T) {
+	ctx := context.Background()
+	s, db := setUp(t)
+
+	_, err := db.Exec(ctx, `INSERT INTO Alerts (id, alert, sub_name) VALUES (1, '{}', 'sub-a')`)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		INSERT INTO Regressions2 (commit_number, alert_id, cluster_type, creation_time, triage_time) VALUES
+			(10, 1, 'high', $1, NULL),
+			(11, 1, 'high', $1, NULL),
+			(12, 1, 'low', $1, NULL)`, created)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		INSERT INTO TriageRuleAudit (rule_id, alert_id, commit_number, cluster_type, action) VALUES
+			(1, '1', 10, 'high', 'ignore'),
+			(2, '1', 11, 'high', 'priority'),
+			(3, '1', 12, 'high', 'bug')`)
+	require.NoError(t, err)
+
+	records, err := s.TriageRecords(ctx, created)
+	require.NoError(t, err)
+	// Only the regression whose priority was bumped, and the low regression
+	// the rule didn't apply to, are still waiting on a person.
+	assert.Len(t, records, 2)
+}
+
+func TestSummarySent_RecordedDay_ReturnsTrue(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setUp(t)
+
+	sent, err := s.SummarySent(ctx, "2024-05-06")
+	require.NoError(t, err)
+	assert.False(t, sent)
+
+	require.NoError(t, s.RecordSummarySent(ctx, "2024-05-06"))
+	require.NoError(t, s.RecordSummarySent(ctx, "2024-05-06"))
+
+	sent, err = s.SummarySent(ctx, "2024-05-06")
+	require.NoError(t, err)
+	assert.True(t, sent)
+	sent, err = s.SummarySent(ctx, "2024-05-13")
+	require.NoError(t, err)
+	assert.False(t, sent)
+}
+
+func TestBugRecords_SkipsMergedAndNonReportGroups(t *testing.T) {
+	ctx := context.Background()
+	s, db := setUp(t)
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO AnomalyGroups (group_meta_data, creation_time, action, action_time, reported_issue_id, merged_into) VALUES
+			('{"subscription_name": "sub-a"}', $1, 'REPORT', $2, '12345', NULL),
+			('{"subscription_name": "sub-a"}', $1, 'REPORT', NULL, NULL, NULL),
+			('{"subscription_name": "sub-a"}', $1, 'BISECT', $2, '', NULL),
+			('{"subscription_name": "sub-a"}', $1, 'REPORT', $2, '67890', gen_random_uuid())`, created, later)
+	require.NoError(t, err)
+
+	records, err := s.BugRecords(ctx, created)
+	require.NoError(t, err)
+	for i := range records {
+		records[i].Created = records[i].Created.UTC()
+		if !records[i].Filed.IsZero() {
+			records[i].Filed = records[i].Filed.UTC()
+		}
+	}
+	assert.ElementsMatch(t, []slo.BugRecord{
+		{Subscription: "sub-a", Created: created, Filed: later},
+		{Subscription: "sub-a", Created: created},
+	}, records)
+}
+
+// utc converts the times returned from the database to UTC so they compare
+// equal to the inserted values.
+func utc(records []slo.TriageRecord) []slo.TriageRecord {
+	for i := range records {
+		records[i].Created = records[i].Created.UTC()
+		if !records[i].Triaged.IsZero() {
+			records[i].Triaged = records[i].Triaged.UTC()
+		}
+	}
+	return records
+}
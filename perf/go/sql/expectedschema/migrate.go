@@ -46,12 +46,97 @@ var FromLiveToNext = `
 		last_modified INT,
 		INDEX by_user_id (user_id)
   	);
+	ALTER TABLE Favorites ADD COLUMN IF NOT EXISTS team_name STRING;
+	CREATE INDEX IF NOT EXISTS by_team_name ON Favorites (team_name);
+	CREATE TABLE IF NOT EXISTS FavoriteTeams (
+		name STRING PRIMARY KEY,
+		members STRING ARRAY,
+		last_modified INT
+	);
+	CREATE TABLE IF NOT EXISTS TriageRules (
+		id INT PRIMARY KEY DEFAULT unique_rowid(),
+		rule TEXT,
+		deleted BOOL DEFAULT false,
+		last_modified INT
+	);
+	CREATE TABLE IF NOT EXISTS TriageRuleAudit (
+		id INT PRIMARY KEY DEFAULT unique_rowid(),
+		rule_id INT,
+		rule_name TEXT,
+		alert_id TEXT,
+		commit_number INT,
+		cluster_type TEXT,
+		action TEXT,
+		message TEXT,
+		created_at TIMESTAMPTZ DEFAULT now(),
+		INDEX by_rule_id (rule_id),
+		INDEX by_created_at (created_at DESC)
+	);
+	CREATE TABLE IF NOT EXISTS TraceSamples (
+		trace_id BYTES,
+		commit_number INT,
+		samples REAL[],
+		PRIMARY KEY (trace_id, commit_number)
+	);
+	CREATE TABLE IF NOT EXISTS TraceNoise (
+		trace_name TEXT PRIMARY KEY,
+		noise REAL,
+		last_updated TIMESTAMPTZ DEFAULT now()
+	);
+	ALTER TABLE AnomalyGroups ADD COLUMN IF NOT EXISTS merged_into UUID;
+	CREATE TABLE IF NOT EXISTS AnomalyGroupHistory (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		group_id UUID,
+		change_type TEXT,
+		related_group_ids UUID ARRAY,
+		anomaly_ids UUID ARRAY,
+		reason TEXT,
+		create_time TIMESTAMPTZ DEFAULT now(),
+		INDEX by_group_id (group_id)
+	);
+	ALTER TABLE Shortcuts ADD COLUMN IF NOT EXISTS created_by TEXT;
+	ALTER TABLE Shortcuts ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;
+	ALTER TABLE Shortcuts ADD COLUMN IF NOT EXISTS last_accessed TIMESTAMPTZ DEFAULT now();
+	CREATE INDEX IF NOT EXISTS by_last_accessed ON Shortcuts (last_accessed);
+	ALTER TABLE GraphsShortcuts ADD COLUMN IF NOT EXISTS created_by TEXT;
+	ALTER TABLE GraphsShortcuts ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;
+	ALTER TABLE GraphsShortcuts ADD COLUMN IF NOT EXISTS last_accessed TIMESTAMPTZ DEFAULT now();
+	CREATE INDEX IF NOT EXISTS by_last_accessed ON GraphsShortcuts (last_accessed);
+	ALTER TABLE Regressions2 ADD COLUMN IF NOT EXISTS triage_time TIMESTAMPTZ;
+	CREATE INDEX IF NOT EXISTS by_alert_commit ON TriageRuleAudit (alert_id, commit_number);
+	CREATE TABLE IF NOT EXISTS SLOSummaries (
+		day TEXT PRIMARY KEY,
+		sent_at TIMESTAMPTZ DEFAULT now()
+	);
+	CREATE TABLE IF NOT EXISTS TraceMetadata (
+		trace_id BYTES PRIMARY KEY,
+		unit TEXT,
+		improvement_direction TEXT
+	);
 `
 
 // ONLY DROP TABLE IF YOU JUST CREATED A NEW TABLE.
 // FOR MODIFYING COLUMNS USE ADD/DROP COLUMN INSTEAD.
 var FromNextToLive = `
 	DROP TABLE IF EXISTS Favorites;
+	DROP TABLE IF EXISTS FavoriteTeams;
+	DROP TABLE IF EXISTS TriageRules;
+	DROP TABLE IF EXISTS TriageRuleAudit;
+	DROP TABLE IF EXISTS TraceSamples;
+	DROP TABLE IF EXISTS TraceNoise;
+	ALTER TABLE AnomalyGroups DROP COLUMN IF EXISTS merged_into;
+	DROP TABLE IF EXISTS AnomalyGroupHistory;
+	DROP INDEX IF EXISTS Shortcuts@by_last_accessed;
+	ALTER TABLE Shortcuts DROP COLUMN IF EXISTS created_by;
+	ALTER TABLE Shortcuts DROP COLUMN IF EXISTS created_at;
+	ALTER TABLE Shortcuts DROP COLUMN IF EXISTS last_accessed;
+	DROP INDEX IF EXISTS GraphsShortcuts@by_last_accessed;
+	ALTER TABLE GraphsShortcuts DROP COLUMN IF EXISTS created_by;
+	ALTER TABLE GraphsShortcuts DROP COLUMN IF EXISTS created_at;
+	ALTER TABLE GraphsShortcuts DROP COLUMN IF EXISTS last_accessed;
+	ALTER TABLE Regressions2 DROP COLUMN IF EXISTS triage_time;
+	DROP TABLE IF EXISTS SLOSummaries;
+	DROP TABLE IF EXISTS TraceMetadata;
 `
 
 // This function will check whether there's a new schema checked-in,
@@ -11,6 +11,16 @@ const Schema = `CREATE TABLE IF NOT EXISTS Alerts (
   sub_name STRING,
   sub_revision STRING
 );
+CREATE TABLE IF NOT EXISTS AnomalyGroupHistory (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  group_id UUID,
+  change_type TEXT,
+  related_group_ids UUID ARRAY,
+  anomaly_ids UUID ARRAY,
+  reason TEXT,
+  create_time TIMESTAMPTZ DEFAULT now(),
+  INDEX by_group_id (group_id)
+);
 CREATE TABLE IF NOT EXISTS AnomalyGroups (
   id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
   creation_time TIMESTAMPTZ DEFAULT now(),
@@ -23,7 +33,8 @@ CREATE TABLE IF NOT EXISTS AnomalyGroups (
   bisection_id TEXT,
   reported_issue_id TEXT,
   culprit_ids UUID ARRAY,
-  last_modified_time TIMESTAMPTZ
+  last_modified_time TIMESTAMPTZ,
+  merged_into UUID
 );
 CREATE TABLE IF NOT EXISTS Commits (
   commit_number INT PRIMARY KEY,
@@ -44,6 +55,11 @@ CREATE TABLE IF NOT EXISTS Culprits (
   group_issue_map JSONB,
   UNIQUE INDEX by_revision (revision, host, project, ref)
 );
+CREATE TABLE IF NOT EXISTS FavoriteTeams (
+  name STRING PRIMARY KEY,
+  members STRING ARRAY,
+  last_modified INT
+);
 CREATE TABLE IF NOT EXISTS Favorites (
   id INT PRIMARY KEY DEFAULT unique_rowid(),
   user_id STRING NOT NULL,
@@ -51,11 +67,17 @@ CREATE TABLE IF NOT EXISTS Favorites (
   url STRING NOT NULL,
   description STRING,
   last_modified INT,
-  INDEX by_user_id (user_id)
+  team_name STRING,
+  INDEX by_user_id (user_id),
+  INDEX by_team_name (team_name)
 );
 CREATE TABLE IF NOT EXISTS GraphsShortcuts (
   id TEXT UNIQUE NOT NULL PRIMARY KEY,
-  graphs TEXT
+  graphs TEXT,
+  created_by TEXT,
+  created_at TIMESTAMPTZ,
+  last_accessed TIMESTAMPTZ DEFAULT now(),
+  INDEX by_last_accessed (last_accessed)
 );
 CREATE TABLE IF NOT EXISTS ParamSets (
   tile_number INT,
@@ -94,12 +116,21 @@ CREATE TABLE IF NOT EXISTS Regressions2 (
   frame JSONB,
   triage_status TEXT,
   triage_message TEXT,
+  triage_time TIMESTAMPTZ,
   INDEX by_alert_id (alert_id),
   INDEX by_commit_alert (commit_number, alert_id)
 );
+CREATE TABLE IF NOT EXISTS SLOSummaries (
+  day TEXT PRIMARY KEY,
+  sent_at TIMESTAMPTZ DEFAULT now()
+);
 CREATE TABLE IF NOT EXISTS Shortcuts (
   id TEXT UNIQUE NOT NULL PRIMARY KEY,
-  trace_ids TEXT
+  trace_ids TEXT,
+  created_by TEXT,
+  created_at TIMESTAMPTZ,
+  last_accessed TIMESTAMPTZ DEFAULT now(),
+  INDEX by_last_accessed (last_accessed)
 );
 CREATE TABLE IF NOT EXISTS SourceFiles (
   source_file_id INT PRIMARY KEY DEFAULT unique_rowid(),
@@ -118,6 +149,22 @@ CREATE TABLE IF NOT EXISTS Subscriptions (
   contact_email STRING,
   PRIMARY KEY(name, revision)
 );
+CREATE TABLE IF NOT EXISTS TraceMetadata (
+  trace_id BYTES PRIMARY KEY,
+  unit TEXT,
+  improvement_direction TEXT
+);
+CREATE TABLE IF NOT EXISTS TraceNoise (
+  trace_name TEXT PRIMARY KEY,
+  noise REAL,
+  last_updated TIMESTAMPTZ DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS TraceSamples (
+  trace_id BYTES,
+  commit_number INT,
+  samples REAL[],
+  PRIMARY KEY (trace_id, commit_number)
+);
 CREATE TABLE IF NOT EXISTS TraceValues (
   trace_id BYTES,
   commit_number INT,
@@ -126,6 +173,26 @@ CREATE TABLE IF NOT EXISTS TraceValues (
   PRIMARY KEY (trace_id, commit_number),
   INDEX by_source_file_id (source_file_id, trace_id)
 );
+CREATE TABLE IF NOT EXISTS TriageRuleAudit (
+  id INT PRIMARY KEY DEFAULT unique_rowid(),
+  rule_id INT,
+  rule_name TEXT,
+  alert_id TEXT,
+  commit_number INT,
+  cluster_type TEXT,
+  action TEXT,
+  message TEXT,
+  created_at TIMESTAMPTZ DEFAULT now(),
+  INDEX by_rule_id (rule_id),
+  INDEX by_created_at (created_at DESC),
+  INDEX by_alert_commit (alert_id, commit_number)
+);
+CREATE TABLE IF NOT EXISTS TriageRules (
+  id INT PRIMARY KEY DEFAULT unique_rowid(),
+  rule TEXT,
+  deleted BOOL DEFAULT false,
+  last_modified INT
+);
 `
 
 var Alerts = []string{
@@ -137,6 +204,16 @@ var Alerts = []string{
 	"sub_revision",
 }
 
+var AnomalyGroupHistory = []string{
+	"id",
+	"group_id",
+	"change_type",
+	"related_group_ids",
+	"anomaly_ids",
+	"reason",
+	"create_time",
+}
+
 var AnomalyGroups = []string{
 	"id",
 	"creation_time",
@@ -150,6 +227,7 @@ var AnomalyGroups = []string{
 	"reported_issue_id",
 	"culprit_ids",
 	"last_modified_time",
+	"merged_into",
 }
 
 var Commits = []string{
@@ -173,6 +251,12 @@ var Culprits = []string{
 	"UNIQUE",
 }
 
+var FavoriteTeams = []string{
+	"name",
+	"members",
+	"last_modified",
+}
+
 var Favorites = []string{
 	"id",
 	"user_id",
@@ -180,11 +264,15 @@ var Favorites = []string{
 	"url",
 	"description",
 	"last_modified",
+	"team_name",
 }
 
 var GraphsShortcuts = []string{
 	"id",
 	"graphs",
+	"created_by",
+	"created_at",
+	"last_accessed",
 }
 
 var ParamSets = []string{
@@ -221,11 +309,20 @@ var Regressions2 = []string{
 	"frame",
 	"triage_status",
 	"triage_message",
+	"triage_time",
+}
+
+var SLOSummaries = []string{
+	"day",
+	"sent_at",
 }
 
 var Shortcuts = []string{
 	"id",
 	"trace_ids",
+	"created_by",
+	"created_at",
+	"last_accessed",
 }
 
 var SourceFiles = []string{
@@ -245,9 +342,46 @@ var Subscriptions = []string{
 	"contact_email",
 }
 
+var TraceMetadata = []string{
+	"trace_id",
+	"unit",
+	"improvement_direction",
+}
+
+var TraceNoise = []string{
+	"trace_name",
+	"noise",
+	"last_updated",
+}
+
+var TraceSamples = []string{
+	"trace_id",
+	"commit_number",
+	"samples",
+}
+
 var TraceValues = []string{
 	"trace_id",
 	"commit_number",
 	"val",
 	"source_file_id",
 }
+
+var TriageRuleAudit = []string{
+	"id",
+	"rule_id",
+	"rule_name",
+	"alert_id",
+	"commit_number",
+	"cluster_type",
+	"action",
+	"message",
+	"created_at",
+}
+
+var TriageRules = []string{
+	"id",
+	"rule",
+	"deleted",
+	"last_modified",
+}
@@ -15,18 +15,27 @@ import (
 
 const DropTables = `
 	DROP TABLE IF EXISTS Alerts;
+	DROP TABLE IF EXISTS AnomalyGroupHistory;
 	DROP TABLE IF EXISTS AnomalyGroups;
 	DROP TABLE IF EXISTS Commits;
 	DROP TABLE IF EXISTS Culprits;
+	DROP TABLE IF EXISTS FavoriteTeams;
+	DROP TABLE IF EXISTS Favorites;
 	DROP TABLE IF EXISTS GraphsShortcuts;
 	DROP TABLE IF EXISTS ParamSets;
 	DROP TABLE IF EXISTS Postings;
 	DROP TABLE IF EXISTS Regressions;
 	DROP TABLE IF EXISTS Regressions2;
+	DROP TABLE IF EXISTS SLOSummaries;
 	DROP TABLE IF EXISTS Shortcuts;
 	DROP TABLE IF EXISTS SourceFiles;
 	DROP TABLE IF EXISTS Subscriptions;
+	DROP TABLE IF EXISTS TraceMetadata;
+	DROP TABLE IF EXISTS TraceNoise;
+	DROP TABLE IF EXISTS TraceSamples;
 	DROP TABLE IF EXISTS TraceValues;
+	DROP TABLE IF EXISTS TriageRuleAudit;
+	DROP TABLE IF EXISTS TriageRules;
 `
 
 // LiveSchema has to reflect what's live in prod right now
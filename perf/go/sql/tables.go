@@ -9,27 +9,38 @@ import (
 	favoriteschema "go.skia.org/infra/perf/go/favorites/sqlfavoritestore/schema"
 	gitschema "go.skia.org/infra/perf/go/git/schema"
 	graphsshortcutschema "go.skia.org/infra/perf/go/graphsshortcut/graphsshortcutstore/schema"
+	noiseschema "go.skia.org/infra/perf/go/noise/sqlnoisestore/schema"
 	regression2schema "go.skia.org/infra/perf/go/regression/sqlregression2store/schema"
 	regressionschema "go.skia.org/infra/perf/go/regression/sqlregressionstore/schema"
 	shortcutschema "go.skia.org/infra/perf/go/shortcut/sqlshortcutstore/schema"
+	sloschema "go.skia.org/infra/perf/go/slo/sqlslosource/schema"
 	subscriptionschema "go.skia.org/infra/perf/go/subscription/sqlsubscriptionstore/schema"
 	traceschema "go.skia.org/infra/perf/go/tracestore/sqltracestore/schema"
+	triageruleschema "go.skia.org/infra/perf/go/triagerules/sqltriagerulestore/schema"
 )
 
 // Tables represents the full schema of the SQL database.
 type Tables struct {
-	Alerts          []alertschema.AlertSchema
-	AnomalyGroups   []anomalygroupschema.AnomalyGroupSchema
-	Commits         []gitschema.Commit
-	Culprits        []culpritschema.CulpritSchema
-	Favorites       []favoriteschema.FavoriteSchema
-	GraphsShortcuts []graphsshortcutschema.GraphsShortcutSchema
-	ParamSets       []traceschema.ParamSetsSchema
-	Postings        []traceschema.PostingsSchema
-	Regressions     []regressionschema.RegressionSchema
-	Regressions2    []regression2schema.Regression2Schema
-	Shortcuts       []shortcutschema.ShortcutSchema
-	SourceFiles     []traceschema.SourceFilesSchema
-	Subscriptions   []subscriptionschema.SubscriptionSchema
-	TraceValues     []traceschema.TraceValuesSchema
+	Alerts              []alertschema.AlertSchema
+	AnomalyGroupHistory []anomalygroupschema.AnomalyGroupHistorySchema
+	AnomalyGroups       []anomalygroupschema.AnomalyGroupSchema
+	Commits             []gitschema.Commit
+	Culprits            []culpritschema.CulpritSchema
+	FavoriteTeams       []favoriteschema.FavoriteTeamSchema
+	Favorites           []favoriteschema.FavoriteSchema
+	GraphsShortcuts     []graphsshortcutschema.GraphsShortcutSchema
+	ParamSets           []traceschema.ParamSetsSchema
+	Postings            []traceschema.PostingsSchema
+	Regressions         []regressionschema.RegressionSchema
+	Regressions2        []regression2schema.Regression2Schema
+	SLOSummaries        []sloschema.SLOSummarySchema
+	Shortcuts           []shortcutschema.ShortcutSchema
+	SourceFiles         []traceschema.SourceFilesSchema
+	Subscriptions       []subscriptionschema.SubscriptionSchema
+	TraceMetadata       []traceschema.TraceMetadataSchema
+	TraceNoise          []noiseschema.TraceNoiseSchema
+	TraceSamples        []traceschema.TraceSamplesSchema
+	TraceValues         []traceschema.TraceValuesSchema
+	TriageRuleAudit     []triageruleschema.TriageRuleAuditSchema
+	TriageRules         []triageruleschema.TriageRuleSchema
 }
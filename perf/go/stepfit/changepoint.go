@@ -0,0 +1,264 @@
+package stepfit
+
+import (
+	"math"
+	"sort"
+)
+
+// This file contains multiple change point detection algorithms. Unlike the
+// other step detections, which assume there is at most a single step in the
+// middle of the trace, these find all the change points in a trace, which
+// allows them to correctly attribute a step at the middle of the trace even
+// if there are other shifts elsewhere in the trace.
+
+const (
+	// minSegmentLength is the smallest number of points a segment must have to
+	// be considered a change in the trace, as opposed to an outlier.
+	minSegmentLength = 2
+
+	// madToStdDev converts the median absolute difference between consecutive
+	// points of a Gaussian to its standard deviation, i.e. 1/(0.6745*sqrt(2)).
+	madToStdDev = 1.0483
+
+	// Parameters of the Normal-Gamma prior used by BOCPD, which is applied to
+	// traces that have been standardized so the noise has a unit standard
+	// deviation, i.e. the expected variance of a run is 1. The prior on the
+	// mean of a run is as wide as the spread of the whole trace.
+	bocpdPriorMean  = 0.0
+	bocpdPriorAlpha = 2.0
+	bocpdPriorBeta  = 1.0
+)
+
+// robustStdDev estimates the standard deviation of the noise in the trace
+// from the median absolute difference between consecutive points. Unlike the
+// regular standard deviation, this estimate isn't inflated by the steps in the
+// trace. The returned value is never less than stddevThreshold.
+func robustStdDev(trace []float32, stddevThreshold float32) float64 {
+	if len(trace) < 2 {
+		return float64(stddevThreshold)
+	}
+	diffs := make([]float64, 0, len(trace)-1)
+	for i := 1; i < len(trace); i++ {
+		diffs = append(diffs, math.Abs(float64(trace[i]-trace[i-1])))
+	}
+	sort.Float64s(diffs)
+	n := len(diffs)
+	median := diffs[n/2]
+	if n%2 == 0 {
+		median = (diffs[n/2-1] + diffs[n/2]) / 2
+	}
+	ret := median * madToStdDev
+	if math.IsNaN(ret) || ret < float64(stddevThreshold) {
+		return float64(stddevThreshold)
+	}
+	return ret
+}
+
+// meanOf returns the mean of trace[begin:end].
+func meanOf(trace []float32, begin, end int) float32 {
+	var sum float64
+	for _, x := range trace[begin:end] {
+		sum += float64(x)
+	}
+	return float32(sum / float64(end-begin))
+}
+
+// peltChangePoints finds the optimal segmentation of the trace into segments
+// of constant mean using the Pruned Exact Linear Time (PELT) algorithm, see
+// https://arxiv.org/abs/1101.1438.
+//
+// The cost of a segment is its sum of squared errors normalized by the
+// variance of the noise, and each additional change point costs penalty.
+//
+// Segments may be a single point long, which allows outliers to be isolated
+// in their own segment instead of distorting the segments around them.
+//
+// The returned change points are the indices of the first point of each
+// segment after the first, in increasing order.
+func peltChangePoints(trace []float32, stddev float64, penalty float64) []int {
+	n := len(trace)
+	if n < 2 {
+		return []int{}
+	}
+
+	// Prefix sums allow computing the cost of any segment in O(1).
+	sum := make([]float64, n+1)
+	sumSq := make([]float64, n+1)
+	for i, x := range trace {
+		sum[i+1] = sum[i] + float64(x)
+		sumSq[i+1] = sumSq[i] + float64(x)*float64(x)
+	}
+	variance := stddev * stddev
+	cost := func(begin, end int) float64 {
+		s := sum[end] - sum[begin]
+		sse := sumSq[end] - sumSq[begin] - s*s/float64(end-begin)
+		if sse < 0 {
+			// Guard against rounding errors.
+			sse = 0
+		}
+		return sse / variance
+	}
+
+	// best[t] is the cost of the optimal segmentation of trace[:t], and last[t]
+	// is the start of the last segment in that segmentation.
+	best := make([]float64, n+1)
+	last := make([]int, n+1)
+	best[0] = -penalty
+	candidates := []int{0}
+	for t := 1; t <= n; t++ {
+		best[t] = math.Inf(1)
+		for _, s := range candidates {
+			if c := best[s] + cost(s, t) + penalty; c < best[t] {
+				best[t] = c
+				last[t] = s
+			}
+		}
+
+		// Prune the candidates that can never be optimal again.
+		pruned := candidates[:0]
+		for _, s := range candidates {
+			if best[s]+cost(s, t) <= best[t] {
+				pruned = append(pruned, s)
+			}
+		}
+		candidates = append(pruned, t)
+	}
+
+	ret := []int{}
+	for t := last[n]; t > 0; t = last[t] {
+		ret = append(ret, t)
+	}
+	sort.Ints(ret)
+	return ret
+}
+
+// normalGamma is the posterior of a Normal-Gamma distribution over the mean and
+// precision of the points in a single run.
+type normalGamma struct {
+	mean  float64
+	kappa float64
+	alpha float64
+	beta  float64
+}
+
+// update returns the posterior after observing x.
+func (n normalGamma) update(x float64) normalGamma {
+	return normalGamma{
+		mean:  (n.kappa*n.mean + x) / (n.kappa + 1),
+		kappa: n.kappa + 1,
+		alpha: n.alpha + 0.5,
+		beta:  n.beta + n.kappa*(x-n.mean)*(x-n.mean)/(2*(n.kappa+1)),
+	}
+}
+
+// predictive returns the probability density of x under the posterior
+// predictive distribution, which is a Student's t-distribution.
+func (n normalGamma) predictive(x float64) float64 {
+	nu := 2 * n.alpha
+	scale := math.Sqrt(n.beta * (n.kappa + 1) / (n.alpha * n.kappa))
+	z := (x - n.mean) / scale
+	lg1, _ := math.Lgamma((nu + 1) / 2)
+	lg2, _ := math.Lgamma(nu / 2)
+	logPDF := lg1 - lg2 - 0.5*math.Log(nu*math.Pi) - math.Log(scale) - (nu+1)/2*math.Log1p(z*z/nu)
+	return math.Exp(logPDF)
+}
+
+// bocpdChangePointProbabilities uses Bayesian Online Change Point Detection,
+// see https://arxiv.org/abs/0710.3742, to calculate for each index i in the
+// trace the probability that a new run, i.e. a segment with a different mean
+// or variance, starts at i.
+//
+// The probability that a run starts at i changes as more points after i are
+// observed, so the returned value is the largest posterior probability seen
+// once at least minRunLength points of the run have been observed. Using a
+// minRunLength of 1 finds outliers as well as changes.
+//
+// The trace is standardized using the given stddev and hazard is the prior
+// probability of a change point at any given point.
+func bocpdChangePointProbabilities(trace []float32, stddev float64, hazard float64, minRunLength int) []float64 {
+	n := len(trace)
+	ret := make([]float64, n)
+	if n == 0 {
+		return ret
+	}
+	center := float64(meanOf(trace, 0, n))
+	var spread float64
+	for _, value := range trace {
+		x := (float64(value) - center) / stddev
+		spread += x * x
+	}
+	spread /= float64(n)
+	prior := normalGamma{
+		mean:  bocpdPriorMean,
+		kappa: 1 / math.Max(1, spread),
+		alpha: bocpdPriorAlpha,
+		beta:  bocpdPriorBeta,
+	}
+
+	// runLength[r] is the probability that the current run contains the last
+	// r+1 points, and params[r] is the posterior for that run.
+	runLength := []float64{}
+	params := []normalGamma{}
+	for t, value := range trace {
+		x := (float64(value) - center) / stddev
+		next := make([]float64, len(runLength)+1)
+		nextParams := make([]normalGamma, len(runLength)+1)
+		if t == 0 {
+			next[0] = 1
+		} else {
+			next[0] = hazard * prior.predictive(x)
+		}
+		nextParams[0] = prior.update(x)
+		total := next[0]
+		for r, p := range runLength {
+			next[r+1] = p * (1 - hazard) * params[r].predictive(x)
+			nextParams[r+1] = params[r].update(x)
+			total += next[r+1]
+		}
+		if total == 0 || math.IsNaN(total) {
+			// The point is so unlikely under every run that the densities
+			// underflowed, which is overwhelming evidence of a new run.
+			for r := range next {
+				next[r] = 0
+			}
+			next[0] = 1
+			total = 1
+		}
+		for r := range next {
+			next[r] /= total
+		}
+		runLength = next
+		params = nextParams
+
+		// Record the probability that the current run started at each index
+		// that has at least minRunLength points in the run.
+		for r := minRunLength - 1; r < len(runLength); r++ {
+			start := t - r
+			if runLength[r] > ret[start] {
+				ret[start] = runLength[r]
+			}
+		}
+	}
+	// The first point always starts a run, which isn't a change point.
+	ret[0] = 0
+	return ret
+}
+
+// segmentAround returns the bounds [begin, end) of the segments on either
+// side of the change point at i, given all the change points in the trace.
+//
+// The last return value is false if either segment is shorter than
+// minSegmentLength, i.e. the change point is just the start or the end of an
+// outlier.
+func segmentAround(changePoints []int, i, n int) (int, int, bool) {
+	begin, end := 0, n
+	for _, c := range changePoints {
+		if c < i && c > begin {
+			begin = c
+		}
+		if c > i && c < end {
+			end = c
+		}
+	}
+	return begin, end, i-begin >= minSegmentLength && end-i >= minSegmentLength
+}
@@ -0,0 +1,76 @@
+package stepfit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRobustStdDev_IgnoresStep(t *testing.T) {
+	// The differences are all 0.1 except for the step.
+	assert.InDelta(t, 0.1*madToStdDev, robustStdDev([]float32{1, 1.1, 1, 1.1, 5, 5.1, 5, 5.1}, 0.01), 0.0001)
+}
+
+func TestRobustStdDev_ConstantTrace_ReturnsThreshold(t *testing.T) {
+	assert.Equal(t, 0.5, robustStdDev([]float32{1, 1, 1, 1}, 0.5))
+}
+
+func TestRobustStdDev_SinglePoint_ReturnsThreshold(t *testing.T) {
+	assert.Equal(t, 0.5, robustStdDev([]float32{1}, 0.5))
+}
+
+func TestPeltChangePoints_NoChange(t *testing.T) {
+	assert.Equal(t, []int{}, peltChangePoints([]float32{1, 1.1, 0.9, 1, 1.1, 0.9}, 0.1, peltPenalty(6)))
+}
+
+func TestPeltChangePoints_MultipleChanges(t *testing.T) {
+	assert.Equal(t, []int{3, 6}, peltChangePoints([]float32{1, 1.1, 0.9, 5, 5.1, 4.9, 10, 10.1, 9.9}, 0.1, peltPenalty(9)))
+}
+
+func TestPeltChangePoints_Outlier_IsolatedInItsOwnSegment(t *testing.T) {
+	assert.Equal(t, []int{3, 4}, peltChangePoints([]float32{1, 1.1, 0.9, 5, 1, 1.1, 0.9}, 0.1, peltPenalty(7)))
+}
+
+func TestPeltChangePoints_EmptyTrace(t *testing.T) {
+	assert.Equal(t, []int{}, peltChangePoints([]float32{}, 0.1, peltPenalty(0)))
+}
+
+func TestBOCPDChangePointProbabilities_MultipleChanges(t *testing.T) {
+	probs := bocpdChangePointProbabilities([]float32{1, 1.1, 0.9, 5, 5.1, 4.9, 10, 10.1, 9.9}, 0.1, bocpdHazard(9), minSegmentLength)
+	assert.Len(t, probs, 9)
+	for i, p := range probs {
+		if i == 3 || i == 6 {
+			assert.Greater(t, p, 0.9, i)
+		} else {
+			assert.Less(t, p, 0.1, i)
+		}
+	}
+}
+
+func TestBOCPDChangePointProbabilities_EmptyTrace(t *testing.T) {
+	assert.Equal(t, []float64{}, bocpdChangePointProbabilities([]float32{}, 0.1, 0.1, 1))
+}
+
+func TestSegmentAround_SegmentsLongEnough_ReturnsBounds(t *testing.T) {
+	begin, end, ok := segmentAround([]int{2, 5, 9}, 5, 12)
+	assert.Equal(t, 2, begin)
+	assert.Equal(t, 9, end)
+	assert.True(t, ok)
+}
+
+func TestSegmentAround_NoOtherChangePoints_ReturnsWholeTrace(t *testing.T) {
+	begin, end, ok := segmentAround([]int{5}, 5, 12)
+	assert.Equal(t, 0, begin)
+	assert.Equal(t, 12, end)
+	assert.True(t, ok)
+}
+
+func TestSegmentAround_OutlierBeforeChangePoint_ReturnsFalse(t *testing.T) {
+	_, _, ok := segmentAround([]int{4, 5}, 5, 12)
+	assert.False(t, ok)
+}
+
+func TestSegmentAround_OutlierAtChangePoint_ReturnsFalse(t *testing.T) {
+	_, _, ok := segmentAround([]int{5, 6}, 5, 12)
+	assert.False(t, ok)
+}
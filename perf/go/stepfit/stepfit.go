@@ -25,6 +25,11 @@ const (
 
 	// minTraceSize is the smallest trace length we can analyze.
 	minTraceSize = 3
+
+	// bocpdChangePointProbability is the posterior probability above which
+	// BOCPD considers a point to be a change point when bounding the segments
+	// on either side of the turning point.
+	bocpdChangePointProbability = 0.5
 )
 
 // AllStepFitStatus is the list of all StepFitStatus values.
@@ -72,6 +77,20 @@ func NewStepFit() *StepFit {
 	}
 }
 
+// peltPenalty is the cost of adding a change point in PELT for a trace of
+// length n, which is the Bayesian Information Criterion for the two parameters,
+// location and mean, that each new segment adds.
+func peltPenalty(n int) float64 {
+	return 2 * math.Log(float64(n))
+}
+
+// bocpdHazard is the prior probability of a change point at any point in a
+// trace of length n used by BOCPD, i.e. we expect about one change point per
+// trace.
+func bocpdHazard(n int) float64 {
+	return 1 / float64(n)
+}
+
 // GetStepFitAtMid takes one []float32 trace and calculates and returns a
 // *StepFit.
 //
@@ -85,6 +104,14 @@ func NewStepFit() *StepFit {
 //
 // See StepFit for a description of the values being calculated.
 func GetStepFitAtMid(trace []float32, stddevThreshold float32, interesting float32, stepDetection types.StepDetection) *StepFit {
+	return GetStepFitAtMidWithNoise(trace, stddevThreshold, interesting, stepDetection, 0)
+}
+
+// GetStepFitAtMidWithNoise is the same as GetStepFitAtMid, but noise is the
+// previously estimated standard deviation of the noise in the trace, see
+// NoiseEstimate, which is used by types.NoiseStep. If noise is 0 then the
+// noise is estimated from the trace itself.
+func GetStepFitAtMidWithNoise(trace []float32, stddevThreshold float32, interesting float32, stepDetection types.StepDetection, noise float32) *StepFit {
 	ret := NewStepFit()
 	if len(trace) < minTraceSize {
 		return ret
@@ -174,6 +201,57 @@ func GetStepFitAtMid(trace []float32, stddevThreshold float32, interesting float
 			}
 			regression = stepSize
 		}
+	} else if stepDetection == types.PELTStep {
+		// Find all the change points in the trace and only report a step if
+		// one of them is at the turning point, measuring the step between the
+		// segments on either side of it, so other shifts in the trace don't
+		// affect the result.
+		stddev := robustStdDev(trace, stddevThreshold)
+		changePoints := peltChangePoints(trace, stddev, peltPenalty(len(trace)))
+		stepSize = 0
+		for _, c := range changePoints {
+			if c != i {
+				continue
+			}
+			if begin, end, ok := segmentAround(changePoints, i, len(trace)); ok {
+				stepSize = (meanOf(trace, begin, i) - meanOf(trace, i, end)) / float32(stddev)
+			}
+			break
+		}
+		regression = stepSize
+	} else if stepDetection == types.BOCPDStep {
+		// Regression is the posterior probability that a new run starts at
+		// the turning point, and the step is measured between the runs on
+		// either side of it. Single point runs are included when finding
+		// the other runs so that outliers next to the turning point can be
+		// recognized.
+		stddev := robustStdDev(trace, stddevThreshold)
+		hazard := bocpdHazard(len(trace))
+		probs := bocpdChangePointProbabilities(trace, stddev, hazard, minSegmentLength)
+		changePoints := []int{}
+		for c, p := range bocpdChangePointProbabilities(trace, stddev, hazard, 1) {
+			if c != i && p >= bocpdChangePointProbability {
+				changePoints = append(changePoints, c)
+			}
+		}
+		begin, end, ok := segmentAround(changePoints, i, len(trace))
+		stepSize = meanOf(trace, begin, i) - meanOf(trace, i, end)
+		regression = 0
+		if ok {
+			regression = float32(probs[i])
+		}
+	} else if stepDetection == types.NoiseStep {
+		// The step size is measured in multiples of the noise in the trace,
+		// so that noisy traces need a larger step to be flagged than quiet
+		// ones.
+		stddev := noise
+		if stddev <= 0 {
+			stddev = float32(robustStdDev(trace, stddevThreshold))
+		} else if stddev < stddevThreshold {
+			stddev = stddevThreshold
+		}
+		stepSize = y0 - y1
+		regression = stepSize / stddev
 	} else /* types.MannWhitneyU  */ {
 		s1 := vec32.ToFloat64(trace[:i])
 		s2 := vec32.ToFloat64(trace[i:])
@@ -201,6 +279,17 @@ func GetStepFitAtMid(trace []float32, stddevThreshold float32, interesting float
 				status = LOW
 			}
 		}
+	} else if stepDetection == types.BOCPDStep {
+		// For BOCPD regression is a probability, so like MannWhitneyU we need
+		// to use the sign of stepSize to determine the direction.
+		if regression >= interesting {
+			if stepSize < 0 {
+				status = HIGH
+				regression *= -1
+			} else {
+				status = LOW
+			}
+		}
 	} else {
 		if regression >= interesting {
 			status = LOW
@@ -215,3 +304,20 @@ func GetStepFitAtMid(trace []float32, stddevThreshold float32, interesting float
 	ret.Regression = regression
 	return ret
 }
+
+// NoiseEstimate returns an estimate of the standard deviation of the noise in
+// the trace that isn't inflated by the steps in the trace. Missing data points
+// are ignored. Returns 0 if the trace doesn't have enough data points to
+// estimate the noise.
+func NoiseEstimate(trace []float32) float32 {
+	values := make([]float32, 0, len(trace))
+	for _, x := range trace {
+		if x != vec32.MissingDataSentinel {
+			values = append(values, x)
+		}
+	}
+	if len(values) < minTraceSize {
+		return 0
+	}
+	return float32(robustStdDev(values, 0))
+}
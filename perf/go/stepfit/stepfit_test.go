@@ -180,3 +180,129 @@ func TestStepFit_MannWhitneyU_UninterestingBecauseNotEnoughData(t *testing.T) {
 		&StepFit{LeastSquares: 0, TurningPoint: 0, StepSize: 0, Regression: 0, Status: "Uninteresting"},
 		GetStepFitAtMid([]float32{2, 2, x}, minStdDev, 0.01, types.MannWhitneyU))
 }
+
+// multipleSteps is a trace with a step at index 3 and another step at the
+// turning point, index 6.
+var multipleSteps = []float32{1, 1.1, 0.9, 5, 5.1, 4.9, 10, 10.1, 9.9, 10, 10.05, 9.95, x}
+
+// stepAwayFromTurningPoint is a trace with a single step at index 9, which
+// isn't the turning point.
+var stepAwayFromTurningPoint = []float32{1, 1.1, 0.9, 1, 1.05, 0.95, 1, 1.1, 0.9, 3, 3.05, 2.95, x}
+
+// outlierBeforeTurningPoint is a trace with no step, just a single outlier
+// right before the turning point.
+var outlierBeforeTurningPoint = []float32{1, 1.1, 0.9, 1, 1.05, 5, 1, 1.1, 0.9, 1, 1.05, 0.95, x}
+
+func TestStepFit_PELT_StepHigh(t *testing.T) {
+	sf := GetStepFitAtMid([]float32{1, 1.1, 0.9, 1, 1.05, 0.95, 2, 2.1, 1.9, 2, 2.05, 1.95, x}, minStdDev, 2, types.PELTStep)
+	assert.Equal(t, HIGH, sf.Status)
+	assert.Equal(t, 6, sf.TurningPoint)
+	assert.Equal(t, float32(InvalidLeastSquaresError), sf.LeastSquares)
+	assert.InDelta(t, -9.54, sf.Regression, 0.01)
+	assert.Equal(t, sf.StepSize, sf.Regression)
+}
+
+func TestStepFit_PELT_StepLow(t *testing.T) {
+	sf := GetStepFitAtMid([]float32{2, 2.1, 1.9, 2, 2.05, 1.95, 1, 1.1, 0.9, 1, 1.05, 0.95, x}, minStdDev, 2, types.PELTStep)
+	assert.Equal(t, LOW, sf.Status)
+	assert.InDelta(t, 9.54, sf.Regression, 0.01)
+}
+
+func TestStepFit_PELT_MultipleSteps_OnlyMeasuresStepAtTurningPoint(t *testing.T) {
+	sf := GetStepFitAtMid(multipleSteps, minStdDev, 2, types.PELTStep)
+	assert.Equal(t, HIGH, sf.Status)
+	// The step is measured from the segment that starts at index 3, so it
+	// is 5 and not the 7 that comparing the two halves would give.
+	assert.InDelta(t, -5/robustStdDev(multipleSteps[:12], minStdDev), sf.StepSize, 0.01)
+}
+
+func TestStepFit_PELT_StepAwayFromTurningPoint_Uninteresting(t *testing.T) {
+	assert.Equal(t,
+		&StepFit{TurningPoint: 6, StepSize: 0, Status: UNINTERESTING, Regression: 0, LeastSquares: InvalidLeastSquaresError},
+		GetStepFitAtMid(stepAwayFromTurningPoint, minStdDev, 2, types.PELTStep))
+}
+
+func TestStepFit_PELT_Outlier_Uninteresting(t *testing.T) {
+	assert.Equal(t, UNINTERESTING, GetStepFitAtMid(outlierBeforeTurningPoint, minStdDev, 2, types.PELTStep).Status)
+}
+
+func TestStepFit_PELT_ConstantTrace_Uninteresting(t *testing.T) {
+	assert.Equal(t,
+		&StepFit{TurningPoint: 2, StepSize: 0, Status: UNINTERESTING, Regression: 0, LeastSquares: InvalidLeastSquaresError},
+		GetStepFitAtMid([]float32{1, 1, 1, 1, x}, minStdDev, 2, types.PELTStep))
+}
+
+func TestStepFit_BOCPD_StepHigh(t *testing.T) {
+	sf := GetStepFitAtMid([]float32{1, 1.1, 0.9, 1, 1.05, 0.95, 2, 2.1, 1.9, 2, 2.05, 1.95, x}, minStdDev, 0.5, types.BOCPDStep)
+	assert.Equal(t, HIGH, sf.Status)
+	assert.Equal(t, 6, sf.TurningPoint)
+	assert.InDelta(t, -1.0, sf.StepSize, 0.01)
+	assert.Less(t, sf.Regression, float32(-0.9))
+}
+
+func TestStepFit_BOCPD_StepLow(t *testing.T) {
+	sf := GetStepFitAtMid([]float32{2, 2.1, 1.9, 2, 2.05, 1.95, 1, 1.1, 0.9, 1, 1.05, 0.95, x}, minStdDev, 0.5, types.BOCPDStep)
+	assert.Equal(t, LOW, sf.Status)
+	assert.InDelta(t, 1.0, sf.StepSize, 0.01)
+	assert.Greater(t, sf.Regression, float32(0.9))
+}
+
+func TestStepFit_BOCPD_MultipleSteps_OnlyMeasuresStepAtTurningPoint(t *testing.T) {
+	sf := GetStepFitAtMid(multipleSteps, minStdDev, 0.5, types.BOCPDStep)
+	assert.Equal(t, HIGH, sf.Status)
+	assert.InDelta(t, -5.0, sf.StepSize, 0.01)
+}
+
+func TestStepFit_BOCPD_StepAwayFromTurningPoint_Uninteresting(t *testing.T) {
+	assert.Equal(t, UNINTERESTING, GetStepFitAtMid(stepAwayFromTurningPoint, minStdDev, 0.5, types.BOCPDStep).Status)
+}
+
+func TestStepFit_BOCPD_Outlier_Uninteresting(t *testing.T) {
+	sf := GetStepFitAtMid(outlierBeforeTurningPoint, minStdDev, 0.5, types.BOCPDStep)
+	assert.Equal(t, UNINTERESTING, sf.Status)
+	assert.Equal(t, float32(0), sf.Regression)
+}
+
+func TestStepFit_Noise_StepLargerThanNoiseMultiple_StepHigh(t *testing.T) {
+	sf := GetStepFitAtMidWithNoise([]float32{1, 1, 1, 1, 2, 2, 2, 2, x}, minStdDev, 5, types.NoiseStep, 0.2)
+	assert.Equal(t, HIGH, sf.Status)
+	assert.Equal(t, 4, sf.TurningPoint)
+	assert.Equal(t, float32(-1), sf.StepSize)
+	assert.InDelta(t, -5.0, sf.Regression, 0.01)
+}
+
+func TestStepFit_Noise_SameStepOnNoisierTrace_Uninteresting(t *testing.T) {
+	sf := GetStepFitAtMidWithNoise([]float32{1, 1, 1, 1, 2, 2, 2, 2, x}, minStdDev, 5, types.NoiseStep, 0.5)
+	assert.Equal(t, UNINTERESTING, sf.Status)
+	assert.InDelta(t, -2.0, sf.Regression, 0.01)
+}
+
+func TestStepFit_Noise_StepDown_StepLow(t *testing.T) {
+	sf := GetStepFitAtMidWithNoise([]float32{2, 2, 2, 2, 1, 1, 1, 1, x}, minStdDev, 5, types.NoiseStep, 0.2)
+	assert.Equal(t, LOW, sf.Status)
+	assert.InDelta(t, 5.0, sf.Regression, 0.01)
+}
+
+func TestStepFit_Noise_NoiseBelowThreshold_ThresholdIsUsed(t *testing.T) {
+	sf := GetStepFitAtMidWithNoise([]float32{1, 1, 1, 1, 2, 2, 2, 2, x}, minStdDev, 5, types.NoiseStep, 0.01)
+	assert.InDelta(t, -10.0, sf.Regression, 0.01)
+}
+
+func TestStepFit_Noise_NoNoiseEstimate_NoiseIsEstimatedFromTrace(t *testing.T) {
+	trace := []float32{1, 1.1, 0.9, 1, 1.05, 0.95, 2, 2.1, 1.9, 2, 2.05, 1.95, x}
+	sf := GetStepFitAtMid(trace, minStdDev, 2, types.NoiseStep)
+	assert.Equal(t, HIGH, sf.Status)
+	assert.InDelta(t, -1/robustStdDev(trace[:12], minStdDev), sf.Regression, 0.01)
+}
+
+func TestNoiseEstimate_StepInTrace_EstimateIsNotInflatedByStep(t *testing.T) {
+	assert.InDelta(t, 0.1*madToStdDev, NoiseEstimate([]float32{1, 1.1, 1, 1.1, 1, 11, 11.1, 11, 11.1, 11}), 0.001)
+}
+
+func TestNoiseEstimate_MissingDataIsIgnored(t *testing.T) {
+	assert.InDelta(t, 0.1*madToStdDev, NoiseEstimate([]float32{1, x, 1.1, 1, x, 1.1, 1}), 0.001)
+}
+
+func TestNoiseEstimate_TooFewPoints_ReturnsZero(t *testing.T) {
+	assert.Equal(t, float32(0), NoiseEstimate([]float32{1, x, 2}))
+}
@@ -0,0 +1,129 @@
+// Package localsubscriptionstore implements subscription.Store using a
+// localstore.DB.
+package localsubscriptionstore
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/jackc/pgx/v4"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/subscription"
+	pb "go.skia.org/infra/perf/go/subscription/proto/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// subscriptionsTable is the name of the table in the localstore.DB.
+const subscriptionsTable = "subscriptions"
+
+// subscriptionKey is the unique key of a subscription.
+type subscriptionKey struct {
+	Name     string
+	Revision string
+}
+
+// table is the data stored in the subscriptions table.
+type table struct {
+	// Subscriptions maps keys to the serialized pb.Subscription.
+	Subscriptions map[subscriptionKey][]byte
+}
+
+// SubscriptionStore implements the subscription.Store interface using a
+// localstore.DB.
+type SubscriptionStore struct {
+	db *localstore.DB
+
+	// mutex protects data.
+	mutex sync.Mutex
+	data  table
+}
+
+// New returns a new *SubscriptionStore.
+func New(db *localstore.DB) (*SubscriptionStore, error) {
+	ret := &SubscriptionStore{
+		db: db,
+		data: table{
+			Subscriptions: map[subscriptionKey][]byte{},
+		},
+	}
+	if err := db.Read(subscriptionsTable, &ret.data); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return ret, nil
+}
+
+// GetSubscription implements the subscription.Store interface.
+func (s *SubscriptionStore) GetSubscription(ctx context.Context, name string, revision string) (*pb.Subscription, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	b, ok := s.data.Subscriptions[subscriptionKey{Name: name, Revision: revision}]
+	if !ok {
+		return nil, skerr.Fmt("Failed to load subscription.")
+	}
+	sub := &pb.Subscription{}
+	if err := proto.Unmarshal(b, sub); err != nil {
+		return nil, skerr.Wrapf(err, "Failed to parse subscription.")
+	}
+	return sub, nil
+}
+
+// InsertSubscriptions implements the subscription.Store interface.
+//
+// The local store has no SQL transactions, so tx is ignored.
+func (s *SubscriptionStore) InsertSubscriptions(ctx context.Context, subs []*pb.Subscription, _ pgx.Tx) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Build up the new table and only swap it in once it has been written, so
+	// that the insert is all or nothing.
+	newData := table{
+		Subscriptions: make(map[subscriptionKey][]byte, len(s.data.Subscriptions)+len(subs)),
+	}
+	for key, b := range s.data.Subscriptions {
+		newData.Subscriptions[key] = b
+	}
+	for _, sub := range subs {
+		key := subscriptionKey{Name: sub.Name, Revision: sub.Revision}
+		if _, ok := newData.Subscriptions[key]; ok {
+			return skerr.Fmt("Subscription %q at revision %q already exists.", sub.Name, sub.Revision)
+		}
+		b, err := proto.Marshal(sub)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		newData.Subscriptions[key] = b
+	}
+	if err := s.db.Write(subscriptionsTable, newData); err != nil {
+		return skerr.Wrap(err)
+	}
+	s.data = newData
+	return nil
+}
+
+// GetAllSubscriptions implements the subscription.Store interface.
+func (s *SubscriptionStore) GetAllSubscriptions(ctx context.Context) ([]*pb.Subscription, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	subscriptions := []*pb.Subscription{}
+	for _, b := range s.data.Subscriptions {
+		sub := &pb.Subscription{}
+		if err := proto.Unmarshal(b, sub); err != nil {
+			return nil, skerr.Wrapf(err, "Failed to parse subscriptions.")
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if subscriptions[i].Name == subscriptions[j].Name {
+			return subscriptions[i].Revision < subscriptions[j].Revision
+		}
+		return subscriptions[i].Name < subscriptions[j].Name
+	})
+	return subscriptions, nil
+}
+
+// Confirm that *SubscriptionStore implements subscription.Store.
+var _ subscription.Store = (*SubscriptionStore)(nil)
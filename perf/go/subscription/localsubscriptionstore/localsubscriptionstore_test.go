@@ -0,0 +1,48 @@
+package localsubscriptionstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/localstore"
+	pb "go.skia.org/infra/perf/go/subscription/proto/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestInsertAndGet_Success(t *testing.T) {
+	ctx := context.Background()
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	store, err := New(db)
+	require.NoError(t, err)
+
+	subs := []*pb.Subscription{
+		{Name: "Test Subscription 2", Revision: "abcd", BugComponent: "Component>Subcomponent", ContactEmail: "test@example.org"},
+		{Name: "Test Subscription 1", Revision: "abcd", BugLabels: []string{"A", "B"}, BugPriority: 1},
+	}
+	require.NoError(t, store.InsertSubscriptions(ctx, subs, nil))
+
+	// Inserting a duplicate fails and doesn't change the store.
+	require.Error(t, store.InsertSubscriptions(ctx, []*pb.Subscription{
+		{Name: "Test Subscription 3", Revision: "abcd"},
+		{Name: "Test Subscription 1", Revision: "abcd"},
+	}, nil))
+
+	// Reopen the store and confirm the subscriptions are still there.
+	store, err = New(db)
+	require.NoError(t, err)
+	sub, err := store.GetSubscription(ctx, "Test Subscription 2", "abcd")
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(subs[0], sub))
+
+	_, err = store.GetSubscription(ctx, "Test Subscription 2", "efgh")
+	require.Error(t, err)
+
+	all, err := store.GetAllSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.True(t, proto.Equal(subs[1], all[0]))
+	assert.True(t, proto.Equal(subs[0], all[1]))
+}
@@ -7,6 +7,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	pgx "github.com/jackc/pgx/v4"
+
 	v1 "go.skia.org/infra/perf/go/subscription/proto/v1"
 )
 
@@ -75,17 +77,17 @@ func (_m *Store) GetSubscription(ctx context.Context, name string, revision stri
 	return r0, r1
 }
 
-// InsertSubscriptions provides a mock function with given fields: ctx, _a1
-func (_m *Store) InsertSubscriptions(ctx context.Context, _a1 []*v1.Subscription) error {
-	ret := _m.Called(ctx, _a1)
+// InsertSubscriptions provides a mock function with given fields: ctx, _a1, tx
+func (_m *Store) InsertSubscriptions(ctx context.Context, _a1 []*v1.Subscription, tx pgx.Tx) error {
+	ret := _m.Called(ctx, _a1, tx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for InsertSubscriptions")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, []*v1.Subscription) error); ok {
-		r0 = rf(ctx, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, []*v1.Subscription, pgx.Tx) error); ok {
+		r0 = rf(ctx, _a1, tx)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -5,6 +5,7 @@ package sqlsubscriptionstore
 import (
 	"context"
 
+	"github.com/jackc/pgx/v4"
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/sklog"
 	"go.skia.org/infra/go/sql/pool"
@@ -88,22 +89,32 @@ func (s *SubscriptionStore) GetSubscription(ctx context.Context, name string, re
 }
 
 // InsertSubscriptions implements the subscription.Store interface.
-func (s *SubscriptionStore) InsertSubscriptions(ctx context.Context, subs []*pb.Subscription) error {
+func (s *SubscriptionStore) InsertSubscriptions(ctx context.Context, subs []*pb.Subscription, tx pgx.Tx) error {
+	if tx != nil {
+		return insertSubscriptionsInTx(ctx, subs, tx)
+	}
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return skerr.Wrap(err)
 	}
 
+	if err := insertSubscriptionsInTx(ctx, subs, tx); err != nil {
+		if err := tx.Rollback(ctx); err != nil {
+			sklog.Errorf("Failed on rollback: %s", err)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func insertSubscriptionsInTx(ctx context.Context, subs []*pb.Subscription, tx pgx.Tx) error {
 	for _, sub := range subs {
 		if _, err := tx.Exec(ctx, statements[insertSubscription], sub.Name, sub.Revision, sub.BugLabels, sub.Hotlists, sub.BugComponent, sub.BugPriority, sub.BugSeverity, sub.BugCcEmails, sub.ContactEmail); err != nil {
-			if err := tx.Rollback(ctx); err != nil {
-				sklog.Errorf("Failed on rollback: %s", err)
-			}
 			return skerr.Wrap(err)
 		}
 	}
-
-	return tx.Commit(ctx)
+	return nil
 }
 
 // GetAllSubscriptions implements the subscription.Store interface.
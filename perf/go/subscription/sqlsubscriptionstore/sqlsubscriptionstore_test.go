@@ -56,7 +56,7 @@ func TestInsert_ValidSubscriptions(t *testing.T) {
 		},
 	}
 
-	err := store.InsertSubscriptions(ctx, s)
+	err := store.InsertSubscriptions(ctx, s, nil)
 	require.NoError(t, err)
 
 	actual := getSubscriptionsFromDb(t, ctx, db)
@@ -101,7 +101,7 @@ func TestInsert_DuplicateSubscriptionKeys(t *testing.T) {
 		},
 	}
 
-	err := store.InsertSubscriptions(ctx, s)
+	err := store.InsertSubscriptions(ctx, s, nil)
 	require.Error(t, err)
 
 	actual := getSubscriptionsFromDb(t, ctx, db)
@@ -114,7 +114,7 @@ func TestInsert_EmptyList(t *testing.T) {
 
 	s := []*pb.Subscription{}
 
-	err := store.InsertSubscriptions(ctx, s)
+	err := store.InsertSubscriptions(ctx, s, nil)
 	require.NoError(t, err)
 
 	actual := getSubscriptionsFromDb(t, ctx, db)
@@ -4,6 +4,7 @@ package subscription
 import (
 	"context"
 
+	"github.com/jackc/pgx/v4"
 	pb "go.skia.org/infra/perf/go/subscription/proto/v1"
 )
 
@@ -13,7 +14,10 @@ type Store interface {
 	GetSubscription(ctx context.Context, name string, revision string) (*pb.Subscription, error)
 
 	// InsertSubscriptions inserts multiple subscription.
-	InsertSubscriptions(ctx context.Context, subscription []*pb.Subscription) error
+	//
+	// If tx is not nil then the inserts are made as part of that transaction,
+	// otherwise the store uses its own transaction.
+	InsertSubscriptions(ctx context.Context, subscription []*pb.Subscription, tx pgx.Tx) error
 
 	// GetAllSubscriptions gets all the subscriptions unique by name
 	GetAllSubscriptions(ctx context.Context) ([]*pb.Subscription, error)
@@ -0,0 +1,997 @@
+// Package localtracestore implements tracestore.TraceStore on top of a
+// localstore.DB, an embedded file database, so that a single perfserver can
+// run without any external services.
+//
+// Each tile is stored as its own table in the database, and all the tiles
+// that have been read or written are kept in memory, so this implementation
+// is only appropriate for small instances. The ingester, the frontend, and
+// the maintenance job can share the same database, since every table kept in
+// memory is reloaded once its localstore.Version changes, and every write
+// holds the localstore.DB Lock from reading a table to writing it back, so
+// writes from different processes don't overwrite each other. Cached tiles are
+// never modified, all writes are made to a copy of the tile that replaces the
+// cached tile once it has been written to the database.
+package localtracestore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/query"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/go/vec32"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/tracestore"
+	"go.skia.org/infra/perf/go/types"
+)
+
+const (
+	// tileTablePrefix is the prefix of the name of each tile table.
+	tileTablePrefix = "tile-"
+
+	// sourceFilesTable is the name of the table that stores the names of all
+	// the ingested files.
+	sourceFilesTable = "sourcefiles"
+
+	// traceMetadataTable is the name of the table that stores the metadata of
+	// all the traces.
+	traceMetadataTable = "tracemetadata"
+
+	// noSource is stored in traceData.Sources for points that have no value.
+	noSource = -1
+
+	// queryTracesIDOnlyChannelSize is the size of the channel returned from
+	// QueryTracesIDOnly.
+	queryTracesIDOnlyChannelSize = 10000
+)
+
+// CommitSource is the subset of perfgit.Git that LocalTraceStore needs to
+// populate the commits returned from the Read* and Query* methods.
+type CommitSource interface {
+	// CommitSliceFromCommitNumberRange returns a slice of Commits that fall
+	// in the range [begin, end], i.e  inclusive of both begin and end.
+	CommitSliceFromCommitNumberRange(ctx context.Context, begin, end types.CommitNumber) ([]provider.Commit, error)
+}
+
+// traceData is all the data stored for a single trace in a single tile.
+type traceData struct {
+	// Values has one entry per commit in the tile, with
+	// vec32.MissingDataSentinel where there is no data.
+	Values []float32
+
+	// Sources is parallel to Values and contains the index into the source
+	// files table of the file that supplied each value, or noSource.
+	Sources []int64
+
+	// Samples is nil if no samples have been written for the trace, otherwise
+	// it is parallel to Values and contains the raw samples each value was
+	// computed from.
+	Samples [][]float32
+}
+
+// copy returns a copy of the trace data that can be modified without changing
+// t. The individual slices of samples are shared since they are only ever
+// replaced, never modified.
+func (t *traceData) copy() *traceData {
+	ret := &traceData{
+		Values:  append(vec32.New(0), t.Values...),
+		Sources: append([]int64{}, t.Sources...),
+	}
+	if t.Samples != nil {
+		ret.Samples = append([][]float32{}, t.Samples...)
+	}
+	return ret
+}
+
+// newTraceData returns a new *traceData for a tile of the given size with no
+// values.
+func newTraceData(tileSize int32) *traceData {
+	ret := &traceData{
+		Values:  vec32.New(int(tileSize)),
+		Sources: make([]int64, tileSize),
+	}
+	for j := range ret.Sources {
+		ret.Sources[j] = noSource
+	}
+	return ret
+}
+
+// tileData is the data stored in each tile table.
+type tileData struct {
+	// Traces maps trace names to their data.
+	Traces map[string]*traceData
+
+	// ParamSet is the ParamSet of all the traces in the tile.
+	ParamSet paramtools.ParamSet
+}
+
+// copy returns a copy of the tile whose Traces map and ParamSet can be
+// modified without changing t. The traceData is shared, so each trace must be
+// copied before it is modified.
+func (t *tileData) copy() *tileData {
+	ret := &tileData{
+		Traces:   make(map[string]*traceData, len(t.Traces)),
+		ParamSet: t.ParamSet.Copy(),
+	}
+	for traceName, trace := range t.Traces {
+		ret.Traces[traceName] = trace
+	}
+	return ret
+}
+
+// sourceFiles is the data stored in the source files table.
+type sourceFiles struct {
+	Filenames []string
+}
+
+// traceMetadata is the data stored in the trace metadata table.
+type traceMetadata struct {
+	// Traces maps trace names to their metadata.
+	Traces map[string]tracestore.TraceMetadata
+}
+
+// LocalTraceStore implements tracestore.TraceStore backed onto a
+// localstore.DB.
+type LocalTraceStore struct {
+	db *localstore.DB
+
+	commits CommitSource
+
+	// tileSize is the number of commits per Tile.
+	tileSize int32
+
+	// mutex protects tiles, tileVersions, sources, sourcesVersion,
+	// sourceIndex, metadata, and metadataVersion.
+	mutex sync.RWMutex
+
+	// tiles are the tiles loaded from the database.
+	tiles map[types.TileNumber]*tileData
+
+	// tileVersions are the versions of the tables the tiles were loaded from.
+	tileVersions map[types.TileNumber]localstore.Version
+
+	// sources is the list of all source files.
+	sources sourceFiles
+
+	// sourcesVersion is the version of the table sources was loaded from.
+	sourcesVersion localstore.Version
+
+	// sourceIndex maps source filenames to their index in sources.
+	sourceIndex map[string]int64
+
+	// metadata is the metadata of all the traces.
+	metadata traceMetadata
+
+	// metadataVersion is the version of the table metadata was loaded from.
+	metadataVersion localstore.Version
+}
+
+// New returns a new *LocalTraceStore.
+func New(db *localstore.DB, commits CommitSource, datastoreConfig config.DataStoreConfig) (*LocalTraceStore, error) {
+	if datastoreConfig.TileSize <= 0 {
+		return nil, skerr.Fmt("Invalid tile_size: %d", datastoreConfig.TileSize)
+	}
+	ret := &LocalTraceStore{
+		db:           db,
+		commits:      commits,
+		tileSize:     datastoreConfig.TileSize,
+		tiles:        map[types.TileNumber]*tileData{},
+		tileVersions: map[types.TileNumber]localstore.Version{},
+		sourceIndex:  map[string]int64{},
+		metadata: traceMetadata{
+			Traces: map[string]tracestore.TraceMetadata{},
+		},
+	}
+	if err := ret.loadSources(); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	if err := ret.loadMetadata(); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return ret, nil
+}
+
+// loadSources reloads the source files table if it has changed since it was
+// last loaded. The caller must hold s.mutex for writing.
+func (s *LocalTraceStore) loadSources() error {
+	version, err := s.db.Version(sourceFilesTable)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if version == s.sourcesVersion {
+		return nil
+	}
+	var sources sourceFiles
+	version, err = s.db.ReadVersion(sourceFilesTable, &sources)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	s.sources = sources
+	s.sourcesVersion = version
+	s.sourceIndex = make(map[string]int64, len(sources.Filenames))
+	for i, filename := range sources.Filenames {
+		s.sourceIndex[filename] = int64(i)
+	}
+	return nil
+}
+
+// loadMetadata reloads the trace metadata table if it has changed since it
+// was last loaded. The caller must hold s.mutex for writing.
+func (s *LocalTraceStore) loadMetadata() error {
+	version, err := s.db.Version(traceMetadataTable)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if version == s.metadataVersion {
+		return nil
+	}
+	var metadata traceMetadata
+	version, err = s.db.ReadVersion(traceMetadataTable, &metadata)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if metadata.Traces == nil {
+		metadata.Traces = map[string]tracestore.TraceMetadata{}
+	}
+	s.metadata = metadata
+	s.metadataVersion = version
+	return nil
+}
+
+// sourceFilename returns the name of the source file with the given index.
+// The caller must hold s.mutex.
+func (s *LocalTraceStore) sourceFilename(index int64) (string, error) {
+	if index < 0 || index >= int64(len(s.sources.Filenames)) {
+		return "", skerr.Fmt("Unknown source file index: %d", index)
+	}
+	return s.sources.Filenames[index], nil
+}
+
+func tableNameForTile(tileNumber types.TileNumber) string {
+	return fmt.Sprintf("%s%08d", tileTablePrefix, tileNumber)
+}
+
+// tileNumbers returns all the tile numbers in the database in ascending
+// order.
+func (s *LocalTraceStore) tileNumbers() ([]types.TileNumber, error) {
+	tables, err := s.db.Tables(tileTablePrefix)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	ret := make([]types.TileNumber, 0, len(tables))
+	for _, table := range tables {
+		tileNumber, err := strconv.Atoi(strings.TrimPrefix(table, tileTablePrefix))
+		if err != nil {
+			sklog.Warningf("Found invalid tile table name: %q", table)
+			continue
+		}
+		ret = append(ret, types.TileNumber(tileNumber))
+	}
+	return ret, nil
+}
+
+// getTile returns the tile for the given tile number, loading it from the
+// database if it isn't cached or the cached copy is out of date. If the tile
+// doesn't exist then nil is returned. The returned tile must not be
+// modified. The caller must hold s.mutex for writing.
+func (s *LocalTraceStore) getTile(tileNumber types.TileNumber) (*tileData, error) {
+	table := tableNameForTile(tileNumber)
+	version, err := s.db.Version(table)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	if tile, ok := s.tiles[tileNumber]; ok && version == s.tileVersions[tileNumber] {
+		return tile, nil
+	}
+	delete(s.tiles, tileNumber)
+	delete(s.tileVersions, tileNumber)
+	if version == 0 {
+		return nil, nil
+	}
+	tile := &tileData{}
+	version, err = s.db.ReadVersion(table, tile)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	if version == 0 {
+		// The tile was dropped after we checked the version.
+		return nil, nil
+	}
+	if tile.Traces == nil {
+		tile.Traces = map[string]*traceData{}
+	}
+	if tile.ParamSet == nil {
+		tile.ParamSet = paramtools.NewParamSet()
+	}
+	s.tiles[tileNumber] = tile
+	s.tileVersions[tileNumber] = version
+	return tile, nil
+}
+
+// readTile is like getTile, but only needs the caller to not hold the mutex.
+func (s *LocalTraceStore) readTile(tileNumber types.TileNumber) (*tileData, error) {
+	version, err := s.db.Version(tableNameForTile(tileNumber))
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	s.mutex.RLock()
+	tile, ok := s.tiles[tileNumber]
+	current := ok && version == s.tileVersions[tileNumber]
+	s.mutex.RUnlock()
+	if current {
+		return tile, nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.getTile(tileNumber)
+}
+
+// StartBackgroundMetricsGathering implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) StartBackgroundMetricsGathering() {
+	// There are no metrics to gather for the local trace store.
+}
+
+// CommitNumberOfTileStart implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) CommitNumberOfTileStart(commitNumber types.CommitNumber) types.CommitNumber {
+	tileNumber := types.TileNumberFromCommitNumber(commitNumber, s.tileSize)
+	beginCommit, _ := types.TileCommitRangeForTileNumber(tileNumber, s.tileSize)
+	return beginCommit
+}
+
+// GetLatestTile implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) GetLatestTile(ctx context.Context) (types.TileNumber, error) {
+	tileNumbers, err := s.tileNumbers()
+	if err != nil {
+		return types.BadTileNumber, skerr.Wrap(err)
+	}
+	if len(tileNumbers) == 0 {
+		return types.BadTileNumber, skerr.Fmt("No tiles found.")
+	}
+	return tileNumbers[len(tileNumbers)-1], nil
+}
+
+// GetParamSet implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) GetParamSet(ctx context.Context, tileNumber types.TileNumber) (paramtools.ReadOnlyParamSet, error) {
+	tile, err := s.readTile(tileNumber)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	if tile == nil {
+		return paramtools.NewReadOnlyParamSet(), nil
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return tile.ParamSet.FrozenCopy(), nil
+}
+
+// GetSource implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) GetSource(ctx context.Context, commitNumber types.CommitNumber, traceName string) (string, error) {
+	tile, err := s.readTile(s.TileNumber(commitNumber))
+	if err != nil {
+		return "", skerr.Wrap(err)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// The tile may have been written by another process after the sources
+	// were last loaded.
+	if err := s.loadSources(); err != nil {
+		return "", skerr.Wrap(err)
+	}
+	if tile != nil {
+		if trace, ok := tile.Traces[traceName]; ok {
+			if index := trace.Sources[s.OffsetFromCommitNumber(commitNumber)]; index != noSource {
+				return s.sourceFilename(index)
+			}
+		}
+	}
+	return "", skerr.Fmt("No source found for commitNumber=%d traceName=%q", commitNumber, traceName)
+}
+
+// GetLastNSources implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) GetLastNSources(ctx context.Context, traceID string, n int) ([]tracestore.Source, error) {
+	tileNumbers, err := s.tileNumbers()
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	ret := []tracestore.Source{}
+	for i := len(tileNumbers) - 1; i >= 0 && len(ret) < n; i-- {
+		tileNumber := tileNumbers[i]
+		tile, err := s.readTile(tileNumber)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		if tile == nil {
+			continue
+		}
+		trace, ok := tile.Traces[traceID]
+		if !ok {
+			continue
+		}
+		s.mutex.Lock()
+		if err := s.loadSources(); err != nil {
+			s.mutex.Unlock()
+			return nil, skerr.Wrap(err)
+		}
+		beginCommit, _ := types.TileCommitRangeForTileNumber(tileNumber, s.tileSize)
+		for offset := len(trace.Sources) - 1; offset >= 0 && len(ret) < n; offset-- {
+			index := trace.Sources[offset]
+			if index == noSource {
+				continue
+			}
+			filename, err := s.sourceFilename(index)
+			if err != nil {
+				s.mutex.Unlock()
+				return nil, skerr.Wrap(err)
+			}
+			ret = append(ret, tracestore.Source{
+				Filename:     filename,
+				CommitNumber: beginCommit + types.CommitNumber(offset),
+			})
+		}
+		s.mutex.Unlock()
+	}
+	return ret, nil
+}
+
+// GetTraceIDs implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) GetTraceIDs(ctx context.Context, tileNumber types.TileNumber) ([]string, error) {
+	tile, err := s.readTile(tileNumber)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	ret := []string{}
+	if tile == nil {
+		return ret, nil
+	}
+	for traceName := range tile.Traces {
+		ret = append(ret, traceName)
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// GetTraceIDsBySource implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) GetTraceIDsBySource(ctx context.Context, sourceFilename string, tileNumber types.TileNumber) ([]string, error) {
+	tile, err := s.readTile(tileNumber)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.loadSources(); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	ret := []string{}
+	index, ok := s.sourceIndex[sourceFilename]
+	if tile == nil || !ok {
+		return ret, nil
+	}
+	for traceName, trace := range tile.Traces {
+		for _, sourceIndex := range trace.Sources {
+			if sourceIndex == index {
+				ret = append(ret, traceName)
+				break
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// OffsetFromCommitNumber implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) OffsetFromCommitNumber(commitNumber types.CommitNumber) int32 {
+	return int32(commitNumber) % s.tileSize
+}
+
+// matchingTraceNames returns the names of all the traces in the given tile
+// that match the query.
+func (s *LocalTraceStore) matchingTraceNames(tileNumber types.TileNumber, q *query.Query) ([]string, error) {
+	tile, err := s.readTile(tileNumber)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	ret := []string{}
+	if tile == nil {
+		return ret, nil
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for traceName := range tile.Traces {
+		if q.Matches(traceName) {
+			ret = append(ret, traceName)
+		}
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// QueryTraces implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) QueryTraces(ctx context.Context, tileNumber types.TileNumber, q *query.Query) (types.TraceSet, []provider.Commit, error) {
+	if q.Empty() {
+		return nil, nil, skerr.Fmt("Can't run QueryTraces for the empty query.")
+	}
+	traceNames, err := s.matchingTraceNames(tileNumber, q)
+	if err != nil {
+		return nil, nil, skerr.Wrap(err)
+	}
+	return s.ReadTraces(ctx, tileNumber, traceNames)
+}
+
+// QueryTracesIDOnly implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) QueryTracesIDOnly(ctx context.Context, tileNumber types.TileNumber, q *query.Query) (<-chan paramtools.Params, error) {
+	outParams := make(chan paramtools.Params, queryTracesIDOnlyChannelSize)
+	if q.Empty() {
+		close(outParams)
+		return outParams, skerr.Fmt("Can't run QueryTracesIDOnly for the empty query.")
+	}
+	traceNames, err := s.matchingTraceNames(tileNumber, q)
+	if err != nil {
+		close(outParams)
+		return outParams, skerr.Wrap(err)
+	}
+	go func() {
+		defer close(outParams)
+		for _, traceName := range traceNames {
+			p, err := query.ParseKey(traceName)
+			if err != nil {
+				sklog.Warningf("Invalid trace name found in tile: %q", traceName)
+				continue
+			}
+			select {
+			case outParams <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return outParams, nil
+}
+
+// ReadTraces implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) ReadTraces(ctx context.Context, tileNumber types.TileNumber, keys []string) (types.TraceSet, []provider.Commit, error) {
+	beginCommit, endCommit := types.TileCommitRangeForTileNumber(tileNumber, s.tileSize)
+	return s.ReadTracesForCommitRange(ctx, keys, beginCommit, endCommit)
+}
+
+// ReadTracesForCommitRange implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) ReadTracesForCommitRange(ctx context.Context, keys []string, beginCommit types.CommitNumber, endCommit types.CommitNumber) (types.TraceSet, []provider.Commit, error) {
+	if beginCommit > endCommit {
+		return nil, nil, skerr.Fmt("Invalid commit range, [%d, %d] should be [%d, %d]", beginCommit, endCommit, endCommit, beginCommit)
+	}
+	commits, err := s.commits.CommitSliceFromCommitNumberRange(ctx, beginCommit, endCommit)
+	if err != nil {
+		return nil, nil, skerr.Wrapf(err, "Failed to load commits in range [%d, %d]", beginCommit, endCommit)
+	}
+
+	ret := types.TraceSet{}
+	for _, key := range keys {
+		if !query.IsValid(key) {
+			sklog.Errorf("Invalid key: %q", key)
+			continue
+		}
+		ret[key] = vec32.New(len(commits))
+	}
+
+	var tile *tileData
+	tileNumber := types.BadTileNumber
+	for i, commit := range commits {
+		if n := s.TileNumber(commit.CommitNumber); n != tileNumber {
+			tileNumber = n
+			tile, err = s.readTile(tileNumber)
+			if err != nil {
+				return nil, nil, skerr.Wrap(err)
+			}
+		}
+		if tile == nil {
+			continue
+		}
+		offset := s.OffsetFromCommitNumber(commit.CommitNumber)
+		s.mutex.RLock()
+		for key, values := range ret {
+			if trace, ok := tile.Traces[key]; ok {
+				values[i] = trace.Values[offset]
+			}
+		}
+		s.mutex.RUnlock()
+	}
+
+	return ret, commits, nil
+}
+
+// TileNumber implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) TileNumber(commitNumber types.CommitNumber) types.TileNumber {
+	return types.TileNumberFromCommitNumber(commitNumber, s.tileSize)
+}
+
+// TileSize implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) TileSize() int32 {
+	return s.tileSize
+}
+
+// TraceCount implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) TraceCount(ctx context.Context, tileNumber types.TileNumber) (int64, error) {
+	tile, err := s.readTile(tileNumber)
+	if err != nil {
+		return 0, skerr.Wrap(err)
+	}
+	if tile == nil {
+		return 0, nil
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return int64(len(tile.Traces)), nil
+}
+
+// WriteTraces implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) WriteTraces(ctx context.Context, commitNumber types.CommitNumber, params []paramtools.Params, values []float32, ps paramtools.ParamSet, source string, _ time.Time) error {
+	if len(params) != len(values) {
+		return skerr.Fmt("params and values must be the same length: %d != %d", len(params), len(values))
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	unlock, err := s.db.Lock()
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	defer unlock()
+
+	tileNumber := s.TileNumber(commitNumber)
+	tile, err := s.getTile(tileNumber)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if tile == nil {
+		tile = &tileData{
+			Traces:   map[string]*traceData{},
+			ParamSet: paramtools.NewParamSet(),
+		}
+	} else {
+		tile = tile.copy()
+	}
+
+	if err := s.loadSources(); err != nil {
+		return skerr.Wrap(err)
+	}
+	sourceIndex, ok := s.sourceIndex[source]
+	if !ok {
+		sourceIndex = int64(len(s.sources.Filenames))
+		newSources := sourceFiles{
+			Filenames: append(append([]string{}, s.sources.Filenames...), source),
+		}
+		version, err := s.db.WriteVersion(sourceFilesTable, newSources)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		s.sources = newSources
+		s.sourcesVersion = version
+		s.sourceIndex[source] = sourceIndex
+	}
+
+	offset := s.OffsetFromCommitNumber(commitNumber)
+	for i, p := range params {
+		traceName, err := query.MakeKey(p)
+		if err != nil {
+			sklog.Errorf("Somehow still invalid: %v", p)
+			continue
+		}
+		trace, ok := tile.Traces[traceName]
+		if ok {
+			trace = trace.copy()
+		} else {
+			trace = newTraceData(s.tileSize)
+		}
+		tile.Traces[traceName] = trace
+		trace.Values[offset] = values[i]
+		trace.Sources[offset] = sourceIndex
+	}
+	tile.ParamSet.AddParamSet(ps)
+	tile.ParamSet.Normalize()
+
+	version, err := s.db.WriteVersion(tableNameForTile(tileNumber), tile)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	s.tiles[tileNumber] = tile
+	s.tileVersions[tileNumber] = version
+	return nil
+}
+
+// ReadSamples implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) ReadSamples(ctx context.Context, points []tracestore.TracePoint) (map[tracestore.TracePoint][]float32, error) {
+	tiles := map[types.TileNumber]*tileData{}
+	for _, point := range points {
+		tileNumber := s.TileNumber(point.CommitNumber)
+		if _, ok := tiles[tileNumber]; ok {
+			continue
+		}
+		tile, err := s.readTile(tileNumber)
+		if err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		tiles[tileNumber] = tile
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	ret := map[tracestore.TracePoint][]float32{}
+	for _, point := range points {
+		tile := tiles[s.TileNumber(point.CommitNumber)]
+		if tile == nil {
+			continue
+		}
+		trace, ok := tile.Traces[point.TraceName]
+		if !ok || trace.Samples == nil {
+			continue
+		}
+		if samples := trace.Samples[s.OffsetFromCommitNumber(point.CommitNumber)]; samples != nil {
+			ret[point] = append([]float32{}, samples...)
+		}
+	}
+	return ret, nil
+}
+
+// WriteSamples implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) WriteSamples(ctx context.Context, commitNumber types.CommitNumber, params []paramtools.Params, samples [][]float32) error {
+	if len(params) != len(samples) {
+		return skerr.Fmt("params and samples must be the same length: %d != %d", len(params), len(samples))
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	unlock, err := s.db.Lock()
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	defer unlock()
+
+	tileNumber := s.TileNumber(commitNumber)
+	tile, err := s.getTile(tileNumber)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	if tile == nil {
+		// Samples are written after the values, so there's nothing to attach
+		// them to.
+		return skerr.Fmt("No tile found for commitNumber=%d", commitNumber)
+	}
+	tile = tile.copy()
+
+	offset := s.OffsetFromCommitNumber(commitNumber)
+	for i, p := range params {
+		if len(samples[i]) == 0 {
+			continue
+		}
+		traceName, err := query.MakeKey(p)
+		if err != nil {
+			sklog.Errorf("Somehow still invalid: %v", p)
+			continue
+		}
+		trace, ok := tile.Traces[traceName]
+		if ok {
+			trace = trace.copy()
+		} else {
+			trace = newTraceData(s.tileSize)
+		}
+		tile.Traces[traceName] = trace
+		if trace.Samples == nil {
+			trace.Samples = make([][]float32, s.tileSize)
+		}
+		trace.Samples[offset] = append([]float32{}, samples[i]...)
+	}
+
+	version, err := s.db.WriteVersion(tableNameForTile(tileNumber), tile)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	s.tiles[tileNumber] = tile
+	s.tileVersions[tileNumber] = version
+	return nil
+}
+
+// ReadTraceMetadata implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) ReadTraceMetadata(ctx context.Context, traceNames []string) (map[string]tracestore.TraceMetadata, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.loadMetadata(); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	ret := map[string]tracestore.TraceMetadata{}
+	for _, traceName := range traceNames {
+		if m, ok := s.metadata.Traces[traceName]; ok {
+			ret[traceName] = m
+		}
+	}
+	return ret, nil
+}
+
+// WriteTraceMetadata implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) WriteTraceMetadata(ctx context.Context, params []paramtools.Params, metadata []tracestore.TraceMetadata) error {
+	if len(params) != len(metadata) {
+		return skerr.Fmt("params and metadata must be the same length: %d != %d", len(params), len(metadata))
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	unlock, err := s.db.Lock()
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	defer unlock()
+	if err := s.loadMetadata(); err != nil {
+		return skerr.Wrap(err)
+	}
+
+	newMetadata := traceMetadata{Traces: make(map[string]tracestore.TraceMetadata, len(s.metadata.Traces))}
+	for traceName, m := range s.metadata.Traces {
+		newMetadata.Traces[traceName] = m
+	}
+	for i, p := range params {
+		if metadata[i].IsEmpty() {
+			continue
+		}
+		traceName, err := query.MakeKey(p)
+		if err != nil {
+			sklog.Errorf("Somehow still invalid: %v", p)
+			continue
+		}
+		newMetadata.Traces[traceName] = metadata[i]
+	}
+	version, err := s.db.WriteVersion(traceMetadataTable, newMetadata)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	s.metadata = newMetadata
+	s.metadataVersion = version
+	return nil
+}
+
+// DropTile implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) DropTile(ctx context.Context, tileNumber types.TileNumber, dryrun bool) (tracestore.CompactionResult, error) {
+	ret := tracestore.CompactionResult{TileNumber: tileNumber}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	unlock, err := s.db.Lock()
+	if err != nil {
+		return ret, skerr.Wrap(err)
+	}
+	defer unlock()
+
+	tile, err := s.getTile(tileNumber)
+	if err != nil {
+		return ret, skerr.Wrap(err)
+	}
+	if tile == nil {
+		return ret, nil
+	}
+	for traceName, trace := range tile.Traces {
+		for _, v := range trace.Values {
+			if v != vec32.MissingDataSentinel {
+				ret.Values++
+			}
+		}
+		for _, samples := range trace.Samples {
+			if samples != nil {
+				ret.Samples++
+			}
+		}
+		p, err := query.ParseKey(traceName)
+		if err == nil {
+			ret.Postings += int64(len(p))
+		}
+	}
+	for _, values := range tile.ParamSet {
+		ret.ParamSets += int64(len(values))
+	}
+	if dryrun {
+		return ret, nil
+	}
+
+	if err := s.db.Delete(tableNameForTile(tileNumber)); err != nil {
+		return ret, skerr.Wrap(err)
+	}
+	delete(s.tiles, tileNumber)
+	delete(s.tileVersions, tileNumber)
+	return ret, nil
+}
+
+// DownsampleTile implements the tracestore.TraceStore interface.
+func (s *LocalTraceStore) DownsampleTile(ctx context.Context, tileNumber types.TileNumber, windowSize int32, dryrun bool) (tracestore.CompactionResult, error) {
+	ret := tracestore.CompactionResult{TileNumber: tileNumber}
+	if windowSize <= 0 {
+		return ret, skerr.Fmt("Invalid window size: %d", windowSize)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	unlock, err := s.db.Lock()
+	if err != nil {
+		return ret, skerr.Wrap(err)
+	}
+	defer unlock()
+
+	tile, err := s.getTile(tileNumber)
+	if err != nil {
+		return ret, skerr.Wrap(err)
+	}
+	if tile == nil {
+		return ret, nil
+	}
+	tile = tile.copy()
+
+	tileStart, _ := types.TileCommitRangeForTileNumber(tileNumber, s.tileSize)
+	for traceName, trace := range tile.Traces {
+		trace = trace.copy()
+		tile.Traces[traceName] = trace
+		for _, samples := range trace.Samples {
+			if samples != nil {
+				ret.Samples++
+			}
+		}
+		if !dryrun {
+			trace.Samples = nil
+		}
+
+		// Walk the windows, which are aligned on multiples of windowSize so
+		// they are the same no matter which tile they fall in.
+		begin := 0
+		for begin < len(trace.Values) {
+			window := (int32(tileStart) + int32(begin)) / windowSize
+			end := begin
+			for end < len(trace.Values) && (int32(tileStart)+int32(end))/windowSize == window {
+				end++
+			}
+
+			var sum float64
+			count := 0
+			last := -1
+			source := int64(noSource)
+			for i := begin; i < end; i++ {
+				if trace.Values[i] == vec32.MissingDataSentinel {
+					continue
+				}
+				sum += float64(trace.Values[i])
+				count++
+				last = i
+				// Source indexes increase as files are ingested, so this
+				// picks the most recently ingested file in the window.
+				if trace.Sources[i] > source {
+					source = trace.Sources[i]
+				}
+			}
+			if count > 1 {
+				ret.Values += int64(count - 1)
+				if !dryrun {
+					for i := begin; i < end; i++ {
+						trace.Values[i] = vec32.MissingDataSentinel
+						trace.Sources[i] = noSource
+					}
+					trace.Values[last] = float32(sum / float64(count))
+					trace.Sources[last] = source
+				}
+			}
+			begin = end
+		}
+	}
+	if dryrun || ret.Total() == 0 {
+		return ret, nil
+	}
+
+	version, err := s.db.WriteVersion(tableNameForTile(tileNumber), tile)
+	if err != nil {
+		return ret, skerr.Wrap(err)
+	}
+	s.tiles[tileNumber] = tile
+	s.tileVersions[tileNumber] = version
+	return ret, nil
+}
+
+// Confirm that *LocalTraceStore fulfills the tracestore.TraceStore interface.
+var _ tracestore.TraceStore = (*LocalTraceStore)(nil)
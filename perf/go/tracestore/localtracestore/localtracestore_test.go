@@ -0,0 +1,392 @@
+package localtracestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/query"
+	"go.skia.org/infra/go/vec32"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/tracestore"
+	"go.skia.org/infra/perf/go/types"
+)
+
+const (
+	testTileSize = 8
+
+	e = vec32.MissingDataSentinel
+)
+
+// allCommits is a CommitSource where every commit number exists.
+type allCommits struct{}
+
+func (allCommits) CommitSliceFromCommitNumberRange(ctx context.Context, begin, end types.CommitNumber) ([]provider.Commit, error) {
+	ret := []provider.Commit{}
+	for i := begin; i <= end; i++ {
+		ret = append(ret, provider.Commit{CommitNumber: i})
+	}
+	return ret, nil
+}
+
+func newForTest(t *testing.T) (context.Context, *localstore.DB, *LocalTraceStore) {
+	ctx := context.Background()
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	s, err := New(db, allCommits{}, config.DataStoreConfig{TileSize: testTileSize})
+	require.NoError(t, err)
+
+	// Write two traces to commit 1, and one trace to commit 9 in the next tile.
+	params := []paramtools.Params{
+		{"arch": "x86", "config": "8888"},
+		{"arch": "arm", "config": "8888"},
+	}
+	ps := paramtools.NewParamSet(params...)
+	require.NoError(t, s.WriteTraces(ctx, 1, params, []float32{1.5, 2.5}, ps, "gs://bucket/file1.json", time.Now()))
+	require.NoError(t, s.WriteTraces(ctx, 2, params[:1], []float32{3.5}, paramtools.NewParamSet(params[0]), "gs://bucket/file2.json", time.Now()))
+	require.NoError(t, s.WriteTraces(ctx, 9, params[:1], []float32{4.5}, paramtools.NewParamSet(params[0]), "gs://bucket/file3.json", time.Now()))
+	return ctx, db, s
+}
+
+func TestReadTraces_Success(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	ts, commits, err := s.ReadTraces(ctx, 0, []string{",arch=x86,config=8888,", ",arch=riscv,config=8888,"})
+	require.NoError(t, err)
+	assert.Len(t, commits, testTileSize)
+	assert.Equal(t, types.TraceSet{
+		",arch=x86,config=8888,":   {e, 1.5, 3.5, e, e, e, e, e},
+		",arch=riscv,config=8888,": {e, e, e, e, e, e, e, e},
+	}, ts)
+}
+
+func TestReadTracesForCommitRange_SpansTiles_Success(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	ts, commits, err := s.ReadTracesForCommitRange(ctx, []string{",arch=x86,config=8888,"}, 2, 9)
+	require.NoError(t, err)
+	assert.Len(t, commits, 8)
+	assert.Equal(t, types.TraceSet{
+		",arch=x86,config=8888,": {3.5, e, e, e, e, e, e, 4.5},
+	}, ts)
+}
+
+func TestQueryTraces_Success(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	q, err := query.NewFromString("arch=arm")
+	require.NoError(t, err)
+	ts, _, err := s.QueryTraces(ctx, 0, q)
+	require.NoError(t, err)
+	assert.Equal(t, types.TraceSet{
+		",arch=arm,config=8888,": {e, 2.5, e, e, e, e, e, e},
+	}, ts)
+
+	ch, err := s.QueryTracesIDOnly(ctx, 0, q)
+	require.NoError(t, err)
+	found := []paramtools.Params{}
+	for p := range ch {
+		found = append(found, p)
+	}
+	assert.Equal(t, []paramtools.Params{{"arch": "arm", "config": "8888"}}, found)
+}
+
+func TestQueryTracesIDOnly_EmptyQuery_ReturnsError(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	q, err := query.NewFromString("")
+	require.NoError(t, err)
+	_, err = s.QueryTracesIDOnly(ctx, 0, q)
+	require.Error(t, err)
+}
+
+func TestSources_Success(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	source, err := s.GetSource(ctx, 2, ",arch=x86,config=8888,")
+	require.NoError(t, err)
+	assert.Equal(t, "gs://bucket/file2.json", source)
+
+	_, err = s.GetSource(ctx, 2, ",arch=arm,config=8888,")
+	require.Error(t, err)
+
+	sources, err := s.GetLastNSources(ctx, ",arch=x86,config=8888,", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []tracestore.Source{
+		{Filename: "gs://bucket/file3.json", CommitNumber: 9},
+		{Filename: "gs://bucket/file2.json", CommitNumber: 2},
+	}, sources)
+
+	traceIDs, err := s.GetTraceIDsBySource(ctx, "gs://bucket/file1.json", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{",arch=arm,config=8888,", ",arch=x86,config=8888,"}, traceIDs)
+}
+
+func TestGetTraceIDs_Success(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	traceIDs, err := s.GetTraceIDs(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{",arch=arm,config=8888,", ",arch=x86,config=8888,"}, traceIDs)
+
+	traceIDs, err = s.GetTraceIDs(ctx, 5)
+	require.NoError(t, err)
+	assert.Empty(t, traceIDs)
+}
+
+func TestTileInfo_PersistedAcrossReopen(t *testing.T) {
+	ctx, db, _ := newForTest(t)
+
+	// Open a new store on the same database.
+	s, err := New(db, allCommits{}, config.DataStoreConfig{TileSize: testTileSize})
+	require.NoError(t, err)
+
+	tileNumber, err := s.GetLatestTile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, types.TileNumber(1), tileNumber)
+
+	count, err := s.TraceCount(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	ps, err := s.GetParamSet(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, paramtools.NewReadOnlyParamSet(
+		paramtools.Params{"arch": "arm", "config": "8888"},
+		paramtools.Params{"arch": "x86", "config": "8888"},
+	), ps)
+
+	source, err := s.GetSource(ctx, 1, ",arch=arm,config=8888,")
+	require.NoError(t, err)
+	assert.Equal(t, "gs://bucket/file1.json", source)
+}
+
+func TestGetLatestTile_EmptyStore_ReturnsError(t *testing.T) {
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	s, err := New(db, allCommits{}, config.DataStoreConfig{TileSize: testTileSize})
+	require.NoError(t, err)
+
+	_, err = s.GetLatestTile(context.Background())
+	require.Error(t, err)
+}
+
+func TestWriteSamples_ReadSamples_RoundTrip(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	params := []paramtools.Params{
+		{"arch": "x86", "config": "8888"},
+		{"arch": "arm", "config": "8888"},
+	}
+	require.NoError(t, s.WriteSamples(ctx, 1, params, [][]float32{{1.0, 1.5, 2.0}, nil}))
+
+	// No samples were supplied for the second trace, and none were written at
+	// the second commit.
+	samples, err := s.ReadSamples(ctx, []tracestore.TracePoint{
+		{TraceName: ",arch=x86,config=8888,", CommitNumber: 1},
+		{TraceName: ",arch=arm,config=8888,", CommitNumber: 1},
+		{TraceName: ",arch=x86,config=8888,", CommitNumber: 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[tracestore.TracePoint][]float32{
+		{TraceName: ",arch=x86,config=8888,", CommitNumber: 1}: {1.0, 1.5, 2.0},
+	}, samples)
+}
+
+func TestReadSamples_MissingTile_ReturnsEmpty(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	samples, err := s.ReadSamples(ctx, []tracestore.TracePoint{{TraceName: ",arch=x86,config=8888,", CommitNumber: 100}})
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+}
+
+func TestWriteSamples_MismatchedLengths_ReturnsError(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	err := s.WriteSamples(ctx, 1, []paramtools.Params{{"arch": "x86"}}, [][]float32{})
+	require.Error(t, err)
+}
+
+func TestWriteTraceMetadata_ReadTraceMetadata_RoundTripAndSurvivesReopen(t *testing.T) {
+	ctx, db, s := newForTest(t)
+
+	params := []paramtools.Params{
+		{"arch": "x86", "config": "8888"},
+		{"arch": "arm", "config": "8888"},
+	}
+	metadata := []tracestore.TraceMetadata{{Unit: "ms", ImprovementDirection: "down"}, {}}
+	require.NoError(t, s.WriteTraceMetadata(ctx, params, metadata))
+
+	expected := map[string]tracestore.TraceMetadata{
+		",arch=x86,config=8888,": {Unit: "ms", ImprovementDirection: "down"},
+	}
+	got, err := s.ReadTraceMetadata(ctx, []string{",arch=x86,config=8888,", ",arch=arm,config=8888,"})
+	require.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	reopened, err := New(db, allCommits{}, config.DataStoreConfig{TileSize: testTileSize})
+	require.NoError(t, err)
+	got, err = reopened.ReadTraceMetadata(ctx, []string{",arch=x86,config=8888,", ",arch=arm,config=8888,"})
+	require.NoError(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestWriteTraceMetadata_MismatchedLengths_ReturnsError(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	err := s.WriteTraceMetadata(ctx, []paramtools.Params{{"arch": "x86"}}, []tracestore.TraceMetadata{})
+	require.Error(t, err)
+}
+
+func TestDropTile_DryRun_ReportsRowsAndChangesNothing(t *testing.T) {
+	ctx, _, s := newForTest(t)
+	require.NoError(t, s.WriteSamples(ctx, 1, []paramtools.Params{{"arch": "x86", "config": "8888"}}, [][]float32{{1.0, 2.0}}))
+
+	res, err := s.DropTile(ctx, 0, true)
+	require.NoError(t, err)
+	assert.Equal(t, tracestore.CompactionResult{
+		TileNumber: 0,
+		Values:     3,
+		Samples:    1,
+		Postings:   4,
+		ParamSets:  3,
+	}, res)
+
+	ts, _, err := s.ReadTraces(ctx, 0, []string{",arch=x86,config=8888,"})
+	require.NoError(t, err)
+	assert.Equal(t, []float32{e, 1.5, 3.5, e, e, e, e, e}, []float32(ts[",arch=x86,config=8888,"]))
+}
+
+func TestDropTile_RemovesTile(t *testing.T) {
+	ctx, db, s := newForTest(t)
+
+	res, err := s.DropTile(ctx, 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), res.Values)
+
+	ts, _, err := s.ReadTraces(ctx, 0, []string{",arch=x86,config=8888,"})
+	require.NoError(t, err)
+	assert.Equal(t, []float32{e, e, e, e, e, e, e, e}, []float32(ts[",arch=x86,config=8888,"]))
+
+	// The change is persisted.
+	s2, err := New(db, allCommits{}, config.DataStoreConfig{TileSize: testTileSize})
+	require.NoError(t, err)
+	ps, err := s2.GetParamSet(ctx, 0)
+	require.NoError(t, err)
+	assert.Empty(t, ps)
+
+	// Dropping again finds nothing to remove.
+	res, err = s.DropTile(ctx, 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Total())
+}
+
+func TestDownsampleTile_AveragesValuesInEachWindow(t *testing.T) {
+	ctx, db, s := newForTest(t)
+	require.NoError(t, s.WriteSamples(ctx, 1, []paramtools.Params{{"arch": "x86", "config": "8888"}}, [][]float32{{1.0, 2.0}}))
+
+	res, err := s.DownsampleTile(ctx, 0, 4, true)
+	require.NoError(t, err)
+	assert.Equal(t, tracestore.CompactionResult{TileNumber: 0, Values: 1, Samples: 1}, res)
+
+	res, err = s.DownsampleTile(ctx, 0, 4, false)
+	require.NoError(t, err)
+	assert.Equal(t, tracestore.CompactionResult{TileNumber: 0, Values: 1, Samples: 1}, res)
+
+	s2, err := New(db, allCommits{}, config.DataStoreConfig{TileSize: testTileSize})
+	require.NoError(t, err)
+	ts, _, err := s2.ReadTraces(ctx, 0, []string{",arch=x86,config=8888,", ",arch=arm,config=8888,"})
+	require.NoError(t, err)
+	assert.Equal(t, types.TraceSet{
+		",arch=x86,config=8888,": {e, e, 2.5, e, e, e, e, e},
+		",arch=arm,config=8888,": {e, 2.5, e, e, e, e, e, e},
+	}, ts)
+
+	source, err := s2.GetSource(ctx, 2, ",arch=x86,config=8888,")
+	require.NoError(t, err)
+	assert.Equal(t, "gs://bucket/file2.json", source)
+
+	samples, err := s2.ReadSamples(ctx, []tracestore.TracePoint{{TraceName: ",arch=x86,config=8888,", CommitNumber: 1}})
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+
+	// A second pass has nothing left to do.
+	res, err = s2.DownsampleTile(ctx, 0, 4, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Total())
+}
+
+func TestDownsampleTile_InvalidWindowSize_ReturnsError(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	_, err := s.DownsampleTile(ctx, 0, 0, true)
+	require.Error(t, err)
+}
+
+func TestWriteTraces_SecondStoreOnSameDirectory_SeesWritesAndDrops(t *testing.T) {
+	ctx, db, s := newForTest(t)
+
+	// Open the directory again, as another process would.
+	otherDB, err := localstore.New(db.Dir())
+	require.NoError(t, err)
+	other, err := New(otherDB, allCommits{}, config.DataStoreConfig{TileSize: testTileSize})
+	require.NoError(t, err)
+
+	// Load tile 0 into the cache of the other store.
+	count, err := other.TraceCount(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	p := paramtools.Params{"arch": "riscv", "config": "8888"}
+	require.NoError(t, s.WriteTraces(ctx, 3, []paramtools.Params{p}, []float32{5.5}, paramtools.NewParamSet(p), "gs://bucket/file4.json", time.Now()))
+	require.NoError(t, s.WriteTraceMetadata(ctx, []paramtools.Params{p}, []tracestore.TraceMetadata{{Unit: "ms"}}))
+
+	ts, _, err := other.ReadTraces(ctx, 0, []string{",arch=riscv,config=8888,"})
+	require.NoError(t, err)
+	assert.Equal(t, []float32{e, e, e, 5.5, e, e, e, e}, []float32(ts[",arch=riscv,config=8888,"]))
+	source, err := other.GetSource(ctx, 3, ",arch=riscv,config=8888,")
+	require.NoError(t, err)
+	assert.Equal(t, "gs://bucket/file4.json", source)
+	metadata, err := other.ReadTraceMetadata(ctx, []string{",arch=riscv,config=8888,"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]tracestore.TraceMetadata{",arch=riscv,config=8888,": {Unit: "ms"}}, metadata)
+
+	// Drop the tile from the first store, the other store must not bring it
+	// back from its cache when it writes to the tile.
+	_, err = s.DropTile(ctx, 0, false)
+	require.NoError(t, err)
+	require.NoError(t, other.WriteTraces(ctx, 4, []paramtools.Params{p}, []float32{6.5}, paramtools.NewParamSet(p), "gs://bucket/file5.json", time.Now()))
+
+	count, err = s.TraceCount(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	ts, _, err = s.ReadTraces(ctx, 0, []string{",arch=riscv,config=8888,", ",arch=x86,config=8888,"})
+	require.NoError(t, err)
+	assert.Equal(t, types.TraceSet{
+		",arch=riscv,config=8888,": {e, e, e, e, 6.5, e, e, e},
+		",arch=x86,config=8888,":   {e, e, e, e, e, e, e, e},
+	}, ts)
+}
+
+func TestWriteTraces_TilesReturnedEarlierAreNotModified(t *testing.T) {
+	ctx, _, s := newForTest(t)
+
+	before, err := s.readTile(0)
+	require.NoError(t, err)
+	p := paramtools.Params{"arch": "x86", "config": "8888"}
+	require.NoError(t, s.WriteTraces(ctx, 3, []paramtools.Params{p}, []float32{5.5}, paramtools.NewParamSet(p), "gs://bucket/file4.json", time.Now()))
+	require.NoError(t, s.WriteSamples(ctx, 3, []paramtools.Params{p}, [][]float32{{5.0, 6.0}}))
+
+	assert.Equal(t, []float32{e, 1.5, 3.5, e, e, e, e, e}, before.Traces[",arch=x86,config=8888,"].Values)
+	assert.Nil(t, before.Traces[",arch=x86,config=8888,"].Samples)
+
+	after, err := s.readTile(0)
+	require.NoError(t, err)
+	assert.Equal(t, []float32{e, 1.5, 3.5, 5.5, e, e, e, e}, after.Traces[",arch=x86,config=8888,"].Values)
+}
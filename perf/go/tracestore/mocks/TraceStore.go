@@ -72,6 +72,62 @@ func (_m *TraceStore) GetLastNSources(ctx context.Context, traceID string, n int
 	return r0, r1
 }
 
+// DownsampleTile provides a mock function with given fields: ctx, tileNumber, windowSize, dryrun
+func (_m *TraceStore) DownsampleTile(ctx context.Context, tileNumber types.TileNumber, windowSize int32, dryrun bool) (tracestore.CompactionResult, error) {
+	ret := _m.Called(ctx, tileNumber, windowSize, dryrun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DownsampleTile")
+	}
+
+	var r0 tracestore.CompactionResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, types.TileNumber, int32, bool) (tracestore.CompactionResult, error)); ok {
+		return rf(ctx, tileNumber, windowSize, dryrun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, types.TileNumber, int32, bool) tracestore.CompactionResult); ok {
+		r0 = rf(ctx, tileNumber, windowSize, dryrun)
+	} else {
+		r0 = ret.Get(0).(tracestore.CompactionResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, types.TileNumber, int32, bool) error); ok {
+		r1 = rf(ctx, tileNumber, windowSize, dryrun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DropTile provides a mock function with given fields: ctx, tileNumber, dryrun
+func (_m *TraceStore) DropTile(ctx context.Context, tileNumber types.TileNumber, dryrun bool) (tracestore.CompactionResult, error) {
+	ret := _m.Called(ctx, tileNumber, dryrun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DropTile")
+	}
+
+	var r0 tracestore.CompactionResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, types.TileNumber, bool) (tracestore.CompactionResult, error)); ok {
+		return rf(ctx, tileNumber, dryrun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, types.TileNumber, bool) tracestore.CompactionResult); ok {
+		r0 = rf(ctx, tileNumber, dryrun)
+	} else {
+		r0 = ret.Get(0).(tracestore.CompactionResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, types.TileNumber, bool) error); ok {
+		r1 = rf(ctx, tileNumber, dryrun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLatestTile provides a mock function with given fields: _a0
 func (_m *TraceStore) GetLatestTile(_a0 context.Context) (types.TileNumber, error) {
 	ret := _m.Called(_a0)
@@ -158,6 +214,36 @@ func (_m *TraceStore) GetSource(ctx context.Context, commitNumber types.CommitNu
 	return r0, r1
 }
 
+// GetTraceIDs provides a mock function with given fields: ctx, tileNumber
+func (_m *TraceStore) GetTraceIDs(ctx context.Context, tileNumber types.TileNumber) ([]string, error) {
+	ret := _m.Called(ctx, tileNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTraceIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, types.TileNumber) ([]string, error)); ok {
+		return rf(ctx, tileNumber)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, types.TileNumber) []string); ok {
+		r0 = rf(ctx, tileNumber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, types.TileNumber) error); ok {
+		r1 = rf(ctx, tileNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTraceIDsBySource provides a mock function with given fields: ctx, sourceFilename, tileNumber
 func (_m *TraceStore) GetTraceIDsBySource(ctx context.Context, sourceFilename string, tileNumber types.TileNumber) ([]string, error) {
 	ret := _m.Called(ctx, sourceFilename, tileNumber)
@@ -275,6 +361,36 @@ func (_m *TraceStore) QueryTracesIDOnly(ctx context.Context, tileNumber types.Ti
 	return r0, r1
 }
 
+// ReadTraceMetadata provides a mock function with given fields: ctx, traceNames
+func (_m *TraceStore) ReadTraceMetadata(ctx context.Context, traceNames []string) (map[string]tracestore.TraceMetadata, error) {
+	ret := _m.Called(ctx, traceNames)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadTraceMetadata")
+	}
+
+	var r0 map[string]tracestore.TraceMetadata
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) (map[string]tracestore.TraceMetadata, error)); ok {
+		return rf(ctx, traceNames)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) map[string]tracestore.TraceMetadata); ok {
+		r0 = rf(ctx, traceNames)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]tracestore.TraceMetadata)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, traceNames)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReadTraces provides a mock function with given fields: ctx, tileNumber, keys
 func (_m *TraceStore) ReadTraces(ctx context.Context, tileNumber types.TileNumber, keys []string) (types.TraceSet, []provider.Commit, error) {
 	ret := _m.Called(ctx, tileNumber, keys)
@@ -353,6 +469,36 @@ func (_m *TraceStore) ReadTracesForCommitRange(ctx context.Context, keys []strin
 	return r0, r1, r2
 }
 
+// ReadSamples provides a mock function with given fields: ctx, points
+func (_m *TraceStore) ReadSamples(ctx context.Context, points []tracestore.TracePoint) (map[tracestore.TracePoint][]float32, error) {
+	ret := _m.Called(ctx, points)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadSamples")
+	}
+
+	var r0 map[tracestore.TracePoint][]float32
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []tracestore.TracePoint) (map[tracestore.TracePoint][]float32, error)); ok {
+		return rf(ctx, points)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []tracestore.TracePoint) map[tracestore.TracePoint][]float32); ok {
+		r0 = rf(ctx, points)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[tracestore.TracePoint][]float32)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []tracestore.TracePoint) error); ok {
+		r1 = rf(ctx, points)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StartBackgroundMetricsGathering provides a mock function with given fields:
 func (_m *TraceStore) StartBackgroundMetricsGathering() {
 	_m.Called()
@@ -422,6 +568,42 @@ func (_m *TraceStore) TraceCount(ctx context.Context, tileNumber types.TileNumbe
 	return r0, r1
 }
 
+// WriteSamples provides a mock function with given fields: ctx, commitNumber, params, samples
+func (_m *TraceStore) WriteSamples(ctx context.Context, commitNumber types.CommitNumber, params []paramtools.Params, samples [][]float32) error {
+	ret := _m.Called(ctx, commitNumber, params, samples)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteSamples")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, types.CommitNumber, []paramtools.Params, [][]float32) error); ok {
+		r0 = rf(ctx, commitNumber, params, samples)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WriteTraceMetadata provides a mock function with given fields: ctx, params, metadata
+func (_m *TraceStore) WriteTraceMetadata(ctx context.Context, params []paramtools.Params, metadata []tracestore.TraceMetadata) error {
+	ret := _m.Called(ctx, params, metadata)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteTraceMetadata")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []paramtools.Params, []tracestore.TraceMetadata) error); ok {
+		r0 = rf(ctx, params, metadata)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // WriteTraces provides a mock function with given fields: ctx, commitNumber, params, values, paramset, source, timestamp
 func (_m *TraceStore) WriteTraces(ctx context.Context, commitNumber types.CommitNumber, params []paramtools.Params, values []float32, paramset paramtools.ParamSet, source string, timestamp time.Time) error {
 	ret := _m.Called(ctx, commitNumber, params, values, paramset, source, timestamp)
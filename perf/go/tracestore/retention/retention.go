@@ -0,0 +1,143 @@
+// Package retention applies an instance's config.RetentionConfig to the trace
+// store, dropping or downsampling the data in tiles that are older than the
+// retention period.
+package retention
+
+import (
+	"context"
+	"time"
+
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/tracestore"
+	"go.skia.org/infra/perf/go/types"
+)
+
+// compactTimeout is the longest a single periodic compaction may run.
+const compactTimeout = 6 * time.Hour
+
+// Compactor applies a RetentionConfig to a TraceStore.
+type Compactor struct {
+	store tracestore.TraceStore
+	cfg   config.RetentionConfig
+
+	// nextTile is the oldest tile that hasn't been compacted yet by this
+	// Compactor, so that periodic runs don't re-scan tiles that have already
+	// been compacted.
+	nextTile types.TileNumber
+
+	rowsRemoved    metrics2.Counter
+	tilesCompacted metrics2.Counter
+	failures       metrics2.Counter
+}
+
+// New returns a new *Compactor. An error is returned if cfg has no retention
+// policy.
+func New(store tracestore.TraceStore, cfg config.RetentionConfig) (*Compactor, error) {
+	switch cfg.Policy {
+	case config.DropRetentionPolicy:
+	case config.DownsampleRetentionPolicy:
+		if cfg.DownsampleWindow < 2 {
+			return nil, skerr.Fmt("Invalid downsample window: %d", cfg.DownsampleWindow)
+		}
+	default:
+		return nil, skerr.Fmt("Invalid retention policy: %q", cfg.Policy)
+	}
+	if cfg.KeepTiles < 1 {
+		return nil, skerr.Fmt("At least one tile must be kept, got keep_tiles=%d", cfg.KeepTiles)
+	}
+	tags := map[string]string{"policy": string(cfg.Policy)}
+	return &Compactor{
+		store:          store,
+		cfg:            cfg,
+		rowsRemoved:    metrics2.GetCounter("perf_retention_rows_removed", tags),
+		tilesCompacted: metrics2.GetCounter("perf_retention_tiles_compacted", tags),
+		failures:       metrics2.GetCounter("perf_retention_failures", tags),
+	}, nil
+}
+
+// TilesToCompact returns the tiles, oldest first, that fall outside the
+// retention period.
+func (c *Compactor) TilesToCompact(ctx context.Context) ([]types.TileNumber, error) {
+	latest, err := c.store.GetLatestTile(ctx)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to find the latest tile")
+	}
+	ret := []types.TileNumber{}
+	for tileNumber := types.TileNumber(0); tileNumber <= latest-types.TileNumber(c.cfg.KeepTiles); tileNumber++ {
+		ret = append(ret, tileNumber)
+	}
+	return ret, nil
+}
+
+// Compact applies the retention policy to every tile that falls outside the
+// retention period and returns what was removed from each. If dryrun is true
+// then nothing is changed and the results report what would be removed.
+func (c *Compactor) Compact(ctx context.Context, dryrun bool) ([]tracestore.CompactionResult, error) {
+	tiles, err := c.TilesToCompact(ctx)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return c.compactTiles(ctx, tiles, dryrun)
+}
+
+func (c *Compactor) compactTiles(ctx context.Context, tiles []types.TileNumber, dryrun bool) ([]tracestore.CompactionResult, error) {
+	ret := make([]tracestore.CompactionResult, 0, len(tiles))
+	for _, tileNumber := range tiles {
+		var res tracestore.CompactionResult
+		var err error
+		if c.cfg.Policy == config.DropRetentionPolicy {
+			res, err = c.store.DropTile(ctx, tileNumber, dryrun)
+		} else {
+			res, err = c.store.DownsampleTile(ctx, tileNumber, c.cfg.DownsampleWindow, dryrun)
+		}
+		if err != nil {
+			return ret, skerr.Wrapf(err, "Failed to compact tile %d", tileNumber)
+		}
+		ret = append(ret, res)
+		if !dryrun {
+			c.rowsRemoved.Inc(res.Total())
+			c.tilesCompacted.Inc(1)
+		}
+	}
+	return ret, nil
+}
+
+// compactNewTiles compacts the tiles that have fallen outside the retention
+// period since the last call.
+func (c *Compactor) compactNewTiles(ctx context.Context) error {
+	tiles, err := c.TilesToCompact(ctx)
+	if err != nil {
+		return skerr.Wrap(err)
+	}
+	newTiles := []types.TileNumber{}
+	for _, tileNumber := range tiles {
+		if tileNumber >= c.nextTile {
+			newTiles = append(newTiles, tileNumber)
+		}
+	}
+	results, err := c.compactTiles(ctx, newTiles, false)
+	if len(results) > 0 {
+		c.nextTile = results[len(results)-1].TileNumber + 1
+	}
+	for _, res := range results {
+		sklog.Infof("Compacted tile %d, removed %d rows.", res.TileNumber, res.Total())
+	}
+	return skerr.Wrap(err)
+}
+
+// Start compacts the tiles that fall outside the retention period, and then
+// does so again every period, until the context is cancelled.
+func (c *Compactor) Start(ctx context.Context, period time.Duration) {
+	util.RepeatCtx(ctx, period, func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, compactTimeout)
+		defer cancel()
+		if err := c.compactNewTiles(ctx); err != nil {
+			sklog.Errorf("Failed to apply retention policy: %s", err)
+			c.failures.Inc(1)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package retention
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/config"
+	"go.skia.org/infra/perf/go/tracestore"
+	"go.skia.org/infra/perf/go/tracestore/mocks"
+	"go.skia.org/infra/perf/go/types"
+)
+
+func TestNew_InvalidConfigs_ReturnError(t *testing.T) {
+	for name, cfg := range map[string]config.RetentionConfig{
+		"no policy":          {KeepTiles: 2},
+		"no tiles kept":      {Policy: config.DropRetentionPolicy},
+		"no window":          {Policy: config.DownsampleRetentionPolicy, KeepTiles: 2},
+		"unknown policy":     {Policy: "shred", KeepTiles: 2},
+		"negative keep tile": {Policy: config.DropRetentionPolicy, KeepTiles: -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(mocks.NewTraceStore(t), cfg)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestTilesToCompact_FewerTilesThanKept_ReturnsEmpty(t *testing.T) {
+	ctx := context.Background()
+	store := mocks.NewTraceStore(t)
+	store.On("GetLatestTile", ctx).Return(types.TileNumber(1), nil)
+	c, err := New(store, config.RetentionConfig{Policy: config.DropRetentionPolicy, KeepTiles: 2})
+	require.NoError(t, err)
+
+	tiles, err := c.TilesToCompact(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, tiles)
+}
+
+func TestCompact_DropPolicy_DropsOldTiles(t *testing.T) {
+	ctx := context.Background()
+	store := mocks.NewTraceStore(t)
+	store.On("GetLatestTile", ctx).Return(types.TileNumber(3), nil)
+	store.On("DropTile", ctx, types.TileNumber(0), true).Return(tracestore.CompactionResult{TileNumber: 0, Values: 10}, nil)
+	store.On("DropTile", ctx, types.TileNumber(1), true).Return(tracestore.CompactionResult{TileNumber: 1, Values: 5}, nil)
+	c, err := New(store, config.RetentionConfig{Policy: config.DropRetentionPolicy, KeepTiles: 2})
+	require.NoError(t, err)
+
+	results, err := c.Compact(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, []tracestore.CompactionResult{
+		{TileNumber: 0, Values: 10},
+		{TileNumber: 1, Values: 5},
+	}, results)
+}
+
+func TestCompact_DownsamplePolicy_DownsamplesOldTiles(t *testing.T) {
+	ctx := context.Background()
+	store := mocks.NewTraceStore(t)
+	store.On("GetLatestTile", ctx).Return(types.TileNumber(1), nil)
+	store.On("DownsampleTile", ctx, types.TileNumber(0), int32(4), false).Return(tracestore.CompactionResult{TileNumber: 0, Values: 3}, nil)
+	c, err := New(store, config.RetentionConfig{Policy: config.DownsampleRetentionPolicy, KeepTiles: 1, DownsampleWindow: 4})
+	require.NoError(t, err)
+
+	results, err := c.Compact(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []tracestore.CompactionResult{{TileNumber: 0, Values: 3}}, results)
+}
+
+func TestCompactNewTiles_SecondCall_OnlyCompactsNewTiles(t *testing.T) {
+	ctx := context.Background()
+	store := mocks.NewTraceStore(t)
+	store.On("GetLatestTile", ctx).Return(types.TileNumber(2), nil).Once()
+	store.On("DropTile", ctx, types.TileNumber(0), false).Return(tracestore.CompactionResult{TileNumber: 0}, nil).Once()
+	store.On("DropTile", ctx, types.TileNumber(1), false).Return(tracestore.CompactionResult{TileNumber: 1}, nil).Once()
+	c, err := New(store, config.RetentionConfig{Policy: config.DropRetentionPolicy, KeepTiles: 1})
+	require.NoError(t, err)
+	require.NoError(t, c.compactNewTiles(ctx))
+
+	store.On("GetLatestTile", ctx).Return(types.TileNumber(3), nil).Once()
+	store.On("DropTile", ctx, types.TileNumber(2), false).Return(tracestore.CompactionResult{TileNumber: 2}, nil).Once()
+	require.NoError(t, c.compactNewTiles(ctx))
+	store.AssertNumberOfCalls(t, "DropTile", 3)
+}
+
+func TestCompact_StoreFails_ReturnsPartialResultsAndError(t *testing.T) {
+	ctx := context.Background()
+	store := mocks.NewTraceStore(t)
+	store.On("GetLatestTile", ctx).Return(types.TileNumber(2), nil)
+	store.On("DropTile", ctx, types.TileNumber(0), false).Return(tracestore.CompactionResult{TileNumber: 0, Values: 1}, nil)
+	store.On("DropTile", ctx, types.TileNumber(1), false).Return(tracestore.CompactionResult{}, assert.AnError)
+	c, err := New(store, config.RetentionConfig{Policy: config.DropRetentionPolicy, KeepTiles: 1})
+	require.NoError(t, err)
+
+	results, err := c.Compact(ctx, false)
+	require.Error(t, err)
+	assert.Len(t, results, 1)
+	store.AssertNotCalled(t, "DropTile", mock.Anything, types.TileNumber(2), mock.Anything)
+}
@@ -34,3 +34,21 @@ type PostingsSchema struct {
 	byTraceIDIndex  struct{}         `sql:"INDEX by_trace_id (tile_number, trace_id, key_value)"`
 	byKeyValueIndex struct{}         `sql:"INDEX by_key_value (tile_number, key_value)"`
 }
+
+// TraceSamplesSchema describes the SQL schema of the TraceSamples table, which
+// holds the raw samples that each value in TraceValues was computed from, if
+// they were supplied at ingestion time.
+type TraceSamplesSchema struct {
+	TraceID      []byte    `sql:"trace_id BYTES"`
+	CommitNumber int64     `sql:"commit_number INT"`
+	Samples      []float32 `sql:"samples REAL[]"`
+	primaryKey   struct{}  `sql:"PRIMARY KEY (trace_id, commit_number)"`
+}
+
+// TraceMetadataSchema describes the SQL schema of the TraceMetadata table,
+// which holds the metadata of each trace that isn't part of its trace id.
+type TraceMetadataSchema struct {
+	TraceID              []byte `sql:"trace_id BYTES PRIMARY KEY"`
+	Unit                 string `sql:"unit TEXT"`
+	ImprovementDirection string `sql:"improvement_direction TEXT"`
+}
@@ -145,6 +145,7 @@ import (
 	"context"
 	"crypto/md5"
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"sync"
@@ -152,6 +153,7 @@ import (
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"go.opencensus.io/trace"
 	"go.skia.org/infra/go/metrics2"
@@ -161,6 +163,7 @@ import (
 	"go.skia.org/infra/go/skerr"
 	"go.skia.org/infra/go/sklog"
 	"go.skia.org/infra/go/sql/pool"
+	"go.skia.org/infra/go/sql/sqlutil"
 	"go.skia.org/infra/go/timer"
 	"go.skia.org/infra/go/util"
 	"go.skia.org/infra/go/vec32"
@@ -188,6 +191,29 @@ const (
 	writeTracesValuesChunkSize    = 1000
 	writeTracesPostingsChunkSize  = 1000
 	writeTracesParamSetsChunkSize = 100
+	writeSamplesChunkSize         = 100
+	writeTraceMetadataChunkSize   = 100
+
+	// upsertTraceMetadataColsPerRow is the number of columns in the
+	// upsertTraceMetadata statement.
+	upsertTraceMetadataColsPerRow = 3
+
+	// readSamplesChunkSize is the number of points read by a single
+	// readSamples statement.
+	readSamplesChunkSize = 1000
+
+	// readSamplesColsPerRow is the number of values per point in the
+	// readSamples statement.
+	readSamplesColsPerRow = 2
+
+	// compactTileChunkSize is the number of trace ids in each statement when
+	// compacting a tile.
+	compactTileChunkSize = 1000
+
+	// deleteBatchSize limits the number of rows removed by a single DELETE
+	// from the Postings and ParamSets tables, so that each transaction stays
+	// small.
+	deleteBatchSize = 10000
 
 	// See writeTracesChunkSize.
 	readTracesChunkSize = 100
@@ -275,12 +301,29 @@ const (
 	convertTraceIDs
 	readTraces
 	getLastNSources
+	getTraceIDs
 	getTraceIDsBySource
 	countMatchingTraces
 	restrictClause
 	deleteCommit
 	countCommitInCommitNumberRange
 	getCommitsFromCommitNumberRange
+	insertIntoTraceSamples
+	readSamples
+	upsertTraceMetadata
+	readTraceMetadata
+	traceIDsForTile
+	countPostingsForTile
+	deletePostingsForTile
+	countParamSetsForTile
+	deleteParamSetsForTile
+	countTraceValuesForTile
+	deleteTraceValuesForTile
+	countTraceSamplesForTile
+	deleteTraceSamplesForTile
+	countDownsampledTraceValues
+	downsampleTraceValues
+	deleteDownsampledTraceValues
 )
 
 var templates = map[statement]string{
@@ -294,6 +337,16 @@ var templates = map[statement]string{
             )
         {{ end }}
         `,
+	insertIntoTraceSamples: `UPSERT INTO
+            TraceSamples (trace_id, commit_number, samples)
+        VALUES
+        {{ range $index, $element :=  . -}}
+            {{ if $index }},{{end}}
+            (
+                '{{ $element.MD5HexTraceID }}', {{ $element.CommitNumber }}, ARRAY[{{ range $i, $sample := $element.Samples }}{{ if $i }},{{ end }}{{ $sample }}{{ end }}]::REAL[]
+            )
+        {{ end }}
+        `,
 	convertTraceIDs: `
         {{ $tileNumber := .TileNumber }}
         SELECT
@@ -421,6 +474,71 @@ var templates = map[statement]string{
                )
             LIMIT {{ .CountOptimizationThreshold }}
         )`,
+	countTraceValuesForTile: `
+        SELECT
+            count(*)
+        FROM
+            TraceValues
+        WHERE
+            {{ template "compactTileWhere" . }}`,
+	deleteTraceValuesForTile: `
+        DELETE FROM
+            TraceValues
+        WHERE
+            {{ template "compactTileWhere" . }}`,
+	countTraceSamplesForTile: `
+        SELECT
+            count(*)
+        FROM
+            TraceSamples
+        WHERE
+            {{ template "compactTileWhere" . }}`,
+	deleteTraceSamplesForTile: `
+        DELETE FROM
+            TraceSamples
+        WHERE
+            {{ template "compactTileWhere" . }}`,
+	countDownsampledTraceValues: `
+        SELECT
+            COALESCE(sum(n - 1), 0)
+        FROM (
+            SELECT
+                count(*) AS n
+            FROM
+                TraceValues
+            WHERE
+                {{ template "compactTileWhere" . }}
+            GROUP BY
+                trace_id, commit_number // {{ .WindowSize }}
+        )`,
+	downsampleTraceValues: `
+        UPSERT INTO
+            TraceValues (trace_id, commit_number, val, source_file_id)
+        SELECT
+            trace_id, max(commit_number), avg(val)::REAL, max(source_file_id)
+        FROM
+            TraceValues
+        WHERE
+            {{ template "compactTileWhere" . }}
+        GROUP BY
+            trace_id, commit_number // {{ .WindowSize }}
+        HAVING
+            count(*) > 1`,
+	deleteDownsampledTraceValues: `
+        DELETE FROM
+            TraceValues
+        WHERE
+            {{ template "compactTileWhere" . }}
+            AND (trace_id, commit_number) NOT IN (
+                SELECT
+                    trace_id, max(commit_number)
+                FROM
+                    TraceValues
+                WHERE
+                    {{ template "compactTileWhere" . }}
+                GROUP BY
+                    trace_id, commit_number // {{ .WindowSize }}
+            )`,
 	restrictClause: `
     AND trace_ID IN
     ({{ range $index, $value := .Values -}}
@@ -429,6 +547,30 @@ var templates = map[statement]string{
     {{ end }})`,
 }
 
+// compactTileWhere is the WHERE clause shared by the templates used to compact
+// a tile, and is executed with a compactTileContext.
+const compactTileWhere = `{{ define "compactTileWhere" }}
+            commit_number >= {{ .BeginCommitNumber }}
+            AND commit_number <= {{ .EndCommitNumber }}
+            AND trace_id IN
+            (
+                {{ range $index, $trace_id :=  .TraceIDs -}}
+                    {{ if $index }},{{end}}
+                    '{{ $trace_id }}'
+                {{ end }}
+            )
+{{- end }}`
+
+// compactTileContext is the context for the templates used to compact a tile.
+type compactTileContext struct {
+	BeginCommitNumber types.CommitNumber
+	EndCommitNumber   types.CommitNumber
+	TraceIDs          []traceIDForSQL
+
+	// WindowSize is the number of commits in each window when downsampling.
+	WindowSize int32
+}
+
 // replaceTraceValuesContext is the context for the replaceTraceValues template.
 type insertIntoTraceValuesContext struct {
 	// The MD5 sum of the trace name as a hex string, i.e.
@@ -441,6 +583,18 @@ type insertIntoTraceValuesContext struct {
 	SourceFileID sourceFileIDFromSQL
 }
 
+// insertIntoTraceSamplesContext is the context for the insertIntoTraceSamples
+// template.
+type insertIntoTraceSamplesContext struct {
+	// The MD5 sum of the trace name as a hex string, i.e.
+	// "\xfe385b159ff55dca481069805e5ff050". Note the leading \x which
+	// CockroachDB will use to know the string is in hex.
+	MD5HexTraceID traceIDForSQL
+
+	CommitNumber types.CommitNumber
+	Samples      []float32
+}
+
 // replaceTraceNamesContext is the context for the replaceTraceNames template.
 type replaceTraceNamesContext struct {
 	// The trace's Params serialize as JSON.
@@ -583,6 +737,25 @@ var statements = map[statement]string{
             Postings
         WHERE
           tile_number = $1`,
+	readSamples: `
+        SELECT
+            trace_id, commit_number, samples
+        FROM
+            TraceSamples
+        WHERE
+            (trace_id, commit_number) IN `,
+	upsertTraceMetadata: `
+        UPSERT INTO
+            TraceMetadata (trace_id, unit, improvement_direction)
+        VALUES
+        `,
+	readTraceMetadata: `
+        SELECT
+            trace_id, unit, improvement_direction
+        FROM
+            TraceMetadata
+        WHERE
+            trace_id = ANY($1)`,
 	getLastNSources: `
         SELECT
             SourceFiles.source_file, TraceValues.commit_number
@@ -598,6 +771,15 @@ var statements = map[statement]string{
             TraceValues.commit_number DESC
         LIMIT
             $2`,
+	getTraceIDs: `
+        SELECT
+            key_value, trace_id
+        FROM
+            Postings@by_trace_id
+        WHERE
+            tile_number = $1
+        ORDER BY
+            trace_id`,
 	getTraceIDsBySource: `
         SELECT
             Postings.key_value, Postings.trace_id
@@ -642,6 +824,41 @@ var statements = map[statement]string{
 		WHERE
 			commit_number = $1
 		`,
+	traceIDsForTile: `
+        SELECT DISTINCT
+            trace_id
+        FROM
+            Postings@by_trace_id
+        WHERE
+            tile_number = $1`,
+	countPostingsForTile: `
+        SELECT
+            count(*)
+        FROM
+            Postings
+        WHERE
+            tile_number = $1`,
+	deletePostingsForTile: `
+        DELETE FROM
+            Postings
+        WHERE
+            tile_number = $1
+        LIMIT
+            $2`,
+	countParamSetsForTile: `
+        SELECT
+            count(*)
+        FROM
+            ParamSets
+        WHERE
+            tile_number = $1`,
+	deleteParamSetsForTile: `
+        DELETE FROM
+            ParamSets
+        WHERE
+            tile_number = $1
+        LIMIT
+            $2`,
 }
 
 type timeProvider func() time.Time
@@ -698,7 +915,11 @@ type SQLTraceStore struct {
 func New(db pool.Pool, datastoreConfig config.DataStoreConfig) (*SQLTraceStore, error) {
 	unpreparedStatements := map[statement]*template.Template{}
 	for key, tmpl := range templates {
-		t, err := template.New("").Parse(tmpl)
+		t, err := template.New("").Parse(compactTileWhere)
+		if err != nil {
+			return nil, skerr.Wrapf(err, "parsing template %q", compactTileWhere)
+		}
+		t, err = t.Parse(tmpl)
 		if err != nil {
 			return nil, skerr.Wrapf(err, "parsing template %v, %q", key, tmpl)
 		}
@@ -918,6 +1139,23 @@ func (s *SQLTraceStore) GetLastNSources(ctx context.Context, traceID string, n i
 	return ret, nil
 }
 
+// GetTraceIDs implements the tracestore.TraceStore interface.
+func (s *SQLTraceStore) GetTraceIDs(ctx context.Context, tileNumber types.TileNumber) ([]string, error) {
+	ctx, span := trace.StartSpan(ctx, "sqltracestore.GetTraceIDs")
+	defer span.End()
+
+	rows, err := s.db.Query(ctx, statements[getTraceIDs], tileNumber)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed for tileNumber=%d", tileNumber)
+	}
+	ret, err := traceNamesFromPostings(rows)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed scanning for tileNumber=%d", tileNumber)
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
 // GetTraceIDsBySource implements the tracestore.TraceStore interface.
 func (s *SQLTraceStore) GetTraceIDsBySource(ctx context.Context, sourceFilename string, tileNumber types.TileNumber) ([]string, error) {
 	ctx, span := trace.StartSpan(ctx, "sqltracestore.GetTraceIDsBySource")
@@ -927,9 +1165,18 @@ func (s *SQLTraceStore) GetTraceIDsBySource(ctx context.Context, sourceFilename
 	if err != nil {
 		return nil, skerr.Wrapf(err, "Failed for sourceFilename=%q and tileNumber=%d", sourceFilename, tileNumber)
 	}
+	ret, err := traceNamesFromPostings(rows)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed scanning for sourceFilename=%q and tileNumber=%d", sourceFilename, tileNumber)
+	}
+	return ret, nil
+}
 
-	// We queried the Postings table, build up each traceid from all the
-	// key=value pairs returned.
+// traceNamesFromPostings builds up the trace names from rows of key=value
+// pairs and trace ids read from the Postings table, which must be ordered by
+// trace id.
+func traceNamesFromPostings(rows pgx.Rows) ([]string, error) {
+	defer rows.Close()
 	var currentTraceIDAsBytes []byte
 	p := paramtools.Params{}
 	ret := []string{}
@@ -937,7 +1184,7 @@ func (s *SQLTraceStore) GetTraceIDsBySource(ctx context.Context, sourceFilename
 		var keyValue string
 		var traceIDAsBytes []byte
 		if err := rows.Scan(&keyValue, &traceIDAsBytes); err != nil {
-			return nil, skerr.Wrapf(err, "Failed scanning for sourceFilename=%q and tileNumber=%d", sourceFilename, tileNumber)
+			return nil, skerr.Wrap(err)
 		}
 		// If we hit a new trace_id then we have a complete traceID.
 		if !bytes.Equal(currentTraceIDAsBytes, traceIDAsBytes) {
@@ -1877,6 +2124,368 @@ func (s *SQLTraceStore) WriteTraces(ctx context.Context, commitNumber types.Comm
 	return nil
 }
 
+// WriteSamples implements the tracestore.TraceStore interface.
+func (s *SQLTraceStore) WriteSamples(ctx context.Context, commitNumber types.CommitNumber, params []paramtools.Params, samples [][]float32) error {
+	ctx, span := trace.StartSpan(ctx, "sqltracestore.WriteSamples")
+	defer span.End()
+
+	if len(params) != len(samples) {
+		return skerr.Fmt("params and samples must be the same length: %d != %d", len(params), len(samples))
+	}
+
+	samplesTemplateContext := []insertIntoTraceSamplesContext{}
+	for i, p := range params {
+		if len(samples[i]) == 0 {
+			continue
+		}
+		traceName, err := query.MakeKey(p)
+		if err != nil {
+			sklog.Errorf("Somehow still invalid: %v", p)
+			continue
+		}
+		// Non-finite values can't be represented in the SQL statement.
+		finite := make([]float32, 0, len(samples[i]))
+		for _, x := range samples[i] {
+			if !math.IsNaN(float64(x)) && !math.IsInf(float64(x), 0) {
+				finite = append(finite, x)
+			}
+		}
+		samplesTemplateContext = append(samplesTemplateContext, insertIntoTraceSamplesContext{
+			MD5HexTraceID: traceIDForSQLFromTraceName(traceName),
+			CommitNumber:  commitNumber,
+			Samples:       finite,
+		})
+	}
+
+	return util.ChunkIter(len(samplesTemplateContext), writeSamplesChunkSize, func(startIdx int, endIdx int) error {
+		ctx, span := trace.StartSpan(ctx, "sqltracestore.WriteSamples.writeChunk")
+		defer span.End()
+
+		var b bytes.Buffer
+		if err := s.unpreparedStatements[insertIntoTraceSamples].Execute(&b, samplesTemplateContext[startIdx:endIdx]); err != nil {
+			return skerr.Wrapf(err, "failed to expand trace samples template")
+		}
+
+		sql := b.String()
+		if _, err := s.db.Exec(ctx, sql); err != nil {
+			return skerr.Wrapf(err, "Executing: %q", sql)
+		}
+		return nil
+	})
+}
+
+// ReadSamples implements the tracestore.TraceStore interface.
+func (s *SQLTraceStore) ReadSamples(ctx context.Context, points []tracestore.TracePoint) (map[tracestore.TracePoint][]float32, error) {
+	ctx, span := trace.StartSpan(ctx, "sqltracestore.ReadSamples")
+	defer span.End()
+
+	ret := map[tracestore.TracePoint][]float32{}
+	traceNameFromID := make(map[traceIDForSQLInBytes]string, len(points))
+	arguments := make([]interface{}, 0, len(points)*readSamplesColsPerRow)
+	for _, point := range points {
+		traceIDAsBytes := traceIDForSQLInBytesFromTraceName(point.TraceName)
+		traceNameFromID[traceIDAsBytes] = point.TraceName
+		arguments = append(arguments, traceIDAsBytes[:], point.CommitNumber)
+	}
+
+	err := util.ChunkIter(len(points), readSamplesChunkSize, func(startIdx int, endIdx int) error {
+		statement := statements[readSamples] + "(" + sqlutil.ValuesPlaceholders(readSamplesColsPerRow, endIdx-startIdx) + ")"
+		rows, err := s.db.Query(ctx, statement, arguments[startIdx*readSamplesColsPerRow:endIdx*readSamplesColsPerRow]...)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to read samples")
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var traceID []byte
+			var commitNumber types.CommitNumber
+			var samples []float32
+			if err := rows.Scan(&traceID, &commitNumber, &samples); err != nil {
+				return skerr.Wrapf(err, "Failed to scan samples")
+			}
+			var traceIDAsBytes traceIDForSQLInBytes
+			copy(traceIDAsBytes[:], traceID)
+			ret[tracestore.TracePoint{TraceName: traceNameFromID[traceIDAsBytes], CommitNumber: commitNumber}] = samples
+		}
+		return skerr.Wrap(rows.Err())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// WriteTraceMetadata implements the tracestore.TraceStore interface.
+func (s *SQLTraceStore) WriteTraceMetadata(ctx context.Context, params []paramtools.Params, metadata []tracestore.TraceMetadata) error {
+	ctx, span := trace.StartSpan(ctx, "sqltracestore.WriteTraceMetadata")
+	defer span.End()
+
+	if len(params) != len(metadata) {
+		return skerr.Fmt("params and metadata must be the same length: %d != %d", len(params), len(metadata))
+	}
+
+	arguments := []interface{}{}
+	for i, p := range params {
+		if metadata[i].IsEmpty() {
+			continue
+		}
+		traceName, err := query.MakeKey(p)
+		if err != nil {
+			sklog.Errorf("Somehow still invalid: %v", p)
+			continue
+		}
+		traceIDAsBytes := traceIDForSQLInBytesFromTraceName(traceName)
+		arguments = append(arguments, traceIDAsBytes[:], metadata[i].Unit, metadata[i].ImprovementDirection)
+	}
+
+	numRows := len(arguments) / upsertTraceMetadataColsPerRow
+	if numRows == 0 {
+		return nil
+	}
+	return util.ChunkIter(numRows, writeTraceMetadataChunkSize, func(startIdx int, endIdx int) error {
+		statement := statements[upsertTraceMetadata] + sqlutil.ValuesPlaceholders(upsertTraceMetadataColsPerRow, endIdx-startIdx)
+		if _, err := s.db.Exec(ctx, statement, arguments[startIdx*upsertTraceMetadataColsPerRow:endIdx*upsertTraceMetadataColsPerRow]...); err != nil {
+			return skerr.Wrapf(err, "Failed to write trace metadata")
+		}
+		return nil
+	})
+}
+
+// ReadTraceMetadata implements the tracestore.TraceStore interface.
+func (s *SQLTraceStore) ReadTraceMetadata(ctx context.Context, traceNames []string) (map[string]tracestore.TraceMetadata, error) {
+	ctx, span := trace.StartSpan(ctx, "sqltracestore.ReadTraceMetadata")
+	defer span.End()
+
+	ret := map[string]tracestore.TraceMetadata{}
+	if len(traceNames) == 0 {
+		return ret, nil
+	}
+	traceIDs := make([][]byte, 0, len(traceNames))
+	traceNameFromID := make(map[traceIDForSQLInBytes]string, len(traceNames))
+	for _, traceName := range traceNames {
+		traceIDAsBytes := traceIDForSQLInBytesFromTraceName(traceName)
+		traceIDs = append(traceIDs, traceIDAsBytes[:])
+		traceNameFromID[traceIDAsBytes] = traceName
+	}
+
+	rows, err := s.db.Query(ctx, statements[readTraceMetadata], traceIDs)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to read trace metadata")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var traceID []byte
+		var m tracestore.TraceMetadata
+		if err := rows.Scan(&traceID, &m.Unit, &m.ImprovementDirection); err != nil {
+			return nil, skerr.Wrapf(err, "Failed to scan trace metadata")
+		}
+		var traceIDAsBytes traceIDForSQLInBytes
+		copy(traceIDAsBytes[:], traceID)
+		ret[traceNameFromID[traceIDAsBytes]] = m
+	}
+	return ret, skerr.Wrap(rows.Err())
+}
+
+// traceIDsForTile returns the IDs of all the traces in the given tile.
+func (s *SQLTraceStore) traceIDsForTile(ctx context.Context, tileNumber types.TileNumber) ([]traceIDForSQL, error) {
+	rows, err := s.db.Query(ctx, statements[traceIDsForTile], tileNumber)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "tileNumber=%d", tileNumber)
+	}
+	defer rows.Close()
+	ret := []traceIDForSQL{}
+	for rows.Next() {
+		var traceIDAsBytes []byte
+		if err := rows.Scan(&traceIDAsBytes); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		ret = append(ret, traceIDForSQLFromTraceIDAsBytes(traceIDAsBytes))
+	}
+	return ret, nil
+}
+
+// execCompactTileTemplate expands the given compaction template and runs it,
+// returning the number of rows it affected. If the statement is a query then
+// the count it returns is used instead.
+func (s *SQLTraceStore) execCompactTileTemplate(ctx context.Context, db pgxExecer, stmt statement, compactContext compactTileContext) (int64, error) {
+	var b bytes.Buffer
+	if err := s.unpreparedStatements[stmt].Execute(&b, compactContext); err != nil {
+		return 0, skerr.Wrapf(err, "failed to expand template %v", stmt)
+	}
+	sql := b.String()
+	switch stmt {
+	case countTraceValuesForTile, countTraceSamplesForTile, countDownsampledTraceValues:
+		var count int64
+		if err := db.QueryRow(ctx, sql).Scan(&count); err != nil {
+			return 0, skerr.Wrapf(err, "Executing: %q", sql)
+		}
+		return count, nil
+	default:
+		tag, err := db.Exec(ctx, sql)
+		if err != nil {
+			return 0, skerr.Wrapf(err, "Executing: %q", sql)
+		}
+		return tag.RowsAffected(), nil
+	}
+}
+
+// pgxExecer is the subset of pool.Pool and pgx.Tx used when compacting.
+type pgxExecer interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// deleteInBatches runs the DELETE statement, which must take the tile number
+// and a LIMIT as arguments, until there is nothing left to delete, and returns
+// the number of rows deleted. If dryrun is true then the count statement is
+// run instead.
+func (s *SQLTraceStore) deleteInBatches(ctx context.Context, countStmt, deleteStmt statement, tileNumber types.TileNumber, dryrun bool) (int64, error) {
+	if dryrun {
+		var count int64
+		if err := s.db.QueryRow(ctx, statements[countStmt], tileNumber).Scan(&count); err != nil {
+			return 0, skerr.Wrapf(err, "tileNumber=%d", tileNumber)
+		}
+		return count, nil
+	}
+	var total int64
+	for {
+		tag, err := s.db.Exec(ctx, statements[deleteStmt], tileNumber, deleteBatchSize)
+		if err != nil {
+			return total, skerr.Wrapf(err, "tileNumber=%d", tileNumber)
+		}
+		total += tag.RowsAffected()
+		if tag.RowsAffected() < deleteBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// DropTile implements the tracestore.TraceStore interface.
+func (s *SQLTraceStore) DropTile(ctx context.Context, tileNumber types.TileNumber, dryrun bool) (tracestore.CompactionResult, error) {
+	ctx, span := trace.StartSpan(ctx, "sqltracestore.DropTile")
+	defer span.End()
+
+	ret := tracestore.CompactionResult{TileNumber: tileNumber}
+	traceIDs, err := s.traceIDsForTile(ctx, tileNumber)
+	if err != nil {
+		return ret, skerr.Wrap(err)
+	}
+
+	valuesStmt, samplesStmt := deleteTraceValuesForTile, deleteTraceSamplesForTile
+	if dryrun {
+		valuesStmt, samplesStmt = countTraceValuesForTile, countTraceSamplesForTile
+	}
+	beginCommit, endCommit := types.TileCommitRangeForTileNumber(tileNumber, s.tileSize)
+	err = util.ChunkIter(len(traceIDs), compactTileChunkSize, func(startIdx int, endIdx int) error {
+		if startIdx == endIdx {
+			// The tile has no traces.
+			return nil
+		}
+		compactContext := compactTileContext{
+			BeginCommitNumber: beginCommit,
+			EndCommitNumber:   endCommit,
+			TraceIDs:          traceIDs[startIdx:endIdx],
+		}
+		n, err := s.execCompactTileTemplate(ctx, s.db, valuesStmt, compactContext)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		ret.Values += n
+		n, err = s.execCompactTileTemplate(ctx, s.db, samplesStmt, compactContext)
+		if err != nil {
+			return skerr.Wrap(err)
+		}
+		ret.Samples += n
+		return nil
+	})
+	if err != nil {
+		return ret, skerr.Wrap(err)
+	}
+
+	// The Postings are removed last since they are used to find the traces
+	// in the tile, so an interrupted DropTile can be re-run.
+	ret.ParamSets, err = s.deleteInBatches(ctx, countParamSetsForTile, deleteParamSetsForTile, tileNumber, dryrun)
+	if err != nil {
+		return ret, skerr.Wrap(err)
+	}
+	ret.Postings, err = s.deleteInBatches(ctx, countPostingsForTile, deletePostingsForTile, tileNumber, dryrun)
+	if err != nil {
+		return ret, skerr.Wrap(err)
+	}
+	if !dryrun {
+		s.orderedParamSetCache.Remove(tileNumber)
+	}
+	return ret, nil
+}
+
+// DownsampleTile implements the tracestore.TraceStore interface.
+//
+// Windows are aligned on multiples of windowSize, and the source file of each
+// downsampled value is the most recently ingested file in its window.
+func (s *SQLTraceStore) DownsampleTile(ctx context.Context, tileNumber types.TileNumber, windowSize int32, dryrun bool) (tracestore.CompactionResult, error) {
+	ctx, span := trace.StartSpan(ctx, "sqltracestore.DownsampleTile")
+	defer span.End()
+
+	ret := tracestore.CompactionResult{TileNumber: tileNumber}
+	if windowSize <= 0 {
+		return ret, skerr.Fmt("Invalid window size: %d", windowSize)
+	}
+	traceIDs, err := s.traceIDsForTile(ctx, tileNumber)
+	if err != nil {
+		return ret, skerr.Wrap(err)
+	}
+
+	beginCommit, endCommit := types.TileCommitRangeForTileNumber(tileNumber, s.tileSize)
+	err = util.ChunkIter(len(traceIDs), compactTileChunkSize, func(startIdx int, endIdx int) error {
+		if startIdx == endIdx {
+			// The tile has no traces.
+			return nil
+		}
+		compactContext := compactTileContext{
+			BeginCommitNumber: beginCommit,
+			EndCommitNumber:   endCommit,
+			TraceIDs:          traceIDs[startIdx:endIdx],
+			WindowSize:        windowSize,
+		}
+		if dryrun {
+			n, err := s.execCompactTileTemplate(ctx, s.db, countDownsampledTraceValues, compactContext)
+			if err != nil {
+				return skerr.Wrap(err)
+			}
+			ret.Values += n
+			n, err = s.execCompactTileTemplate(ctx, s.db, countTraceSamplesForTile, compactContext)
+			if err != nil {
+				return skerr.Wrap(err)
+			}
+			ret.Samples += n
+			return nil
+		}
+
+		// The aggregate values must be written and the rest of the values
+		// removed together, otherwise re-running after a failure would
+		// average the averages.
+		return s.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+			if _, err := s.execCompactTileTemplate(ctx, tx, downsampleTraceValues, compactContext); err != nil {
+				return skerr.Wrap(err)
+			}
+			n, err := s.execCompactTileTemplate(ctx, tx, deleteDownsampledTraceValues, compactContext)
+			if err != nil {
+				return skerr.Wrap(err)
+			}
+			ret.Values += n
+			n, err = s.execCompactTileTemplate(ctx, tx, deleteTraceSamplesForTile, compactContext)
+			if err != nil {
+				return skerr.Wrap(err)
+			}
+			ret.Samples += n
+			return nil
+		})
+	})
+	if err != nil {
+		return ret, skerr.Wrap(err)
+	}
+	return ret, nil
+}
+
 // commitSliceFromCommitNumberRange returns a slice of Commits that fall in the range
 // [begin, end], i.e  inclusive of both begin and end.
 func (s *SQLTraceStore) commitSliceFromCommitNumberRange(ctx context.Context, begin, end types.CommitNumber) ([]provider.Commit, error) {
@@ -582,6 +582,66 @@ func TestGetSource_Empty(t *testing.T) {
 	assert.Equal(t, "", filename)
 }
 
+func TestWriteSamples_ReadSamples_RoundTrip(t *testing.T) {
+	ctx, s := commonTestSetup(t, false)
+
+	params := []paramtools.Params{
+		{"arch": "x86", "config": "8888"},
+		{"arch": "arm", "config": "8888"},
+	}
+	err := s.WriteSamples(ctx, types.CommitNumber(1), params, [][]float32{{1.0, 1.5, 2.0}, {}})
+	require.NoError(t, err)
+
+	// Empty samples aren't written.
+	samples, err := s.ReadSamples(ctx, []tracestore.TracePoint{
+		{TraceName: ",arch=x86,config=8888,", CommitNumber: 1},
+		{TraceName: ",arch=arm,config=8888,", CommitNumber: 1},
+		{TraceName: ",arch=x86,config=8888,", CommitNumber: 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[tracestore.TracePoint][]float32{
+		{TraceName: ",arch=x86,config=8888,", CommitNumber: 1}: {1.0, 1.5, 2.0},
+	}, samples)
+}
+
+func TestWriteSamples_MismatchedLengths_ReturnsError(t *testing.T) {
+	ctx, s := commonTestSetup(t, false)
+
+	err := s.WriteSamples(ctx, types.CommitNumber(1), []paramtools.Params{{"arch": "x86"}}, [][]float32{})
+	require.Error(t, err)
+}
+
+func TestWriteTraceMetadata_ReadTraceMetadata_RoundTrip(t *testing.T) {
+	ctx, s := commonTestSetup(t, false)
+
+	params := []paramtools.Params{
+		{"arch": "x86", "config": "8888"},
+		{"arch": "arm", "config": "8888"},
+	}
+	metadata := []tracestore.TraceMetadata{{Unit: "ms", ImprovementDirection: "down"}, {}}
+	require.NoError(t, s.WriteTraceMetadata(ctx, params, metadata))
+
+	// Empty metadata isn't written.
+	got, err := s.ReadTraceMetadata(ctx, []string{",arch=x86,config=8888,", ",arch=arm,config=8888,"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]tracestore.TraceMetadata{
+		",arch=x86,config=8888,": {Unit: "ms", ImprovementDirection: "down"},
+	}, got)
+
+	// Writing again replaces the metadata.
+	require.NoError(t, s.WriteTraceMetadata(ctx, params[:1], []tracestore.TraceMetadata{{Unit: "s", ImprovementDirection: "down"}}))
+	got, err = s.ReadTraceMetadata(ctx, []string{",arch=x86,config=8888,"})
+	require.NoError(t, err)
+	assert.Equal(t, "s", got[",arch=x86,config=8888,"].Unit)
+}
+
+func TestWriteTraceMetadata_MismatchedLengths_ReturnsError(t *testing.T) {
+	ctx, s := commonTestSetup(t, false)
+
+	err := s.WriteTraceMetadata(ctx, []paramtools.Params{{"arch": "x86"}}, []tracestore.TraceMetadata{})
+	require.Error(t, err)
+}
+
 func TestSQLTraceStore_TileNumber(t *testing.T) {
 	_, s := commonTestSetup(t, false)
 
@@ -747,6 +807,22 @@ func TestGetTraceIDsBySource_LookForSourceThatDoesNotExist_ReturnsEmptySlice(t *
 	require.Empty(t, traceIDs)
 }
 
+func TestGetTraceIDs_SecondTile_ReturnsSortedTraceIDs(t *testing.T) {
+	ctx, s := commonTestSetup(t, true)
+
+	traceIDs, err := s.GetTraceIDs(ctx, types.TileNumber(1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{",arch=x86,config=565,", ",arch=x86,config=8888,"}, traceIDs)
+}
+
+func TestGetTraceIDs_EmptyTile_ReturnsEmptySlice(t *testing.T) {
+	ctx, s := commonTestSetup(t, true)
+
+	traceIDs, err := s.GetTraceIDs(ctx, types.TileNumber(5))
+	require.NoError(t, err)
+	require.Empty(t, traceIDs)
+}
+
 func TestWriteTraces_InsertDifferentValueAndFile_OverwriteExistingTraceValues(t *testing.T) {
 	ctx, s := commonTestSetupWithCommits(t, true)
 	traceName1 := ",arch=x86,config=8888,"
@@ -867,3 +943,81 @@ func TestReadTraces_WithDiscontinueCommitNumbers_Succeed(t *testing.T) {
 		",arch=x86,config=8888,": {3.5, e, e, e, e, e, e, e},
 	}, ts)
 }
+
+func TestDropTile_DryRun_ReportsRowsAndRemovesNothing(t *testing.T) {
+	ctx, s := commonTestSetup(t, true)
+
+	res, err := s.DropTile(ctx, 0, true)
+	require.NoError(t, err)
+	assert.Equal(t, tracestore.CompactionResult{
+		TileNumber: 0,
+		Values:     4,
+		Postings:   4,
+		ParamSets:  3,
+	}, res)
+
+	count, err := s.TraceCount(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestDropTile_RemovesAllRowsInTheTile(t *testing.T) {
+	ctx, s := commonTestSetup(t, true)
+	err := s.WriteSamples(ctx, types.CommitNumber(1), []paramtools.Params{{"config": "8888", "arch": "x86"}}, [][]float32{{1.0, 2.0}})
+	require.NoError(t, err)
+
+	res, err := s.DropTile(ctx, 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), res.Values)
+	assert.Equal(t, int64(1), res.Samples)
+
+	count, err := s.TraceCount(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+	ps, err := s.paramSetForTile(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, paramtools.NewReadOnlyParamSet(), ps)
+
+	// The next tile is untouched.
+	count, err = s.TraceCount(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// Dropping again is a no-op.
+	res, err = s.DropTile(ctx, 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Total())
+}
+
+func TestDownsampleTile_AveragesValuesInEachWindow(t *testing.T) {
+	ctx, s := commonTestSetup(t, true)
+
+	res, err := s.DownsampleTile(ctx, 0, 4, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Values)
+
+	res, err = s.DownsampleTile(ctx, 0, 4, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Values)
+
+	ts, _, err := s.ReadTraces(ctx, 0, []string{
+		",arch=x86,config=8888,",
+		",arch=x86,config=565,",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.Trace{e, e, e, 2, e, e, e, e}, ts[",arch=x86,config=8888,"])
+	assert.InDelta(t, 2.8, ts[",arch=x86,config=565,"][3], 1e-5)
+	assert.Equal(t, e, ts[",arch=x86,config=565,"][1])
+
+	// Downsampling again is a no-op.
+	res, err = s.DownsampleTile(ctx, 0, 4, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Total())
+}
+
+func TestDownsampleTile_InvalidWindowSize_ReturnsError(t *testing.T) {
+	ctx, s := commonTestSetup(t, true)
+
+	_, err := s.DownsampleTile(ctx, 0, 0, false)
+	require.Error(t, err)
+}
@@ -19,6 +19,43 @@ type Source struct {
 	CommitNumber types.CommitNumber
 }
 
+// TraceMetadata describes a trace without being part of its trace id, so it
+// can be set or changed without starting a new trace.
+type TraceMetadata struct {
+	// Unit is the unit of the trace values, e.g. "ms", or "" if unknown.
+	Unit string `json:"unit,omitempty"`
+
+	// ImprovementDirection is "up" if larger values are better, "down" if
+	// smaller values are better, or "" if unknown.
+	ImprovementDirection string `json:"improvement_direction,omitempty"`
+}
+
+// IsEmpty returns true if no metadata is set.
+func (m TraceMetadata) IsEmpty() bool {
+	return m == TraceMetadata{}
+}
+
+// TracePoint is the point of a trace at a commit.
+type TracePoint struct {
+	TraceName    string
+	CommitNumber types.CommitNumber
+}
+
+// CompactionResult is the number of rows removed from each kind of storage
+// when a tile is compacted, or the number that would be removed on a dry run.
+type CompactionResult struct {
+	TileNumber types.TileNumber `json:"tile_number"`
+	Values     int64            `json:"values"`
+	Samples    int64            `json:"samples"`
+	Postings   int64            `json:"postings"`
+	ParamSets  int64            `json:"paramsets"`
+}
+
+// Total returns the total number of rows removed.
+func (c CompactionResult) Total() int64 {
+	return c.Values + c.Samples + c.Postings + c.ParamSets
+}
+
 // TraceStore is the interface that all backends that store traces must
 // implement. It is used by dfbuilder to build DataFrames and by the perf-tool
 // to perform some common maintenance tasks.
@@ -27,6 +64,18 @@ type TraceStore interface {
 	// given tile.
 	CommitNumberOfTileStart(commitNumber types.CommitNumber) types.CommitNumber
 
+	// DownsampleTile replaces the values of every trace in the given tile with
+	// one value per window of windowSize commits, the mean of the values in the
+	// window, stored at the last commit in the window that has a value. All the
+	// raw samples in the tile are removed. If dryrun is true then nothing is
+	// changed and the returned CompactionResult reports what would be removed.
+	DownsampleTile(ctx context.Context, tileNumber types.TileNumber, windowSize int32, dryrun bool) (CompactionResult, error)
+
+	// DropTile removes all the data stored for the given tile. If dryrun is
+	// true then nothing is removed and the returned CompactionResult reports
+	// what would be removed.
+	DropTile(ctx context.Context, tileNumber types.TileNumber, dryrun bool) (CompactionResult, error)
+
 	// GetLatestTile returns the latest, i.e. the newest tile.
 	GetLatestTile(context.Context) (types.TileNumber, error)
 
@@ -41,6 +90,10 @@ type TraceStore interface {
 	// n commits to the given trace.
 	GetLastNSources(ctx context.Context, traceID string, n int) ([]Source, error)
 
+	// GetTraceIDs returns the names of all the traces in the given tile,
+	// sorted.
+	GetTraceIDs(ctx context.Context, tileNumber types.TileNumber) ([]string, error)
+
 	// GetTraceIDsBySource returns all the traceIDs that came from a given
 	// ingested file.
 	GetTraceIDsBySource(ctx context.Context, sourceFilename string, tileNumber types.TileNumber) ([]string, error)
@@ -64,6 +117,14 @@ type TraceStore interface {
 	// between the begin and end commit, inclusive.
 	ReadTracesForCommitRange(ctx context.Context, keys []string, begin types.CommitNumber, end types.CommitNumber) (types.TraceSet, []provider.Commit, error)
 
+	// ReadSamples returns the raw samples of the given points, keyed by point.
+	// Points that no samples were written for aren't included in the result.
+	ReadSamples(ctx context.Context, points []TracePoint) (map[TracePoint][]float32, error)
+
+	// ReadTraceMetadata returns the metadata of the given traces, keyed by
+	// trace name. Traces without metadata aren't included in the result.
+	ReadTraceMetadata(ctx context.Context, traceNames []string) (map[string]TraceMetadata, error)
+
 	// TileNumber returns the types.TileNumber that the commit is stored in.
 	TileNumber(commitNumber types.CommitNumber) types.TileNumber
 
@@ -85,6 +146,19 @@ type TraceStore interface {
 	// match.
 	WriteTraces(ctx context.Context, commitNumber types.CommitNumber, params []paramtools.Params, values []float32, paramset paramtools.ParamSet, source string, timestamp time.Time) error
 
+	// WriteSamples writes the raw samples that the values written by
+	// WriteTraces were computed from.
+	//
+	// Note that 'params' and 'samples' are parallel slices and thus need to
+	// match. Empty entries in 'samples' are skipped.
+	WriteSamples(ctx context.Context, commitNumber types.CommitNumber, params []paramtools.Params, samples [][]float32) error
+
+	// WriteTraceMetadata replaces the metadata of the given traces.
+	//
+	// Note that 'params' and 'metadata' are parallel slices and thus need to
+	// match. Empty entries in 'metadata' are skipped.
+	WriteTraceMetadata(ctx context.Context, params []paramtools.Params, metadata []TraceMetadata) error
+
 	// StartBackgroundMetricsGathering runs metrics collection in the background
 	StartBackgroundMetricsGathering()
 }
@@ -0,0 +1,108 @@
+package triagerules
+
+import (
+	"context"
+
+	"go.skia.org/infra/go/metrics2"
+	"go.skia.org/infra/go/now"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sklog"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/clustering2"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/types"
+)
+
+// Result is the outcome of applying the Rules to a single regression.
+type Result struct {
+	// Rule is the Rule that matched.
+	Rule *Rule
+
+	// TriageStatus is the status the regression should be given.
+	TriageStatus regression.TriageStatus
+
+	// Notify is false if no notification should be sent for the regression.
+	Notify bool
+}
+
+// Engine evaluates the Rules in a Store against newly found regressions.
+type Engine struct {
+	store Store
+
+	applied metrics2.Counter
+	errors  metrics2.Counter
+}
+
+// NewEngine returns a new *Engine that uses the Rules in the given Store.
+func NewEngine(store Store) *Engine {
+	return &Engine{
+		store:   store,
+		applied: metrics2.GetCounter("perf_triage_rules_applied"),
+		errors:  metrics2.GetCounter("perf_triage_rules_errors"),
+	}
+}
+
+// Evaluate returns the first active Rule that matches the regression, or nil
+// if no Rule matches.
+func (e *Engine) Evaluate(ctx context.Context, alert *alerts.Alert, commit provider.Commit, cl *clustering2.ClusterSummary) (*Rule, error) {
+	rules, err := e.store.List(ctx, false)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to load triage rules")
+	}
+	for _, rule := range rules {
+		// Invalid rules, e.g. ones edited directly in the database, must not
+		// fall back to ignoring regressions.
+		if err := rule.Validate(); err != nil {
+			e.errors.Inc(1)
+			sklog.Errorf("Skipping invalid triage rule %d: %s", rule.ID, err)
+			continue
+		}
+		matched, err := rule.Matches(alert, commit, cl)
+		if err != nil {
+			// A single bad rule shouldn't stop the rest from being evaluated.
+			e.errors.Inc(1)
+			sklog.Errorf("Failed to evaluate triage rule: %s", err)
+			continue
+		}
+		if matched {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// Apply evaluates the Rules against a newly found regression and, if one
+// matches, records the match in the audit trail and returns the Result. A nil
+// Result is returned if no Rule matches.
+//
+// The caller is responsible for storing the returned TriageStatus.
+func (e *Engine) Apply(ctx context.Context, commitNumber types.CommitNumber, alert *alerts.Alert, commit provider.Commit, cl *clustering2.ClusterSummary, clusterType regression.ClusterType) (*Result, error) {
+	rule, err := e.Evaluate(ctx, alert, commit, cl)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	if rule == nil {
+		return nil, nil
+	}
+	ret := &Result{
+		Rule:         rule,
+		TriageStatus: rule.TriageStatus(),
+		Notify:       rule.Action != Ignore,
+	}
+	entry := &AuditEntry{
+		RuleID:       rule.ID,
+		RuleName:     rule.Name,
+		AlertID:      alert.IDAsString,
+		CommitNumber: commitNumber,
+		ClusterType:  clusterType,
+		Action:       rule.Action,
+		Message:      ret.TriageStatus.Message,
+		Timestamp:    now.Now(ctx),
+	}
+	if err := e.store.RecordAudit(ctx, entry); err != nil {
+		return nil, skerr.Wrapf(err, "Failed to record triage rule %d in the audit trail", rule.ID)
+	}
+	e.applied.Inc(1)
+	return ret, nil
+}
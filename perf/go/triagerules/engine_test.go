@@ -0,0 +1,123 @@
+package triagerules
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/go/now"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/types"
+)
+
+const commitNumber = types.CommitNumber(100)
+
+// fakeStore is an in-memory Store. The mocks package can't be used here since
+// it imports this package.
+type fakeStore struct {
+	rules   []*Rule
+	listErr error
+	audit   []*AuditEntry
+}
+
+func (f *fakeStore) Save(ctx context.Context, rule *Rule) error { return nil }
+
+func (f *fakeStore) Delete(ctx context.Context, id int64) error { return nil }
+
+func (f *fakeStore) List(ctx context.Context, includeDeleted bool) ([]*Rule, error) {
+	return f.rules, f.listErr
+}
+
+func (f *fakeStore) RecordAudit(ctx context.Context, entry *AuditEntry) error {
+	f.audit = append(f.audit, entry)
+	return nil
+}
+
+func (f *fakeStore) ListAudit(ctx context.Context, limit int) ([]*AuditEntry, error) {
+	return f.audit, nil
+}
+
+func TestApply_NoRules_ReturnsNilResult(t *testing.T) {
+	store := &fakeStore{}
+
+	res, err := NewEngine(store).Apply(context.Background(), commitNumber, testAlert(), commit, testCluster(), regression.LowClusterType)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestApply_FirstMatchingRuleIsAppliedAndAudited(t *testing.T) {
+	notMatching := testRule()
+	notMatching.ID = 1
+	notMatching.AlertIDs = []string{"99"}
+
+	ignore := testRule()
+	ignore.ID = 2
+	ignore.Name = "ignore-rolls"
+	ignore.SubjectRegex = "^Roll "
+
+	bug := testRule()
+	bug.ID = 3
+	bug.Action = AttachBug
+	bug.Bug = "b/1"
+
+	store := &fakeStore{rules: []*Rule{notMatching, ignore, bug}}
+
+	ts := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.WithValue(context.Background(), now.ContextKey, ts)
+	res, err := NewEngine(store).Apply(ctx, commitNumber, testAlert(), commit, testCluster(), regression.LowClusterType)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, ignore, res.Rule)
+	assert.Equal(t, regression.Positive, res.TriageStatus.Status)
+	assert.False(t, res.Notify)
+
+	require.Len(t, store.audit, 1)
+	entry := store.audit[0]
+	assert.Equal(t, int64(2), entry.RuleID)
+	assert.Equal(t, "ignore-rolls", entry.RuleName)
+	assert.Equal(t, alertID, entry.AlertID)
+	assert.Equal(t, commitNumber, entry.CommitNumber)
+	assert.Equal(t, regression.LowClusterType, entry.ClusterType)
+	assert.Equal(t, Ignore, entry.Action)
+	assert.Equal(t, ts, entry.Timestamp)
+}
+
+func TestApply_RuleWithInvalidRegexIsSkipped(t *testing.T) {
+	bad := testRule()
+	bad.SubjectRegex = "("
+	bug := testRule()
+	bug.Action = AttachBug
+	bug.Bug = "b/1"
+
+	store := &fakeStore{rules: []*Rule{bad, bug}}
+
+	res, err := NewEngine(store).Apply(context.Background(), commitNumber, testAlert(), commit, testCluster(), regression.LowClusterType)
+	require.NoError(t, err)
+	assert.Equal(t, bug, res.Rule)
+	assert.True(t, res.Notify)
+}
+
+func TestApply_RuleWithUnknownActionIsSkipped(t *testing.T) {
+	unknown := testRule()
+	unknown.Action = "delete"
+	bug := testRule()
+	bug.Action = AttachBug
+	bug.Bug = "b/1"
+
+	store := &fakeStore{rules: []*Rule{unknown, bug}}
+
+	res, err := NewEngine(store).Apply(context.Background(), commitNumber, testAlert(), commit, testCluster(), regression.LowClusterType)
+	require.NoError(t, err)
+	assert.Equal(t, bug, res.Rule)
+	assert.True(t, res.Notify)
+}
+
+func TestApply_ListFails_ReturnsError(t *testing.T) {
+	store := &fakeStore{listErr: errors.New("my error")}
+
+	_, err := NewEngine(store).Apply(context.Background(), commitNumber, testAlert(), commit, testCluster(), regression.LowClusterType)
+	require.Error(t, err)
+}
@@ -0,0 +1,179 @@
+// Package localtriagerulestore implements triagerules.Store using a
+// localstore.DB.
+package localtriagerulestore
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/triagerules"
+)
+
+const (
+	// rulesTable is the name of the table of rules in the localstore.DB.
+	rulesTable = "triagerules"
+
+	// auditTable is the name of the table of audit entries in the
+	// localstore.DB.
+	auditTable = "triageruleaudit"
+)
+
+// ruleRow is a single stored Rule.
+type ruleRow struct {
+	// Rule is the JSON serialized triagerules.Rule.
+	Rule         string
+	Deleted      bool
+	LastModified int64
+}
+
+// rules is the data stored in the rules table.
+type rules struct {
+	NextID int64
+	Rows   map[int64]ruleRow
+}
+
+// audit is the data stored in the audit table.
+type audit struct {
+	NextID  int64
+	Entries []triagerules.AuditEntry
+}
+
+// LocalTriageRuleStore implements the triagerules.Store interface.
+type LocalTriageRuleStore struct {
+	db *localstore.DB
+
+	// mutex protects rules and audit.
+	mutex sync.Mutex
+	rules rules
+	audit audit
+}
+
+// New returns a new *LocalTriageRuleStore.
+func New(db *localstore.DB) (*LocalTriageRuleStore, error) {
+	ret := &LocalTriageRuleStore{
+		db: db,
+		rules: rules{
+			NextID: 1,
+			Rows:   map[int64]ruleRow{},
+		},
+		audit: audit{
+			NextID:  1,
+			Entries: []triagerules.AuditEntry{},
+		},
+	}
+	if err := db.Read(rulesTable, &ret.rules); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	if err := db.Read(auditTable, &ret.audit); err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return ret, nil
+}
+
+// Save implements the triagerules.Store interface.
+func (s *LocalTriageRuleStore) Save(ctx context.Context, rule *triagerules.Rule) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if rule.ID == triagerules.BadRuleID {
+		// Not a valid ID, so this should be an insert, not an update.
+		rule.ID = s.rules.NextID
+	}
+	if rule.ID >= s.rules.NextID {
+		s.rules.NextID = rule.ID + 1
+	}
+	b, err := json.Marshal(rule)
+	if err != nil {
+		return skerr.Wrapf(err, "Failed to serialize triage rule with ID=%d", rule.ID)
+	}
+	s.rules.Rows[rule.ID] = ruleRow{
+		Rule:         string(b),
+		LastModified: time.Now().Unix(),
+	}
+	return skerr.Wrap(s.db.Write(rulesTable, s.rules))
+}
+
+// Delete implements the triagerules.Store interface.
+func (s *LocalTriageRuleStore) Delete(ctx context.Context, id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	row, ok := s.rules.Rows[id]
+	if !ok {
+		return nil
+	}
+	row.Deleted = true
+	row.LastModified = time.Now().Unix()
+	s.rules.Rows[id] = row
+	if err := s.db.Write(rulesTable, s.rules); err != nil {
+		return skerr.Wrapf(err, "Failed to mark triage rule as deleted with ID=%d", id)
+	}
+	return nil
+}
+
+// List implements the triagerules.Store interface.
+func (s *LocalTriageRuleStore) List(ctx context.Context, includeDeleted bool) ([]*triagerules.Rule, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ret := []*triagerules.Rule{}
+	for id, row := range s.rules.Rows {
+		if !includeDeleted && row.Deleted {
+			continue
+		}
+		rule := triagerules.NewRule()
+		if err := json.Unmarshal([]byte(row.Rule), rule); err != nil {
+			return nil, skerr.Wrapf(err, "Failed to deserialize JSON triage rule.")
+		}
+		rule.ID = id
+		ret = append(ret, rule)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+	return ret, nil
+}
+
+// RecordAudit implements the triagerules.Store interface.
+func (s *LocalTriageRuleStore) RecordAudit(ctx context.Context, entry *triagerules.AuditEntry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry.ID = s.audit.NextID
+	s.audit.NextID++
+	s.audit.Entries = append(s.audit.Entries, *entry)
+	if err := s.db.Write(auditTable, s.audit); err != nil {
+		return skerr.Wrapf(err, "Failed to record audit entry for triage rule %d", entry.RuleID)
+	}
+	return nil
+}
+
+// ListAudit implements the triagerules.Store interface.
+func (s *LocalTriageRuleStore) ListAudit(ctx context.Context, limit int) ([]*triagerules.AuditEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ret := make([]*triagerules.AuditEntry, 0, len(s.audit.Entries))
+	for i := range s.audit.Entries {
+		entry := s.audit.Entries[i]
+		ret = append(ret, &entry)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Timestamp.Equal(ret[j].Timestamp) {
+			return ret[i].ID > ret[j].ID
+		}
+		return ret[i].Timestamp.After(ret[j].Timestamp)
+	})
+	if limit >= 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret, nil
+}
+
+// Confirm that *LocalTriageRuleStore implements triagerules.Store.
+var _ triagerules.Store = (*LocalTriageRuleStore)(nil)
@@ -0,0 +1,78 @@
+package localtriagerulestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/localstore"
+	"go.skia.org/infra/perf/go/triagerules"
+	"go.skia.org/infra/perf/go/types"
+)
+
+func setUp(t *testing.T) (*LocalTriageRuleStore, *localstore.DB) {
+	db, err := localstore.New(t.TempDir())
+	require.NoError(t, err)
+	store, err := New(db)
+	require.NoError(t, err)
+	return store, db
+}
+
+func TestStore_SaveListDelete(t *testing.T) {
+	ctx := context.Background()
+	store, db := setUp(t)
+
+	rule := triagerules.NewRule()
+	rule.Name = "ignore-flaky-bot"
+	rule.Query = "bot=flaky"
+	require.NoError(t, store.Save(ctx, rule))
+	require.NotEqual(t, triagerules.BadRuleID, rule.ID)
+
+	second := triagerules.NewRule()
+	second.Name = "second"
+	require.NoError(t, store.Save(ctx, second))
+	require.Greater(t, second.ID, rule.ID)
+
+	rules, err := store.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, rule, rules[0])
+
+	require.NoError(t, store.Delete(ctx, rule.ID))
+	rules, err = store.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "second", rules[0].Name)
+
+	// Confirm the data is persisted.
+	reopened, err := New(db)
+	require.NoError(t, err)
+	rules, err = reopened.List(ctx, true)
+	require.NoError(t, err)
+	assert.Len(t, rules, 2)
+}
+
+func TestListAudit_ThreeEntries_NewestFirstAndLimited(t *testing.T) {
+	ctx := context.Background()
+	store, db := setUp(t)
+
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, store.RecordAudit(ctx, &triagerules.AuditEntry{
+			RuleID:       1,
+			CommitNumber: types.CommitNumber(i),
+			Action:       triagerules.Ignore,
+			Timestamp:    now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	reopened, err := New(db)
+	require.NoError(t, err)
+	entries, err := reopened.ListAudit(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, types.CommitNumber(2), entries[0].CommitNumber)
+	assert.Equal(t, types.CommitNumber(1), entries[1].CommitNumber)
+}
@@ -0,0 +1,52 @@
+package schema
+
+import "time"
+
+// TriageRuleSchema represents the SQL schema of the TriageRules table.
+type TriageRuleSchema struct {
+	ID int `sql:"id INT PRIMARY KEY DEFAULT unique_rowid()"`
+
+	// A triagerules.Rule serialized as JSON.
+	Rule string `sql:"rule TEXT"`
+
+	// Deleted rules are kept so that the audit trail can refer to them.
+	Deleted bool `sql:"deleted BOOL DEFAULT false"`
+
+	// Stored as a Unix timestamp.
+	LastModified int `sql:"last_modified INT"`
+}
+
+// TriageRuleAuditSchema represents the SQL schema of the TriageRuleAudit
+// table, which records every time a triage rule was applied to a regression.
+type TriageRuleAuditSchema struct {
+	ID int `sql:"id INT PRIMARY KEY DEFAULT unique_rowid()"`
+
+	// The id of the rule in the TriageRules table.
+	RuleID int `sql:"rule_id INT"`
+
+	// The name of the rule at the time it was applied.
+	RuleName string `sql:"rule_name TEXT"`
+
+	// The alert that found the regression.
+	AlertID string `sql:"alert_id TEXT"`
+
+	// The commit the regression was found at.
+	CommitNumber int `sql:"commit_number INT"`
+
+	// Either "high" or "low".
+	ClusterType string `sql:"cluster_type TEXT"`
+
+	// The triagerules.ActionType that was applied.
+	Action string `sql:"action TEXT"`
+
+	// The triage message that was applied.
+	Message string `sql:"message TEXT"`
+
+	CreatedAt time.Time `sql:"created_at TIMESTAMPTZ DEFAULT now()"`
+
+	byRuleIDIndex    struct{} `sql:"INDEX by_rule_id (rule_id)"`
+	byCreatedAtIndex struct{} `sql:"INDEX by_created_at (created_at DESC)"`
+
+	// Used to look up whether a rule was applied to a given regression.
+	byAlertCommitIndex struct{} `sql:"INDEX by_alert_commit (alert_id, commit_number)"`
+}
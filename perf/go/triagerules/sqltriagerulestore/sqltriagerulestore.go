@@ -0,0 +1,215 @@
+// Package sqltriagerulestore implements triagerules.Store using SQL.
+//
+// Please see perf/go/sql/schema.go for the database schema used.
+package sqltriagerulestore
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/sql/pool"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/triagerules"
+	"go.skia.org/infra/perf/go/types"
+)
+
+// statement is an SQL statement identifier.
+type statement int
+
+const (
+	// The identifiers for all the SQL statements used.
+	insertRule statement = iota
+	updateRule
+	deleteRule
+	listActiveRules
+	listAllRules
+	insertAudit
+	listAudit
+)
+
+// statements holds all the raw SQL statements used.
+var statements = map[statement]string{
+	insertRule: `
+		INSERT INTO
+			TriageRules (rule, last_modified)
+		VALUES
+			($1, $2)
+		RETURNING
+			id
+		`,
+	updateRule: `
+		UPSERT INTO
+			TriageRules (id, rule, deleted, last_modified)
+		VALUES
+			($1, $2, false, $3)
+		`,
+	deleteRule: `
+		UPDATE
+			TriageRules
+		SET
+			deleted=true,
+			last_modified=$1
+		WHERE
+			id=$2
+		`,
+	listActiveRules: `
+		SELECT
+			id, rule
+		FROM
+			TriageRules
+		WHERE
+			deleted=false
+		ORDER BY
+			id
+		`,
+	listAllRules: `
+		SELECT
+			id, rule
+		FROM
+			TriageRules
+		ORDER BY
+			id
+		`,
+	insertAudit: `
+		INSERT INTO
+			TriageRuleAudit (rule_id, rule_name, alert_id, commit_number, cluster_type, action, message, created_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING
+			id
+		`,
+	listAudit: `
+		SELECT
+			id, rule_id, rule_name, alert_id, commit_number, cluster_type, action, message, created_at
+		FROM
+			TriageRuleAudit
+		ORDER BY
+			created_at DESC, id DESC
+		LIMIT
+			$1
+		`,
+}
+
+// SQLTriageRuleStore implements the triagerules.Store interface.
+type SQLTriageRuleStore struct {
+	// db is the database interface.
+	db pool.Pool
+}
+
+// New returns a new *SQLTriageRuleStore.
+//
+// We presume all migrations have been run against db before this function is
+// called.
+func New(db pool.Pool) (*SQLTriageRuleStore, error) {
+	return &SQLTriageRuleStore{
+		db: db,
+	}, nil
+}
+
+// Save implements the triagerules.Store interface.
+func (s *SQLTriageRuleStore) Save(ctx context.Context, rule *triagerules.Rule) error {
+	now := time.Now().Unix()
+	if rule.ID == triagerules.BadRuleID {
+		b, err := json.Marshal(rule)
+		if err != nil {
+			return skerr.Wrapf(err, "Failed to serialize triage rule %q", rule.Name)
+		}
+		// Not a valid ID, so this should be an insert, not an update.
+		if err := s.db.QueryRow(ctx, statements[insertRule], string(b), now).Scan(&rule.ID); err != nil {
+			return skerr.Wrapf(err, "Failed to insert triage rule")
+		}
+		return nil
+	}
+	b, err := json.Marshal(rule)
+	if err != nil {
+		return skerr.Wrapf(err, "Failed to serialize triage rule with ID=%d", rule.ID)
+	}
+	if _, err := s.db.Exec(ctx, statements[updateRule], rule.ID, string(b), now); err != nil {
+		return skerr.Wrapf(err, "Failed to update triage rule with ID=%d", rule.ID)
+	}
+	return nil
+}
+
+// Delete implements the triagerules.Store interface.
+func (s *SQLTriageRuleStore) Delete(ctx context.Context, id int64) error {
+	if _, err := s.db.Exec(ctx, statements[deleteRule], time.Now().Unix(), id); err != nil {
+		return skerr.Wrapf(err, "Failed to mark triage rule as deleted with ID=%d", id)
+	}
+	return nil
+}
+
+// List implements the triagerules.Store interface.
+func (s *SQLTriageRuleStore) List(ctx context.Context, includeDeleted bool) ([]*triagerules.Rule, error) {
+	stmt := listActiveRules
+	if includeDeleted {
+		stmt = listAllRules
+	}
+	rows, err := s.db.Query(ctx, statements[stmt])
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to load triage rules")
+	}
+	defer rows.Close()
+	ret := []*triagerules.Rule{}
+	for rows.Next() {
+		var id int64
+		var serializedRule string
+		if err := rows.Scan(&id, &serializedRule); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		rule := triagerules.NewRule()
+		if err := json.Unmarshal([]byte(serializedRule), rule); err != nil {
+			return nil, skerr.Wrapf(err, "Failed to deserialize JSON triage rule.")
+		}
+		// The ID is only known after the first insert, so the stored JSON
+		// may not contain it.
+		rule.ID = id
+		ret = append(ret, rule)
+	}
+	return ret, nil
+}
+
+// RecordAudit implements the triagerules.Store interface.
+func (s *SQLTriageRuleStore) RecordAudit(ctx context.Context, entry *triagerules.AuditEntry) error {
+	err := s.db.QueryRow(ctx, statements[insertAudit],
+		entry.RuleID,
+		entry.RuleName,
+		entry.AlertID,
+		entry.CommitNumber,
+		string(entry.ClusterType),
+		string(entry.Action),
+		entry.Message,
+		entry.Timestamp,
+	).Scan(&entry.ID)
+	if err != nil {
+		return skerr.Wrapf(err, "Failed to record audit entry for triage rule %d", entry.RuleID)
+	}
+	return nil
+}
+
+// ListAudit implements the triagerules.Store interface.
+func (s *SQLTriageRuleStore) ListAudit(ctx context.Context, limit int) ([]*triagerules.AuditEntry, error) {
+	rows, err := s.db.Query(ctx, statements[listAudit], limit)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to load triage rule audit trail")
+	}
+	defer rows.Close()
+	ret := []*triagerules.AuditEntry{}
+	for rows.Next() {
+		entry := &triagerules.AuditEntry{}
+		var commitNumber int64
+		var clusterType, action string
+		if err := rows.Scan(&entry.ID, &entry.RuleID, &entry.RuleName, &entry.AlertID, &commitNumber, &clusterType, &action, &entry.Message, &entry.Timestamp); err != nil {
+			return nil, skerr.Wrap(err)
+		}
+		entry.CommitNumber = types.CommitNumber(commitNumber)
+		entry.ClusterType = regression.ClusterType(clusterType)
+		entry.Action = triagerules.ActionType(action)
+		ret = append(ret, entry)
+	}
+	return ret, nil
+}
+
+// Confirm that *SQLTriageRuleStore implements triagerules.Store.
+var _ triagerules.Store = (*SQLTriageRuleStore)(nil)
@@ -0,0 +1,99 @@
+package sqltriagerulestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/sql/sqltest"
+	"go.skia.org/infra/perf/go/triagerules"
+	"go.skia.org/infra/perf/go/types"
+)
+
+func setUp(t *testing.T) *SQLTriageRuleStore {
+	db := sqltest.NewCockroachDBForTests(t, "triagerulestore")
+	store, err := New(db)
+	require.NoError(t, err)
+	return store
+}
+
+func TestSave_NewRule_IDIsAssignedAndRuleIsListed(t *testing.T) {
+	ctx := context.Background()
+	store := setUp(t)
+
+	rule := triagerules.NewRule()
+	rule.Name = "ignore-flaky-bot"
+	rule.Query = "bot=flaky"
+	require.NoError(t, store.Save(ctx, rule))
+	require.NotEqual(t, triagerules.BadRuleID, rule.ID)
+
+	rules, err := store.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, rule, rules[0])
+}
+
+func TestSave_ExistingRule_RuleIsUpdated(t *testing.T) {
+	ctx := context.Background()
+	store := setUp(t)
+
+	rule := triagerules.NewRule()
+	rule.Name = "first"
+	require.NoError(t, store.Save(ctx, rule))
+	rule.Name = "second"
+	require.NoError(t, store.Save(ctx, rule))
+
+	rules, err := store.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "second", rules[0].Name)
+}
+
+func TestDelete_RuleIsOnlyListedWhenIncludingDeleted(t *testing.T) {
+	ctx := context.Background()
+	store := setUp(t)
+
+	rule := triagerules.NewRule()
+	rule.Name = "to-be-deleted"
+	require.NoError(t, store.Save(ctx, rule))
+	require.NoError(t, store.Delete(ctx, rule.ID))
+
+	rules, err := store.List(ctx, false)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	rules, err = store.List(ctx, true)
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+}
+
+func TestListAudit_TwoEntries_NewestFirstAndLimited(t *testing.T) {
+	ctx := context.Background()
+	store := setUp(t)
+
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, store.RecordAudit(ctx, &triagerules.AuditEntry{
+			RuleID:       1,
+			RuleName:     "my-rule",
+			AlertID:      "12",
+			CommitNumber: types.CommitNumber(100 + i),
+			ClusterType:  regression.HighClusterType,
+			Action:       triagerules.AttachBug,
+			Message:      "b/123",
+			Timestamp:    now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	entries, err := store.ListAudit(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, types.CommitNumber(101), entries[0].CommitNumber)
+	assert.Equal(t, regression.HighClusterType, entries[0].ClusterType)
+	assert.Equal(t, triagerules.AttachBug, entries[0].Action)
+	assert.Equal(t, "b/123", entries[0].Message)
+	assert.True(t, now.Add(time.Minute).Equal(entries[0].Timestamp))
+}
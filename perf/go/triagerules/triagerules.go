@@ -0,0 +1,294 @@
+// Package triagerules provides a rules engine that automatically triages newly
+// found regressions.
+//
+// Each Rule has a set of match criteria, such as the Alert that found the
+// regression, a query the regressing traces must match, the magnitude of the
+// step, and the author and subject of the commit the regression was found at.
+// The first active Rule, in ID order, that matches a new regression is applied
+// to it, and every application is recorded in an audit trail.
+package triagerules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"time"
+
+	"go.skia.org/infra/go/query"
+	"go.skia.org/infra/go/skerr"
+	"go.skia.org/infra/go/util"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/clustering2"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/stepfit"
+	"go.skia.org/infra/perf/go/types"
+)
+
+// BadRuleID is the ID of a Rule that hasn't been saved yet.
+const BadRuleID int64 = -1
+
+// ActionType is the action a Rule takes on a matching regression.
+type ActionType string
+
+const (
+	// Ignore marks the regression as Positive, i.e. expected, and suppresses
+	// the notification for it.
+	Ignore ActionType = "ignore"
+
+	// AttachBug marks the regression as Negative with the Rule's Bug as the
+	// triage message.
+	AttachBug ActionType = "bug"
+
+	// BumpPriority leaves the regression untriaged, and sets the priority of
+	// the issue filed for it to the Rule's Priority. Only notifiers that file
+	// issues, i.e. the issue tracker, support priorities.
+	BumpPriority ActionType = "priority"
+)
+
+// AllActions is the list of all ActionType values.
+var AllActions = []ActionType{Ignore, AttachBug, BumpPriority}
+
+// MaxPriority is the lowest priority, i.e. the largest value, that
+// BumpPriority can set. The highest priority is 0.
+const MaxPriority = 4
+
+// Rule is a single auto-triage rule.
+//
+// All the match criteria that are set must match for the Rule to apply. A Rule
+// with no criteria set matches every regression.
+type Rule struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+
+	// Active rules are evaluated, inactive ones are not.
+	Active bool `json:"active"`
+
+	// AlertIDs, if not empty, is the list of alerts.Alert.IDAsString values
+	// whose regressions the Rule applies to.
+	AlertIDs []string `json:"alert_ids"`
+
+	// Query, if not empty, is a URL encoded query that every trace in the
+	// regression must match.
+	Query string `json:"query"`
+
+	// Direction, if not empty or alerts.BOTH, limits the Rule to only steps
+	// up or only steps down.
+	Direction alerts.Direction `json:"direction"`
+
+	// MinMagnitude and MaxMagnitude bound the absolute value of the
+	// StepFit.StepSize of the regression. A value of 0 means no bound.
+	MinMagnitude float32 `json:"min_magnitude"`
+	MaxMagnitude float32 `json:"max_magnitude"`
+
+	// AuthorRegex, if not empty, must match the author of the commit the
+	// regression was found at.
+	AuthorRegex string `json:"author_regex"`
+
+	// SubjectRegex, if not empty, must match the subject of the commit the
+	// regression was found at.
+	SubjectRegex string `json:"subject_regex"`
+
+	// Action to take on matching regressions.
+	Action ActionType `json:"action"`
+
+	// Bug is the bug to attach for the AttachBug action.
+	Bug string `json:"bug"`
+
+	// Priority is the priority, 0 to MaxPriority, to set for the BumpPriority
+	// action.
+	Priority int `json:"priority"`
+}
+
+// NewRule returns a new unsaved Rule.
+func NewRule() *Rule {
+	return &Rule{
+		ID:       BadRuleID,
+		Active:   true,
+		AlertIDs: []string{},
+		Action:   Ignore,
+	}
+}
+
+// Validate returns an error if the Rule isn't valid.
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return skerr.Fmt("A rule must have a name.")
+	}
+	if _, err := r.parseQuery(); err != nil {
+		return skerr.Wrapf(err, "Invalid query %q", r.Query)
+	}
+	if r.Direction != "" && !isValidDirection(r.Direction) {
+		return skerr.Fmt("Invalid direction %q.", r.Direction)
+	}
+	if r.MinMagnitude < 0 || r.MaxMagnitude < 0 {
+		return skerr.Fmt("Magnitudes must not be negative.")
+	}
+	if r.MaxMagnitude != 0 && r.MaxMagnitude < r.MinMagnitude {
+		return skerr.Fmt("MaxMagnitude must be larger than MinMagnitude.")
+	}
+	if _, err := regexp.Compile(r.AuthorRegex); err != nil {
+		return skerr.Wrapf(err, "Invalid author regex")
+	}
+	if _, err := regexp.Compile(r.SubjectRegex); err != nil {
+		return skerr.Wrapf(err, "Invalid subject regex")
+	}
+	switch r.Action {
+	case Ignore:
+	case AttachBug:
+		if r.Bug == "" {
+			return skerr.Fmt("A bug must be supplied for the %q action.", r.Action)
+		}
+	case BumpPriority:
+		if r.Priority < 0 || r.Priority > MaxPriority {
+			return skerr.Fmt("Priority must be between 0 and %d, got %d.", MaxPriority, r.Priority)
+		}
+	default:
+		return skerr.Fmt("Invalid action %q.", r.Action)
+	}
+	return nil
+}
+
+func isValidDirection(d alerts.Direction) bool {
+	for _, valid := range alerts.AllDirections {
+		if d == valid {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) parseQuery() (*query.Query, error) {
+	if r.Query == "" {
+		return nil, nil
+	}
+	values, err := url.ParseQuery(r.Query)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+	return query.New(values)
+}
+
+// Matches returns true if the Rule matches the regression described by the
+// alert, the commit it was found at, and its cluster.
+func (r *Rule) Matches(alert *alerts.Alert, commit provider.Commit, cl *clustering2.ClusterSummary) (bool, error) {
+	if !r.Active {
+		return false, nil
+	}
+	if len(r.AlertIDs) > 0 && !util.In(alert.IDAsString, r.AlertIDs) {
+		return false, nil
+	}
+
+	if cl.StepFit != nil {
+		switch r.Direction {
+		case alerts.UP:
+			if cl.StepFit.Status != stepfit.HIGH {
+				return false, nil
+			}
+		case alerts.DOWN:
+			if cl.StepFit.Status != stepfit.LOW {
+				return false, nil
+			}
+		}
+		magnitude := float32(math.Abs(float64(cl.StepFit.StepSize)))
+		if magnitude < r.MinMagnitude {
+			return false, nil
+		}
+		if r.MaxMagnitude != 0 && magnitude > r.MaxMagnitude {
+			return false, nil
+		}
+	}
+
+	q, err := r.parseQuery()
+	if err != nil {
+		return false, skerr.Wrapf(err, "Rule %d has an invalid query", r.ID)
+	}
+	if q != nil {
+		if len(cl.Keys) == 0 {
+			return false, nil
+		}
+		for _, key := range cl.Keys {
+			if !q.Matches(key) {
+				return false, nil
+			}
+		}
+	}
+
+	for _, re := range []struct {
+		expr  string
+		value string
+	}{
+		{expr: r.AuthorRegex, value: commit.Author},
+		{expr: r.SubjectRegex, value: commit.Subject},
+	} {
+		if re.expr == "" {
+			continue
+		}
+		matched, err := regexp.MatchString(re.expr, re.value)
+		if err != nil {
+			return false, skerr.Wrapf(err, "Rule %d has an invalid regex", r.ID)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// TriageStatus returns the TriageStatus to apply to a matching regression.
+func (r *Rule) TriageStatus() regression.TriageStatus {
+	switch r.Action {
+	case AttachBug:
+		return regression.TriageStatus{
+			Status:  regression.Negative,
+			Message: r.Bug,
+		}
+	case BumpPriority:
+		return regression.TriageStatus{
+			Status:  regression.Untriaged,
+			Message: fmt.Sprintf("Priority set to P%d by triage rule %q", r.Priority, r.Name),
+		}
+	default:
+		return regression.TriageStatus{
+			Status:  regression.Positive,
+			Message: fmt.Sprintf("Ignored by triage rule %q", r.Name),
+		}
+	}
+}
+
+// AuditEntry records a single application of a Rule to a regression.
+type AuditEntry struct {
+	ID           int64                  `json:"id"`
+	RuleID       int64                  `json:"rule_id"`
+	RuleName     string                 `json:"rule_name"`
+	AlertID      string                 `json:"alert_id"`
+	CommitNumber types.CommitNumber     `json:"commit_number"`
+	ClusterType  regression.ClusterType `json:"cluster_type"`
+	Action       ActionType             `json:"action"`
+	Message      string                 `json:"message"`
+	Timestamp    time.Time              `json:"timestamp"`
+}
+
+// Store persists Rules and the audit trail of their applications.
+type Store interface {
+	// Save the Rule. If the ID is BadRuleID then a new Rule is created and
+	// rule.ID is updated.
+	Save(ctx context.Context, rule *Rule) error
+
+	// Delete the Rule with the given ID. Deleted rules are kept so the audit
+	// trail can still refer to them, but they are never evaluated.
+	Delete(ctx context.Context, id int64) error
+
+	// List returns all the Rules sorted by ID, optionally including deleted
+	// Rules.
+	List(ctx context.Context, includeDeleted bool) ([]*Rule, error)
+
+	// RecordAudit adds an entry to the audit trail.
+	RecordAudit(ctx context.Context, entry *AuditEntry) error
+
+	// ListAudit returns the most recent entries in the audit trail, newest
+	// first.
+	ListAudit(ctx context.Context, limit int) ([]*AuditEntry, error)
+}
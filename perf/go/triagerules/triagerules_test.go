@@ -0,0 +1,135 @@
+package triagerules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.skia.org/infra/perf/go/alerts"
+	"go.skia.org/infra/perf/go/clustering2"
+	"go.skia.org/infra/perf/go/git/provider"
+	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/stepfit"
+)
+
+const alertID = "12"
+
+var commit = provider.Commit{
+	Author:  "autoroller@example.org",
+	Subject: "Roll third_party/foo from abc to def",
+}
+
+func testAlert() *alerts.Alert {
+	a := alerts.NewConfig()
+	a.IDAsString = alertID
+	return a
+}
+
+func testCluster() *clustering2.ClusterSummary {
+	return &clustering2.ClusterSummary{
+		Keys: []string{
+			",arch=x86,config=8888,",
+			",arch=x86,config=565,",
+		},
+		StepFit: &stepfit.StepFit{
+			StepSize: -2,
+			Status:   stepfit.LOW,
+		},
+	}
+}
+
+func testRule() *Rule {
+	r := NewRule()
+	r.Name = "my-rule"
+	return r
+}
+
+func TestValidate_DefaultRuleWithName_Success(t *testing.T) {
+	require.NoError(t, testRule().Validate())
+}
+
+func TestValidate_InvalidRules_ReturnError(t *testing.T) {
+	for name, modify := range map[string]func(r *Rule){
+		"no name":            func(r *Rule) { r.Name = "" },
+		"bad query":          func(r *Rule) { r.Query = "%gh&%ij" },
+		"bad direction":      func(r *Rule) { r.Direction = "SIDEWAYS" },
+		"negative magnitude": func(r *Rule) { r.MinMagnitude = -1 },
+		"max less than min":  func(r *Rule) { r.MinMagnitude, r.MaxMagnitude = 2, 1 },
+		"bad author regex":   func(r *Rule) { r.AuthorRegex = "(" },
+		"bad subject regex":  func(r *Rule) { r.SubjectRegex = "(" },
+		"bug action no bug":  func(r *Rule) { r.Action = AttachBug },
+		"priority too large": func(r *Rule) { r.Action, r.Priority = BumpPriority, 5 },
+		"priority negative":  func(r *Rule) { r.Action, r.Priority = BumpPriority, -1 },
+		"unknown action":     func(r *Rule) { r.Action = "delete" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := testRule()
+			modify(r)
+			require.Error(t, r.Validate())
+		})
+	}
+}
+
+func TestMatches_RuleWithNoCriteria_MatchesEverything(t *testing.T) {
+	matched, err := testRule().Matches(testAlert(), commit, testCluster())
+	require.NoError(t, err)
+	assert.True(t, matched)
+}
+
+func TestMatches_InactiveRule_DoesNotMatch(t *testing.T) {
+	r := testRule()
+	r.Active = false
+	matched, err := r.Matches(testAlert(), commit, testCluster())
+	require.NoError(t, err)
+	assert.False(t, matched)
+}
+
+func TestMatches_EachCriteria(t *testing.T) {
+	for name, test := range map[string]struct {
+		modify   func(r *Rule)
+		expected bool
+	}{
+		"alert id matches":               {func(r *Rule) { r.AlertIDs = []string{"1", alertID} }, true},
+		"alert id does not match":        {func(r *Rule) { r.AlertIDs = []string{"1"} }, false},
+		"query matches all traces":       {func(r *Rule) { r.Query = "arch=x86" }, true},
+		"query matches only some traces": {func(r *Rule) { r.Query = "config=8888" }, false},
+		"direction matches":              {func(r *Rule) { r.Direction = alerts.DOWN }, true},
+		"direction does not match":       {func(r *Rule) { r.Direction = alerts.UP }, false},
+		"both directions":                {func(r *Rule) { r.Direction = alerts.BOTH }, true},
+		"magnitude within bounds":        {func(r *Rule) { r.MinMagnitude, r.MaxMagnitude = 1, 3 }, true},
+		"magnitude too small":            {func(r *Rule) { r.MinMagnitude = 3 }, false},
+		"magnitude too large":            {func(r *Rule) { r.MaxMagnitude = 1 }, false},
+		"author matches":                 {func(r *Rule) { r.AuthorRegex = "^autoroller@" }, true},
+		"author does not match":          {func(r *Rule) { r.AuthorRegex = "^someone@" }, false},
+		"subject matches":                {func(r *Rule) { r.SubjectRegex = "^Roll " }, true},
+		"subject does not match":         {func(r *Rule) { r.SubjectRegex = "^Revert " }, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := testRule()
+			test.modify(r)
+			matched, err := r.Matches(testAlert(), commit, testCluster())
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, matched)
+		})
+	}
+}
+
+func TestMatches_InvalidRegex_ReturnsError(t *testing.T) {
+	r := testRule()
+	r.SubjectRegex = "("
+	_, err := r.Matches(testAlert(), commit, testCluster())
+	require.Error(t, err)
+}
+
+func TestTriageStatus_EachAction(t *testing.T) {
+	r := testRule()
+	assert.Equal(t, regression.Positive, r.TriageStatus().Status)
+
+	r.Action = AttachBug
+	r.Bug = "b/1234"
+	assert.Equal(t, regression.TriageStatus{Status: regression.Negative, Message: "b/1234"}, r.TriageStatus())
+
+	r.Action = BumpPriority
+	r.Priority = 1
+	assert.Equal(t, regression.TriageStatus{Status: regression.Untriaged, Message: `Priority set to P1 by triage rule "my-rule"`}, r.TriageStatus())
+}
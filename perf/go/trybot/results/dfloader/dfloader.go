@@ -4,6 +4,7 @@ package dfloader
 import (
 	"context"
 	"fmt"
+	"math"
 	"sort"
 	"time"
 
@@ -15,7 +16,10 @@ import (
 	"go.skia.org/infra/go/vec32"
 	"go.skia.org/infra/perf/go/dataframe"
 	perfgit "go.skia.org/infra/perf/go/git"
+	"go.skia.org/infra/perf/go/ingest/parser"
 	"go.skia.org/infra/perf/go/progress"
+	"go.skia.org/infra/perf/go/samplestats"
+	"go.skia.org/infra/perf/go/tracestore"
 	"go.skia.org/infra/perf/go/trybot/results"
 	"go.skia.org/infra/perf/go/trybot/store"
 	"go.skia.org/infra/perf/go/types"
@@ -29,17 +33,19 @@ var ErrQueryMustNotBeEmpty = fmt.Errorf("Query must not be empty.")
 
 // Loader implements results.Loader.
 type Loader struct {
-	dfb   dataframe.DataFrameBuilder
-	store store.TryBotStore
-	git   perfgit.Git
+	dfb        dataframe.DataFrameBuilder
+	store      store.TryBotStore
+	git        perfgit.Git
+	traceStore tracestore.TraceStore
 }
 
 // New returns a new Loader instance.
-func New(dfb dataframe.DataFrameBuilder, store store.TryBotStore, git perfgit.Git) Loader {
+func New(dfb dataframe.DataFrameBuilder, store store.TryBotStore, git perfgit.Git, traceStore tracestore.TraceStore) Loader {
 	return Loader{
-		dfb:   dfb,
-		store: store,
-		git:   git,
+		dfb:        dfb,
+		store:      store,
+		git:        git,
+		traceStore: traceStore,
 	}
 }
 
@@ -75,6 +81,11 @@ func (l Loader) Load(ctx context.Context, request results.TryBotRequest, progres
 	var df *dataframe.DataFrame
 	rebuildParamSet := false
 
+	// The samples of the trybot results, and of the base patch if one is
+	// given, keyed by trace name.
+	trybotSamples := map[string][]float64{}
+	baseSamples := map[string][]float64{}
+
 	// TODO(jcgregorio) What we really need for queries below is a new call into
 	// TraceStore that retrieves the last N commits along with their offsets
 	// ending at a given commit. Note that the N values can come from different
@@ -100,6 +111,16 @@ func (l Loader) Load(ctx context.Context, request results.TryBotRequest, progres
 		traceNames := make([]string, 0, len(storeResults))
 		for _, results := range storeResults {
 			traceNames = append(traceNames, results.TraceName)
+			trybotSamples[results.TraceName] = results.Samples
+		}
+		if request.BasePatchNumber != 0 {
+			baseResults, err := l.store.Get(ctx, request.CL, request.BasePatchNumber)
+			if err != nil {
+				return results.TryBotResponse{}, skerr.Wrap(err)
+			}
+			for _, results := range baseResults {
+				baseSamples[results.TraceName] = results.Samples
+			}
 		}
 		// Query for all traces that match up with the trybot results.
 		df, err = l.dfb.NewNFromKeys(ctx, timestamp, traceNames, TraceHistorySize+1, progress)
@@ -127,6 +148,11 @@ func (l Loader) Load(ctx context.Context, request results.TryBotRequest, progres
 	}
 	ret.ParamSet = df.ParamSet
 
+	significance, err := l.analyze(ctx, request, df.Header, df.TraceSet, trybotSamples, baseSamples)
+	if err != nil {
+		return results.TryBotResponse{}, skerr.Wrap(err)
+	}
+
 	res := make([]results.TryBotResult, 0, len(df.TraceSet))
 	// Loop over all the traces and parse the key into params and pass the
 	// values to vec32.StdDevRatio.
@@ -142,14 +168,20 @@ func (l Loader) Load(ctx context.Context, request results.TryBotRequest, progres
 			rebuildParamSet = true
 			continue
 		}
-		res = append(res, results.TryBotResult{
+		result := results.TryBotResult{
 			Params:      params,
 			Median:      median,
 			Lower:       lower,
 			Upper:       upper,
 			StdDevRatio: stddevRatio,
 			Values:      values,
-		})
+			P:           1,
+			Note:        "No samples to compare against.",
+		}
+		if row, ok := significance[traceName]; ok {
+			applySignificance(&result, request.Alpha, row)
+		}
+		res = append(res, result)
 	}
 
 	sort.Sort(sortableTryBotResults(res))
@@ -167,5 +199,134 @@ func (l Loader) Load(ctx context.Context, request results.TryBotRequest, progres
 	return ret, nil
 }
 
+// analyze runs samplestats.Analyze over every trace, comparing the samples
+// under inspection against the reference samples, and returns the rows of the
+// analysis keyed by trace name.
+//
+// If Kind is Commit then the samples under inspection are the ones stored for
+// the commit in the last column of the header, otherwise they are the trybot
+// samples. The reference samples are the samples of the base patch if Kind is
+// TryBot and BasePatchNumber is set, otherwise they are the ones stored for the
+// closest earlier commit that has a value in the trace, i.e. the tip-of-tree.
+// Traces without both sets of samples are left out.
+func (l Loader) analyze(ctx context.Context, request results.TryBotRequest, header []*dataframe.ColumnHeader, traceSet types.TraceSet, trybotSamples, baseSamples map[string][]float64) (map[string]samplestats.Row, error) {
+	compareToBase := request.Kind == results.TryBot && request.BasePatchNumber != 0
+
+	// Find the points of each trace whose samples need to be read from the
+	// trace store, so they can all be read at once.
+	traceNames := []string{}
+	inspectedPoints := map[string]tracestore.TracePoint{}
+	referencePoints := map[string]tracestore.TracePoint{}
+	points := []tracestore.TracePoint{}
+	for traceName, values := range traceSet {
+		last := len(values) - 1
+		if last < 1 || len(header) != len(values) || values[last] == vec32.MissingDataSentinel {
+			continue
+		}
+		if request.Kind == results.TryBot && len(trybotSamples[traceName]) == 0 {
+			continue
+		}
+		traceNames = append(traceNames, traceName)
+	}
+	sort.Strings(traceNames)
+	for _, traceName := range traceNames {
+		values := traceSet[traceName]
+		last := len(values) - 1
+		if request.Kind == results.Commit {
+			inspectedPoints[traceName] = tracestore.TracePoint{TraceName: traceName, CommitNumber: header[last].Offset}
+			points = append(points, inspectedPoints[traceName])
+		}
+		if compareToBase {
+			continue
+		}
+		prev := last - 1
+		for prev >= 0 && values[prev] == vec32.MissingDataSentinel {
+			prev--
+		}
+		if prev >= 0 {
+			referencePoints[traceName] = tracestore.TracePoint{TraceName: traceName, CommitNumber: header[prev].Offset}
+			points = append(points, referencePoints[traceName])
+		}
+	}
+	stored, err := l.readSamples(ctx, points)
+	if err != nil {
+		return nil, skerr.Wrap(err)
+	}
+
+	before := map[string]parser.Samples{}
+	after := map[string]parser.Samples{}
+	for _, traceName := range traceNames {
+		inspected := trybotSamples[traceName]
+		if request.Kind == results.Commit {
+			inspected = stored[inspectedPoints[traceName]]
+		}
+		reference := baseSamples[traceName]
+		if !compareToBase {
+			point, ok := referencePoints[traceName]
+			if !ok {
+				continue
+			}
+			reference = stored[point]
+		}
+		if len(inspected) == 0 || len(reference) == 0 {
+			continue
+		}
+		before[traceName] = parser.Samples{Values: reference}
+		after[traceName] = parser.Samples{Values: inspected}
+	}
+
+	analysis := samplestats.Analyze(samplestats.Config{
+		Alpha: request.Alpha,
+		All:   true,
+		Test:  request.Test,
+	}, before, after)
+	ret := make(map[string]samplestats.Row, len(analysis.Rows))
+	for _, row := range analysis.Rows {
+		ret[row.Name] = row
+	}
+	return ret, nil
+}
+
+// readSamples returns the samples stored for the given points, keyed by point,
+// reading them all with a single call to the trace store.
+func (l Loader) readSamples(ctx context.Context, points []tracestore.TracePoint) (map[tracestore.TracePoint][]float64, error) {
+	ret := map[tracestore.TracePoint][]float64{}
+	if len(points) == 0 {
+		return ret, nil
+	}
+	stored, err := l.traceStore.ReadSamples(ctx, points)
+	if err != nil {
+		return nil, skerr.Wrapf(err, "Failed to read samples")
+	}
+	for point, samples := range stored {
+		values := make([]float64, len(samples))
+		for i, v := range samples {
+			values[i] = float64(v)
+		}
+		ret[point] = values
+	}
+	return ret, nil
+}
+
+// applySignificance copies the p-value, delta and confidence interval for the
+// given row into result.
+func applySignificance(result *results.TryBotResult, alpha float64, row samplestats.Row) {
+	result.P = row.P
+	result.Note = row.Note
+	if !math.IsNaN(row.Delta) && !math.IsInf(row.Delta, 0) {
+		result.Significant = true
+		result.Delta = row.Delta
+	}
+	lower, upper, err := samplestats.ConfidenceInterval(alpha, row)
+	if err != nil {
+		if result.Note == "" {
+			result.Note = err.Error()
+		}
+		return
+	}
+	result.ConfidenceLower = lower
+	result.ConfidenceUpper = upper
+}
+
 // Assert that we fulfill the interface.
 var _ results.Loader = (*Loader)(nil)
@@ -10,11 +10,14 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.skia.org/infra/go/paramtools"
+	"go.skia.org/infra/go/testutils"
 	"go.skia.org/infra/go/vec32"
 	"go.skia.org/infra/perf/go/dataframe"
 	"go.skia.org/infra/perf/go/dataframe/mocks"
 	perfgit "go.skia.org/infra/perf/go/git"
 	"go.skia.org/infra/perf/go/git/gittest"
+	"go.skia.org/infra/perf/go/tracestore"
+	traceStoreMocks "go.skia.org/infra/perf/go/tracestore/mocks"
 	"go.skia.org/infra/perf/go/trybot/results"
 	"go.skia.org/infra/perf/go/trybot/store"
 	storeMocks "go.skia.org/infra/perf/go/trybot/store/mocks"
@@ -40,7 +43,7 @@ func TestLoader_UnknownCommit_ReturnsError(t *testing.T) {
 
 	dfb := &mocks.DataFrameBuilder{}
 	storeMock := &storeMocks.TryBotStore{}
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:         results.Commit,
 		Query:        "config=8888",
@@ -56,7 +59,7 @@ func TestLoader_InvalidQuery_ReturnsError(t *testing.T) {
 
 	dfb := &mocks.DataFrameBuilder{}
 	storeMock := &storeMocks.TryBotStore{}
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:         results.Commit,
 		CommitNumber: 2, // Valid commit that gittest.NewForTest has added.
@@ -72,7 +75,7 @@ func TestLoader_EmptyQuery_LoadReturnsError(t *testing.T) {
 
 	dfb := &mocks.DataFrameBuilder{}
 	storeMock := &storeMocks.TryBotStore{}
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:         results.Commit,
 		Query:        "",
@@ -90,7 +93,7 @@ func TestLoader_LoadWithDataFrameBuilderThatErrorsNewNFromQuery_LoadReturnsError
 	dfb.On("NewNFromQuery", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errFromMock)
 
 	storeMock := &storeMocks.TryBotStore{}
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:         results.Commit,
 		Query:        "config=8888",
@@ -110,7 +113,7 @@ func TestLoader_LoadWithTryBotStoreThatErrors_LoadReturnsError(t *testing.T) {
 	const patch = int(1)
 	storeMock.On("Get", mock.Anything, cl, patch).Return(nil, errFromMock)
 
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:        results.TryBot,
 		CL:          cl,
@@ -132,7 +135,7 @@ func TestLoader_LoadDataFrameBuilderThatErrorsNewNFromKeys_LoadReturnsError(t *t
 	const patch = int(1)
 	storeMock.On("Get", mock.Anything, cl, patch).Return(nil, nil)
 
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:        results.TryBot,
 		CL:          cl,
@@ -158,7 +161,7 @@ func TestLoader_ZeroLengthResponseFromTryBotStore_LoadReturnsSuccess(t *testing.
 	const patch = int(1)
 	storeMock.On("Get", mock.Anything, cl, patch).Return(nil, nil)
 
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:        results.TryBot,
 		CL:          cl,
@@ -206,7 +209,7 @@ func TestLoader_OneTraceTryBotHappyPath_LoadReturnsSuccess(t *testing.T) {
 	}
 	storeMock.On("Get", mock.Anything, cl, patch).Return(storeResults, nil)
 
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:        results.TryBot,
 		CL:          cl,
@@ -221,6 +224,8 @@ func TestLoader_OneTraceTryBotHappyPath_LoadReturnsSuccess(t *testing.T) {
 		Upper:       0.122474514,
 		StdDevRatio: 16.329927,
 		Values:      []float32{1, 1, 0.9, 0.9, 1.1, 1.1, 0.8, 0.8, 1.2, 3},
+		P:           1,
+		Note:        "No samples to compare against.",
 	}
 	assert.Len(t, resp.Results, 1)
 	assert.Equal(t, expected, resp.Results[0])
@@ -266,7 +271,7 @@ func TestLoader_TwoTraces_LoadReturnsSuccessAndResultsAreSorted(t *testing.T) {
 	}
 	storeMock.On("Get", mock.Anything, cl, patch).Return(storeResults, nil)
 
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:        results.TryBot,
 		CL:          cl,
@@ -283,6 +288,8 @@ func TestLoader_TwoTraces_LoadReturnsSuccessAndResultsAreSorted(t *testing.T) {
 			Upper:       1,
 			StdDevRatio: 5,
 			Values:      []float32{1, 1, 1, 1, 2, 2, 2, 6},
+			P:           1,
+			Note:        "No samples to compare against.",
 		},
 		{
 			Params:      paramtools.Params{"config": "cpu"},
@@ -291,6 +298,8 @@ func TestLoader_TwoTraces_LoadReturnsSuccessAndResultsAreSorted(t *testing.T) {
 			Upper:       1,
 			StdDevRatio: 3,
 			Values:      []float32{1, 1, 1, 1, 2, 2, 2, 4},
+			P:           1,
+			Note:        "No samples to compare against.",
 		},
 	}
 	assert.Equal(t, expected, resp.Results)
@@ -339,7 +348,7 @@ func TestLoader_UnknownTracesAreIgnored_LoadReturnsSuccess(t *testing.T) {
 	}
 	storeMock.On("Get", mock.Anything, cl, patch).Return(storeResults, nil)
 
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:        results.TryBot,
 		CL:          cl,
@@ -354,6 +363,8 @@ func TestLoader_UnknownTracesAreIgnored_LoadReturnsSuccess(t *testing.T) {
 		Upper:       0.122474514,
 		StdDevRatio: 16.329927,
 		Values:      []float32{1, 1, 0.9, 0.9, 1.1, 1.1, 0.8, 0.8, 1.2, 3},
+		P:           1,
+		Note:        "No samples to compare against.",
 	}
 	assert.Len(t, resp.Results, 1)
 	assert.Equal(t, expected, resp.Results[0])
@@ -402,7 +413,7 @@ func TestLoader_InsufficientNonMissingDataSentinel_ResultIsSkipped(t *testing.T)
 	}
 	storeMock.On("Get", mock.Anything, cl, patch).Return(storeResults, nil)
 
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:        results.TryBot,
 		CL:          cl,
@@ -417,6 +428,8 @@ func TestLoader_InsufficientNonMissingDataSentinel_ResultIsSkipped(t *testing.T)
 		Upper:       0.122474514,
 		StdDevRatio: 16.329927,
 		Values:      []float32{1, 1, 0.9, 0.9, 1.1, 1.1, 0.8, 0.8, 1.2, 3},
+		P:           1,
+		Note:        "No samples to compare against.",
 	}
 	assert.Len(t, resp.Results, 1)
 	assert.Equal(t, expected, resp.Results[0])
@@ -448,7 +461,7 @@ func TestLoader_InvalidTraceKeysAreIgnored_LoadReturnsSuccess(t *testing.T) {
 	}
 	storeMock.On("Get", mock.Anything, cl, patch).Return(storeResults, nil)
 
-	loader := New(dfb, storeMock, g)
+	loader := New(dfb, storeMock, g, nil)
 	request := results.TryBotRequest{
 		Kind:        results.TryBot,
 		CL:          cl,
@@ -460,3 +473,194 @@ func TestLoader_InvalidTraceKeysAreIgnored_LoadReturnsSuccess(t *testing.T) {
 	assert.Empty(t, resp.Header)
 	assert.Empty(t, resp.ParamSet)
 }
+
+func newTenPointDataFrame() *dataframe.DataFrame {
+	header := []*dataframe.ColumnHeader{}
+	for i := 0; i < 10; i++ {
+		header = append(header, &dataframe.ColumnHeader{Offset: types.CommitNumber(i), Timestamp: dataframe.TimestampSeconds(gittest.StartTime.Unix() + int64(i))})
+	}
+	return &dataframe.DataFrame{
+		Header:   header,
+		ParamSet: paramtools.ReadOnlyParamSet{"config": []string{"gpu"}},
+		TraceSet: types.TraceSet{
+			",config=gpu,": []float32{1, 1, 0.9, 0.9, 1.1, 1.1, 0.8, 0.8, e, 3},
+		},
+	}
+}
+
+func newCommitRequest() results.TryBotRequest {
+	return results.TryBotRequest{
+		Kind:         results.Commit,
+		Query:        "config=gpu",
+		CommitNumber: 2, // Valid commit that gittest.NewForTest has added.
+	}
+}
+
+func TestLoader_CommitSamplesComparedToPreviousCommitSamples_ResultIsSignificant(t *testing.T) {
+	ctx, g, _ := setupForTest(t)
+
+	dfb := &mocks.DataFrameBuilder{}
+	dfb.On("NewNFromQuery", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(newTenPointDataFrame(), nil)
+
+	// Commit 8 has no value, so commit 9 is compared to commit 7.
+	traceStore := &traceStoreMocks.TraceStore{}
+	traceStore.On("ReadSamples", testutils.AnyContext, []tracestore.TracePoint{
+		{TraceName: ",config=gpu,", CommitNumber: 9},
+		{TraceName: ",config=gpu,", CommitNumber: 7},
+	}).Return(map[tracestore.TracePoint][]float32{
+		{TraceName: ",config=gpu,", CommitNumber: 9}: {3, 3.1, 2.9, 3.2},
+		{TraceName: ",config=gpu,", CommitNumber: 7}: {1, 1.1, 0.9, 1.2},
+	}, nil)
+
+	loader := New(dfb, &storeMocks.TryBotStore{}, g, traceStore)
+	resp, err := loader.Load(ctx, newCommitRequest(), nil)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	res := resp.Results[0]
+	assert.InDelta(t, 0.0285714, res.P, 1e-6)
+	assert.True(t, res.Significant)
+	assert.InDelta(t, 190.48, res.Delta, 0.01)
+	assert.InDelta(t, 1.7766, res.ConfidenceLower, 1e-4)
+	assert.InDelta(t, 2.2234, res.ConfidenceUpper, 1e-4)
+	assert.Empty(t, res.Note)
+}
+
+func TestLoader_CommitHasNoSamples_ResultHasNoSignificance(t *testing.T) {
+	ctx, g, _ := setupForTest(t)
+
+	dfb := &mocks.DataFrameBuilder{}
+	dfb.On("NewNFromQuery", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(newTenPointDataFrame(), nil)
+
+	traceStore := &traceStoreMocks.TraceStore{}
+	traceStore.On("ReadSamples", testutils.AnyContext, mock.Anything).Return(map[tracestore.TracePoint][]float32{
+		{TraceName: ",config=gpu,", CommitNumber: 7}: {1, 1.1, 0.9, 1.2},
+	}, nil)
+
+	loader := New(dfb, &storeMocks.TryBotStore{}, g, traceStore)
+	resp, err := loader.Load(ctx, newCommitRequest(), nil)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, 1.0, resp.Results[0].P)
+	assert.False(t, resp.Results[0].Significant)
+	assert.Equal(t, "No samples to compare against.", resp.Results[0].Note)
+}
+
+func TestLoader_TraceStoreErrorsOnReadSamples_LoadReturnsError(t *testing.T) {
+	ctx, g, _ := setupForTest(t)
+
+	dfb := &mocks.DataFrameBuilder{}
+	dfb.On("NewNFromQuery", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(newTenPointDataFrame(), nil)
+
+	traceStore := &traceStoreMocks.TraceStore{}
+	traceStore.On("ReadSamples", testutils.AnyContext, mock.Anything).Return(nil, errFromMock)
+
+	loader := New(dfb, &storeMocks.TryBotStore{}, g, traceStore)
+	_, err := loader.Load(ctx, newCommitRequest(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), errFromMock.Error())
+}
+
+const (
+	testCL    = types.CL("123456")
+	testPatch = 2
+)
+
+func newTryBotRequest() results.TryBotRequest {
+	return results.TryBotRequest{
+		Kind:        results.TryBot,
+		CL:          testCL,
+		PatchNumber: testPatch,
+	}
+}
+
+func TestLoader_TryBotSamplesComparedToTipOfTreeSamples_ResultIsSignificant(t *testing.T) {
+	dfb := &mocks.DataFrameBuilder{}
+	dfb.On("NewNFromKeys", mock.Anything, mock.Anything, []string{",config=gpu,"}, mock.Anything, mock.Anything).Return(newTenPointDataFrame(), nil)
+
+	storeMock := &storeMocks.TryBotStore{}
+	storeMock.On("Get", testutils.AnyContext, testCL, testPatch).Return([]store.GetResult{
+		{TraceName: ",config=gpu,", Value: 3, Samples: []float64{3, 3.1, 2.9, 3.2}},
+	}, nil)
+
+	// Commit 8 has no value, so the trybot samples are compared to commit 7.
+	traceStore := &traceStoreMocks.TraceStore{}
+	traceStore.On("ReadSamples", testutils.AnyContext, []tracestore.TracePoint{
+		{TraceName: ",config=gpu,", CommitNumber: 7},
+	}).Return(map[tracestore.TracePoint][]float32{
+		{TraceName: ",config=gpu,", CommitNumber: 7}: {1, 1.1, 0.9, 1.2},
+	}, nil)
+
+	loader := New(dfb, storeMock, nil, traceStore)
+	resp, err := loader.Load(context.Background(), newTryBotRequest(), nil)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	res := resp.Results[0]
+	assert.InDelta(t, 0.0285714, res.P, 1e-6)
+	assert.True(t, res.Significant)
+	assert.InDelta(t, 190.48, res.Delta, 0.01)
+	assert.InDelta(t, 1.7766, res.ConfidenceLower, 1e-4)
+	assert.InDelta(t, 2.2234, res.ConfidenceUpper, 1e-4)
+	assert.Empty(t, res.Note)
+	traceStore.AssertExpectations(t)
+}
+
+func TestLoader_TryBotSamplesComparedToBasePatchSamples_ResultIsSignificant(t *testing.T) {
+	dfb := &mocks.DataFrameBuilder{}
+	dfb.On("NewNFromKeys", mock.Anything, mock.Anything, []string{",config=gpu,"}, mock.Anything, mock.Anything).Return(newTenPointDataFrame(), nil)
+
+	storeMock := &storeMocks.TryBotStore{}
+	storeMock.On("Get", testutils.AnyContext, testCL, testPatch).Return([]store.GetResult{
+		{TraceName: ",config=gpu,", Value: 3, Samples: []float64{3, 3.1, 2.9, 3.2}},
+	}, nil)
+	storeMock.On("Get", testutils.AnyContext, testCL, 1).Return([]store.GetResult{
+		{TraceName: ",config=gpu,", Value: 1, Samples: []float64{1, 1.1, 0.9, 1.2}},
+	}, nil)
+
+	// The trace store isn't needed when comparing two patches.
+	request := newTryBotRequest()
+	request.BasePatchNumber = 1
+	loader := New(dfb, storeMock, nil, nil)
+	resp, err := loader.Load(context.Background(), request, nil)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	res := resp.Results[0]
+	assert.InDelta(t, 0.0285714, res.P, 1e-6)
+	assert.True(t, res.Significant)
+	assert.InDelta(t, 190.48, res.Delta, 0.01)
+	assert.Empty(t, res.Note)
+}
+
+func TestLoader_BasePatchHasNoResultForTrace_ResultHasNoSignificance(t *testing.T) {
+	dfb := &mocks.DataFrameBuilder{}
+	dfb.On("NewNFromKeys", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(newTenPointDataFrame(), nil)
+
+	storeMock := &storeMocks.TryBotStore{}
+	storeMock.On("Get", testutils.AnyContext, testCL, testPatch).Return([]store.GetResult{
+		{TraceName: ",config=gpu,", Value: 3, Samples: []float64{3, 3.1, 2.9, 3.2}},
+	}, nil)
+	storeMock.On("Get", testutils.AnyContext, testCL, 1).Return([]store.GetResult{}, nil)
+
+	request := newTryBotRequest()
+	request.BasePatchNumber = 1
+	loader := New(dfb, storeMock, nil, nil)
+	resp, err := loader.Load(context.Background(), request, nil)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, 1.0, resp.Results[0].P)
+	assert.False(t, resp.Results[0].Significant)
+	assert.Equal(t, "No samples to compare against.", resp.Results[0].Note)
+}
+
+func TestLoader_TryBotStoreErrorsOnBasePatch_LoadReturnsError(t *testing.T) {
+	dfb := &mocks.DataFrameBuilder{}
+	storeMock := &storeMocks.TryBotStore{}
+	storeMock.On("Get", testutils.AnyContext, testCL, testPatch).Return([]store.GetResult{}, nil)
+	storeMock.On("Get", testutils.AnyContext, testCL, 1).Return(nil, errFromMock)
+
+	request := newTryBotRequest()
+	request.BasePatchNumber = 1
+	loader := New(dfb, storeMock, nil, nil)
+	_, err := loader.Load(context.Background(), request, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), errFromMock.Error())
+}
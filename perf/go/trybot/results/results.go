@@ -10,6 +10,7 @@ import (
 	"go.skia.org/infra/go/paramtools"
 	"go.skia.org/infra/perf/go/dataframe"
 	"go.skia.org/infra/perf/go/progress"
+	"go.skia.org/infra/perf/go/samplestats"
 	"go.skia.org/infra/perf/go/types"
 )
 
@@ -46,6 +47,19 @@ type TryBotRequest struct {
 
 	// Query is a query to select the set of traces to analys. Only used if Kind is Commit.
 	Query string `json:"query"`
+
+	// BasePatchNumber, if not zero, is another patch of the same CL that the
+	// samples of PatchNumber are compared against, instead of against the
+	// tip-of-tree samples of each trace. Only used if Kind is TryBot.
+	BasePatchNumber int `json:"base_patch_number"`
+
+	// Test is the statistical test used to compare the samples. Defaults to
+	// the Mann-Whitney U test.
+	Test samplestats.Test `json:"test"`
+
+	// Alpha is the p-value cutoff to report a change as significant. Defaults
+	// to 0.05.
+	Alpha float64 `json:"alpha"`
 }
 
 // TryBotResult of the analysis for a single trace id.
@@ -63,6 +77,31 @@ type TryBotResult struct {
 	// commit N+1, or a trybot result, depending on the RequestKind sent in
 	// TryBotRequest.
 	Values []float32 `json:"values"`
+
+	// P is the p-value of the test that the samples under inspection, which
+	// are the trybot samples or the samples of the commit, come from the same
+	// population as the reference samples, which are the samples of the base
+	// patch or the samples of the previous commit of the trace. Only
+	// calculated if both sets of samples are available, otherwise P is 1 and
+	// Note says why.
+	P float64 `json:"p"`
+
+	// Significant is true if P is below the requested alpha.
+	Significant bool `json:"significant"`
+
+	// Delta is the change in mean from the reference samples, as a percent.
+	// Only set if Significant is true.
+	Delta float64 `json:"delta"`
+
+	// ConfidenceLower and ConfidenceUpper bound the confidence interval, at
+	// the 1-alpha level, for the difference in means of the samples under
+	// inspection and the reference samples. Both are zero if the interval
+	// could not be calculated, in which case Note says why.
+	ConfidenceLower float64 `json:"confidence_lower"`
+	ConfidenceUpper float64 `json:"confidence_upper"`
+
+	// Note of any issues that arose when comparing the samples.
+	Note string `json:"note"`
 }
 
 // TryBotResponse is the response sent to a TryBotRequest.
@@ -33,4 +33,8 @@ type ListResult struct {
 type GetResult struct {
 	TraceName string
 	Value     float32
+
+	// Samples are the individual measurements that Value summarizes, if the
+	// trybot reported them.
+	Samples []float64
 }
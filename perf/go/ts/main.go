@@ -26,6 +26,7 @@ import (
 	"go.skia.org/infra/perf/go/pivot"
 	"go.skia.org/infra/perf/go/progress"
 	"go.skia.org/infra/perf/go/regression"
+	"go.skia.org/infra/perf/go/samplestats"
 	"go.skia.org/infra/perf/go/stepfit"
 	subProto "go.skia.org/infra/perf/go/subscription/proto/v1"
 	"go.skia.org/infra/perf/go/trybot/results"
@@ -114,6 +115,7 @@ func main() {
 		{types.AllClusterAlgos, "ClusterAlgo"},
 		{types.AllStepDetections, "StepDetection"},
 		{results.AllRequestKind, "TryBotRequestKind"},
+		{samplestats.AllTests, "TryBotTest"},
 		{frame.AllResponseDisplayModes, "FrameResponseDisplayMode"},
 		{notifytypes.AllNotifierTypes, "NotifierTypes"},
 		{config.AllTraceFormats, "TraceFormat"},
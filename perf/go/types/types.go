@@ -113,6 +113,23 @@ const (
 
 	// MannWhitneyU uses the Mann-Whitney U test to detect a change. https://en.wikipedia.org/wiki/Mann%E2%80%93Whitney_U_test
 	MannWhitneyU StepDetection = "mannwhitneyu"
+
+	// PELTStep finds all the change points in the trace using the Pruned Exact
+	// Linear Time algorithm and detects a change if one of them is at the
+	// commit of interest. The size of the change is measured in standard
+	// deviations of the noise in the trace. https://arxiv.org/abs/1101.1438
+	PELTStep StepDetection = "pelt"
+
+	// BOCPDStep uses Bayesian Online Change Point Detection to calculate the
+	// probability that a change occurred at the commit of interest.
+	// https://arxiv.org/abs/0710.3742
+	BOCPDStep StepDetection = "bocpd"
+
+	// NoiseStep detects a change if the step size is larger than some
+	// multiple of the trace's own noise, as estimated over the most recent
+	// tiles of the trace. If no estimate is available the noise is estimated
+	// from the trace being analyzed.
+	NoiseStep StepDetection = "noise"
 )
 
 var (
@@ -130,6 +147,9 @@ var (
 		PercentStep,
 		CohenStep,
 		MannWhitneyU,
+		PELTStep,
+		BOCPDStep,
+		NoiseStep,
 	}
 )
 
@@ -132,17 +132,7 @@ type frameRequestProcess struct {
 //
 // The finished results are stored in the FrameRequestProcess.Progress.Results.
 func ProcessFrameRequest(ctx context.Context, req *FrameRequest, perfGit perfgit.Git, dfBuilder dataframe.DataFrameBuilder, shortcutStore shortcut.Store, anomalyStore anomalies.Store, searchAnomaliesTimeBased bool) error {
-	numKeys := 0
-	if req.Keys != "" {
-		numKeys = 1
-	}
-	ret := &frameRequestProcess{
-		perfGit:       perfGit,
-		request:       req,
-		totalSearches: len(req.Formulas) + len(req.Queries) + numKeys,
-		dfBuilder:     dfBuilder,
-		shortcutStore: shortcutStore,
-	}
+	ret := newFrameRequestProcess(req, perfGit, dfBuilder, shortcutStore)
 	df, err := ret.run(ctx)
 	if err != nil {
 		return skerr.Wrap(err)
@@ -166,6 +156,28 @@ func ProcessFrameRequest(ctx context.Context, req *FrameRequest, perfGit perfgit
 
 }
 
+// DataFrameFromFrameRequest returns the DataFrame for the FrameRequest,
+// including any pivot, without building a full FrameResponse.
+//
+// It does not return until all the work is complete.
+func DataFrameFromFrameRequest(ctx context.Context, req *FrameRequest, perfGit perfgit.Git, dfBuilder dataframe.DataFrameBuilder, shortcutStore shortcut.Store) (*dataframe.DataFrame, error) {
+	return newFrameRequestProcess(req, perfGit, dfBuilder, shortcutStore).run(ctx)
+}
+
+func newFrameRequestProcess(req *FrameRequest, perfGit perfgit.Git, dfBuilder dataframe.DataFrameBuilder, shortcutStore shortcut.Store) *frameRequestProcess {
+	numKeys := 0
+	if req.Keys != "" {
+		numKeys = 1
+	}
+	return &frameRequestProcess{
+		perfGit:       perfGit,
+		request:       req,
+		totalSearches: len(req.Formulas) + len(req.Queries) + numKeys,
+		dfBuilder:     dfBuilder,
+		shortcutStore: shortcutStore,
+	}
+}
+
 // reportError records the reason a FrameRequestProcess failed.
 func (p *frameRequestProcess) reportError(err error, message string) error {
 	sklog.Errorf("FrameRequest failed: %#v %s: %s", *(p.request), message, err)
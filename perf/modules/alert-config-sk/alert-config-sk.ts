@@ -100,6 +100,25 @@ const thresholdDescriptors: Record<StepDetection, ThresholdDescriptor> = {
     units: 'alpha (α)',
     label: 'Consider change significant if p < α. A typical value is 0.05.',
   },
+  pelt: {
+    units: 'standard deviations',
+    label: `Consider change significant if a change point is found at the
+        commit and the mean has changed by this many standard deviations of
+        the noise. Other change points in the trace are ignored.
+        Values from 2.0 to 3.0 work well.`,
+  },
+  bocpd: {
+    units: 'probability',
+    label: `Consider change significant if the probability of a change point
+        at the commit is greater than this. A typical value is 0.5.`,
+  },
+  noise: {
+    units: 'noise multiples',
+    label: `Consider change significant if the step is larger than this many
+        times the noise of the trace, as measured over its recent history, so
+        noisy traces need a larger step than quiet ones. Values from 3.0 to
+        5.0 work well.`,
+  },
 };
 
 export class AlertConfigSk extends ElementSk {
@@ -209,6 +228,9 @@ export class AlertConfigSk extends ElementSk {
       <div value="percent">Percent</div>
       <div value="cohen">Cohen's d</div>
       <div value="mannwhitneyu">Mann-Whitney U (Wilcoxon rank-sum)</div>
+      <div value="pelt">PELT (multiple change points)</div>
+      <div value="bocpd">Bayesian online change point detection</div>
+      <div value="noise">Multiples of the trace's noise</div>
     </select-sk>
     <h4>Threshold</h4>
     <label for="threshold">
@@ -353,6 +375,24 @@ export class AlertConfigSk extends ElementSk {
           <spinner-sk id="alertSpinner"></spinner-sk>
         `
       : html``}
+    ${window.perf.notifications === 'slack_webhook' ||
+    window.perf.notifications === 'webhook'
+      ? html`
+          <h3>Where are alerts sent</h3>
+          <label for="channel">
+            Channel, e.g. #perf-alerts. Leave empty to use the default channel.
+          </label>
+          <input
+            id="channel"
+            .value=${ele._config.webhook_channel || ''}
+            @input=${(e: InputEvent) =>
+              (ele._config.webhook_channel = (
+                e.target! as HTMLInputElement
+              ).value)} />
+          <button @click=${ele.testAlert}>Test</button>
+          <spinner-sk id="alertSpinner"></spinner-sk>
+        `
+      : html``}
     <!-- No bug template if alerts are already going to the issue tracker. -->
     ${window.perf.notifications === 'markdown_issuetracker'
       ? html``
@@ -370,6 +410,17 @@ export class AlertConfigSk extends ElementSk {
           <button @click=${ele.testBugTemplate}>Test</button>
           <spinner-sk id="bugSpinner"></spinner-sk> `}
 
+    <h3>Which files are likely culprits</h3>
+    <label for="culprit-paths">
+      Comma separated path globs, e.g. src/core/**. Commits that touch them
+      are listed in notifications.
+    </label>
+    <input
+      id="culprit-paths"
+      .value=${ele._config.culprit_paths || ''}
+      @input=${(e: InputEvent) =>
+        (ele._config.culprit_paths = (e.target! as HTMLInputElement).value)} />
+
     <h3>Who owns this alert</h3>
     <label for="owner">Email address of owner.</label>
     <input
@@ -149,6 +149,30 @@ const labelsForStepDetection: Record<StepDetection, LabelsAndFormatters> = {
     lse: 'U:',
     lseFormatter: decimalFormatter,
   },
+  pelt: {
+    regression: 'Standard Deviations:',
+    regressionFormatter: decimalFormatter,
+    stepSize: '',
+    stepSizeFormatter: emptyFormatter,
+    lse: '',
+    lseFormatter: emptyFormatter,
+  },
+  bocpd: {
+    regression: 'Probability:',
+    regressionFormatter: percentFormatter,
+    stepSize: 'Step Size:',
+    stepSizeFormatter: decimalFormatter,
+    lse: '',
+    lseFormatter: emptyFormatter,
+  },
+  noise: {
+    regression: 'Noise Multiples:',
+    regressionFormatter: decimalFormatter,
+    stepSize: 'Step Size:',
+    stepSizeFormatter: decimalFormatter,
+    lse: '',
+    lseFormatter: emptyFormatter,
+  },
 };
 
 export interface ClusterSummary2SkTriagedEventDetail {
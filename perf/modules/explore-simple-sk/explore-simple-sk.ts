@@ -1545,7 +1545,17 @@ export class ExploreSimpleSk extends ElementSk {
         this.commits!.details = json.commitSlice || [];
         this.commitsTab!.disabled = false;
         this.simpleParamset!.paramsets = [paramset as CommonSkParamSet];
-        this.logEntry!.innerHTML = escapeAndLinkifyToString(json.logEntry);
+        let logEntry = json.logEntry;
+        (json.dependencyRolls || []).forEach((roll) => {
+          logEntry += `\n\nRolled in ${roll.name} commits:\n`;
+          (roll.commits || []).forEach((c) => {
+            logEntry += `\n${c.hash.slice(0, 8)} ${c.message}\n  ${c.url}`;
+          });
+          if (roll.truncated) {
+            logEntry += '\n\nOlder commits are not shown.';
+          }
+        });
+        this.logEntry!.innerHTML = escapeAndLinkifyToString(logEntry);
         this.anomalyTable!.anomaly = selected_anomaly;
         this.anomalyTable!.bugHostUrl = window.perf.bug_host_url;
         this.detailTab!.selected = COMMIT_TAB_INDEX;
@@ -5,6 +5,8 @@ export namespace pivot {
 		group_by: string[] | null;
 		operation: pivot.Operation;
 		summary: pivot.Operation[] | null;
+		subtotals?: boolean;
+		baseline?: Params;
 	}
 }
 
@@ -32,6 +34,8 @@ export interface Alert {
 	sparse: boolean;
 	minimum_num: number;
 	category: string;
+	webhook_channel?: string;
+	culprit_paths?: string;
 	action?: AlertAction;
 	sub_name?: string;
 	sub_revision?: string;
@@ -200,9 +204,18 @@ export interface AlertUpdateResponse {
 	IDAsString: string;
 }
 
+export interface DependencyRoll {
+	name: string;
+	begin: string;
+	end: string;
+	commits: Commit[] | null;
+	truncated: boolean;
+}
+
 export interface CIDHandlerResponse {
 	commitSlice: Commit[] | null;
 	logEntry: string;
+	dependencyRolls: DependencyRoll[] | null;
 }
 
 export interface ClusterStartResponse {
@@ -378,6 +391,9 @@ export interface TryBotRequest {
 	patch_number: number;
 	commit_number: CommitNumber;
 	query: string;
+	base_patch_number: number;
+	test: TryBotTest;
+	alpha: number;
 }
 
 export interface TryBotResult {
@@ -387,6 +403,12 @@ export interface TryBotResult {
 	upper: number;
 	stddevRatio: number;
 	values: number[] | null;
+	p: number;
+	significant: boolean;
+	delta: number;
+	confidence_lower: number;
+	confidence_upper: number;
+	note: string;
 }
 
 export interface TryBotResponse {
@@ -520,7 +542,7 @@ export function TraceSet(v: { [key: string]: Trace }): TraceSet {
 	return v as TraceSet;
 };
 
-export namespace pivot { export type Operation = 'sum' | 'avg' | 'geo' | 'std' | 'count' | 'min' | 'max'; }
+export namespace pivot { export type Operation = 'sum' | 'avg' | 'geo' | 'std' | 'count' | 'min' | 'max' | 'p50' | 'p90' | 'p99' | 'trimmed_mean'; }
 
 export type SerializesToString = string & {
 	/**
@@ -538,7 +560,7 @@ export function SerializesToString(v: string): SerializesToString {
 
 export type ClusterAlgo = 'kmeans' | 'stepfit';
 
-export type StepDetection = '' | 'absolute' | 'const' | 'percent' | 'cohen' | 'mannwhitneyu';
+export type StepDetection = '' | 'absolute' | 'const' | 'percent' | 'cohen' | 'mannwhitneyu' | 'pelt' | 'bocpd' | 'noise';
 
 export type ConfigState = 'ACTIVE' | 'DELETED';
 
@@ -588,7 +610,7 @@ export type RequestType = 0 | 1;
 
 export type Subset = 'all' | 'regressions' | 'untriaged';
 
-export type NotifierTypes = 'html_email' | 'markdown_issuetracker' | 'none';
+export type NotifierTypes = 'html_email' | 'markdown_issuetracker' | 'slack_webhook' | 'webhook' | 'none';
 
 export type TraceFormat = 'chrome' | '';
 
@@ -608,6 +630,8 @@ export function CL(v: string): CL {
 	return v as CL;
 };
 
+export type TryBotTest = 'utest' | 'ttest';
+
 export type ProcessState = 'Running' | 'Success' | 'Error';
 
 export namespace progress { export type Status = 'Running' | 'Finished' | 'Error'; }
@@ -222,8 +222,9 @@ export function keyValuesFromTraceSet(
   Object.keys(traceset).forEach((traceKey) => {
     // Parse the key.
     const ps = fromKey(traceKey);
-    // Store the values for each key in group_by order.
-    ret[traceKey] = req.group_by!.map((colName) => ps[colName]);
+    // Store the values for each key in group_by order. Subtotal rows don't
+    // have values for the keys below their level in the hierarchy.
+    ret[traceKey] = req.group_by!.map((colName) => ps[colName] || '');
   });
   return ret;
 }
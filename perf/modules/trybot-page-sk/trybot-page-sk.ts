@@ -86,6 +86,9 @@ export class TrybotPageSk extends ElementSk {
     patch_number: -1,
     commit_number: CommitNumber(-1),
     query: '',
+    base_patch_number: 0,
+    test: 'utest',
+    alpha: 0,
   };
 
   private displayedTrace: boolean = false;
@@ -170,6 +173,8 @@ export class TrybotPageSk extends ElementSk {
             <tr>
               <th>Index</th>
               <th title="How many standard deviations this value is from the median.">StdDevs</th>
+              <th title="The p-value of the change from the reference samples.">P</th>
+              <th title="The change in mean from the reference samples, if significant.">Delta %</th>
               <th>Plot</th>
               ${TrybotPageSk.paramKeysAsHeaders(ele)}
             </tr>
@@ -271,6 +276,8 @@ export class TrybotPageSk extends ElementSk {
         html`<tr>
           <td>${i + 1}</td>
           <td>${r.stddevRatio}</td>
+          <td title=${r.note}>${r.p.toPrecision(3)}</td>
+          <td>${r.significant ? r.delta.toFixed(1) : ''}</td>
           <td
             class="link"
             @click=${(e: MouseEvent) => ele.plotIndividualTrace(e, i)}>
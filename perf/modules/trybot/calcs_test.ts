@@ -29,6 +29,12 @@ describe('trybot', () => {
             upper: 0,
             stddevRatio: 2.0,
             values: [],
+            p: 1,
+            significant: false,
+            delta: 0,
+            confidence_lower: 0,
+            confidence_upper: 0,
+            note: '',
           },
           {
             params: Params({
@@ -40,6 +46,12 @@ describe('trybot', () => {
             upper: 0,
             stddevRatio: 1.0,
             values: [],
+            p: 1,
+            significant: false,
+            delta: 0,
+            confidence_lower: 0,
+            confidence_upper: 0,
+            note: '',
           },
         ],
         paramset: ReadOnlyParamSet({
@@ -87,6 +99,12 @@ describe('trybot', () => {
             upper: 0,
             stddevRatio: 2.0,
             values: [],
+            p: 1,
+            significant: false,
+            delta: 0,
+            confidence_lower: 0,
+            confidence_upper: 0,
+            note: '',
           },
           {
             params: Params({
@@ -97,6 +115,12 @@ describe('trybot', () => {
             upper: 0,
             stddevRatio: 1.0,
             values: [],
+            p: 1,
+            significant: false,
+            delta: 0,
+            confidence_lower: 0,
+            confidence_upper: 0,
+            note: '',
           },
           {
             params: Params({
@@ -107,6 +131,12 @@ describe('trybot', () => {
             upper: 0,
             stddevRatio: 3.0,
             values: [],
+            p: 1,
+            significant: false,
+            delta: 0,
+            confidence_lower: 0,
+            confidence_upper: 0,
+            note: '',
           },
         ],
         paramset: ReadOnlyParamSet({